@@ -37,6 +37,10 @@ type Config struct {
 
 	// Review is used to get code and docs reviewers.
 	Review *review.Assignments
+
+	// Lines is the changed-lines threshold for the size check, zero
+	// means the default threshold.
+	Lines int
 }
 
 // CheckAndSetDefaults checks and sets defaults.
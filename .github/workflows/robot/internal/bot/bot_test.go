@@ -102,6 +102,10 @@ func (f *fakeGithub) ListReviews(ctx context.Context, organization string, repos
 	return nil, nil
 }
 
+func (f *fakeGithub) GetPullRequest(ctx context.Context, organization string, repository string, number int) (*github.PullRequest, error) {
+	return &github.PullRequest{}, nil
+}
+
 func (f *fakeGithub) ListPullRequests(ctx context.Context, organization string, repository string, state string) ([]github.PullRequest, error) {
 	return nil, nil
 }
@@ -110,6 +114,18 @@ func (f *fakeGithub) ListFiles(ctx context.Context, organization string, reposit
 	return f.files, nil
 }
 
+func (f *fakeGithub) ListFileChanges(ctx context.Context, organization string, repository string, number int) ([]github.FileChange, error) {
+	return nil, nil
+}
+
+func (f *fakeGithub) ListLabels(ctx context.Context, organization string, repository string, number int) ([]string, error) {
+	return nil, nil
+}
+
+func (f *fakeGithub) CreateComment(ctx context.Context, organization string, repository string, number int, comment string) error {
+	return nil
+}
+
 func (f *fakeGithub) AddLabels(ctx context.Context, organization string, repository string, number int, labels []string) error {
 	return nil
 }
@@ -0,0 +1,192 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bot
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/gravitational/teleport/.github/workflows/robot/internal/github"
+
+	"github.com/gravitational/trace"
+)
+
+// noChangelogLabel overrides the changelog requirement, for user-facing
+// paths changed in a way that is not visible to users (refactoring,
+// comment fixes, and similar).
+const noChangelogLabel = "no-changelog"
+
+// userFacingPrefixes are the path prefixes whose changes are visible to
+// users and therefore need a changelog entry.
+var userFacingPrefixes = []string{
+	"api/",
+	"lib/",
+	"tool/",
+	"web/",
+}
+
+// CheckChangelog checks that PRs touching user-facing paths carry a
+// changelog entry in the PR description, either as a "changelog:"
+// trailer or a "```release-notes" block. On failure it posts a comment
+// on the PR explaining the format before failing the workflow.
+func (b *Bot) CheckChangelog(ctx context.Context) error {
+	files, err := b.c.GitHub.ListFiles(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		b.c.Environment.Number)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if !hasUserFacingChanges(files) {
+		return nil
+	}
+
+	labels, err := b.c.GitHub.ListLabels(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		b.c.Environment.Number)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	for _, label := range labels {
+		if label == noChangelogLabel {
+			return nil
+		}
+	}
+
+	pull, err := b.c.GitHub.GetPullRequest(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		b.c.Environment.Number)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if len(parseChangelog(pull.UnsafeBody)) > 0 {
+		return nil
+	}
+
+	// Post the explanation as a comment, but don't mask the check
+	// failure if commenting fails.
+	err = b.c.GitHub.CreateComment(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		b.c.Environment.Number,
+		changelogComment())
+	if err != nil {
+		log.Printf("Changelog: failed to create comment: %v.", err)
+	}
+
+	return trace.BadParameter("user-facing changes without a changelog entry")
+}
+
+// DraftReleaseNotes aggregates the changelog entries of merged PRs into
+// a draft release notes document and writes it to standard output. The
+// release branch workflow redirects the output into the draft release
+// notes file and commits it.
+func (b *Bot) DraftReleaseNotes(ctx context.Context) error {
+	pulls, err := b.c.GitHub.ListPullRequests(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		"closed")
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	fmt.Print(draftReleaseNotes(pulls))
+	return nil
+}
+
+// hasUserFacingChanges returns true if any of the changed files is on a
+// user-facing path. Test files don't count, their changes are not
+// visible to users.
+func hasUserFacingChanges(files []string) bool {
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		for _, prefix := range userFacingPrefixes {
+			if strings.HasPrefix(file, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseChangelog returns the changelog entries found in the PR
+// description: "changelog:" trailer lines and the lines of a
+// "```release-notes" block.
+func parseChangelog(body string) []string {
+	var entries []string
+	var inBlock bool
+
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if inBlock {
+			if line == "```" {
+				inBlock = false
+				continue
+			}
+			if line != "" {
+				entries = append(entries, line)
+			}
+			continue
+		}
+		if strings.EqualFold(line, "```release-notes") {
+			inBlock = true
+			continue
+		}
+		if len(line) > len("changelog:") && strings.EqualFold(line[:len("changelog:")], "changelog:") {
+			if entry := strings.TrimSpace(line[len("changelog:"):]); entry != "" {
+				entries = append(entries, entry)
+			}
+		}
+	}
+
+	return entries
+}
+
+// draftReleaseNotes formats the changelog entries of the given merged
+// PRs into a draft release notes document.
+func draftReleaseNotes(pulls []github.PullRequest) string {
+	var sb strings.Builder
+	sb.WriteString("# Release Notes (draft)\n\n")
+	sb.WriteString("Generated from the changelog entries of merged PRs, do not edit by hand.\n\n")
+	for _, pull := range pulls {
+		if !pull.Merged {
+			continue
+		}
+		for _, entry := range parseChangelog(pull.UnsafeBody) {
+			fmt.Fprintf(&sb, "* %v (#%v)\n", entry, pull.Number)
+		}
+	}
+	return sb.String()
+}
+
+// changelogComment formats a friendly comment explaining the changelog
+// requirement.
+func changelogComment() string {
+	return fmt.Sprintf("Thanks for the contribution! This PR touches user-facing code, so it needs a changelog entry "+
+		"in the PR description: either a line starting with `changelog:` followed by a one-line summary of the change, "+
+		"or a fenced ```release-notes block with one entry per line. "+
+		"If the change is not visible to users, add the %q label instead. "+
+		"The check re-runs when the description or labels change.", noChangelogLabel)
+}
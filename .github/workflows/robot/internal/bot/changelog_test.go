@@ -0,0 +1,115 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bot
+
+import (
+	"testing"
+
+	"github.com/gravitational/teleport/.github/workflows/robot/internal/github"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestHasUserFacingChanges checks the path rules that decide whether a
+// PR needs a changelog entry.
+func TestHasUserFacingChanges(t *testing.T) {
+	tests := []struct {
+		desc       string
+		files      []string
+		userFacing bool
+	}{
+		{
+			desc:       "lib-change",
+			files:      []string{"lib/service/service.go"},
+			userFacing: true,
+		},
+		{
+			desc:       "docs-and-ci-only",
+			files:      []string{"docs/pages/setup.mdx", ".github/workflows/ci.yaml"},
+			userFacing: false,
+		},
+		{
+			desc:       "tests-only",
+			files:      []string{"lib/service/service_test.go"},
+			userFacing: false,
+		},
+		{
+			desc:       "mixed",
+			files:      []string{"docs/pages/setup.mdx", "tool/tsh/tsh.go"},
+			userFacing: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			require.Equal(t, test.userFacing, hasUserFacingChanges(test.files))
+		})
+	}
+}
+
+// TestParseChangelog checks extraction of changelog entries from PR
+// descriptions.
+func TestParseChangelog(t *testing.T) {
+	tests := []struct {
+		desc    string
+		body    string
+		entries []string
+	}{
+		{
+			desc:    "trailer",
+			body:    "Fixes #1234.\n\nchangelog: Fixed a panic in `tsh login`.",
+			entries: []string{"Fixed a panic in `tsh login`."},
+		},
+		{
+			desc:    "trailer-case-insensitive",
+			body:    "Changelog: Added support for hardware keys.",
+			entries: []string{"Added support for hardware keys."},
+		},
+		{
+			desc:    "release-notes-block",
+			body:    "Summary.\n\n```release-notes\nAdded X.\nFixed Y.\n```\n",
+			entries: []string{"Added X.", "Fixed Y."},
+		},
+		{
+			desc: "no-entry",
+			body: "Just a refactoring, changelog pending.",
+		},
+		{
+			desc: "empty-trailer",
+			body: "changelog:",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			require.Equal(t, test.entries, parseChangelog(test.body))
+		})
+	}
+}
+
+// TestDraftReleaseNotes checks aggregation of merged PR changelog
+// entries into the draft release notes document.
+func TestDraftReleaseNotes(t *testing.T) {
+	notes := draftReleaseNotes([]github.PullRequest{
+		{Number: 1, Merged: true, UnsafeBody: "changelog: Added X."},
+		{Number: 2, Merged: false, UnsafeBody: "changelog: Not merged."},
+		{Number: 3, Merged: true, UnsafeBody: "No entry."},
+		{Number: 4, Merged: true, UnsafeBody: "```release-notes\nFixed Y.\n```"},
+	})
+	require.Contains(t, notes, "* Added X. (#1)")
+	require.Contains(t, notes, "* Fixed Y. (#4)")
+	require.NotContains(t, notes, "Not merged")
+	require.NotContains(t, notes, "#3")
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/gravitational/teleport/.github/workflows/robot/internal/github"
+
+	"github.com/gravitational/trace"
+)
+
+// defaultSizeThreshold is the changed-lines budget applied when the
+// workflow does not pass an explicit threshold.
+const defaultSizeThreshold = 1000
+
+const (
+	// designDocLabel overrides the changed-lines budget, it signals the
+	// PR has an attached design doc justifying its size.
+	designDocLabel = "design-doc"
+	// noTestsLabel overrides the test changes requirement, for changes
+	// covered by existing tests or that genuinely can't be tested.
+	noTestsLabel = "no-test-changes"
+)
+
+// CheckSize checks that the PR stays within the changed-lines budget
+// unless a design doc label is attached, and that changed Go packages
+// come with test changes. On failure it posts a comment on the PR
+// explaining how to override the check before failing the workflow.
+func (b *Bot) CheckSize(ctx context.Context) error {
+	files, err := b.c.GitHub.ListFileChanges(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		b.c.Environment.Number)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	labels, err := b.c.GitHub.ListLabels(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		b.c.Environment.Number)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	threshold := b.c.Lines
+	if threshold <= 0 {
+		threshold = defaultSizeThreshold
+	}
+
+	failures := checkSize(files, labels, threshold)
+	if len(failures) == 0 {
+		return nil
+	}
+
+	// Post the explanation as a comment, but don't mask the check
+	// failure if commenting fails.
+	err = b.c.GitHub.CreateComment(ctx,
+		b.c.Environment.Organization,
+		b.c.Environment.Repository,
+		b.c.Environment.Number,
+		sizeComment(failures))
+	if err != nil {
+		log.Printf("Size: failed to create comment: %v.", err)
+	}
+
+	return trace.BadParameter("size check failed: %v", strings.Join(failures, "; "))
+}
+
+// checkSize returns the list of size check failures for the given PR
+// files and labels.
+func checkSize(files []github.FileChange, labels []string, threshold int) []string {
+	attached := map[string]bool{}
+	for _, label := range labels {
+		attached[label] = true
+	}
+
+	var total int
+	// Go packages with changed code and with changed tests, keyed by
+	// package directory.
+	changed := map[string]bool{}
+	tested := map[string]bool{}
+	for _, file := range files {
+		if strings.HasPrefix(file.Name, "vendor/") {
+			continue
+		}
+		total += file.Additions + file.Deletions
+
+		// Generated code doesn't count towards the test requirement.
+		if !strings.HasSuffix(file.Name, ".go") || strings.HasSuffix(file.Name, ".pb.go") {
+			continue
+		}
+		if strings.HasSuffix(file.Name, "_test.go") {
+			tested[path.Dir(file.Name)] = true
+		} else {
+			changed[path.Dir(file.Name)] = true
+		}
+	}
+
+	var failures []string
+	if total > threshold && !attached[designDocLabel] {
+		failures = append(failures, fmt.Sprintf(
+			"%v lines changed exceeds the %v line threshold and the PR has no %q label",
+			total, threshold, designDocLabel))
+	}
+	if !attached[noTestsLabel] {
+		var missing []string
+		for dir := range changed {
+			if !tested[dir] {
+				missing = append(missing, dir)
+			}
+		}
+		sort.Strings(missing)
+		if len(missing) > 0 {
+			failures = append(failures, fmt.Sprintf(
+				"Go packages changed without test changes: %v",
+				strings.Join(missing, ", ")))
+		}
+	}
+
+	return failures
+}
+
+// sizeComment formats the size check failures into a friendly comment
+// explaining how to override the check.
+func sizeComment(failures []string) string {
+	var sb strings.Builder
+	sb.WriteString("Thanks for the contribution! Before this PR can merge, a couple of things need a look:\n\n")
+	for _, failure := range failures {
+		fmt.Fprintf(&sb, "* %v\n", failure)
+	}
+	fmt.Fprintf(&sb, "\nIf the size is expected, attach a design doc to the PR description and add the %q label. "+
+		"If the change needs no test updates (for example it is covered by existing tests or only touches generated code), "+
+		"add the %q label. The check re-runs when labels change.", designDocLabel, noTestsLabel)
+	return sb.String()
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bot
+
+import (
+	"testing"
+
+	"github.com/gravitational/teleport/.github/workflows/robot/internal/github"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestCheckSize checks the changed-lines budget and test changes
+// requirement.
+func TestCheckSize(t *testing.T) {
+	tests := []struct {
+		desc     string
+		files    []github.FileChange
+		labels   []string
+		failures int
+	}{
+		{
+			desc: "small-pr-with-tests",
+			files: []github.FileChange{
+				{Name: "lib/service/service.go", Additions: 10, Deletions: 5},
+				{Name: "lib/service/service_test.go", Additions: 20},
+			},
+			failures: 0,
+		},
+		{
+			desc: "over-threshold-without-label",
+			files: []github.FileChange{
+				{Name: "lib/service/service.go", Additions: 2000},
+				{Name: "lib/service/service_test.go", Additions: 20},
+			},
+			failures: 1,
+		},
+		{
+			desc: "over-threshold-with-design-doc",
+			files: []github.FileChange{
+				{Name: "lib/service/service.go", Additions: 2000},
+				{Name: "lib/service/service_test.go", Additions: 20},
+			},
+			labels:   []string{designDocLabel},
+			failures: 0,
+		},
+		{
+			desc: "code-without-tests",
+			files: []github.FileChange{
+				{Name: "lib/service/service.go", Additions: 10},
+			},
+			failures: 1,
+		},
+		{
+			desc: "code-without-tests-with-label",
+			files: []github.FileChange{
+				{Name: "lib/service/service.go", Additions: 10},
+			},
+			labels:   []string{noTestsLabel},
+			failures: 0,
+		},
+		{
+			desc: "generated-and-vendored-code-skipped",
+			files: []github.FileChange{
+				{Name: "api/types/events/events.pb.go", Additions: 5000},
+				{Name: "vendor/foo/bar.go", Additions: 5000},
+				{Name: "docs/pages/setup.mdx", Additions: 10},
+			},
+			failures: 1, // vendored lines don't count, generated ones do.
+		},
+		{
+			desc: "both-failures",
+			files: []github.FileChange{
+				{Name: "lib/service/service.go", Additions: 2000},
+			},
+			failures: 2,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			failures := checkSize(test.files, test.labels, defaultSizeThreshold)
+			require.Len(t, failures, test.failures)
+		})
+	}
+}
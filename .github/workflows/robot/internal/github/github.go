@@ -39,12 +39,25 @@ type Client interface {
 	// ListReviews is used to list all submitted reviews for a PR.
 	ListReviews(ctx context.Context, organization string, repository string, number int) (map[string]*Review, error)
 
+	// GetPullRequest returns a specific Pull Request.
+	GetPullRequest(ctx context.Context, organization string, repository string, number int) (*PullRequest, error)
+
 	// ListPullRequests returns a list of Pull Requests.
 	ListPullRequests(ctx context.Context, organization string, repository string, state string) ([]PullRequest, error)
 
 	// ListFiles is used to list all the files within a PR.
 	ListFiles(ctx context.Context, organization string, repository string, number int) ([]string, error)
 
+	// ListFileChanges is used to list all the files within a PR along
+	// with their changed line counts.
+	ListFileChanges(ctx context.Context, organization string, repository string, number int) ([]FileChange, error)
+
+	// ListLabels is used to list all labels attached to an Issue or Pull Request.
+	ListLabels(ctx context.Context, organization string, repository string, number int) ([]string, error)
+
+	// CreateComment will create a comment on an Issue or Pull Request.
+	CreateComment(ctx context.Context, organization string, repository string, number int, comment string) error
+
 	// AddLabels will add labels to an Issue or Pull Request.
 	AddLabels(ctx context.Context, organization string, repository string, number int, labels []string) error
 
@@ -145,11 +158,42 @@ type PullRequest struct {
 	Author string
 	// Repository is the name of the repository.
 	Repository string
+	// Number is the number of the PR.
+	Number int
 	// UnsafeHead is the name of the branch this PR is created from. It is marked
 	// unsafe as it can be attacker controlled.
 	UnsafeHead string
+	// UnsafeTitle is the title of the PR. It is marked unsafe as it can be
+	// attacker controlled.
+	UnsafeTitle string
+	// UnsafeBody is the description of the PR. It is marked unsafe as it
+	// can be attacker controlled.
+	UnsafeBody string
 	// Fork determines if the pull request is from a fork.
 	Fork bool
+	// Merged determines if the pull request has been merged.
+	Merged bool
+}
+
+func (c *client) GetPullRequest(ctx context.Context, organization string, repository string, number int) (*PullRequest, error) {
+	pr, _, err := c.client.PullRequests.Get(ctx,
+		organization,
+		repository,
+		number)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	return &PullRequest{
+		Author:      pr.GetUser().GetLogin(),
+		Repository:  repository,
+		Number:      pr.GetNumber(),
+		UnsafeHead:  pr.GetHead().GetRef(),
+		UnsafeTitle: pr.GetTitle(),
+		UnsafeBody:  pr.GetBody(),
+		Fork:        pr.GetHead().GetRepo().GetFork(),
+		Merged:      pr.GetMerged(),
+	}, nil
 }
 
 func (c *client) ListPullRequests(ctx context.Context, organization string, repository string, state string) ([]PullRequest, error) {
@@ -173,10 +217,14 @@ func (c *client) ListPullRequests(ctx context.Context, organization string, repo
 
 		for _, pr := range page {
 			pulls = append(pulls, PullRequest{
-				Author:     pr.GetUser().GetLogin(),
-				Repository: repository,
-				UnsafeHead: pr.GetHead().GetRef(),
-				Fork:       pr.GetHead().GetRepo().GetFork(),
+				Author:      pr.GetUser().GetLogin(),
+				Repository:  repository,
+				Number:      pr.GetNumber(),
+				UnsafeHead:  pr.GetHead().GetRef(),
+				UnsafeTitle: pr.GetTitle(),
+				UnsafeBody:  pr.GetBody(),
+				Fork:        pr.GetHead().GetRepo().GetFork(),
+				Merged:      pr.MergedAt != nil,
 			})
 		}
 		if resp.NextPage == 0 {
@@ -218,6 +266,96 @@ func (c *client) ListFiles(ctx context.Context, organization string, repository
 	return files, nil
 }
 
+// FileChange is a file changed in a Pull Request.
+type FileChange struct {
+	// Name is the path of the file within the repository.
+	Name string
+	// Additions is the number of lines added.
+	Additions int
+	// Deletions is the number of lines deleted.
+	Deletions int
+}
+
+func (c *client) ListFileChanges(ctx context.Context, organization string, repository string, number int) ([]FileChange, error) {
+	var files []FileChange
+
+	opt := &go_github.ListOptions{
+		Page:    0,
+		PerPage: perPage,
+	}
+	for {
+		page, resp, err := c.client.PullRequests.ListFiles(ctx,
+			organization,
+			repository,
+			number,
+			opt)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+
+		for _, file := range page {
+			files = append(files, FileChange{
+				Name:      file.GetFilename(),
+				Additions: file.GetAdditions(),
+				Deletions: file.GetDeletions(),
+			})
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	return files, nil
+}
+
+func (c *client) ListLabels(ctx context.Context, organization string, repository string, number int) ([]string, error) {
+	var labels []string
+
+	opt := &go_github.ListOptions{
+		Page:    0,
+		PerPage: perPage,
+	}
+	for {
+		page, resp, err := c.client.Issues.ListLabelsByIssue(ctx,
+			organization,
+			repository,
+			number,
+			opt)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+
+		for _, label := range page {
+			labels = append(labels, label.GetName())
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	return labels, nil
+}
+
+// CreateComment will create a comment on an Issue or Pull Request.
+func (c *client) CreateComment(ctx context.Context, organization string, repository string, number int, comment string) error {
+	_, _, err := c.client.Issues.CreateComment(ctx,
+		organization,
+		repository,
+		number,
+		&go_github.IssueComment{
+			Body: &comment,
+		})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	return nil
+}
+
 // AddLabels will add labels to an Issue or Pull Request.
 func (c *client) AddLabels(ctx context.Context, organization string, repository string, number int, labels []string) error {
 	_, _, err := c.client.Issues.AddLabelsToIssue(ctx,
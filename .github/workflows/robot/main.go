@@ -32,7 +32,7 @@ import (
 )
 
 func main() {
-	workflow, token, reviewers, err := parseFlags()
+	workflow, token, reviewers, lines, err := parseFlags()
 	if err != nil {
 		log.Fatalf("Failed to parse flags: %v.", err)
 	}
@@ -44,7 +44,7 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Minute)
 	defer cancel()
 
-	b, err := createBot(ctx, token, reviewers)
+	b, err := createBot(ctx, token, reviewers, lines)
 	if err != nil {
 		log.Fatalf("Failed to create bot: %v.", err)
 	}
@@ -60,6 +60,12 @@ func main() {
 		err = b.Dismiss(ctx)
 	case "label":
 		err = b.Label(ctx)
+	case "size":
+		err = b.CheckSize(ctx)
+	case "changelog":
+		err = b.CheckChangelog(ctx)
+	case "release-notes":
+		err = b.DraftReleaseNotes(ctx)
 	default:
 		err = trace.BadParameter("unknown workflow: %v", workflow)
 	}
@@ -70,33 +76,34 @@ func main() {
 	log.Printf("Workflow %v complete.", workflow)
 }
 
-func parseFlags() (string, string, string, error) {
+func parseFlags() (string, string, string, int, error) {
 	var (
-		workflow  = flag.String("workflow", "", "specific workflow to run [assign, check, dismiss]")
+		workflow  = flag.String("workflow", "", "specific workflow to run [assign, check, dismiss, size, changelog, release-notes]")
 		token     = flag.String("token", "", "GitHub authentication token")
 		reviewers = flag.String("reviewers", "", "reviewer assignments")
+		lines     = flag.Int("lines", 0, "changed-lines threshold for the size workflow")
 	)
 	flag.Parse()
 
 	if *workflow == "" {
-		return "", "", "", trace.BadParameter("workflow missing")
+		return "", "", "", 0, trace.BadParameter("workflow missing")
 	}
 	if *token == "" {
-		return "", "", "", trace.BadParameter("token missing")
+		return "", "", "", 0, trace.BadParameter("token missing")
 	}
 	if *reviewers == "" {
-		return "", "", "", trace.BadParameter("reviewers required for assign and check")
+		return "", "", "", 0, trace.BadParameter("reviewers required for assign and check")
 	}
 
 	data, err := base64.StdEncoding.DecodeString(*reviewers)
 	if err != nil {
-		return "", "", "", trace.Wrap(err)
+		return "", "", "", 0, trace.Wrap(err)
 	}
 
-	return *workflow, *token, string(data), nil
+	return *workflow, *token, string(data), *lines, nil
 }
 
-func createBot(ctx context.Context, token string, reviewers string) (*bot.Bot, error) {
+func createBot(ctx context.Context, token string, reviewers string, lines int) (*bot.Bot, error) {
 	gh, err := github.New(ctx, token)
 	if err != nil {
 		return nil, trace.Wrap(err)
@@ -113,6 +120,7 @@ func createBot(ctx context.Context, token string, reviewers string) (*bot.Bot, e
 		GitHub:      gh,
 		Environment: environment,
 		Review:      reviewer,
+		Lines:       lines,
 	})
 	if err != nil {
 		return nil, trace.Wrap(err)
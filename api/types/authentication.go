@@ -80,6 +80,13 @@ type AuthPreference interface {
 	// SetWebauthn sets the Webauthn configuration settings.
 	SetWebauthn(*Webauthn)
 
+	// GetDeviceAttestationCAs gets the PEM-encoded certificate authorities
+	// device key attestation chains are verified against.
+	GetDeviceAttestationCAs() []string
+	// SetDeviceAttestationCAs sets the PEM-encoded certificate authorities
+	// device key attestation chains are verified against.
+	SetDeviceAttestationCAs([]string)
+
 	// GetRequireSessionMFA returns true when all sessions in this cluster
 	// require an MFA check.
 	GetRequireSessionMFA() bool
@@ -342,6 +349,18 @@ func (c *AuthPreferenceV2) SetWebauthn(w *Webauthn) {
 	c.Spec.Webauthn = w
 }
 
+// GetDeviceAttestationCAs gets the PEM-encoded certificate authorities
+// device key attestation chains are verified against.
+func (c *AuthPreferenceV2) GetDeviceAttestationCAs() []string {
+	return c.Spec.DeviceAttestationCAs
+}
+
+// SetDeviceAttestationCAs sets the PEM-encoded certificate authorities
+// device key attestation chains are verified against.
+func (c *AuthPreferenceV2) SetDeviceAttestationCAs(cas []string) {
+	c.Spec.DeviceAttestationCAs = cas
+}
+
 // GetRequireSessionMFA returns true when all sessions in this cluster require
 // an MFA check.
 func (c *AuthPreferenceV2) GetRequireSessionMFA() bool {
@@ -109,6 +109,11 @@ const (
 	// KindNode is node resource
 	KindNode = "node"
 
+	// SubKindOpenSSHNode is a node sub kind for stock sshd hosts that are
+	// registered with the cluster through certificates only and do not run
+	// the Teleport binary.
+	SubKindOpenSSHNode = "openssh"
+
 	// KindAppServer is an application server resource.
 	KindAppServer = "app_server"
 
@@ -292,6 +297,42 @@ const (
 	// that the resource originates from.
 	OriginLabel = "teleport.dev/origin"
 
+	// ADDomainLabel is a database label name containing the Active
+	// Directory domain used for Kerberos authentication.
+	ADDomainLabel = "teleport.dev/ad-domain"
+
+	// ADKeytabFileLabel is a database label name containing the path to
+	// the keytab file used for Kerberos authentication.
+	ADKeytabFileLabel = "teleport.dev/ad-keytab-file"
+
+	// ADKrb5FileLabel is a database label name containing the path to the
+	// Kerberos configuration file, defaults to /etc/krb5.conf.
+	ADKrb5FileLabel = "teleport.dev/ad-krb5-file"
+
+	// ADSPNLabel is a database label name containing the service principal
+	// name of the database, derived from its URI when not set.
+	ADSPNLabel = "teleport.dev/ad-spn"
+
+	// ADLDAPAddrLabel is a database label name containing the address of
+	// an Active Directory LDAP server used to look up database users.
+	ADLDAPAddrLabel = "teleport.dev/ad-ldap-addr"
+
+	// SnowflakeKeyFileLabel is a database label name containing the path
+	// to the RSA private key used for Snowflake key pair authentication.
+	SnowflakeKeyFileLabel = "teleport.dev/snowflake-key-file"
+
+	// DatabaseHealthLabel is a database label name containing the result
+	// of the database agent's last connectivity check to the database.
+	DatabaseHealthLabel = "teleport.dev/db-health"
+
+	// DatabaseHealthHealthy means the agent's last connectivity check to
+	// the database succeeded.
+	DatabaseHealthHealthy = "healthy"
+
+	// DatabaseHealthUnhealthy means the agent's last connectivity check to
+	// the database failed.
+	DatabaseHealthUnhealthy = "unhealthy"
+
 	// OriginConfigFile is an origin value indicating that the resource was
 	// constructed as a default value.
 	OriginDefaults = "defaults"
@@ -79,14 +79,31 @@ type Database interface {
 	GetIAMResources() []string
 	// IsRDS returns true if this is an RDS/Aurora database.
 	IsRDS() bool
+	// IsRDSProxy returns true if this is an RDS Proxy endpoint.
+	IsRDSProxy() bool
+	// IsElastiCache returns true if this is an ElastiCache Redis database.
+	IsElastiCache() bool
+	// IsMemoryDB returns true if this is a MemoryDB database.
+	IsMemoryDB() bool
 	// IsRedshift returns true if this is a Redshift database.
 	IsRedshift() bool
+	// IsRedshiftServerless returns true if this is a Redshift Serverless database.
+	IsRedshiftServerless() bool
+	// GetRedshiftServerlessWorkgroupName returns the Redshift Serverless
+	// workgroup name parsed from the database endpoint.
+	GetRedshiftServerlessWorkgroupName() string
 	// IsCloudSQL returns true if this is a Cloud SQL database.
 	IsCloudSQL() bool
+	// IsAlloyDB returns true if this is an AlloyDB database.
+	IsAlloyDB() bool
 	// IsAzure returns true if this is an Azure database.
 	IsAzure() bool
 	// IsCloudHosted returns true if database is hosted in the cloud (AWS RDS/Aurora/Redshift, Azure or Cloud SQL).
 	IsCloudHosted() bool
+	// IsConnectionPooler returns true if the database endpoint appears to be
+	// a connection pooler such as PgBouncer or ProxySQL rather than the
+	// database server itself.
+	IsConnectionPooler() bool
 	// Copy returns a copy of this database resource.
 	Copy() *DatabaseV3
 }
@@ -282,16 +299,60 @@ func (d *DatabaseV3) IsRDS() bool {
 	return d.GetType() == DatabaseTypeRDS
 }
 
+// IsRDSProxy returns true if this database is an RDS Proxy endpoint.
+//
+// RDS Proxy endpoints keep the "rds" database type as they use the same
+// IAM authentication flow, the distinction only matters for connection
+// handling.
+func (d *DatabaseV3) IsRDSProxy() bool {
+	return isRDSProxyEndpoint(d.Spec.URI)
+}
+
+// IsElastiCache returns true if this database is an ElastiCache Redis
+// cluster.
+func (d *DatabaseV3) IsElastiCache() bool {
+	return d.GetType() == DatabaseTypeElastiCache
+}
+
+// IsMemoryDB returns true if this database is a MemoryDB cluster.
+func (d *DatabaseV3) IsMemoryDB() bool {
+	return d.GetType() == DatabaseTypeMemoryDB
+}
+
 // IsRedshift returns true if this is a Redshift database instance.
 func (d *DatabaseV3) IsRedshift() bool {
 	return d.GetType() == DatabaseTypeRedshift
 }
 
+// IsRedshiftServerless returns true if this is a Redshift Serverless database
+// instance.
+func (d *DatabaseV3) IsRedshiftServerless() bool {
+	return d.GetType() == DatabaseTypeRedshiftServerless
+}
+
+// GetRedshiftServerlessWorkgroupName returns the Redshift Serverless workgroup
+// name parsed from the database endpoint.
+//
+// Unlike provisioned Redshift clusters, serverless workgroups don't have a
+// dedicated metadata field so the name is always derived from the endpoint.
+func (d *DatabaseV3) GetRedshiftServerlessWorkgroupName() string {
+	workgroup, _, _, err := parseRedshiftServerlessEndpoint(d.Spec.URI)
+	if err != nil {
+		return ""
+	}
+	return workgroup
+}
+
 // IsCloudSQL returns true if this database is a Cloud SQL instance.
 func (d *DatabaseV3) IsCloudSQL() bool {
 	return d.GetType() == DatabaseTypeCloudSQL
 }
 
+// IsAlloyDB returns true if this database is an AlloyDB instance.
+func (d *DatabaseV3) IsAlloyDB() bool {
+	return d.GetType() == DatabaseTypeAlloyDB
+}
+
 // IsAzure returns true if this is Azure hosted database.
 func (d *DatabaseV3) IsAzure() bool {
 	return d.GetType() == DatabaseTypeAzure
@@ -299,7 +360,28 @@ func (d *DatabaseV3) IsAzure() bool {
 
 // IsCloudHosted returns true if database is hosted in the cloud (AWS RDS/Aurora/Redshift, Azure or Cloud SQL).
 func (d *DatabaseV3) IsCloudHosted() bool {
-	return d.IsRDS() || d.IsRedshift() || d.IsCloudSQL() || d.IsAzure()
+	return d.IsRDS() || d.IsRedshift() || d.IsRedshiftServerless() || d.IsElastiCache() || d.IsMemoryDB() || d.IsCloudSQL() || d.IsAlloyDB() || d.IsAzure()
+}
+
+// IsConnectionPooler returns true if the database endpoint appears to be a
+// connection pooler such as PgBouncer or ProxySQL rather than the database
+// server itself.
+//
+// Poolers deviate from the regular server wire protocol in subtle ways
+// (startup parameters, transaction pooling) so database engines enable a
+// compatibility mode for them. Poolers don't advertise themselves in a
+// machine-readable way so detection is based on their default ports and
+// conventional endpoint names.
+func (d *DatabaseV3) IsConnectionPooler() bool {
+	host, port, err := net.SplitHostPort(d.Spec.URI)
+	if err != nil {
+		return false
+	}
+	hostname := strings.Split(host, ".")[0]
+	if strings.Contains(hostname, "pgbouncer") || strings.Contains(hostname, "proxysql") {
+		return true
+	}
+	return port == pgBouncerDefaultPort || port == proxySQLDefaultPort
 }
 
 // GetType returns the database type.
@@ -307,10 +389,29 @@ func (d *DatabaseV3) GetType() string {
 	if d.GetAWS().Redshift.ClusterID != "" {
 		return DatabaseTypeRedshift
 	}
+	// Redshift Serverless workgroups don't have a dedicated metadata field
+	// so they are detected by the endpoint.
+	if strings.Contains(d.Spec.URI, redshiftServerlessEndpointSuffix) {
+		return DatabaseTypeRedshiftServerless
+	}
+	// ElastiCache and MemoryDB don't have dedicated metadata fields so
+	// they are detected by the endpoint, before the generic AWS check
+	// below since their resources can have the region set too.
+	if strings.Contains(d.Spec.URI, elastiCacheEndpointSuffix) {
+		return DatabaseTypeElastiCache
+	}
+	if isMemoryDBEndpoint(d.Spec.URI) {
+		return DatabaseTypeMemoryDB
+	}
 	if d.GetAWS().Region != "" || d.GetAWS().RDS.InstanceID != "" || d.GetAWS().RDS.ClusterID != "" {
 		return DatabaseTypeRDS
 	}
 	if d.GetGCP().ProjectID != "" {
+		// AlloyDB instances are identified by a full resource path rather
+		// than a plain instance ID like Cloud SQL.
+		if strings.Contains(d.GetGCP().InstanceID, "/") {
+			return DatabaseTypeAlloyDB
+		}
 		return DatabaseTypeCloudSQL
 	}
 	if d.GetAzure().Name != "" {
@@ -356,6 +457,16 @@ func (d *DatabaseV3) CheckAndSetDefaults() error {
 	// In case of RDS, Aurora or Redshift, AWS information such as region or
 	// cluster ID can be extracted from the endpoint if not provided.
 	switch {
+	case isRDSProxyEndpoint(d.Spec.URI):
+		// RDS Proxy endpoints don't map to a single database instance so
+		// only the region can be populated from the endpoint.
+		_, region, err := parseRDSProxyEndpoint(d.Spec.URI)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if d.Spec.AWS.Region == "" {
+			d.Spec.AWS.Region = region
+		}
 	case strings.Contains(d.Spec.URI, rdsEndpointSuffix):
 		instanceID, region, err := parseRDSEndpoint(d.Spec.URI)
 		if err != nil {
@@ -378,6 +489,25 @@ func (d *DatabaseV3) CheckAndSetDefaults() error {
 		if d.Spec.AWS.Region == "" {
 			d.Spec.AWS.Region = region
 		}
+	case strings.Contains(d.Spec.URI, redshiftServerlessEndpointSuffix):
+		_, accountID, region, err := parseRedshiftServerlessEndpoint(d.Spec.URI)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if d.Spec.AWS.AccountID == "" {
+			d.Spec.AWS.AccountID = accountID
+		}
+		if d.Spec.AWS.Region == "" {
+			d.Spec.AWS.Region = region
+		}
+	case isMemoryDBEndpoint(d.Spec.URI):
+		region, err := parseMemoryDBEndpoint(d.Spec.URI)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if d.Spec.AWS.Region == "" {
+			d.Spec.AWS.Region = region
+		}
 	case strings.Contains(d.Spec.URI, azureEndpointSuffix):
 		name, err := parseAzureEndpoint(d.Spec.URI)
 		if err != nil {
@@ -387,6 +517,17 @@ func (d *DatabaseV3) CheckAndSetDefaults() error {
 			d.Spec.Azure.Name = name
 		}
 	}
+	// In case of AlloyDB, the instance is identified by its full resource
+	// path from which the project ID can be extracted if not provided.
+	if strings.Contains(d.Spec.GCP.InstanceID, "/") {
+		projectID, err := parseAlloyDBInstanceURI(d.Spec.GCP.InstanceID)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if d.Spec.GCP.ProjectID == "" {
+			d.Spec.GCP.ProjectID = projectID
+		}
+	}
 	return nil
 }
 
@@ -405,6 +546,34 @@ func parseRDSEndpoint(endpoint string) (instanceID, region string, err error) {
 	return parts[0], parts[2], nil
 }
 
+// isRDSProxyEndpoint returns true if the provided endpoint is an RDS Proxy
+// default or custom endpoint.
+func isRDSProxyEndpoint(endpoint string) bool {
+	_, _, err := parseRDSProxyEndpoint(endpoint)
+	return err == nil
+}
+
+// parseRDSProxyEndpoint extracts the proxy or custom endpoint name and the
+// region from the provided RDS Proxy endpoint.
+func parseRDSProxyEndpoint(endpoint string) (name, region string, err error) {
+	host, _, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return "", "", trace.Wrap(err)
+	}
+	parts := strings.Split(host, ".")
+	switch {
+	// RDS Proxy default endpoint looks like this:
+	// my-proxy.proxy-abcdefghijklmnop.us-west-1.rds.amazonaws.com
+	case strings.HasSuffix(host, rdsEndpointSuffix) && len(parts) == 6 && strings.HasPrefix(parts[1], rdsProxyEndpointPrefix):
+		return parts[0], parts[2], nil
+	// RDS Proxy custom endpoint looks like this:
+	// my-endpoint.endpoint.proxy-abcdefghijklmnop.us-west-1.rds.amazonaws.com
+	case strings.HasSuffix(host, rdsEndpointSuffix) && len(parts) == 7 && parts[1] == "endpoint" && strings.HasPrefix(parts[2], rdsProxyEndpointPrefix):
+		return parts[0], parts[3], nil
+	}
+	return "", "", trace.BadParameter("failed to parse %v as RDS Proxy endpoint", endpoint)
+}
+
 // parseRedshiftEndpoint extracts cluster ID and region from the provided Redshift endpoint.
 func parseRedshiftEndpoint(endpoint string) (clusterID, region string, err error) {
 	host, _, err := net.SplitHostPort(endpoint)
@@ -420,6 +589,61 @@ func parseRedshiftEndpoint(endpoint string) (clusterID, region string, err error
 	return parts[0], parts[2], nil
 }
 
+// parseAlloyDBInstanceURI extracts the project ID from the provided AlloyDB
+// instance URI.
+func parseAlloyDBInstanceURI(uri string) (projectID string, err error) {
+	// AlloyDB instance URI looks like this:
+	// projects/my-project/locations/us-east1/clusters/my-cluster/instances/my-instance
+	parts := strings.Split(uri, "/")
+	if len(parts) != 8 || parts[0] != "projects" || parts[2] != "locations" || parts[4] != "clusters" || parts[6] != "instances" {
+		return "", trace.BadParameter("failed to parse %v as AlloyDB instance URI", uri)
+	}
+	return parts[1], nil
+}
+
+// parseRedshiftServerlessEndpoint extracts workgroup name, account ID and
+// region from the provided Redshift Serverless endpoint.
+func parseRedshiftServerlessEndpoint(endpoint string) (workgroup, accountID, region string, err error) {
+	host, _, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return "", "", "", trace.Wrap(err)
+	}
+	// Redshift Serverless workgroup endpoint looks like this:
+	// my-workgroup.123456789012.us-east-1.redshift-serverless.amazonaws.com
+	parts := strings.Split(host, ".")
+	if !strings.HasSuffix(host, redshiftServerlessEndpointSuffix) || len(parts) != 6 {
+		return "", "", "", trace.BadParameter("failed to parse %v as Redshift Serverless endpoint", endpoint)
+	}
+	return parts[0], parts[1], parts[2], nil
+}
+
+// isMemoryDBEndpoint returns true if the provided endpoint is a MemoryDB
+// cluster endpoint.
+func isMemoryDBEndpoint(endpoint string) bool {
+	_, err := parseMemoryDBEndpoint(endpoint)
+	return err == nil
+}
+
+// parseMemoryDBEndpoint extracts the region from the provided MemoryDB
+// cluster endpoint.
+//
+// ElastiCache endpoints encode the region as a short code (e.g. "use1")
+// which can't be mapped back to a region name so unlike MemoryDB the
+// region must be set explicitly for them.
+func parseMemoryDBEndpoint(endpoint string) (region string, err error) {
+	host, _, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	// MemoryDB cluster endpoint looks like this:
+	// clustercfg.my-memorydb.abcdef.memorydb.us-east-1.amazonaws.com
+	parts := strings.Split(host, ".")
+	if !strings.HasSuffix(host, awsEndpointSuffix) || len(parts) != 7 || parts[3] != "memorydb" {
+		return "", trace.BadParameter("failed to parse %v as MemoryDB endpoint", endpoint)
+	}
+	return parts[4], nil
+}
+
 // parseAzureEndpoint extracts database server name from Azure endpoint.
 func parseAzureEndpoint(endpoint string) (name string, err error) {
 	host, _, err := net.SplitHostPort(endpoint)
@@ -441,6 +665,8 @@ func (d *DatabaseV3) GetIAMPolicy() string {
 		return d.getRDSPolicy()
 	} else if d.IsRedshift() {
 		return d.getRedshiftPolicy()
+	} else if d.IsRedshiftServerless() {
+		return d.getRedshiftServerlessPolicy()
 	}
 	return ""
 }
@@ -451,6 +677,8 @@ func (d *DatabaseV3) GetIAMAction() string {
 		return "rds-db:connect"
 	} else if d.IsRedshift() {
 		return "redshift:GetClusterCredentials"
+	} else if d.IsRedshiftServerless() {
+		return "redshift-serverless:GetCredentials"
 	}
 	return ""
 }
@@ -472,6 +700,11 @@ func (d *DatabaseV3) GetIAMResources() []string {
 			fmt.Sprintf("arn:aws:redshift:%v:%v:dbgroup:%v/*",
 				aws.Region, aws.AccountID, aws.Redshift.ClusterID),
 		}
+	} else if d.IsRedshiftServerless() {
+		return []string{
+			fmt.Sprintf("arn:aws:redshift-serverless:%v:%v:workgroup/*",
+				aws.Region, aws.AccountID),
+		}
 	}
 	return nil
 }
@@ -512,6 +745,21 @@ func (d *DatabaseV3) getRedshiftPolicy() string {
 		region, accountID, clusterID)
 }
 
+// getRedshiftServerlessPolicy returns IAM policy document for this Redshift
+// Serverless database.
+func (d *DatabaseV3) getRedshiftServerlessPolicy() string {
+	region := d.GetAWS().Region
+	if region == "" {
+		region = "<region>"
+	}
+	accountID := d.GetAWS().AccountID
+	if accountID == "" {
+		accountID = "<account_id>"
+	}
+	return fmt.Sprintf(redshiftServerlessPolicyTemplate,
+		region, accountID)
+}
+
 const (
 	// DatabaseTypeSelfHosted is the self-hosted type of database.
 	DatabaseTypeSelfHosted = "self-hosted"
@@ -519,8 +767,16 @@ const (
 	DatabaseTypeRDS = "rds"
 	// DatabaseTypeRedshift is AWS Redshift database.
 	DatabaseTypeRedshift = "redshift"
+	// DatabaseTypeRedshiftServerless is AWS Redshift Serverless database.
+	DatabaseTypeRedshiftServerless = "redshift-serverless"
+	// DatabaseTypeElastiCache is AWS-hosted ElastiCache database.
+	DatabaseTypeElastiCache = "elasticache"
+	// DatabaseTypeMemoryDB is AWS-hosted MemoryDB database.
+	DatabaseTypeMemoryDB = "memorydb"
 	// DatabaseTypeCloudSQL is GCP-hosted Cloud SQL database.
 	DatabaseTypeCloudSQL = "gcp"
+	// DatabaseTypeAlloyDB is GCP-hosted AlloyDB database.
+	DatabaseTypeAlloyDB = "gcp-alloydb"
 	// DatabaseTypeAzure is Azure-hosted database.
 	DatabaseTypeAzure = "azure"
 )
@@ -580,10 +836,25 @@ func (d Databases) Swap(i, j int) { d[i], d[j] = d[j], d[i] }
 const (
 	// rdsEndpointSuffix is the RDS/Aurora endpoint suffix.
 	rdsEndpointSuffix = ".rds.amazonaws.com"
+	// rdsProxyEndpointPrefix is the prefix of the hostname part that
+	// identifies RDS Proxy endpoints (proxy-<id>).
+	rdsProxyEndpointPrefix = "proxy-"
+	// elastiCacheEndpointSuffix is the ElastiCache endpoint suffix.
+	elastiCacheEndpointSuffix = ".cache.amazonaws.com"
+	// awsEndpointSuffix is the generic AWS endpoint suffix used by
+	// services without a dedicated subdomain, such as MemoryDB.
+	awsEndpointSuffix = ".amazonaws.com"
 	// redshiftEndpointSuffix is the Redshift endpoint suffix.
 	redshiftEndpointSuffix = ".redshift.amazonaws.com"
+	// redshiftServerlessEndpointSuffix is the Redshift Serverless endpoint suffix.
+	redshiftServerlessEndpointSuffix = ".redshift-serverless.amazonaws.com"
 	// azureEndpointSuffix is the Azure database endpoint suffix.
 	azureEndpointSuffix = ".database.azure.com"
+	// pgBouncerDefaultPort is the port PgBouncer listens on by default.
+	pgBouncerDefaultPort = "6432"
+	// proxySQLDefaultPort is the port ProxySQL serves MySQL traffic on by
+	// default.
+	proxySQLDefaultPort = "6033"
 )
 
 var (
@@ -612,5 +883,17 @@ var (
       ]
     }
   ]
+}`
+	// redshiftServerlessPolicyTemplate is the IAM policy template for Redshift
+	// Serverless databases access.
+	redshiftServerlessPolicyTemplate = `{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Effect": "Allow",
+      "Action": "redshift-serverless:GetCredentials",
+      "Resource": "arn:aws:redshift-serverless:%[1]v:%[2]v:workgroup/*"
+    }
+  ]
 }`
 )
@@ -19,6 +19,7 @@ package types
 import (
 	"testing"
 
+	"github.com/gravitational/trace"
 	"github.com/stretchr/testify/require"
 )
 
@@ -58,6 +59,122 @@ func TestDatabaseRedshiftEndpoint(t *testing.T) {
 	}, database.GetAWS())
 }
 
+// TestDatabaseRedshiftServerlessEndpoint verifies AWS info is correctly
+// populated based on the Redshift Serverless endpoint.
+func TestDatabaseRedshiftServerlessEndpoint(t *testing.T) {
+	database, err := NewDatabaseV3(Metadata{
+		Name: "redshift-serverless",
+	}, DatabaseSpecV3{
+		Protocol: "postgres",
+		URI:      "my-workgroup.123456789012.us-east-1.redshift-serverless.amazonaws.com:5439",
+	})
+	require.NoError(t, err)
+	require.Equal(t, AWS{
+		Region:    "us-east-1",
+		AccountID: "123456789012",
+	}, database.GetAWS())
+	require.Equal(t, DatabaseTypeRedshiftServerless, database.GetType())
+	require.Equal(t, "my-workgroup", database.GetRedshiftServerlessWorkgroupName())
+}
+
+// TestDatabaseRDSProxyEndpoint verifies RDS Proxy endpoints are detected
+// and AWS info is correctly populated based on them.
+func TestDatabaseRDSProxyEndpoint(t *testing.T) {
+	database, err := NewDatabaseV3(Metadata{
+		Name: "rds-proxy",
+	}, DatabaseSpecV3{
+		Protocol: "postgres",
+		URI:      "my-proxy.proxy-abcdefghijklmnop.us-west-1.rds.amazonaws.com:5432",
+	})
+	require.NoError(t, err)
+	require.Equal(t, AWS{
+		Region: "us-west-1",
+	}, database.GetAWS())
+	require.Equal(t, DatabaseTypeRDS, database.GetType())
+	require.True(t, database.IsRDSProxy())
+
+	custom, err := NewDatabaseV3(Metadata{
+		Name: "rds-proxy-custom",
+	}, DatabaseSpecV3{
+		Protocol: "postgres",
+		URI:      "my-endpoint.endpoint.proxy-abcdefghijklmnop.us-west-1.rds.amazonaws.com:5432",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "us-west-1", custom.GetAWS().Region)
+	require.True(t, custom.IsRDSProxy())
+
+	instance, err := NewDatabaseV3(Metadata{
+		Name: "rds",
+	}, DatabaseSpecV3{
+		Protocol: "postgres",
+		URI:      "aurora-instance-1.abcdefghijklmnop.us-west-1.rds.amazonaws.com:5432",
+	})
+	require.NoError(t, err)
+	require.False(t, instance.IsRDSProxy())
+}
+
+// TestDatabaseElastiCacheMemoryDBEndpoints verifies ElastiCache and
+// MemoryDB endpoints are detected and AWS info is populated for MemoryDB.
+func TestDatabaseElastiCacheMemoryDBEndpoints(t *testing.T) {
+	elasticache, err := NewDatabaseV3(Metadata{
+		Name: "elasticache",
+	}, DatabaseSpecV3{
+		Protocol: "redis",
+		URI:      "master.my-redis.abcdef.use1.cache.amazonaws.com:6379",
+		AWS: AWS{
+			Region: "us-east-1",
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, DatabaseTypeElastiCache, elasticache.GetType())
+	require.True(t, elasticache.IsElastiCache())
+	require.False(t, elasticache.IsRDS())
+
+	memorydb, err := NewDatabaseV3(Metadata{
+		Name: "memorydb",
+	}, DatabaseSpecV3{
+		Protocol: "redis",
+		URI:      "clustercfg.my-memorydb.abcdef.memorydb.us-east-1.amazonaws.com:6379",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "us-east-1", memorydb.GetAWS().Region)
+	require.Equal(t, DatabaseTypeMemoryDB, memorydb.GetType())
+	require.True(t, memorydb.IsMemoryDB())
+}
+
+// TestDatabaseAlloyDBInstanceURI verifies GCP info is correctly populated
+// based on the AlloyDB instance URI.
+func TestDatabaseAlloyDBInstanceURI(t *testing.T) {
+	database, err := NewDatabaseV3(Metadata{
+		Name: "alloydb",
+	}, DatabaseSpecV3{
+		Protocol: "postgres",
+		URI:      "10.0.0.1:5432",
+		GCP: GCPCloudSQL{
+			InstanceID: "projects/my-project/locations/us-east1/clusters/my-cluster/instances/my-instance",
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, GCPCloudSQL{
+		ProjectID:  "my-project",
+		InstanceID: "projects/my-project/locations/us-east1/clusters/my-cluster/instances/my-instance",
+	}, database.GetGCP())
+	require.Equal(t, DatabaseTypeAlloyDB, database.GetType())
+	require.True(t, database.IsAlloyDB())
+	require.False(t, database.IsCloudSQL())
+
+	_, err = NewDatabaseV3(Metadata{
+		Name: "alloydb-bad-uri",
+	}, DatabaseSpecV3{
+		Protocol: "postgres",
+		URI:      "10.0.0.1:5432",
+		GCP: GCPCloudSQL{
+			InstanceID: "my-cluster/instances/my-instance",
+		},
+	})
+	require.True(t, trace.IsBadParameter(err))
+}
+
 // TestDatabaseStatus verifies database resource status field usage.
 func TestDatabaseStatus(t *testing.T) {
 	database, err := NewDatabaseV3(Metadata{
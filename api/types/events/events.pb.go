@@ -1100,7 +1100,16 @@ type SessionData struct {
 	// BytesTransmitted is the amount of bytes transmitted
 	BytesTransmitted uint64 `protobuf:"varint,6,opt,name=BytesTransmitted,proto3" json:"tx"`
 	// BytesReceived is the amount of bytes received
-	BytesReceived        uint64   `protobuf:"varint,7,opt,name=BytesReceived,proto3" json:"rx"`
+	BytesReceived uint64 `protobuf:"varint,7,opt,name=BytesReceived,proto3" json:"rx"`
+	// PacketsTransmitted is the number of writes used to transmit the data,
+	// only set by periodic in-flight reports
+	PacketsTransmitted uint64 `protobuf:"varint,8,opt,name=PacketsTransmitted,proto3" json:"tx_packets,omitempty"`
+	// PacketsReceived is the number of reads used to receive the data,
+	// only set by periodic in-flight reports
+	PacketsReceived uint64 `protobuf:"varint,9,opt,name=PacketsReceived,proto3" json:"rx_packets,omitempty"`
+	// DurationMilliseconds is how long the session has been going on so far,
+	// only set by periodic in-flight reports
+	DurationMilliseconds int64    `protobuf:"varint,10,opt,name=DurationMilliseconds,proto3" json:"duration_ms,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -2530,9 +2539,13 @@ type KubeRequest struct {
 	ResponseCode int32 `protobuf:"varint,11,opt,name=ResponseCode,proto3" json:"response_code"`
 	// Kubernetes has information about a kubernetes cluster, if applicable.
 	KubernetesClusterMetadata `protobuf:"bytes,12,opt,name=Kubernetes,proto3,embedded=Kubernetes" json:""`
-	XXX_NoUnkeyedLiteral      struct{} `json:"-"`
-	XXX_unrecognized          []byte   `json:"-"`
-	XXX_sizecache             int32    `json:"-"`
+	// RequestDiff is a redacted representation of the object change
+	// submitted by a mutating request: the patch body for PATCH requests,
+	// the submitted object otherwise.
+	RequestDiff          string   `protobuf:"bytes,13,opt,name=RequestDiff,proto3" json:"request_diff,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *KubeRequest) Reset()         { *m = KubeRequest{} }
@@ -3193,7 +3206,15 @@ type DatabaseSessionQuery struct {
 	// DatabaseQueryParameters are the query parameters for prepared statements.
 	DatabaseQueryParameters []string `protobuf:"bytes,6,rep,name=DatabaseQueryParameters,proto3" json:"db_query_parameters,omitempty"`
 	// Status indicates whether the query was successfully sent to the database.
-	Status               `protobuf:"bytes,7,opt,name=Status,proto3,embedded=Status" json:""`
+	Status `protobuf:"bytes,7,opt,name=Status,proto3,embedded=Status" json:""`
+	// DatabaseQueryFingerprint is the normalized form of the query with
+	// literal values replaced by placeholders.
+	DatabaseQueryFingerprint string `protobuf:"bytes,8,opt,name=DatabaseQueryFingerprint,proto3" json:"db_query_fingerprint,omitempty"`
+	// DatabaseQueryStatementType is the type of the SQL statement,
+	// e.g. SELECT or INSERT.
+	DatabaseQueryStatementType string `protobuf:"bytes,9,opt,name=DatabaseQueryStatementType,proto3" json:"db_query_statement_type,omitempty"`
+	// DatabaseQueryTables are the table names referenced by the query.
+	DatabaseQueryTables  []string `protobuf:"bytes,10,rep,name=DatabaseQueryTables,proto3" json:"db_query_tables,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -7102,6 +7123,21 @@ func (m *SessionData) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if m.DurationMilliseconds != 0 {
+		i = encodeVarintEvents(dAtA, i, uint64(m.DurationMilliseconds))
+		i--
+		dAtA[i] = 0x50
+	}
+	if m.PacketsReceived != 0 {
+		i = encodeVarintEvents(dAtA, i, uint64(m.PacketsReceived))
+		i--
+		dAtA[i] = 0x48
+	}
+	if m.PacketsTransmitted != 0 {
+		i = encodeVarintEvents(dAtA, i, uint64(m.PacketsTransmitted))
+		i--
+		dAtA[i] = 0x40
+	}
 	if m.BytesReceived != 0 {
 		i = encodeVarintEvents(dAtA, i, uint64(m.BytesReceived))
 		i--
@@ -9054,6 +9090,13 @@ func (m *KubeRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if len(m.RequestDiff) > 0 {
+		i -= len(m.RequestDiff)
+		copy(dAtA[i:], m.RequestDiff)
+		i = encodeVarintEvents(dAtA, i, uint64(len(m.RequestDiff)))
+		i--
+		dAtA[i] = 0x6a
+	}
 	{
 		size, err := m.KubernetesClusterMetadata.MarshalToSizedBuffer(dAtA[:i])
 		if err != nil {
@@ -10094,6 +10137,29 @@ func (m *DatabaseSessionQuery) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if len(m.DatabaseQueryTables) > 0 {
+		for iNdEx := len(m.DatabaseQueryTables) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.DatabaseQueryTables[iNdEx])
+			copy(dAtA[i:], m.DatabaseQueryTables[iNdEx])
+			i = encodeVarintEvents(dAtA, i, uint64(len(m.DatabaseQueryTables[iNdEx])))
+			i--
+			dAtA[i] = 0x52
+		}
+	}
+	if len(m.DatabaseQueryStatementType) > 0 {
+		i -= len(m.DatabaseQueryStatementType)
+		copy(dAtA[i:], m.DatabaseQueryStatementType)
+		i = encodeVarintEvents(dAtA, i, uint64(len(m.DatabaseQueryStatementType)))
+		i--
+		dAtA[i] = 0x4a
+	}
+	if len(m.DatabaseQueryFingerprint) > 0 {
+		i -= len(m.DatabaseQueryFingerprint)
+		copy(dAtA[i:], m.DatabaseQueryFingerprint)
+		i = encodeVarintEvents(dAtA, i, uint64(len(m.DatabaseQueryFingerprint)))
+		i--
+		dAtA[i] = 0x42
+	}
 	{
 		size, err := m.Status.MarshalToSizedBuffer(dAtA[:i])
 		if err != nil {
@@ -13659,6 +13725,15 @@ func (m *SessionData) Size() (n int) {
 	if m.BytesReceived != 0 {
 		n += 1 + sovEvents(uint64(m.BytesReceived))
 	}
+	if m.PacketsTransmitted != 0 {
+		n += 1 + sovEvents(uint64(m.PacketsTransmitted))
+	}
+	if m.PacketsReceived != 0 {
+		n += 1 + sovEvents(uint64(m.PacketsReceived))
+	}
+	if m.DurationMilliseconds != 0 {
+		n += 1 + sovEvents(uint64(m.DurationMilliseconds))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -14334,6 +14409,10 @@ func (m *KubeRequest) Size() (n int) {
 	}
 	l = m.KubernetesClusterMetadata.Size()
 	n += 1 + l + sovEvents(uint64(l))
+	l = len(m.RequestDiff)
+	if l > 0 {
+		n += 1 + l + sovEvents(uint64(l))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -14683,6 +14762,20 @@ func (m *DatabaseSessionQuery) Size() (n int) {
 	}
 	l = m.Status.Size()
 	n += 1 + l + sovEvents(uint64(l))
+	l = len(m.DatabaseQueryFingerprint)
+	if l > 0 {
+		n += 1 + l + sovEvents(uint64(l))
+	}
+	l = len(m.DatabaseQueryStatementType)
+	if l > 0 {
+		n += 1 + l + sovEvents(uint64(l))
+	}
+	if len(m.DatabaseQueryTables) > 0 {
+		for _, s := range m.DatabaseQueryTables {
+			l = len(s)
+			n += 1 + l + sovEvents(uint64(l))
+		}
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -20807,6 +20900,63 @@ func (m *SessionData) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 8:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PacketsTransmitted", wireType)
+			}
+			m.PacketsTransmitted = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEvents
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.PacketsTransmitted |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 9:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PacketsReceived", wireType)
+			}
+			m.PacketsReceived = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEvents
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.PacketsReceived |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 10:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DurationMilliseconds", wireType)
+			}
+			m.DurationMilliseconds = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEvents
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.DurationMilliseconds |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipEvents(dAtA[iNdEx:])
@@ -26509,6 +26659,38 @@ func (m *KubeRequest) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 13:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RequestDiff", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEvents
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthEvents
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthEvents
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.RequestDiff = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipEvents(dAtA[iNdEx:])
@@ -29637,6 +29819,102 @@ func (m *DatabaseSessionQuery) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DatabaseQueryFingerprint", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEvents
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthEvents
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthEvents
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.DatabaseQueryFingerprint = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 9:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DatabaseQueryStatementType", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEvents
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthEvents
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthEvents
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.DatabaseQueryStatementType = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 10:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DatabaseQueryTables", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEvents
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthEvents
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthEvents
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.DatabaseQueryTables = append(m.DatabaseQueryTables, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipEvents(dAtA[iNdEx:])
@@ -220,6 +220,12 @@ func (t LockTarget) Match(lock Lock) bool {
 	if t.WindowsDesktop != "" && lockTarget.WindowsDesktop != t.WindowsDesktop {
 		return false
 	}
+	if t.Database != "" && lockTarget.Database != t.Database {
+		return false
+	}
+	if t.Application != "" && lockTarget.Application != t.Application {
+		return false
+	}
 	return true
 }
 
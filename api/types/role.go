@@ -132,6 +132,35 @@ type Role interface {
 	GetWindowsLogins(RoleConditionType) []string
 	// SetWindowsLogins sets Windows desktop logins for allow or deny condition.
 	SetWindowsLogins(RoleConditionType, []string)
+	// GetClientNetworks gets client source networks for allow or deny condition.
+	GetClientNetworks(RoleConditionType) []string
+	// SetClientNetworks sets client source networks for allow or deny condition.
+	SetClientNetworks(RoleConditionType, []string)
+	// GetDatabaseQueries gets database query patterns for allow or deny condition.
+	GetDatabaseQueries(RoleConditionType) []string
+	// SetDatabaseQueries sets database query patterns for allow or deny condition.
+	SetDatabaseQueries(RoleConditionType, []string)
+	// GetCommands gets SSH command patterns for allow or deny condition.
+	GetCommands(RoleConditionType) []string
+	// SetCommands sets SSH command patterns for allow or deny condition.
+	SetCommands(RoleConditionType, []string)
+	// GetPortForwardingDestinations gets port forwarding destination patterns
+	// for allow or deny condition.
+	GetPortForwardingDestinations(RoleConditionType) []string
+	// SetPortForwardingDestinations sets port forwarding destination patterns
+	// for allow or deny condition.
+	SetPortForwardingDestinations(RoleConditionType, []string)
+	// GetHostGroups gets groups for automatically created host users.
+	GetHostGroups(RoleConditionType) []string
+	// SetHostGroups sets groups for automatically created host users.
+	SetHostGroups(RoleConditionType, []string)
+	// GetHostSudoers gets sudoers entries for automatically created host users.
+	GetHostSudoers(RoleConditionType) []string
+	// SetHostSudoers sets sudoers entries for automatically created host users.
+	SetHostSudoers(RoleConditionType, []string)
+	// GetLabelsExpression gets the label expression for the allow or deny
+	// condition matching resources of the given kind.
+	GetLabelsExpression(rct RoleConditionType, kind string) string
 }
 
 // NewRole constructs new standard role
@@ -534,6 +563,143 @@ func (r *RoleV4) SetWindowsLogins(rct RoleConditionType, logins []string) {
 	}
 }
 
+// GetClientNetworks gets client source networks for the role's allow or deny condition.
+func (r *RoleV4) GetClientNetworks(rct RoleConditionType) []string {
+	if rct == Allow {
+		return r.Spec.Allow.ClientNetworks
+	}
+	return r.Spec.Deny.ClientNetworks
+}
+
+// SetClientNetworks sets client source networks for the role's allow or deny condition.
+func (r *RoleV4) SetClientNetworks(rct RoleConditionType, networks []string) {
+	ncopy := utils.CopyStrings(networks)
+
+	if rct == Allow {
+		r.Spec.Allow.ClientNetworks = ncopy
+	} else {
+		r.Spec.Deny.ClientNetworks = ncopy
+	}
+}
+
+// GetDatabaseQueries gets database query patterns for the role's allow or deny condition.
+func (r *RoleV4) GetDatabaseQueries(rct RoleConditionType) []string {
+	if rct == Allow {
+		return r.Spec.Allow.DatabaseQueries
+	}
+	return r.Spec.Deny.DatabaseQueries
+}
+
+// SetDatabaseQueries sets database query patterns for the role's allow or deny condition.
+func (r *RoleV4) SetDatabaseQueries(rct RoleConditionType, queries []string) {
+	qcopy := utils.CopyStrings(queries)
+
+	if rct == Allow {
+		r.Spec.Allow.DatabaseQueries = qcopy
+	} else {
+		r.Spec.Deny.DatabaseQueries = qcopy
+	}
+}
+
+// GetCommands gets SSH command patterns for the role's allow or deny condition.
+func (r *RoleV4) GetCommands(rct RoleConditionType) []string {
+	if rct == Allow {
+		return r.Spec.Allow.Commands
+	}
+	return r.Spec.Deny.Commands
+}
+
+// SetCommands sets SSH command patterns for the role's allow or deny condition.
+func (r *RoleV4) SetCommands(rct RoleConditionType, commands []string) {
+	ccopy := utils.CopyStrings(commands)
+
+	if rct == Allow {
+		r.Spec.Allow.Commands = ccopy
+	} else {
+		r.Spec.Deny.Commands = ccopy
+	}
+}
+
+// GetPortForwardingDestinations gets port forwarding destination patterns for
+// the role's allow or deny condition.
+func (r *RoleV4) GetPortForwardingDestinations(rct RoleConditionType) []string {
+	if rct == Allow {
+		return r.Spec.Allow.PortForwardingDestinations
+	}
+	return r.Spec.Deny.PortForwardingDestinations
+}
+
+// SetPortForwardingDestinations sets port forwarding destination patterns for
+// the role's allow or deny condition.
+func (r *RoleV4) SetPortForwardingDestinations(rct RoleConditionType, destinations []string) {
+	dcopy := utils.CopyStrings(destinations)
+
+	if rct == Allow {
+		r.Spec.Allow.PortForwardingDestinations = dcopy
+	} else {
+		r.Spec.Deny.PortForwardingDestinations = dcopy
+	}
+}
+
+// GetHostGroups gets groups for automatically created host users.
+func (r *RoleV4) GetHostGroups(rct RoleConditionType) []string {
+	if rct == Allow {
+		return r.Spec.Allow.HostGroups
+	}
+	return r.Spec.Deny.HostGroups
+}
+
+// SetHostGroups sets groups for automatically created host users.
+func (r *RoleV4) SetHostGroups(rct RoleConditionType, groups []string) {
+	gcopy := utils.CopyStrings(groups)
+
+	if rct == Allow {
+		r.Spec.Allow.HostGroups = gcopy
+	} else {
+		r.Spec.Deny.HostGroups = gcopy
+	}
+}
+
+// GetHostSudoers gets sudoers entries for automatically created host users.
+func (r *RoleV4) GetHostSudoers(rct RoleConditionType) []string {
+	if rct == Allow {
+		return r.Spec.Allow.HostSudoers
+	}
+	return r.Spec.Deny.HostSudoers
+}
+
+// SetHostSudoers sets sudoers entries for automatically created host users.
+func (r *RoleV4) SetHostSudoers(rct RoleConditionType, sudoers []string) {
+	scopy := utils.CopyStrings(sudoers)
+
+	if rct == Allow {
+		r.Spec.Allow.HostSudoers = scopy
+	} else {
+		r.Spec.Deny.HostSudoers = scopy
+	}
+}
+
+// GetLabelsExpression gets the label expression for the role's allow or deny
+// condition matching resources of the given kind. Returns an empty string for
+// kinds without label expression support.
+func (r *RoleV4) GetLabelsExpression(rct RoleConditionType, kind string) string {
+	cond := &r.Spec.Deny
+	if rct == Allow {
+		cond = &r.Spec.Allow
+	}
+	switch kind {
+	case KindNode:
+		return cond.NodeLabelsExpression
+	case KindApp:
+		return cond.AppLabelsExpression
+	case KindDatabase:
+		return cond.DatabaseLabelsExpression
+	case KindWindowsDesktop:
+		return cond.WindowsDesktopLabelsExpression
+	}
+	return ""
+}
+
 // GetRules gets all allow or deny rules.
 func (r *RoleV4) GetRules(rct RoleConditionType) []Rule {
 	if rct == Allow {
@@ -32,6 +32,11 @@ import (
 // role option).
 const SemaphoreKindConnection = "connection"
 
+// SemaphoreKindSession is the semaphore kind used to limit concurrent
+// interactive sessions of a user on a single node (corresponds to the
+// "teleport.dev/ssh-max-concurrent-sessions" role metadata label).
+const SemaphoreKindSession = "session"
+
 // Semaphore represents distributed semaphore concept
 type Semaphore interface {
 	// Resource contains common resource values
@@ -38,6 +38,13 @@ const (
 	// process ID
 	SSHAgentPID = "SSH_AGENT_PID"
 
+	// X11Display is the environment variable pointing to the X display
+	// of the session.
+	X11Display = "DISPLAY"
+	// X11Authority is the environment variable pointing to the Xauthority
+	// file holding the X11 authentication cookie of the session.
+	X11Authority = "XAUTHORITY"
+
 	// SSHTeleportUser is the current Teleport user that is logged in.
 	SSHTeleportUser = "SSH_TELEPORT_USER"
 
@@ -303,6 +310,17 @@ const (
 	// PTY is a raw pty session capture format
 	PTY = "pty"
 
+	// MP4 is an MP4 video export format for desktop session recordings
+	MP4 = "mp4"
+
+	// Asciicast is the asciicast v2 export format for SSH session
+	// recordings, playable with the asciinema toolchain
+	Asciicast = "asciicast"
+
+	// Raw is a plain typescript export format with the terminal output
+	// of an SSH session recording and no timing information
+	Raw = "raw"
+
 	// Names is for formatting node names in plain text
 	Names = "names"
 
@@ -564,6 +582,14 @@ const (
 // SCP is Secure Copy.
 const SCP = "scp"
 
+// SFTPSubsystem is the name of the SSH subsystem that serves SFTP.
+const SFTPSubsystem = "sftp"
+
+// WatchSubsystemPrefix is the prefix of the SSH subsystem that streams the
+// live terminal output of an active session to a read-only observer. The
+// watched session ID follows the prefix.
+const WatchSubsystemPrefix = "watch:"
+
 // Root is *nix system administrator account name.
 const Root = "root"
 
@@ -703,6 +729,10 @@ const (
 	// ForwardSubCommand is the sub-command Teleport uses to re-exec itself
 	// for port forwarding.
 	ForwardSubCommand = "forward"
+
+	// SFTPSubCommand is the sub-command Teleport uses to re-exec itself to
+	// serve the SFTP subsystem as the login user.
+	SFTPSubCommand = "sftp"
 )
 
 const (
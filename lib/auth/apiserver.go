@@ -115,6 +115,15 @@ func NewAPIServer(config *APIConfig) (http.Handler, error) {
 	srv.GET("/:version/users/:user", srv.withAuth(srv.getUser))
 	srv.DELETE("/:version/users/:user", srv.withAuth(srv.deleteUser)) // DELETE IN: 5.2 REST method is replaced by grpc method with context.
 
+	// SCIM 2.0 user provisioning (RFC 7644), lets identity providers push
+	// user lifecycle changes instead of relying on JIT SSO claims at login.
+	srv.GET("/:version/scim/v2/users", srv.withAuth(srv.scimListUsers))
+	srv.GET("/:version/scim/v2/users/:user", srv.withAuth(srv.scimGetUser))
+	srv.POST("/:version/scim/v2/users", srv.withAuth(srv.scimCreateUser))
+	srv.PUT("/:version/scim/v2/users/:user", srv.withAuth(srv.scimReplaceUser))
+	srv.PATCH("/:version/scim/v2/users/:user", srv.withAuth(srv.scimPatchUser))
+	srv.DELETE("/:version/scim/v2/users/:user", srv.withAuth(srv.scimDeleteUser))
+
 	// Generating keypairs
 	srv.POST("/:version/keypair", srv.withAuth(srv.generateKeyPair))
 
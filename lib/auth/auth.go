@@ -643,6 +643,10 @@ type certRequest struct {
 	// disallowReissue flags that a cert should not be allowed to issue future
 	// certificates.
 	disallowReissue bool
+	// boundKeyFingerprint is the fingerprint of the device-held key the
+	// issued certificates are bound to, set after a successful device key
+	// attestation at login.
+	boundKeyFingerprint string
 }
 
 // check verifies the cert request is valid.
@@ -990,13 +994,14 @@ func (a *Server) generateUserCert(req certRequest) (*proto.Certs, error) {
 			Username:    req.dbUser,
 			Database:    req.dbName,
 		},
-		DatabaseNames:   dbNames,
-		DatabaseUsers:   dbUsers,
-		MFAVerified:     req.mfaVerified,
-		ClientIP:        req.clientIP,
-		AWSRoleARNs:     roleARNs,
-		ActiveRequests:  req.activeRequests.AccessRequests,
-		DisallowReissue: req.disallowReissue,
+		DatabaseNames:       dbNames,
+		DatabaseUsers:       dbUsers,
+		MFAVerified:         req.mfaVerified,
+		ClientIP:            req.clientIP,
+		AWSRoleARNs:         roleARNs,
+		ActiveRequests:      req.activeRequests.AccessRequests,
+		DisallowReissue:     req.disallowReissue,
+		BoundKeyFingerprint: req.boundKeyFingerprint,
 	}
 	subject, err := identity.Subject()
 	if err != nil {
@@ -1012,6 +1017,14 @@ func (a *Server) generateUserCert(req certRequest) (*proto.Certs, error) {
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
+
+	// If the certificate elevates the user with access requests, record the
+	// start of the elevation window and schedule the matching end event for
+	// when the certificate expires and the elevation is dropped.
+	if len(req.activeRequests.AccessRequests) > 0 {
+		a.reportRoleAssumption(req.user.GetName(), req.activeRequests.AccessRequests, certRequest.NotAfter)
+	}
+
 	return &proto.Certs{
 		SSH:        sshCert,
 		TLS:        tlsCert,
@@ -1020,6 +1033,43 @@ func (a *Server) generateUserCert(req certRequest) (*proto.Certs, error) {
 	}, nil
 }
 
+// reportRoleAssumption emits an audit event for each access request a newly
+// issued certificate elevates the user with, and schedules the matching
+// expiry events for when the certificate lapses and the elevation is
+// automatically dropped. The certificate expiry is already capped to the
+// access expiry of every included request, so the expiry events fire no
+// later than the requests themselves allow.
+func (a *Server) reportRoleAssumption(user string, requestIDs []string, expires time.Time) {
+	emit := func(eventType, eventCode string) {
+		for _, reqID := range requestIDs {
+			err := a.emitter.EmitAuditEvent(a.closeCtx, &apievents.AccessRequestCreate{
+				Metadata: apievents.Metadata{
+					Type: eventType,
+					Code: eventCode,
+				},
+				UserMetadata: apievents.UserMetadata{
+					User: user,
+				},
+				ResourceMetadata: apievents.ResourceMetadata{
+					Expires: expires,
+				},
+				RequestID: reqID,
+			})
+			if err != nil {
+				log.WithError(err).Warnf("Failed to emit %v event.", eventType)
+			}
+		}
+	}
+	emit(events.AccessRequestAssumeEvent, events.AccessRequestAssumeCode)
+	go func() {
+		select {
+		case <-a.clock.After(expires.Sub(a.clock.Now())):
+			emit(events.AccessRequestExpireEvent, events.AccessRequestExpireCode)
+		case <-a.closeCtx.Done():
+		}
+	}()
+}
+
 // WithUserLock executes function authenticateFn that performs user authentication
 // if authenticateFn returns non nil error, the login attempt will be logged in as failed.
 // The only exception to this rule is ConnectionProblemError, in case if it occurs
@@ -3160,10 +3210,14 @@ func (a *Server) isMFARequired(ctx context.Context, checker services.AccessCheck
 			return nil, trace.Wrap(notFoundErr)
 		}
 
+		// The client IP is not known at this point - this is an advisory
+		// check only, network conditions are enforced when the database
+		// connection is actually made.
 		dbRoleMatchers := role.DatabaseRoleMatchers(
 			db.GetProtocol(),
 			t.Database.Username,
 			t.Database.GetDatabase(),
+			"",
 		)
 		noMFAAccessErr = checker.CheckAccess(
 			db,
@@ -0,0 +1,94 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"bytes"
+	"crypto/x509"
+
+	"github.com/gravitational/trace"
+	"golang.org/x/crypto/ssh"
+)
+
+// DeviceKeyAttestation proves that the private key certificates are
+// requested for resides in dedicated device hardware such as a TPM or
+// Secure Enclave and can not be exported. The proof is an X.509
+// certificate chain produced by the hardware for the key, rooted in an
+// attestation CA of the hardware vendor. The cluster only accepts chains
+// that verify against the attestation CAs configured in the auth
+// preference, so a client can not fabricate an attestation for a
+// software key.
+type DeviceKeyAttestation struct {
+	// AttestationChain is the DER-encoded X.509 certificate chain, leaf
+	// first, certifying the login public key as hardware-held.
+	AttestationChain [][]byte `json:"attestation_chain"`
+}
+
+// VerifyDeviceKeyAttestation verifies the attestation chain against the
+// attestation CAs configured in the cluster auth preference, checks that
+// the attested key is the key certificates are requested for and returns
+// the fingerprint of the bound key to encode into the issued
+// certificates. Verification fails closed if an attestation is submitted
+// but no attestation CAs are configured.
+func VerifyDeviceKeyAttestation(attestation *DeviceKeyAttestation, loginPublicKey []byte, attestationCAs []string) (string, error) {
+	if len(attestation.AttestationChain) == 0 {
+		return "", trace.BadParameter("missing attestation chain")
+	}
+	if len(attestationCAs) == 0 {
+		return "", trace.AccessDenied("device key attestation submitted but no device attestation CAs are configured in the cluster auth preference")
+	}
+	roots := x509.NewCertPool()
+	for _, ca := range attestationCAs {
+		if !roots.AppendCertsFromPEM([]byte(ca)) {
+			return "", trace.BadParameter("failed to parse configured device attestation CA")
+		}
+	}
+	chain := make([]*x509.Certificate, 0, len(attestation.AttestationChain))
+	for _, der := range attestation.AttestationChain {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return "", trace.BadParameter("failed to parse attestation certificate: %v", err)
+		}
+		chain = append(chain, cert)
+	}
+	leaf := chain[0]
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+	if _, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}); err != nil {
+		return "", trace.AccessDenied("device key attestation chain does not verify against the configured attestation CAs")
+	}
+	// Certificates must be requested for the attested key itself, otherwise
+	// the attestation proves nothing about the key they are issued for.
+	attestedKey, err := ssh.NewPublicKey(leaf.PublicKey)
+	if err != nil {
+		return "", trace.BadParameter("unsupported attested public key type: %v", err)
+	}
+	loginKey, _, _, _, err := ssh.ParseAuthorizedKey(loginPublicKey)
+	if err != nil {
+		return "", trace.BadParameter("failed to parse login public key: %v", err)
+	}
+	if !bytes.Equal(attestedKey.Marshal(), loginKey.Marshal()) {
+		return "", trace.AccessDenied("attested key does not match the key certificates are requested for")
+	}
+	return ssh.FingerprintSHA256(attestedKey), nil
+}
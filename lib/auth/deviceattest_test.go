@@ -0,0 +1,124 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/gravitational/trace"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+// attestationCA is a fake hardware vendor attestation CA issuing
+// attestation certificates for device keys.
+type attestationCA struct {
+	key  *ecdsa.PrivateKey
+	cert *x509.Certificate
+	pem  string
+}
+
+func newAttestationCA(t *testing.T) *attestationCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test Attestation CA"},
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &attestationCA{
+		key:  key,
+		cert: cert,
+		pem:  string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+	}
+}
+
+// attest issues an attestation certificate for the device public key.
+func (ca *attestationCA) attest(t *testing.T, devicePublicKey interface{}) []byte {
+	t.Helper()
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "Test Device Key"},
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, devicePublicKey, ca.key)
+	require.NoError(t, err)
+	return der
+}
+
+func TestVerifyDeviceKeyAttestation(t *testing.T) {
+	ca := newAttestationCA(t)
+
+	deviceKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	sshKey, err := ssh.NewPublicKey(deviceKey.Public())
+	require.NoError(t, err)
+	loginKey := ssh.MarshalAuthorizedKey(sshKey)
+
+	attestation := &DeviceKeyAttestation{
+		AttestationChain: [][]byte{ca.attest(t, deviceKey.Public())},
+	}
+
+	fingerprint, err := VerifyDeviceKeyAttestation(attestation, loginKey, []string{ca.pem})
+	require.NoError(t, err)
+	require.Equal(t, ssh.FingerprintSHA256(sshKey), fingerprint)
+
+	// Verification fails closed when no attestation CAs are configured.
+	_, err = VerifyDeviceKeyAttestation(attestation, loginKey, nil)
+	require.True(t, trace.IsAccessDenied(err))
+
+	// A chain issued by an unknown CA is rejected.
+	otherCA := newAttestationCA(t)
+	_, err = VerifyDeviceKeyAttestation(attestation, loginKey, []string{otherCA.pem})
+	require.True(t, trace.IsAccessDenied(err))
+
+	// A self-issued "attestation" not rooted in a configured CA is
+	// rejected, a client can not attest its own key.
+	selfIssued := newAttestationCA(t)
+	_, err = VerifyDeviceKeyAttestation(&DeviceKeyAttestation{
+		AttestationChain: [][]byte{selfIssued.attest(t, deviceKey.Public())},
+	}, loginKey, []string{ca.pem})
+	require.True(t, trace.IsAccessDenied(err))
+
+	// The attested key must be the key certificates are requested for.
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherSSHKey, err := ssh.NewPublicKey(otherKey.Public())
+	require.NoError(t, err)
+	_, err = VerifyDeviceKeyAttestation(attestation, ssh.MarshalAuthorizedKey(otherSSHKey), []string{ca.pem})
+	require.True(t, trace.IsAccessDenied(err))
+
+	// Attestations without a chain are rejected.
+	_, err = VerifyDeviceKeyAttestation(&DeviceKeyAttestation{}, loginKey, []string{ca.pem})
+	require.True(t, trace.IsBadParameter(err))
+}
@@ -298,6 +298,11 @@ type AuthenticateSSHRequest struct {
 	// KubernetesCluster sets the target kubernetes cluster for the TLS
 	// certificate. This can be empty on older clients.
 	KubernetesCluster string `json:"kubernetes_cluster"`
+	// AttestDeviceKey is an optional hardware attestation of the key
+	// certificates are requested for. The attestation chain is verified
+	// against the device attestation CAs configured in the cluster auth
+	// preference and the issued certificates record the binding.
+	AttestDeviceKey *DeviceKeyAttestation `json:"attest_device_key,omitempty"`
 }
 
 // CheckAndSetDefaults checks and sets default certificate values
@@ -415,15 +420,27 @@ func (s *Server) AuthenticateSSHUser(req AuthenticateSSHRequest) (*SSHLoginRespo
 		authority,
 	}
 
+	// When the client attested a device-held key, verify the attestation
+	// chain against the configured attestation CAs and record the binding
+	// in the issued certificates.
+	var boundKeyFingerprint string
+	if req.AttestDeviceKey != nil {
+		boundKeyFingerprint, err = VerifyDeviceKeyAttestation(req.AttestDeviceKey, req.PublicKey, authPref.GetDeviceAttestationCAs())
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
+
 	certs, err := s.generateUserCert(certRequest{
-		user:              user,
-		ttl:               req.TTL,
-		publicKey:         req.PublicKey,
-		compatibility:     req.CompatibilityMode,
-		checker:           checker,
-		traits:            user.GetTraits(),
-		routeToCluster:    req.RouteToCluster,
-		kubernetesCluster: req.KubernetesCluster,
+		user:                user,
+		ttl:                 req.TTL,
+		publicKey:           req.PublicKey,
+		compatibility:       req.CompatibilityMode,
+		checker:             checker,
+		traits:              user.GetTraits(),
+		routeToCluster:      req.RouteToCluster,
+		kubernetesCluster:   req.KubernetesCluster,
+		boundKeyFingerprint: boundKeyFingerprint,
 	})
 	if err != nil {
 		return nil, trace.Wrap(err)
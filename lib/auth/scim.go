@@ -0,0 +1,335 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/teleport/lib/httplib"
+
+	"github.com/gravitational/trace"
+	"github.com/julienschmidt/httprouter"
+)
+
+const (
+	// scimUserSchema is the SCIM 2.0 core user resource schema URI.
+	scimUserSchema = "urn:ietf:params:scim:schemas:core:2.0:User"
+	// scimListResponseSchema is the SCIM 2.0 list response envelope schema URI.
+	scimListResponseSchema = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+)
+
+// scimTraitGroups is the trait SCIM group memberships are stored in. Roles
+// can template on the pushed group names through this trait.
+const scimTraitGroups = "groups"
+
+// scimUser is the SCIM 2.0 wire representation of a Teleport user. Teleport
+// users are keyed by name, so the resource ID and the user name are the same.
+type scimUser struct {
+	Schemas  []string    `json:"schemas"`
+	ID       string      `json:"id"`
+	UserName string      `json:"userName"`
+	Active   bool        `json:"active"`
+	Groups   []scimGroup `json:"groups,omitempty"`
+}
+
+// scimGroup is a single group membership of a SCIM user.
+type scimGroup struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+}
+
+// name returns the group name, preferring the display name over the opaque
+// group ID identity providers put in the value field.
+func (g scimGroup) name() string {
+	if g.Display != "" {
+		return g.Display
+	}
+	return g.Value
+}
+
+// scimListResponse is the SCIM 2.0 paginated list envelope.
+type scimListResponse struct {
+	Schemas      []string   `json:"schemas"`
+	TotalResults int        `json:"totalResults"`
+	StartIndex   int        `json:"startIndex"`
+	ItemsPerPage int        `json:"itemsPerPage"`
+	Resources    []scimUser `json:"Resources"`
+}
+
+// scimPatchRequest is the SCIM 2.0 patch request envelope.
+type scimPatchRequest struct {
+	Schemas    []string      `json:"schemas"`
+	Operations []scimPatchOp `json:"Operations"`
+}
+
+// scimPatchOp is a single SCIM patch operation.
+type scimPatchOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// scimFilterRe matches the only filter expression identity providers use
+// during provisioning: a user lookup by name.
+var scimFilterRe = regexp.MustCompile(`(?i)^username eq "([^"]+)"$`)
+
+// parseSCIMFilter parses a SCIM filter expression, e.g. `userName eq "alice"`,
+// and returns the user name being looked up.
+func parseSCIMFilter(filter string) (string, error) {
+	match := scimFilterRe.FindStringSubmatch(filter)
+	if match == nil {
+		return "", trace.BadParameter("unsupported SCIM filter %q", filter)
+	}
+	return match[1], nil
+}
+
+// scimListUsers returns the list of users, optionally narrowed down by a
+// `userName eq` filter which is how identity providers check whether a user
+// already exists before pushing it.
+func (s *APIServer) scimListUsers(auth ClientI, w http.ResponseWriter, r *http.Request, p httprouter.Params, version string) (interface{}, error) {
+	var users []types.User
+	if filter := r.URL.Query().Get("filter"); filter != "" {
+		name, err := parseSCIMFilter(filter)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		user, err := auth.GetUser(name, false)
+		if err != nil && !trace.IsNotFound(err) {
+			return nil, trace.Wrap(err)
+		}
+		if err == nil {
+			users = append(users, user)
+		}
+	} else {
+		var err error
+		users, err = auth.GetUsers(false)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
+	resources := make([]scimUser, 0, len(users))
+	for _, user := range users {
+		resources = append(resources, s.scimResource(user))
+	}
+	return scimListResponse{
+		Schemas:      []string{scimListResponseSchema},
+		TotalResults: len(resources),
+		StartIndex:   1,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	}, nil
+}
+
+// scimGetUser returns a single user as a SCIM resource.
+func (s *APIServer) scimGetUser(auth ClientI, w http.ResponseWriter, r *http.Request, p httprouter.Params, version string) (interface{}, error) {
+	user, err := auth.GetUser(p.ByName("user"), false)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return s.scimResource(user), nil
+}
+
+// scimCreateUser creates a user pushed by the identity provider, mapping
+// its group memberships to roles and traits.
+func (s *APIServer) scimCreateUser(auth ClientI, w http.ResponseWriter, r *http.Request, p httprouter.Params, version string) (interface{}, error) {
+	var req scimUser
+	if err := httplib.ReadJSON(r, &req); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if req.UserName == "" {
+		return nil, trace.BadParameter("missing userName")
+	}
+	if _, err := auth.GetUser(req.UserName, false); err == nil {
+		return nil, trace.AlreadyExists("user %q already exists", req.UserName)
+	}
+	user, err := types.NewUser(req.UserName)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if err := scimApplyGroups(r.Context(), auth, user, req.Groups); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	s.scimSetActive(user, req.Active)
+	if err := auth.CreateUser(r.Context(), user); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return s.scimResource(user), nil
+}
+
+// scimReplaceUser replaces the user with the pushed resource. The identity
+// provider is the source of truth for provisioned users, so roles and the
+// groups trait are recomputed from the pushed group memberships.
+func (s *APIServer) scimReplaceUser(auth ClientI, w http.ResponseWriter, r *http.Request, p httprouter.Params, version string) (interface{}, error) {
+	user, err := auth.GetUser(p.ByName("user"), false)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var req scimUser
+	if err := httplib.ReadJSON(r, &req); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if req.UserName != "" && req.UserName != user.GetName() {
+		return nil, trace.BadParameter("renaming users is not supported")
+	}
+	user.SetRoles(nil)
+	if err := scimApplyGroups(r.Context(), auth, user, req.Groups); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	s.scimSetActive(user, req.Active)
+	if err := auth.UpdateUser(r.Context(), user); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return s.scimResource(user), nil
+}
+
+// scimPatchUser applies a SCIM patch to the user. Only toggling the active
+// flag is supported, which is how Okta and Azure AD deactivate and
+// reactivate users; group changes are pushed as full replaces.
+func (s *APIServer) scimPatchUser(auth ClientI, w http.ResponseWriter, r *http.Request, p httprouter.Params, version string) (interface{}, error) {
+	user, err := auth.GetUser(p.ByName("user"), false)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var req scimPatchRequest
+	if err := httplib.ReadJSON(r, &req); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, op := range req.Operations {
+		if !strings.EqualFold(op.Op, "replace") {
+			return nil, trace.NotImplemented("unsupported SCIM patch operation %q", op.Op)
+		}
+		switch {
+		case strings.EqualFold(op.Path, "active"):
+			active, err := parseSCIMBool(op.Value)
+			if err != nil {
+				return nil, trace.Wrap(err)
+			}
+			s.scimSetActive(user, active)
+		case op.Path == "":
+			// Replace without a path carries a partial resource.
+			var value struct {
+				Active *bool `json:"active"`
+			}
+			if err := json.Unmarshal(op.Value, &value); err != nil {
+				return nil, trace.BadParameter("failed to parse SCIM patch value: %v", err)
+			}
+			if value.Active == nil {
+				return nil, trace.NotImplemented("only the active attribute can be patched")
+			}
+			s.scimSetActive(user, *value.Active)
+		default:
+			return nil, trace.NotImplemented("unsupported SCIM patch path %q", op.Path)
+		}
+	}
+	if err := auth.UpdateUser(r.Context(), user); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return s.scimResource(user), nil
+}
+
+// scimDeleteUser deletes the user.
+func (s *APIServer) scimDeleteUser(auth ClientI, w http.ResponseWriter, r *http.Request, p httprouter.Params, version string) (interface{}, error) {
+	name := p.ByName("user")
+	if err := auth.DeleteUser(r.Context(), name); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return message(fmt.Sprintf("user %q deleted", name)), nil
+}
+
+// scimResource converts a Teleport user to its SCIM representation.
+func (s *APIServer) scimResource(user types.User) scimUser {
+	resource := scimUser{
+		Schemas:  []string{scimUserSchema},
+		ID:       user.GetName(),
+		UserName: user.GetName(),
+		Active:   true,
+	}
+	status := user.GetStatus()
+	if status.IsLocked && status.LockExpires.After(s.Now()) {
+		resource.Active = false
+	}
+	for _, group := range user.GetTraits()[scimTraitGroups] {
+		resource.Groups = append(resource.Groups, scimGroup{Value: group, Display: group})
+	}
+	return resource
+}
+
+// scimApplyGroups stores the pushed group names in the groups trait and
+// grants the roles whose names match a group, letting operators map identity
+// provider groups to access by creating roles of the same name.
+func scimApplyGroups(ctx context.Context, auth ClientI, user types.User, groups []scimGroup) error {
+	names := make([]string, 0, len(groups))
+	for _, group := range groups {
+		name := group.name()
+		if name == "" {
+			continue
+		}
+		names = append(names, name)
+		if _, err := auth.GetRole(ctx, name); err != nil {
+			if trace.IsNotFound(err) {
+				continue
+			}
+			return trace.Wrap(err)
+		}
+		user.AddRole(name)
+	}
+	traits := user.GetTraits()
+	if traits == nil {
+		traits = make(map[string][]string)
+	}
+	traits[scimTraitGroups] = names
+	user.SetTraits(traits)
+	return nil
+}
+
+// scimSetActive locks or unlocks the user based on the SCIM active flag.
+// Deactivated users are kept around so the identity provider can reactivate
+// them with their roles and traits intact.
+func (s *APIServer) scimSetActive(user types.User, active bool) {
+	if active {
+		user.ResetLocks()
+		return
+	}
+	// Locks in this tree always carry an expiry, use one far enough out
+	// to never unlock a deactivated user.
+	user.SetLocked(s.Now().UTC().AddDate(1000, 0, 0), "user deactivated through SCIM")
+}
+
+// parseSCIMBool parses a SCIM boolean value. Azure AD is known to send
+// booleans as the strings "True" and "False".
+func parseSCIMBool(raw json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(raw, &b); err == nil {
+		return b, nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return false, trace.BadParameter("failed to parse SCIM boolean value %q", string(raw))
+	}
+	b, err := strconv.ParseBool(strings.ToLower(s))
+	if err != nil {
+		return false, trace.BadParameter("failed to parse SCIM boolean value %q", s)
+	}
+	return b, nil
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSCIMFilter(t *testing.T) {
+	tests := []struct {
+		filter  string
+		name    string
+		wantErr bool
+	}{
+		{filter: `userName eq "alice"`, name: "alice"},
+		{filter: `UserName Eq "alice@example.com"`, name: "alice@example.com"},
+		{filter: `displayName eq "alice"`, wantErr: true},
+		{filter: `userName sw "al"`, wantErr: true},
+		{filter: ``, wantErr: true},
+	}
+	for _, tt := range tests {
+		name, err := parseSCIMFilter(tt.filter)
+		if tt.wantErr {
+			require.Error(t, err, "filter %q", tt.filter)
+			continue
+		}
+		require.NoError(t, err, "filter %q", tt.filter)
+		require.Equal(t, tt.name, name)
+	}
+}
+
+func TestParseSCIMBool(t *testing.T) {
+	tests := []struct {
+		raw     string
+		value   bool
+		wantErr bool
+	}{
+		{raw: `true`, value: true},
+		{raw: `false`, value: false},
+		// Azure AD sends booleans as strings.
+		{raw: `"True"`, value: true},
+		{raw: `"False"`, value: false},
+		{raw: `"yes"`, wantErr: true},
+		{raw: `1`, wantErr: true},
+	}
+	for _, tt := range tests {
+		value, err := parseSCIMBool(json.RawMessage(tt.raw))
+		if tt.wantErr {
+			require.Error(t, err, "value %q", tt.raw)
+			continue
+		}
+		require.NoError(t, err, "value %q", tt.raw)
+		require.Equal(t, tt.value, value)
+	}
+}
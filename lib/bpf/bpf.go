@@ -31,6 +31,7 @@ import (
 	"net"
 	"strconv"
 	"sync"
+	"syscall"
 	"time"
 	"unsafe"
 
@@ -322,11 +323,23 @@ func (s *Service) emitCommandEvent(eventBytes []byte) {
 		}
 		argv := args.([]string)
 
+		// If the roles of the session deny execution of this program, kill
+		// it before it does any more work. The command event is still
+		// emitted below, marked with the deny code so the enforcement shows
+		// up in the audit log.
+		code := events.SessionCommandCode
+		if commandDenied(ctx, argv[0]) {
+			code = events.SessionCommandDenyCode
+			if err := syscall.Kill(int(event.PID), syscall.SIGKILL); err != nil {
+				log.Debugf("Failed to kill denied program %v (PID %v): %v.", argv[0], event.PID, err)
+			}
+		}
+
 		// Emit "command" event.
 		sessionCommandEvent := &apievents.SessionCommand{
 			Metadata: apievents.Metadata{
 				Type: events.SessionCommandEvent,
-				Code: events.SessionCommandCode,
+				Code: code,
 			},
 			ServerMetadata: apievents.ServerMetadata{
 				ServerID:        ctx.ServerID,
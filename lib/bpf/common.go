@@ -80,6 +80,15 @@ type SessionContext struct {
 	// Events is the set of events (command, disk, or network) to record for
 	// this session.
 	Events map[string]bool
+
+	// CommandAllow is a list of glob patterns; when non-empty, programs
+	// executed within the session must match one of them or they are
+	// killed.
+	CommandAllow []string
+
+	// CommandDeny is a list of glob patterns; programs executed within the
+	// session matching any of them are killed.
+	CommandDeny []string
 }
 
 // Config holds configuration for the BPF service.
@@ -0,0 +1,51 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bpf
+
+import (
+	"path/filepath"
+)
+
+// commandDenied returns true if the roles of the session deny execution of
+// the program at the given path. The deny list is checked first and wins
+// over the allow list; a non-empty allow list denies everything that does
+// not match it. Patterns match against both the full path and the base name
+// of the program, so "vi" blocks both "/usr/bin/vi" and "./vi".
+func commandDenied(ctx *SessionContext, path string) bool {
+	if matchesCommandPattern(ctx.CommandDeny, path) {
+		return true
+	}
+	if len(ctx.CommandAllow) > 0 && !matchesCommandPattern(ctx.CommandAllow, path) {
+		return true
+	}
+	return false
+}
+
+// matchesCommandPattern returns true if the full path or base name of the
+// program matches any of the glob patterns. Malformed patterns never match.
+func matchesCommandPattern(patterns []string, path string) bool {
+	base := filepath.Base(path)
+	for _, pattern := range patterns {
+		if ok, err := filepath.Match(pattern, path); err == nil && ok {
+			return true
+		}
+		if ok, err := filepath.Match(pattern, base); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bpf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestCommandDenied verifies the allow and deny pattern matching used to
+// enforce per-role command restrictions.
+func TestCommandDenied(t *testing.T) {
+	var tests = []struct {
+		desc   string
+		allow  []string
+		deny   []string
+		path   string
+		denied bool
+	}{
+		{
+			desc:   "no filters allows everything",
+			path:   "/bin/ls",
+			denied: false,
+		},
+		{
+			desc:   "deny by base name",
+			deny:   []string{"nc"},
+			path:   "/usr/bin/nc",
+			denied: true,
+		},
+		{
+			desc:   "deny by full path glob",
+			deny:   []string{"/usr/bin/*"},
+			path:   "/usr/bin/curl",
+			denied: true,
+		},
+		{
+			desc:   "deny does not match other programs",
+			deny:   []string{"nc"},
+			path:   "/bin/ls",
+			denied: false,
+		},
+		{
+			desc:   "allow list denies everything else",
+			allow:  []string{"ls", "cat"},
+			path:   "/usr/bin/vi",
+			denied: true,
+		},
+		{
+			desc:   "allow list permits matching program",
+			allow:  []string{"ls", "cat"},
+			path:   "/bin/ls",
+			denied: false,
+		},
+		{
+			desc:   "deny wins over allow",
+			allow:  []string{"*"},
+			deny:   []string{"nc"},
+			path:   "/usr/bin/nc",
+			denied: true,
+		},
+		{
+			desc:   "malformed pattern never matches",
+			deny:   []string{"[unclosed"},
+			path:   "/bin/ls",
+			denied: false,
+		},
+	}
+
+	for _, tt := range tests {
+		ctx := &SessionContext{
+			CommandAllow: tt.allow,
+			CommandDeny:  tt.deny,
+		}
+		require.Equal(t, tt.denied, commandDenied(ctx, tt.path), tt.desc)
+	}
+}
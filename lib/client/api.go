@@ -1548,6 +1548,88 @@ func (tc *TeleportClient) Join(ctx context.Context, namespace string, sessionID
 	return tc.runShell(nc, session)
 }
 
+// WatchSession connects to the node hosting an active session and streams
+// the live terminal output of the session to tc.Stdout without joining it.
+// The call blocks until the watched session ends or ctx is canceled.
+func (tc *TeleportClient) WatchSession(ctx context.Context, namespace string, sessionID session.ID) error {
+	if namespace == "" {
+		return trace.BadParameter(auth.MissingNamespaceError)
+	}
+	if sessionID.Check() != nil {
+		return trace.Errorf("Invalid session ID format: %s", string(sessionID))
+	}
+	var notFoundErrorMessage = fmt.Sprintf("session '%s' not found or it has ended", sessionID)
+
+	// connect to proxy:
+	if !tc.Config.ProxySpecified() {
+		return trace.BadParameter("proxy server is not specified")
+	}
+	proxyClient, err := tc.ConnectToProxy(ctx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer proxyClient.Close()
+	site, err := proxyClient.ConnectToCurrentCluster(ctx, false)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	// find the session ID on the site:
+	sessions, err := site.GetSessions(namespace)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	var session *session.Session
+	for _, s := range sessions {
+		if s.ID == sessionID {
+			session = &s
+			break
+		}
+	}
+	if session == nil {
+		return trace.NotFound(notFoundErrorMessage)
+	}
+
+	// pick the 1st party of the session and use his server ID to connect to
+	if len(session.Parties) == 0 {
+		return trace.NotFound(notFoundErrorMessage)
+	}
+	serverID := session.Parties[0].ServerID
+
+	// find a server address by its ID
+	nodes, err := site.GetNodes(ctx, namespace)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	var node types.Server
+	for _, n := range nodes {
+		if n.GetName() == serverID {
+			node = n
+			break
+		}
+	}
+	if node == nil {
+		return trace.NotFound(notFoundErrorMessage)
+	}
+	target := node.GetAddr()
+	if target == "" {
+		// address is empty, try dialing by UUID instead
+		target = fmt.Sprintf("%s:0", serverID)
+	}
+	// connect to server:
+	nc, err := proxyClient.ConnectToNode(ctx, NodeAddr{
+		Addr:      target,
+		Namespace: tc.Namespace,
+		Cluster:   tc.SiteName,
+	}, tc.Config.HostLogin, false)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer nc.Close()
+
+	return trace.Wrap(nc.WatchSession(ctx, string(sessionID), tc.Stdout))
+}
+
 // Play replays the recorded session
 func (tc *TeleportClient) Play(ctx context.Context, namespace, sessionID string) (err error) {
 	var sessionEvents []events.EventFields
@@ -1591,6 +1673,41 @@ func (tc *TeleportClient) Play(ctx context.Context, namespace, sessionID string)
 	return playSession(sessionEvents, stream)
 }
 
+// GetSessionStream returns the full recorded byte stream of a session.
+func (tc *TeleportClient) GetSessionStream(ctx context.Context, namespace, sessionID string) ([]byte, error) {
+	if namespace == "" {
+		return nil, trace.BadParameter(auth.MissingNamespaceError)
+	}
+	sid, err := session.ParseID(sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("'%v' is not a valid session ID (must be GUID)", sid)
+	}
+	// connect to the auth server (site) who made the recording
+	proxyClient, err := tc.ConnectToProxy(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	defer proxyClient.Close()
+
+	site, err := proxyClient.ConnectToCurrentCluster(ctx, false)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	// read the stream into a buffer:
+	var stream []byte
+	for {
+		tmp, err := site.GetSessionChunk(namespace, *sid, len(stream), events.MaxChunkBytes)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		if len(tmp) == 0 {
+			break
+		}
+		stream = append(stream, tmp...)
+	}
+	return stream, nil
+}
+
 func (tc *TeleportClient) GetSessionEvents(ctx context.Context, namespace, sessionID string) ([]events.EventFields, error) {
 	if namespace == "" {
 		return nil, trace.BadParameter(auth.MissingNamespaceError)
@@ -140,6 +140,10 @@ type ReissueParams struct {
 	RouteToDatabase   proto.RouteToDatabase
 	RouteToApp        proto.RouteToApp
 
+	// Expires optionally caps the validity of the reissued certificates.
+	// When zero, the certificates inherit the expiry of the current ones.
+	Expires time.Time
+
 	// ExistingCreds is a gross hack for lib/web/terminal.go to pass in
 	// existing user credentials. The TeleportClient in lib/web/terminal.go
 	// doesn't have a real LocalKeystore and keeps all certs in memory.
@@ -466,10 +470,15 @@ func (proxy *ProxyClient) prepareUserCertsRequest(params ReissueParams, key *Key
 		params.AccessRequests = activeRequests.AccessRequests
 	}
 
+	expires := tlsCert.NotAfter
+	if !params.Expires.IsZero() && params.Expires.Before(expires) {
+		expires = params.Expires
+	}
+
 	return &proto.UserCertsRequest{
 		PublicKey:         key.Pub,
 		Username:          tlsCert.Subject.CommonName,
-		Expires:           tlsCert.NotAfter,
+		Expires:           expires,
 		RouteToCluster:    params.RouteToCluster,
 		KubernetesCluster: params.KubernetesCluster,
 		AccessRequests:    params.AccessRequests,
@@ -1306,6 +1315,33 @@ func (c *NodeClient) ExecuteSCP(ctx context.Context, cmd scp.Command) error {
 	return trace.Wrap(err)
 }
 
+// WatchSession requests the "watch" subsystem on the node hosting an active
+// session and copies the streamed terminal output into w. The call blocks
+// until the watched session ends, the server detaches the observer or ctx
+// is canceled.
+func (c *NodeClient) WatchSession(ctx context.Context, sessionID string, w io.Writer) error {
+	s, err := c.Client.NewSession()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer s.Close()
+
+	stdout, err := s.StdoutPipe()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	if err := requestSubsystem(ctx, s, teleport.WatchSubsystemPrefix+sessionID); err != nil {
+		return trace.Wrap(err)
+	}
+
+	_, err = io.Copy(w, stdout)
+	if err != nil && !trace.IsEOF(err) {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
 type netDialer interface {
 	Dial(string, string) (net.Conn, error)
 }
@@ -29,7 +29,7 @@ const (
 
 	// Note: on a raw terminal, "\r\n" is needed to move a cursor to the start
 	// of next line.
-	helpText = "\r\ntsh escape characters:\r\n  ~? - display a list of escape characters\r\n  ~. - disconnect\r\n"
+	helpText = "\r\ntsh escape characters:\r\n  ~? - display a list of escape characters\r\n  ~. - disconnect\r\n  ~p - pause or resume the session\r\n"
 )
 
 var (
@@ -47,10 +47,11 @@ var (
 //
 // Reader is safe for concurrent use.
 type Reader struct {
-	inner        io.Reader
-	out          io.Writer
-	onDisconnect func(error)
-	bufferLimit  int
+	inner         io.Reader
+	out           io.Writer
+	onDisconnect  func(error)
+	onPauseToggle func()
+	bufferLimit   int
 
 	// cond protects buf and err and also announces to blocked readers that
 	// more data is available.
@@ -61,12 +62,14 @@ type Reader struct {
 
 // NewReader creates a new Reader to catch escape sequences from 'in'.
 //
-// Two sequences are supported:
+// Three sequences are supported:
 // - "~?": prints help text to 'out' listing supported sequences
 // - "~.": disconnect stops any future reads from in; after this sequence,
 //   callers can still read any unread data up to this sequence from Reader but
 //   all future Read calls will return ErrDisconnect; onDisconnect will also be
 //   called with ErrDisconnect immediately
+// - "~p": calls onPauseToggle, used to pause and resume the session; when
+//   onPauseToggle is nil the sequence is passed through as regular input
 //
 // NewReader starts consuming 'in' immediately in the background. This allows
 // Reader to detect sequences without the caller actively calling Read (such as
@@ -80,21 +83,22 @@ type Reader struct {
 //
 // If the internal buffer is empty, calls to Read will block until some data is
 // available or an error occurs.
-func NewReader(in io.Reader, out io.Writer, onDisconnect func(error)) *Reader {
-	r := newUnstartedReader(in, out, onDisconnect)
+func NewReader(in io.Reader, out io.Writer, onDisconnect func(error), onPauseToggle func()) *Reader {
+	r := newUnstartedReader(in, out, onDisconnect, onPauseToggle)
 	go r.runReads()
 	return r
 }
 
 // newUnstartedReader allows unit tests to mutate Reader before runReads
 // starts.
-func newUnstartedReader(in io.Reader, out io.Writer, onDisconnect func(error)) *Reader {
+func newUnstartedReader(in io.Reader, out io.Writer, onDisconnect func(error), onPauseToggle func()) *Reader {
 	return &Reader{
-		inner:        in,
-		out:          out,
-		onDisconnect: onDisconnect,
-		bufferLimit:  readerBufferLimit,
-		cond:         sync.Cond{L: &sync.Mutex{}},
+		inner:         in,
+		out:           out,
+		onDisconnect:  onDisconnect,
+		onPauseToggle: onPauseToggle,
+		bufferLimit:   readerBufferLimit,
+		cond:          sync.Cond{L: &sync.Mutex{}},
 		// note: no need to pre-allocate buf, it will allocate and grow as
 		// needed in runReads via append.
 	}
@@ -155,6 +159,20 @@ func (r *Reader) runReads() {
 					return
 				}
 				newLine = false
+			case 'p':
+				if escape {
+					if r.onPauseToggle != nil {
+						// Complete pause sequence.
+						r.onPauseToggle()
+						newLine, escape = false, false
+						continue inner
+					}
+					// Pause is not supported by the caller, send out a '~'
+					// that was previously suppressed.
+					writeBuf = append(writeBuf, '~')
+					escape = false
+				}
+				newLine = false
 			default:
 				if escape {
 					// An incomplete escape sequence, send out a '~' that was
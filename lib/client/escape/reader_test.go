@@ -31,13 +31,15 @@ type ReaderSuite struct {
 var _ = check.Suite(&ReaderSuite{})
 
 type readerTestCase struct {
-	inChunks [][]byte
-	inErr    error
+	inChunks        [][]byte
+	inErr           error
+	nilPauseHandler bool
 
 	wantReadErr       error
 	wantDisconnectErr error
 	wantOut           string
 	wantHelp          string
+	wantPauseToggles  int
 }
 
 func (*ReaderSuite) runCase(c *check.C, t readerTestCase) {
@@ -46,15 +48,22 @@ func (*ReaderSuite) runCase(c *check.C, t readerTestCase) {
 	out := new(bytes.Buffer)
 	var disconnectErr error
 
+	pauseToggles := 0
+	onPauseToggle := func() { pauseToggles++ }
+	if t.nilPauseHandler {
+		onPauseToggle = nil
+	}
+
 	r := NewReader(in, helpOut, func(err error) {
 		disconnectErr = err
-	})
+	}, onPauseToggle)
 
 	_, err := io.Copy(out, r)
 	c.Assert(err, check.Equals, t.wantReadErr)
 	c.Assert(disconnectErr, check.Equals, t.wantDisconnectErr)
 	c.Assert(out.String(), check.Equals, t.wantOut)
 	c.Assert(helpOut.String(), check.Equals, t.wantHelp)
+	c.Assert(pauseToggles, check.Equals, t.wantPauseToggles)
 }
 
 func (s *ReaderSuite) TestNormalReads(c *check.C) {
@@ -170,6 +179,41 @@ func (s *ReaderSuite) TestEscapeDisconnect(c *check.C) {
 	})
 }
 
+func (s *ReaderSuite) TestEscapePause(c *check.C) {
+	c.Log("single pause sequence between reads")
+	s.runCase(c, readerTestCase{
+		inChunks:         [][]byte{[]byte("hello\r~pworld")},
+		wantOut:          "hello\rworld",
+		wantPauseToggles: 1,
+	})
+
+	c.Log("repeated pause sequences")
+	s.runCase(c, readerTestCase{
+		inChunks:         [][]byte{[]byte("hello\r~pworld\n~p")},
+		wantOut:          "hello\rworld\n",
+		wantPauseToggles: 2,
+	})
+
+	c.Log("pause sequence split across reads")
+	s.runCase(c, readerTestCase{
+		inChunks: [][]byte{
+			[]byte("hello\r"),
+			[]byte("~"),
+			[]byte("p"),
+			[]byte("world"),
+		},
+		wantOut:          "hello\rworld",
+		wantPauseToggles: 1,
+	})
+
+	c.Log("pause sequence without a pause handler")
+	s.runCase(c, readerTestCase{
+		inChunks:        [][]byte{[]byte("hello\r~pworld")},
+		nilPauseHandler: true,
+		wantOut:         "hello\r~pworld",
+	})
+}
+
 func (*ReaderSuite) TestBufferOverflow(c *check.C) {
 	in := &mockReader{chunks: [][]byte{make([]byte, 100)}}
 	helpOut := new(bytes.Buffer)
@@ -178,7 +222,7 @@ func (*ReaderSuite) TestBufferOverflow(c *check.C) {
 
 	r := newUnstartedReader(in, helpOut, func(err error) {
 		disconnectErr = err
-	})
+	}, nil)
 	r.bufferLimit = 10
 	go r.runReads()
 
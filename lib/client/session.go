@@ -273,7 +273,7 @@ func (ns *NodeSession) interactiveSession(callback interactiveCallback) error {
 
 	// start piping input into the remote shell and pipe the output from
 	// the remote shell into stdout:
-	ns.pipeInOut(remoteTerm)
+	ns.pipeInOut(remoteTerm, sess)
 
 	// wait for the session to end
 	<-ns.closer.C
@@ -584,7 +584,7 @@ func (ns *NodeSession) watchSignals(shell io.Writer) {
 
 // pipeInOut launches two goroutines: one to pipe the local input into the remote shell,
 // and another to pipe the output of the remote shell into the local output
-func (ns *NodeSession) pipeInOut(shell io.ReadWriteCloser) {
+func (ns *NodeSession) pipeInOut(shell io.ReadWriteCloser, sess *ssh.Session) {
 	// copy from the remote shell to the local output
 	go func() {
 		defer ns.closer.Close()
@@ -600,6 +600,21 @@ func (ns *NodeSession) pipeInOut(shell io.ReadWriteCloser) {
 
 		stdin := ns.terminal.Stdin()
 		if ns.terminal.IsAttached() && ns.enableEscapeSequences {
+			// onPauseToggle alternates between pause and resume requests on
+			// the server-side session. It is only ever called from the escape
+			// reader goroutine, so the paused flag needs no synchronization.
+			paused := false
+			onPauseToggle := func() {
+				reqType := sshutils.PauseRequest
+				if paused {
+					reqType = sshutils.ResumeRequest
+				}
+				if _, err := sess.SendRequest(reqType, false, nil); err != nil {
+					fmt.Fprintf(ns.terminal.Stderr(), "\r\nerror: %v\r\n", trace.Wrap(err))
+					return
+				}
+				paused = !paused
+			}
 			stdin = escape.NewReader(stdin, ns.terminal.Stderr(), func(err error) {
 				switch err {
 				case escape.ErrDisconnect:
@@ -610,7 +625,7 @@ func (ns *NodeSession) pipeInOut(shell io.ReadWriteCloser) {
 					fmt.Fprintf(ns.terminal.Stderr(), "\r\nerror: %v\r\n", trace.Wrap(err))
 				}
 				ns.closer.Close()
-			})
+			}, onPauseToggle)
 		}
 		for {
 			n, err := stdin.Read(buf)
@@ -96,7 +96,12 @@ func GetIdentityWithClient(ctx context.Context, stsClient stsiface.STSAPI) (Iden
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
-	parsedARN, err := arn.Parse(aws.StringValue(out.Arn))
+	return IdentityFromArn(aws.StringValue(out.Arn))
+}
+
+// IdentityFromArn returns an identity corresponding to the provided ARN.
+func IdentityFromArn(arnString string) (Identity, error) {
+	parsedARN, err := arn.Parse(arnString)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
@@ -16,8 +16,8 @@ limitations under the License.
 
 // Package config provides facilities for configuring Teleport daemons
 // including
-//	- parsing YAML configuration
-//	- parsing CLI flags
+//   - parsing YAML configuration
+//   - parsing CLI flags
 package config
 
 import (
@@ -957,6 +957,14 @@ func applySSHConfig(fc *FileConfig, cfg *service.Config) (err error) {
 
 	cfg.SSH.AllowTCPForwarding = fc.SSH.AllowTCPForwarding()
 
+	if fc.SSH.OutboundProxy != nil {
+		outboundProxy, err := fc.SSH.OutboundProxy.Parse()
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		cfg.SSH.OutboundProxy = outboundProxy
+	}
+
 	return nil
 }
 
@@ -1025,6 +1033,30 @@ func applyDatabasesConfig(fc *FileConfig, cfg *service.Config) error {
 				Tags:    matcher.Tags,
 			})
 	}
+	for _, matcher := range fc.Databases.AzureMatchers {
+		cfg.Databases.AzureMatchers = append(cfg.Databases.AzureMatchers,
+			services.AzureMatcher{
+				Types:         matcher.Types,
+				Subscriptions: matcher.Subscriptions,
+				Regions:       matcher.Regions,
+				Tags:          matcher.Tags,
+			})
+	}
+	for _, matcher := range fc.Databases.GCPMatchers {
+		cfg.Databases.GCPMatchers = append(cfg.Databases.GCPMatchers,
+			services.GCPMatcher{
+				Types:      matcher.Types,
+				ProjectIDs: matcher.ProjectIDs,
+				Labels:     matcher.Labels,
+			})
+	}
+	if fc.Databases.OutboundProxy != nil {
+		outboundProxy, err := fc.Databases.OutboundProxy.Parse()
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		cfg.Databases.OutboundProxy = outboundProxy
+	}
 	for _, database := range fc.Databases.Databases {
 		staticLabels := make(map[string]string)
 		if database.StaticLabels != nil {
@@ -1270,6 +1302,7 @@ func applyWindowsDesktopConfig(fc *FileConfig, cfg *service.Config) error {
 		}
 	}
 	cfg.WindowsDesktop.Discovery = fc.WindowsDesktop.Discovery
+	cfg.WindowsDesktop.ScreenshotInterval = fc.WindowsDesktop.ScreenshotInterval.Value()
 
 	var err error
 	cfg.WindowsDesktop.PublicAddrs, err = utils.AddrsFromStrings(fc.WindowsDesktop.PublicAddr, defaults.WindowsDesktopListenPort)
@@ -1280,6 +1313,46 @@ func applyWindowsDesktopConfig(fc *FileConfig, cfg *service.Config) error {
 	if err != nil {
 		return trace.Wrap(err)
 	}
+	cfg.WindowsDesktop.NonADHosts, err = utils.AddrsFromStrings(fc.WindowsDesktop.NonADHosts, defaults.RDPListenPort)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	// The LDAP section is optional when only non-AD hosts are configured.
+	if fc.WindowsDesktop.LDAP.Addr != "" {
+		if err := applyLDAPConfig(fc, cfg); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+
+	for _, rule := range fc.WindowsDesktop.HostLabels {
+		r, err := regexp.Compile(rule.Match)
+		if err != nil {
+			return trace.BadParameter("WindowsDesktopService specifies invalid regexp %q", rule.Match)
+		}
+
+		if len(rule.Labels) == 0 {
+			return trace.BadParameter("WindowsDesktopService host regex %q has no labels", rule.Match)
+		}
+
+		for k := range rule.Labels {
+			if !types.IsValidLabelKey(k) {
+				return trace.BadParameter("WindowsDesktopService specifies invalid label %q", k)
+			}
+		}
+
+		cfg.WindowsDesktop.HostLabels = append(cfg.WindowsDesktop.HostLabels, service.HostLabelRule{
+			Regexp: r,
+			Labels: rule.Labels,
+		})
+	}
+
+	return nil
+}
+
+// applyLDAPConfig applies the ldap section of the "windows_desktop_service"
+// configuration.
+func applyLDAPConfig(fc *FileConfig, cfg *service.Config) error {
 	ldapPassword, err := os.ReadFile(fc.WindowsDesktop.LDAP.PasswordFile)
 	if err != nil {
 		return trace.WrapWithMessage(err, "loading the LDAP password from file %v",
@@ -1319,28 +1392,6 @@ func applyWindowsDesktopConfig(fc *FileConfig, cfg *service.Config) error {
 		CA:                 cert,
 	}
 
-	for _, rule := range fc.WindowsDesktop.HostLabels {
-		r, err := regexp.Compile(rule.Match)
-		if err != nil {
-			return trace.BadParameter("WindowsDesktopService specifies invalid regexp %q", rule.Match)
-		}
-
-		if len(rule.Labels) == 0 {
-			return trace.BadParameter("WindowsDesktopService host regex %q has no labels", rule.Match)
-		}
-
-		for k := range rule.Labels {
-			if !types.IsValidLabelKey(k) {
-				return trace.BadParameter("WindowsDesktopService specifies invalid label %q", k)
-			}
-		}
-
-		cfg.WindowsDesktop.HostLabels = append(cfg.WindowsDesktop.HostLabels, service.HostLabelRule{
-			Regexp: r,
-			Labels: rule.Labels,
-		})
-	}
-
 	return nil
 }
 
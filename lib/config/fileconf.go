@@ -45,6 +45,7 @@ import (
 	"github.com/gravitational/teleport/lib/service"
 	"github.com/gravitational/teleport/lib/services"
 	"github.com/gravitational/teleport/lib/utils"
+	"github.com/gravitational/teleport/lib/utils/proxy"
 
 	"github.com/gravitational/trace"
 
@@ -822,6 +823,10 @@ type SSH struct {
 	// Don't read this value directly: call the AllowTCPForwarding method
 	// instead.
 	MaybeAllowTCPForwarding *bool `yaml:"port_forwarding,omitempty"`
+
+	// OutboundProxy optionally specifies an outbound proxy the node
+	// establishes its reverse tunnel through.
+	OutboundProxy *OutboundProxy `yaml:"outbound_proxy,omitempty"`
 }
 
 // AllowTCPForwarding checks whether the config file allows TCP forwarding or not.
@@ -839,6 +844,37 @@ type CommandLabel struct {
 	Period  time.Duration `yaml:"period"`
 }
 
+// OutboundProxy is an `outbound_proxy` section of a service in the config
+// file. It points the service's reverse tunnel at a corporate HTTP
+// CONNECT or SOCKS5 proxy for networks that don't allow direct egress.
+type OutboundProxy struct {
+	// URL is the proxy URL, e.g. http://proxy.example.com:3128 or
+	// socks5://proxy.example.com:1080.
+	URL string `yaml:"url"`
+	// Username is an optional username to authenticate to the proxy with.
+	Username string `yaml:"username,omitempty"`
+	// Password is an optional password to authenticate to the proxy with.
+	Password string `yaml:"password,omitempty"`
+	// Auth is the authentication method for HTTP CONNECT proxies, "basic"
+	// (default) or "ntlm".
+	Auth string `yaml:"auth,omitempty"`
+}
+
+// Parse validates the section and returns the outbound proxy
+// configuration.
+func (o *OutboundProxy) Parse() (*proxy.OutboundProxyConfig, error) {
+	config := &proxy.OutboundProxyConfig{
+		URL:        o.URL,
+		Username:   o.Username,
+		Password:   o.Password,
+		AuthMethod: o.Auth,
+	}
+	if err := config.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return config, nil
+}
+
 // PAM is configuration for Pluggable Authentication Modules (PAM).
 type PAM struct {
 	// Enabled controls if PAM will be used or not.
@@ -936,6 +972,13 @@ type Databases struct {
 	ResourceMatchers []ResourceMatcher `yaml:"resources,omitempty"`
 	// AWSMatchers match AWS hosted databases.
 	AWSMatchers []AWSMatcher `yaml:"aws,omitempty"`
+	// AzureMatchers match Azure hosted databases.
+	AzureMatchers []AzureMatcher `yaml:"azure,omitempty"`
+	// GCPMatchers match GCP hosted databases.
+	GCPMatchers []GCPMatcher `yaml:"gcp,omitempty"`
+	// OutboundProxy optionally specifies an outbound proxy the database
+	// service establishes its reverse tunnel through.
+	OutboundProxy *OutboundProxy `yaml:"outbound_proxy,omitempty"`
 }
 
 // ResourceMatcher matches cluster resources.
@@ -954,6 +997,28 @@ type AWSMatcher struct {
 	Tags map[string]apiutils.Strings `yaml:"tags,omitempty"`
 }
 
+// AzureMatcher matches Azure databases.
+type AzureMatcher struct {
+	// Types are Azure database types to match, "mysql" or "postgres".
+	Types []string `yaml:"types,omitempty"`
+	// Subscriptions are Azure subscription IDs to query for databases.
+	Subscriptions []string `yaml:"subscriptions,omitempty"`
+	// Regions are Azure regions to filter databases by.
+	Regions []string `yaml:"regions,omitempty"`
+	// Tags are Azure resource tags to match.
+	Tags map[string]apiutils.Strings `yaml:"tags,omitempty"`
+}
+
+// GCPMatcher matches GCP databases.
+type GCPMatcher struct {
+	// Types are GCP database types to match, currently only "cloudsql".
+	Types []string `yaml:"types,omitempty"`
+	// ProjectIDs are GCP project IDs to query for databases.
+	ProjectIDs []string `yaml:"project_ids,omitempty"`
+	// Labels are GCP labels to match.
+	Labels map[string]apiutils.Strings `yaml:"labels,omitempty"`
+}
+
 // Database represents a single database proxied by the service.
 type Database struct {
 	// Name is the name for the database proxy service.
@@ -1361,10 +1426,20 @@ type WindowsDesktopService struct {
 	// Hosts is a list of static Windows hosts connected to this service in
 	// gateway mode.
 	Hosts []string `yaml:"hosts,omitempty"`
+	// NonADHosts is a list of standalone Windows hosts without Active
+	// Directory exposed through this service. Logins use local accounts
+	// with locally provisioned certificates, so the ldap section may be
+	// omitted when only non-AD hosts are configured.
+	NonADHosts []string `yaml:"non_ad_hosts,omitempty"`
 	// HostLabels optionally applies labels to Windows hosts for RBAC.
 	// A host can match multiple rules and will get a union of all
 	// the matched labels.
 	HostLabels []WindowsHostLabelRule `yaml:"host_labels,omitempty"`
+	// ScreenshotInterval, if set, makes the service periodically capture
+	// a still PNG of each desktop session and attach it to the session
+	// recording. Screenshots are also captured on session start and on
+	// clipboard use, regardless of the interval.
+	ScreenshotInterval types.Duration `yaml:"screenshot_interval,omitempty"`
 }
 
 // WindowsHostLabelRule describes how a set of labels should be a applied to
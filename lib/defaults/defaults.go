@@ -503,6 +503,25 @@ const (
 	// differentiate between Cockroach and Postgres databases e.g. when
 	// selecting a CLI client to use.
 	ProtocolCockroachDB = "cockroachdb"
+	// ProtocolSQLServer is the Microsoft SQL Server database protocol.
+	ProtocolSQLServer = "sqlserver"
+	// ProtocolOracle is the Oracle database protocol.
+	ProtocolOracle = "oracle"
+	// ProtocolCassandra is the Cassandra/ScyllaDB database protocol.
+	ProtocolCassandra = "cassandra"
+	// ProtocolElasticsearch is the Elasticsearch/OpenSearch database
+	// protocol.
+	ProtocolElasticsearch = "elasticsearch"
+	// ProtocolClickHouse is the ClickHouse database native TCP protocol.
+	ProtocolClickHouse = "clickhouse"
+	// ProtocolClickHouseHTTP is the ClickHouse database HTTP protocol.
+	ProtocolClickHouseHTTP = "clickhouse-http"
+	// ProtocolSnowflake is the Snowflake database protocol.
+	ProtocolSnowflake = "snowflake"
+	// ProtocolDynamoDB is the AWS DynamoDB database protocol.
+	ProtocolDynamoDB = "dynamodb"
+	// ProtocolRedis is the Redis database protocol.
+	ProtocolRedis = "redis"
 )
 
 // DatabaseProtocols is a list of all supported database protocols.
@@ -511,6 +530,15 @@ var DatabaseProtocols = []string{
 	ProtocolMySQL,
 	ProtocolMongoDB,
 	ProtocolCockroachDB,
+	ProtocolSQLServer,
+	ProtocolOracle,
+	ProtocolCassandra,
+	ProtocolElasticsearch,
+	ProtocolClickHouse,
+	ProtocolClickHouseHTTP,
+	ProtocolSnowflake,
+	ProtocolDynamoDB,
+	ProtocolRedis,
 }
 
 const (
@@ -139,6 +139,15 @@ const (
 	SessionJoinEvent = "session.join"
 	// SessionLeaveEvent indicates that someone left a session
 	SessionLeaveEvent = "session.leave"
+	// SessionPauseEvent indicates that someone paused a session
+	SessionPauseEvent = "session.pause"
+	// SessionResumeEvent indicates that someone resumed a paused session
+	SessionResumeEvent = "session.resume"
+	// SessionKickEvent indicates that a participant was forcibly removed
+	// from a session
+	SessionKickEvent = "session.kick"
+	// SessionTerminateEvent indicates that a session was forcibly terminated
+	SessionTerminateEvent = "session.terminate"
 
 	// Data transfer events.
 	SessionDataEvent = "session.data"
@@ -201,6 +210,12 @@ const (
 	AccessRequestReviewEvent = "access_request.review"
 	// AccessRequestDeleteEvent is emitted when a new access request is deleted.
 	AccessRequestDeleteEvent = "access_request.delete"
+	// AccessRequestAssumeEvent is emitted when a certificate elevating the
+	// user with an approved access request is issued.
+	AccessRequestAssumeEvent = "access_request.assume"
+	// AccessRequestExpireEvent is emitted when the elevation window of an
+	// assumed access request lapses and the elevation is dropped.
+	AccessRequestExpireEvent = "access_request.expire"
 	// AccessRequestDelegator is used by teleport plugins to indicate the identity
 	// which caused them to update state.
 	AccessRequestDelegator = "delegator"
@@ -253,10 +268,16 @@ const (
 	X11ForwardErr     = "error"
 
 	// Port forwarding event
-	PortForwardEvent   = "port"
-	PortForwardAddr    = "addr"
-	PortForwardSuccess = "success"
-	PortForwardErr     = "error"
+	PortForwardEvent = "port"
+	// PortForwardStartEvent is emitted when a port forwarding channel is
+	// opened.
+	PortForwardStartEvent = "port.start"
+	// PortForwardStopEvent is emitted when a port forwarding channel is
+	// closed and carries the bytes transferred over it.
+	PortForwardStopEvent = "port.stop"
+	PortForwardAddr      = "addr"
+	PortForwardSuccess   = "success"
+	PortForwardErr       = "error"
 
 	// AuthAttemptEvent is authentication attempt that either
 	// succeeded or failed based on event status
@@ -265,8 +286,15 @@ const (
 	AuthAttemptErr     = "error"
 	AuthAttemptMessage = "message"
 
+	// BreakGlassLoginEvent is emitted when a pinned identity is allowed
+	// to log in while the auth service is unreachable.
+	BreakGlassLoginEvent = "breakglass.login"
+
 	// SCPEvent means data transfer that occurred on the server
-	SCPEvent          = "scp"
+	SCPEvent = "scp"
+	// SFTPEvent means a file transfer over the SFTP subsystem occurred on
+	// the server
+	SFTPEvent         = "sftp"
 	SCPPath           = "path"
 	SCPLengh          = "len"
 	SCPAction         = "action"
@@ -403,6 +431,10 @@ const (
 	// DatabaseSessionQueryFailedEvent is emitted when database client's request
 	// to execute a database query/command was unsuccessful.
 	DatabaseSessionQueryFailedEvent = "db.session.query.failed"
+	// DatabaseSessionAttributesEvent is emitted at database session start
+	// with the connection attributes the client reported during the
+	// handshake, such as the client program name and driver version.
+	DatabaseSessionAttributesEvent = "db.session.attributes"
 
 	// DatabaseSessionPostgresParseEvent is emitted when a Postgres client
 	// creates a prepared statement using extended query protocol.
@@ -456,6 +488,31 @@ const (
 	// WindowsDesktopSessionEndEvent is emitted when a user  disconnects
 	// from a desktop.
 	WindowsDesktopSessionEndEvent = "windows.desktop.session.end"
+
+	// DesktopClipboardSendEvent is emitted when clipboard data crosses a
+	// desktop connection towards the remote desktop.
+	DesktopClipboardSendEvent = "desktop.clipboard.send"
+	// DesktopClipboardReceiveEvent is emitted when clipboard data crosses
+	// a desktop connection towards the client.
+	DesktopClipboardReceiveEvent = "desktop.clipboard.receive"
+
+	// DesktopDirectoryShareEvent is emitted when the client announces a
+	// local directory shared with a remote desktop.
+	DesktopDirectoryShareEvent = "desktop.directory.share"
+	// DesktopDirectoryReadEvent is emitted when a file in a shared
+	// directory is read by the remote desktop.
+	DesktopDirectoryReadEvent = "desktop.directory.read"
+	// DesktopDirectoryWriteEvent is emitted when a file in a shared
+	// directory is written to by the remote desktop.
+	DesktopDirectoryWriteEvent = "desktop.directory.write"
+	// DesktopSmartcardAuthEvent is emitted when the virtual smart card
+	// answers an in-session certificate request (for example a UAC
+	// prompt) on a remote desktop.
+	DesktopSmartcardAuthEvent = "desktop.smartcard.auth"
+	// DesktopInputThrottledEvent is emitted when a remote desktop client
+	// sends keyboard or mouse input faster than the per-session rate
+	// limit and the excess input is dropped.
+	DesktopInputThrottledEvent = "desktop.input.throttled"
 )
 
 const (
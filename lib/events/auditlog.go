@@ -98,7 +98,31 @@ var (
 		},
 	)
 
-	prometheusCollectors = []prometheus.Collector{auditOpenFiles, auditDiskUsed, auditFailedDisk, AuditFailedEmit}
+	auditEmittedEvents = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "audit_emitted_events",
+			Help: "Number of audit events successfully emitted.",
+		},
+	)
+
+	auditRetriedEvents = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "audit_retried_emit_events",
+			Help: "Number of audit events replayed to a recovered audit stream.",
+		},
+	)
+
+	auditBufferedEvents = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "audit_buffered_emit_events",
+			Help: "Number of audit events buffered to local disk after a failed emit.",
+		},
+	)
+
+	prometheusCollectors = []prometheus.Collector{
+		auditOpenFiles, auditDiskUsed, auditFailedDisk, AuditFailedEmit,
+		auditEmittedEvents, auditRetriedEvents, auditBufferedEvents,
+	}
 )
 
 // AuditLog is a new combined facility to record Teleport events and
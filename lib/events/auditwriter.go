@@ -459,6 +459,7 @@ func (a *AuditWriter) recoverStream() error {
 			a.closeStream(a.stream)
 			return trace.Wrap(err)
 		}
+		auditRetriedEvents.Inc()
 	}
 	a.log.Debugf("Replayed buffer of %v events to stream in %v", len(a.buffer), time.Since(start))
 	return nil
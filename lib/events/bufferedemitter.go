@@ -0,0 +1,226 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gravitational/teleport"
+	apievents "github.com/gravitational/teleport/api/types/events"
+	"github.com/gravitational/teleport/lib/utils"
+
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultBufferedEmitterFlushInterval is how often buffered events are
+// retried against the inner emitter when no interval is configured.
+const defaultBufferedEmitterFlushInterval = 30 * time.Second
+
+// bufferedEventExt is the file extension of buffered event files.
+const bufferedEventExt = ".event"
+
+// BufferedEmitterConfig provides parameters for the buffered emitter.
+type BufferedEmitterConfig struct {
+	// Inner emits events to the upstream audit log.
+	Inner apievents.Emitter
+	// Dir is the directory failed events are buffered in.
+	Dir string
+	// Clock is a clock interface, used in tests.
+	Clock clockwork.Clock
+	// UIDGenerator is unique ID generator, used to name buffered event
+	// files.
+	UIDGenerator utils.UID
+	// FlushInterval is how often buffered events are retried.
+	FlushInterval time.Duration
+	// Context is the close context for the background flush loop.
+	Context context.Context
+}
+
+// CheckAndSetDefaults checks and sets default values.
+func (c *BufferedEmitterConfig) CheckAndSetDefaults() error {
+	if c.Inner == nil {
+		return trace.BadParameter("missing parameter Inner")
+	}
+	if c.Dir == "" {
+		return trace.BadParameter("missing parameter Dir")
+	}
+	if c.Clock == nil {
+		c.Clock = clockwork.NewRealClock()
+	}
+	if c.UIDGenerator == nil {
+		c.UIDGenerator = utils.NewRealUID()
+	}
+	if c.FlushInterval == 0 {
+		c.FlushInterval = defaultBufferedEmitterFlushInterval
+	}
+	if c.Context == nil {
+		c.Context = context.Background()
+	}
+	return nil
+}
+
+// BufferedEmitter passes events through to the inner emitter and spools
+// them to a local directory when emitting fails, for example while the
+// auth service is unreachable. Spooled events are replayed in the
+// background once the inner emitter accepts events again so the audit
+// record stays complete across connectivity outages.
+type BufferedEmitter struct {
+	BufferedEmitterConfig
+	log *logrus.Entry
+	// mtx serializes access to the buffer directory between the emit and
+	// flush paths.
+	mtx sync.Mutex
+}
+
+// NewBufferedEmitter returns a new buffered emitter and starts its
+// background flush loop.
+func NewBufferedEmitter(cfg BufferedEmitterConfig) (*BufferedEmitter, error) {
+	if err := cfg.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if err := os.MkdirAll(cfg.Dir, 0700); err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	if err := utils.RegisterPrometheusCollectors(prometheusCollectors...); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	emitter := &BufferedEmitter{
+		BufferedEmitterConfig: cfg,
+		log: logrus.WithFields(logrus.Fields{
+			trace.Component: teleport.ComponentAuditLog,
+		}),
+	}
+	go emitter.flushLoop()
+	return emitter, nil
+}
+
+// EmitAuditEvent emits the event to the inner emitter, buffering it
+// locally when emitting fails.
+func (b *BufferedEmitter) EmitAuditEvent(ctx context.Context, event apievents.AuditEvent) error {
+	err := b.Inner.EmitAuditEvent(ctx, event)
+	if err == nil {
+		return nil
+	}
+	if errBuffer := b.buffer(event); errBuffer != nil {
+		return trace.NewAggregate(err, errBuffer)
+	}
+	b.log.WithError(err).Warnf("Failed to emit audit event %v, buffered it locally.", event.GetType())
+	return nil
+}
+
+// buffer serializes the event to a file in the buffer directory.
+func (b *BufferedEmitter) buffer(event apievents.AuditEvent) error {
+	fields, err := ToEventFields(event)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	// Prefix file names with the emit time so events replay in order.
+	name := fmt.Sprintf("%v-%v%v", b.Clock.Now().UTC().UnixNano(), b.UIDGenerator.New(), bufferedEventExt)
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if err := ioutil.WriteFile(filepath.Join(b.Dir, name), data, 0600); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	auditBufferedEvents.Inc()
+	return nil
+}
+
+// flushLoop periodically replays buffered events until the close context
+// is canceled.
+func (b *BufferedEmitter) flushLoop() {
+	ticker := time.NewTicker(b.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := b.Flush(b.Context); err != nil {
+				b.log.WithError(err).Debug("Failed to flush buffered audit events.")
+			}
+		case <-b.Context.Done():
+			return
+		}
+	}
+}
+
+// Flush replays buffered events to the inner emitter in the order they
+// were buffered, stopping at the first event the emitter doesn't accept.
+func (b *BufferedEmitter) Flush(ctx context.Context) error {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	infos, err := ioutil.ReadDir(b.Dir)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name() < infos[j].Name() })
+	for _, info := range infos {
+		if info.IsDir() || !strings.HasSuffix(info.Name(), bufferedEventExt) {
+			continue
+		}
+		path := filepath.Join(b.Dir, info.Name())
+		event, err := readBufferedEvent(path)
+		if err != nil {
+			// Don't let a corrupt file wedge the replay, move it out of
+			// the way instead of deleting potential audit data.
+			b.log.WithError(err).Warnf("Failed to parse buffered audit event %v.", path)
+			if err := os.Rename(path, path+".corrupt"); err != nil {
+				return trace.ConvertSystemError(err)
+			}
+			continue
+		}
+		if err := b.Inner.EmitAuditEvent(ctx, event); err != nil {
+			// The emitter is likely still unavailable, retry on the next
+			// flush.
+			return trace.Wrap(err)
+		}
+		if err := os.Remove(path); err != nil {
+			return trace.ConvertSystemError(err)
+		}
+	}
+	return nil
+}
+
+// readBufferedEvent reads and deserializes a buffered event file.
+func readBufferedEvent(path string) (apievents.AuditEvent, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	var fields EventFields
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	event, err := FromEventFields(fields)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return event, nil
+}
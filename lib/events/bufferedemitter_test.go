@@ -0,0 +1,109 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	apievents "github.com/gravitational/teleport/api/types/events"
+
+	"github.com/gravitational/trace"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBufferedEmitter verifies that events rejected by the inner emitter
+// are spooled to disk and replayed in order once the emitter recovers.
+func TestBufferedEmitter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dir := t.TempDir()
+	inner := &toggleEmitter{failing: true}
+	emitter, err := NewBufferedEmitter(BufferedEmitterConfig{
+		Inner:         inner,
+		Dir:           dir,
+		FlushInterval: time.Hour,
+		Context:       ctx,
+	})
+	require.NoError(t, err)
+
+	events := []apievents.AuditEvent{
+		&apievents.AuthAttempt{
+			Metadata: apievents.Metadata{
+				Type: AuthAttemptEvent,
+				Code: AuthAttemptFailureCode,
+				Time: time.Now().UTC(),
+			},
+			UserMetadata: apievents.UserMetadata{User: "alice"},
+		},
+		&apievents.AuthAttempt{
+			Metadata: apievents.Metadata{
+				Type: BreakGlassLoginEvent,
+				Code: BreakGlassLoginCode,
+				Time: time.Now().UTC(),
+			},
+			UserMetadata: apievents.UserMetadata{User: "bob"},
+		},
+	}
+	for _, event := range events {
+		require.NoError(t, emitter.EmitAuditEvent(ctx, event))
+	}
+
+	// Events were buffered, not delivered.
+	require.Empty(t, inner.events)
+	infos, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, infos, 2)
+
+	// While the inner emitter keeps failing, flushing keeps the buffer
+	// intact.
+	require.Error(t, emitter.Flush(ctx))
+	infos, err = ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, infos, 2)
+
+	// Once the emitter recovers, buffered events replay in order and the
+	// buffer drains.
+	inner.failing = false
+	require.NoError(t, emitter.Flush(ctx))
+	require.Len(t, inner.events, 2)
+	require.Equal(t, AuthAttemptEvent, inner.events[0].GetType())
+	require.Equal(t, BreakGlassLoginEvent, inner.events[1].GetType())
+	infos, err = ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Empty(t, infos)
+
+	// A healthy emitter receives events directly without buffering.
+	require.NoError(t, emitter.EmitAuditEvent(ctx, events[0]))
+	require.Len(t, inner.events, 3)
+}
+
+type toggleEmitter struct {
+	failing bool
+	events  []apievents.AuditEvent
+}
+
+func (t *toggleEmitter) EmitAuditEvent(ctx context.Context, event apievents.AuditEvent) error {
+	if t.failing {
+		return trace.ConnectionProblem(nil, "emitter unavailable")
+	}
+	t.events = append(t.events, event)
+	return nil
+}
@@ -85,6 +85,33 @@ var (
 		Name: SessionLeaveEvent,
 		Code: SessionLeaveCode,
 	}
+	// SessionPauseE is emitted when a user pauses the session.
+	SessionPauseE = Event{
+		Name: SessionPauseEvent,
+		Code: SessionPauseCode,
+	}
+	// SessionResumeE is emitted when a user resumes a paused session.
+	SessionResumeE = Event{
+		Name: SessionResumeEvent,
+		Code: SessionResumeCode,
+	}
+	// SessionKickE is emitted when a participant is forcibly removed from
+	// the session.
+	SessionKickE = Event{
+		Name: SessionKickEvent,
+		Code: SessionKickCode,
+	}
+	// SessionTerminateE is emitted when the session is forcibly terminated.
+	SessionTerminateE = Event{
+		Name: SessionTerminateEvent,
+		Code: SessionTerminateCode,
+	}
+	// SessionDurationLimitE is emitted when the session is terminated
+	// because it reached its maximum duration.
+	SessionDurationLimitE = Event{
+		Name: SessionTerminateEvent,
+		Code: SessionDurationLimitCode,
+	}
 	// SessionEndE is emitted when a user ends the session.
 	SessionEndE = Event{
 		Name: SessionEndEvent,
@@ -140,6 +167,16 @@ var (
 		Name: PortForwardEvent,
 		Code: PortForwardFailureCode,
 	}
+	// PortForwardStartE is emitted when a port forwarding channel is opened.
+	PortForwardStartE = Event{
+		Name: PortForwardStartEvent,
+		Code: PortForwardStartCode,
+	}
+	// PortForwardStopE is emitted when a port forwarding channel is closed.
+	PortForwardStopE = Event{
+		Name: PortForwardStopEvent,
+		Code: PortForwardStopCode,
+	}
 	// SCPDownloadE is emitted when a user downloads a file.
 	SCPDownloadE = Event{
 		Name: SCPEvent,
@@ -160,6 +197,16 @@ var (
 		Name: SCPEvent,
 		Code: SCPUploadFailureCode,
 	}
+	// SFTPDownloadE is emitted when a user downloads a file over SFTP.
+	SFTPDownloadE = Event{
+		Name: SFTPEvent,
+		Code: SFTPDownloadCode,
+	}
+	// SFTPUploadE is emitted when a user uploads a file over SFTP.
+	SFTPUploadE = Event{
+		Name: SFTPEvent,
+		Code: SFTPUploadCode,
+	}
 	// ClientDisconnectE is emitted when a user session is disconnected.
 	ClientDisconnectE = Event{
 		Name: ClientDisconnectEvent,
@@ -179,6 +226,18 @@ var (
 		Name: AccessRequestUpdateEvent,
 		Code: AccessRequestUpdateCode,
 	}
+	// AccessRequestAssumedE is emitted when an approved access request is
+	// assumed by issuing an elevated certificate.
+	AccessRequestAssumedE = Event{
+		Name: AccessRequestAssumeEvent,
+		Code: AccessRequestAssumeCode,
+	}
+	// AccessRequestExpiredE is emitted when an assumed access request's
+	// elevation window lapses.
+	AccessRequestExpiredE = Event{
+		Name: AccessRequestExpireEvent,
+		Code: AccessRequestExpireCode,
+	}
 	// SessionCommandE is emitted upon execution of a command when using enhanced
 	// session recording.
 	SessionCommandE = Event{
@@ -196,6 +255,12 @@ var (
 		Name: SessionNetworkEvent,
 		Code: SessionNetworkCode,
 	}
+	// SessionCommandDenyE is emitted when a command denied by the user's
+	// roles is executed and killed during an enhanced recording session.
+	SessionCommandDenyE = Event{
+		Name: SessionCommandEvent,
+		Code: SessionCommandDenyCode,
+	}
 	// ResetPasswordTokenCreatedE is emitted when a password reset token is created.
 	ResetPasswordTokenCreatedE = Event{
 		Name: ResetPasswordTokenCreateEvent,
@@ -348,6 +413,22 @@ const (
 	// SessionConnectCode is the session connect event code.
 	SessionConnectCode = "T2010I"
 
+	// SessionPauseCode is the session pause event code.
+	SessionPauseCode = "T2011I"
+	// SessionResumeCode is the session resume event code.
+	SessionResumeCode = "T2012I"
+	// SessionKickCode is the session kick event code.
+	SessionKickCode = "T2013I"
+	// SessionTerminateCode is the session terminate event code.
+	SessionTerminateCode = "T2014I"
+	// SessionDurationLimitCode is the code of the session terminate event
+	// emitted when the session reached its maximum duration.
+	SessionDurationLimitCode = "T2015I"
+	// SessionDataPeriodicCode is the code of the session data event emitted
+	// periodically while a session is still in progress, as opposed to the
+	// final report emitted when the connection closes.
+	SessionDataPeriodicCode = "T2017I"
+
 	// DatabaseSessionStartCode is the database session start event code.
 	DatabaseSessionStartCode = "TDB00I"
 	// DatabaseSessionStartFailureCode is the database session start failure event code.
@@ -358,6 +439,9 @@ const (
 	DatabaseSessionQueryCode = "TDB02I"
 	// DatabaseSessionQueryFailedCode is the database query failure event code.
 	DatabaseSessionQueryFailedCode = "TDB02W"
+	// DatabaseSessionAttributesCode is the database session connection
+	// attributes event code.
+	DatabaseSessionAttributesCode = "TDB06I"
 
 	// PostgresParseCode is the db.session.postgres.parse event code.
 	PostgresParseCode = "TPG00I"
@@ -384,6 +468,24 @@ const (
 	DesktopSessionStartFailureCode = "TDP00W"
 	// DesktopSessionEndCode is the desktop session end event code.
 	DesktopSessionEndCode = "TDP01I"
+	// DesktopClipboardSendCode is the desktop clipboard send event code.
+	DesktopClipboardSendCode = "TDP02I"
+	// DesktopClipboardReceiveCode is the desktop clipboard receive event code.
+	DesktopClipboardReceiveCode = "TDP03I"
+	// DesktopDirectoryShareCode is the desktop directory share event code.
+	DesktopDirectoryShareCode = "TDP04I"
+	// DesktopDirectoryReadCode is the desktop directory read event code.
+	DesktopDirectoryReadCode = "TDP05I"
+	// DesktopDirectoryWriteCode is the desktop directory write event code.
+	DesktopDirectoryWriteCode = "TDP06I"
+	// DesktopSmartcardAuthCode is the desktop in-session smart card
+	// authentication event code.
+	DesktopSmartcardAuthCode = "TDP07I"
+	// DesktopSmartcardAuthFailureCode is the desktop in-session smart
+	// card authentication denied event code.
+	DesktopSmartcardAuthFailureCode = "TDP07W"
+	// DesktopInputThrottledCode is the desktop input throttled event code.
+	DesktopInputThrottledCode = "TDP08W"
 
 	// SubsystemCode is the subsystem event code.
 	SubsystemCode = "T3001I"
@@ -405,10 +507,20 @@ const (
 	SCPUploadCode = "T3005I"
 	// SCPUploadFailureCode is the file upload failure event code.
 	SCPUploadFailureCode = "T3005E"
+	// SFTPDownloadCode is the SFTP file download event code.
+	SFTPDownloadCode = "T3010I"
+	// SFTPUploadCode is the SFTP file upload event code.
+	SFTPUploadCode = "T3011I"
+	// PortForwardStartCode is the port forward channel start event code.
+	PortForwardStartCode = "T3012I"
+	// PortForwardStopCode is the port forward channel stop event code.
+	PortForwardStopCode = "T3013I"
 	// ClientDisconnectCode is the client disconnect event code.
 	ClientDisconnectCode = "T3006I"
 	// AuthAttemptFailureCode is the auth attempt failure event code.
 	AuthAttemptFailureCode = "T3007W"
+	// BreakGlassLoginCode is the break-glass login event code.
+	BreakGlassLoginCode = "T3014W"
 	// X11ForwardCode is the x11 forward event code.
 	X11ForwardCode = "T3008I"
 	// X11ForwardFailureCode is the x11 forward failure event code.
@@ -425,6 +537,9 @@ const (
 	SessionDiskCode = "T4001I"
 	// SessionNetworkCode is a session network code.
 	SessionNetworkCode = "T4002I"
+	// SessionCommandDenyCode is the code of a session command that was
+	// denied by the user's roles and killed.
+	SessionCommandDenyCode = "T4003W"
 
 	// AccessRequestCreateCode is the the access request creation code.
 	AccessRequestCreateCode = "T5000I"
@@ -434,6 +549,10 @@ const (
 	AccessRequestReviewCode = "T5002I"
 	// AccessRequestDeleteCode is the access request deleted code.
 	AccessRequestDeleteCode = "T5003I"
+	// AccessRequestAssumeCode is the access request assumption code.
+	AccessRequestAssumeCode = "T5004I"
+	// AccessRequestExpireCode is the access request elevation expiry code.
+	AccessRequestExpireCode = "T5005I"
 
 	// ResetPasswordTokenCreateCode is the token create event code.
 	ResetPasswordTokenCreateCode = "T6000I"
@@ -53,6 +53,15 @@ func FromEventFields(fields EventFields) (apievents.AuditEvent, error) {
 		e = &events.SessionJoin{}
 	case SessionLeaveEvent:
 		e = &events.SessionLeave{}
+	case SessionPauseEvent, SessionResumeEvent:
+		// Pause and resume reuse the session join wire message, they carry
+		// the same server, session and user metadata and no extra fields.
+		e = &events.SessionJoin{}
+	case SessionKickEvent, SessionTerminateEvent:
+		// Kick and terminate reuse the session leave wire message, they
+		// carry the same server, session and user metadata and no extra
+		// fields.
+		e = &events.SessionLeave{}
 	case SessionDataEvent:
 		e = &events.SessionData{}
 	case ClientDisconnectEvent:
@@ -77,6 +86,10 @@ func FromEventFields(fields EventFields) (apievents.AuditEvent, error) {
 		e = &events.AccessRequestCreate{}
 	case AccessRequestUpdateEvent:
 		e = &events.AccessRequestCreate{}
+	case AccessRequestAssumeEvent:
+		e = &events.AccessRequestCreate{}
+	case AccessRequestExpireEvent:
+		e = &events.AccessRequestCreate{}
 	case BillingCardCreateEvent:
 		e = &events.BillingCardCreate{}
 	case BillingCardUpdateEvent:
@@ -95,10 +108,22 @@ func FromEventFields(fields EventFields) (apievents.AuditEvent, error) {
 		e = &events.X11Forward{}
 	case PortForwardEvent:
 		e = &events.PortForward{}
+	case PortForwardStartEvent, PortForwardStopEvent:
+		// Start and stop of a port forwarding channel reuse the port
+		// forward wire message, they carry the same target address.
+		e = &events.PortForward{}
 	case AuthAttemptEvent:
 		e = &events.AuthAttempt{}
+	case BreakGlassLoginEvent:
+		// Break-glass logins reuse the auth attempt wire message, they
+		// carry the same identity and connection metadata.
+		e = &events.AuthAttempt{}
 	case SCPEvent:
 		e = &events.SCP{}
+	case SFTPEvent:
+		// SFTP transfers reuse the scp wire message, they carry the same
+		// path, action and command metadata.
+		e = &events.SCP{}
 	case ResizeEvent:
 		e = &events.Resize{}
 	case SessionCommandEvent:
@@ -155,6 +180,10 @@ func FromEventFields(fields EventFields) (apievents.AuditEvent, error) {
 		e = &events.DatabaseSessionEnd{}
 	case DatabaseSessionQueryEvent, DatabaseSessionQueryFailedEvent:
 		e = &events.DatabaseSessionQuery{}
+	// The connection attributes event reuses the database session start
+	// wire message, the attributes are carried in the status user message.
+	case DatabaseSessionAttributesEvent:
+		e = &events.DatabaseSessionStart{}
 	case DatabaseSessionPostgresParseEvent:
 		e = &events.PostgresParse{}
 	case DatabaseSessionPostgresBindEvent:
@@ -187,6 +216,17 @@ func FromEventFields(fields EventFields) (apievents.AuditEvent, error) {
 		e = &events.WindowsDesktopSessionStart{}
 	case WindowsDesktopSessionEndEvent:
 		e = &events.WindowsDesktopSessionEnd{}
+	// Desktop clipboard and directory sharing events reuse the desktop
+	// session start wire message, the details of the transfer are carried
+	// in the status user message.
+	case DesktopClipboardSendEvent, DesktopClipboardReceiveEvent:
+		e = &events.WindowsDesktopSessionStart{}
+	case DesktopDirectoryShareEvent, DesktopDirectoryReadEvent, DesktopDirectoryWriteEvent:
+		e = &events.WindowsDesktopSessionStart{}
+	case DesktopSmartcardAuthEvent:
+		e = &events.WindowsDesktopSessionStart{}
+	case DesktopInputThrottledEvent:
+		e = &events.WindowsDesktopSessionStart{}
 	case SessionConnectEvent:
 		e = &events.SessionConnect{}
 	case AccessRequestDeleteEvent:
@@ -132,6 +132,9 @@ func NewCheckingEmitter(cfg CheckingEmitterConfig) (*CheckingEmitter, error) {
 	if err := cfg.CheckAndSetDefaults(); err != nil {
 		return nil, trace.Wrap(err)
 	}
+	if err := utils.RegisterPrometheusCollectors(prometheusCollectors...); err != nil {
+		return nil, trace.Wrap(err)
+	}
 	return &CheckingEmitter{
 		CheckingEmitterConfig: cfg,
 	}, nil
@@ -169,6 +172,7 @@ func (r *CheckingEmitter) EmitAuditEvent(ctx context.Context, event apievents.Au
 		log.WithError(err).Errorf("Failed to emit audit event.")
 		return trace.Wrap(err)
 	}
+	auditEmittedEvents.Inc()
 	return nil
 }
 
@@ -483,6 +487,7 @@ func (s *CheckingStream) EmitAuditEvent(ctx context.Context, event apievents.Aud
 		log.WithError(err).Errorf("Failed to emit audit event %v(%v).", event.GetType(), event.GetCode())
 		return trace.Wrap(err)
 	}
+	auditEmittedEvents.Inc()
 	return nil
 }
 
@@ -0,0 +1,355 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package encryptedsessions wraps a session upload handler with envelope
+// encryption, so recordings are encrypted at rest in whatever storage
+// backend is configured. Each uploaded part carries its own header with
+// the wrapped data key, which keeps parts self-contained: uploads can be
+// resumed after a restart and parts can be decrypted in isolation.
+// Download decrypts transparently, so playback through the auth server
+// (and with it "tsh play") works unchanged for authorized users.
+package encryptedsessions
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/gravitational/teleport/lib/events"
+	"github.com/gravitational/teleport/lib/session"
+
+	"github.com/gravitational/trace"
+)
+
+const (
+	// magic marks the beginning of an encrypted part.
+	magic = "TELEENC2"
+
+	// chunkSize is the amount of plaintext sealed per chunk. Chunks are
+	// framed with their length, so the stream can be decrypted without
+	// knowing part boundaries.
+	chunkSize = 64 * 1024
+
+	// keySize is the size of the per-part data key (AES-256).
+	keySize = 32
+
+	// frameChunk marks a sealed chunk of recording data.
+	frameChunk = 0x00
+	// frameFinal marks the sealed end-of-stream marker terminating an
+	// envelope.
+	frameFinal = 0x01
+)
+
+// Config is the encrypted handler configuration.
+type Config struct {
+	// Inner is the wrapped upload handler that stores the ciphertext.
+	Inner events.MultipartHandler
+	// KeyWrapper wraps and unwraps the per-part data keys, for example
+	// with an RSA recipient key or a KMS key.
+	KeyWrapper KeyWrapper
+}
+
+// CheckAndSetDefaults checks and sets default values
+func (c *Config) CheckAndSetDefaults() error {
+	if c.Inner == nil {
+		return trace.BadParameter("missing parameter Inner")
+	}
+	if c.KeyWrapper == nil {
+		return trace.BadParameter("missing parameter KeyWrapper")
+	}
+	return nil
+}
+
+// NewHandler returns a handler that encrypts uploaded session
+// recordings and decrypts them on download.
+func NewHandler(cfg Config) (*Handler, error) {
+	if err := cfg.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &Handler{cfg: cfg}, nil
+}
+
+// Handler wraps an upload handler with envelope encryption.
+type Handler struct {
+	cfg Config
+}
+
+// Upload encrypts the session recording and uploads it to the inner
+// handler.
+func (h *Handler) Upload(ctx context.Context, sessionID session.ID, reader io.Reader) (string, error) {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(h.encrypt(ctx, sessionID, pw, reader))
+	}()
+	url, err := h.cfg.Inner.Upload(ctx, sessionID, pr)
+	pr.Close()
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	return url, nil
+}
+
+// Download downloads the session recording from the inner handler and
+// decrypts it into writer.
+func (h *Handler) Download(ctx context.Context, sessionID session.ID, writer io.WriterAt) error {
+	// The ciphertext lands in a temporary file first: the inner handler
+	// needs an io.WriterAt, which cannot be decrypted in flight.
+	f, err := ioutil.TempFile("", string(sessionID))
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	if err := h.cfg.Inner.Download(ctx, sessionID, f); err != nil {
+		return trace.Wrap(err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	if err := h.decrypt(ctx, sessionID, &sequentialWriter{w: writer}, f); err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// CreateUpload creates a multipart upload
+func (h *Handler) CreateUpload(ctx context.Context, sessionID session.ID) (*events.StreamUpload, error) {
+	upload, err := h.cfg.Inner.CreateUpload(ctx, sessionID)
+	return upload, trace.Wrap(err)
+}
+
+// CompleteUpload completes the upload
+func (h *Handler) CompleteUpload(ctx context.Context, upload events.StreamUpload, parts []events.StreamPart) error {
+	return trace.Wrap(h.cfg.Inner.CompleteUpload(ctx, upload, parts))
+}
+
+// UploadPart encrypts and uploads a part. Each part is a self-contained
+// envelope, so uploads resumed after a restart keep working.
+func (h *Handler) UploadPart(ctx context.Context, upload events.StreamUpload, partNumber int64, partBody io.ReadSeeker) (*events.StreamPart, error) {
+	var buf bytes.Buffer
+	if err := h.encrypt(ctx, session.ID(upload.SessionID), &buf, partBody); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	part, err := h.cfg.Inner.UploadPart(ctx, upload, partNumber, bytes.NewReader(buf.Bytes()))
+	return part, trace.Wrap(err)
+}
+
+// ListParts returns all uploaded parts for the completed upload in sorted order
+func (h *Handler) ListParts(ctx context.Context, upload events.StreamUpload) ([]events.StreamPart, error) {
+	parts, err := h.cfg.Inner.ListParts(ctx, upload)
+	return parts, trace.Wrap(err)
+}
+
+// ListUploads lists uploads that have been initiated but not completed
+func (h *Handler) ListUploads(ctx context.Context) ([]events.StreamUpload, error) {
+	uploads, err := h.cfg.Inner.ListUploads(ctx)
+	return uploads, trace.Wrap(err)
+}
+
+// GetUploadMetadata gets the upload metadata
+func (h *Handler) GetUploadMetadata(sessionID session.ID) events.UploadMetadata {
+	return h.cfg.Inner.GetUploadMetadata(sessionID)
+}
+
+// encrypt writes an encrypted envelope of everything read from reader:
+// a header with the wrapped data key followed by framed AES-GCM chunks.
+//
+// Each chunk is sealed with its sequence number as the nonce, which is
+// safe with the fresh per-envelope key and makes reordered or dropped
+// chunks fail authentication on decrypt. The session ID and the frame
+// type are bound as additional authenticated data, so chunks cannot be
+// spliced in from a recording of another session and the end-of-stream
+// marker cannot be forged: an envelope that does not end in an
+// authenticated final frame was truncated.
+func (h *Handler) encrypt(ctx context.Context, sessionID session.ID, w io.Writer, reader io.Reader) error {
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return trace.Wrap(err)
+	}
+	wrapped, keyID, err := h.cfg.KeyWrapper.WrapKey(ctx, key)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	if _, err := w.Write([]byte(magic)); err != nil {
+		return trace.Wrap(err)
+	}
+	if err := writeFrame(w, []byte(keyID)); err != nil {
+		return trace.Wrap(err)
+	}
+	if err := writeFrame(w, wrapped); err != nil {
+		return trace.Wrap(err)
+	}
+
+	plaintext := make([]byte, chunkSize)
+	nonce := make([]byte, aead.NonceSize())
+	var seq uint64
+	for {
+		n, err := io.ReadFull(reader, plaintext)
+		if n > 0 {
+			binary.BigEndian.PutUint64(nonce[len(nonce)-8:], seq)
+			sealed := aead.Seal([]byte{frameChunk}, nonce, plaintext[:n], chunkAAD(sessionID, frameChunk))
+			if err := writeFrame(w, sealed); err != nil {
+				return trace.Wrap(err)
+			}
+			seq++
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			// An authenticated final frame terminates the envelope, so
+			// envelopes can be concatenated (as completed parts are) and
+			// a truncated envelope is detected.
+			binary.BigEndian.PutUint64(nonce[len(nonce)-8:], seq)
+			sealed := aead.Seal([]byte{frameFinal}, nonce, nil, chunkAAD(sessionID, frameFinal))
+			return trace.Wrap(writeFrame(w, sealed))
+		}
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+}
+
+// decrypt reads a sequence of encrypted envelopes from reader and
+// writes the plaintext to w.
+func (h *Handler) decrypt(ctx context.Context, sessionID session.ID, w io.Writer, reader io.Reader) error {
+	header := make([]byte, len(magic))
+	for {
+		if _, err := io.ReadFull(reader, header); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return trace.Wrap(err)
+		}
+		if string(header) != magic {
+			return trace.BadParameter("session recording is not encrypted or is corrupted")
+		}
+		keyID, err := readFrame(reader)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		wrapped, err := readFrame(reader)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		key, err := h.cfg.KeyWrapper.UnwrapKey(ctx, wrapped, string(keyID))
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		nonce := make([]byte, aead.NonceSize())
+		var seq uint64
+		for {
+			frame, err := readFrame(reader)
+			if err != nil {
+				return trace.Wrap(err)
+			}
+			if len(frame) == 0 {
+				return trace.BadParameter("session recording chunk is corrupted")
+			}
+			frameType := frame[0]
+			if frameType != frameChunk && frameType != frameFinal {
+				return trace.BadParameter("session recording chunk is corrupted")
+			}
+			// The nonce is the expected sequence number, a reordered,
+			// duplicated or dropped chunk fails to authenticate.
+			binary.BigEndian.PutUint64(nonce[len(nonce)-8:], seq)
+			plaintext, err := aead.Open(nil, nonce, frame[1:], chunkAAD(sessionID, frameType))
+			if err != nil {
+				return trace.BadParameter("failed to decrypt session recording chunk: %v", err)
+			}
+			seq++
+			if frameType == frameFinal {
+				break
+			}
+			if _, err := w.Write(plaintext); err != nil {
+				return trace.Wrap(err)
+			}
+		}
+	}
+}
+
+// chunkAAD returns the additional authenticated data chunks are sealed
+// with, binding the chunk to the session it was recorded for and to its
+// frame type.
+func chunkAAD(sessionID session.ID, frameType byte) []byte {
+	return append([]byte(sessionID), frameType)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return aead, nil
+}
+
+// writeFrame writes a length-prefixed blob.
+func writeFrame(w io.Writer, data []byte) error {
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(data)))
+	if _, err := w.Write(length[:]); err != nil {
+		return trace.Wrap(err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// readFrame reads a length-prefixed blob.
+func readFrame(r io.Reader) ([]byte, error) {
+	var length [4]byte
+	if _, err := io.ReadFull(r, length[:]); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	size := binary.BigEndian.Uint32(length[:])
+	if size == 0 {
+		return nil, nil
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return data, nil
+}
+
+// sequentialWriter adapts an io.WriterAt to sequential writes.
+type sequentialWriter struct {
+	w      io.WriterAt
+	offset int64
+}
+
+func (s *sequentialWriter) Write(p []byte) (int, error) {
+	n, err := s.w.WriteAt(p, s.offset)
+	s.offset += int64(n)
+	return n, err
+}
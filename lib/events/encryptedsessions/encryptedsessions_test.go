@@ -0,0 +1,212 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encryptedsessions
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gravitational/teleport/lib/events"
+	"github.com/gravitational/teleport/lib/events/filesessions"
+	"github.com/gravitational/teleport/lib/session"
+
+	"github.com/stretchr/testify/require"
+)
+
+// discardUploader is a stub inner handler for tests that exercise the
+// encryption helpers directly.
+type discardUploader struct{}
+
+func (discardUploader) Upload(ctx context.Context, sessionID session.ID, reader io.Reader) (string, error) {
+	return "", nil
+}
+func (discardUploader) Download(ctx context.Context, sessionID session.ID, writer io.WriterAt) error {
+	return nil
+}
+func (discardUploader) CreateUpload(ctx context.Context, sessionID session.ID) (*events.StreamUpload, error) {
+	return &events.StreamUpload{}, nil
+}
+func (discardUploader) CompleteUpload(ctx context.Context, upload events.StreamUpload, parts []events.StreamPart) error {
+	return nil
+}
+func (discardUploader) UploadPart(ctx context.Context, upload events.StreamUpload, partNumber int64, partBody io.ReadSeeker) (*events.StreamPart, error) {
+	return &events.StreamPart{}, nil
+}
+func (discardUploader) ListParts(ctx context.Context, upload events.StreamUpload) ([]events.StreamPart, error) {
+	return nil, nil
+}
+func (discardUploader) ListUploads(ctx context.Context) ([]events.StreamUpload, error) {
+	return nil, nil
+}
+func (discardUploader) GetUploadMetadata(sessionID session.ID) events.UploadMetadata {
+	return events.UploadMetadata{}
+}
+
+func newTestWrapper(t *testing.T) (*RecipientKeyWrapper, []byte) {
+	t.Helper()
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(private),
+	})
+	wrapper, err := NewRecipientKeyWrapper(keyPEM)
+	require.NoError(t, err)
+	publicPEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PUBLIC KEY",
+		Bytes: x509.MarshalPKCS1PublicKey(&private.PublicKey),
+	})
+	return wrapper, publicPEM
+}
+
+// TestEncryptedUploadDownload verifies that an uploaded recording is
+// stored encrypted and decrypts back to the original on download.
+func TestEncryptedUploadDownload(t *testing.T) {
+	dir := t.TempDir()
+	inner, err := filesessions.NewHandler(filesessions.Config{Directory: dir})
+	require.NoError(t, err)
+	wrapper, _ := newTestWrapper(t)
+	handler, err := NewHandler(Config{Inner: inner, KeyWrapper: wrapper})
+	require.NoError(t, err)
+
+	// Use a payload larger than one chunk to exercise chunk framing.
+	payload := bytes.Repeat([]byte("teleport session recording "), 10*1024)
+	sessionID := session.NewID()
+
+	_, err = handler.Upload(context.Background(), sessionID, bytes.NewReader(payload))
+	require.NoError(t, err)
+
+	// The stored file must not contain the plaintext.
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	stored, err := ioutil.ReadFile(filepath.Join(dir, files[0].Name()))
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(stored, []byte(magic)))
+	require.False(t, bytes.Contains(stored, []byte("teleport session recording")))
+
+	f, err := os.CreateTemp(t.TempDir(), "download")
+	require.NoError(t, err)
+	defer f.Close()
+	require.NoError(t, handler.Download(context.Background(), sessionID, f))
+	downloaded, err := ioutil.ReadFile(f.Name())
+	require.NoError(t, err)
+	require.Equal(t, payload, downloaded)
+}
+
+// TestEncryptedParts verifies that parts uploaded separately decrypt as
+// a concatenated stream, the way completed multipart uploads are read.
+func TestEncryptedParts(t *testing.T) {
+	wrapper, _ := newTestWrapper(t)
+	handler, err := NewHandler(Config{Inner: discardUploader{}, KeyWrapper: wrapper})
+	require.NoError(t, err)
+	sessionID := session.NewID()
+
+	var ciphertext bytes.Buffer
+	require.NoError(t, handler.encrypt(context.Background(), sessionID, &ciphertext, bytes.NewReader([]byte("part one,"))))
+	require.NoError(t, handler.encrypt(context.Background(), sessionID, &ciphertext, bytes.NewReader([]byte("part two"))))
+
+	var plaintext bytes.Buffer
+	require.NoError(t, handler.decrypt(context.Background(), sessionID, &plaintext, &ciphertext))
+	require.Equal(t, "part one,part two", plaintext.String())
+}
+
+// TestDecryptDetectsTampering verifies that a recording modified in
+// storage does not decrypt: chunks cannot be reordered, the stream cannot
+// be truncated before the end-of-stream marker and chunks are bound to
+// the session they were recorded for.
+func TestDecryptDetectsTampering(t *testing.T) {
+	wrapper, _ := newTestWrapper(t)
+	handler, err := NewHandler(Config{Inner: discardUploader{}, KeyWrapper: wrapper})
+	require.NoError(t, err)
+	sessionID := session.NewID()
+
+	// Two full chunks plus a bit, so the envelope has three data frames.
+	payload := bytes.Repeat([]byte("x"), 2*chunkSize+16)
+	var ciphertext bytes.Buffer
+	require.NoError(t, handler.encrypt(context.Background(), sessionID, &ciphertext, bytes.NewReader(payload)))
+
+	// The untampered envelope decrypts.
+	var plaintext bytes.Buffer
+	require.NoError(t, handler.decrypt(context.Background(), sessionID, &plaintext, bytes.NewReader(ciphertext.Bytes())))
+	require.Equal(t, payload, plaintext.Bytes())
+
+	// Split the envelope into header and frames to tamper with it.
+	envelope := ciphertext.Bytes()
+	reader := bytes.NewReader(envelope[len(magic):])
+	var frames [][]byte
+	var lengths []int
+	for reader.Len() > 0 {
+		frame, err := readFrame(reader)
+		require.NoError(t, err)
+		frames = append(frames, frame)
+		lengths = append(lengths, len(frame)+4)
+	}
+	// keyID frame, wrapped key frame, three data frames, final marker.
+	require.Len(t, frames, 6)
+	headerLen := len(magic) + lengths[0] + lengths[1]
+
+	rebuild := func(frames ...[]byte) io.Reader {
+		var buf bytes.Buffer
+		buf.Write(envelope[:headerLen])
+		for _, frame := range frames {
+			require.NoError(t, writeFrame(&buf, frame))
+		}
+		return &buf
+	}
+
+	// Swapping two chunks fails.
+	err = handler.decrypt(context.Background(), sessionID, io.Discard, rebuild(frames[3], frames[2], frames[4], frames[5]))
+	require.Error(t, err)
+
+	// Dropping a chunk fails.
+	err = handler.decrypt(context.Background(), sessionID, io.Discard, rebuild(frames[2], frames[4], frames[5]))
+	require.Error(t, err)
+
+	// Truncating the stream before the end-of-stream marker fails.
+	err = handler.decrypt(context.Background(), sessionID, io.Discard, rebuild(frames[2], frames[3]))
+	require.Error(t, err)
+
+	// Chunks are bound to the session they were recorded for.
+	err = handler.decrypt(context.Background(), session.NewID(), io.Discard, bytes.NewReader(envelope))
+	require.Error(t, err)
+}
+
+// TestDecryptWithoutPrivateKey verifies that a recipient wrapper built
+// from only the public key can record but not play back.
+func TestDecryptWithoutPrivateKey(t *testing.T) {
+	_, publicPEM := newTestWrapper(t)
+	wrapper, err := NewRecipientKeyWrapper(publicPEM)
+	require.NoError(t, err)
+	handler, err := NewHandler(Config{Inner: discardUploader{}, KeyWrapper: wrapper})
+	require.NoError(t, err)
+
+	sessionID := session.NewID()
+	var ciphertext bytes.Buffer
+	require.NoError(t, handler.encrypt(context.Background(), sessionID, &ciphertext, bytes.NewReader([]byte("secret"))))
+	var plaintext bytes.Buffer
+	require.Error(t, handler.decrypt(context.Background(), sessionID, &plaintext, &ciphertext))
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encryptedsessions
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awssession "github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+	"github.com/gravitational/trace"
+)
+
+// KeyWrapper wraps and unwraps the per-part data keys. The key ID is
+// stored in the envelope header alongside the wrapped key, so a wrapper
+// can detect envelopes produced with a key it does not hold.
+type KeyWrapper interface {
+	// WrapKey encrypts a data key and returns it along with an
+	// identifier of the wrapping key.
+	WrapKey(ctx context.Context, key []byte) (wrapped []byte, keyID string, err error)
+	// UnwrapKey decrypts a data key wrapped with the given key ID.
+	UnwrapKey(ctx context.Context, wrapped []byte, keyID string) ([]byte, error)
+}
+
+// NewRecipientKeyWrapper returns a key wrapper that encrypts data keys
+// to an RSA recipient key. Only the public key is needed to record
+// sessions; the private key (if present in the same PEM data) is needed
+// to play them back, so auth servers that only record can be configured
+// without it.
+func NewRecipientKeyWrapper(keyPEM []byte) (*RecipientKeyWrapper, error) {
+	w := &RecipientKeyWrapper{}
+	for {
+		var block *pem.Block
+		block, keyPEM = pem.Decode(keyPEM)
+		if block == nil {
+			break
+		}
+		switch block.Type {
+		case "RSA PUBLIC KEY":
+			public, err := x509.ParsePKCS1PublicKey(block.Bytes)
+			if err != nil {
+				return nil, trace.Wrap(err)
+			}
+			w.public = public
+		case "PUBLIC KEY":
+			public, err := x509.ParsePKIXPublicKey(block.Bytes)
+			if err != nil {
+				return nil, trace.Wrap(err)
+			}
+			rsaPublic, ok := public.(*rsa.PublicKey)
+			if !ok {
+				return nil, trace.BadParameter("expected an RSA recipient key, got %T", public)
+			}
+			w.public = rsaPublic
+		case "RSA PRIVATE KEY":
+			private, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+			if err != nil {
+				return nil, trace.Wrap(err)
+			}
+			w.private = private
+			w.public = &private.PublicKey
+		}
+	}
+	if w.public == nil {
+		return nil, trace.BadParameter("recipient key PEM data contains no RSA key")
+	}
+	fingerprint := sha256.Sum256(x509.MarshalPKCS1PublicKey(w.public))
+	w.keyID = hex.EncodeToString(fingerprint[:8])
+	return w, nil
+}
+
+// RecipientKeyWrapper wraps data keys to an RSA recipient key.
+type RecipientKeyWrapper struct {
+	public  *rsa.PublicKey
+	private *rsa.PrivateKey
+	keyID   string
+}
+
+// WrapKey encrypts the data key with RSA-OAEP to the recipient key.
+func (w *RecipientKeyWrapper) WrapKey(ctx context.Context, key []byte) ([]byte, string, error) {
+	wrapped, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, w.public, key, nil)
+	if err != nil {
+		return nil, "", trace.Wrap(err)
+	}
+	return wrapped, w.keyID, nil
+}
+
+// UnwrapKey decrypts the data key with the recipient's private key.
+func (w *RecipientKeyWrapper) UnwrapKey(ctx context.Context, wrapped []byte, keyID string) ([]byte, error) {
+	if keyID != w.keyID {
+		return nil, trace.NotFound("session recording was encrypted to recipient key %v, configured key is %v", keyID, w.keyID)
+	}
+	if w.private == nil {
+		return nil, trace.AccessDenied("recipient private key is not configured, cannot decrypt session recording")
+	}
+	key, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, w.private, wrapped, nil)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return key, nil
+}
+
+// KMSKeyWrapperConfig configures a KMS key wrapper.
+type KMSKeyWrapperConfig struct {
+	// KeyID is the KMS key ID or ARN used to wrap the data keys.
+	KeyID string
+	// Region is an optional AWS region override; by default it is
+	// picked up from the environment or shared configuration.
+	Region string
+	// Client is an optional KMS client override, used in tests.
+	Client kmsiface.KMSAPI
+}
+
+// CheckAndSetDefaults checks and sets default values
+func (c *KMSKeyWrapperConfig) CheckAndSetDefaults() error {
+	if c.KeyID == "" {
+		return trace.BadParameter("missing parameter KeyID")
+	}
+	if c.Client == nil {
+		// Create an AWS session using default SDK behavior, i.e. it will
+		// interpret the environment and ~/.aws directory just like an AWS
+		// CLI tool would.
+		sess, err := awssession.NewSessionWithOptions(awssession.Options{
+			SharedConfigState: awssession.SharedConfigEnable,
+		})
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if c.Region != "" {
+			sess.Config.Region = aws.String(c.Region)
+		}
+		c.Client = kms.New(sess)
+	}
+	return nil
+}
+
+// NewKMSKeyWrapper returns a key wrapper backed by an AWS KMS key.
+func NewKMSKeyWrapper(cfg KMSKeyWrapperConfig) (*KMSKeyWrapper, error) {
+	if err := cfg.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &KMSKeyWrapper{cfg: cfg}, nil
+}
+
+// KMSKeyWrapper wraps data keys with an AWS KMS key.
+type KMSKeyWrapper struct {
+	cfg KMSKeyWrapperConfig
+}
+
+// WrapKey encrypts the data key with the KMS key.
+func (w *KMSKeyWrapper) WrapKey(ctx context.Context, key []byte) ([]byte, string, error) {
+	out, err := w.cfg.Client.EncryptWithContext(ctx, &kms.EncryptInput{
+		KeyId:     aws.String(w.cfg.KeyID),
+		Plaintext: key,
+	})
+	if err != nil {
+		return nil, "", trace.Wrap(err)
+	}
+	return out.CiphertextBlob, aws.StringValue(out.KeyId), nil
+}
+
+// UnwrapKey decrypts the data key with KMS. The ciphertext blob carries
+// the key reference, so the stored key ID is informational only.
+func (w *KMSKeyWrapper) UnwrapKey(ctx context.Context, wrapped []byte, keyID string) ([]byte, error) {
+	out, err := w.cfg.Client.DecryptWithContext(ctx, &kms.DecryptInput{
+		CiphertextBlob: wrapped,
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return out.Plaintext, nil
+}
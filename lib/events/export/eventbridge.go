@@ -0,0 +1,143 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package export
+
+import (
+	"context"
+
+	apievents "github.com/gravitational/teleport/api/types/events"
+	"github.com/gravitational/teleport/lib/utils"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awssession "github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/eventbridge"
+	"github.com/aws/aws-sdk-go/service/eventbridge/eventbridgeiface"
+	"github.com/gravitational/trace"
+)
+
+const (
+	// eventBridgeSource is the source recorded on the published entries,
+	// used by consumers to build event patterns.
+	eventBridgeSource = "teleport.audit"
+
+	// eventBridgeBatchSize is the maximum number of entries accepted by
+	// a single PutEvents call.
+	eventBridgeBatchSize = 10
+)
+
+// EventBridgeSinkConfig configures an AWS EventBridge sink.
+type EventBridgeSinkConfig struct {
+	// EventBusName is the event bus entries are published to. Empty
+	// means the account's default bus.
+	EventBusName string
+	// Region is an optional AWS region override; by default it is
+	// picked up from the environment or shared configuration.
+	Region string
+	// Client is an optional EventBridge client override, used in tests.
+	Client eventbridgeiface.EventBridgeAPI
+}
+
+// CheckAndSetDefaults checks and sets default values
+func (c *EventBridgeSinkConfig) CheckAndSetDefaults() error {
+	if c.Client == nil {
+		// Create an AWS session using default SDK behavior, i.e. it will
+		// interpret the environment and ~/.aws directory just like an AWS
+		// CLI tool would.
+		sess, err := awssession.NewSessionWithOptions(awssession.Options{
+			SharedConfigState: awssession.SharedConfigEnable,
+		})
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if c.Region != "" {
+			sess.Config.Region = aws.String(c.Region)
+		}
+		c.Client = eventbridge.New(sess)
+	}
+	return nil
+}
+
+// NewEventBridgeSink returns a sink that publishes audit events to an
+// AWS EventBridge event bus, one entry per event, with the event type
+// as the detail type. PutEvents accepts partial failures, so failed
+// entries are reported back as an error and the whole batch is retried;
+// consumers should deduplicate on the event ID.
+func NewEventBridgeSink(cfg EventBridgeSinkConfig) (*EventBridgeSink, error) {
+	if err := cfg.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &EventBridgeSink{cfg: cfg}, nil
+}
+
+// EventBridgeSink delivers audit event batches to AWS EventBridge.
+type EventBridgeSink struct {
+	cfg EventBridgeSinkConfig
+}
+
+// Export implements Sink.
+func (s *EventBridgeSink) Export(ctx context.Context, events []apievents.AuditEvent) error {
+	for len(events) != 0 {
+		batch := events
+		if len(batch) > eventBridgeBatchSize {
+			batch = batch[:eventBridgeBatchSize]
+		}
+		events = events[len(batch):]
+		if err := s.put(ctx, batch); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	return nil
+}
+
+func (s *EventBridgeSink) put(ctx context.Context, events []apievents.AuditEvent) error {
+	entries := make([]*eventbridge.PutEventsRequestEntry, 0, len(events))
+	for _, event := range events {
+		data, err := utils.FastMarshal(event)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		entry := &eventbridge.PutEventsRequestEntry{
+			Source:     aws.String(eventBridgeSource),
+			DetailType: aws.String(event.GetType()),
+			Detail:     aws.String(string(data)),
+			Time:       aws.Time(event.GetTime()),
+		}
+		if s.cfg.EventBusName != "" {
+			entry.EventBusName = aws.String(s.cfg.EventBusName)
+		}
+		entries = append(entries, entry)
+	}
+	out, err := s.cfg.Client.PutEventsWithContext(ctx, &eventbridge.PutEventsInput{
+		Entries: entries,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if aws.Int64Value(out.FailedEntryCount) != 0 {
+		for _, entry := range out.Entries {
+			if entry.ErrorCode != nil {
+				return trace.ConnectionProblem(nil, "failed to publish %v entries to EventBridge: %v: %v",
+					aws.Int64Value(out.FailedEntryCount),
+					aws.StringValue(entry.ErrorCode),
+					aws.StringValue(entry.ErrorMessage))
+			}
+		}
+		return trace.ConnectionProblem(nil, "failed to publish %v entries to EventBridge",
+			aws.Int64Value(out.FailedEntryCount))
+	}
+	return nil
+}
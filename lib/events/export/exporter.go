@@ -0,0 +1,213 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package export implements streaming export of audit events to
+// external sinks such as webhooks or AWS EventBridge, so external
+// systems can consume the audit log without polling the audit API.
+package export
+
+import (
+	"context"
+	"time"
+
+	apievents "github.com/gravitational/teleport/api/types/events"
+	"github.com/gravitational/teleport/lib/defaults"
+	"github.com/gravitational/teleport/lib/utils"
+
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+	log "github.com/sirupsen/logrus"
+)
+
+// closeTimeout is how long Close waits for buffered events to be
+// delivered before giving up on them.
+const closeTimeout = 5 * time.Second
+
+// Sink delivers batches of audit events to an external system.
+//
+// Export is retried until it succeeds, which gives at-least-once
+// delivery: implementations must tolerate duplicate deliveries, for
+// example by keying on the event ID.
+type Sink interface {
+	// Export delivers a batch of events to the external system.
+	Export(ctx context.Context, events []apievents.AuditEvent) error
+}
+
+// ExporterConfig configures an audit event exporter.
+type ExporterConfig struct {
+	// Sink receives the exported event batches.
+	Sink Sink
+	// BatchSize is the maximum number of events delivered in one
+	// Sink.Export call.
+	BatchSize int
+	// FlushInterval is how long a partial batch is held before it is
+	// delivered anyway.
+	FlushInterval time.Duration
+	// BufferSize is the number of events held while a delivery is in
+	// progress. Once the buffer is full, EmitAuditEvent blocks,
+	// applying backpressure to the caller instead of losing events.
+	BufferSize int
+	// RetryConfig configures the backoff between failed deliveries.
+	RetryConfig utils.LinearConfig
+	// Clock is used to override time in tests.
+	Clock clockwork.Clock
+}
+
+// CheckAndSetDefaults checks and sets default values
+func (c *ExporterConfig) CheckAndSetDefaults() error {
+	if c.Sink == nil {
+		return trace.BadParameter("missing parameter Sink")
+	}
+	if c.BatchSize == 0 {
+		c.BatchSize = defaults.EventsIterationLimit
+	}
+	if c.FlushInterval == 0 {
+		c.FlushInterval = defaults.NetworkRetryDuration
+	}
+	if c.BufferSize == 0 {
+		c.BufferSize = defaults.AsyncBufferSize
+	}
+	if c.Clock == nil {
+		c.Clock = clockwork.NewRealClock()
+	}
+	if c.RetryConfig.Step == 0 {
+		c.RetryConfig.Step = defaults.NetworkRetryDuration
+	}
+	if c.RetryConfig.Max == 0 {
+		c.RetryConfig.Max = defaults.NetworkBackoffDuration
+	}
+	c.RetryConfig.Clock = c.Clock
+	return nil
+}
+
+// NewExporter returns an exporter that forwards emitted audit events to
+// the configured sink in batches. It implements apievents.Emitter, so
+// it can be placed alongside other emitters with NewMultiEmitter.
+func NewExporter(cfg ExporterConfig) (*Exporter, error) {
+	if err := cfg.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	e := &Exporter{
+		cfg:      cfg,
+		eventsCh: make(chan apievents.AuditEvent, cfg.BufferSize),
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+	go e.forward()
+	return e, nil
+}
+
+// Exporter accepts emitted audit events and delivers them to a Sink in
+// batches, retrying failed deliveries with backoff.
+type Exporter struct {
+	cfg      ExporterConfig
+	eventsCh chan apievents.AuditEvent
+	ctx      context.Context
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// EmitAuditEvent queues an event for export. If the buffer is full, it
+// blocks until the sink catches up or the context is canceled, so slow
+// sinks apply backpressure to the caller rather than losing events.
+func (e *Exporter) EmitAuditEvent(ctx context.Context, event apievents.AuditEvent) error {
+	select {
+	case e.eventsCh <- event:
+		return nil
+	case <-ctx.Done():
+		return trace.ConnectionProblem(ctx.Err(), "context canceled or closed")
+	case <-e.ctx.Done():
+		return trace.ConnectionProblem(nil, "exporter is closed")
+	}
+}
+
+// Close stops the exporter and waits for the buffered events to be
+// delivered. Events that cannot be delivered before the close timeout
+// are lost.
+func (e *Exporter) Close() error {
+	e.cancel()
+	select {
+	case <-e.done:
+	case <-time.After(closeTimeout):
+	}
+	return nil
+}
+
+func (e *Exporter) forward() {
+	defer close(e.done)
+	ticker := e.cfg.Clock.NewTicker(e.cfg.FlushInterval)
+	defer ticker.Stop()
+	var batch []apievents.AuditEvent
+	for {
+		select {
+		case event := <-e.eventsCh:
+			batch = append(batch, event)
+			if len(batch) >= e.cfg.BatchSize {
+				e.export(e.ctx, batch)
+				batch = nil
+			}
+		case <-ticker.Chan():
+			if len(batch) != 0 {
+				e.export(e.ctx, batch)
+				batch = nil
+			}
+		case <-e.ctx.Done():
+			// Flush the batch and whatever is still buffered,
+			// within the close timeout.
+			ctx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+			defer cancel()
+			for {
+				select {
+				case event := <-e.eventsCh:
+					batch = append(batch, event)
+					continue
+				default:
+				}
+				break
+			}
+			if len(batch) != 0 {
+				e.export(ctx, batch)
+			}
+			return
+		}
+	}
+}
+
+// export delivers a batch to the sink, retrying with backoff until it
+// succeeds or the context is canceled.
+func (e *Exporter) export(ctx context.Context, batch []apievents.AuditEvent) {
+	retry, err := utils.NewLinear(e.cfg.RetryConfig)
+	if err != nil {
+		log.WithError(err).Error("Failed to create retry, dropping batch of audit events.")
+		return
+	}
+	for {
+		err := e.cfg.Sink.Export(ctx, batch)
+		if err == nil {
+			return
+		}
+		log.WithError(err).Warningf("Failed to export batch of %v audit events, will retry.", len(batch))
+		retry.Inc()
+		select {
+		case <-retry.After():
+		case <-ctx.Done():
+			log.Warningf("Closing, dropping batch of %v audit events.", len(batch))
+			return
+		}
+	}
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	apievents "github.com/gravitational/teleport/api/types/events"
+	"github.com/gravitational/teleport/lib/utils"
+
+	"github.com/gravitational/trace"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSink collects exported batches and can be told to fail a number
+// of deliveries first.
+type fakeSink struct {
+	mu       sync.Mutex
+	batches  [][]apievents.AuditEvent
+	failures int
+	received chan struct{}
+}
+
+func newFakeSink() *fakeSink {
+	return &fakeSink{received: make(chan struct{}, 128)}
+}
+
+func (s *fakeSink) Export(ctx context.Context, events []apievents.AuditEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failures > 0 {
+		s.failures--
+		return trace.ConnectionProblem(nil, "sink is down")
+	}
+	s.batches = append(s.batches, events)
+	s.received <- struct{}{}
+	return nil
+}
+
+func (s *fakeSink) batchSizes() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var sizes []int
+	for _, batch := range s.batches {
+		sizes = append(sizes, len(batch))
+	}
+	return sizes
+}
+
+func makeEvents(count int) []apievents.AuditEvent {
+	events := make([]apievents.AuditEvent, 0, count)
+	for i := 0; i < count; i++ {
+		events = append(events, &apievents.UserLogin{
+			Metadata: apievents.Metadata{
+				ID:   fmt.Sprintf("event-%v", i),
+				Type: "user.login",
+				Time: time.Now().UTC(),
+			},
+		})
+	}
+	return events
+}
+
+func waitForBatch(t *testing.T, sink *fakeSink) {
+	t.Helper()
+	select {
+	case <-sink.received:
+	case <-time.After(10 * time.Second):
+		t.Fatal("timeout waiting for batch delivery")
+	}
+}
+
+// TestExporterBatching verifies that events are delivered in batches of
+// the configured size.
+func TestExporterBatching(t *testing.T) {
+	sink := newFakeSink()
+	exporter, err := NewExporter(ExporterConfig{
+		Sink:          sink,
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+	})
+	require.NoError(t, err)
+	defer exporter.Close()
+
+	for _, event := range makeEvents(4) {
+		require.NoError(t, exporter.EmitAuditEvent(context.Background(), event))
+	}
+	waitForBatch(t, sink)
+	waitForBatch(t, sink)
+	require.Equal(t, []int{2, 2}, sink.batchSizes())
+}
+
+// TestExporterRetry verifies that failed deliveries are retried until
+// they succeed, without losing the batch.
+func TestExporterRetry(t *testing.T) {
+	sink := newFakeSink()
+	sink.failures = 2
+	exporter, err := NewExporter(ExporterConfig{
+		Sink:          sink,
+		BatchSize:     1,
+		FlushInterval: time.Hour,
+		RetryConfig: utils.LinearConfig{
+			Step: time.Millisecond,
+			Max:  10 * time.Millisecond,
+		},
+	})
+	require.NoError(t, err)
+	defer exporter.Close()
+
+	require.NoError(t, exporter.EmitAuditEvent(context.Background(), makeEvents(1)[0]))
+	waitForBatch(t, sink)
+	require.Equal(t, []int{1}, sink.batchSizes())
+}
+
+// TestExporterCloseFlush verifies that a partial batch is delivered on
+// Close instead of being lost.
+func TestExporterCloseFlush(t *testing.T) {
+	sink := newFakeSink()
+	exporter, err := NewExporter(ExporterConfig{
+		Sink:          sink,
+		BatchSize:     100,
+		FlushInterval: time.Hour,
+	})
+	require.NoError(t, err)
+
+	for _, event := range makeEvents(3) {
+		require.NoError(t, exporter.EmitAuditEvent(context.Background(), event))
+	}
+	require.NoError(t, exporter.Close())
+	require.Equal(t, []int{3}, sink.batchSizes())
+}
+
+// TestWebhookSink verifies the webhook request format and the error
+// handling of non-2xx responses.
+func TestWebhookSink(t *testing.T) {
+	var payload []json.RawMessage
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+	}))
+	defer srv.Close()
+
+	sink, err := NewWebhookSink(WebhookSinkConfig{
+		URL:     srv.URL,
+		Headers: map[string]string{"Authorization": "Bearer token"},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, sink.Export(context.Background(), makeEvents(2)))
+	require.Len(t, payload, 2)
+	require.Equal(t, "Bearer token", auth)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	sink, err = NewWebhookSink(WebhookSinkConfig{URL: failing.URL})
+	require.NoError(t, err)
+	require.Error(t, sink.Export(context.Background(), makeEvents(1)))
+}
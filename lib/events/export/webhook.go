@@ -0,0 +1,106 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package export
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	apievents "github.com/gravitational/teleport/api/types/events"
+	"github.com/gravitational/teleport/lib/utils"
+
+	"github.com/gravitational/trace"
+)
+
+// WebhookSinkConfig configures a webhook sink.
+type WebhookSinkConfig struct {
+	// URL is the endpoint event batches are POSTed to.
+	URL string
+	// Headers are additional headers set on every request, for example
+	// an Authorization header.
+	Headers map[string]string
+	// Client is an optional HTTP client override, used in tests and to
+	// customize TLS settings.
+	Client *http.Client
+}
+
+// CheckAndSetDefaults checks and sets default values
+func (c *WebhookSinkConfig) CheckAndSetDefaults() error {
+	if c.URL == "" {
+		return trace.BadParameter("missing parameter URL")
+	}
+	if c.Client == nil {
+		c.Client = http.DefaultClient
+	}
+	return nil
+}
+
+// NewWebhookSink returns a sink that POSTs each batch of audit events
+// to an HTTP endpoint as a JSON array. Any response other than 2xx is
+// treated as a failed delivery and the batch is retried, so receivers
+// should deduplicate on the event ID.
+func NewWebhookSink(cfg WebhookSinkConfig) (*WebhookSink, error) {
+	if err := cfg.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &WebhookSink{cfg: cfg}, nil
+}
+
+// WebhookSink delivers audit event batches to an HTTP endpoint.
+type WebhookSink struct {
+	cfg WebhookSinkConfig
+}
+
+// Export implements Sink.
+func (s *WebhookSink) Export(ctx context.Context, events []apievents.AuditEvent) error {
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, event := range events {
+		if i != 0 {
+			buf.WriteByte(',')
+		}
+		data, err := utils.FastMarshal(event)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		buf.Write(data)
+	}
+	buf.WriteByte(']')
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.URL, &buf)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range s.cfg.Headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := s.cfg.Client.Do(req)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer resp.Body.Close()
+	// Drain the body so the connection can be reused.
+	io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return trace.ConnectionProblem(nil, "webhook %v returned status %v", s.cfg.URL, resp.StatusCode)
+	}
+	return nil
+}
@@ -75,12 +75,15 @@ func DetectFormat(r io.ReadSeeker) (*Header, error) {
 	return &Header{Tar: true}, nil
 }
 
-// Export converts session files from binary/protobuf to text/JSON.
+// Export converts session files from binary/protobuf to text formats:
+// a stream of JSON events, an asciicast v2 recording or the raw terminal
+// output of the session.
 func Export(ctx context.Context, rs io.ReadSeeker, w io.Writer, exportFormat string) error {
 	switch exportFormat {
-	case teleport.JSON:
+	case teleport.JSON, teleport.Asciicast, teleport.Raw:
 	default:
-		return trace.BadParameter("unsupported format %q, %q is the only supported format", exportFormat, teleport.JSON)
+		return trace.BadParameter("unsupported format %q, supported formats are %q, %q and %q",
+			exportFormat, teleport.JSON, teleport.Asciicast, teleport.Raw)
 	}
 
 	format, err := DetectFormat(rs)
@@ -94,6 +97,12 @@ func Export(ctx context.Context, rs io.ReadSeeker, w io.Writer, exportFormat str
 	switch {
 	case format.Proto == true:
 		protoReader := NewProtoReader(rs)
+		switch exportFormat {
+		case teleport.Asciicast:
+			return trace.Wrap(exportAsciicast(ctx, protoReader, w))
+		case teleport.Raw:
+			return trace.Wrap(exportRaw(ctx, protoReader, w))
+		}
 		for {
 			event, err := protoReader.Read(ctx)
 			if err != nil {
@@ -102,18 +111,13 @@ func Export(ctx context.Context, rs io.ReadSeeker, w io.Writer, exportFormat str
 				}
 				return trace.Wrap(err)
 			}
-			switch exportFormat {
-			case teleport.JSON:
-				data, err := utils.FastMarshal(event)
-				if err != nil {
-					return trace.ConvertSystemError(err)
-				}
-				_, err = fmt.Fprintln(w, string(data))
-				if err != nil {
-					return trace.ConvertSystemError(err)
-				}
-			default:
-				return trace.BadParameter("unsupported format %q, %q is the only supported format", exportFormat, teleport.JSON)
+			data, err := utils.FastMarshal(event)
+			if err != nil {
+				return trace.ConvertSystemError(err)
+			}
+			_, err = fmt.Fprintln(w, string(data))
+			if err != nil {
+				return trace.ConvertSystemError(err)
 			}
 		}
 	case format.Tar == true:
@@ -124,6 +128,168 @@ func Export(ctx context.Context, rs io.ReadSeeker, w io.Writer, exportFormat str
 	}
 }
 
+// Terminal size assumed for recordings that do not include one.
+const (
+	defaultExportWidth  = 80
+	defaultExportHeight = 25
+)
+
+// asciicastHeader is the header line of an asciicast v2 recording, see
+// https://github.com/asciinema/asciinema/blob/master/doc/asciicast-v2.md
+// for the format description.
+type asciicastHeader struct {
+	Version   int    `json:"version"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+	Timestamp int64  `json:"timestamp,omitempty"`
+	Title     string `json:"title,omitempty"`
+}
+
+// asciicastWriter writes an asciicast v2 recording: a JSON header line
+// followed by one JSON event line per terminal output chunk.
+type asciicastWriter struct {
+	w             io.Writer
+	headerWritten bool
+}
+
+// writeHeader writes the recording header. Only the first header is
+// written, so the terminal size from the session start event wins over
+// later resizes.
+func (a *asciicastWriter) writeHeader(header asciicastHeader) error {
+	if a.headerWritten {
+		return nil
+	}
+	a.headerWritten = true
+	header.Version = 2
+	data, err := utils.FastMarshal(header)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	_, err = fmt.Fprintln(a.w, string(data))
+	return trace.ConvertSystemError(err)
+}
+
+// writeOutput writes a terminal output event at the given offset from
+// the start of the session.
+func (a *asciicastWriter) writeOutput(delayMilliseconds int64, data []byte) error {
+	if !a.headerWritten {
+		// The recording is missing the session start event, assume a
+		// common terminal size.
+		err := a.writeHeader(asciicastHeader{Width: defaultExportWidth, Height: defaultExportHeight})
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	line, err := utils.FastMarshal([]interface{}{
+		float64(delayMilliseconds) / 1000, "o", string(data),
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	_, err = fmt.Fprintln(a.w, string(line))
+	return trace.ConvertSystemError(err)
+}
+
+// exportAsciicast writes the session recording from the reader as an
+// asciicast v2 recording playable with the asciinema toolchain.
+func exportAsciicast(ctx context.Context, reader AuditReader, w io.Writer) error {
+	cast := &asciicastWriter{w: w}
+	for {
+		event, err := reader.Read(ctx)
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return trace.Wrap(err)
+		}
+		switch e := event.(type) {
+		case *apievents.SessionStart:
+			header := asciicastHeader{
+				Width:     defaultExportWidth,
+				Height:    defaultExportHeight,
+				Timestamp: e.GetTime().Unix(),
+				Title:     e.GetSessionID(),
+			}
+			if params, err := session.UnmarshalTerminalParams(e.TerminalSize); err == nil {
+				header.Width, header.Height = params.W, params.H
+			}
+			if err := cast.writeHeader(header); err != nil {
+				return trace.Wrap(err)
+			}
+		case *apievents.SessionPrint:
+			if err := cast.writeOutput(e.DelayMilliseconds, e.Data); err != nil {
+				return trace.Wrap(err)
+			}
+		}
+	}
+}
+
+// exportRaw writes the terminal output of the session recording from the
+// reader as a plain typescript with no timing information.
+func exportRaw(ctx context.Context, reader AuditReader, w io.Writer) error {
+	for {
+		event, err := reader.Read(ctx)
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return trace.Wrap(err)
+		}
+		if print, ok := event.(*apievents.SessionPrint); ok {
+			if _, err := w.Write(print.Data); err != nil {
+				return trace.ConvertSystemError(err)
+			}
+		}
+	}
+}
+
+// ExportEventFields converts a session fetched from the audit log as a
+// list of events plus the raw terminal stream into the requested export
+// format. It complements Export which converts recordings stored in the
+// protobuf stream format.
+func ExportEventFields(sessionEvents []EventFields, stream []byte, w io.Writer, exportFormat string) error {
+	switch exportFormat {
+	case teleport.Raw:
+		_, err := w.Write(stream)
+		return trace.ConvertSystemError(err)
+	case teleport.Asciicast:
+	default:
+		return trace.BadParameter("unsupported format %q, supported formats are %q and %q",
+			exportFormat, teleport.Asciicast, teleport.Raw)
+	}
+	cast := &asciicastWriter{w: w}
+	for _, e := range sessionEvents {
+		switch e.GetType() {
+		case SessionStartEvent:
+			header := asciicastHeader{
+				Width:  defaultExportWidth,
+				Height: defaultExportHeight,
+				Title:  e.GetString(SessionEventID),
+			}
+			if t := e.GetTime(EventTime); !t.IsZero() {
+				header.Timestamp = t.Unix()
+			}
+			if params, err := session.UnmarshalTerminalParams(e.GetString(TerminalSize)); err == nil {
+				header.Width, header.Height = params.W, params.H
+			}
+			if err := cast.writeHeader(header); err != nil {
+				return trace.Wrap(err)
+			}
+		case SessionPrintEvent:
+			offset := e.GetInt(SessionByteOffset)
+			bytes := e.GetInt(SessionPrintEventBytes)
+			if offset < 0 || bytes <= 0 || offset+bytes > len(stream) {
+				continue
+			}
+			err := cast.writeOutput(int64(e.GetInt(SessionEventTimestamp)), stream[offset:offset+bytes])
+			if err != nil {
+				return trace.Wrap(err)
+			}
+		}
+	}
+	return nil
+}
+
 // WriteForPlayback reads events from audit reader and writes them to the format optimized for playback
 // this function returns *PlaybackWriter and error
 func WriteForPlayback(ctx context.Context, sid session.ID, reader AuditReader, dir string) (*PlaybackWriter, error) {
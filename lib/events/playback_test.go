@@ -0,0 +1,66 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportEventFieldsAsciicast(t *testing.T) {
+	stream := []byte("$ echo hello\r\nhello\r\n")
+	sessionEvents := []EventFields{
+		{
+			EventType:      SessionStartEvent,
+			SessionEventID: "a53b0b63-1087-4627-a742-5d901b90a9ae",
+			TerminalSize:   "120:40",
+		},
+		{
+			EventType:              SessionPrintEvent,
+			SessionByteOffset:      0,
+			SessionPrintEventBytes: 14,
+			SessionEventTimestamp:  100,
+		},
+		{
+			EventType:              SessionPrintEvent,
+			SessionByteOffset:      14,
+			SessionPrintEventBytes: 7,
+			SessionEventTimestamp:  1500,
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, ExportEventFields(sessionEvents, stream, &buf, "asciicast"))
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 3)
+	require.JSONEq(t, `{"version":2,"width":120,"height":40,"title":"a53b0b63-1087-4627-a742-5d901b90a9ae"}`, lines[0])
+	require.JSONEq(t, `[0.1,"o","$ echo hello\r\n"]`, lines[1])
+	require.JSONEq(t, `[1.5,"o","hello\r\n"]`, lines[2])
+}
+
+func TestExportEventFieldsRaw(t *testing.T) {
+	stream := []byte("$ echo hello\r\nhello\r\n")
+	var buf bytes.Buffer
+	require.NoError(t, ExportEventFields(nil, stream, &buf, "raw"))
+	require.Equal(t, stream, buf.Bytes())
+
+	require.Error(t, ExportEventFields(nil, stream, &buf, "mp4"))
+}
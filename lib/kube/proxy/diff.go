@@ -0,0 +1,195 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxAuditedRequestBody caps how much of a mutating request body is
+// captured for the audit event. Bodies that exceed the cap are dropped
+// from the event rather than recorded truncated.
+const maxAuditedRequestBody = 256 * 1024
+
+// redactedValue replaces sensitive values in audited request bodies.
+const redactedValue = "REDACTED"
+
+// redactedKeys are object keys whose values are always redacted,
+// matched case-insensitively on the last path element.
+var redactedKeys = []string{
+	"password",
+	"token",
+	"secret",
+}
+
+// redactedAnnotations are object annotations removed from audited
+// request bodies, they duplicate the full (unredacted) object.
+var redactedAnnotations = []string{
+	"kubectl.kubernetes.io/last-applied-configuration",
+}
+
+// isMutatingVerb returns true for HTTP verbs that modify cluster state.
+func isMutatingVerb(verb string) bool {
+	switch verb {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// captureRequestBody replaces the request body with a reader that copies
+// what the forwarder consumes into the returned buffer, so mutating
+// requests can be audited without draining the body up front.
+func captureRequestBody(req *http.Request) *cappedBuffer {
+	if req.Body == nil {
+		return nil
+	}
+	buf := &cappedBuffer{cap: maxAuditedRequestBody}
+	req.Body = struct {
+		io.Reader
+		io.Closer
+	}{
+		Reader: io.TeeReader(req.Body, buf),
+		Closer: req.Body,
+	}
+	return buf
+}
+
+// cappedBuffer collects writes up to a cap and discards the rest,
+// remembering that it overflowed.
+type cappedBuffer struct {
+	cap      int
+	data     []byte
+	overflow bool
+}
+
+// Write implements io.Writer.
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if remaining := b.cap - len(b.data); len(p) > remaining {
+		p = p[:remaining]
+		b.overflow = true
+	}
+	b.data = append(b.data, p...)
+	return len(p), nil
+}
+
+// kubeRequestDiff returns the redacted object change carried by a
+// mutating request body: the patch itself for PATCH requests, the
+// submitted object otherwise. Returns an empty string if the body was
+// not valid JSON or exceeded the capture cap.
+func kubeRequestDiff(r apiResource, body *cappedBuffer) string {
+	if body == nil || body.overflow || len(body.data) == 0 {
+		return ""
+	}
+	var object interface{}
+	if err := json.Unmarshal(body.data, &object); err != nil {
+		// Not a JSON body, e.g. a protobuf-encoded request.
+		return ""
+	}
+	redacted, err := json.Marshal(redactObject(r, "", object))
+	if err != nil {
+		return ""
+	}
+	return string(redacted)
+}
+
+// redactObject walks the decoded object replacing sensitive values.
+// Secret contents are always redacted wholesale, for other resources
+// values are redacted based on their key.
+func redactObject(r apiResource, key string, object interface{}) interface{} {
+	switch value := object.(type) {
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			if key == "annotations" && isRedactedAnnotation(k) {
+				continue
+			}
+			if isSecretContents(r, k) {
+				redacted[k] = redactAll(v)
+				continue
+			}
+			redacted[k] = redactObject(r, k, v)
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(value))
+		for i, v := range value {
+			redacted[i] = redactObject(r, key, v)
+		}
+		return redacted
+	case string:
+		if isRedactedKey(key) {
+			return redactedValue
+		}
+		return value
+	default:
+		return value
+	}
+}
+
+// redactAll replaces all leaf values of the object.
+func redactAll(object interface{}) interface{} {
+	switch value := object.(type) {
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			redacted[k] = redactAll(v)
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(value))
+		for i, v := range value {
+			redacted[i] = redactAll(v)
+		}
+		return redacted
+	default:
+		return redactedValue
+	}
+}
+
+// isSecretContents returns true if the key holds secret payload: the
+// data and stringData fields of Secret objects.
+func isSecretContents(r apiResource, key string) bool {
+	if !strings.HasPrefix(r.resourceKind, "secrets") {
+		return false
+	}
+	return key == "data" || key == "stringData"
+}
+
+// isRedactedKey returns true if values under the key are sensitive.
+func isRedactedKey(key string) bool {
+	for _, redacted := range redactedKeys {
+		if strings.Contains(strings.ToLower(key), redacted) {
+			return true
+		}
+	}
+	return false
+}
+
+// isRedactedAnnotation returns true if the annotation is dropped from
+// audited objects.
+func isRedactedAnnotation(annotation string) bool {
+	for _, redacted := range redactedAnnotations {
+		if annotation == redacted {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKubeRequestDiff(t *testing.T) {
+	tests := []struct {
+		desc string
+		kind string
+		body string
+		want string
+	}{
+		{
+			desc: "secret contents are redacted wholesale",
+			kind: "secrets",
+			body: `{"apiVersion":"v1","kind":"Secret","data":{"username":"YWxpY2U=","cert":"YWJjZA=="},"metadata":{"name":"creds"}}`,
+			want: `{"apiVersion":"v1","data":{"cert":"REDACTED","username":"REDACTED"},"kind":"Secret","metadata":{"name":"creds"}}`,
+		},
+		{
+			desc: "sensitive keys are redacted in other resources",
+			kind: "pods",
+			body: `{"spec":{"containers":[{"env":[{"name":"DB","value":"postgres"},{"name":"X","dbPassword":"hunter2"}]}]}}`,
+			want: `{"spec":{"containers":[{"env":[{"name":"DB","value":"postgres"},{"dbPassword":"REDACTED","name":"X"}]}]}}`,
+		},
+		{
+			desc: "last applied annotation is dropped",
+			kind: "deployments",
+			body: `{"metadata":{"annotations":{"kubectl.kubernetes.io/last-applied-configuration":"{...}","team":"dev"}}}`,
+			want: `{"metadata":{"annotations":{"team":"dev"}}}`,
+		},
+		{
+			desc: "non-JSON body is skipped",
+			kind: "pods",
+			body: "\x00\x01binary",
+			want: "",
+		},
+		{
+			desc: "empty body is skipped",
+			kind: "pods",
+			body: "",
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			buf := &cappedBuffer{cap: maxAuditedRequestBody}
+			buf.Write([]byte(tt.body))
+			require.Equal(t, tt.want, kubeRequestDiff(apiResource{resourceKind: tt.kind}, buf))
+		})
+	}
+
+	t.Run("oversized body is skipped", func(t *testing.T) {
+		buf := &cappedBuffer{cap: 8}
+		buf.Write([]byte(`{"a":"bcdefghij"}`))
+		require.True(t, buf.overflow)
+		require.Empty(t, kubeRequestDiff(apiResource{resourceKind: "pods"}, buf))
+	})
+}
+
+func TestCaptureRequestBody(t *testing.T) {
+	body := `{"metadata":{"name":"test"}}`
+	req, err := http.NewRequest(http.MethodPost, "/api/v1/namespaces/test/pods", strings.NewReader(body))
+	require.NoError(t, err)
+
+	buf := captureRequestBody(req)
+	require.NotNil(t, buf)
+
+	// The capture is transparent: the forwarder still reads the full
+	// body, and the buffer holds a copy of what was consumed.
+	forwarded, err := ioutil.ReadAll(req.Body)
+	require.NoError(t, err)
+	require.Equal(t, body, string(forwarded))
+	require.True(t, bytes.Equal(forwarded, buf.data))
+}
+
+func TestIsMutatingVerb(t *testing.T) {
+	for _, verb := range []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		require.True(t, isMutatingVerb(verb), verb)
+	}
+	for _, verb := range []string{http.MethodGet, http.MethodHead, http.MethodOptions} {
+		require.False(t, isMutatingVerb(verb), verb)
+	}
+}
@@ -733,7 +733,7 @@ func (f *Forwarder) exec(ctx *authContext, w http.ResponseWriter, req *http.Requ
 		// All events should be recorded by kubernetes_service and not proxy_service
 		emitter = events.NewDiscardEmitter()
 		request.onResize = func(resize remotecommand.TerminalSize) {}
-	} else if request.tty {
+	} else {
 		streamer, err := f.newStreamer(ctx)
 		if err != nil {
 			return nil, trace.Wrap(err)
@@ -741,7 +741,9 @@ func (f *Forwarder) exec(ctx *authContext, w http.ResponseWriter, req *http.Requ
 		// create session recorder
 		// get the audit log from the server and create a session recorder. this will
 		// be a discard audit log if the proxy is in recording mode and a teleport
-		// node so we don't create double recordings.
+		// node so we don't create double recordings. Both interactive and
+		// non-interactive commands are recorded, matching SSH recording
+		// guarantees.
 		recorder, err = events.NewAuditWriter(events.AuditWriterConfig{
 			// Audit stream is using server context, not session context,
 			// to make sure that session is uploaded even after it is closed
@@ -760,91 +762,89 @@ func (f *Forwarder) exec(ctx *authContext, w http.ResponseWriter, req *http.Requ
 		}
 		emitter = recorder
 		defer recorder.Close(f.ctx)
-		request.onResize = func(resize remotecommand.TerminalSize) {
-			params := session.TerminalParams{
-				W: int(resize.Width),
-				H: int(resize.Height),
-			}
-			// Build the resize event.
-			resizeEvent := &apievents.Resize{
-				Metadata: apievents.Metadata{
-					Type:        events.ResizeEvent,
-					Code:        events.TerminalResizeCode,
-					ClusterName: f.cfg.ClusterName,
-				},
-				ConnectionMetadata: apievents.ConnectionMetadata{
-					RemoteAddr: req.RemoteAddr,
-					Protocol:   events.EventProtocolKube,
-				},
-				ServerMetadata: apievents.ServerMetadata{
-					ServerNamespace: f.cfg.Namespace,
-				},
-				SessionMetadata: apievents.SessionMetadata{
-					SessionID: string(sessionID),
-					WithMFA:   ctx.Identity.GetIdentity().MFAVerified,
-				},
-				UserMetadata: apievents.UserMetadata{
-					User:         ctx.User.GetName(),
-					Login:        ctx.User.GetName(),
-					Impersonator: ctx.Identity.GetIdentity().Impersonator,
-				},
-				TerminalSize:              params.Serialize(),
-				KubernetesClusterMetadata: ctx.eventClusterMeta(),
-				KubernetesPodMetadata:     eventPodMeta,
-			}
+		if request.tty {
+			request.onResize = func(resize remotecommand.TerminalSize) {
+				params := session.TerminalParams{
+					W: int(resize.Width),
+					H: int(resize.Height),
+				}
+				// Build the resize event.
+				resizeEvent := &apievents.Resize{
+					Metadata: apievents.Metadata{
+						Type:        events.ResizeEvent,
+						Code:        events.TerminalResizeCode,
+						ClusterName: f.cfg.ClusterName,
+					},
+					ConnectionMetadata: apievents.ConnectionMetadata{
+						RemoteAddr: req.RemoteAddr,
+						Protocol:   events.EventProtocolKube,
+					},
+					ServerMetadata: apievents.ServerMetadata{
+						ServerNamespace: f.cfg.Namespace,
+					},
+					SessionMetadata: apievents.SessionMetadata{
+						SessionID: string(sessionID),
+						WithMFA:   ctx.Identity.GetIdentity().MFAVerified,
+					},
+					UserMetadata: apievents.UserMetadata{
+						User:         ctx.User.GetName(),
+						Login:        ctx.User.GetName(),
+						Impersonator: ctx.Identity.GetIdentity().Impersonator,
+					},
+					TerminalSize:              params.Serialize(),
+					KubernetesClusterMetadata: ctx.eventClusterMeta(),
+					KubernetesPodMetadata:     eventPodMeta,
+				}
 
-			// Report the updated window size to the event log (this is so the sessions
-			// can be replayed correctly).
-			if err := recorder.EmitAuditEvent(f.ctx, resizeEvent); err != nil {
-				f.log.WithError(err).Warn("Failed to emit terminal resize event.")
+				// Report the updated window size to the event log (this is so the sessions
+				// can be replayed correctly).
+				if err := recorder.EmitAuditEvent(f.ctx, resizeEvent); err != nil {
+					f.log.WithError(err).Warn("Failed to emit terminal resize event.")
+				}
 			}
 		}
-	} else {
-		emitter = f.cfg.StreamEmitter
 	}
 
-	if request.tty {
-		// Emit "new session created" event. There are no initial terminal
-		// parameters per k8s protocol, so set up with any default
-		termParams := session.TerminalParams{
-			W: 100,
-			H: 100,
-		}
-		sessionStartEvent := &apievents.SessionStart{
-			Metadata: apievents.Metadata{
-				Type:        events.SessionStartEvent,
-				Code:        events.SessionStartCode,
-				ClusterName: f.cfg.ClusterName,
-			},
-			ServerMetadata: apievents.ServerMetadata{
-				ServerID:        f.cfg.ServerID,
-				ServerNamespace: f.cfg.Namespace,
-				ServerHostname:  sess.teleportCluster.name,
-				ServerAddr:      sess.kubeAddress,
-			},
-			SessionMetadata: apievents.SessionMetadata{
-				SessionID: string(sessionID),
-				WithMFA:   ctx.Identity.GetIdentity().MFAVerified,
-			},
-			UserMetadata: apievents.UserMetadata{
-				User:         ctx.User.GetName(),
-				Login:        ctx.User.GetName(),
-				Impersonator: ctx.Identity.GetIdentity().Impersonator,
-			},
-			ConnectionMetadata: apievents.ConnectionMetadata{
-				RemoteAddr: req.RemoteAddr,
-				LocalAddr:  sess.kubeAddress,
-				Protocol:   events.EventProtocolKube,
-			},
-			TerminalSize:              termParams.Serialize(),
-			KubernetesClusterMetadata: ctx.eventClusterMeta(),
-			KubernetesPodMetadata:     eventPodMeta,
-			InitialCommand:            request.cmd,
-			SessionRecording:          ctx.recordingConfig.GetMode(),
-		}
-		if err := emitter.EmitAuditEvent(f.ctx, sessionStartEvent); err != nil {
-			f.log.WithError(err).Warn("Failed to emit event.")
-		}
+	// Emit "new session created" event. There are no initial terminal
+	// parameters per k8s protocol, so set up with any default
+	termParams := session.TerminalParams{
+		W: 100,
+		H: 100,
+	}
+	sessionStartEvent := &apievents.SessionStart{
+		Metadata: apievents.Metadata{
+			Type:        events.SessionStartEvent,
+			Code:        events.SessionStartCode,
+			ClusterName: f.cfg.ClusterName,
+		},
+		ServerMetadata: apievents.ServerMetadata{
+			ServerID:        f.cfg.ServerID,
+			ServerNamespace: f.cfg.Namespace,
+			ServerHostname:  sess.teleportCluster.name,
+			ServerAddr:      sess.kubeAddress,
+		},
+		SessionMetadata: apievents.SessionMetadata{
+			SessionID: string(sessionID),
+			WithMFA:   ctx.Identity.GetIdentity().MFAVerified,
+		},
+		UserMetadata: apievents.UserMetadata{
+			User:         ctx.User.GetName(),
+			Login:        ctx.User.GetName(),
+			Impersonator: ctx.Identity.GetIdentity().Impersonator,
+		},
+		ConnectionMetadata: apievents.ConnectionMetadata{
+			RemoteAddr: req.RemoteAddr,
+			LocalAddr:  sess.kubeAddress,
+			Protocol:   events.EventProtocolKube,
+		},
+		TerminalSize:              termParams.Serialize(),
+		KubernetesClusterMetadata: ctx.eventClusterMeta(),
+		KubernetesPodMetadata:     eventPodMeta,
+		InitialCommand:            request.cmd,
+		SessionRecording:          ctx.recordingConfig.GetMode(),
+	}
+	if err := emitter.EmitAuditEvent(f.ctx, sessionStartEvent); err != nil {
+		f.log.WithError(err).Warn("Failed to emit event.")
 	}
 
 	if err := f.setupForwardingHeaders(sess, req); err != nil {
@@ -881,8 +881,12 @@ func (f *Forwarder) exec(ctx *authContext, w http.ResponseWriter, req *http.Requ
 	}
 	if recorder != nil {
 		// capture stderr and stdout writes to session recorder
-		streamOptions.Stdout = io.MultiWriter(streamOptions.Stdout, recorder)
-		streamOptions.Stderr = io.MultiWriter(streamOptions.Stderr, recorder)
+		if streamOptions.Stdout != nil {
+			streamOptions.Stdout = io.MultiWriter(streamOptions.Stdout, recorder)
+		}
+		if streamOptions.Stderr != nil {
+			streamOptions.Stderr = io.MultiWriter(streamOptions.Stderr, recorder)
+		}
 	}
 
 	// Defer a cleanup handler that will mark the stream as complete on exit, regardless of
@@ -893,77 +897,7 @@ func (f *Forwarder) exec(ctx *authContext, w http.ResponseWriter, req *http.Requ
 			f.log.WithError(err).Warning("Failed to send status. Exec command was aborted by client.")
 		}
 
-		if request.tty {
-			sessionDataEvent := &apievents.SessionData{
-				Metadata: apievents.Metadata{
-					Type:        events.SessionDataEvent,
-					Code:        events.SessionDataCode,
-					ClusterName: f.cfg.ClusterName,
-				},
-				ServerMetadata: apievents.ServerMetadata{
-					ServerID:        f.cfg.ServerID,
-					ServerNamespace: f.cfg.Namespace,
-				},
-				SessionMetadata: apievents.SessionMetadata{
-					SessionID: string(sessionID),
-					WithMFA:   ctx.Identity.GetIdentity().MFAVerified,
-				},
-				UserMetadata: apievents.UserMetadata{
-					User:         ctx.User.GetName(),
-					Login:        ctx.User.GetName(),
-					Impersonator: ctx.Identity.GetIdentity().Impersonator,
-				},
-				ConnectionMetadata: apievents.ConnectionMetadata{
-					RemoteAddr: req.RemoteAddr,
-					LocalAddr:  sess.kubeAddress,
-					Protocol:   events.EventProtocolKube,
-				},
-				// Bytes transmitted from user to pod.
-				BytesTransmitted: trackIn.Count(),
-				// Bytes received from pod by user.
-				BytesReceived: trackOut.Count() + trackErr.Count(),
-			}
-			if err := emitter.EmitAuditEvent(f.ctx, sessionDataEvent); err != nil {
-				f.log.WithError(err).Warn("Failed to emit session data event.")
-			}
-			sessionEndEvent := &apievents.SessionEnd{
-				Metadata: apievents.Metadata{
-					Type:        events.SessionEndEvent,
-					Code:        events.SessionEndCode,
-					ClusterName: f.cfg.ClusterName,
-				},
-				ServerMetadata: apievents.ServerMetadata{
-					ServerID:        f.cfg.ServerID,
-					ServerNamespace: f.cfg.Namespace,
-				},
-				SessionMetadata: apievents.SessionMetadata{
-					SessionID: string(sessionID),
-					WithMFA:   ctx.Identity.GetIdentity().MFAVerified,
-				},
-				UserMetadata: apievents.UserMetadata{
-					User:         ctx.User.GetName(),
-					Login:        ctx.User.GetName(),
-					Impersonator: ctx.Identity.GetIdentity().Impersonator,
-				},
-				ConnectionMetadata: apievents.ConnectionMetadata{
-					RemoteAddr: req.RemoteAddr,
-					LocalAddr:  sess.kubeAddress,
-					Protocol:   events.EventProtocolKube,
-				},
-				Interactive: true,
-				// There can only be 1 participant, k8s sessions are not join-able.
-				Participants:              []string{ctx.User.GetName()},
-				StartTime:                 sessionStart,
-				EndTime:                   f.cfg.Clock.Now().UTC(),
-				KubernetesClusterMetadata: ctx.eventClusterMeta(),
-				KubernetesPodMetadata:     eventPodMeta,
-				InitialCommand:            request.cmd,
-				SessionRecording:          ctx.recordingConfig.GetMode(),
-			}
-			if err := emitter.EmitAuditEvent(f.ctx, sessionEndEvent); err != nil {
-				f.log.WithError(err).Warn("Failed to emit session end event.")
-			}
-		} else {
+		if !request.tty {
 			// send an exec event
 			execEvent := &apievents.Exec{
 				Metadata: apievents.Metadata{
@@ -1007,6 +941,76 @@ func (f *Forwarder) exec(ctx *authContext, w http.ResponseWriter, req *http.Requ
 				f.log.WithError(err).Warn("Failed to emit event.")
 			}
 		}
+
+		sessionDataEvent := &apievents.SessionData{
+			Metadata: apievents.Metadata{
+				Type:        events.SessionDataEvent,
+				Code:        events.SessionDataCode,
+				ClusterName: f.cfg.ClusterName,
+			},
+			ServerMetadata: apievents.ServerMetadata{
+				ServerID:        f.cfg.ServerID,
+				ServerNamespace: f.cfg.Namespace,
+			},
+			SessionMetadata: apievents.SessionMetadata{
+				SessionID: string(sessionID),
+				WithMFA:   ctx.Identity.GetIdentity().MFAVerified,
+			},
+			UserMetadata: apievents.UserMetadata{
+				User:         ctx.User.GetName(),
+				Login:        ctx.User.GetName(),
+				Impersonator: ctx.Identity.GetIdentity().Impersonator,
+			},
+			ConnectionMetadata: apievents.ConnectionMetadata{
+				RemoteAddr: req.RemoteAddr,
+				LocalAddr:  sess.kubeAddress,
+				Protocol:   events.EventProtocolKube,
+			},
+			// Bytes transmitted from user to pod.
+			BytesTransmitted: trackIn.Count(),
+			// Bytes received from pod by user.
+			BytesReceived: trackOut.Count() + trackErr.Count(),
+		}
+		if err := emitter.EmitAuditEvent(f.ctx, sessionDataEvent); err != nil {
+			f.log.WithError(err).Warn("Failed to emit session data event.")
+		}
+		sessionEndEvent := &apievents.SessionEnd{
+			Metadata: apievents.Metadata{
+				Type:        events.SessionEndEvent,
+				Code:        events.SessionEndCode,
+				ClusterName: f.cfg.ClusterName,
+			},
+			ServerMetadata: apievents.ServerMetadata{
+				ServerID:        f.cfg.ServerID,
+				ServerNamespace: f.cfg.Namespace,
+			},
+			SessionMetadata: apievents.SessionMetadata{
+				SessionID: string(sessionID),
+				WithMFA:   ctx.Identity.GetIdentity().MFAVerified,
+			},
+			UserMetadata: apievents.UserMetadata{
+				User:         ctx.User.GetName(),
+				Login:        ctx.User.GetName(),
+				Impersonator: ctx.Identity.GetIdentity().Impersonator,
+			},
+			ConnectionMetadata: apievents.ConnectionMetadata{
+				RemoteAddr: req.RemoteAddr,
+				LocalAddr:  sess.kubeAddress,
+				Protocol:   events.EventProtocolKube,
+			},
+			Interactive: request.tty,
+			// There can only be 1 participant, k8s sessions are not join-able.
+			Participants:              []string{ctx.User.GetName()},
+			StartTime:                 sessionStart,
+			EndTime:                   f.cfg.Clock.Now().UTC(),
+			KubernetesClusterMetadata: ctx.eventClusterMeta(),
+			KubernetesPodMetadata:     eventPodMeta,
+			InitialCommand:            request.cmd,
+			SessionRecording:          ctx.recordingConfig.GetMode(),
+		}
+		if err := emitter.EmitAuditEvent(f.ctx, sessionEndEvent); err != nil {
+			f.log.WithError(err).Warn("Failed to emit session end event.")
+		}
 	}()
 
 	if err = executor.Stream(streamOptions); err != nil {
@@ -1232,6 +1236,13 @@ func (f *Forwarder) catchAll(ctx *authContext, w http.ResponseWriter, req *http.
 		f.log.Errorf("Failed to set up forwarding headers: %v.", err)
 		return nil, trace.Wrap(err)
 	}
+	// Capture the body of mutating requests as it is forwarded so the
+	// audit event can carry the submitted object change.
+	var requestBody *cappedBuffer
+	if !sess.noAuditEvents && isMutatingVerb(req.Method) {
+		requestBody = captureRequestBody(req)
+	}
+
 	rw := newResponseStatusRecorder(w)
 	sess.forwarder.ServeHTTP(rw, req)
 
@@ -1269,6 +1280,9 @@ func (f *Forwarder) catchAll(ctx *authContext, w http.ResponseWriter, req *http.
 		return nil, nil
 	}
 	r.populateEvent(event)
+	if requestBody != nil {
+		event.RequestDiff = kubeRequestDiff(r, requestBody)
+	}
 	if err := f.cfg.AuthClient.EmitAuditEvent(f.ctx, event); err != nil {
 		f.log.WithError(err).Warn("Failed to emit event.")
 	}
@@ -295,6 +295,18 @@ func detect(conn net.Conn, enableProxyProtocol bool) (*Conn, error) {
 				return nil, trace.Wrap(err)
 			}
 			// repeat the cycle to detect the protocol
+		case ProtoProxyV2:
+			if !enableProxyProtocol {
+				return nil, trace.BadParameter("proxy protocol support is disabled")
+			}
+			if proxyLine != nil {
+				return nil, trace.BadParameter("duplicate proxy line")
+			}
+			proxyLine, err = ReadProxyLineV2(reader)
+			if err != nil {
+				return nil, trace.Wrap(err)
+			}
+			// repeat the cycle to detect the protocol
 		case ProtoTLS, ProtoSSH, ProtoHTTP:
 			return &Conn{
 				protocol:  proto,
@@ -330,6 +342,8 @@ const (
 	ProtoHTTP
 	// ProtoPostgres is PostgreSQL wire protocol
 	ProtoPostgres
+	// ProtoProxyV2 is a PROXY protocol version 2
+	ProtoProxyV2
 )
 
 // protocolStrings defines strings for each Protocol.
@@ -340,6 +354,7 @@ var protocolStrings = map[Protocol]string{
 	ProtoProxy:    "Proxy",
 	ProtoHTTP:     "HTTP",
 	ProtoPostgres: "Postgres",
+	ProtoProxyV2:  "ProxyV2",
 }
 
 // String returns the string representation of Protocol p.
@@ -398,6 +413,9 @@ func detectProto(in []byte) (Protocol, error) {
 	// reader peeks only 3 bytes, slice the longer proxy prefix
 	case bytes.HasPrefix(in, proxyPrefix[:3]):
 		return ProtoProxy, nil
+	// reader peeks only 8 bytes, slice the longer v2 signature
+	case bytes.HasPrefix(in, proxyV2Prefix[:8]):
+		return ProtoProxyV2, nil
 	case bytes.HasPrefix(in, sshPrefix):
 		return ProtoSSH, nil
 	case bytes.HasPrefix(in, tlsPrefix):
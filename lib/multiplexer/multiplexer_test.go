@@ -190,6 +190,60 @@ func TestMux(t *testing.T) {
 		require.Equal(t, out, remoteAddr.String())
 	})
 
+	// ProxyLineV2 tests proxy protocol v2
+	t.Run("ProxyLineV2", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.Nil(t, err)
+
+		mux, err := New(Config{
+			Listener:            listener,
+			EnableProxyProtocol: true,
+		})
+		require.Nil(t, err)
+		go mux.Serve()
+		defer mux.Close()
+
+		backend1 := &httptest.Server{
+			Listener: mux.TLS(),
+			Config: &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintf(w, r.RemoteAddr)
+			}),
+			},
+		}
+		backend1.StartTLS()
+		defer backend1.Close()
+
+		remoteAddr := net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 8000}
+		proxyLine := ProxyLine{
+			Protocol:    TCP4,
+			Source:      remoteAddr,
+			Destination: net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 9000},
+		}
+
+		parsedURL, err := url.Parse(backend1.URL)
+		require.Nil(t, err)
+
+		conn, err := net.Dial("tcp", parsedURL.Host)
+		require.Nil(t, err)
+		defer conn.Close()
+		// send binary proxy protocol v2 header first before establishing
+		// TLS connection
+		header, err := proxyLine.Bytes()
+		require.Nil(t, err)
+		_, err = conn.Write(header)
+		require.Nil(t, err)
+
+		// upgrade connection to TLS
+		tlsConn := tls.Client(conn, clientConfig(backend1))
+		defer tlsConn.Close()
+
+		// make sure the TLS call succeeded and we got remote address
+		// correctly
+		out, err := utils.RoundtripWithConn(tlsConn)
+		require.Nil(t, err)
+		require.Equal(t, out, remoteAddr.String())
+	})
+
 	// TestDisabledProxy makes sure the connection gets dropped
 	// when Proxy line support protocol is turned off
 	t.Run("DisabledProxy", func(t *testing.T) {
@@ -20,7 +20,11 @@ package multiplexer
 
 import (
 	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"net"
 	"strconv"
 	"strings"
@@ -40,6 +44,10 @@ const (
 var (
 	proxyCRLF = "\r\n"
 	proxySep  = " "
+
+	// proxyV2Prefix is the unique signature that starts a PROXY protocol
+	// version 2 binary header.
+	proxyV2Prefix = []byte{0x0D, 0x0A, 0x0D, 0x0A, 0x00, 0x0D, 0x0A, 0x51, 0x55, 0x49, 0x54, 0x0A}
 )
 
 // ProxyLine is HA Proxy protocol version 1
@@ -99,6 +107,153 @@ func ReadProxyLine(reader *bufio.Reader) (*ProxyLine, error) {
 	return &ret, nil
 }
 
+// Bytes returns the PROXY protocol version 2 binary encoding of the
+// proxy line:
+//
+// https://www.haproxy.org/download/1.8/doc/proxy-protocol.txt
+func (p *ProxyLine) Bytes() ([]byte, error) {
+	header := proxyV2Header{VersionCommand: (Version2 << 4) | ProxyCommand}
+	copy(header.Signature[:], proxyV2Prefix)
+	var buf bytes.Buffer
+	sourceIPv4, destIPv4 := p.Source.IP.To4(), p.Destination.IP.To4()
+	if sourceIPv4 != nil && destIPv4 != nil {
+		addr := proxyV2Address4{
+			SourcePort:      uint16(p.Source.Port),
+			DestinationPort: uint16(p.Destination.Port),
+		}
+		copy(addr.Source[:], sourceIPv4)
+		copy(addr.Destination[:], destIPv4)
+		header.Protocol = ProtocolTCP4
+		header.Length = uint16(binary.Size(addr))
+		if err := binary.Write(&buf, binary.BigEndian, header); err != nil {
+			return nil, trace.Wrap(err)
+		}
+		if err := binary.Write(&buf, binary.BigEndian, addr); err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return buf.Bytes(), nil
+	}
+	addr := proxyV2Address6{
+		SourcePort:      uint16(p.Source.Port),
+		DestinationPort: uint16(p.Destination.Port),
+	}
+	copy(addr.Source[:], p.Source.IP.To16())
+	copy(addr.Destination[:], p.Destination.IP.To16())
+	header.Protocol = ProtocolTCP6
+	header.Length = uint16(binary.Size(addr))
+	if err := binary.Write(&buf, binary.BigEndian, header); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if err := binary.Write(&buf, binary.BigEndian, addr); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ReadProxyLineV2 reads PROXY protocol version 2 binary header from the
+// reader. Returns nil without an error for a LOCAL command header which
+// carries no address information (sent e.g. by load balancer health checks).
+func ReadProxyLineV2(reader *bufio.Reader) (*ProxyLine, error) {
+	var header proxyV2Header
+	if err := binary.Read(reader, binary.BigEndian, &header); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if !bytes.Equal(header.Signature[:], proxyV2Prefix) {
+		return nil, trace.BadParameter("unrecognized PROXY protocol v2 signature %v",
+			hex.EncodeToString(header.Signature[:]))
+	}
+	if header.VersionCommand>>4 != Version2 {
+		return nil, trace.BadParameter("unsupported PROXY protocol version %v", header.VersionCommand>>4)
+	}
+	remaining := int64(header.Length)
+	switch header.VersionCommand & 0xF {
+	case LocalCommand:
+		// LOCAL command carries no address information, skip the payload.
+		if _, err := io.CopyN(io.Discard, reader, remaining); err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return nil, nil
+	case ProxyCommand:
+	default:
+		return nil, trace.BadParameter("unsupported PROXY protocol command %v", header.VersionCommand&0xF)
+	}
+	var ret ProxyLine
+	switch header.Protocol {
+	case ProtocolTCP4:
+		var addr proxyV2Address4
+		if int64(binary.Size(addr)) > remaining {
+			return nil, trace.BadParameter("malformed PROXY protocol v2 header length %v", header.Length)
+		}
+		if err := binary.Read(reader, binary.BigEndian, &addr); err != nil {
+			return nil, trace.Wrap(err)
+		}
+		ret.Protocol = TCP4
+		ret.Source = net.TCPAddr{IP: addr.Source[:], Port: int(addr.SourcePort)}
+		ret.Destination = net.TCPAddr{IP: addr.Destination[:], Port: int(addr.DestinationPort)}
+		remaining -= int64(binary.Size(addr))
+	case ProtocolTCP6:
+		var addr proxyV2Address6
+		if int64(binary.Size(addr)) > remaining {
+			return nil, trace.BadParameter("malformed PROXY protocol v2 header length %v", header.Length)
+		}
+		if err := binary.Read(reader, binary.BigEndian, &addr); err != nil {
+			return nil, trace.Wrap(err)
+		}
+		ret.Protocol = TCP6
+		ret.Source = net.TCPAddr{IP: addr.Source[:], Port: int(addr.SourcePort)}
+		ret.Destination = net.TCPAddr{IP: addr.Destination[:], Port: int(addr.DestinationPort)}
+		remaining -= int64(binary.Size(addr))
+	default:
+		return nil, trace.BadParameter("unsupported PROXY protocol transport %#x", header.Protocol)
+	}
+	// Skip TLV vectors some load balancers (e.g. AWS NLB) append after
+	// the address information.
+	if _, err := io.CopyN(io.Discard, reader, remaining); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &ret, nil
+}
+
+const (
+	// Version2 identifies PROXY protocol version 2.
+	Version2 = 2
+	// ProxyCommand is sent by a proxy relaying a real client connection.
+	ProxyCommand = 1
+	// LocalCommand is sent for connections established by the proxy itself,
+	// e.g. health checks, and carries no address information.
+	LocalCommand = 0
+	// ProtocolTCP4 is the PROXY protocol v2 transport for TCP over IPv4.
+	ProtocolTCP4 = 0x11
+	// ProtocolTCP6 is the PROXY protocol v2 transport for TCP over IPv6.
+	ProtocolTCP6 = 0x21
+)
+
+// proxyV2Header is the fixed part of the PROXY protocol v2 binary header.
+type proxyV2Header struct {
+	Signature      [12]uint8
+	VersionCommand uint8
+	Protocol       uint8
+	Length         uint16
+}
+
+// proxyV2Address4 is the address part of a PROXY protocol v2 header for
+// TCP over IPv4.
+type proxyV2Address4 struct {
+	Source          [4]uint8
+	Destination     [4]uint8
+	SourcePort      uint16
+	DestinationPort uint16
+}
+
+// proxyV2Address6 is the address part of a PROXY protocol v2 header for
+// TCP over IPv6.
+type proxyV2Address6 struct {
+	Source          [16]uint8
+	Destination     [16]uint8
+	SourcePort      uint16
+	DestinationPort uint16
+}
+
 func parsePortNumber(portString string) (int, error) {
 	port, err := strconv.Atoi(portString)
 	if err != nil {
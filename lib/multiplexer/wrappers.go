@@ -82,9 +82,19 @@ func (c *Conn) Detect() (Protocol, error) {
 	return proto, nil
 }
 
-// ReadProxyLine reads proxy-line from the connection.
+// ReadProxyLine reads proxy-line from the connection, detecting whether
+// it is sent in the text (version 1) or binary (version 2) format.
 func (c *Conn) ReadProxyLine() (*ProxyLine, error) {
-	proxyLine, err := ReadProxyLine(c.reader)
+	proto, err := c.Detect()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var proxyLine *ProxyLine
+	if proto == ProtoProxyV2 {
+		proxyLine, err = ReadProxyLineV2(c.reader)
+	} else {
+		proxyLine, err = ReadProxyLine(c.reader)
+	}
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
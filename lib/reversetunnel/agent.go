@@ -101,6 +101,10 @@ type AgentConfig struct {
 	Log log.FieldLogger
 	// FIPS indicates if Teleport was started in FIPS mode.
 	FIPS bool
+	// OutboundProxy optionally specifies an explicitly configured outbound
+	// proxy the reverse tunnel is established through. When not set,
+	// proxy settings are picked up from the environment.
+	OutboundProxy *proxy.OutboundProxyConfig
 	// reverseTunnelDetails cacheable details about the Addr endpoint used to reduce proxy ping calls in order to prevent
 	// proxy endpoint stagnation where even numbers of proxy are hidden behind RoundRobbin Load Balancer.
 	// For instance in a situation where only two proxies [A, B] are configured behind RoundRobbin Load Balancer
@@ -285,7 +289,15 @@ func (a *Agent) connect() (conn *ssh.Client, err error) {
 
 	for _, authMethod := range a.authMethods {
 		// Create a dialer (that respects HTTP proxies) and connect to remote host.
-		dialer := proxy.DialerFromEnvironment(a.Addr.Addr, opts...)
+		var dialer proxy.Dialer
+		if a.OutboundProxy != nil {
+			dialer, err = proxy.DialerFromConfig(*a.OutboundProxy, opts...)
+			if err != nil {
+				return nil, trace.Wrap(err)
+			}
+		} else {
+			dialer = proxy.DialerFromEnvironment(a.Addr.Addr, opts...)
+		}
 		pconn, err := dialer.DialTimeout(a.Addr.AddrNetwork, a.Addr.Addr, apidefaults.DefaultDialTimeout)
 		if err != nil {
 			a.log.WithError(err).Debugf("Dial to %v failed.", a.Addr.Addr)
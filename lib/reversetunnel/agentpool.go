@@ -28,6 +28,7 @@ import (
 	"github.com/gravitational/teleport/lib/defaults"
 	"github.com/gravitational/teleport/lib/reversetunnel/track"
 	"github.com/gravitational/teleport/lib/utils"
+	"github.com/gravitational/teleport/lib/utils/proxy"
 
 	"github.com/gravitational/trace"
 	"github.com/jonboulle/clockwork"
@@ -95,6 +96,9 @@ type AgentPoolConfig struct {
 	Cluster string
 	// FIPS indicates if Teleport was started in FIPS mode.
 	FIPS bool
+	// OutboundProxy optionally specifies an explicitly configured outbound
+	// proxy the reverse tunnels are established through.
+	OutboundProxy *proxy.OutboundProxyConfig
 }
 
 // CheckAndSetDefaults checks and sets defaults
@@ -300,6 +304,7 @@ func (m *AgentPool) addAgent(lease track.Lease) error {
 		Tracker:              m.proxyTracker,
 		Lease:                lease,
 		FIPS:                 m.cfg.FIPS,
+		OutboundProxy:        m.cfg.OutboundProxy,
 		reverseTunnelDetails: m.getReverseTunnelDetails(addr),
 	})
 	if err != nil {
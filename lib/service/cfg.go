@@ -56,6 +56,7 @@ import (
 	"github.com/gravitational/teleport/lib/sshca"
 	"github.com/gravitational/teleport/lib/tlsca"
 	"github.com/gravitational/teleport/lib/utils"
+	utilsproxy "github.com/gravitational/teleport/lib/utils/proxy"
 
 	"github.com/ghodss/yaml"
 	"github.com/gravitational/trace"
@@ -568,6 +569,10 @@ type SSHConfig struct {
 	// the inactivity timeout expiring. The empty string indicates that no
 	// timeout message will be sent.
 	IdleTimeoutMessage string
+
+	// OutboundProxy optionally specifies an outbound proxy the node
+	// establishes its reverse tunnel through.
+	OutboundProxy *utilsproxy.OutboundProxyConfig
 }
 
 // KubeConfig specifies configuration for kubernetes service
@@ -612,8 +617,15 @@ type DatabasesConfig struct {
 	ResourceMatchers []services.ResourceMatcher
 	// AWSMatchers match AWS hosted databases.
 	AWSMatchers []services.AWSMatcher
+	// AzureMatchers match Azure hosted databases.
+	AzureMatchers []services.AzureMatcher
+	// GCPMatchers match GCP hosted databases.
+	GCPMatchers []services.GCPMatcher
 	// Limiter limits the connection and request rates.
 	Limiter limiter.Config
+	// OutboundProxy optionally specifies an outbound proxy the database
+	// service establishes its reverse tunnel through.
+	OutboundProxy *utilsproxy.OutboundProxyConfig
 }
 
 // Database represents a single database that's being proxied.
@@ -916,10 +928,17 @@ type WindowsDesktopConfig struct {
 	// Hosts is an optional list of static Windows hosts to expose through this
 	// service.
 	Hosts []utils.NetAddr
+	// NonADHosts is an optional list of standalone Windows hosts without
+	// Active Directory to expose through this service.
+	NonADHosts []utils.NetAddr
 	// ConnLimiter limits the connection and request rates.
 	ConnLimiter limiter.Config
 	// HostLabels specifies rules that are used to apply labels to Windows hosts.
 	HostLabels HostLabelRules
+	// ScreenshotInterval is how often to capture a still PNG of each
+	// desktop session and attach it to the session recording. Zero
+	// disables periodic screenshots.
+	ScreenshotInterval time.Duration
 }
 
 type LDAPDiscoveryConfig struct {
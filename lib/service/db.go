@@ -33,7 +33,9 @@ import (
 func (process *TeleportProcess) initDatabases() {
 	if len(process.Config.Databases.Databases) == 0 &&
 		len(process.Config.Databases.ResourceMatchers) == 0 &&
-		len(process.Config.Databases.AWSMatchers) == 0 {
+		len(process.Config.Databases.AWSMatchers) == 0 &&
+		len(process.Config.Databases.AzureMatchers) == 0 &&
+		len(process.Config.Databases.GCPMatchers) == 0 {
 		return
 	}
 	process.registerWithAuthServer(types.RoleDatabase, DatabasesIdentityEvent)
@@ -192,6 +194,8 @@ func (process *TeleportProcess) initDatabaseService() (retErr error) {
 		Databases:        databases,
 		ResourceMatchers: process.Config.Databases.ResourceMatchers,
 		AWSMatchers:      process.Config.Databases.AWSMatchers,
+		AzureMatchers:    process.Config.Databases.AzureMatchers,
+		GCPMatchers:      process.Config.Databases.GCPMatchers,
 		OnHeartbeat:      process.onHeartbeat(teleport.ComponentDatabase),
 		LockWatcher:      lockWatcher,
 	})
@@ -210,15 +214,16 @@ func (process *TeleportProcess) initDatabaseService() (retErr error) {
 	// Create and start the agent pool.
 	agentPool, err := reversetunnel.NewAgentPool(process.ExitContext(),
 		reversetunnel.AgentPoolConfig{
-			Component:   teleport.ComponentDatabase,
-			HostUUID:    conn.ServerIdentity.ID.HostUUID,
-			ProxyAddr:   tunnelAddr,
-			Client:      conn.Client,
-			Server:      dbService,
-			AccessPoint: conn.Client,
-			HostSigner:  conn.ServerIdentity.KeySigner,
-			Cluster:     clusterName,
-			FIPS:        process.Config.FIPS,
+			Component:     teleport.ComponentDatabase,
+			HostUUID:      conn.ServerIdentity.ID.HostUUID,
+			ProxyAddr:     tunnelAddr,
+			Client:        conn.Client,
+			Server:        dbService,
+			AccessPoint:   conn.Client,
+			HostSigner:    conn.ServerIdentity.KeySigner,
+			Cluster:       clusterName,
+			FIPS:          process.Config.FIPS,
+			OutboundProxy: process.Config.Databases.OutboundProxy,
 		})
 	if err != nil {
 		return trace.Wrap(err)
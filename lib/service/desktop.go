@@ -205,20 +205,23 @@ func (process *TeleportProcess) initWindowsDesktopServiceRegistered(log *logrus.
 	}
 
 	srv, err := desktop.NewWindowsService(desktop.WindowsServiceConfig{
-		Log:          log,
-		Clock:        process.Clock,
-		Authorizer:   authorizer,
-		Emitter:      conn.Client,
-		TLS:          tlsConfig,
-		AccessPoint:  accessPoint,
-		ConnLimiter:  connLimiter,
-		LockWatcher:  lockWatcher,
-		AuthClient:   conn.Client,
-		HostLabelsFn: cfg.WindowsDesktop.HostLabels.LabelsForHost,
+		Log:                log,
+		Clock:              process.Clock,
+		Authorizer:         authorizer,
+		Emitter:            conn.Client,
+		TLS:                tlsConfig,
+		AccessPoint:        accessPoint,
+		ConnLimiter:        connLimiter,
+		LockWatcher:        lockWatcher,
+		AuthClient:         conn.Client,
+		DataDir:            cfg.DataDir,
+		ScreenshotInterval: cfg.WindowsDesktop.ScreenshotInterval,
+		HostLabelsFn:       cfg.WindowsDesktop.HostLabels.LabelsForHost,
 		Heartbeat: desktop.HeartbeatConfig{
 			HostUUID:    cfg.HostUUID,
 			PublicAddr:  publicAddr,
 			StaticHosts: cfg.WindowsDesktop.Hosts,
+			NonADHosts:  cfg.WindowsDesktop.NonADHosts,
 			OnHeartbeat: process.onHeartbeat(teleport.ComponentWindowsDesktop),
 		},
 		LDAPConfig:           desktop.LDAPConfig(cfg.WindowsDesktop.LDAP),
@@ -32,6 +32,7 @@ import (
 	"net/http/httptest"
 	"net/http/httputil"
 	"net/http/pprof"
+	"net/url"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -66,6 +67,7 @@ import (
 	"github.com/gravitational/teleport/lib/defaults"
 	"github.com/gravitational/teleport/lib/events"
 	"github.com/gravitational/teleport/lib/events/dynamoevents"
+	"github.com/gravitational/teleport/lib/events/encryptedsessions"
 	"github.com/gravitational/teleport/lib/events/filesessions"
 	"github.com/gravitational/teleport/lib/events/firestoreevents"
 	"github.com/gravitational/teleport/lib/events/gcssessions"
@@ -961,43 +963,87 @@ func initUploadHandler(ctx context.Context, auditConfig types.ClusterAuditConfig
 		return nil, trace.Wrap(err)
 	}
 
+	var handler events.MultipartHandler
 	switch uri.Scheme {
 	case teleport.SchemeGCS:
 		config := gcssessions.Config{}
 		if err := config.SetFromURL(uri); err != nil {
 			return nil, trace.Wrap(err)
 		}
-		handler, err := gcssessions.DefaultNewHandler(ctx, config)
+		handler, err = gcssessions.DefaultNewHandler(ctx, config)
 		if err != nil {
 			return nil, trace.Wrap(err)
 		}
-		return handler, nil
 	case teleport.SchemeS3:
 		config := s3sessions.Config{}
 		if err := config.SetFromURL(uri, auditConfig.Region()); err != nil {
 			return nil, trace.Wrap(err)
 		}
-		handler, err := s3sessions.NewHandler(ctx, config)
+		handler, err = s3sessions.NewHandler(ctx, config)
 		if err != nil {
 			return nil, trace.Wrap(err)
 		}
-		return handler, nil
 	case teleport.SchemeFile:
 		if err := os.MkdirAll(uri.Path, teleport.SharedDirMode); err != nil {
 			return nil, trace.ConvertSystemError(err)
 		}
-		handler, err := filesessions.NewHandler(filesessions.Config{
+		handler, err = filesessions.NewHandler(filesessions.Config{
 			Directory: uri.Path,
 		})
 		if err != nil {
 			return nil, trace.Wrap(err)
 		}
-		return handler, nil
 	default:
 		return nil, trace.BadParameter(
 			"unsupported scheme for audit_sesions_uri: %q, currently supported schemes are %q and %q",
 			uri.Scheme, teleport.SchemeS3, teleport.SchemeFile)
 	}
+	handler, err = maybeEncryptUploads(uri, handler)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return handler, nil
+}
+
+// maybeEncryptUploads wraps the upload handler with envelope encryption
+// if the audit_sessions_uri requests it via the recording_encryption_key
+// (path to a PEM-encoded RSA recipient key) or recording_encryption_kms_key
+// (AWS KMS key ID or ARN) query parameters.
+func maybeEncryptUploads(uri *url.URL, handler events.MultipartHandler) (events.MultipartHandler, error) {
+	recipientKeyPath := uri.Query().Get("recording_encryption_key")
+	kmsKeyID := uri.Query().Get("recording_encryption_kms_key")
+	switch {
+	case recipientKeyPath != "" && kmsKeyID != "":
+		return nil, trace.BadParameter("specify either recording_encryption_key or recording_encryption_kms_key in audit_sessions_uri, not both")
+	case recipientKeyPath != "":
+		keyPEM, err := ioutil.ReadFile(recipientKeyPath)
+		if err != nil {
+			return nil, trace.ConvertSystemError(err)
+		}
+		wrapper, err := encryptedsessions.NewRecipientKeyWrapper(keyPEM)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		encrypted, err := encryptedsessions.NewHandler(encryptedsessions.Config{
+			Inner:      handler,
+			KeyWrapper: wrapper,
+		})
+		return encrypted, trace.Wrap(err)
+	case kmsKeyID != "":
+		wrapper, err := encryptedsessions.NewKMSKeyWrapper(encryptedsessions.KMSKeyWrapperConfig{
+			KeyID: kmsKeyID,
+		})
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		encrypted, err := encryptedsessions.NewHandler(encryptedsessions.Config{
+			Inner:      handler,
+			KeyWrapper: wrapper,
+		})
+		return encrypted, trace.Wrap(err)
+	default:
+		return handler, nil
+	}
 }
 
 // initExternalLog initializes external storage, if the storage is not
@@ -1997,15 +2043,16 @@ func (process *TeleportProcess) initSSH() error {
 			agentPool, err = reversetunnel.NewAgentPool(
 				process.ExitContext(),
 				reversetunnel.AgentPoolConfig{
-					Component:   teleport.ComponentNode,
-					HostUUID:    conn.ServerIdentity.ID.HostUUID,
-					ProxyAddr:   conn.TunnelProxy(),
-					Client:      conn.Client,
-					AccessPoint: conn.Client,
-					HostSigner:  conn.ServerIdentity.KeySigner,
-					Cluster:     conn.ServerIdentity.Cert.Extensions[utils.CertExtensionAuthority],
-					Server:      s,
-					FIPS:        process.Config.FIPS,
+					Component:     teleport.ComponentNode,
+					HostUUID:      conn.ServerIdentity.ID.HostUUID,
+					ProxyAddr:     conn.TunnelProxy(),
+					Client:        conn.Client,
+					AccessPoint:   conn.Client,
+					HostSigner:    conn.ServerIdentity.KeySigner,
+					Cluster:       conn.ServerIdentity.Cert.Extensions[utils.CertExtensionAuthority],
+					Server:        s,
+					FIPS:          process.Config.FIPS,
+					OutboundProxy: cfg.SSH.OutboundProxy,
 				})
 			if err != nil {
 				return trace.Wrap(err)
@@ -2469,9 +2516,9 @@ func (process *TeleportProcess) getAdditionalPrincipals(role types.SystemRole) (
 
 // initProxy gets called if teleport runs with 'proxy' role enabled.
 // this means it will do two things:
-//    1. serve a web UI
-//    2. proxy SSH connections to nodes running with 'node' role
-//    3. take care of reverse tunnels
+//  1. serve a web UI
+//  2. proxy SSH connections to nodes running with 'node' role
+//  3. take care of reverse tunnels
 func (process *TeleportProcess) initProxy() error {
 	// If no TLS key was provided for the web listener, generate a self-signed cert
 	if len(process.Config.Proxy.KeyPairs) == 0 &&
@@ -2939,6 +2986,14 @@ func (process *TeleportProcess) initProxyEndpoint(conn *Connector) error {
 			return trace.Wrap(err)
 		}
 		proxyLimiter.WrapHandle(webHandler)
+		if alpnRouter != nil {
+			// Route TCP application connections to the app handler, they
+			// are identified by the ALPN protocol set by "tsh proxy app".
+			alpnRouter.Add(alpnproxy.HandlerDecs{
+				MatchFunc: alpnproxy.MatchByProtocol(alpncommon.ProtocolTCP),
+				Handler:   webHandler.HandleConnection,
+			})
+		}
 		if !cfg.Proxy.DisableTLS && cfg.Proxy.DisableALPNSNIListener {
 			listeners.tls, err = multiplexer.NewWebListener(multiplexer.WebListenerConfig{
 				Listener: tls.NewListener(listeners.web, tlsConfigWeb),
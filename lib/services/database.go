@@ -32,6 +32,7 @@ import (
 
 	"github.com/gravitational/trace"
 	log "github.com/sirupsen/logrus"
+	sqladmin "google.golang.org/api/sqladmin/v1beta4"
 )
 
 // DatabaseGetter defines interface for fetching database resources.
@@ -151,6 +152,75 @@ func NewDatabaseFromRDSCluster(cluster *rds.DBCluster) (types.Database, error) {
 	})
 }
 
+// AzureDBServer represents an Azure MySQL or PostgreSQL flexible server.
+type AzureDBServer struct {
+	// ID is the fully qualified resource ID of the server.
+	ID string
+	// Name is the server name.
+	Name string
+	// Location is the Azure region the server is in.
+	Location string
+	// Protocol is the database protocol, "mysql" or "postgres".
+	Protocol string
+	// Version is the database engine version.
+	Version string
+	// FQDN is the fully qualified domain name of the server.
+	FQDN string
+	// State is the server lifecycle state, e.g. "Ready".
+	State string
+	// Tags is the server's resource tags.
+	Tags map[string]string
+}
+
+// NewDatabaseFromAzureServer creates a database resource from an Azure
+// MySQL or PostgreSQL flexible server.
+func NewDatabaseFromAzureServer(server *AzureDBServer) (types.Database, error) {
+	if server.FQDN == "" {
+		return nil, trace.BadParameter("empty domain name for Azure server %q", server.Name)
+	}
+	port, err := protocolToPort(server.Protocol)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return types.NewDatabaseV3(types.Metadata{
+		Name:        server.Name,
+		Description: fmt.Sprintf("Azure %v server in %v", server.Protocol, server.Location),
+		Labels:      labelsFromAzureServer(server),
+	}, types.DatabaseSpecV3{
+		Protocol: server.Protocol,
+		URI:      fmt.Sprintf("%v:%v", server.FQDN, port),
+		Azure: types.Azure{
+			Name: server.Name,
+		},
+	})
+}
+
+// NewDatabaseFromCloudSQLInstance creates a database resource from a GCP
+// Cloud SQL instance.
+func NewDatabaseFromCloudSQLInstance(instance *sqladmin.DatabaseInstance) (types.Database, error) {
+	address, err := cloudSQLInstanceAddress(instance)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	protocol := cloudSQLEngineToProtocol(instance.DatabaseVersion)
+	port, err := protocolToPort(protocol)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return types.NewDatabaseV3(types.Metadata{
+		Name:        instance.Name,
+		Description: fmt.Sprintf("Cloud SQL instance in %v", instance.Region),
+		Labels:      labelsFromCloudSQLInstance(instance),
+	}, types.DatabaseSpecV3{
+		Protocol: protocol,
+		URI:      fmt.Sprintf("%v:%v", address, port),
+		GCP: types.GCPCloudSQL{
+			ProjectID:  instance.Project,
+			InstanceID: instance.Name,
+		},
+	})
+}
+
 // MetadataFromRDSInstance creates AWS metadata from the provided RDS instance.
 func MetadataFromRDSInstance(rdsInstance *rds.DBInstance) (*types.AWS, error) {
 	parsedARN, err := arn.Parse(aws.StringValue(rdsInstance.DBInstanceArn))
@@ -197,6 +267,46 @@ func engineToProtocol(engine string) string {
 	return ""
 }
 
+// cloudSQLEngineToProtocol converts Cloud SQL instance engine version, such
+// as MYSQL_8_0 or POSTGRES_13, to the database protocol.
+func cloudSQLEngineToProtocol(engine string) string {
+	switch {
+	case strings.HasPrefix(engine, CloudSQLEngineMySQLPrefix):
+		return defaults.ProtocolMySQL
+	case strings.HasPrefix(engine, CloudSQLEnginePostgresPrefix):
+		return defaults.ProtocolPostgres
+	}
+	return ""
+}
+
+// protocolToPort returns the standard database server port for the protocol.
+func protocolToPort(protocol string) (int, error) {
+	switch protocol {
+	case defaults.ProtocolMySQL:
+		return 3306, nil
+	case defaults.ProtocolPostgres:
+		return 5432, nil
+	}
+	return 0, trace.BadParameter("unsupported database protocol %q", protocol)
+}
+
+// cloudSQLInstanceAddress returns the Cloud SQL instance endpoint address.
+func cloudSQLInstanceAddress(instance *sqladmin.DatabaseInstance) (string, error) {
+	// Prefer the public address if the instance has one, otherwise fall
+	// back to the first assigned (e.g. private) address.
+	for _, ip := range instance.IpAddresses {
+		if ip.Type == "PRIMARY" {
+			return ip.IpAddress, nil
+		}
+	}
+	for _, ip := range instance.IpAddresses {
+		if ip.IpAddress != "" {
+			return ip.IpAddress, nil
+		}
+	}
+	return "", trace.BadParameter("Cloud SQL instance %q has no IP addresses assigned", instance.Name)
+}
+
 // labelsFromRDSInstance creates database labels for the provided RDS instance.
 func labelsFromRDSInstance(rdsInstance *rds.DBInstance, meta *types.AWS) map[string]string {
 	labels := rdsTagsToLabels(rdsInstance.TagList)
@@ -219,6 +329,45 @@ func labelsFromRDSCluster(rdsCluster *rds.DBCluster, meta *types.AWS) map[string
 	return labels
 }
 
+// labelsFromAzureServer creates database labels for the provided Azure server.
+func labelsFromAzureServer(server *AzureDBServer) map[string]string {
+	labels := cloudTagsToLabels(server.Tags)
+	labels[types.OriginLabel] = types.OriginCloud
+	labels[labelRegion] = server.Location
+	labels[labelEngine] = server.Protocol
+	labels[labelEngineVersion] = server.Version
+	return labels
+}
+
+// labelsFromCloudSQLInstance creates database labels for the provided Cloud SQL instance.
+func labelsFromCloudSQLInstance(instance *sqladmin.DatabaseInstance) map[string]string {
+	var labels map[string]string
+	if instance.Settings != nil {
+		labels = cloudTagsToLabels(instance.Settings.UserLabels)
+	} else {
+		labels = make(map[string]string)
+	}
+	labels[types.OriginLabel] = types.OriginCloud
+	labels[labelProjectID] = instance.Project
+	labels[labelRegion] = instance.Region
+	labels[labelEngine] = instance.DatabaseVersion
+	return labels
+}
+
+// cloudTagsToLabels converts cloud resource tags to a labels map, omitting
+// tags that aren't valid label keys.
+func cloudTagsToLabels(tags map[string]string) map[string]string {
+	labels := make(map[string]string)
+	for key, value := range tags {
+		if types.IsValidLabelKey(key) {
+			labels[key] = value
+		} else {
+			log.Debugf("Skipping cloud tag %q, not a valid label key.", key)
+		}
+	}
+	return labels
+}
+
 // rdsTagsToLabels converts RDS tags to a labels map.
 func rdsTagsToLabels(tags []*rds.Tag) map[string]string {
 	labels := make(map[string]string)
@@ -290,6 +439,8 @@ const (
 	labelEngine = "engine"
 	// labelEngineVersion is the label key containing RDS database engine version.
 	labelEngineVersion = "engine-version"
+	// labelProjectID is the label key containing GCP project ID.
+	labelProjectID = "project-id"
 )
 
 const (
@@ -317,3 +468,10 @@ const (
 	// RDSEngineModeMultiMaster is the RDS engine mode for Multi-master clusters
 	RDSEngineModeMultiMaster = "multimaster"
 )
+
+const (
+	// CloudSQLEngineMySQLPrefix is the Cloud SQL engine version prefix for MySQL instances.
+	CloudSQLEngineMySQLPrefix = "MYSQL_"
+	// CloudSQLEnginePostgresPrefix is the Cloud SQL engine version prefix for Postgres instances.
+	CloudSQLEnginePostgresPrefix = "POSTGRES_"
+)
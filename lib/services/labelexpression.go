@@ -0,0 +1,388 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/gravitational/trace"
+)
+
+// LabelExpression is a parsed label expression which can be evaluated
+// against a resource's labels. Expressions reference label values as
+// labels["key"] and combine string comparisons with boolean operators,
+// for example:
+//
+//	labels["env"] == "prod" && labels["team"] in ["db", "sre"]
+//
+// Supported operators are ==, !=, in, !, && and ||, with parentheses
+// for grouping. A label that is not present on the resource evaluates
+// to the empty string.
+type LabelExpression struct {
+	root exprNode
+}
+
+// Evaluate returns true if the expression matches the provided labels.
+func (e *LabelExpression) Evaluate(labels map[string]string) bool {
+	return e.root.eval(labels)
+}
+
+// expressionCache caches parsed label expressions keyed by their source
+// text. Expressions come from roles so the set is small, but parsing on
+// every access check would be wasteful since CheckAccess is called for
+// each resource returned by the backend.
+var expressionCache struct {
+	sync.RWMutex
+	parsed map[string]*LabelExpression
+}
+
+// ParseLabelExpression parses a label expression, returning an evaluator
+// that can be applied to resource labels. Results are cached, so it is
+// cheap to call repeatedly with the same expression.
+func ParseLabelExpression(expr string) (*LabelExpression, error) {
+	expressionCache.RLock()
+	cached := expressionCache.parsed[expr]
+	expressionCache.RUnlock()
+	if cached != nil {
+		return cached, nil
+	}
+	tokens, err := scanExpression(expr)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	p := &expressionParser{tokens: tokens}
+	root, err := p.parseExpression()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if !p.eof() {
+		return nil, trace.BadParameter("unexpected %q after end of label expression", p.peek().text)
+	}
+	parsed := &LabelExpression{root: root}
+	expressionCache.Lock()
+	if expressionCache.parsed == nil {
+		expressionCache.parsed = make(map[string]*LabelExpression)
+	}
+	expressionCache.parsed[expr] = parsed
+	expressionCache.Unlock()
+	return parsed, nil
+}
+
+// exprNode is a node of the parsed expression tree.
+type exprNode interface {
+	eval(labels map[string]string) bool
+}
+
+type andNode struct{ left, right exprNode }
+
+func (n andNode) eval(labels map[string]string) bool {
+	return n.left.eval(labels) && n.right.eval(labels)
+}
+
+type orNode struct{ left, right exprNode }
+
+func (n orNode) eval(labels map[string]string) bool {
+	return n.left.eval(labels) || n.right.eval(labels)
+}
+
+type notNode struct{ node exprNode }
+
+func (n notNode) eval(labels map[string]string) bool {
+	return !n.node.eval(labels)
+}
+
+// equalsNode compares two operands, negated for !=.
+type equalsNode struct {
+	left, right operand
+	negate      bool
+}
+
+func (n equalsNode) eval(labels map[string]string) bool {
+	return (n.left.value(labels) == n.right.value(labels)) != n.negate
+}
+
+// inNode checks membership of an operand in a list of operands.
+type inNode struct {
+	left operand
+	list []operand
+}
+
+func (n inNode) eval(labels map[string]string) bool {
+	value := n.left.value(labels)
+	for _, item := range n.list {
+		if item.value(labels) == value {
+			return true
+		}
+	}
+	return false
+}
+
+// operand is either a string literal or a labels["key"] reference.
+type operand struct {
+	literal string
+	key     string
+	isLabel bool
+}
+
+func (o operand) value(labels map[string]string) string {
+	if o.isLabel {
+		return labels[o.key]
+	}
+	return o.literal
+}
+
+// Token kinds produced by the scanner.
+const (
+	tokenIdent = iota
+	tokenString
+	tokenPunct
+)
+
+type exprToken struct {
+	kind int
+	text string
+}
+
+// scanExpression splits the expression into tokens: identifiers, quoted
+// string literals and punctuation (operators, brackets, parentheses).
+func scanExpression(expr string) ([]exprToken, error) {
+	var tokens []exprToken
+	runes := []rune(expr)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"' || r == '\'':
+			quote := r
+			j := i + 1
+			for j < len(runes) && runes[j] != quote {
+				j++
+			}
+			if j == len(runes) {
+				return nil, trace.BadParameter("unterminated string in label expression %q", expr)
+			}
+			tokens = append(tokens, exprToken{kind: tokenString, text: string(runes[i+1 : j])})
+			i = j + 1
+		case unicode.IsLetter(r) || r == '_':
+			j := i
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || runes[j] == '_') {
+				j++
+			}
+			tokens = append(tokens, exprToken{kind: tokenIdent, text: string(runes[i:j])})
+			i = j
+		case strings.ContainsRune("[](),", r):
+			tokens = append(tokens, exprToken{kind: tokenPunct, text: string(r)})
+			i++
+		case r == '&' || r == '|' || r == '=' || r == '!':
+			if i+1 < len(runes) {
+				pair := string(runes[i : i+2])
+				switch pair {
+				case "&&", "||", "==", "!=":
+					tokens = append(tokens, exprToken{kind: tokenPunct, text: pair})
+					i += 2
+					continue
+				}
+			}
+			if r != '!' {
+				return nil, trace.BadParameter("unexpected %q in label expression %q", string(r), expr)
+			}
+			tokens = append(tokens, exprToken{kind: tokenPunct, text: "!"})
+			i++
+		default:
+			return nil, trace.BadParameter("unexpected %q in label expression %q", string(r), expr)
+		}
+	}
+	return tokens, nil
+}
+
+// expressionParser is a recursive descent parser over the scanned tokens
+// with standard precedence: ! binds tighter than &&, which binds tighter
+// than ||.
+type expressionParser struct {
+	tokens []exprToken
+	pos    int
+}
+
+func (p *expressionParser) eof() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *expressionParser) peek() exprToken {
+	if p.eof() {
+		return exprToken{}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *expressionParser) consume(kind int, text string) bool {
+	if p.eof() {
+		return false
+	}
+	token := p.tokens[p.pos]
+	if token.kind != kind || token.text != text {
+		return false
+	}
+	p.pos++
+	return true
+}
+
+func (p *expressionParser) parseExpression() (exprNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for p.consume(tokenPunct, "||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		left = orNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *expressionParser) parseAnd() (exprNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for p.consume(tokenPunct, "&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		left = andNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *expressionParser) parseUnary() (exprNode, error) {
+	if p.consume(tokenPunct, "!") {
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return notNode{node: node}, nil
+	}
+	if p.consume(tokenPunct, "(") {
+		node, err := p.parseExpression()
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		if !p.consume(tokenPunct, ")") {
+			return nil, trace.BadParameter("missing closing parenthesis in label expression")
+		}
+		return node, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *expressionParser) parseComparison() (exprNode, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	switch {
+	case p.consume(tokenPunct, "=="):
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return equalsNode{left: left, right: right}, nil
+	case p.consume(tokenPunct, "!="):
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return equalsNode{left: left, right: right, negate: true}, nil
+	case p.consume(tokenIdent, "in"):
+		list, err := p.parseList()
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return inNode{left: left, list: list}, nil
+	}
+	return nil, trace.BadParameter("expected ==, != or in, got %q in label expression", p.peek().text)
+}
+
+func (p *expressionParser) parseList() ([]operand, error) {
+	if !p.consume(tokenPunct, "[") {
+		return nil, trace.BadParameter("expected list after \"in\" in label expression")
+	}
+	var list []operand
+	for {
+		item, err := p.parseOperand()
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		list = append(list, item)
+		if p.consume(tokenPunct, ",") {
+			continue
+		}
+		if p.consume(tokenPunct, "]") {
+			return list, nil
+		}
+		return nil, trace.BadParameter("expected , or ] in label expression list, got %q", p.peek().text)
+	}
+}
+
+func (p *expressionParser) parseOperand() (operand, error) {
+	if p.eof() {
+		return operand{}, trace.BadParameter("unexpected end of label expression")
+	}
+	token := p.tokens[p.pos]
+	if token.kind == tokenString {
+		p.pos++
+		return operand{literal: token.text}, nil
+	}
+	if token.kind == tokenIdent && token.text == "labels" {
+		p.pos++
+		if !p.consume(tokenPunct, "[") {
+			return operand{}, trace.BadParameter("expected [ after \"labels\" in label expression")
+		}
+		if p.eof() || p.tokens[p.pos].kind != tokenString {
+			return operand{}, trace.BadParameter("expected quoted label key in label expression")
+		}
+		key := p.tokens[p.pos].text
+		p.pos++
+		if !p.consume(tokenPunct, "]") {
+			return operand{}, trace.BadParameter("expected ] after label key in label expression")
+		}
+		return operand{key: key, isLabel: true}, nil
+	}
+	return operand{}, trace.BadParameter("expected string literal or labels[...], got %q in label expression", token.text)
+}
+
+// MatchLabelsExpression evaluates the label expression against the target
+// labels. Empty expression matches nothing.
+func MatchLabelsExpression(expression string, target map[string]string) (bool, string, error) {
+	if expression == "" {
+		return false, "no match, empty expression", nil
+	}
+	parsed, err := ParseLabelExpression(expression)
+	if err != nil {
+		return false, "", trace.Wrap(err)
+	}
+	if !parsed.Evaluate(target) {
+		return false, fmt.Sprintf("no match, expression %q", expression), nil
+	}
+	return true, "matched", nil
+}
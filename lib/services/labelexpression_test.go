@@ -0,0 +1,185 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"testing"
+
+	"github.com/gravitational/teleport/api/types"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLabelExpression(t *testing.T) {
+	labels := map[string]string{
+		"env":  "prod",
+		"team": "db",
+		"os":   "linux",
+	}
+	tests := []struct {
+		name       string
+		expression string
+		parseError bool
+		match      bool
+	}{
+		{
+			name:       "simple equality match",
+			expression: `labels["env"] == "prod"`,
+			match:      true,
+		},
+		{
+			name:       "simple equality no match",
+			expression: `labels["env"] == "staging"`,
+			match:      false,
+		},
+		{
+			name:       "inequality",
+			expression: `labels["env"] != "staging"`,
+			match:      true,
+		},
+		{
+			name:       "list membership",
+			expression: `labels["team"] in ["db", "sre"]`,
+			match:      true,
+		},
+		{
+			name:       "list membership no match",
+			expression: `labels["team"] in ["web", "infra"]`,
+			match:      false,
+		},
+		{
+			name:       "conjunction",
+			expression: `labels["env"] == "prod" && labels["team"] in ["db", "sre"]`,
+			match:      true,
+		},
+		{
+			name:       "disjunction",
+			expression: `labels["env"] == "staging" || labels["os"] == "linux"`,
+			match:      true,
+		},
+		{
+			name:       "negation",
+			expression: `!(labels["env"] == "staging")`,
+			match:      true,
+		},
+		{
+			name:       "precedence of and over or",
+			expression: `labels["env"] == "staging" && labels["os"] == "linux" || labels["team"] == "db"`,
+			match:      true,
+		},
+		{
+			name:       "missing label evaluates to empty string",
+			expression: `labels["cloud"] == ""`,
+			match:      true,
+		},
+		{
+			name:       "label to label comparison",
+			expression: `labels["env"] == labels["team"]`,
+			match:      false,
+		},
+		{
+			name:       "single quoted strings",
+			expression: `labels['env'] == 'prod'`,
+			match:      true,
+		},
+		{
+			name:       "unterminated string",
+			expression: `labels["env] == "prod"`,
+			parseError: true,
+		},
+		{
+			name:       "missing operator",
+			expression: `labels["env"] "prod"`,
+			parseError: true,
+		},
+		{
+			name:       "missing closing parenthesis",
+			expression: `(labels["env"] == "prod"`,
+			parseError: true,
+		},
+		{
+			name:       "missing list after in",
+			expression: `labels["team"] in "db"`,
+			parseError: true,
+		},
+		{
+			name:       "trailing tokens",
+			expression: `labels["env"] == "prod" labels`,
+			parseError: true,
+		},
+		{
+			name:       "unknown identifier",
+			expression: `resource["env"] == "prod"`,
+			parseError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := ParseLabelExpression(tt.expression)
+			if tt.parseError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.match, parsed.Evaluate(labels))
+		})
+	}
+}
+
+func TestMatchRoleLabels(t *testing.T) {
+	target := map[string]string{"env": "prod", "team": "db"}
+	tests := []struct {
+		name       string
+		selector   types.Labels
+		expression string
+		match      bool
+	}{
+		{
+			name:     "selector only",
+			selector: types.Labels{"env": {"prod"}},
+			match:    true,
+		},
+		{
+			name:       "expression only",
+			expression: `labels["env"] == "prod"`,
+			match:      true,
+		},
+		{
+			name:       "both must match",
+			selector:   types.Labels{"env": {"prod"}},
+			expression: `labels["team"] == "sre"`,
+			match:      false,
+		},
+		{
+			name:       "both match",
+			selector:   types.Labels{"env": {"prod"}},
+			expression: `labels["team"] == "db"`,
+			match:      true,
+		},
+		{
+			name:  "neither set matches nothing",
+			match: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, _, err := matchRoleLabels(tt.selector, tt.expression, target)
+			require.NoError(t, err)
+			require.Equal(t, tt.match, match)
+		})
+	}
+}
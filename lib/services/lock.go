@@ -49,6 +49,14 @@ func LockTargetsFromTLSIdentity(id tlsca.Identity) []types.LockTarget {
 	if id.MFAVerified != "" {
 		lockTargets = append(lockTargets, types.LockTarget{MFADevice: id.MFAVerified})
 	}
+	// Certificates routed to a specific database or application also make
+	// the connection subject to locks targeting that resource.
+	if id.RouteToDatabase.ServiceName != "" {
+		lockTargets = append(lockTargets, types.LockTarget{Database: id.RouteToDatabase.ServiceName})
+	}
+	if id.RouteToApp.Name != "" {
+		lockTargets = append(lockTargets, types.LockTarget{Application: id.RouteToApp.Name})
+	}
 	return lockTargets
 }
 
@@ -38,6 +38,29 @@ type AWSMatcher struct {
 	Tags types.Labels
 }
 
+// AzureMatcher matches Azure databases.
+type AzureMatcher struct {
+	// Types are Azure database types to match, "mysql" or "postgres".
+	Types []string
+	// Subscriptions are Azure subscription IDs to query for databases.
+	Subscriptions []string
+	// Regions are Azure regions to filter databases by. Empty means
+	// all regions.
+	Regions []string
+	// Tags are Azure resource tags to match.
+	Tags types.Labels
+}
+
+// GCPMatcher matches GCP databases.
+type GCPMatcher struct {
+	// Types are GCP database types to match, currently only "cloudsql".
+	Types []string
+	// ProjectIDs are GCP project IDs to query for databases.
+	ProjectIDs []string
+	// Labels are GCP labels to match.
+	Labels types.Labels
+}
+
 // MatchResourceLabels returns true if any of the provided selectors matches the provided database.
 func MatchResourceLabels(matchers []ResourceMatcher, resource types.ResourceWithLabels) bool {
 	for _, matcher := range matchers {
@@ -63,3 +86,15 @@ const (
 	// AWSMatcherRedshift is the AWS matcher type for Redshift databases.
 	AWSMatcherRedshift = "redshift"
 )
+
+const (
+	// AzureMatcherMySQL is the Azure matcher type for MySQL flexible servers.
+	AzureMatcherMySQL = "mysql"
+	// AzureMatcherPostgres is the Azure matcher type for PostgreSQL flexible servers.
+	AzureMatcherPostgres = "postgres"
+)
+
+const (
+	// GCPMatcherCloudSQL is the GCP matcher type for Cloud SQL databases.
+	GCPMatcherCloudSQL = "cloudsql"
+)
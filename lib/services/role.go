@@ -20,6 +20,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
+	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 	"time"
@@ -200,6 +203,27 @@ func ValidateRole(r types.Role) error {
 		}
 	}
 
+	// Label expressions must parse so that invalid ones are rejected at
+	// role creation time instead of failing access checks later.
+	for _, condition := range []types.RoleConditionType{types.Allow, types.Deny} {
+		for _, kind := range []string{types.KindNode, types.KindApp, types.KindDatabase, types.KindWindowsDesktop} {
+			expression := r.GetLabelsExpression(condition, kind)
+			if expression == "" {
+				continue
+			}
+			if _, err := ParseLabelExpression(expression); err != nil {
+				return trace.BadParameter("invalid %v label expression: %v", kind, err)
+			}
+		}
+	}
+
+	// Patterns in the session restriction conditions must compile so that
+	// a typo is rejected here instead of failing closed when the
+	// restriction is enforced.
+	if err := validateRestrictionPatterns(r); err != nil {
+		return trace.Wrap(err)
+	}
+
 	rules := append(r.GetRules(types.Allow), r.GetRules(types.Deny)...)
 	for _, rule := range rules {
 		if err := validateRule(rule); err != nil {
@@ -210,6 +234,30 @@ func ValidateRole(r types.Role) error {
 	return nil
 }
 
+// validateRestrictionPatterns compiles the patterns in the session
+// restriction conditions, so a malformed pattern is rejected when the role
+// is created or updated.
+func validateRestrictionPatterns(r types.Role) error {
+	for _, condition := range []types.RoleConditionType{types.Allow, types.Deny} {
+		for _, pattern := range r.GetDatabaseQueries(condition) {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return trace.BadParameter("invalid db_queries regular expression %q: %v", pattern, err)
+			}
+		}
+		for _, pattern := range r.GetCommands(condition) {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				return trace.BadParameter("invalid commands glob pattern %q", pattern)
+			}
+		}
+		for _, pattern := range r.GetPortForwardingDestinations(condition) {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				return trace.BadParameter("invalid port_forwarding_destinations glob pattern %q", pattern)
+			}
+		}
+	}
+	return nil
+}
+
 // validateRule parses the where and action fields to validate the rule.
 func validateRule(r types.Rule) error {
 	if len(r.Where) != 0 {
@@ -684,6 +732,81 @@ type AccessChecker interface {
 	// is allowed to use.
 	CheckDatabaseNamesAndUsers(ttl time.Duration, overrideTTL bool) (names []string, users []string, err error)
 
+	// MaskedDatabaseColumns returns a list of column name patterns whose
+	// values should be masked in database query results.
+	MaskedDatabaseColumns() []string
+
+	// RejectDatabaseMultiStatements returns true if database queries
+	// containing multiple statements should be rejected.
+	RejectDatabaseMultiStatements() bool
+
+	// DatabaseResultLimits returns the maximum number of rows and bytes a
+	// single database query may return, zero meaning no limit.
+	DatabaseResultLimits() (rows, bytes int64)
+
+	// MFAVerificationInterval returns the maximum lifetime of a database
+	// session started with a per-session MFA check, zero meaning
+	// unlimited. The session is terminated after the interval and the
+	// user must reconnect, passing a new MFA check.
+	MFAVerificationInterval() time.Duration
+
+	// DatabaseQueryFilters returns regular expression patterns for
+	// database queries the user is allowed or denied to execute.
+	DatabaseQueryFilters() (allow, deny []string)
+
+	// RejectDatabaseCopy returns true if database bulk copy operations
+	// should be rejected.
+	RejectDatabaseCopy() bool
+
+	// DenyFileTransfers returns true if file transfers (SFTP and scp)
+	// should be rejected on SSH nodes.
+	DenyFileTransfers() bool
+
+	// DenyDesktopClipboard returns true if clipboard sharing between the
+	// client and remote Windows desktops should be rejected.
+	DenyDesktopClipboard() bool
+
+	// DenyDesktopSmartcardAuth returns true if in-session certificate
+	// requests on remote Windows desktops should not be answered by the
+	// virtual smart card.
+	DenyDesktopSmartcardAuth() bool
+
+	// CreateHostUser returns true if the login user of a session should
+	// be created on the host automatically if it does not exist, and
+	// whether the created user should be deleted again on session end.
+	CreateHostUser() (create, cleanup bool)
+
+	// HostUserGroups returns the groups automatically created host users
+	// are added to.
+	HostUserGroups() []string
+
+	// HostUserSudoers returns the sudoers specifications installed for
+	// automatically created host users.
+	HostUserSudoers() []string
+
+	// MaxSessionDuration returns the wall-clock limit of interactive SSH
+	// sessions and how long before the limit a warning is written to the
+	// terminal, zero limit meaning sessions are not limited.
+	MaxSessionDuration() (limit, warning time.Duration)
+
+	// CommandFilters returns glob patterns for programs the user is
+	// allowed or denied to execute in SSH sessions.
+	CommandFilters() (allow, deny []string)
+
+	// PortForwardFilters returns glob patterns for host:port targets the
+	// user is allowed or denied to forward ports to.
+	PortForwardFilters() (allow, deny []string)
+
+	// MaxConcurrentSessions returns the maximum number of concurrent
+	// interactive sessions a user may have on a single node, zero meaning
+	// sessions are not limited.
+	MaxConcurrentSessions() int64
+
+	// MaxSessionBandwidth returns the maximum number of bytes per second
+	// of terminal data transferred in each direction of an interactive
+	// SSH session, zero meaning the bandwidth is not limited.
+	MaxSessionBandwidth() int64
+
 	// CheckImpersonate checks whether current user is allowed to impersonate
 	// users and roles
 	CheckImpersonate(currentUser, impersonateUser types.User, impersonateRoles []types.Role) error
@@ -919,6 +1042,26 @@ func MatchLabels(selector types.Labels, target map[string]string) (bool, string,
 	return true, "matched", nil
 }
 
+// matchRoleLabels matches the role's label selector and label expression
+// against the target labels. A role may constrain a resource kind with a
+// selector, an expression, or both; when both are set, both must match.
+func matchRoleLabels(selector types.Labels, expression string, target map[string]string) (bool, string, error) {
+	if expression == "" {
+		return MatchLabels(selector, target)
+	}
+	if len(selector) == 0 {
+		return MatchLabelsExpression(expression, target)
+	}
+	matchSelector, selectorMessage, err := MatchLabels(selector, target)
+	if err != nil {
+		return false, "", trace.Wrap(err)
+	}
+	if !matchSelector {
+		return false, selectorMessage, nil
+	}
+	return MatchLabelsExpression(expression, target)
+}
+
 // RoleNames returns a slice with role names. Removes runtime roles like
 // the default implicit role.
 func (set RoleSet) RoleNames() []string {
@@ -1098,6 +1241,283 @@ func (set RoleSet) CheckDatabaseNamesAndUsers(ttl time.Duration, overrideTTL boo
 	return utils.StringsSliceFromSet(names), utils.StringsSliceFromSet(users), nil
 }
 
+// MaskedDatabaseColumns returns a list of column name patterns whose values
+// should be masked in database query results returned to the user.
+//
+// The patterns are combined from the db_masked_columns option of all roles
+// in the set. Masking is additive: a column flagged by any role is masked.
+func (set RoleSet) MaskedDatabaseColumns() []string {
+	patterns := make(map[string]struct{})
+	for _, role := range set {
+		for _, pattern := range role.GetOptions().MaskedDatabaseColumns {
+			patterns[pattern] = struct{}{}
+		}
+	}
+	return utils.StringsSliceFromSet(patterns)
+}
+
+// RejectDatabaseMultiStatements returns true if database queries containing
+// multiple statements should be rejected.
+//
+// The restriction is enabled with the db_reject_multi_statements role
+// option and, like other restrictions, applies if any role in the set has
+// it.
+func (set RoleSet) RejectDatabaseMultiStatements() bool {
+	for _, role := range set {
+		if role.GetOptions().RejectDatabaseMultiStatements {
+			return true
+		}
+	}
+	return false
+}
+
+// DatabaseResultLimits returns the maximum number of rows and bytes a single
+// database query may return, zero meaning no limit.
+//
+// The limits come from the db_max_result_rows and db_max_result_bytes role
+// options and, similar to other limits such as MaxConnections, the lowest
+// value set by any role in the set wins.
+func (set RoleSet) DatabaseResultLimits() (rows, bytes int64) {
+	rows = set.minOptionValue(func(o types.RoleOptions) int64 { return o.DatabaseMaxResultRows })
+	bytes = set.minOptionValue(func(o types.RoleOptions) int64 { return o.DatabaseMaxResultBytes })
+	return rows, bytes
+}
+
+// RejectDatabaseCopy returns true if database bulk copy operations (such as
+// the Postgres COPY subprotocol) should be rejected.
+//
+// The restriction is enabled with the db_reject_copy role option and, like
+// other restrictions, applies if any role in the set has it.
+func (set RoleSet) RejectDatabaseCopy() bool {
+	for _, role := range set {
+		if role.GetOptions().RejectDatabaseCopy {
+			return true
+		}
+	}
+	return false
+}
+
+// DenyFileTransfers returns true if file transfers (SFTP and scp) should be
+// rejected on SSH nodes.
+//
+// The restriction is enabled with the deny_file_transfers role option and,
+// like other restrictions, applies if any role in the set has it.
+func (set RoleSet) DenyFileTransfers() bool {
+	for _, role := range set {
+		if role.GetOptions().DenyFileTransfers {
+			return true
+		}
+	}
+	return false
+}
+
+// DenyDesktopClipboard returns true if clipboard sharing between the client
+// and remote Windows desktops should be rejected.
+//
+// The restriction is enabled with the deny_desktop_clipboard role option
+// and, like other restrictions, applies if any role in the set has it.
+func (set RoleSet) DenyDesktopClipboard() bool {
+	for _, role := range set {
+		if role.GetOptions().DenyDesktopClipboard {
+			return true
+		}
+	}
+	return false
+}
+
+// DenyDesktopSmartcardAuth returns true if in-session certificate requests
+// on remote Windows desktops, such as UAC prompts or "runas" invocations,
+// should not be answered by the virtual smart card used for the RDP login.
+//
+// The restriction is enabled with the deny_desktop_smartcard_auth role
+// option and, like other restrictions, applies if any role in the set has
+// it.
+func (set RoleSet) DenyDesktopSmartcardAuth() bool {
+	for _, role := range set {
+		if role.GetOptions().DenyDesktopSmartcardAuth {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateHostUser returns true if the login user of a session should be
+// created on the host automatically if it does not exist, and whether the
+// created user should be deleted again on session end.
+//
+// Provisioning is enabled with the create_host_user role option, cleanup by
+// additionally setting cleanup_host_user, and both apply if any role in the
+// set has them.
+func (set RoleSet) CreateHostUser() (create, cleanup bool) {
+	for _, role := range set {
+		options := role.GetOptions()
+		if options.CreateHostUser {
+			create = true
+		}
+		if options.CleanupHostUser {
+			cleanup = true
+		}
+	}
+	return create, cleanup
+}
+
+// HostUserGroups returns the groups automatically created host users are
+// added to.
+//
+// The groups come from the host_groups allow condition, combined across all
+// roles in the set.
+func (set RoleSet) HostUserGroups() []string {
+	return set.conditionList(func(role types.Role) []string {
+		return role.GetHostGroups(types.Allow)
+	})
+}
+
+// HostUserSudoers returns the sudoers specifications installed for
+// automatically created host users.
+//
+// The entries come from the host_sudoers allow condition, combined across
+// all roles in the set. Each entry is prefixed with the login user name
+// when installed on the host.
+func (set RoleSet) HostUserSudoers() []string {
+	return set.conditionList(func(role types.Role) []string {
+		return role.GetHostSudoers(types.Allow)
+	})
+}
+
+// CommandFilters returns glob patterns for programs the user is allowed or
+// denied to execute in SSH sessions. Enforcement requires BPF enhanced
+// session recording.
+//
+// The patterns come from the commands allow and deny conditions and are
+// combined across all roles in the set.
+func (set RoleSet) CommandFilters() (allow, deny []string) {
+	return set.conditionList(func(role types.Role) []string {
+			return role.GetCommands(types.Allow)
+		}), set.conditionList(func(role types.Role) []string {
+			return role.GetCommands(types.Deny)
+		})
+}
+
+// PortForwardFilters returns glob patterns for host:port targets the user
+// is allowed or denied to forward ports to.
+//
+// The patterns come from the port_forwarding_destinations allow and deny
+// conditions and are combined across all roles in the set.
+func (set RoleSet) PortForwardFilters() (allow, deny []string) {
+	return set.conditionList(func(role types.Role) []string {
+			return role.GetPortForwardingDestinations(types.Allow)
+		}), set.conditionList(func(role types.Role) []string {
+			return role.GetPortForwardingDestinations(types.Deny)
+		})
+}
+
+// MaxConcurrentSessions returns the maximum number of concurrent
+// interactive sessions a user may have on a single node, zero meaning
+// sessions are not limited.
+//
+// The limit comes from the max_concurrent_sessions role option, the lowest
+// value set by any role in the set wins.
+func (set RoleSet) MaxConcurrentSessions() int64 {
+	return set.minOptionValue(func(o types.RoleOptions) int64 { return o.MaxConcurrentSessions })
+}
+
+// MaxSessionDuration returns the wall-clock limit of interactive SSH
+// sessions and how long before the limit a warning is written to the
+// terminal, zero limit meaning sessions are not limited.
+//
+// The values come from the max_session_duration and
+// session_duration_warning role options and for both the lowest value set
+// by any role in the set wins.
+func (set RoleSet) MaxSessionDuration() (limit, warning time.Duration) {
+	limit = set.minOptionDuration(func(o types.RoleOptions) time.Duration {
+		return o.MaxSessionDuration.Duration()
+	})
+	warning = set.minOptionDuration(func(o types.RoleOptions) time.Duration {
+		return o.SessionDurationWarning.Duration()
+	})
+	return limit, warning
+}
+
+// MaxSessionBandwidth returns the maximum number of bytes per second of
+// terminal data transferred in each direction of an interactive SSH
+// session, zero meaning the bandwidth is not limited.
+//
+// The limit comes from the max_session_bandwidth role option, the lowest
+// value set by any role in the set wins.
+func (set RoleSet) MaxSessionBandwidth() int64 {
+	return set.minOptionValue(func(o types.RoleOptions) int64 { return o.MaxSessionBandwidth })
+}
+
+// DatabaseQueryFilters returns regular expression patterns for database
+// queries the user is allowed or denied to execute.
+//
+// The patterns come from the db_queries allow and deny conditions and are
+// combined across all roles in the set.
+func (set RoleSet) DatabaseQueryFilters() (allow, deny []string) {
+	return set.conditionList(func(role types.Role) []string {
+			return role.GetDatabaseQueries(types.Allow)
+		}), set.conditionList(func(role types.Role) []string {
+			return role.GetDatabaseQueries(types.Deny)
+		})
+}
+
+// MFAVerificationInterval returns the maximum lifetime of a database
+// session started with a per-session MFA check, zero meaning unlimited.
+// The session is terminated after the interval and the user must
+// reconnect, passing a new MFA check.
+//
+// The interval comes from the mfa_verification_interval role option, the
+// lowest value set by any role in the set wins.
+func (set RoleSet) MFAVerificationInterval() time.Duration {
+	return set.minOptionDuration(func(o types.RoleOptions) time.Duration {
+		return o.MFAVerificationInterval.Duration()
+	})
+}
+
+// conditionList combines the condition values selected by the getter from
+// all roles in the set.
+func (set RoleSet) conditionList(values func(role types.Role) []string) []string {
+	combined := make(map[string]struct{})
+	for _, role := range set {
+		for _, value := range values(role) {
+			combined[value] = struct{}{}
+		}
+	}
+	return utils.StringsSliceFromSet(combined)
+}
+
+// minOptionValue returns the smallest positive value of the option selected
+// by the getter across all roles in the set, or zero if no role sets the
+// option.
+func (set RoleSet) minOptionValue(value func(options types.RoleOptions) int64) (min int64) {
+	for _, role := range set {
+		parsed := value(role.GetOptions())
+		if parsed <= 0 {
+			continue
+		}
+		if min == 0 || parsed < min {
+			min = parsed
+		}
+	}
+	return min
+}
+
+// minOptionDuration returns the smallest positive duration of the option
+// selected by the getter across all roles in the set, or zero if no role
+// sets the option.
+func (set RoleSet) minOptionDuration(value func(options types.RoleOptions) time.Duration) (min time.Duration) {
+	for _, role := range set {
+		parsed := value(role.GetOptions())
+		if parsed <= 0 {
+			continue
+		}
+		if min == 0 || parsed < min {
+			min = parsed
+		}
+	}
+	return min
+}
+
 // CheckAWSRoleARNs returns a list of AWS role ARNs this role set is allowed to assume.
 func (set RoleSet) CheckAWSRoleARNs(ttl time.Duration, overrideTTL bool) ([]string, error) {
 	arns := make(map[string]struct{})
@@ -1641,6 +2061,45 @@ func (l *windowsLoginMatcher) Match(role types.Role, typ types.RoleConditionType
 	return false, nil
 }
 
+type clientNetworkMatcher struct {
+	clientIP string
+}
+
+// NewClientNetworkMatcher creates a RoleMatcher that checks whether the
+// client source IP falls within the role's client network condition.
+func NewClientNetworkMatcher(clientIP string) RoleMatcher {
+	return &clientNetworkMatcher{clientIP: clientIP}
+}
+
+// Match matches the client source IP against the role's client networks.
+// Roles that do not constrain client networks match any client in the
+// allow condition and no client in the deny condition.
+func (m *clientNetworkMatcher) Match(role types.Role, typ types.RoleConditionType) (bool, error) {
+	networks := role.GetClientNetworks(typ)
+	if len(networks) == 0 {
+		return typ == types.Allow, nil
+	}
+	if m.clientIP == "" {
+		// Without a known client IP only unconstrained allow roles can
+		// match, and deny roles stay on the safe side by matching.
+		return typ == types.Deny, nil
+	}
+	ip := net.ParseIP(m.clientIP)
+	if ip == nil {
+		return false, trace.BadParameter("failed to parse client IP %q", m.clientIP)
+	}
+	for _, network := range networks {
+		_, cidr, err := net.ParseCIDR(network)
+		if err != nil {
+			return false, trace.BadParameter("failed to parse client network %q: %v", network, err)
+		}
+		if cidr.Contains(ip) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // AccessCheckable is the subset of types.Resource required for the RBAC checks.
 type AccessCheckable interface {
 	GetKind() string
@@ -1697,7 +2156,8 @@ func (set RoleSet) CheckAccess(r AccessCheckable, mfa AccessMFAParams, matchers
 			continue
 		}
 
-		matchLabels, labelsMessage, err := MatchLabels(getRoleLabels(role, types.Deny), allLabels)
+		matchLabels, labelsMessage, err := matchRoleLabels(getRoleLabels(role, types.Deny),
+			role.GetLabelsExpression(types.Deny, r.GetKind()), allLabels)
 		if err != nil {
 			return trace.Wrap(err)
 		}
@@ -1733,7 +2193,8 @@ func (set RoleSet) CheckAccess(r AccessCheckable, mfa AccessMFAParams, matchers
 			continue
 		}
 
-		matchLabels, labelsMessage, err := MatchLabels(getRoleLabels(role, types.Allow), allLabels)
+		matchLabels, labelsMessage, err := matchRoleLabels(getRoleLabels(role, types.Allow),
+			role.GetLabelsExpression(types.Allow, r.GetKind()), allLabels)
 		if err != nil {
 			return trace.Wrap(err)
 		}
@@ -580,6 +580,79 @@ func TestValidateRole(t *testing.T) {
 	}
 }
 
+func TestValidateRestrictionPatterns(t *testing.T) {
+	var tests = []struct {
+		name         string
+		spec         types.RoleSpecV4
+		err          error
+		matchMessage string
+	}{
+		{
+			name: "valid restriction patterns",
+			spec: types.RoleSpecV4{
+				Allow: types.RoleConditions{
+					DatabaseQueries: []string{"(?i)select.*"},
+					Commands:        []string{"/usr/bin/*"},
+				},
+				Deny: types.RoleConditions{
+					DatabaseQueries:            []string{"(?i)drop table.*"},
+					Commands:                   []string{"rm *", "curl *"},
+					PortForwardingDestinations: []string{"*.internal:3306"},
+				},
+			},
+		},
+		{
+			name: "invalid query pattern",
+			spec: types.RoleSpecV4{
+				Deny: types.RoleConditions{
+					DatabaseQueries: []string{"(unbalanced"},
+				},
+			},
+			err:          trace.BadParameter(""),
+			matchMessage: "db_queries",
+		},
+		{
+			name: "invalid command pattern",
+			spec: types.RoleSpecV4{
+				Deny: types.RoleConditions{
+					Commands: []string{"rm ["},
+				},
+			},
+			err:          trace.BadParameter(""),
+			matchMessage: "commands",
+		},
+		{
+			name: "invalid port forwarding pattern",
+			spec: types.RoleSpecV4{
+				Allow: types.RoleConditions{
+					PortForwardingDestinations: []string{"[:80"},
+				},
+			},
+			err:          trace.BadParameter(""),
+			matchMessage: "port_forwarding_destinations",
+		},
+	}
+
+	for _, tc := range tests {
+		err := ValidateRole(&types.RoleV4{
+			Metadata: types.Metadata{
+				Name:      "name1",
+				Namespace: apidefaults.Namespace,
+			},
+			Version: types.V3,
+			Spec:    tc.spec,
+		})
+		if tc.err != nil {
+			require.Error(t, err, tc.name)
+			if tc.matchMessage != "" {
+				require.Contains(t, err.Error(), tc.matchMessage)
+			}
+		} else {
+			require.NoError(t, err, tc.name)
+		}
+	}
+}
+
 func TestValidateRoleName(t *testing.T) {
 	var tests = []struct {
 		name         string
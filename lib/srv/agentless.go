@@ -0,0 +1,143 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package srv
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/teleport/lib/auth"
+	"github.com/gravitational/teleport/lib/services"
+
+	"github.com/gravitational/trace"
+)
+
+// OpenSSHNode describes a stock sshd host that is registered with the
+// cluster through certificates only. Sessions to such hosts are established
+// and recorded by the proxy, so RBAC node labels and auditing apply even
+// though the host does not run the Teleport binary.
+type OpenSSHNode struct {
+	// Hostname is the name the host is registered under.
+	Hostname string
+
+	// Addr is the host:port clients use to reach sshd on the host.
+	Addr string
+
+	// Labels are static labels applied to the node resource for RBAC.
+	Labels map[string]string
+
+	// Principals are additional principals placed on the host certificate
+	// next to the hostname and the address of the host.
+	Principals []string
+
+	// TTL is how long the registration and the host certificate stay
+	// valid, zero meaning they do not expire.
+	TTL time.Duration
+}
+
+// CheckAndSetDefaults checks the parameters of the host registration.
+func (n *OpenSSHNode) CheckAndSetDefaults() error {
+	if n.Hostname == "" {
+		return trace.BadParameter("missing parameter Hostname")
+	}
+	if n.Addr == "" {
+		return trace.BadParameter("missing parameter Addr")
+	}
+	return nil
+}
+
+// RegisterOpenSSHNode announces a stock sshd host as a node resource with
+// the openssh sub kind so it shows up in the inventory and node labels from
+// the user's roles are enforced when connecting to it.
+func RegisterOpenSSHNode(ctx context.Context, announcer auth.Announcer, node OpenSSHNode) (types.Server, error) {
+	if err := node.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	server, err := types.NewServerWithLabels(node.Hostname, types.KindNode, types.ServerSpecV2{
+		Addr:     node.Addr,
+		Hostname: node.Hostname,
+	}, node.Labels)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	server.SetSubKind(types.SubKindOpenSSHNode)
+	if node.TTL > 0 {
+		server.SetExpiry(time.Now().UTC().Add(node.TTL))
+	}
+	if _, err := announcer.UpsertNode(ctx, server); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return server, nil
+}
+
+// HostCertSigner signs host certificates with the cluster host CA.
+type HostCertSigner interface {
+	// GenerateHostCert signs the public key of a host with the host CA of
+	// the cluster.
+	GenerateHostCert(key []byte, hostID, nodeName string, principals []string, clusterName string, role types.SystemRole, ttl time.Duration) ([]byte, error)
+}
+
+// SignOpenSSHHostCert signs the public host key of a stock sshd host with
+// the cluster host CA. The certificate carries the hostname, the address,
+// and any additional principals of the host so clients validate the host
+// identity the same way they do for regular nodes.
+func SignOpenSSHHostCert(signer HostCertSigner, clusterName string, node OpenSSHNode, hostKey []byte) ([]byte, error) {
+	if err := node.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	principals := append([]string{node.Hostname}, node.Principals...)
+	if host, _, err := net.SplitHostPort(node.Addr); err == nil {
+		principals = append(principals, host)
+	}
+	cert, err := signer.GenerateHostCert(hostKey, node.Hostname, node.Hostname, principals, clusterName, types.RoleNode, node.TTL)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return cert, nil
+}
+
+// OpenSSHTrustedUserCAKeys formats the public keys of the cluster user CA
+// for the sshd TrustedUserCAKeys directive, one key per line in the
+// authorized keys format.
+func OpenSSHTrustedUserCAKeys(ca types.CertAuthority) ([]byte, error) {
+	keys := services.GetSSHCheckingKeys(ca)
+	if len(keys) == 0 {
+		return nil, trace.NotFound("certificate authority %q has no SSH public keys", ca.GetClusterName())
+	}
+	var buf bytes.Buffer
+	for _, key := range keys {
+		buf.Write(bytes.TrimRight(key, "\n"))
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// OpenSSHConfig returns an sshd configuration snippet that wires the
+// trusted user CA and the signed host certificate into a stock sshd. The
+// snippet is meant to be appended to sshd_config on the host.
+func OpenSSHConfig(trustedUserCAKeysPath, hostKeyPath, hostCertPath string) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# Added by Teleport: trust user certificates signed by the cluster.\n")
+	fmt.Fprintf(&buf, "TrustedUserCAKeys %v\n", trustedUserCAKeysPath)
+	fmt.Fprintf(&buf, "HostKey %v\n", hostKeyPath)
+	fmt.Fprintf(&buf, "HostCertificate %v\n", hostCertPath)
+	return buf.Bytes()
+}
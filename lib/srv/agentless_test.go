@@ -0,0 +1,76 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package srv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/trace"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeHostCertSigner records the host certificate request it receives.
+type fakeHostCertSigner struct {
+	principals  []string
+	clusterName string
+	role        types.SystemRole
+	ttl         time.Duration
+}
+
+func (f *fakeHostCertSigner) GenerateHostCert(key []byte, hostID, nodeName string, principals []string, clusterName string, role types.SystemRole, ttl time.Duration) ([]byte, error) {
+	f.principals = principals
+	f.clusterName = clusterName
+	f.role = role
+	f.ttl = ttl
+	return []byte("cert"), nil
+}
+
+// TestSignOpenSSHHostCert verifies the principals placed on host
+// certificates signed for stock sshd hosts.
+func TestSignOpenSSHHostCert(t *testing.T) {
+	signer := &fakeHostCertSigner{}
+	cert, err := SignOpenSSHHostCert(signer, "example.com", OpenSSHNode{
+		Hostname:   "db-1",
+		Addr:       "db-1.internal:22",
+		Principals: []string{"db-1.example.com"},
+		TTL:        time.Hour,
+	}, []byte("hostkey"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("cert"), cert)
+	require.Equal(t, []string{"db-1", "db-1.example.com", "db-1.internal"}, signer.principals)
+	require.Equal(t, "example.com", signer.clusterName)
+	require.Equal(t, types.RoleNode, signer.role)
+	require.Equal(t, time.Hour, signer.ttl)
+
+	// Registration parameters are validated before signing.
+	_, err = SignOpenSSHHostCert(signer, "example.com", OpenSSHNode{Addr: "db-1:22"}, []byte("hostkey"))
+	require.True(t, trace.IsBadParameter(err))
+	_, err = SignOpenSSHHostCert(signer, "example.com", OpenSSHNode{Hostname: "db-1"}, []byte("hostkey"))
+	require.True(t, trace.IsBadParameter(err))
+}
+
+// TestOpenSSHConfig verifies the sshd configuration snippet rendered for
+// stock sshd hosts.
+func TestOpenSSHConfig(t *testing.T) {
+	config := string(OpenSSHConfig("/etc/ssh/teleport_user_ca.pub", "/etc/ssh/ssh_host_ed25519_key", "/etc/ssh/ssh_host_ed25519_key-cert.pub"))
+	require.Contains(t, config, "TrustedUserCAKeys /etc/ssh/teleport_user_ca.pub\n")
+	require.Contains(t, config, "HostKey /etc/ssh/ssh_host_ed25519_key\n")
+	require.Contains(t, config, "HostCertificate /etc/ssh/ssh_host_ed25519_key-cert.pub\n")
+}
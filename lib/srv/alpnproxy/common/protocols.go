@@ -37,6 +37,9 @@ const (
 	// ProtocolReverseTunnel is TLS ALPN protocol value used to indicate Proxy reversetunnel protocol.
 	ProtocolReverseTunnel Protocol = "teleport-reversetunnel"
 
+	// ProtocolTCP is TLS ALPN protocol value used to indicate plain TCP application protocol.
+	ProtocolTCP Protocol = "teleport-tcp"
+
 	// ProtocolHTTP is TLS ALPN protocol value used to indicate HTTP2 protocol
 	ProtocolHTTP Protocol = "http/1.1"
 
@@ -58,6 +61,7 @@ var SupportedProtocols = []Protocol{
 	ProtocolMongoDB,
 	ProtocolProxySSH,
 	ProtocolReverseTunnel,
+	ProtocolTCP,
 	ProtocolHTTP,
 	ProtocolHTTP2,
 	ProtocolAuth,
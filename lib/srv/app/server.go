@@ -156,8 +156,9 @@ type Server struct {
 	closeContext context.Context
 	closeFunc    context.CancelFunc
 
-	httpServer *http.Server
-	tlsConfig  *tls.Config
+	httpServer     *http.Server
+	tlsConfig      *tls.Config
+	authMiddleware *auth.Middleware
 
 	mu            sync.RWMutex
 	heartbeats    map[string]*srv.Heartbeat
@@ -542,20 +543,50 @@ func (s *Server) ForceHeartbeat() error {
 	return nil
 }
 
-// HandleConnection takes a connection and wraps it in a listener so it can
-// be passed to http.Serve to process as a HTTP request.
+// HandleConnection takes a connection and, depending on the application
+// being requested, proxies it to the target address directly (TCP apps) or
+// wraps it in a listener so it can be passed to http.Serve to process as a
+// HTTP request.
 func (s *Server) HandleConnection(conn net.Conn) {
 	// Wrap conn in a CloserConn to detect when it is closed.
 	// Returning early will close conn before it has been serviced.
 	// httpServer will initiate the close call.
 	closerConn := utils.NewCloserConn(conn)
 
-	// Wrap a TLS authorizing conn in a single-use listener.
 	tlsConn := tls.Server(closerConn, s.tlsConfig)
+
+	// Trigger the TLS handshake and check which application the client
+	// certificate is routing to. Connections to TCP applications carry a
+	// raw byte stream and bypass the HTTP server below.
+	ctx, err := s.authMiddleware.WrapContextWithUser(s.closeContext, tlsConn)
+	if err != nil {
+		s.log.WithError(err).Warn("Failed to authenticate client connection.")
+		if err := tlsConn.Close(); err != nil && !utils.IsOKNetworkError(err) {
+			s.log.WithError(err).Warn("Failed to close client connection.")
+		}
+		return
+	}
+	identity, app, err := s.authorizeContext(ctx)
+	if err != nil {
+		s.log.WithError(err).Warn("Failed to authorize client connection.")
+		if err := tlsConn.Close(); err != nil && !utils.IsOKNetworkError(err) {
+			s.log.WithError(err).Warn("Failed to close client connection.")
+		}
+		return
+	}
+
+	if app.IsTCP() {
+		if err := s.handleTCPApp(ctx, tlsConn, identity, app); err != nil && !utils.IsOKNetworkError(err) {
+			s.log.WithError(err).Warn("Failed to handle TCP app connection.")
+		}
+		return
+	}
+
+	// Wrap a TLS authorizing conn in a single-use listener.
 	listener := newListener(s.closeContext, tlsConn)
 
 	// Serve will return as soon as tlsConn is running in its own goroutine
-	err := s.httpServer.Serve(listener)
+	err = s.httpServer.Serve(listener)
 	if err != nil && !errors.Is(err, errListenerConnServed) {
 		// okay to ignore errListenerConnServed; it is a signal that our
 		// single-use listener has passed the connection to http.Serve
@@ -582,7 +613,7 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) error {
 	// Extract the identity and application being requested from the certificate
 	// and check if the caller has access.
-	identity, app, err := s.authorize(r.Context(), r)
+	identity, app, err := s.authorizeContext(r.Context())
 	if err != nil {
 		return trace.Wrap(err)
 	}
@@ -626,11 +657,11 @@ func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) error {
 	return nil
 }
 
-// authorize will check if request carries a session cookie matching a
-// session in the backend.
-func (s *Server) authorize(ctx context.Context, r *http.Request) (*tlsca.Identity, types.Application, error) {
+// authorizeContext checks if the identity in the context is authorized to
+// access the application it is routing to.
+func (s *Server) authorizeContext(ctx context.Context) (*tlsca.Identity, types.Application, error) {
 	// Only allow local and remote identities to proxy to an application.
-	userType := r.Context().Value(auth.ContextUser)
+	userType := ctx.Value(auth.ContextUser)
 	switch userType.(type) {
 	case auth.LocalUser, auth.RemoteUser:
 	default:
@@ -638,14 +669,14 @@ func (s *Server) authorize(ctx context.Context, r *http.Request) (*tlsca.Identit
 	}
 
 	// Extract authorizing context and identity of the user from the request.
-	authContext, err := s.c.Authorizer.Authorize(r.Context())
+	authContext, err := s.c.Authorizer.Authorize(ctx)
 	if err != nil {
 		return nil, nil, trace.Wrap(err)
 	}
 	identity := authContext.Identity.GetIdentity()
 
 	// Fetch the application and check if the identity has access.
-	app, err := s.getApp(r.Context(), identity.RouteToApp.PublicAddr)
+	app, err := s.getApp(ctx, identity.RouteToApp.PublicAddr)
 	if err != nil {
 		return nil, nil, trace.Wrap(err)
 	}
@@ -727,14 +758,14 @@ func (s *Server) getApp(ctx context.Context, publicAddr string) (types.Applicati
 func (s *Server) newHTTPServer() *http.Server {
 	// Reuse the auth.Middleware to authorize requests but only accept
 	// certificates that were specifically generated for applications.
-	authMiddleware := &auth.Middleware{
+	s.authMiddleware = &auth.Middleware{
 		AccessPoint:   s.c.AccessPoint,
 		AcceptedUsage: []string{teleport.UsageAppsOnly},
 	}
-	authMiddleware.Wrap(s)
+	s.authMiddleware.Wrap(s)
 
 	return &http.Server{
-		Handler:           authMiddleware,
+		Handler:           s.authMiddleware,
 		ReadHeaderTimeout: apidefaults.DefaultDialTimeout,
 		ErrorLog:          utils.NewStdlogger(s.log.Error, teleport.ComponentApp),
 	}
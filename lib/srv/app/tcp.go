@@ -0,0 +1,91 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"net"
+	"net/url"
+
+	apidefaults "github.com/gravitational/teleport/api/defaults"
+	"github.com/gravitational/teleport/api/types"
+	apievents "github.com/gravitational/teleport/api/types/events"
+	"github.com/gravitational/teleport/lib/events"
+	"github.com/gravitational/teleport/lib/tlsca"
+	"github.com/gravitational/teleport/lib/utils"
+
+	"github.com/gravitational/trace"
+)
+
+// handleTCPApp proxies the connection to a TCP application. Unlike HTTP
+// applications where individual requests are audited, TCP streams have no
+// finer-grained unit, so an event is emitted for each connection.
+func (s *Server) handleTCPApp(ctx context.Context, conn net.Conn, identity *tlsca.Identity, app types.Application) error {
+	uri, err := url.Parse(app.GetURI())
+	if err != nil {
+		return trace.BadParameter("failed to parse app URI %q: %v", app.GetURI(), err)
+	}
+	if uri.Scheme != "tcp" {
+		return trace.BadParameter("unexpected app URI scheme %q, expected tcp", uri.Scheme)
+	}
+	if uri.Port() == "" {
+		return trace.BadParameter("app URI %q is missing port", app.GetURI())
+	}
+
+	var dialer net.Dialer
+	serverConn, err := dialer.DialContext(ctx, "tcp", uri.Host)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	// Emit the "connection established" event before the byte streams start
+	// flowing so the connection shows up in the audit log even if it never
+	// cleanly closes.
+	appSessionStartEvent := &apievents.AppSessionStart{
+		Metadata: apievents.Metadata{
+			Type:        events.AppSessionStartEvent,
+			Code:        events.AppSessionStartCode,
+			ClusterName: identity.RouteToApp.ClusterName,
+		},
+		ServerMetadata: apievents.ServerMetadata{
+			ServerID:        s.c.HostID,
+			ServerNamespace: apidefaults.Namespace,
+		},
+		SessionMetadata: apievents.SessionMetadata{
+			SessionID: identity.RouteToApp.SessionID,
+			WithMFA:   identity.MFAVerified,
+		},
+		UserMetadata: apievents.UserMetadata{
+			User:         identity.Username,
+			Impersonator: identity.Impersonator,
+		},
+		ConnectionMetadata: apievents.ConnectionMetadata{
+			RemoteAddr: conn.RemoteAddr().String(),
+		},
+		PublicAddr: identity.RouteToApp.PublicAddr,
+		AppMetadata: apievents.AppMetadata{
+			AppURI:        app.GetURI(),
+			AppPublicAddr: app.GetPublicAddr(),
+			AppName:       app.GetName(),
+		},
+	}
+	if err := s.c.AuthClient.EmitAuditEvent(s.closeContext, appSessionStartEvent); err != nil {
+		return trace.Wrap(err)
+	}
+
+	return trace.Wrap(utils.ProxyConn(ctx, conn, serverConn))
+}
@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
+	"path/filepath"
 	"time"
 
 	"golang.org/x/crypto/ssh"
@@ -77,6 +78,10 @@ type AuthHandlerConfig struct {
 	// configuration.
 	FIPS bool
 
+	// BreakGlass configures the emergency access mode for pre-authorized
+	// identities when the auth service is unreachable.
+	BreakGlass BreakGlassConfig
+
 	// Clock specifies the time provider. Will be used to override the time anchor
 	// for TLS certificate verification.
 	// Defaults to real clock if unspecified
@@ -135,7 +140,17 @@ func (h *AuthHandlers) CreateIdentityContext(sconn *ssh.ServerConn) (IdentityCon
 
 	roleSet, origRoles, err := h.fetchRoleSet(certificate, certAuthority, identity.TeleportUser, clusterName.GetClusterName())
 	if err != nil {
-		return IdentityContext{}, trace.Wrap(err)
+		// For connections allowed in break-glass mode the user's actual
+		// roles may not be fetchable, fall back to a minimal role set
+		// that only permits the certificate's principals.
+		if sconn.Permissions.Extensions[BreakGlassExtension] == "" || !trace.IsConnectionProblem(err) {
+			return IdentityContext{}, trace.Wrap(err)
+		}
+		roleSet, err = breakGlassRoleSet(certificate.ValidPrincipals)
+		if err != nil {
+			return IdentityContext{}, trace.Wrap(err)
+		}
+		origRoles = nil
 	}
 	identity.RoleSet, identity.UnmappedRoles = roleSet, origRoles
 	identity.Impersonator = certificate.Extensions[teleport.CertExtensionImpersonator]
@@ -159,42 +174,93 @@ func (h *AuthHandlers) CheckAgentForward(ctx *ServerContext) error {
 	return nil
 }
 
+// CheckX11Forward checks if X11 forwarding is allowed for the users RoleSet.
+func (h *AuthHandlers) CheckX11Forward(ctx *ServerContext) error {
+	if !ctx.Identity.RoleSet.PermitX11Forwarding() {
+		return trace.AccessDenied("x11 forwarding not permitted")
+	}
+
+	return nil
+}
+
 // CheckPortForward checks if port forwarding is allowed for the users RoleSet.
 func (h *AuthHandlers) CheckPortForward(addr string, ctx *ServerContext) error {
 	if ok := ctx.Identity.RoleSet.CanPortForward(); !ok {
 		systemErrorMessage := fmt.Sprintf("port forwarding not allowed by role set: %v", ctx.Identity.RoleSet)
-		userErrorMessage := "port forwarding not allowed"
+		h.emitPortForwardFailure(addr, systemErrorMessage, ctx)
+		return trace.AccessDenied("port forwarding not allowed")
+	}
 
-		// Emit port forward failure event
-		if err := h.c.Emitter.EmitAuditEvent(h.c.Server.Context(), &apievents.PortForward{
-			Metadata: apievents.Metadata{
-				Type: events.PortForwardEvent,
-				Code: events.PortForwardFailureCode,
-			},
-			UserMetadata: apievents.UserMetadata{
-				Login:        ctx.Identity.Login,
-				User:         ctx.Identity.TeleportUser,
-				Impersonator: ctx.Identity.Impersonator,
-			},
-			ConnectionMetadata: apievents.ConnectionMetadata{
-				LocalAddr:  ctx.ServerConn.LocalAddr().String(),
-				RemoteAddr: ctx.ServerConn.RemoteAddr().String(),
-			},
-			Addr: addr,
-			Status: apievents.Status{
-				Success: false,
-				Error:   systemErrorMessage,
-			},
-		}); err != nil {
-			h.log.WithError(err).Warn("Failed to emit port forward deny audit event.")
-		}
+	allow, deny := ctx.Identity.RoleSet.PortForwardFilters()
+	if portForwardTargetDenied(allow, deny, addr) {
+		systemErrorMessage := fmt.Sprintf("port forwarding to %v not allowed by role set: %v", addr, ctx.Identity.RoleSet)
+		h.emitPortForwardFailure(addr, systemErrorMessage, ctx)
+		return trace.AccessDenied("port forwarding to %v not allowed", addr)
+	}
+
+	return nil
+}
+
+// emitPortForwardFailure emits a failed port forward event and logs the
+// reason the request was denied.
+func (h *AuthHandlers) emitPortForwardFailure(addr, systemErrorMessage string, ctx *ServerContext) {
+	if err := h.c.Emitter.EmitAuditEvent(h.c.Server.Context(), &apievents.PortForward{
+		Metadata: apievents.Metadata{
+			Type: events.PortForwardEvent,
+			Code: events.PortForwardFailureCode,
+		},
+		UserMetadata: apievents.UserMetadata{
+			Login:        ctx.Identity.Login,
+			User:         ctx.Identity.TeleportUser,
+			Impersonator: ctx.Identity.Impersonator,
+		},
+		ConnectionMetadata: apievents.ConnectionMetadata{
+			LocalAddr:  ctx.ServerConn.LocalAddr().String(),
+			RemoteAddr: ctx.ServerConn.RemoteAddr().String(),
+		},
+		Addr: addr,
+		Status: apievents.Status{
+			Success: false,
+			Error:   systemErrorMessage,
+		},
+	}); err != nil {
+		h.log.WithError(err).Warn("Failed to emit port forward deny audit event.")
+	}
 
-		h.log.Warnf("Port forwarding request denied: %v.", systemErrorMessage)
+	h.log.Warnf("Port forwarding request denied: %v.", systemErrorMessage)
+}
 
-		return trace.AccessDenied(userErrorMessage)
+// portForwardTargetDenied returns true if the filter patterns deny port
+// forwarding to the given host:port target. The deny list is checked first
+// and wins over the allow list; a non-empty allow list denies every target
+// that does not match it. Patterns match against both the full host:port
+// and the host alone.
+func portForwardTargetDenied(allow, deny []string, addr string) bool {
+	if matchesPortForwardPattern(deny, addr) {
+		return true
 	}
+	if len(allow) > 0 && !matchesPortForwardPattern(allow, addr) {
+		return true
+	}
+	return false
+}
 
-	return nil
+// matchesPortForwardPattern returns true if the full host:port or the host
+// alone matches any of the glob patterns. Malformed patterns never match.
+func matchesPortForwardPattern(patterns []string, addr string) bool {
+	host := addr
+	if h, _, err := net.SplitHostPort(addr); err == nil {
+		host = h
+	}
+	for _, pattern := range patterns {
+		if ok, err := filepath.Match(pattern, addr); err == nil && ok {
+			return true
+		}
+		if ok, err := filepath.Match(pattern, host); err == nil && ok {
+			return true
+		}
+	}
+	return false
 }
 
 // UserKeyAuth implements SSH client authentication using public keys and is
@@ -308,6 +374,12 @@ func (h *AuthHandlers) UserKeyAuth(conn ssh.ConnMetadata, key ssh.PublicKey) (*s
 		err = h.canLoginWithRBAC(cert, clusterName.GetClusterName(), teleportUser, conn.User())
 	}
 	if err != nil {
+		if h.c.BreakGlass.Allows(key, err) {
+			log.Warnf("Auth service unreachable, allowing pinned identity %v in break-glass mode: %v.", teleportUser, err)
+			permissions.Extensions[BreakGlassExtension] = "true"
+			h.recordBreakGlassLogin(conn, teleportUser, err)
+			return permissions, nil
+		}
 		log.Errorf("Permission denied: %v", err)
 		recordFailedLogin(err)
 		return nil, trace.Wrap(err)
@@ -316,6 +388,32 @@ func (h *AuthHandlers) UserKeyAuth(conn ssh.ConnMetadata, key ssh.PublicKey) (*s
 	return permissions, nil
 }
 
+// recordBreakGlassLogin emits an audit event for a login allowed in
+// break-glass mode. The event is expected to be buffered locally since
+// the auth service is unreachable at this point.
+func (h *AuthHandlers) recordBreakGlassLogin(conn ssh.ConnMetadata, teleportUser string, loginErr error) {
+	if err := h.c.Emitter.EmitAuditEvent(h.c.Server.Context(), &apievents.AuthAttempt{
+		Metadata: apievents.Metadata{
+			Type: events.BreakGlassLoginEvent,
+			Code: events.BreakGlassLoginCode,
+		},
+		UserMetadata: apievents.UserMetadata{
+			Login: conn.User(),
+			User:  teleportUser,
+		},
+		ConnectionMetadata: apievents.ConnectionMetadata{
+			LocalAddr:  conn.LocalAddr().String(),
+			RemoteAddr: conn.RemoteAddr().String(),
+		},
+		Status: apievents.Status{
+			Success:     true,
+			UserMessage: fmt.Sprintf("auth service unreachable, break-glass login allowed: %v", loginErr),
+		},
+	}); err != nil {
+		h.log.WithError(err).Warn("Failed to emit break-glass login audit event.")
+	}
+}
+
 // HostKeyAuth implements host key verification and is called by the client
 // to validate the certificate presented by the target server. If the target
 // server presents a SSH certificate, we validate that it was Teleport that
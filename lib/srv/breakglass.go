@@ -0,0 +1,87 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package srv
+
+import (
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/teleport/lib/services"
+	"github.com/gravitational/teleport/lib/sshutils"
+
+	"github.com/gravitational/trace"
+)
+
+// BreakGlassExtension is the SSH permissions extension set on connections
+// allowed in break-glass mode.
+const BreakGlassExtension = "break-glass"
+
+// BreakGlassConfig configures the node-level emergency access mode that
+// lets pre-authorized identities log in while the auth service is
+// unreachable.
+//
+// Logins in this mode are still authenticated: the presented certificate
+// must be signed by the cluster's user CA (which requires the CA to be
+// available in the local cache) and valid, and in addition its public key
+// must match one of the pinned fingerprints. Only the RBAC checks that
+// require fetching fresh cluster state are skipped. Audit events emitted
+// during such sessions are expected to be buffered locally (see
+// events.BufferedEmitter) and session recordings are already spooled to
+// disk by the asynchronous uploader, so the audit trail is uploaded once
+// connectivity returns.
+type BreakGlassConfig struct {
+	// Enabled turns break-glass mode on.
+	Enabled bool
+	// AllowedFingerprints is the list of SSH public key fingerprints of
+	// certificates that may log in while the auth service is unreachable.
+	AllowedFingerprints []string
+}
+
+// Allows returns true if the provided public key is pinned and the error
+// that failed the login indicates the auth service is unreachable rather
+// than access being denied.
+func (c *BreakGlassConfig) Allows(key ssh.PublicKey, loginErr error) bool {
+	if !c.Enabled || !trace.IsConnectionProblem(loginErr) {
+		return false
+	}
+	fingerprint := sshutils.Fingerprint(key)
+	for _, allowed := range c.AllowedFingerprints {
+		if strings.EqualFold(allowed, fingerprint) {
+			return true
+		}
+	}
+	return false
+}
+
+// breakGlassRoleSet returns the minimal role set used for sessions
+// started in break-glass mode when the user's actual roles can't be
+// fetched. It allows logging in with the certificate's principals and
+// nothing else.
+func breakGlassRoleSet(principals []string) (services.RoleSet, error) {
+	role, err := types.NewRole(BreakGlassExtension, types.RoleSpecV4{
+		Allow: types.RoleConditions{
+			Logins:     principals,
+			NodeLabels: types.Labels{types.Wildcard: []string{types.Wildcard}},
+		},
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return services.NewRoleSet(role), nil
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package srv
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/gravitational/teleport/lib/auth/testauthority"
+	"github.com/gravitational/teleport/lib/sshutils"
+
+	"github.com/gravitational/trace"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBreakGlassAllows(t *testing.T) {
+	_, pub, err := testauthority.New().GenerateKeyPair("")
+	require.NoError(t, err)
+	key, _, _, _, err := ssh.ParseAuthorizedKey(pub)
+	require.NoError(t, err)
+	fingerprint := sshutils.Fingerprint(key)
+
+	authUnreachable := trace.ConnectionProblem(nil, "auth service is unreachable")
+	accessDenied := trace.AccessDenied("access denied")
+
+	tests := []struct {
+		desc   string
+		config BreakGlassConfig
+		err    error
+		allows bool
+	}{
+		{
+			desc:   "disabled",
+			config: BreakGlassConfig{AllowedFingerprints: []string{fingerprint}},
+			err:    authUnreachable,
+			allows: false,
+		},
+		{
+			desc:   "pinned key and auth unreachable",
+			config: BreakGlassConfig{Enabled: true, AllowedFingerprints: []string{fingerprint}},
+			err:    authUnreachable,
+			allows: true,
+		},
+		{
+			desc:   "fingerprint match is case-insensitive",
+			config: BreakGlassConfig{Enabled: true, AllowedFingerprints: []string{strings.ToLower(fingerprint)}},
+			err:    authUnreachable,
+			allows: true,
+		},
+		{
+			desc:   "access denied is not a connectivity failure",
+			config: BreakGlassConfig{Enabled: true, AllowedFingerprints: []string{fingerprint}},
+			err:    accessDenied,
+			allows: false,
+		},
+		{
+			desc:   "unpinned key",
+			config: BreakGlassConfig{Enabled: true, AllowedFingerprints: []string{"SHA256:aaaa"}},
+			err:    authUnreachable,
+			allows: false,
+		},
+		{
+			desc:   "no pinned keys",
+			config: BreakGlassConfig{Enabled: true},
+			err:    authUnreachable,
+			allows: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			require.Equal(t, tt.allows, tt.config.Allows(key, tt.err))
+		})
+	}
+}
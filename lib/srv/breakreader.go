@@ -0,0 +1,153 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package srv
+
+import (
+	"io"
+	"sync"
+)
+
+const (
+	// breakReaderMaxBuffer is the maximum amount of data buffered from the
+	// underlying reader while delivery is paused. Once the buffer is full
+	// the pump stops reading which applies backpressure to the source.
+	breakReaderMaxBuffer = 256 * 1024
+	// breakReaderChunkSize is the size of a single read from the
+	// underlying reader.
+	breakReaderChunkSize = 16 * 1024
+)
+
+// BreakReader is an io.ReadCloser that can temporarily pause delivery of
+// data from the underlying reader, used to pause terminal input of a live
+// session.
+//
+// A single pump goroutine keeps reading from the underlying reader into a
+// bounded internal buffer so data received while paused is delivered, in
+// order, once the reader is resumed. Reads are gated on a condition
+// variable: they block while the reader is paused or no data is buffered
+// and are unblocked by new data, Resume or Close.
+type BreakReader struct {
+	// reader is the underlying reader data is pumped from.
+	reader io.Reader
+	// cond guards the fields below and signals state changes to blocked
+	// Reads and the pump.
+	cond *sync.Cond
+	// buffer holds data read from the underlying reader that hasn't been
+	// delivered yet, capped at maxBuffer.
+	buffer []byte
+	// maxBuffer is the internal buffer size limit.
+	maxBuffer int
+	// paused indicates delivery is currently paused.
+	paused bool
+	// closed indicates the reader was closed.
+	closed bool
+	// err is the terminal error returned by the underlying reader, it is
+	// delivered to the caller after the buffer is drained.
+	err error
+}
+
+// NewBreakReader returns a new BreakReader wrapping the provided reader and
+// starts its pump goroutine.
+func NewBreakReader(reader io.Reader) *BreakReader {
+	r := &BreakReader{
+		reader:    reader,
+		cond:      sync.NewCond(&sync.Mutex{}),
+		maxBuffer: breakReaderMaxBuffer,
+	}
+	go r.pump()
+	return r
+}
+
+// pump keeps moving data from the underlying reader into the internal
+// buffer, blocking while the buffer is full to apply backpressure.
+func (r *BreakReader) pump() {
+	chunk := make([]byte, breakReaderChunkSize)
+	for {
+		n, err := r.reader.Read(chunk)
+		r.cond.L.Lock()
+		if n > 0 {
+			for len(r.buffer)+n > r.maxBuffer && !r.closed {
+				r.cond.Wait()
+			}
+			r.buffer = append(r.buffer, chunk[:n]...)
+		}
+		if err != nil {
+			r.err = err
+			r.cond.Broadcast()
+			r.cond.L.Unlock()
+			return
+		}
+		if r.closed {
+			r.cond.L.Unlock()
+			return
+		}
+		r.cond.Broadcast()
+		r.cond.L.Unlock()
+	}
+}
+
+// Read returns buffered data, blocking while the reader is paused or until
+// data becomes available, the underlying reader fails or the reader is
+// closed.
+func (r *BreakReader) Read(p []byte) (int, error) {
+	r.cond.L.Lock()
+	defer r.cond.L.Unlock()
+	for !r.closed && (r.paused || (len(r.buffer) == 0 && r.err == nil)) {
+		r.cond.Wait()
+	}
+	if r.closed {
+		return 0, io.EOF
+	}
+	if len(r.buffer) > 0 {
+		n := copy(p, r.buffer)
+		r.buffer = r.buffer[n:]
+		// Wake the pump in case it is blocked on a full buffer.
+		r.cond.Broadcast()
+		return n, nil
+	}
+	return 0, r.err
+}
+
+// Pause pauses delivery of data to Read callers. Data received while
+// paused is buffered and delivered after Resume.
+func (r *BreakReader) Pause() {
+	r.cond.L.Lock()
+	defer r.cond.L.Unlock()
+	r.paused = true
+}
+
+// Resume resumes delivery of data to Read callers including any data
+// buffered while the reader was paused.
+func (r *BreakReader) Resume() {
+	r.cond.L.Lock()
+	defer r.cond.L.Unlock()
+	r.paused = false
+	r.cond.Broadcast()
+}
+
+// Close unblocks all pending and future Reads which return io.EOF and
+// releases the pump goroutine.
+//
+// The underlying reader is not closed so the caller remains responsible
+// for its lifetime.
+func (r *BreakReader) Close() error {
+	r.cond.L.Lock()
+	defer r.cond.L.Unlock()
+	r.closed = true
+	r.cond.Broadcast()
+	return nil
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package srv
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestBreakReaderPassthrough verifies data flows through an unpaused reader.
+func TestBreakReaderPassthrough(t *testing.T) {
+	pr, pw := io.Pipe()
+	reader := NewBreakReader(pr)
+	defer reader.Close()
+
+	go pw.Write([]byte("hello"))
+
+	buffer := make([]byte, 64)
+	n, err := reader.Read(buffer)
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(buffer[:n]))
+}
+
+// TestBreakReaderPause verifies data received while paused is buffered and
+// delivered in order after the reader is resumed.
+func TestBreakReaderPause(t *testing.T) {
+	pr, pw := io.Pipe()
+	reader := NewBreakReader(pr)
+	defer reader.Close()
+
+	reader.Pause()
+
+	_, err := pw.Write([]byte("hello, "))
+	require.NoError(t, err)
+	_, err = pw.Write([]byte("world"))
+	require.NoError(t, err)
+
+	// Read should be blocked while the reader is paused.
+	expected := "hello, world"
+	readCh := make(chan string, 1)
+	go func() {
+		buffer := make([]byte, 64)
+		total := 0
+		for total < len(expected) {
+			n, err := reader.Read(buffer[total:])
+			if err != nil {
+				readCh <- err.Error()
+				return
+			}
+			total += n
+		}
+		readCh <- string(buffer[:total])
+	}()
+	select {
+	case read := <-readCh:
+		t.Fatalf("Read returned %q while reader was paused.", read)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// After resuming, the data buffered while paused should be delivered.
+	reader.Resume()
+	select {
+	case read := <-readCh:
+		require.Equal(t, expected, read)
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for read after resume.")
+	}
+}
+
+// TestBreakReaderClose verifies Close unblocks a pending Read.
+func TestBreakReaderClose(t *testing.T) {
+	pr, _ := io.Pipe()
+	reader := NewBreakReader(pr)
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := reader.Read(make([]byte, 64))
+		errCh <- err
+	}()
+
+	require.NoError(t, reader.Close())
+	select {
+	case err := <-errCh:
+		require.ErrorIs(t, err, io.EOF)
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for read to unblock after close.")
+	}
+}
+
+// TestBreakReaderError verifies the underlying reader's error is returned
+// after the buffered data is drained.
+func TestBreakReaderError(t *testing.T) {
+	pr, pw := io.Pipe()
+	reader := NewBreakReader(pr)
+	defer reader.Close()
+
+	_, err := pw.Write([]byte("hello"))
+	require.NoError(t, err)
+	require.NoError(t, pw.Close())
+
+	buffer := make([]byte, 64)
+	n, err := reader.Read(buffer)
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(buffer[:n]))
+
+	_, err = reader.Read(buffer)
+	require.ErrorIs(t, err, io.EOF)
+}
@@ -174,6 +174,10 @@ const (
 	cloudSQLAuthToken = "cloudsql-auth-token"
 	// cloudSQLPassword is a mock Cloud SQL user password.
 	cloudSQLPassword = "cloudsql-password"
+	// alloyDBAuthToken is a mock AlloyDB IAM auth token.
+	alloyDBAuthToken = "alloydb-auth-token"
+	// elastiCacheRedisToken is a mock ElastiCache Redis IAM auth token.
+	elastiCacheRedisToken = "elasticache-redis-token"
 	// azureAccessToken is a mock Azure access token.
 	azureAccessToken = "azure-access-token"
 )
@@ -202,6 +206,18 @@ func (a *testAuth) GetCloudSQLPassword(ctx context.Context, sessionCtx *common.S
 	return cloudSQLPassword, nil
 }
 
+// GetAlloyDBAuthToken generates AlloyDB auth token.
+func (a *testAuth) GetAlloyDBAuthToken(ctx context.Context, sessionCtx *common.Session) (string, error) {
+	a.Infof("Generating AlloyDB auth token for %v.", sessionCtx)
+	return alloyDBAuthToken, nil
+}
+
+// GetElastiCacheRedisToken generates ElastiCache/MemoryDB Redis IAM auth token.
+func (a *testAuth) GetElastiCacheRedisToken(ctx context.Context, sessionCtx *common.Session) (string, error) {
+	a.Infof("Generating ElastiCache Redis auth token for %v.", sessionCtx)
+	return elastiCacheRedisToken, nil
+}
+
 // GetAzureAccessToken generates Azure access token.
 func (a *testAuth) GetAzureAccessToken(ctx context.Context, sessionCtx *common.Session) (string, error) {
 	a.Infof("Generating Azure access token for %v.", sessionCtx)
@@ -0,0 +1,395 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cassandra implements the Cassandra and ScyllaDB database
+// access engine.
+//
+// The engine speaks the CQL binary protocol with the client, audits
+// queries including executions of prepared statements, enforces
+// role-based keyspace restrictions per-query and relays frames to the
+// database over mutual TLS.
+package cassandra
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"regexp"
+	"sync"
+
+	"github.com/gravitational/teleport/lib/defaults"
+	"github.com/gravitational/teleport/lib/services"
+	"github.com/gravitational/teleport/lib/srv/db/cassandra/protocol"
+	"github.com/gravitational/teleport/lib/srv/db/common"
+	"github.com/gravitational/teleport/lib/srv/db/common/role"
+	"github.com/gravitational/teleport/lib/utils"
+
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+	"github.com/sirupsen/logrus"
+)
+
+// Engine implements the Cassandra database service that accepts client
+// connections coming over reverse tunnel from the proxy and proxies them
+// between the proxy and the Cassandra database instance.
+//
+// Implements common.Engine.
+type Engine struct {
+	// Auth handles database access authentication.
+	Auth common.Auth
+	// Audit emits database access audit events.
+	Audit common.Audit
+	// Context is the database server close context.
+	Context context.Context
+	// Clock is the clock interface.
+	Clock clockwork.Clock
+	// Log is used for logging.
+	Log logrus.FieldLogger
+	// clientConn is an incoming client connection.
+	clientConn net.Conn
+	// mfaParams are the session MFA parameters used in per-query access
+	// checks.
+	mfaParams services.AccessMFAParams
+	// queryFilter blocks queries based on the user's role-based allow and
+	// deny patterns. It is nil when none of the user's roles set any.
+	queryFilter *common.QueryFilter
+	// mu protects the prepared statement maps below.
+	mu sync.Mutex
+	// pendingPrepares maps stream ids of in-flight PREPARE requests to
+	// their query text.
+	pendingPrepares map[uint16]string
+	// preparedStatements maps prepared statement ids to their query text
+	// for auditing EXECUTE messages.
+	preparedStatements map[string]string
+}
+
+// InitializeConnection initializes the client connection.
+func (e *Engine) InitializeConnection(clientConn net.Conn, _ *common.Session) error {
+	e.clientConn = clientConn
+	e.pendingPrepares = make(map[uint16]string)
+	e.preparedStatements = make(map[string]string)
+	return nil
+}
+
+// SendError sends an error to the connected client in a CQL error frame.
+func (e *Engine) SendError(err error) {
+	if err == nil || utils.IsOKNetworkError(err) {
+		return
+	}
+	// Protocol version 4, stream 0 since the error is not tied to a
+	// particular client request.
+	if errSend := protocol.WriteError(e.clientConn, 0x04, 0, err); errSend != nil {
+		e.Log.WithError(errSend).Debugf("Failed to send error %q to Cassandra client.", err)
+	}
+}
+
+// HandleConnection processes the connection from Cassandra proxy coming
+// over reverse tunnel.
+//
+// It performs authorization checks, connects to the database over mutual
+// TLS and relays CQL frames between the client and the database auditing
+// queries along the way.
+func (e *Engine) HandleConnection(ctx context.Context, sessionCtx *common.Session) error {
+	err := e.checkAccess(ctx, sessionCtx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	// Setup query filtering if any of the user's roles set query allow
+	// or deny patterns.
+	e.queryFilter, err = common.NewQueryFilter(sessionCtx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	serverConn, err := e.connect(ctx, sessionCtx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer serverConn.Close()
+	e.Audit.OnSessionStart(e.Context, sessionCtx, nil)
+	defer e.Audit.OnSessionEnd(e.Context, sessionCtx)
+	// Copy between the connections.
+	clientErrCh := make(chan error, 1)
+	serverErrCh := make(chan error, 1)
+	go e.receiveFromClient(e.clientConn, serverConn, clientErrCh, sessionCtx)
+	go e.receiveFromServer(serverConn, e.clientConn, serverErrCh)
+	select {
+	case err := <-clientErrCh:
+		e.Log.WithError(err).Debug("Client done.")
+	case err := <-serverErrCh:
+		e.Log.WithError(err).Debug("Server done.")
+	case <-ctx.Done():
+		e.Log.Debug("Context canceled.")
+	}
+	return nil
+}
+
+// connect establishes a mutual TLS connection to the Cassandra database.
+func (e *Engine) connect(ctx context.Context, sessionCtx *common.Session) (net.Conn, error) {
+	tlsConfig, err := e.Auth.GetTLSConfig(ctx, sessionCtx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: defaults.DatabaseConnectTimeout},
+		Config:    tlsConfig,
+	}
+	serverConn, err := dialer.DialContext(ctx, "tcp", sessionCtx.Database.GetURI())
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	return serverConn, nil
+}
+
+// checkAccess does authorization check for Cassandra connection about to
+// be established.
+func (e *Engine) checkAccess(ctx context.Context, sessionCtx *common.Session) error {
+	ap, err := e.Auth.GetAuthPreference(ctx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	e.mfaParams = services.AccessMFAParams{
+		Verified:       sessionCtx.Identity.MFAVerified != "",
+		AlwaysRequired: ap.GetRequireSessionMFA(),
+	}
+	dbRoleMatchers := role.DatabaseRoleMatchers(
+		defaults.ProtocolCassandra,
+		sessionCtx.DatabaseUser,
+		sessionCtx.DatabaseName,
+		sessionCtx.Identity.ClientIP,
+	)
+	err = sessionCtx.Checker.CheckAccess(
+		sessionCtx.Database,
+		e.mfaParams,
+		dbRoleMatchers...,
+	)
+	if err != nil {
+		e.Audit.OnSessionStart(e.Context, sessionCtx, err)
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// checkKeyspaceAccess verifies the session is allowed to access the
+// keyspaces referenced by the query, matching them against the roles'
+// allowed database names.
+func (e *Engine) checkKeyspaceAccess(sessionCtx *common.Session, query string) error {
+	for _, keyspace := range queryKeyspaces(query) {
+		err := sessionCtx.Checker.CheckAccess(
+			sessionCtx.Database,
+			e.mfaParams,
+			&services.DatabaseUserMatcher{User: sessionCtx.DatabaseUser},
+			&services.DatabaseNameMatcher{Name: keyspace})
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// receiveFromClient relays CQL frames received from the client to the
+// database, auditing queries and enforcing keyspace restrictions along
+// the way.
+func (e *Engine) receiveFromClient(clientConn, serverConn net.Conn, clientErrCh chan<- error, sessionCtx *common.Session) {
+	log := e.Log.WithFields(logrus.Fields{
+		"from":   "client",
+		"client": clientConn.RemoteAddr(),
+		"server": serverConn.RemoteAddr(),
+	})
+	defer func() {
+		log.Debug("Stop receiving from client.")
+		close(clientErrCh)
+	}()
+	for {
+		frame, err := protocol.ReadFrame(clientConn)
+		if err != nil {
+			if utils.IsOKNetworkError(err) {
+				log.Debug("Client connection closed.")
+				return
+			}
+			log.WithError(err).Error("Failed to read client frame.")
+			clientErrCh <- err
+			return
+		}
+		frame, err = e.processClientFrame(frame, sessionCtx)
+		if err != nil {
+			log.WithError(err).Error("Failed to process client frame.")
+			clientErrCh <- err
+			return
+		}
+		if frame == nil {
+			// The frame was rejected and the error sent to the client.
+			continue
+		}
+		err = protocol.WriteFrame(serverConn, frame)
+		if err != nil {
+			log.WithError(err).Error("Failed to write server frame.")
+			clientErrCh <- err
+			return
+		}
+	}
+}
+
+// processClientFrame audits and authorizes a client frame, returning the
+// frame to forward to the server or nil if the frame was rejected and an
+// error frame sent to the client.
+func (e *Engine) processClientFrame(frame *protocol.Frame, sessionCtx *common.Session) (*protocol.Frame, error) {
+	if frame.Compressed() {
+		return frame, nil
+	}
+	switch frame.OpCode {
+	case protocol.OpStartup:
+		// Strip compression negotiation so frames stay parseable.
+		body, err := protocol.StripCompression(frame.Body)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		frame.Body = body
+		return frame, nil
+	case protocol.OpQuery:
+		query, err := protocol.ParseQuery(frame.Body)
+		if err != nil {
+			e.Log.WithError(err).Warn("Failed to parse query message.")
+			return frame, nil
+		}
+		return e.authorizeQuery(frame, sessionCtx, query)
+	case protocol.OpPrepare:
+		query, err := protocol.ParsePrepare(frame.Body)
+		if err != nil {
+			e.Log.WithError(err).Warn("Failed to parse prepare message.")
+			return frame, nil
+		}
+		frame, err = e.authorizeQuery(frame, sessionCtx, query)
+		if frame != nil {
+			// Remember the query so the prepared statement id from the
+			// server's result can be mapped back to it.
+			e.mu.Lock()
+			e.pendingPrepares[frame.Stream] = query
+			e.mu.Unlock()
+		}
+		return frame, trace.Wrap(err)
+	case protocol.OpExecute:
+		id, err := protocol.ParseExecute(frame.Body)
+		if err != nil {
+			e.Log.WithError(err).Warn("Failed to parse execute message.")
+			return frame, nil
+		}
+		e.mu.Lock()
+		query, ok := e.preparedStatements[id]
+		e.mu.Unlock()
+		if !ok {
+			// Prepared on another connection, the id can't be resolved.
+			e.Audit.OnQuery(e.Context, sessionCtx, common.Query{Query: "execute unknown prepared statement"})
+			return frame, nil
+		}
+		return e.authorizeQuery(frame, sessionCtx, query)
+	case protocol.OpBatch:
+		queries, err := protocol.ParseBatch(frame.Body)
+		if err != nil {
+			e.Log.WithError(err).Warn("Failed to parse batch message.")
+			return frame, nil
+		}
+		for _, batchQuery := range queries {
+			query := batchQuery.Query
+			if query == "" {
+				e.mu.Lock()
+				query = e.preparedStatements[batchQuery.PreparedID]
+				e.mu.Unlock()
+				if query == "" {
+					continue
+				}
+			}
+			frame, err = e.authorizeQuery(frame, sessionCtx, query)
+			if frame == nil || err != nil {
+				return frame, trace.Wrap(err)
+			}
+		}
+		return frame, nil
+	}
+	return frame, nil
+}
+
+// authorizeQuery audits the query and checks keyspace access and query
+// filters, sending an error frame to the client and returning nil if the
+// query is denied.
+func (e *Engine) authorizeQuery(frame *protocol.Frame, sessionCtx *common.Session, query string) (*protocol.Frame, error) {
+	err := e.checkKeyspaceAccess(sessionCtx, query)
+	if err == nil && e.queryFilter != nil {
+		err = e.queryFilter.CheckQuery(query)
+	}
+	e.Audit.OnQuery(e.Context, sessionCtx, common.Query{Query: query, Error: err})
+	if err != nil {
+		if errSend := protocol.WriteError(e.clientConn, frame.Version, frame.Stream, err); errSend != nil {
+			return nil, trace.Wrap(errSend)
+		}
+		return nil, nil
+	}
+	return frame, nil
+}
+
+// receiveFromServer relays CQL frames received from the database to the
+// client, tracking prepared statement results along the way.
+func (e *Engine) receiveFromServer(serverConn, clientConn net.Conn, serverErrCh chan<- error) {
+	log := e.Log.WithFields(logrus.Fields{
+		"from":   "server",
+		"client": clientConn.RemoteAddr(),
+		"server": serverConn.RemoteAddr(),
+	})
+	defer func() {
+		log.Debug("Stop receiving from server.")
+		close(serverErrCh)
+	}()
+	for {
+		frame, err := protocol.ReadFrame(serverConn)
+		if err != nil {
+			if !utils.IsOKNetworkError(err) && err != io.EOF {
+				serverErrCh <- err
+			}
+			return
+		}
+		if frame.OpCode == protocol.OpResult && !frame.Compressed() {
+			e.mu.Lock()
+			if query, ok := e.pendingPrepares[frame.Stream]; ok {
+				if id, prepared := protocol.ParsePreparedResult(frame.Body); prepared {
+					e.preparedStatements[id] = query
+				}
+				delete(e.pendingPrepares, frame.Stream)
+			}
+			e.mu.Unlock()
+		}
+		if err := protocol.WriteFrame(clientConn, frame); err != nil {
+			serverErrCh <- err
+			return
+		}
+	}
+}
+
+// keyspaceRegexps match keyspace references in CQL statements: USE
+// statements and qualified "keyspace.table" names.
+var keyspaceRegexps = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\buse\s+"?([a-zA-Z0-9_]+)"?`),
+	regexp.MustCompile(`(?i)\b(?:from|into|update|table|materialized\s+view|keyspace)\s+"?([a-zA-Z0-9_]+)"?\s*\.`),
+}
+
+// queryKeyspaces extracts the keyspaces referenced by the query.
+func queryKeyspaces(query string) (keyspaces []string) {
+	for _, re := range keyspaceRegexps {
+		for _, match := range re.FindAllStringSubmatch(query, -1) {
+			keyspaces = append(keyspaces, match[1])
+		}
+	}
+	return keyspaces
+}
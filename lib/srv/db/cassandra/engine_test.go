@@ -0,0 +1,53 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cassandra
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestQueryKeyspaces verifies keyspace extraction from CQL statements.
+func TestQueryKeyspaces(t *testing.T) {
+	tests := []struct {
+		query     string
+		keyspaces []string
+	}{
+		{
+			query:     "USE analytics",
+			keyspaces: []string{"analytics"},
+		},
+		{
+			query:     `select * from "Analytics".events where id = 1`,
+			keyspaces: []string{"Analytics"},
+		},
+		{
+			query:     "INSERT INTO metrics.counters (name, value) VALUES ('x', 1)",
+			keyspaces: []string{"metrics"},
+		},
+		{
+			query:     "select * from events",
+			keyspaces: nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			require.Equal(t, test.keyspaces, queryKeyspaces(test.query))
+		})
+	}
+}
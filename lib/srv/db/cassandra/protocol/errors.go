@@ -0,0 +1,52 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protocol
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/gravitational/trace"
+)
+
+const (
+	// errorCodeServerError is the generic server error code.
+	errorCodeServerError = 0x0000
+	// errorCodeUnauthorized is the unauthorized error code.
+	errorCodeUnauthorized = 0x2100
+)
+
+// WriteError sends a CQL error frame to the client on the specified
+// stream.
+//
+// The version should be the protocol version from the client's request
+// frame, the response direction bit is set automatically.
+func WriteError(w io.Writer, version byte, stream uint16, err error) error {
+	code := uint32(errorCodeServerError)
+	if trace.IsAccessDenied(err) {
+		code = errorCodeUnauthorized
+	}
+	body := binary.BigEndian.AppendUint32(nil, code)
+	body = appendString(body, err.Error())
+	return trace.Wrap(WriteFrame(w, &Frame{
+		// Set the response direction bit.
+		Version: version | 0x80,
+		Stream:  stream,
+		OpCode:  OpError,
+		Body:    body,
+	}))
+}
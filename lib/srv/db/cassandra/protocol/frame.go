@@ -0,0 +1,150 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package protocol implements parts of the CQL binary protocol the
+// Teleport Cassandra database engine needs: frame framing, query and
+// prepared statement message parsing and error frames.
+//
+// https://github.com/apache/cassandra/blob/trunk/doc/native_protocol_v4.spec
+package protocol
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/gravitational/trace"
+)
+
+const (
+	// frameHeaderSize is the size of the CQL frame header.
+	frameHeaderSize = 9
+	// maxFrameBodySize is the maximum accepted frame body size, the
+	// protocol limit is 256MB.
+	maxFrameBodySize = 256 * 1024 * 1024
+)
+
+// CQL frame opcodes.
+const (
+	// OpError is the server error response.
+	OpError = 0x00
+	// OpStartup is the client connection initialization message.
+	OpStartup = 0x01
+	// OpReady is the server response indicating the connection is ready.
+	OpReady = 0x02
+	// OpQuery is a CQL query message.
+	OpQuery = 0x07
+	// OpResult is the server result message.
+	OpResult = 0x08
+	// OpPrepare is a prepare statement message.
+	OpPrepare = 0x09
+	// OpExecute is a prepared statement execution message.
+	OpExecute = 0x0A
+	// OpBatch is a batch of queries message.
+	OpBatch = 0x0D
+)
+
+// Frame represents a single CQL protocol frame.
+type Frame struct {
+	// Version is the protocol version byte including the direction bit.
+	Version byte
+	// Flags is the frame flags byte.
+	Flags byte
+	// Stream is the stream id correlating requests with responses.
+	Stream uint16
+	// OpCode is the frame opcode.
+	OpCode byte
+	// Body is the frame body.
+	Body []byte
+}
+
+// ReadFrame reads a single CQL frame from the reader.
+func ReadFrame(r io.Reader) (*Frame, error) {
+	header := make([]byte, frameHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	length := binary.BigEndian.Uint32(header[5:])
+	if length > maxFrameBodySize {
+		return nil, trace.BadParameter("CQL frame body length %v exceeds maximum", length)
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	return &Frame{
+		Version: header[0],
+		Flags:   header[1],
+		Stream:  binary.BigEndian.Uint16(header[2:]),
+		OpCode:  header[4],
+		Body:    body,
+	}, nil
+}
+
+// WriteFrame writes a single CQL frame to the writer.
+func WriteFrame(w io.Writer, frame *Frame) error {
+	header := make([]byte, frameHeaderSize)
+	header[0] = frame.Version
+	header[1] = frame.Flags
+	binary.BigEndian.PutUint16(header[2:], frame.Stream)
+	header[4] = frame.OpCode
+	binary.BigEndian.PutUint32(header[5:], uint32(len(frame.Body)))
+	if _, err := w.Write(append(header, frame.Body...)); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	return nil
+}
+
+// Compressed returns true if the frame body is compressed, in which case
+// it can't be parsed.
+func (f *Frame) Compressed() bool {
+	return f.Flags&0x01 != 0
+}
+
+// readString reads a 2-byte length prefixed string.
+func readString(data []byte) (string, []byte, error) {
+	if len(data) < 2 {
+		return "", nil, trace.BadParameter("truncated CQL string")
+	}
+	length := int(binary.BigEndian.Uint16(data))
+	if len(data) < 2+length {
+		return "", nil, trace.BadParameter("truncated CQL string")
+	}
+	return string(data[2 : 2+length]), data[2+length:], nil
+}
+
+// readLongString reads a 4-byte length prefixed string.
+func readLongString(data []byte) (string, []byte, error) {
+	if len(data) < 4 {
+		return "", nil, trace.BadParameter("truncated CQL long string")
+	}
+	length := int(binary.BigEndian.Uint32(data))
+	if length < 0 || len(data) < 4+length {
+		return "", nil, trace.BadParameter("truncated CQL long string")
+	}
+	return string(data[4 : 4+length]), data[4+length:], nil
+}
+
+// readShortBytes reads a 2-byte length prefixed byte sequence.
+func readShortBytes(data []byte) ([]byte, []byte, error) {
+	if len(data) < 2 {
+		return nil, nil, trace.BadParameter("truncated CQL short bytes")
+	}
+	length := int(binary.BigEndian.Uint16(data))
+	if len(data) < 2+length {
+		return nil, nil, trace.BadParameter("truncated CQL short bytes")
+	}
+	return data[2 : 2+length], data[2+length:], nil
+}
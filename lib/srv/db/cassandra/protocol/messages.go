@@ -0,0 +1,168 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protocol
+
+import (
+	"encoding/binary"
+	"strings"
+
+	"github.com/gravitational/trace"
+)
+
+// resultKindPrepared is the RESULT message kind for prepared statement
+// results.
+const resultKindPrepared = 0x0004
+
+// batchQueryKindString is the batch query kind indicating the query is
+// provided as a string rather than a prepared statement id.
+const batchQueryKindString = 0
+
+// ParseQuery extracts the CQL query string from a QUERY message body.
+func ParseQuery(body []byte) (string, error) {
+	query, _, err := readLongString(body)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	return query, nil
+}
+
+// ParsePrepare extracts the CQL query string from a PREPARE message body.
+func ParsePrepare(body []byte) (string, error) {
+	query, _, err := readLongString(body)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	return query, nil
+}
+
+// ParseExecute extracts the prepared statement id from an EXECUTE message
+// body.
+func ParseExecute(body []byte) (string, error) {
+	id, _, err := readShortBytes(body)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	return string(id), nil
+}
+
+// BatchQuery is a single query within a BATCH message, either a query
+// string or a prepared statement id.
+type BatchQuery struct {
+	// Query is the query string for string queries.
+	Query string
+	// PreparedID is the prepared statement id for prepared queries.
+	PreparedID string
+}
+
+// ParseBatch extracts the queries from a BATCH message body.
+//
+// Only the query strings and prepared statement ids are parsed, the
+// bound values following each query are skipped on a best-effort basis
+// so parsing stops at the first query with values.
+func ParseBatch(body []byte) ([]BatchQuery, error) {
+	if len(body) < 3 {
+		return nil, trace.BadParameter("truncated CQL batch message")
+	}
+	count := int(binary.BigEndian.Uint16(body[1:]))
+	data := body[3:]
+	var queries []BatchQuery
+	for i := 0; i < count; i++ {
+		if len(data) < 1 {
+			return nil, trace.BadParameter("truncated CQL batch message")
+		}
+		kind := data[0]
+		data = data[1:]
+		var query BatchQuery
+		var err error
+		if kind == batchQueryKindString {
+			query.Query, data, err = readLongString(data)
+		} else {
+			var id []byte
+			id, data, err = readShortBytes(data)
+			query.PreparedID = string(id)
+		}
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		queries = append(queries, query)
+		// Each query is followed by its bound values which require type
+		// information to skip precisely, stop if any are present.
+		if len(data) < 2 {
+			return queries, nil
+		}
+		if values := binary.BigEndian.Uint16(data); values != 0 {
+			return queries, nil
+		}
+		data = data[2:]
+	}
+	return queries, nil
+}
+
+// ParsePreparedResult extracts the prepared statement id from a RESULT
+// message body, returning false if the result is not a prepared
+// statement result.
+func ParsePreparedResult(body []byte) (string, bool) {
+	if len(body) < 4 || binary.BigEndian.Uint32(body) != resultKindPrepared {
+		return "", false
+	}
+	id, _, err := readShortBytes(body[4:])
+	if err != nil {
+		return "", false
+	}
+	return string(id), true
+}
+
+// StripCompression removes the COMPRESSION option from a STARTUP message
+// body so both sides of the proxied connection negotiate an uncompressed
+// stream the engine can parse.
+func StripCompression(body []byte) ([]byte, error) {
+	if len(body) < 2 {
+		return nil, trace.BadParameter("truncated CQL startup message")
+	}
+	count := int(binary.BigEndian.Uint16(body))
+	data := body[2:]
+	options := make(map[string]string, count)
+	for i := 0; i < count; i++ {
+		var key, value string
+		var err error
+		key, data, err = readString(data)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		value, data, err = readString(data)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		if strings.EqualFold(key, "COMPRESSION") {
+			continue
+		}
+		options[key] = value
+	}
+	stripped := make([]byte, 2)
+	binary.BigEndian.PutUint16(stripped, uint16(len(options)))
+	for key, value := range options {
+		stripped = appendString(stripped, key)
+		stripped = appendString(stripped, value)
+	}
+	return stripped, nil
+}
+
+// appendString appends a 2-byte length prefixed string.
+func appendString(data []byte, s string) []byte {
+	data = binary.BigEndian.AppendUint16(data, uint16(len(s)))
+	return append(data, s...)
+}
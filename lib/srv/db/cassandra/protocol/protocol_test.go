@@ -0,0 +1,108 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protocol
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestFrameRoundtrip verifies CQL frames are serialized and parsed back.
+func TestFrameRoundtrip(t *testing.T) {
+	frame := &Frame{
+		Version: 0x04,
+		Stream:  42,
+		OpCode:  OpQuery,
+		Body:    []byte("test body"),
+	}
+	var buf bytes.Buffer
+	require.NoError(t, WriteFrame(&buf, frame))
+
+	readFrame, err := ReadFrame(&buf)
+	require.NoError(t, err)
+	require.Equal(t, frame, readFrame)
+}
+
+// TestParseQuery verifies query extraction from QUERY message bodies.
+func TestParseQuery(t *testing.T) {
+	body := binary.BigEndian.AppendUint32(nil, uint32(len("select * from test.users")))
+	body = append(body, "select * from test.users"...)
+	body = append(body, 0x00, 0x01) // Consistency.
+
+	query, err := ParseQuery(body)
+	require.NoError(t, err)
+	require.Equal(t, "select * from test.users", query)
+}
+
+// TestParseBatch verifies query extraction from BATCH message bodies.
+func TestParseBatch(t *testing.T) {
+	body := []byte{0x00} // Batch type.
+	body = binary.BigEndian.AppendUint16(body, 2)
+	// String query without values.
+	body = append(body, batchQueryKindString)
+	body = binary.BigEndian.AppendUint32(body, uint32(len("insert into t (a) values (1)")))
+	body = append(body, "insert into t (a) values (1)"...)
+	body = binary.BigEndian.AppendUint16(body, 0)
+	// Prepared query without values.
+	body = append(body, 0x01)
+	body = binary.BigEndian.AppendUint16(body, 4)
+	body = append(body, "\x01\x02\x03\x04"...)
+	body = binary.BigEndian.AppendUint16(body, 0)
+
+	queries, err := ParseBatch(body)
+	require.NoError(t, err)
+	require.Equal(t, []BatchQuery{
+		{Query: "insert into t (a) values (1)"},
+		{PreparedID: "\x01\x02\x03\x04"},
+	}, queries)
+}
+
+// TestParsePreparedResult verifies prepared statement id extraction from
+// RESULT message bodies.
+func TestParsePreparedResult(t *testing.T) {
+	body := binary.BigEndian.AppendUint32(nil, resultKindPrepared)
+	body = binary.BigEndian.AppendUint16(body, 4)
+	body = append(body, "\x01\x02\x03\x04"...)
+
+	id, ok := ParsePreparedResult(body)
+	require.True(t, ok)
+	require.Equal(t, "\x01\x02\x03\x04", id)
+
+	_, ok = ParsePreparedResult(binary.BigEndian.AppendUint32(nil, 0x0002))
+	require.False(t, ok)
+}
+
+// TestStripCompression verifies the compression option is removed from
+// STARTUP message bodies.
+func TestStripCompression(t *testing.T) {
+	body := binary.BigEndian.AppendUint16(nil, 2)
+	body = appendString(body, "CQL_VERSION")
+	body = appendString(body, "3.0.0")
+	body = appendString(body, "COMPRESSION")
+	body = appendString(body, "lz4")
+
+	stripped, err := StripCompression(body)
+	require.NoError(t, err)
+
+	expected := binary.BigEndian.AppendUint16(nil, 1)
+	expected = appendString(expected, "CQL_VERSION")
+	expected = appendString(expected, "3.0.0")
+	require.Equal(t, expected, stripped)
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clickhouse
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gravitational/trace"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHTTPQuery verifies query extraction from HTTP interface requests.
+func TestHTTPQuery(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		body   string
+		query  string
+	}{
+		{
+			name:   "query in URL parameter",
+			target: "/?query=SELECT+1",
+			query:  "SELECT 1",
+		},
+		{
+			name:   "query in request body",
+			target: "/",
+			body:   "SELECT * FROM events\n",
+			query:  "SELECT * FROM events",
+		},
+		{
+			name:   "URL parameter wins over body",
+			target: "/?query=INSERT+INTO+events+FORMAT+CSV",
+			body:   "1,foo\n2,bar\n",
+			query:  "INSERT INTO events FORMAT CSV",
+		},
+		{
+			name:   "no query",
+			target: "/ping",
+			query:  "",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, test.target, nil)
+			require.Equal(t, test.query, httpQuery(request, []byte(test.body)))
+		})
+	}
+}
+
+// TestSendErrorHTTP verifies errors are sent to the client in the plain
+// text format the ClickHouse HTTP interface uses.
+func TestSendErrorHTTP(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		statusCode int
+		body       string
+	}{
+		{
+			name:       "access denied",
+			err:        trace.AccessDenied("access to db denied"),
+			statusCode: http.StatusForbidden,
+			body:       "Code: 516. DB::Exception: access to db denied\n",
+		},
+		{
+			name:       "internal error",
+			err:        trace.ConnectionProblem(nil, "database is unavailable"),
+			statusCode: http.StatusInternalServerError,
+			body:       "Code: 516. DB::Exception: database is unavailable\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conn := &bufferConn{}
+			engine := &Engine{Log: logrus.StandardLogger(), clientConn: conn}
+			engine.sendErrorHTTP(test.err)
+			response, err := http.ReadResponse(bufio.NewReader(&conn.buf), nil)
+			require.NoError(t, err)
+			defer response.Body.Close()
+			require.Equal(t, test.statusCode, response.StatusCode)
+			body, err := io.ReadAll(response.Body)
+			require.NoError(t, err)
+			require.Equal(t, test.body, string(body))
+		})
+	}
+
+	t.Run("closed connection", func(t *testing.T) {
+		conn := &bufferConn{}
+		engine := &Engine{Log: logrus.StandardLogger(), clientConn: conn}
+		engine.sendErrorHTTP(net.ErrClosed)
+		require.Zero(t, conn.buf.Len())
+	})
+}
+
+// bufferConn is a net.Conn that records everything written to it.
+type bufferConn struct {
+	net.Conn
+	buf bytes.Buffer
+}
+
+func (c *bufferConn) Write(b []byte) (int, error) {
+	return c.buf.Write(b)
+}
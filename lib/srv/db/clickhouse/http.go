@@ -0,0 +1,142 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clickhouse
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gravitational/teleport/lib/defaults"
+	"github.com/gravitational/teleport/lib/srv/db/common"
+	"github.com/gravitational/teleport/lib/utils"
+
+	"github.com/gravitational/trace"
+)
+
+// maxHTTPRequestBodySize caps the accepted client request body size.
+const maxHTTPRequestBodySize = 256 * 1024 * 1024
+
+// handleHTTPConnection proxies the ClickHouse HTTP interface, auditing
+// the query from each request.
+func (e *Engine) handleHTTPConnection(ctx context.Context, sessionCtx *common.Session) error {
+	tlsConfig, err := e.Auth.GetTLSConfig(ctx, sessionCtx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	transport := &http.Transport{
+		TLSClientConfig: tlsConfig,
+		DialContext: (&net.Dialer{
+			Timeout: defaults.DatabaseConnectTimeout,
+		}).DialContext,
+	}
+	defer transport.CloseIdleConnections()
+	e.Audit.OnSessionStart(e.Context, sessionCtx, nil)
+	defer e.Audit.OnSessionEnd(e.Context, sessionCtx)
+	clientConnReader := bufio.NewReader(e.clientConn)
+	for {
+		request, err := http.ReadRequest(clientConnReader)
+		if err != nil {
+			if err == io.EOF || utils.IsOKNetworkError(err) {
+				return nil
+			}
+			return trace.Wrap(err)
+		}
+		err = e.processHTTPRequest(ctx, sessionCtx, transport, request)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+}
+
+// processHTTPRequest audits a single client request and forwards it to
+// the database, relaying the response back to the client.
+func (e *Engine) processHTTPRequest(ctx context.Context, sessionCtx *common.Session, transport *http.Transport, request *http.Request) error {
+	body, err := io.ReadAll(io.LimitReader(request.Body, maxHTTPRequestBodySize))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	request.Body.Close()
+	if query := httpQuery(request, body); query != "" {
+		if e.queryFilter != nil {
+			if err := e.queryFilter.CheckQuery(query); err != nil {
+				e.Audit.OnQuery(e.Context, sessionCtx, common.Query{Query: query, Error: err})
+				return trace.Wrap(err)
+			}
+		}
+		e.Audit.OnQuery(e.Context, sessionCtx, common.Query{Query: query})
+	}
+	reqCopy := request.Clone(ctx)
+	reqCopy.RequestURI = ""
+	reqCopy.URL.Scheme = "https"
+	reqCopy.URL.Host = sessionCtx.Database.GetURI()
+	reqCopy.Body = io.NopCloser(bytes.NewReader(body))
+	reqCopy.ContentLength = int64(len(body))
+	// Authentication is happening between the agent and the database via
+	// the client certificate so drop client credentials.
+	reqCopy.Header.Del("Authorization")
+	reqCopy.Header.Del("X-ClickHouse-Key")
+	reqCopy.Header.Set("X-ClickHouse-User", sessionCtx.DatabaseUser)
+	response, err := transport.RoundTrip(reqCopy)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer response.Body.Close()
+	return trace.Wrap(response.Write(e.clientConn))
+}
+
+// httpQuery extracts the query from an HTTP interface request where it
+// is passed either in the "query" URL parameter, the request body or
+// both (parameter for the statement, body for the data).
+func httpQuery(request *http.Request, body []byte) string {
+	query := request.URL.Query().Get("query")
+	if query == "" {
+		query = string(body)
+	}
+	return strings.TrimSpace(query)
+}
+
+// sendErrorHTTP sends an error to the client in the plain text format
+// the ClickHouse HTTP interface uses.
+func (e *Engine) sendErrorHTTP(err error) {
+	if err == nil || utils.IsOKNetworkError(err) {
+		return
+	}
+	statusCode := http.StatusInternalServerError
+	if trace.IsAccessDenied(err) {
+		statusCode = http.StatusForbidden
+	}
+	body := fmt.Sprintf("Code: 516. DB::Exception: %v\n", err)
+	response := &http.Response{
+		StatusCode: statusCode,
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header: http.Header{
+			"Content-Type": []string{"text/plain; charset=utf-8"},
+		},
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+	}
+	if errSend := response.Write(e.clientConn); errSend != nil {
+		e.Log.WithError(errSend).Debugf("Failed to send error %q to ClickHouse client.", err)
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clickhouse
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+
+	"github.com/gravitational/teleport/lib/defaults"
+	"github.com/gravitational/teleport/lib/srv/db/common"
+	"github.com/gravitational/teleport/lib/utils"
+
+	"github.com/gravitational/trace"
+)
+
+// handleNativeConnection proxies the ClickHouse native TCP protocol.
+//
+// The native protocol is relayed as an opaque stream: its packets are
+// compressed by default and the framing is version-dependent, so
+// auditing is limited to session start and end events. Deployments that
+// need per-query auditing should register the database with the
+// "clickhouse-http" protocol instead.
+func (e *Engine) handleNativeConnection(ctx context.Context, sessionCtx *common.Session) error {
+	tlsConfig, err := e.Auth.GetTLSConfig(ctx, sessionCtx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: defaults.DatabaseConnectTimeout},
+		Config:    tlsConfig,
+	}
+	serverConn, err := dialer.DialContext(ctx, "tcp", sessionCtx.Database.GetURI())
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	defer serverConn.Close()
+	e.Audit.OnSessionStart(e.Context, sessionCtx, nil)
+	defer e.Audit.OnSessionEnd(e.Context, sessionCtx)
+	// Copy between the connections.
+	clientErrCh := make(chan error, 1)
+	serverErrCh := make(chan error, 1)
+	go func() {
+		defer close(clientErrCh)
+		_, err := io.Copy(serverConn, e.clientConn)
+		if err != nil && !utils.IsOKNetworkError(err) {
+			clientErrCh <- err
+		}
+	}()
+	go func() {
+		defer close(serverErrCh)
+		_, err := io.Copy(e.clientConn, serverConn)
+		if err != nil && !utils.IsOKNetworkError(err) {
+			serverErrCh <- err
+		}
+	}()
+	select {
+	case err := <-clientErrCh:
+		e.Log.WithError(err).Debug("Client done.")
+	case err := <-serverErrCh:
+		e.Log.WithError(err).Debug("Server done.")
+	case <-ctx.Done():
+		e.Log.Debug("Context canceled.")
+	}
+	return nil
+}
+
+// sendErrorNative closes the client connection on error.
+//
+// The native protocol exchanges a version-dependent handshake before any
+// exception packets can be sent so errors are reported by closing the
+// connection and logging the cause.
+func (e *Engine) sendErrorNative(err error) {
+	if err == nil || utils.IsOKNetworkError(err) {
+		return
+	}
+	e.Log.WithError(err).Debug("Closing ClickHouse client connection.")
+	e.clientConn.Close()
+}
@@ -130,7 +130,7 @@ func (r *awsClient) teardownIAM(ctx context.Context) error {
 
 // ensureIAMAuth enables RDS instance IAM auth if it isn't enabled.
 func (r *awsClient) ensureIAMAuth(ctx context.Context) error {
-	if r.cfg.database.IsRedshift() {
+	if r.cfg.database.IsRedshift() || r.cfg.database.IsRedshiftServerless() {
 		// Redshift IAM auth is always enabled.
 		return nil
 	}
@@ -0,0 +1,184 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/teleport/api/utils"
+	awslib "github.com/gravitational/teleport/lib/cloud/aws"
+	"github.com/gravitational/teleport/lib/services"
+	"github.com/gravitational/teleport/lib/srv/db/common"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+
+	"github.com/gravitational/trace"
+)
+
+// BootstrapConfig is the config for generating IAM policies the database
+// agent needs to proxy AWS hosted databases.
+type BootstrapConfig struct {
+	// Databases is the list of statically configured databases.
+	Databases types.Databases
+	// AWSMatchers is the list of AWS discovery matchers.
+	AWSMatchers []services.AWSMatcher
+	// Identity is the AWS identity the policy will be attached to.
+	Identity awslib.Identity
+}
+
+// BuildBootstrapPolicy generates the IAM policy the database agent needs
+// to discover databases, configure their IAM authentication and manage its
+// own inline connect policy at runtime. Connect permissions themselves are
+// added by the agent within the limits of the permissions boundary produced
+// by BuildBootstrapBoundary.
+func BuildBootstrapPolicy(config BootstrapConfig) (*awslib.PolicyDocument, error) {
+	policy := awslib.NewPolicyDocument()
+	if hasRDSDatabases(config) {
+		for _, action := range []string{
+			"rds:DescribeDBInstances",
+			"rds:DescribeDBClusters",
+			"rds:ModifyDBInstance",
+			"rds:ModifyDBCluster",
+		} {
+			policy.Ensure(awslib.EffectAllow, action, "*")
+		}
+	}
+	if hasRedshiftDatabases(config) {
+		policy.Ensure(awslib.EffectAllow, "redshift:DescribeClusters", "*")
+	}
+	if len(policy.Statements) == 0 {
+		return nil, trace.NotFound("configuration contains no AWS databases or AWS matchers")
+	}
+	for _, action := range identityPolicyActions(config.Identity) {
+		policy.Ensure(awslib.EffectAllow, action, identityResource(config.Identity))
+	}
+	return policy, nil
+}
+
+// BuildBootstrapBoundary generates the IAM permissions boundary for the
+// database agent. The boundary includes everything the policy does, plus
+// the database connect permissions the agent grants itself at runtime.
+func BuildBootstrapBoundary(config BootstrapConfig) (*awslib.PolicyDocument, error) {
+	boundary, err := BuildBootstrapPolicy(config)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, database := range config.Databases {
+		for _, resource := range database.GetIAMResources() {
+			boundary.Ensure(awslib.EffectAllow, database.GetIAMAction(), resource)
+		}
+	}
+	// Databases matched by the discovery selectors aren't known up front
+	// so the boundary must permit connecting to any of them.
+	if hasRDSMatchers(config) {
+		boundary.Ensure(awslib.EffectAllow, "rds-db:connect", "*")
+	}
+	if hasRedshiftMatchers(config) {
+		boundary.Ensure(awslib.EffectAllow, "redshift:GetClusterCredentials", "*")
+	}
+	return boundary, nil
+}
+
+// AttachBootstrapPolicy attaches the bootstrap policy to the identity as
+// an inline policy with the provided name.
+func AttachBootstrapPolicy(ctx context.Context, iamClient iamiface.IAMAPI, identity awslib.Identity, policyName string, policy *awslib.PolicyDocument) error {
+	document, err := json.Marshal(policy)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	switch identity.(type) {
+	case awslib.Role:
+		_, err = iamClient.PutRolePolicyWithContext(ctx, &iam.PutRolePolicyInput{
+			PolicyName:     aws.String(policyName),
+			PolicyDocument: aws.String(string(document)),
+			RoleName:       aws.String(identity.GetName()),
+		})
+	case awslib.User:
+		_, err = iamClient.PutUserPolicyWithContext(ctx, &iam.PutUserPolicyInput{
+			PolicyName:     aws.String(policyName),
+			PolicyDocument: aws.String(string(document)),
+			UserName:       aws.String(identity.GetName()),
+		})
+	default:
+		return trace.BadParameter("can only attach policies to roles or users, got %v", identity)
+	}
+	return common.ConvertError(err)
+}
+
+// hasRDSDatabases returns true if the config has RDS databases or matchers.
+func hasRDSDatabases(config BootstrapConfig) bool {
+	for _, database := range config.Databases {
+		if database.IsRDS() {
+			return true
+		}
+	}
+	return hasRDSMatchers(config)
+}
+
+// hasRedshiftDatabases returns true if the config has Redshift databases or matchers.
+func hasRedshiftDatabases(config BootstrapConfig) bool {
+	for _, database := range config.Databases {
+		if database.IsRedshift() {
+			return true
+		}
+	}
+	return hasRedshiftMatchers(config)
+}
+
+// hasRDSMatchers returns true if the config has RDS discovery matchers.
+func hasRDSMatchers(config BootstrapConfig) bool {
+	for _, matcher := range config.AWSMatchers {
+		if utils.SliceContainsStr(matcher.Types, services.AWSMatcherRDS) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasRedshiftMatchers returns true if the config has Redshift discovery matchers.
+func hasRedshiftMatchers(config BootstrapConfig) bool {
+	for _, matcher := range config.AWSMatchers {
+		if utils.SliceContainsStr(matcher.Types, services.AWSMatcherRedshift) {
+			return true
+		}
+	}
+	return false
+}
+
+// identityPolicyActions returns the IAM actions the agent needs to manage
+// its own inline connect policy.
+func identityPolicyActions(identity awslib.Identity) []string {
+	switch identity.(type) {
+	case awslib.User:
+		return []string{"iam:GetUserPolicy", "iam:PutUserPolicy", "iam:DeleteUserPolicy"}
+	default:
+		return []string{"iam:GetRolePolicy", "iam:PutRolePolicy", "iam:DeleteRolePolicy"}
+	}
+}
+
+// identityResource returns the IAM resource matching the agent's identity,
+// or a wildcard if the identity isn't known yet.
+func identityResource(identity awslib.Identity) string {
+	if identity == nil {
+		return "*"
+	}
+	return identity.String()
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"testing"
+
+	"github.com/gravitational/teleport/api/types"
+	awslib "github.com/gravitational/teleport/lib/cloud/aws"
+	"github.com/gravitational/teleport/lib/defaults"
+	"github.com/gravitational/teleport/lib/services"
+
+	"github.com/gravitational/trace"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBuildBootstrapPolicy verifies generation of IAM policies and
+// permission boundaries for AWS databases.
+func TestBuildBootstrapPolicy(t *testing.T) {
+	rdsDatabase, err := types.NewDatabaseV3(types.Metadata{
+		Name: "rds",
+	}, types.DatabaseSpecV3{
+		Protocol: defaults.ProtocolPostgres,
+		URI:      "localhost:5432",
+		AWS: types.AWS{
+			Region:    "us-east-1",
+			AccountID: "1234567890",
+			RDS: types.RDS{
+				InstanceID: "postgres-rds",
+				ResourceID: "db-1234",
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	config := BootstrapConfig{
+		Databases: types.Databases{rdsDatabase},
+		AWSMatchers: []services.AWSMatcher{{
+			Types:   []string{services.AWSMatcherRDS},
+			Regions: []string{"us-west-1"},
+			Tags:    types.Labels{"*": []string{"*"}},
+		}},
+	}
+
+	policy, err := BuildBootstrapPolicy(config)
+	require.NoError(t, err)
+	requireStatement(t, policy, "rds:DescribeDBInstances", "*")
+	requireStatement(t, policy, "rds:ModifyDBInstance", "*")
+	requireStatement(t, policy, "iam:PutRolePolicy", "*")
+	requireNoStatement(t, policy, "rds-db:connect")
+
+	boundary, err := BuildBootstrapBoundary(config)
+	require.NoError(t, err)
+	requireStatement(t, boundary, "rds:DescribeDBInstances", "*")
+	requireStatement(t, boundary, "rds-db:connect",
+		"arn:aws:rds-db:us-east-1:1234567890:dbuser:db-1234/*", "*")
+
+	// Without any AWS databases policy generation should fail.
+	_, err = BuildBootstrapPolicy(BootstrapConfig{})
+	require.True(t, trace.IsNotFound(err))
+}
+
+// requireStatement verifies the policy allows the action on the resources.
+func requireStatement(t *testing.T, policy *awslib.PolicyDocument, action string, resources ...string) {
+	t.Helper()
+	for _, statement := range policy.Statements {
+		for _, a := range statement.Actions {
+			if a == action {
+				require.ElementsMatch(t, resources, statement.Resources)
+				return
+			}
+		}
+	}
+	t.Fatalf("policy has no statement for action %q", action)
+}
+
+// requireNoStatement verifies the policy has no statement for the action.
+func requireNoStatement(t *testing.T, policy *awslib.PolicyDocument, action string) {
+	t.Helper()
+	for _, statement := range policy.Statements {
+		require.NotContains(t, statement.Actions, action)
+	}
+}
@@ -68,7 +68,7 @@ func NewIAM(ctx context.Context, config IAMConfig) (*IAM, error) {
 
 // Setup sets up cloud IAM policies for the provided database.
 func (c *IAM) Setup(ctx context.Context, database types.Database) error {
-	if database.IsRDS() || database.IsRedshift() {
+	if database.IsRDS() || database.IsRedshift() || database.IsRedshiftServerless() {
 		rds, err := c.getAWSConfigurator(ctx, database)
 		if err != nil {
 			return trace.Wrap(err)
@@ -80,7 +80,7 @@ func (c *IAM) Setup(ctx context.Context, database types.Database) error {
 
 // Teardown tears down cloud IAM policies for the provided database.
 func (c *IAM) Teardown(ctx context.Context, database types.Database) error {
-	if database.IsRDS() || database.IsRedshift() {
+	if database.IsRDS() || database.IsRedshift() || database.IsRedshiftServerless() {
 		rds, err := c.getAWSConfigurator(ctx, database)
 		if err != nil {
 			return trace.Wrap(err)
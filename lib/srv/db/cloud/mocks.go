@@ -17,6 +17,10 @@ limitations under the License.
 package cloud
 
 import (
+	"context"
+
+	"github.com/gravitational/teleport/lib/services"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/iam"
@@ -307,3 +311,17 @@ func (m *IAMMockUnauth) GetUserPolicyWithContext(ctx aws.Context, input *iam.Get
 func (m *IAMMockUnauth) PutUserPolicyWithContext(ctx aws.Context, input *iam.PutUserPolicyInput, options ...request.Option) (*iam.PutUserPolicyOutput, error) {
 	return nil, trace.AccessDenied("unauthorized")
 }
+
+// AzureServersMock mocks Azure database servers API.
+type AzureServersMock struct {
+	Servers []services.AzureDBServer
+}
+
+func (m *AzureServersMock) ListDBServers(ctx context.Context, protocol string) (servers []services.AzureDBServer, err error) {
+	for _, server := range m.Servers {
+		if server.Protocol == protocol {
+			servers = append(servers, server)
+		}
+	}
+	return servers, nil
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package watchers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/teleport/api/utils"
+	"github.com/gravitational/teleport/lib/services"
+	"github.com/gravitational/teleport/lib/srv/db/common"
+
+	"github.com/gravitational/trace"
+	"github.com/sirupsen/logrus"
+)
+
+// azureFetcherConfig is the Azure database servers fetcher configuration.
+type azureFetcherConfig struct {
+	// Labels is a selector to match cloud databases.
+	Labels types.Labels
+	// AzureServers is the Azure database servers API client.
+	AzureServers common.AzureServersClient
+	// Subscription is the Azure subscription to query databases in.
+	Subscription string
+	// Protocol is the protocol of the servers to fetch, "mysql" or "postgres".
+	Protocol string
+	// Regions is an optional filter of Azure regions to fetch servers
+	// from. Empty means all regions.
+	Regions []string
+}
+
+// CheckAndSetDefaults validates the config and sets defaults.
+func (c *azureFetcherConfig) CheckAndSetDefaults() error {
+	if len(c.Labels) == 0 {
+		return trace.BadParameter("missing parameter Labels")
+	}
+	if c.AzureServers == nil {
+		return trace.BadParameter("missing parameter AzureServers")
+	}
+	if c.Subscription == "" {
+		return trace.BadParameter("missing parameter Subscription")
+	}
+	if c.Protocol == "" {
+		return trace.BadParameter("missing parameter Protocol")
+	}
+	return nil
+}
+
+// azureDBServersFetcher retrieves Azure MySQL or PostgreSQL flexible servers.
+type azureDBServersFetcher struct {
+	cfg azureFetcherConfig
+	log logrus.FieldLogger
+}
+
+// newAzureDBServersFetcher returns a new Azure database servers fetcher instance.
+func newAzureDBServersFetcher(config azureFetcherConfig) (Fetcher, error) {
+	if err := config.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &azureDBServersFetcher{
+		cfg: config,
+		log: logrus.WithFields(logrus.Fields{
+			trace.Component: "watch:azure",
+			"labels":        config.Labels,
+			"subscription":  config.Subscription,
+			"protocol":      config.Protocol,
+		}),
+	}, nil
+}
+
+// Get returns Azure database servers matching the watcher's selectors.
+func (f *azureDBServersFetcher) Get(ctx context.Context) (types.Databases, error) {
+	databases, err := f.getAzureDatabases(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	var result types.Databases
+	for _, database := range databases {
+		match, _, err := services.MatchLabels(f.cfg.Labels, database.GetAllLabels())
+		if err != nil {
+			f.log.Warnf("Failed to match %v against selector: %v.", database, err)
+		} else if match {
+			result = append(result, database)
+		} else {
+			f.log.Debugf("%v doesn't match selector.", database)
+		}
+	}
+	return result, nil
+}
+
+// getAzureDatabases returns a list of database resources representing Azure
+// database servers.
+func (f *azureDBServersFetcher) getAzureDatabases(ctx context.Context) (types.Databases, error) {
+	servers, err := f.cfg.AzureServers.ListDBServers(ctx, f.cfg.Protocol)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	databases := make(types.Databases, 0, len(servers))
+	for _, server := range servers {
+		if len(f.cfg.Regions) != 0 && !utils.SliceContainsStr(f.cfg.Regions, server.Location) {
+			continue
+		}
+		if !azureServerAvailable(server) {
+			f.log.Debugf("Azure server %q is %v. Skipping.", server.Name, server.State)
+			continue
+		}
+		database, err := services.NewDatabaseFromAzureServer(&server)
+		if err != nil {
+			f.log.Infof("Could not convert Azure server %q to database resource: %v.",
+				server.Name, err)
+		} else {
+			databases = append(databases, database)
+		}
+	}
+	return databases, nil
+}
+
+// azureServerAvailable returns false for servers in lifecycle states where
+// they can't be connected to, so they get deregistered until they become
+// available again. Unrecognized states are considered available to avoid
+// deregistering databases on transient states introduced by Azure.
+func azureServerAvailable(server services.AzureDBServer) bool {
+	switch server.State {
+	case "Disabled", "Dropping", "Starting", "Stopped", "Stopping":
+		return false
+	}
+	return true
+}
+
+// String returns the fetcher's string description.
+func (f *azureDBServersFetcher) String() string {
+	return fmt.Sprintf("azureDBServersFetcher(Subscription=%v, Protocol=%v, Labels=%v)",
+		f.cfg.Subscription, f.cfg.Protocol, f.cfg.Labels)
+}
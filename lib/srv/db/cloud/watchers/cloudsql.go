@@ -0,0 +1,173 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package watchers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/teleport/lib/services"
+
+	"github.com/gravitational/trace"
+	"github.com/sirupsen/logrus"
+	sqladmin "google.golang.org/api/sqladmin/v1beta4"
+)
+
+// cloudSQLFetcherConfig is the Cloud SQL databases fetcher configuration.
+type cloudSQLFetcherConfig struct {
+	// Labels is a selector to match cloud databases.
+	Labels types.Labels
+	// SQLAdmin is the Cloud SQL Admin API client.
+	SQLAdmin *sqladmin.Service
+	// ProjectID is the GCP project to query databases in.
+	ProjectID string
+}
+
+// CheckAndSetDefaults validates the config and sets defaults.
+func (c *cloudSQLFetcherConfig) CheckAndSetDefaults() error {
+	if len(c.Labels) == 0 {
+		return trace.BadParameter("missing parameter Labels")
+	}
+	if c.SQLAdmin == nil {
+		return trace.BadParameter("missing parameter SQLAdmin")
+	}
+	if c.ProjectID == "" {
+		return trace.BadParameter("missing parameter ProjectID")
+	}
+	return nil
+}
+
+// cloudSQLFetcher retrieves Cloud SQL instances.
+type cloudSQLFetcher struct {
+	cfg cloudSQLFetcherConfig
+	log logrus.FieldLogger
+}
+
+// newCloudSQLFetcher returns a new Cloud SQL databases fetcher instance.
+func newCloudSQLFetcher(config cloudSQLFetcherConfig) (Fetcher, error) {
+	if err := config.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &cloudSQLFetcher{
+		cfg: config,
+		log: logrus.WithFields(logrus.Fields{
+			trace.Component: "watch:cloudsql",
+			"labels":        config.Labels,
+			"project":       config.ProjectID,
+		}),
+	}, nil
+}
+
+// Get returns Cloud SQL instances matching the watcher's selectors.
+func (f *cloudSQLFetcher) Get(ctx context.Context) (types.Databases, error) {
+	databases, err := f.getCloudSQLDatabases(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	var result types.Databases
+	for _, database := range databases {
+		match, _, err := services.MatchLabels(f.cfg.Labels, database.GetAllLabels())
+		if err != nil {
+			f.log.Warnf("Failed to match %v against selector: %v.", database, err)
+		} else if match {
+			result = append(result, database)
+		} else {
+			f.log.Debugf("%v doesn't match selector.", database)
+		}
+	}
+	return result, nil
+}
+
+// getCloudSQLDatabases returns a list of database resources representing
+// Cloud SQL instances.
+func (f *cloudSQLFetcher) getCloudSQLDatabases(ctx context.Context) (types.Databases, error) {
+	instances, err := f.getAllInstances(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	databases := make(types.Databases, 0, len(instances))
+	for _, instance := range instances {
+		if !cloudSQLInstanceSupported(instance) {
+			f.log.Debugf("Cloud SQL instance %q engine %q is not supported. Skipping.",
+				instance.Name, instance.DatabaseVersion)
+			continue
+		}
+		if !cloudSQLInstanceAvailable(instance) {
+			f.log.Debugf("Cloud SQL instance %q is %v. Skipping.",
+				instance.Name, instance.State)
+			continue
+		}
+		database, err := services.NewDatabaseFromCloudSQLInstance(instance)
+		if err != nil {
+			f.log.Infof("Could not convert Cloud SQL instance %q to database resource: %v.",
+				instance.Name, err)
+		} else {
+			databases = append(databases, database)
+		}
+	}
+	return databases, nil
+}
+
+// getAllInstances fetches all Cloud SQL instances in the fetcher's project.
+func (f *cloudSQLFetcher) getAllInstances(ctx context.Context) (instances []*sqladmin.DatabaseInstance, err error) {
+	var pageToken string
+	for i := 0; i < maxPages; i++ {
+		call := f.cfg.SQLAdmin.Instances.List(f.cfg.ProjectID).Context(ctx)
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+		response, err := call.Do()
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		instances = append(instances, response.Items...)
+		if response.NextPageToken == "" {
+			break
+		}
+		pageToken = response.NextPageToken
+	}
+	return instances, nil
+}
+
+// cloudSQLInstanceSupported returns true if the instance runs a database
+// engine Teleport supports, filtering out e.g. SQL Server instances.
+func cloudSQLInstanceSupported(instance *sqladmin.DatabaseInstance) bool {
+	return strings.HasPrefix(instance.DatabaseVersion, services.CloudSQLEngineMySQLPrefix) ||
+		strings.HasPrefix(instance.DatabaseVersion, services.CloudSQLEnginePostgresPrefix)
+}
+
+// cloudSQLInstanceAvailable returns false for instances in lifecycle states
+// where they can't be connected to, such as stopped instances, so they get
+// deregistered until they become available again. Unrecognized states are
+// considered available to avoid deregistering databases on transient states
+// introduced by GCP.
+func cloudSQLInstanceAvailable(instance *sqladmin.DatabaseInstance) bool {
+	switch instance.State {
+	case "SUSPENDED", "PENDING_DELETE", "PENDING_CREATE", "MAINTENANCE", "FAILED":
+		return false
+	}
+	return true
+}
+
+// String returns the fetcher's string description.
+func (f *cloudSQLFetcher) String() string {
+	return fmt.Sprintf("cloudSQLFetcher(ProjectID=%v, Labels=%v)",
+		f.cfg.ProjectID, f.cfg.Labels)
+}
@@ -106,6 +106,12 @@ func (f *rdsDBInstancesFetcher) getRDSDatabases(ctx context.Context) (types.Data
 	}
 	databases := make(types.Databases, 0, len(instances))
 	for _, instance := range instances {
+		if !rdsInstanceAvailable(instance) {
+			f.log.Debugf("RDS instance %q is %v. Skipping.",
+				aws.StringValue(instance.DBInstanceIdentifier),
+				aws.StringValue(instance.DBInstanceStatus))
+			continue
+		}
 		database, err := services.NewDatabaseFromRDSInstance(instance)
 		if err != nil {
 			f.log.Infof("Could not convert RDS instance %q to database resource: %v.",
@@ -117,6 +123,20 @@ func (f *rdsDBInstancesFetcher) getRDSDatabases(ctx context.Context) (types.Data
 	return databases, nil
 }
 
+// rdsInstanceAvailable returns false for instances in lifecycle states
+// where they can't be connected to, such as stopped instances, so they
+// get deregistered until they become available again. Unrecognized
+// states are considered available to avoid deregistering databases on
+// transient states introduced by AWS.
+func rdsInstanceAvailable(instance *rds.DBInstance) bool {
+	switch aws.StringValue(instance.DBInstanceStatus) {
+	case "stopped", "stopping", "creating", "deleting", "failed",
+		"deleting-and-waiting", "inaccessible-encryption-credentials":
+		return false
+	}
+	return true
+}
+
 // getAllDBInstances fetches all RDS instances using the provided client, up
 // to the specified max number of pages.
 func getAllDBInstances(ctx context.Context, rdsClient rdsiface.RDSAPI, maxPages int) (instances []*rds.DBInstance, err error) {
@@ -187,6 +207,12 @@ func (f *rdsAuroraClustersFetcher) getAuroraDatabases(ctx context.Context) (type
 	}
 	databases := make(types.Databases, 0, len(clusters))
 	for _, cluster := range clusters {
+		if !rdsClusterAvailable(cluster) {
+			f.log.Debugf("Aurora cluster %q is %v. Skipping.",
+				aws.StringValue(cluster.DBClusterIdentifier),
+				aws.StringValue(cluster.Status))
+			continue
+		}
 		if !services.IsRDSClusterSupported(cluster) {
 			f.log.Debugf("Aurora cluster %q (engine mode %v, engine version %v) doesn't support IAM authentication. Skipping.",
 				aws.StringValue(cluster.DBClusterIdentifier),
@@ -206,6 +232,20 @@ func (f *rdsAuroraClustersFetcher) getAuroraDatabases(ctx context.Context) (type
 	return databases, nil
 }
 
+// rdsClusterAvailable returns false for clusters in lifecycle states
+// where they can't be connected to, such as stopped clusters, so they
+// get deregistered until they become available again. Unrecognized
+// states are considered available to avoid deregistering databases on
+// transient states introduced by AWS.
+func rdsClusterAvailable(cluster *rds.DBCluster) bool {
+	switch aws.StringValue(cluster.Status) {
+	case "stopped", "stopping", "creating", "deleting", "failed",
+		"inaccessible-encryption-credentials", "migration-failed":
+		return false
+	}
+	return true
+}
+
 // getAllDBClusters fetches all RDS clusters using the provided client, up to
 // the specified max number of pages.
 func getAllDBClusters(ctx context.Context, rdsClient rdsiface.RDSAPI, maxPages int) (clusters []*rds.DBCluster, err error) {
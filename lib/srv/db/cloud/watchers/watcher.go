@@ -22,6 +22,7 @@ import (
 
 	"github.com/gravitational/teleport/api/types"
 	"github.com/gravitational/teleport/api/utils"
+	"github.com/gravitational/teleport/lib/defaults"
 	"github.com/gravitational/teleport/lib/services"
 	"github.com/gravitational/teleport/lib/srv/db/common"
 
@@ -33,6 +34,10 @@ import (
 type WatcherConfig struct {
 	// AWSMatchers is a list of matchers for AWS databases.
 	AWSMatchers []services.AWSMatcher
+	// AzureMatchers is a list of matchers for Azure databases.
+	AzureMatchers []services.AzureMatcher
+	// GCPMatchers is a list of matchers for GCP databases.
+	GCPMatchers []services.GCPMatcher
 	// Clients provides cloud API clients.
 	Clients common.CloudClients
 	// Interval is the interval between fetches.
@@ -75,7 +80,7 @@ func NewWatcher(ctx context.Context, config WatcherConfig) (*Watcher, error) {
 	if err := config.CheckAndSetDefaults(); err != nil {
 		return nil, trace.Wrap(err)
 	}
-	fetchers, err := makeFetchers(config.Clients, config.AWSMatchers)
+	fetchers, err := makeFetchers(ctx, config)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
@@ -142,8 +147,28 @@ func (w *Watcher) DatabasesC() <-chan types.Databases {
 	return w.databasesC
 }
 
-// makeFetchers returns cloud fetchers for the provided matchers.
-func makeFetchers(clients common.CloudClients, matchers []services.AWSMatcher) (result []Fetcher, err error) {
+// makeFetchers returns cloud fetchers for the watcher's matchers.
+func makeFetchers(ctx context.Context, config WatcherConfig) (result []Fetcher, err error) {
+	awsFetchers, err := makeAWSFetchers(config.Clients, config.AWSMatchers)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	azureFetchers, err := makeAzureFetchers(config.Clients, config.AzureMatchers)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	gcpFetchers, err := makeGCPFetchers(ctx, config.Clients, config.GCPMatchers)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	result = append(result, awsFetchers...)
+	result = append(result, azureFetchers...)
+	result = append(result, gcpFetchers...)
+	return result, nil
+}
+
+// makeAWSFetchers returns AWS fetchers for the provided matchers.
+func makeAWSFetchers(clients common.CloudClients, matchers []services.AWSMatcher) (result []Fetcher, err error) {
 	for _, matcher := range matchers {
 		if utils.SliceContainsStr(matcher.Types, services.AWSMatcherRDS) {
 			for _, region := range matcher.Regions {
@@ -158,6 +183,65 @@ func makeFetchers(clients common.CloudClients, matchers []services.AWSMatcher) (
 	return result, nil
 }
 
+// makeAzureFetchers returns Azure fetchers for the provided matchers.
+func makeAzureFetchers(clients common.CloudClients, matchers []services.AzureMatcher) (result []Fetcher, err error) {
+	for _, matcher := range matchers {
+		for _, matcherType := range matcher.Types {
+			var protocol string
+			switch matcherType {
+			case services.AzureMatcherMySQL:
+				protocol = defaults.ProtocolMySQL
+			case services.AzureMatcherPostgres:
+				protocol = defaults.ProtocolPostgres
+			default:
+				continue
+			}
+			for _, subscription := range matcher.Subscriptions {
+				azureServers, err := clients.GetAzureServersClient(subscription)
+				if err != nil {
+					return nil, trace.Wrap(err)
+				}
+				fetcher, err := newAzureDBServersFetcher(azureFetcherConfig{
+					Labels:       matcher.Tags,
+					AzureServers: azureServers,
+					Subscription: subscription,
+					Protocol:     protocol,
+					Regions:      matcher.Regions,
+				})
+				if err != nil {
+					return nil, trace.Wrap(err)
+				}
+				result = append(result, fetcher)
+			}
+		}
+	}
+	return result, nil
+}
+
+// makeGCPFetchers returns GCP fetchers for the provided matchers.
+func makeGCPFetchers(ctx context.Context, clients common.CloudClients, matchers []services.GCPMatcher) (result []Fetcher, err error) {
+	for _, matcher := range matchers {
+		if utils.SliceContainsStr(matcher.Types, services.GCPMatcherCloudSQL) {
+			sqlAdmin, err := clients.GetGCPSQLAdminClient(ctx)
+			if err != nil {
+				return nil, trace.Wrap(err)
+			}
+			for _, projectID := range matcher.ProjectIDs {
+				fetcher, err := newCloudSQLFetcher(cloudSQLFetcherConfig{
+					Labels:    matcher.Labels,
+					SQLAdmin:  sqlAdmin,
+					ProjectID: projectID,
+				})
+				if err != nil {
+					return nil, trace.Wrap(err)
+				}
+				result = append(result, fetcher)
+			}
+		}
+	}
+	return result, nil
+}
+
 // makeRDSFetchers returns RDS fetcher for the provided region and tags.
 func makeRDSFetchers(clients common.CloudClients, region string, tags types.Labels) ([]Fetcher, error) {
 	rds, err := clients.GetAWSRDSClient(region)
@@ -18,11 +18,15 @@ package watchers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/teleport/lib/defaults"
 	"github.com/gravitational/teleport/lib/services"
 	"github.com/gravitational/teleport/lib/srv/db/cloud"
 	"github.com/gravitational/teleport/lib/srv/db/common"
@@ -33,6 +37,8 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/api/option"
+	sqladmin "google.golang.org/api/sqladmin/v1beta4"
 )
 
 // TestWatcher tests cloud databases watcher.
@@ -49,9 +55,27 @@ func TestWatcher(t *testing.T) {
 	auroraCluster3, _ := makeRDSCluster(t, "cluster-3", "us-east-2", services.RDSEngineModeProvisioned, map[string]string{"env": "prod"})
 	auroraClusterUnsupported, _ := makeRDSCluster(t, "serverless", "us-east-1", services.RDSEngineModeServerless, map[string]string{"env": "prod"})
 
+	rdsInstanceStopped, _ := makeRDSInstance(t, "instance-stopped", "us-east-1", map[string]string{"env": "prod"})
+	rdsInstanceStopped.DBInstanceStatus = aws.String("stopped")
+	auroraClusterStopped, _ := makeRDSCluster(t, "cluster-stopped", "us-east-1", services.RDSEngineModeProvisioned, map[string]string{"env": "prod"})
+	auroraClusterStopped.Status = aws.String("stopped")
+
+	azureServer1, azureDatabase1 := makeAzureServer(t, "azure-1", "eastus", map[string]string{"env": "prod"})
+	azureServer2, _ := makeAzureServer(t, "azure-2", "eastus", map[string]string{"env": "dev"})
+	azureServer3, _ := makeAzureServer(t, "azure-3", "westus", map[string]string{"env": "prod"})
+	azureServerStopped, _ := makeAzureServer(t, "azure-stopped", "eastus", map[string]string{"env": "prod"})
+	azureServerStopped.State = "Stopped"
+
+	cloudSQLInstance1, cloudSQLDatabase1 := makeCloudSQLInstance(t, "cloudsql-1", "project-1", map[string]string{"env": "prod"})
+	cloudSQLInstance2, _ := makeCloudSQLInstance(t, "cloudsql-2", "project-1", map[string]string{"env": "dev"})
+	cloudSQLInstanceStopped, _ := makeCloudSQLInstance(t, "cloudsql-stopped", "project-1", map[string]string{"env": "prod"})
+	cloudSQLInstanceStopped.State = "SUSPENDED"
+
 	tests := []struct {
 		name              string
 		awsMatchers       []services.AWSMatcher
+		azureMatchers     []services.AzureMatcher
+		gcpMatchers       []services.GCPMatcher
 		clients           common.CloudClients
 		expectedDatabases types.Databases
 	}{
@@ -99,6 +123,23 @@ func TestWatcher(t *testing.T) {
 			},
 			expectedDatabases: types.Databases{auroraDatabase1},
 		},
+		{
+			name: "skip unavailable databases",
+			awsMatchers: []services.AWSMatcher{{
+				Types:   []string{services.AWSMatcherRDS},
+				Regions: []string{"us-east-1"},
+				Tags:    types.Labels{"*": []string{"*"}},
+			}},
+			clients: &common.TestCloudClients{
+				RDSPerRegion: map[string]rdsiface.RDSAPI{
+					"us-east-1": &cloud.RDSMock{
+						DBInstances: []*rds.DBInstance{rdsInstance1, rdsInstanceStopped},
+						DBClusters:  []*rds.DBCluster{auroraCluster1, auroraClusterStopped},
+					},
+				},
+			},
+			expectedDatabases: types.Databases{rdsDatabase1, auroraDatabase1},
+		},
 		{
 			name: "skip access denied errors",
 			awsMatchers: []services.AWSMatcher{{
@@ -121,11 +162,43 @@ func TestWatcher(t *testing.T) {
 			},
 			expectedDatabases: types.Databases{rdsDatabase4, auroraDatabase1},
 		},
+		{
+			name: "azure labels matching",
+			azureMatchers: []services.AzureMatcher{{
+				Types:         []string{services.AzureMatcherMySQL},
+				Subscriptions: []string{"sub1"},
+				Regions:       []string{"eastus"},
+				Tags:          types.Labels{"env": []string{"prod"}},
+			}},
+			clients: &common.TestCloudClients{
+				AzureServers: &cloud.AzureServersMock{
+					Servers: []services.AzureDBServer{azureServer1, azureServer2, azureServer3, azureServerStopped},
+				},
+			},
+			expectedDatabases: types.Databases{azureDatabase1},
+		},
+		{
+			name: "cloud sql labels matching",
+			gcpMatchers: []services.GCPMatcher{{
+				Types:      []string{services.GCPMatcherCloudSQL},
+				ProjectIDs: []string{"project-1"},
+				Labels:     types.Labels{"env": []string{"prod"}},
+			}},
+			clients: &common.TestCloudClients{
+				GCPSQL: makeGCPSQLAdmin(t, cloudSQLInstance1, cloudSQLInstance2, cloudSQLInstanceStopped),
+			},
+			expectedDatabases: types.Databases{cloudSQLDatabase1},
+		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			watcher, err := NewWatcher(ctx, WatcherConfig{AWSMatchers: test.awsMatchers, Clients: test.clients})
+			watcher, err := NewWatcher(ctx, WatcherConfig{
+				AWSMatchers:   test.awsMatchers,
+				AzureMatchers: test.azureMatchers,
+				GCPMatchers:   test.gcpMatchers,
+				Clients:       test.clients,
+			})
 			require.NoError(t, err)
 
 			go watcher.fetchAndSend()
@@ -173,6 +246,59 @@ func makeRDSCluster(t *testing.T, name, region, engineMode string, labels map[st
 	return cluster, database
 }
 
+func makeAzureServer(t *testing.T, name, region string, labels map[string]string) (services.AzureDBServer, types.Database) {
+	server := services.AzureDBServer{
+		ID:       fmt.Sprintf("/subscriptions/sub1/resourceGroups/group-1/providers/Microsoft.DBforMySQL/flexibleServers/%v", name),
+		Name:     name,
+		Location: region,
+		Protocol: defaults.ProtocolMySQL,
+		Version:  "8.0.21",
+		FQDN:     fmt.Sprintf("%v.mysql.database.azure.com", name),
+		State:    "Ready",
+		Tags:     labels,
+	}
+	database, err := services.NewDatabaseFromAzureServer(&server)
+	require.NoError(t, err)
+	return server, database
+}
+
+func makeCloudSQLInstance(t *testing.T, name, project string, labels map[string]string) (*sqladmin.DatabaseInstance, types.Database) {
+	instance := &sqladmin.DatabaseInstance{
+		Name:            name,
+		Project:         project,
+		Region:          "us-central1",
+		DatabaseVersion: "POSTGRES_13",
+		State:           "RUNNABLE",
+		IpAddresses: []*sqladmin.IpMapping{{
+			Type:      "PRIMARY",
+			IpAddress: "localhost",
+		}},
+		Settings: &sqladmin.Settings{
+			UserLabels: labels,
+		},
+	}
+	database, err := services.NewDatabaseFromCloudSQLInstance(instance)
+	require.NoError(t, err)
+	return instance, database
+}
+
+// makeGCPSQLAdmin returns a Cloud SQL Admin API client backed by a fake
+// API server that serves the provided list of instances.
+func makeGCPSQLAdmin(t *testing.T, instances ...*sqladmin.DatabaseInstance) *sqladmin.Service {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := json.NewEncoder(w).Encode(&sqladmin.InstancesListResponse{
+			Items: instances,
+		})
+		require.NoError(t, err)
+	}))
+	t.Cleanup(server.Close)
+	service, err := sqladmin.NewService(context.Background(),
+		option.WithEndpoint(server.URL),
+		option.WithoutAuthentication())
+	require.NoError(t, err)
+	return service
+}
+
 func labelsToTags(labels map[string]string) (tags []*rds.Tag) {
 	for key, val := range labels {
 		tags = append(tags, &rds.Tag{
@@ -18,15 +18,41 @@ package common
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strings"
 
 	apidefaults "github.com/gravitational/teleport/api/defaults"
 	"github.com/gravitational/teleport/api/types/events"
+	"github.com/gravitational/teleport/lib/defaults"
 	libevents "github.com/gravitational/teleport/lib/events"
+	"github.com/gravitational/teleport/lib/utils"
 
 	"github.com/gravitational/trace"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
 )
 
+var (
+	dbSessionsStarted = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "db_audit_sessions_started",
+			Help: "Number of database sessions successfully started.",
+		},
+		[]string{"protocol"},
+	)
+
+	dbQueriesAudited = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "db_audit_queries",
+			Help: "Number of database queries and commands audited.",
+		},
+		[]string{"protocol"},
+	)
+
+	prometheusCollectors = []prometheus.Collector{dbSessionsStarted, dbQueriesAudited}
+)
+
 // Audit defines an interface for database access audit events logger.
 type Audit interface {
 	// OnSessionStart is called on successful/unsuccessful database session start.
@@ -55,6 +81,11 @@ type Query struct {
 type AuditConfig struct {
 	// Emitter is used to emit audit events.
 	Emitter events.Emitter
+	// RedactedAttributes are names of client connection attributes whose
+	// values are redacted from audit events. Names are matched as
+	// case-insensitive substrings. Defaults to defaultRedactedAttributes
+	// when empty.
+	RedactedAttributes []string
 }
 
 // Check validates the config.
@@ -62,9 +93,17 @@ func (c *AuditConfig) Check() error {
 	if c.Emitter == nil {
 		return trace.BadParameter("missing Emitter")
 	}
+	if len(c.RedactedAttributes) == 0 {
+		c.RedactedAttributes = defaultRedactedAttributes
+	}
 	return nil
 }
 
+// defaultRedactedAttributes are connection attribute names that commonly
+// carry secrets and are redacted unless the configuration overrides the
+// redaction list.
+var defaultRedactedAttributes = []string{"password", "passwd", "secret", "token"}
+
 // audit provides methods for emitting database access audit events.
 type audit struct {
 	// cfg is the audit events emitter configuration.
@@ -78,6 +117,9 @@ func NewAudit(config AuditConfig) (Audit, error) {
 	if err := config.Check(); err != nil {
 		return nil, trace.Wrap(err)
 	}
+	if err := utils.RegisterPrometheusCollectors(prometheusCollectors...); err != nil {
+		return nil, trace.Wrap(err)
+	}
 	return &audit{
 		cfg: config,
 		log: logrus.WithField(trace.Component, "db:audit"),
@@ -109,6 +151,56 @@ func (a *audit) OnSessionStart(ctx context.Context, session *Session, sessionErr
 		}
 	}
 	a.EmitEvent(ctx, event)
+	if sessionErr == nil {
+		dbSessionsStarted.WithLabelValues(session.Database.GetProtocol()).Inc()
+		if len(session.ConnectionAttributes) > 0 {
+			a.emitSessionAttributes(ctx, session)
+		}
+	}
+}
+
+// emitSessionAttributes emits an event with the connection attributes the
+// client reported during the handshake, such as the client program name
+// and driver version. The event reuses the database session start wire
+// message with the attributes formatted into the status user message and
+// values of sensitive attributes redacted.
+func (a *audit) emitSessionAttributes(ctx context.Context, session *Session) {
+	a.EmitEvent(ctx, &events.DatabaseSessionStart{
+		Metadata: MakeEventMetadata(session,
+			libevents.DatabaseSessionAttributesEvent,
+			libevents.DatabaseSessionAttributesCode),
+		ServerMetadata:   MakeServerMetadata(session),
+		UserMetadata:     MakeUserMetadata(session),
+		SessionMetadata:  MakeSessionMetadata(session),
+		DatabaseMetadata: MakeDatabaseMetadata(session),
+		Status: events.Status{
+			Success:     true,
+			UserMessage: formatConnectionAttributes(session.ConnectionAttributes, a.cfg.RedactedAttributes),
+		},
+	})
+}
+
+// formatConnectionAttributes formats connection attributes into a
+// deterministic name=value list, redacting values of attributes whose
+// name contains one of the redacted substrings.
+func formatConnectionAttributes(attributes map[string]string, redacted []string) string {
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		value := attributes[name]
+		for _, pattern := range redacted {
+			if strings.Contains(strings.ToLower(name), strings.ToLower(pattern)) {
+				value = "[redacted]"
+				break
+			}
+		}
+		parts = append(parts, fmt.Sprintf("%v=%v", name, value))
+	}
+	return strings.Join(parts, ", ")
 }
 
 // OnSessionEnd emits an audit event when database session ends.
@@ -135,6 +227,15 @@ func (a *audit) OnQuery(ctx context.Context, session *Session, query Query) {
 		DatabaseQuery:           query.Query,
 		DatabaseQueryParameters: query.Parameters,
 	}
+	// Only SQL protocols get a normalized fingerprint; other protocols
+	// put non-SQL commands through OnQuery.
+	switch session.Database.GetProtocol() {
+	case defaults.ProtocolPostgres, defaults.ProtocolMySQL:
+		info := NormalizeQuery(query.Query)
+		event.DatabaseQueryFingerprint = info.Fingerprint
+		event.DatabaseQueryStatementType = info.StatementType
+		event.DatabaseQueryTables = info.Tables
+	}
 	if query.Database != "" {
 		event.DatabaseMetadata.DatabaseName = query.Database
 	}
@@ -148,6 +249,7 @@ func (a *audit) OnQuery(ctx context.Context, session *Session, query Query) {
 		}
 	}
 	a.EmitEvent(ctx, event)
+	dbQueriesAudited.WithLabelValues(session.Database.GetProtocol()).Inc()
 }
 
 // EmitEvent emits the provided audit event using configured emitter.
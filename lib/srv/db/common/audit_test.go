@@ -0,0 +1,34 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatConnectionAttributes(t *testing.T) {
+	attributes := map[string]string{
+		"application_name": "psql",
+		"_client_version":  "8.0.27",
+		"aws_secret_key":   "hunter2",
+		"MyPassword":       "hunter2",
+	}
+	formatted := formatConnectionAttributes(attributes, defaultRedactedAttributes)
+	require.Equal(t, "MyPassword=[redacted], _client_version=8.0.27, application_name=psql, aws_secret_key=[redacted]", formatted)
+}
@@ -18,14 +18,22 @@ package common
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
 	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
 	"io"
+	"net/http"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/gravitational/teleport"
 	"github.com/gravitational/teleport/api/client/proto"
+	"github.com/gravitational/teleport/api/constants"
 	"github.com/gravitational/teleport/api/types"
 	libauth "github.com/gravitational/teleport/lib/auth"
 	"github.com/gravitational/teleport/lib/auth/native"
@@ -36,6 +44,8 @@ import (
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
 	"github.com/aws/aws-sdk-go/service/rds/rdsutils"
 	"github.com/aws/aws-sdk-go/service/redshift"
 
@@ -51,14 +61,26 @@ import (
 type Auth interface {
 	// GetRDSAuthToken generates RDS/Aurora auth token.
 	GetRDSAuthToken(sessionCtx *Session) (string, error)
+	// GetAWSCredentials returns AWS credentials for signing requests to
+	// AWS-hosted databases.
+	GetAWSCredentials(sessionCtx *Session) (*credentials.Credentials, error)
 	// GetRedshiftAuthToken generates Redshift auth token.
 	GetRedshiftAuthToken(sessionCtx *Session) (string, string, error)
+	// GetRedshiftServerlessAuthToken generates Redshift Serverless auth token.
+	GetRedshiftServerlessAuthToken(sessionCtx *Session) (string, string, error)
 	// GetCloudSQLAuthToken generates Cloud SQL auth token.
 	GetCloudSQLAuthToken(ctx context.Context, sessionCtx *Session) (string, error)
 	// GetCloudSQLPassword generates password for a Cloud SQL database user.
 	GetCloudSQLPassword(ctx context.Context, sessionCtx *Session) (string, error)
+	// GetAlloyDBAuthToken generates AlloyDB auth token.
+	GetAlloyDBAuthToken(ctx context.Context, sessionCtx *Session) (string, error)
+	// GetElastiCacheRedisToken generates an ElastiCache/MemoryDB Redis IAM auth token.
+	GetElastiCacheRedisToken(ctx context.Context, sessionCtx *Session) (string, error)
 	// GetAzureAccessToken generates Azure database access token.
 	GetAzureAccessToken(ctx context.Context, sessionCtx *Session) (string, error)
+	// GetAzureSQLAccessToken generates an Azure AD access token for
+	// connecting to Azure SQL databases.
+	GetAzureSQLAccessToken(ctx context.Context, sessionCtx *Session) (string, error)
 	// GetTLSConfig builds the client TLS configuration for the session.
 	GetTLSConfig(ctx context.Context, sessionCtx *Session) (*tls.Config, error)
 	// GetAuthPreference returns the cluster authentication config.
@@ -100,6 +122,28 @@ func (c *AuthConfig) CheckAndSetDefaults() error {
 // generating auth tokens when connecting to databases.
 type dbAuth struct {
 	cfg AuthConfig
+	// closeCtx is canceled when the authenticator is closed.
+	closeCtx context.Context
+	// closeFunc cancels closeCtx.
+	closeFunc context.CancelFunc
+	// mtx protects clientCerts.
+	mtx sync.RWMutex
+	// clientCerts caches ephemeral client certificates per database user
+	// to avoid a signing roundtrip on each connection. The cache is
+	// flushed when the cluster cert authority is rotated so new
+	// connections pick up certificates issued by the new authority.
+	clientCerts map[string]*cachedClientCert
+}
+
+// cachedClientCert is a cached ephemeral client certificate.
+type cachedClientCert struct {
+	// cert is the client certificate with the private key.
+	cert *tls.Certificate
+	// cas is PEM-encoded CA certificates returned alongside the client
+	// certificate.
+	cas [][]byte
+	// expires is the certificate expiration time.
+	expires time.Time
 }
 
 // NewAuth returns a new instance of database access authenticator.
@@ -107,9 +151,25 @@ func NewAuth(config AuthConfig) (Auth, error) {
 	if err := config.CheckAndSetDefaults(); err != nil {
 		return nil, trace.Wrap(err)
 	}
-	return &dbAuth{
-		cfg: config,
-	}, nil
+	closeCtx, closeFunc := context.WithCancel(context.Background())
+	auth := &dbAuth{
+		cfg:         config,
+		closeCtx:    closeCtx,
+		closeFunc:   closeFunc,
+		clientCerts: make(map[string]*cachedClientCert),
+	}
+	go auth.watchCARotations()
+	return auth, nil
+}
+
+// GetAWSCredentials returns AWS credentials used for signing requests to
+// AWS-hosted databases in the database's region.
+func (a *dbAuth) GetAWSCredentials(sessionCtx *Session) (*credentials.Credentials, error) {
+	awsSession, err := a.cfg.Clients.GetAWSSession(sessionCtx.Database.GetAWS().Region)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return awsSession.Config.Credentials, nil
 }
 
 // GetRDSAuthToken returns authorization token that will be used as a password
@@ -173,6 +233,33 @@ propagate):
 	return *resp.DbUser, *resp.DbPassword, nil
 }
 
+// GetRedshiftServerlessAuthToken returns authorization token that will be used
+// as a password when connecting to Redshift Serverless databases.
+func (a *dbAuth) GetRedshiftServerlessAuthToken(sessionCtx *Session) (string, string, error) {
+	client, err := a.cfg.Clients.GetAWSRedshiftServerlessClient(sessionCtx.Database.GetAWS().Region)
+	if err != nil {
+		return "", "", trace.Wrap(err)
+	}
+	a.cfg.Log.Debugf("Generating Redshift Serverless auth token for %s.", sessionCtx)
+	resp, err := client.GetCredentials(&RedshiftServerlessGetCredentialsInput{
+		WorkgroupName: aws.String(sessionCtx.Database.GetRedshiftServerlessWorkgroupName()),
+		DbName:        aws.String(sessionCtx.DatabaseName),
+	})
+	if err != nil {
+		return "", "", trace.AccessDenied(`Could not generate Redshift Serverless IAM auth token:
+
+  %v
+
+Make sure that Teleport database agent's IAM policy is attached and has permissions
+to generate Redshift Serverless credentials (note that IAM changes may take a few
+minutes to propagate):
+
+%v
+`, err, sessionCtx.Database.GetIAMPolicy())
+	}
+	return aws.StringValue(resp.DbUser), aws.StringValue(resp.DbPassword), nil
+}
+
 // GetCloudSQLAuthToken returns authorization token that will be used as a
 // password when connecting to Cloud SQL databases.
 func (a *dbAuth) GetCloudSQLAuthToken(ctx context.Context, sessionCtx *Session) (string, error) {
@@ -211,6 +298,102 @@ or "iam.serviceAccounts.getAccessToken" IAM permission.
 	return resp.AccessToken, nil
 }
 
+// GetElastiCacheRedisToken returns an IAM auth token that will be used as
+// a password when authenticating to ElastiCache or MemoryDB Redis with the
+// AUTH command.
+//
+// The token is a SigV4-presigned "connect" request for the database user
+// and the replication group, valid for 15 minutes, with the scheme
+// stripped:
+//
+// https://docs.aws.amazon.com/AmazonElastiCache/latest/red-ug/auth-iam.html
+func (a *dbAuth) GetElastiCacheRedisToken(ctx context.Context, sessionCtx *Session) (string, error) {
+	awsSession, err := a.cfg.Clients.GetAWSSession(sessionCtx.Database.GetAWS().Region)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	clusterName, err := elastiCacheClusterName(sessionCtx.Database.GetURI())
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	serviceName := "elasticache"
+	if sessionCtx.Database.IsMemoryDB() {
+		serviceName = "memorydb"
+	}
+	a.cfg.Log.Debugf("Generating %v Redis auth token for %s.", serviceName, sessionCtx)
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://%s/", clusterName), nil)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	query := req.URL.Query()
+	query.Set("Action", "connect")
+	query.Set("User", sessionCtx.DatabaseUser)
+	req.URL.RawQuery = query.Encode()
+	signer := v4.NewSigner(awsSession.Config.Credentials)
+	_, err = signer.Presign(req, nil, serviceName, sessionCtx.Database.GetAWS().Region, 15*time.Minute, a.cfg.Clock.Now())
+	if err != nil {
+		return "", trace.AccessDenied(`Could not generate ElastiCache IAM auth token:
+
+  %v
+
+Make sure that Teleport database agent's IAM policy allows the
+"elasticache:Connect" action for the replication group and user (note that
+IAM changes may take a few minutes to propagate).
+`, err)
+	}
+	return strings.TrimPrefix(req.URL.String(), "http://"), nil
+}
+
+// elastiCacheClusterName extracts the replication group or cluster name
+// from the provided ElastiCache or MemoryDB endpoint, which is encoded as
+// the second hostname label, e.g.
+// master.my-redis.abcdef.use1.cache.amazonaws.com.
+func elastiCacheClusterName(uri string) (string, error) {
+	addr, err := utils.ParseAddr(uri)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	parts := strings.Split(addr.Host(), ".")
+	if len(parts) < 3 {
+		return "", trace.BadParameter("failed to parse %v as ElastiCache endpoint", uri)
+	}
+	return parts[1], nil
+}
+
+// GetAlloyDBAuthToken returns authorization token that will be used as a
+// password when connecting to AlloyDB databases.
+func (a *dbAuth) GetAlloyDBAuthToken(ctx context.Context, sessionCtx *Session) (string, error) {
+	gcpIAM, err := a.cfg.Clients.GetGCPIAMClient(ctx)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	a.cfg.Log.Debugf("Generating GCP AlloyDB auth token for %s.", sessionCtx)
+	resp, err := gcpIAM.GenerateAccessToken(ctx,
+		&gcpcredentialspb.GenerateAccessTokenRequest{
+			// From GenerateAccessToken docs:
+			//
+			// The resource name of the service account for which the credentials
+			// are requested, in the following format:
+			//   projects/-/serviceAccounts/{ACCOUNT_EMAIL_OR_UNIQUEID}
+			Name: fmt.Sprintf("projects/-/serviceAccounts/%v.gserviceaccount.com", sessionCtx.DatabaseUser),
+			// AlloyDB uses a dedicated OAuth scope for database logins:
+			//   https://developers.google.com/identity/protocols/oauth2/scopes
+			Scope: []string{
+				"https://www.googleapis.com/auth/alloydb.login",
+			},
+		})
+	if err != nil {
+		return "", trace.AccessDenied(`Could not generate GCP IAM auth token:
+
+  %v
+
+Make sure Teleport db service has "Service Account Token Creator" GCP IAM role,
+or "iam.serviceAccounts.getAccessToken" IAM permission.
+`, err)
+	}
+	return resp.AccessToken, nil
+}
+
 // GetCloudSQLPassword updates the specified database user's password to a
 // random value using GCP Cloud SQL Admin API.
 //
@@ -287,6 +470,27 @@ func (a *dbAuth) GetAzureAccessToken(ctx context.Context, sessionCtx *Session) (
 	return token.Token, nil
 }
 
+// GetAzureSQLAccessToken returns an Azure AD access token used to log into
+// Azure SQL Database and Azure SQL Managed Instance via the TDS federated
+// authentication extension.
+func (a *dbAuth) GetAzureSQLAccessToken(ctx context.Context, sessionCtx *Session) (string, error) {
+	a.cfg.Log.Debugf("Generating Azure SQL access token for %s.", sessionCtx)
+	cred, err := a.cfg.Clients.GetAzureCredential()
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	token, err := cred.GetToken(ctx, policy.TokenRequestOptions{
+		Scopes: []string{
+			// Access token scope for connecting to Azure SQL databases.
+			"https://database.windows.net/.default",
+		},
+	})
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	return token.Token, nil
+}
+
 // GetTLSConfig builds the client TLS configuration for the session.
 //
 // For RDS/Aurora, the config must contain RDS root certificate as a trusted
@@ -323,6 +527,13 @@ func (a *dbAuth) getTLSConfigVerifyFull(ctx context.Context, sessionCtx *Session
 			return nil, trace.Wrap(err)
 		}
 		tlsConfig.ServerName = addr.Host()
+		// RDS Proxy serves a wildcard certificate for the proxy's default
+		// domain which hostnames of custom endpoints don't validate
+		// against, so verify the certificate against the equivalent name
+		// under the default domain instead.
+		if sessionCtx.Database.IsRDSProxy() {
+			tlsConfig.ServerName = rdsProxyServerName(addr.Host())
+		}
 	}
 
 	// Add CA certificate to the trusted pool if it's present, e.g. when
@@ -332,6 +543,13 @@ func (a *dbAuth) getTLSConfigVerifyFull(ctx context.Context, sessionCtx *Session
 		return nil, trace.Wrap(err)
 	}
 
+	// ElastiCache and MemoryDB server certificates are issued by public
+	// certificate authorities so fall back to the system cert pool when
+	// no CA was configured explicitly.
+	if len(sessionCtx.Database.GetCA()) == 0 && (sessionCtx.Database.IsElastiCache() || sessionCtx.Database.IsMemoryDB()) {
+		tlsConfig.RootCAs = nil
+	}
+
 	// You connect to Cloud SQL instances by IP and the certificate presented
 	// by the instance does not contain IP SANs so the default "full" certificate
 	// verification will always fail.
@@ -367,8 +585,24 @@ func (a *dbAuth) getTLSConfigVerifyFull(ctx context.Context, sessionCtx *Session
 		tlsConfig.ServerName = dbTLSConfig.ServerName
 	}
 
-	// RDS/Aurora/Redshift and Cloud SQL auth is done with an auth token so
-	// don't generate a client certificate and exit here.
+	// Cloud SQL instances can be configured to require client certificates,
+	// in which case we request an ephemeral certificate from the Cloud SQL
+	// Connect API signed by the instance's server CA. This also allows
+	// connecting to instances that only have a private IP address without
+	// having to run the Cloud SQL proxy alongside the agent.
+	if sessionCtx.Database.IsCloudSQL() {
+		requireSSL, err := a.getCloudSQLRequireSSL(ctx, sessionCtx)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		if requireSSL {
+			return a.appendGCPClientCert(ctx, sessionCtx, tlsConfig)
+		}
+		return tlsConfig, nil
+	}
+
+	// RDS/Aurora/Redshift auth is done with an auth token so don't generate
+	// a client certificate and exit here.
 	if sessionCtx.Database.IsCloudHosted() {
 		return tlsConfig, nil
 	}
@@ -379,6 +613,19 @@ func (a *dbAuth) getTLSConfigVerifyFull(ctx context.Context, sessionCtx *Session
 	return a.appendClientCert(ctx, sessionCtx, tlsConfig)
 }
 
+// rdsProxyServerName returns the TLS server name to verify the RDS Proxy
+// certificate against. RDS Proxy custom endpoints
+// (<name>.endpoint.proxy-<id>.<region>.rds.amazonaws.com) carry an extra
+// "endpoint" label that the wildcard certificate of the proxy's default
+// domain doesn't cover, so the label is dropped from the verified name.
+func rdsProxyServerName(host string) string {
+	parts := strings.Split(host, ".")
+	if len(parts) == 7 && parts[1] == "endpoint" {
+		return strings.Join(append(parts[:1:1], parts[2:]...), ".")
+	}
+	return host
+}
+
 // getTLSConfigInsecure generates tls.Config when TLS mode is equal to 'insecure'.
 // Generated configuration will accept any certificate provided by database.
 func getTLSConfigInsecure() *tls.Config {
@@ -428,6 +675,78 @@ func (a *dbAuth) appendClientCert(ctx context.Context, sessionCtx *Session, tlsC
 	return tlsConfig, nil
 }
 
+// getCloudSQLRequireSSL determines whether the Cloud SQL instance the
+// session is connecting to is set to require SSL (client certificates).
+//
+// When the instance settings can't be retrieved (e.g. the agent's service
+// account is missing "cloudsql.instances.get" permission), assume client
+// certificates are not required rather than failing the connection - the
+// TLS handshake with the instance will fail anyway if they are.
+func (a *dbAuth) getCloudSQLRequireSSL(ctx context.Context, sessionCtx *Session) (bool, error) {
+	gcpCloudSQL, err := a.cfg.Clients.GetGCPSQLAdminClient(ctx)
+	if err != nil {
+		return false, trace.Wrap(err)
+	}
+	instance, err := gcpCloudSQL.Instances.Get(
+		sessionCtx.Database.GetGCP().ProjectID,
+		sessionCtx.Database.GetGCP().InstanceID).Context(ctx).Do()
+	if err != nil {
+		a.cfg.Log.WithError(err).Debugf("Could not retrieve Cloud SQL instance settings for %s, assuming client certificates are not required.", sessionCtx)
+		return false, nil
+	}
+	return instance.Settings != nil &&
+		instance.Settings.IpConfiguration != nil &&
+		instance.Settings.IpConfiguration.RequireSsl, nil
+}
+
+// appendGCPClientCert calls the Cloud SQL Connect API to generate an
+// ephemeral client certificate signed by the instance's server CA and
+// appends it to the provided tlsConfig.
+func (a *dbAuth) appendGCPClientCert(ctx context.Context, sessionCtx *Session, tlsConfig *tls.Config) (*tls.Config, error) {
+	gcpCloudSQL, err := a.cfg.Clients.GetGCPSQLAdminClient(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	// TODO(r0mant): Cache the ephemeral certificate for the duration of its
+	// validity to avoid a roundtrip to the API on each connection.
+	privateKey, err := rsa.GenerateKey(rand.Reader, constants.RSAKeySize)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	publicKey, err := x509.MarshalPKIXPublicKey(privateKey.Public())
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	a.cfg.Log.Debugf("Generating GCP ephemeral certificate for %s.", sessionCtx)
+	resp, err := gcpCloudSQL.SslCerts.CreateEphemeral(
+		sessionCtx.Database.GetGCP().ProjectID,
+		sessionCtx.Database.GetGCP().InstanceID,
+		&sqladmin.SslCertsCreateEphemeralRequest{
+			PublicKey: string(pem.EncodeToMemory(&pem.Block{
+				Type:  "RSA PUBLIC KEY",
+				Bytes: publicKey,
+			})),
+		}).Context(ctx).Do()
+	if err != nil {
+		return nil, trace.AccessDenied(`Could not generate Cloud SQL ephemeral client certificate:
+
+  %v
+
+Make sure Teleport db service has "Cloud SQL Admin" GCP IAM role, or
+"cloudsql.sslCerts.createEphemeral" IAM permission.
+`, err)
+	}
+	clientCert, err := tls.X509KeyPair([]byte(resp.Cert), pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+	}))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	tlsConfig.Certificates = []tls.Certificate{clientCert}
+	return tlsConfig, nil
+}
+
 // appendCAToRoot appends CA certificate from session context to provided tlsConfig.
 func appendCAToRoot(tlsConfig *tls.Config, sessionCtx *Session) (*tls.Config, error) {
 	if len(sessionCtx.Database.GetCA()) != 0 {
@@ -467,6 +786,12 @@ func verifyConnectionFunc(rootCAs *x509.CertPool) func(cs tls.ConnectionState) e
 // getClientCert signs an ephemeral client certificate used by this
 // server to authenticate with the database instance.
 func (a *dbAuth) getClientCert(ctx context.Context, sessionCtx *Session) (cert *tls.Certificate, cas [][]byte, err error) {
+	// Reuse a cached certificate if it's present and still valid to avoid
+	// an expensive signing operation on each connection.
+	if cached := a.getCachedClientCert(sessionCtx.DatabaseUser); cached != nil {
+		a.cfg.Log.Debugf("Reusing cached client certificate for %s.", sessionCtx)
+		return cached.cert, cached.cas, nil
+	}
 	privateBytes, _, err := native.GenerateKeyPair("")
 	if err != nil {
 		return nil, nil, trace.Wrap(err)
@@ -478,8 +803,6 @@ func (a *dbAuth) getClientCert(ctx context.Context, sessionCtx *Session) (cert *
 	if err != nil {
 		return nil, nil, trace.Wrap(err)
 	}
-	// TODO(r0mant): Cache database certificates to avoid expensive generate
-	// operation on each connection.
 	a.cfg.Log.Debugf("Generating client certificate for %s.", sessionCtx)
 	resp, err := a.cfg.AuthClient.GenerateDatabaseCert(ctx, &proto.DatabaseCertRequest{
 		CSR: csr,
@@ -492,9 +815,116 @@ func (a *dbAuth) getClientCert(ctx context.Context, sessionCtx *Session) (cert *
 	if err != nil {
 		return nil, nil, trace.Wrap(err)
 	}
+	a.setCachedClientCert(sessionCtx.DatabaseUser, &cachedClientCert{
+		cert:    &clientCert,
+		cas:     resp.CACerts,
+		expires: sessionCtx.Identity.Expires,
+	})
 	return &clientCert, resp.CACerts, nil
 }
 
+// getCachedClientCert returns the cached client certificate for the provided
+// database user, or nil if there isn't one or it's about to expire.
+func (a *dbAuth) getCachedClientCert(databaseUser string) *cachedClientCert {
+	a.mtx.RLock()
+	defer a.mtx.RUnlock()
+	entry, ok := a.clientCerts[databaseUser]
+	if !ok {
+		return nil
+	}
+	// Leave a margin so the certificate doesn't expire in the middle of
+	// the TLS handshake.
+	if !a.cfg.Clock.Now().Add(time.Minute).Before(entry.expires) {
+		return nil
+	}
+	return entry
+}
+
+// setCachedClientCert caches the client certificate for the provided
+// database user.
+func (a *dbAuth) setCachedClientCert(databaseUser string, entry *cachedClientCert) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	a.clientCerts[databaseUser] = entry
+}
+
+// watchCARotations watches cert authority update events and flushes the
+// cached client certificates when the cluster cert authority is rotated so
+// certificates for new connections are issued by the new authority.
+// Established sessions are not affected as their handshakes have already
+// completed.
+func (a *dbAuth) watchCARotations() {
+	retry, err := utils.NewLinear(utils.LinearConfig{
+		First:  utils.HalfJitter(defaults.MaxWatcherBackoff / 10),
+		Step:   defaults.MaxWatcherBackoff / 5,
+		Max:    defaults.MaxWatcherBackoff,
+		Jitter: utils.NewHalfJitter(),
+	})
+	if err != nil {
+		a.cfg.Log.WithError(err).Error("Failed to create CA rotation watcher retry.")
+		return
+	}
+	for {
+		err := a.watchCARotationsOnce(retry)
+		if err != nil {
+			a.cfg.Log.WithError(err).Warn("Restarting CA rotation watcher on error.")
+		}
+		select {
+		case <-retry.After():
+			retry.Inc()
+		case <-a.closeCtx.Done():
+			return
+		}
+	}
+}
+
+// watchCARotationsOnce creates a cert authority watcher and processes its
+// events until the watcher or the authenticator is closed.
+func (a *dbAuth) watchCARotationsOnce(retry utils.Retry) error {
+	watcher, err := a.cfg.AuthClient.NewWatcher(a.closeCtx, types.Watch{
+		Name: teleport.ComponentDatabase,
+		Kinds: []types.WatchKind{
+			{Kind: types.KindCertAuthority, LoadSecrets: false},
+		},
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer watcher.Close()
+	for {
+		select {
+		case event := <-watcher.Events():
+			if event.Type == types.OpInit {
+				retry.Reset()
+				continue
+			}
+			a.processCAEvent(event)
+		case <-watcher.Done():
+			return trace.Wrap(watcher.Error())
+		case <-a.closeCtx.Done():
+			return nil
+		}
+	}
+}
+
+// processCAEvent flushes the cached client certificates when the cluster's
+// host cert authority is updated, e.g. during rotation.
+func (a *dbAuth) processCAEvent(event types.Event) {
+	if event.Type != types.OpPut {
+		return
+	}
+	ca, ok := event.Resource.(types.CertAuthority)
+	if !ok || ca.GetType() != types.HostCA {
+		return
+	}
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	if len(a.clientCerts) != 0 {
+		a.cfg.Log.Infof("Cert authority %v was updated, flushing cached client certificates.", ca.GetName())
+		a.clientCerts = make(map[string]*cachedClientCert)
+	}
+}
+
 // GetAuthPreference returns the cluster authentication config.
 func (a *dbAuth) GetAuthPreference(ctx context.Context) (types.AuthPreference, error) {
 	return a.cfg.AuthClient.GetAuthPreference(ctx)
@@ -502,6 +932,7 @@ func (a *dbAuth) GetAuthPreference(ctx context.Context) (types.AuthPreference, e
 
 // Close releases all resources used by authenticator.
 func (a *dbAuth) Close() error {
+	a.closeFunc()
 	return a.cfg.Clients.Close()
 }
 
@@ -0,0 +1,182 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+
+	"github.com/gravitational/teleport/lib/defaults"
+	"github.com/gravitational/teleport/lib/services"
+
+	"github.com/gravitational/trace"
+)
+
+// AzureServersClient provides access to Azure database server APIs within
+// a subscription.
+type AzureServersClient interface {
+	// ListDBServers returns all database flexible servers of the given
+	// protocol, "mysql" or "postgres", within the client's subscription.
+	ListDBServers(ctx context.Context, protocol string) ([]services.AzureDBServer, error)
+}
+
+const (
+	// azureManagementEndpoint is the Azure Resource Manager endpoint.
+	azureManagementEndpoint = "https://management.azure.com"
+	// azureManagementScope is the token scope for Azure Resource Manager.
+	azureManagementScope = "https://management.azure.com/.default"
+	// azureMySQLAPIPath is the resource provider path for MySQL flexible servers.
+	azureMySQLAPIPath = "providers/Microsoft.DBforMySQL/flexibleServers"
+	// azureMySQLAPIVersion is the MySQL flexible servers API version.
+	azureMySQLAPIVersion = "2021-05-01"
+	// azurePostgresAPIPath is the resource provider path for PostgreSQL flexible servers.
+	azurePostgresAPIPath = "providers/Microsoft.DBforPostgreSQL/flexibleServers"
+	// azurePostgresAPIVersion is the PostgreSQL flexible servers API version.
+	azurePostgresAPIVersion = "2021-06-01"
+)
+
+// azureServersClient implements AzureServersClient on top of the Azure
+// Resource Manager REST API. The azcore SDK module doesn't come with
+// management plane clients so the list calls are made directly.
+type azureServersClient struct {
+	// subscriptionID is the Azure subscription to query servers in.
+	subscriptionID string
+	// credential is used to obtain Azure Resource Manager tokens.
+	credential azcore.TokenCredential
+	// client is the HTTP client used for Azure Resource Manager calls.
+	client *http.Client
+}
+
+// NewAzureServersClient returns an Azure database servers client for the
+// provided subscription using the provided credential.
+func NewAzureServersClient(subscriptionID string, credential azcore.TokenCredential) AzureServersClient {
+	return &azureServersClient{
+		subscriptionID: subscriptionID,
+		credential:     credential,
+		client:         http.DefaultClient,
+	}
+}
+
+// ListDBServers returns all database flexible servers of the given protocol
+// within the client's subscription.
+func (c *azureServersClient) ListDBServers(ctx context.Context, protocol string) ([]services.AzureDBServer, error) {
+	var apiPath, apiVersion string
+	switch protocol {
+	case defaults.ProtocolMySQL:
+		apiPath, apiVersion = azureMySQLAPIPath, azureMySQLAPIVersion
+	case defaults.ProtocolPostgres:
+		apiPath, apiVersion = azurePostgresAPIPath, azurePostgresAPIVersion
+	default:
+		return nil, trace.BadParameter("unsupported Azure database protocol %q", protocol)
+	}
+	token, err := c.credential.GetToken(ctx, policy.TokenRequestOptions{
+		Scopes: []string{azureManagementScope},
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var servers []services.AzureDBServer
+	url := fmt.Sprintf("%v/subscriptions/%v/%v?api-version=%v",
+		azureManagementEndpoint, c.subscriptionID, apiPath, apiVersion)
+	for url != "" {
+		page, err := c.getServersPage(ctx, url, token.Token)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		for _, server := range page.Value {
+			servers = append(servers, services.AzureDBServer{
+				ID:       server.ID,
+				Name:     server.Name,
+				Location: server.Location,
+				Protocol: protocol,
+				Version:  server.Properties.Version,
+				FQDN:     server.Properties.FullyQualifiedDomainName,
+				State:    server.Properties.State,
+				Tags:     server.Tags,
+			})
+		}
+		url = page.NextLink
+	}
+	return servers, nil
+}
+
+// getServersPage fetches a single page of the server list results.
+func (c *azureServersClient) getServersPage(ctx context.Context, url, token string) (*azureServersPage, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	request.Header.Set("Authorization", fmt.Sprintf("Bearer %v", token))
+	response, err := c.client.Do(request)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(response.Body, maxAzureResponseSize))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, trace.ReadError(response.StatusCode, body)
+	}
+	var page azureServersPage
+	if err := json.Unmarshal(body, &page); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &page, nil
+}
+
+// azureServersPage is a single page of the ARM server list response.
+type azureServersPage struct {
+	// Value is the list of servers on this page.
+	Value []azureServer `json:"value"`
+	// NextLink is the URL of the next results page, if any.
+	NextLink string `json:"nextLink"`
+}
+
+// azureServer is the ARM representation of a database flexible server.
+type azureServer struct {
+	// ID is the fully qualified resource ID.
+	ID string `json:"id"`
+	// Name is the server name.
+	Name string `json:"name"`
+	// Location is the Azure region the server is in.
+	Location string `json:"location"`
+	// Tags is the server's resource tags.
+	Tags map[string]string `json:"tags"`
+	// Properties is the server properties.
+	Properties azureServerProperties `json:"properties"`
+}
+
+// azureServerProperties is the ARM server properties.
+type azureServerProperties struct {
+	// FullyQualifiedDomainName is the domain name of the server.
+	FullyQualifiedDomainName string `json:"fullyQualifiedDomainName"`
+	// Version is the database engine version.
+	Version string `json:"version"`
+	// State is the server lifecycle state.
+	State string `json:"state"`
+}
+
+// maxAzureResponseSize is the maximum accepted size of an ARM API response.
+const maxAzureResponseSize = 10 * 1024 * 1024 // 10MB
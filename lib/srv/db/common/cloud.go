@@ -51,6 +51,9 @@ type CloudClients interface {
 	GetAWSRDSClient(region string) (rdsiface.RDSAPI, error)
 	// GetAWSRedshiftClient returns AWS Redshift client for the specified region.
 	GetAWSRedshiftClient(region string) (redshiftiface.RedshiftAPI, error)
+	// GetAWSRedshiftServerlessClient returns AWS Redshift Serverless client
+	// for the specified region.
+	GetAWSRedshiftServerlessClient(region string) (RedshiftServerlessClient, error)
 	// GetAWSIAMClient returns AWS IAM client for the specified region.
 	GetAWSIAMClient(region string) (iamiface.IAMAPI, error)
 	// GetAWSSTSClient returns AWS STS client for the specified region.
@@ -61,6 +64,9 @@ type CloudClients interface {
 	GetGCPSQLAdminClient(context.Context) (*sqladmin.Service, error)
 	// GetAzureCredential returns Azure default token credential chain.
 	GetAzureCredential() (azcore.TokenCredential, error)
+	// GetAzureServersClient returns Azure database servers client for the
+	// specified subscription.
+	GetAzureServersClient(subscriptionID string) (AzureServersClient, error)
 	// Closer closes all initialized clients.
 	io.Closer
 }
@@ -68,7 +74,8 @@ type CloudClients interface {
 // NewCloudClients returns a new instance of cloud clients retriever.
 func NewCloudClients() CloudClients {
 	return &cloudClients{
-		awsSessions: make(map[string]*awssession.Session),
+		awsSessions:  make(map[string]*awssession.Session),
+		azureServers: make(map[string]AzureServersClient),
 	}
 }
 
@@ -81,6 +88,8 @@ type cloudClients struct {
 	gcpSQLAdmin *sqladmin.Service
 	// azureCredential is the cached Azure credential.
 	azureCredential azcore.TokenCredential
+	// azureServers is a map of cached Azure database servers clients per subscription.
+	azureServers map[string]AzureServersClient
 	// mtx is used for locking.
 	mtx sync.RWMutex
 }
@@ -114,6 +123,16 @@ func (c *cloudClients) GetAWSRedshiftClient(region string) (redshiftiface.Redshi
 	return redshift.New(session), nil
 }
 
+// GetAWSRedshiftServerlessClient returns AWS Redshift Serverless client for
+// the specified region.
+func (c *cloudClients) GetAWSRedshiftServerlessClient(region string) (RedshiftServerlessClient, error) {
+	session, err := c.GetAWSSession(region)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return NewRedshiftServerlessClient(session), nil
+}
+
 // GetAWSIAMClient returns AWS IAM client for the specified region.
 func (c *cloudClients) GetAWSIAMClient(region string) (iamiface.IAMAPI, error) {
 	session, err := c.GetAWSSession(region)
@@ -226,6 +245,34 @@ func (c *cloudClients) initGCPSQLAdminClient(ctx context.Context) (*sqladmin.Ser
 	return gcpSQLAdmin, nil
 }
 
+// GetAzureServersClient returns Azure database servers client for the
+// specified subscription.
+func (c *cloudClients) GetAzureServersClient(subscriptionID string) (AzureServersClient, error) {
+	c.mtx.RLock()
+	if client, ok := c.azureServers[subscriptionID]; ok {
+		c.mtx.RUnlock()
+		return client, nil
+	}
+	c.mtx.RUnlock()
+	return c.initAzureServersClient(subscriptionID)
+}
+
+func (c *cloudClients) initAzureServersClient(subscriptionID string) (AzureServersClient, error) {
+	cred, err := c.GetAzureCredential()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if client, ok := c.azureServers[subscriptionID]; ok { // If some other thread already got here first.
+		return client, nil
+	}
+	logrus.Debugf("Initializing Azure database servers client for subscription %v.", subscriptionID)
+	client := NewAzureServersClient(subscriptionID, cred)
+	c.azureServers[subscriptionID] = client
+	return client, nil
+}
+
 func (c *cloudClients) initAzureCredential() (azcore.TokenCredential, error) {
 	c.mtx.Lock()
 	defer c.mtx.Unlock()
@@ -243,11 +290,14 @@ func (c *cloudClients) initAzureCredential() (azcore.TokenCredential, error) {
 
 // TestCloudClients are used in tests.
 type TestCloudClients struct {
-	RDS          rdsiface.RDSAPI
-	RDSPerRegion map[string]rdsiface.RDSAPI
-	Redshift     redshiftiface.RedshiftAPI
-	IAM          iamiface.IAMAPI
-	STS          stsiface.STSAPI
+	RDS                rdsiface.RDSAPI
+	RDSPerRegion       map[string]rdsiface.RDSAPI
+	Redshift           redshiftiface.RedshiftAPI
+	RedshiftServerless RedshiftServerlessClient
+	IAM                iamiface.IAMAPI
+	STS                stsiface.STSAPI
+	GCPSQL             *sqladmin.Service
+	AzureServers       AzureServersClient
 }
 
 // GetAWSSession returns AWS session for the specified region.
@@ -268,6 +318,12 @@ func (c *TestCloudClients) GetAWSRedshiftClient(region string) (redshiftiface.Re
 	return c.Redshift, nil
 }
 
+// GetAWSRedshiftServerlessClient returns AWS Redshift Serverless client for
+// the specified region.
+func (c *TestCloudClients) GetAWSRedshiftServerlessClient(region string) (RedshiftServerlessClient, error) {
+	return c.RedshiftServerless, nil
+}
+
 // GetAWSIAMClient returns AWS IAM client for the specified region.
 func (c *TestCloudClients) GetAWSIAMClient(region string) (iamiface.IAMAPI, error) {
 	return c.IAM, nil
@@ -287,6 +343,9 @@ func (c *TestCloudClients) GetGCPIAMClient(ctx context.Context) (*gcpcredentials
 
 // GetGCPSQLAdminClient returns GCP Cloud SQL Admin client.
 func (c *TestCloudClients) GetGCPSQLAdminClient(ctx context.Context) (*sqladmin.Service, error) {
+	if c.GCPSQL != nil {
+		return c.GCPSQL, nil
+	}
 	return sqladmin.NewService(ctx,
 		option.WithGRPCDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())), // Insecure must be set for unauth client.
 		option.WithoutAuthentication())
@@ -297,6 +356,12 @@ func (c *TestCloudClients) GetAzureCredential() (azcore.TokenCredential, error)
 	return &azidentity.ChainedTokenCredential{}, nil
 }
 
+// GetAzureServersClient returns Azure database servers client for the
+// specified subscription.
+func (c *TestCloudClients) GetAzureServersClient(subscriptionID string) (AzureServersClient, error) {
+	return c.AzureServers, nil
+}
+
 // Close closes all initialized clients.
 func (c *TestCloudClients) Close() error {
 	return nil
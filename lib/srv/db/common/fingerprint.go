@@ -0,0 +1,162 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"strings"
+	"unicode"
+)
+
+// QueryInfo is the result of normalizing a SQL query for audit purposes.
+type QueryInfo struct {
+	// Fingerprint is the query with literal values replaced by "?",
+	// comments dropped and whitespace collapsed, so invocations of the
+	// same statement with different values produce the same string.
+	Fingerprint string
+	// StatementType is the leading keyword of the statement in upper
+	// case, e.g. "SELECT" or "INSERT".
+	StatementType string
+	// Tables are the table names referenced by the statement, with
+	// quoting stripped.
+	Tables []string
+}
+
+// NormalizeQuery produces an audit fingerprint for a SQL query. It uses
+// a small hand-rolled tokenizer rather than a full SQL parser, so the
+// table extraction is a best-effort heuristic: it collects identifiers
+// following the FROM, JOIN, INTO, UPDATE and TABLE keywords.
+func NormalizeQuery(query string) QueryInfo {
+	var info QueryInfo
+	var tokens []string
+	// expectTable is set after a keyword that is followed by table
+	// names; lastWasTable lets a comma re-arm it so "FROM a, b" yields
+	// both tables.
+	var expectTable, lastWasTable bool
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		// Whitespace collapses into a single separator.
+		case isSQLSpace(c):
+			i++
+		// Comments are dropped entirely.
+		case strings.HasPrefix(query[i:], "--") || c == '#':
+			for i < len(query) && query[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(query[i:], "/*"):
+			if end := strings.Index(query[i+2:], "*/"); end >= 0 {
+				i += end + 4
+			} else {
+				i = len(query)
+			}
+		// String literals are replaced with a placeholder.
+		case c == '\'' || c == '"':
+			i = skipQuoted(query, i, c)
+			tokens = append(tokens, "?")
+			expectTable = false
+		// Numeric literals are replaced with a placeholder.
+		case c >= '0' && c <= '9':
+			for i < len(query) && isNumberChar(query[i]) {
+				i++
+			}
+			tokens = append(tokens, "?")
+			expectTable = false
+		// Identifiers and keywords.
+		case isIdentChar(c) || c == '`':
+			var token string
+			if c == '`' {
+				end := skipQuoted(query, i, '`')
+				token = strings.ReplaceAll(strings.TrimSuffix(query[i+1:end], "`"), "``", "`")
+				i = end
+			} else {
+				start := i
+				for i < len(query) && (isIdentChar(query[i]) || query[i] == '.') {
+					i++
+				}
+				token = query[start:i]
+			}
+			upper := strings.ToUpper(token)
+			if info.StatementType == "" {
+				info.StatementType = upper
+			}
+			switch {
+			case expectTable:
+				info.Tables = append(info.Tables, token)
+				expectTable = false
+				lastWasTable = true
+			case upper == "FROM" || upper == "JOIN" || upper == "INTO" || upper == "TABLE":
+				expectTable = true
+				lastWasTable = false
+			case upper == "UPDATE" && info.StatementType == "UPDATE" && len(info.Tables) == 0:
+				expectTable = true
+				lastWasTable = false
+			default:
+				lastWasTable = false
+			}
+			tokens = append(tokens, token)
+		// Punctuation and operators are tokens of their own. A comma
+		// after a table name keeps table collection going across
+		// "FROM a, b".
+		default:
+			if c == ',' && lastWasTable {
+				expectTable = true
+			} else {
+				expectTable = false
+			}
+			lastWasTable = false
+			tokens = append(tokens, string(c))
+			i++
+		}
+	}
+	info.Fingerprint = strings.Join(tokens, " ")
+	return info
+}
+
+// skipQuoted returns the index just past a quoted region starting at
+// start, where the quote character escapes itself by doubling.
+func skipQuoted(s string, start int, quote byte) int {
+	i := start + 1
+	for i < len(s) {
+		if s[i] == '\\' && quote != '`' {
+			i += 2
+			continue
+		}
+		if s[i] == quote {
+			if i+1 < len(s) && s[i+1] == quote {
+				i += 2
+				continue
+			}
+			return i + 1
+		}
+		i++
+	}
+	return i
+}
+
+func isSQLSpace(c byte) bool {
+	return unicode.IsSpace(rune(c))
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '$' ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func isNumberChar(c byte) bool {
+	return (c >= '0' && c <= '9') || c == '.' || c == 'e' || c == 'E' ||
+		c == 'x' || c == 'X' || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
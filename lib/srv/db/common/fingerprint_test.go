@@ -0,0 +1,114 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestNormalizeQuery verifies query fingerprinting, statement type
+// detection and table extraction.
+func TestNormalizeQuery(t *testing.T) {
+	tests := []struct {
+		desc          string
+		query         string
+		fingerprint   string
+		statementType string
+		tables        []string
+	}{
+		{
+			desc:          "literals are stripped",
+			query:         "SELECT * FROM users WHERE name = 'alice' AND age > 30",
+			fingerprint:   "SELECT * FROM users WHERE name = ? AND age > ?",
+			statementType: "SELECT",
+			tables:        []string{"users"},
+		},
+		{
+			desc:          "same statement different values",
+			query:         "SELECT * FROM users WHERE name = 'bob' AND age > 42",
+			fingerprint:   "SELECT * FROM users WHERE name = ? AND age > ?",
+			statementType: "SELECT",
+			tables:        []string{"users"},
+		},
+		{
+			desc:          "whitespace and comments collapse",
+			query:         "select *\n  from users -- trailing comment\n where id = 1",
+			fingerprint:   "select * from users where id = ?",
+			statementType: "SELECT",
+			tables:        []string{"users"},
+		},
+		{
+			desc:          "insert",
+			query:         "INSERT INTO orders (id, total) VALUES (1, 9.99)",
+			fingerprint:   "INSERT INTO orders ( id , total ) VALUES ( ? , ? )",
+			statementType: "INSERT",
+			tables:        []string{"orders"},
+		},
+		{
+			desc:          "update",
+			query:         "UPDATE accounts SET balance = 0 WHERE id = 5",
+			fingerprint:   "UPDATE accounts SET balance = ? WHERE id = ?",
+			statementType: "UPDATE",
+			tables:        []string{"accounts"},
+		},
+		{
+			desc:          "joins and schema-qualified names",
+			query:         "SELECT a.x FROM public.a JOIN b ON a.id = b.id",
+			fingerprint:   "SELECT a.x FROM public.a JOIN b ON a.id = b.id",
+			statementType: "SELECT",
+			tables:        []string{"public.a", "b"},
+		},
+		{
+			desc:          "comma-separated from list",
+			query:         "SELECT 1 FROM a, b",
+			fingerprint:   "SELECT ? FROM a , b",
+			statementType: "SELECT",
+			tables:        []string{"a", "b"},
+		},
+		{
+			desc:          "quoted identifier",
+			query:         "DELETE FROM `weird table` WHERE id = 2",
+			fingerprint:   "DELETE FROM weird table WHERE id = ?",
+			statementType: "DELETE",
+			tables:        []string{"weird table"},
+		},
+		{
+			desc:          "string with escaped quote",
+			query:         "SELECT 'it''s' FROM t",
+			fingerprint:   "SELECT ? FROM t",
+			statementType: "SELECT",
+			tables:        []string{"t"},
+		},
+		{
+			desc:          "ddl",
+			query:         "CREATE TABLE t (id int)",
+			fingerprint:   "CREATE TABLE t ( id int )",
+			statementType: "CREATE",
+			tables:        []string{"t"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			info := NormalizeQuery(test.query)
+			require.Equal(t, test.fingerprint, info.Fingerprint)
+			require.Equal(t, test.statementType, info.StatementType)
+			require.Equal(t, test.tables, info.Tables)
+		})
+	}
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+
+	"github.com/gravitational/teleport/lib/limiter"
+
+	"github.com/gravitational/trace"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// HandleConnectionFunc proxies a database session, it has the signature
+// of Engine.HandleConnection.
+type HandleConnectionFunc func(context.Context, *Session) error
+
+// Middleware implements a cross-cutting behavior, such as rate limiting
+// or metrics, as a composable wrapper around an engine's HandleConnection
+// so it doesn't have to be re-implemented inside each protocol engine.
+//
+// The engine is provided alongside the next handler so a middleware can
+// report errors to the client in the database protocol format via
+// SendError. Behaviors that require protocol parsing, such as query
+// auditing and filtering, stay inside the engines which attach them at
+// the packet relay level (see QueryFilter).
+type Middleware func(engine Engine, next HandleConnectionFunc) HandleConnectionFunc
+
+// WrapEngine returns an engine that runs the provided middlewares around
+// the engine's HandleConnection, with the first middleware outermost.
+// InitializeConnection and SendError are delegated to the wrapped engine
+// unchanged.
+func WrapEngine(engine Engine, middlewares ...Middleware) Engine {
+	handle := engine.HandleConnection
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handle = middlewares[i](engine, handle)
+	}
+	return &wrappedEngine{Engine: engine, handle: handle}
+}
+
+type wrappedEngine struct {
+	Engine
+	handle HandleConnectionFunc
+}
+
+// HandleConnection runs the middleware chain around the wrapped engine's
+// HandleConnection.
+func (w *wrappedEngine) HandleConnection(ctx context.Context, sessionCtx *Session) error {
+	return trace.Wrap(w.handle(ctx, sessionCtx))
+}
+
+// WithRecovery returns a middleware that converts a panic during
+// connection handling into an error, so a protocol parsing bug triggered
+// by one client cannot take down the whole database service.
+func WithRecovery() Middleware {
+	return func(engine Engine, next HandleConnectionFunc) HandleConnectionFunc {
+		return func(ctx context.Context, sessionCtx *Session) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					sessionCtx.Log.Warnf("Recovered while handling DB connection: %v.", r)
+					err = trace.BadParameter("failed to handle client connection")
+				}
+			}()
+			return next(ctx, sessionCtx)
+		}
+	}
+}
+
+// WithConnectionLimiter returns a middleware that registers the session
+// with the per-client-IP connection and request limiter for the duration
+// of the session.
+func WithConnectionLimiter(connLimiter *limiter.Limiter) Middleware {
+	return func(engine Engine, next HandleConnectionFunc) HandleConnectionFunc {
+		return func(ctx context.Context, sessionCtx *Session) error {
+			// TODO(jakule): ClientIP should be required starting from 10.0.
+			clientIP := sessionCtx.Identity.ClientIP
+			if clientIP == "" {
+				sessionCtx.Log.Debug("ClientIP is not set (Proxy Service has to be updated). Rate limiting is disabled.")
+				return next(ctx, sessionCtx)
+			}
+			sessionCtx.Log.Debugf("Real client IP %s", clientIP)
+			release, err := connLimiter.RegisterRequestAndConnection(clientIP)
+			if err != nil {
+				return trace.Wrap(err)
+			}
+			defer release()
+			return next(ctx, sessionCtx)
+		}
+	}
+}
+
+// WithMFASessionTTL returns a middleware that terminates a session
+// started with a per-session MFA check once it has been open for the
+// interval from the user's roles. The middleware does not re-verify MFA
+// itself: enforcement works by killing the connection, so a fresh MFA
+// check only happens when the user reconnects through tsh, which sends
+// an error telling them to do so.
+func WithMFASessionTTL() Middleware {
+	return func(engine Engine, next HandleConnectionFunc) HandleConnectionFunc {
+		return func(ctx context.Context, sessionCtx *Session) error {
+			interval := sessionCtx.Checker.MFAVerificationInterval()
+			if interval == 0 || sessionCtx.Identity.MFAVerified == "" {
+				return next(ctx, sessionCtx)
+			}
+			ctx, cancel := context.WithTimeout(ctx, interval)
+			defer cancel()
+			err := next(ctx, sessionCtx)
+			if ctx.Err() == context.DeadlineExceeded {
+				engine.SendError(trace.AccessDenied("session requires MFA re-verification, please reconnect to complete a new MFA check"))
+			}
+			return trace.Wrap(err)
+		}
+	}
+}
+
+// WithSessionMetrics returns a middleware that tracks Prometheus metrics
+// of database sessions per protocol.
+func WithSessionMetrics() Middleware {
+	return func(engine Engine, next HandleConnectionFunc) HandleConnectionFunc {
+		return func(ctx context.Context, sessionCtx *Session) error {
+			protocol := sessionCtx.Database.GetProtocol()
+			sessionsTotal.WithLabelValues(protocol).Inc()
+			activeSessions.WithLabelValues(protocol).Inc()
+			defer activeSessions.WithLabelValues(protocol).Dec()
+			err := next(ctx, sessionCtx)
+			if err != nil {
+				sessionErrors.WithLabelValues(protocol).Inc()
+			}
+			return trace.Wrap(err)
+		}
+	}
+}
+
+var (
+	activeSessions = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "db_active_sessions",
+			Help: "Number of database sessions currently being proxied.",
+		},
+		[]string{"protocol"},
+	)
+	sessionsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "db_sessions_total",
+			Help: "Number of database sessions proxied.",
+		},
+		[]string{"protocol"},
+	)
+	sessionErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "db_session_errors_total",
+			Help: "Number of database sessions that ended with an error.",
+		},
+		[]string{"protocol"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(activeSessions)
+	prometheus.MustRegister(sessionsTotal)
+	prometheus.MustRegister(sessionErrors)
+}
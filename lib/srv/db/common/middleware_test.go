@@ -0,0 +1,74 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEngine records calls for middleware tests.
+type fakeEngine struct {
+	handle func(context.Context, *Session) error
+	errors []error
+}
+
+func (e *fakeEngine) InitializeConnection(clientConn net.Conn, sessionCtx *Session) error {
+	return nil
+}
+
+func (e *fakeEngine) SendError(err error) {
+	e.errors = append(e.errors, err)
+}
+
+func (e *fakeEngine) HandleConnection(ctx context.Context, sessionCtx *Session) error {
+	return e.handle(ctx, sessionCtx)
+}
+
+func TestWrapEngineOrder(t *testing.T) {
+	var calls []string
+	tag := func(name string) Middleware {
+		return func(engine Engine, next HandleConnectionFunc) HandleConnectionFunc {
+			return func(ctx context.Context, sessionCtx *Session) error {
+				calls = append(calls, name)
+				return next(ctx, sessionCtx)
+			}
+		}
+	}
+	engine := &fakeEngine{handle: func(ctx context.Context, sessionCtx *Session) error {
+		calls = append(calls, "engine")
+		return nil
+	}}
+
+	wrapped := WrapEngine(engine, tag("outer"), tag("inner"))
+	require.NoError(t, wrapped.HandleConnection(context.Background(), &Session{}))
+	require.Equal(t, []string{"outer", "inner", "engine"}, calls)
+}
+
+func TestWithRecovery(t *testing.T) {
+	engine := &fakeEngine{handle: func(ctx context.Context, sessionCtx *Session) error {
+		panic("boom")
+	}}
+
+	wrapped := WrapEngine(engine, WithRecovery())
+	err := wrapped.HandleConnection(context.Background(), &Session{Log: logrus.New()})
+	require.Error(t, err)
+}
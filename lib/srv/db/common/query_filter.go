@@ -0,0 +1,88 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"regexp"
+
+	"github.com/gravitational/trace"
+)
+
+// QueryFilter checks database queries against role-based regular expression
+// allow and deny lists so query policy lives in one place instead of
+// per-engine ad-hoc checks.
+//
+// Engines call CheckQuery before relaying a statement to the database and,
+// when it returns an error, audit the blocked query and send the error to
+// the client instead of relaying the statement.
+type QueryFilter struct {
+	// allow is the list of patterns a query must match one of, empty
+	// meaning all queries are allowed unless denied.
+	allow []*regexp.Regexp
+	// deny is the list of patterns that block a matching query.
+	deny []*regexp.Regexp
+}
+
+// NewQueryFilter returns a query filter with the patterns from the user's
+// role set, or nil if no role in the set sets query filter patterns.
+func NewQueryFilter(sessionCtx *Session) (*QueryFilter, error) {
+	allow, deny := sessionCtx.Checker.DatabaseQueryFilters()
+	if len(allow) == 0 && len(deny) == 0 {
+		return nil, nil
+	}
+	filter := &QueryFilter{}
+	var err error
+	if filter.allow, err = compileQueryPatterns(allow); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if filter.deny, err = compileQueryPatterns(deny); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return filter, nil
+}
+
+// CheckQuery returns an access denied error if the query matches a deny
+// pattern, or doesn't match any allow pattern when allow patterns are set.
+func (f *QueryFilter) CheckQuery(query string) error {
+	for _, pattern := range f.deny {
+		if pattern.MatchString(query) {
+			return trace.AccessDenied("query is denied by your Teleport role")
+		}
+	}
+	if len(f.allow) == 0 {
+		return nil
+	}
+	for _, pattern := range f.allow {
+		if pattern.MatchString(query) {
+			return nil
+		}
+	}
+	return trace.AccessDenied("query doesn't match the allow list of your Teleport role")
+}
+
+// compileQueryPatterns compiles the regular expression patterns from the
+// role labels.
+func compileQueryPatterns(patterns []string) (compiled []*regexp.Regexp, err error) {
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, trace.BadParameter("failed to compile query filter pattern %q: %v", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
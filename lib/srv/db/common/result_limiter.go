@@ -0,0 +1,85 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"sync"
+
+	"github.com/gravitational/trace"
+)
+
+// ResultLimiter enforces role-based caps on the number of rows and bytes a
+// single database query may return as a data exfiltration guardrail.
+//
+// Database engines count relayed result set rows/bytes via Add and reset
+// the counters via Reset when a query completes. When a cap is exceeded,
+// Add returns a limit exceeded error and the engine is expected to send it
+// to the client and terminate the session.
+//
+// The limiter is safe for concurrent use since engines typically count
+// results and detect query boundaries in separate goroutines.
+type ResultLimiter struct {
+	// maxRows is the maximum number of rows a query may return, zero
+	// meaning no limit.
+	maxRows int64
+	// maxBytes is the maximum number of result bytes a query may return,
+	// zero meaning no limit.
+	maxBytes int64
+	// mu protects the counters below.
+	mu sync.Mutex
+	// rows is the number of rows relayed for the current query.
+	rows int64
+	// bytes is the number of result bytes relayed for the current query.
+	bytes int64
+}
+
+// NewResultLimiter returns a result limiter with the caps from the user's
+// role set, or nil if no role in the set imposes a result limit.
+func NewResultLimiter(sessionCtx *Session) *ResultLimiter {
+	maxRows, maxBytes := sessionCtx.Checker.DatabaseResultLimits()
+	if maxRows == 0 && maxBytes == 0 {
+		return nil
+	}
+	return &ResultLimiter{
+		maxRows:  maxRows,
+		maxBytes: maxBytes,
+	}
+}
+
+// Add registers rows and bytes relayed to the client for the current query
+// and returns a limit exceeded error if either cap has been hit.
+func (l *ResultLimiter) Add(rows, bytes int64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rows += rows
+	l.bytes += bytes
+	if l.maxRows != 0 && l.rows > l.maxRows {
+		return trace.LimitExceeded("query result exceeds the maximum of %v rows allowed by your Teleport role", l.maxRows)
+	}
+	if l.maxBytes != 0 && l.bytes > l.maxBytes {
+		return trace.LimitExceeded("query result exceeds the maximum of %v bytes allowed by your Teleport role", l.maxBytes)
+	}
+	return nil
+}
+
+// Reset resets the counters when a new query starts.
+func (l *ResultLimiter) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rows = 0
+	l.bytes = 0
+}
@@ -22,7 +22,16 @@ import (
 )
 
 // DatabaseRoleMatchers returns role matchers based on the database protocol.
-func DatabaseRoleMatchers(dbProtocol string, user, database string) services.RoleMatchers {
+// The returned matchers also verify the client source IP against the roles'
+// client network conditions.
+func DatabaseRoleMatchers(dbProtocol string, user, database, clientIP string) services.RoleMatchers {
+	return append(protocolRoleMatchers(dbProtocol, user, database),
+		services.NewClientNetworkMatcher(clientIP))
+}
+
+// protocolRoleMatchers returns the database user/name matchers appropriate
+// for the database protocol.
+func protocolRoleMatchers(dbProtocol string, user, database string) services.RoleMatchers {
 	switch dbProtocol {
 	case defaults.ProtocolMySQL:
 		// In MySQL, unlike Postgres, "database" and "schema" are the same thing
@@ -38,6 +47,51 @@ func DatabaseRoleMatchers(dbProtocol string, user, database string) services.Rol
 		return services.RoleMatchers{
 			&services.DatabaseUserMatcher{User: user},
 		}
+	case defaults.ProtocolSQLServer:
+		// Access to the requested database in SQL Server is enforced by
+		// the server itself based on the login's permissions, users can
+		// switch databases within a session so only check db_users.
+		return services.RoleMatchers{
+			&services.DatabaseUserMatcher{User: user},
+		}
+	case defaults.ProtocolCassandra:
+		// Cassandra keyspace restrictions are enforced per-query by the
+		// engine which maps keyspaces to database names, so only check
+		// db_users when establishing the connection.
+		return services.RoleMatchers{
+			&services.DatabaseUserMatcher{User: user},
+		}
+	case defaults.ProtocolElasticsearch:
+		// Elasticsearch has no concept of databases, the user maps to a
+		// backend role so only check db_users.
+		return services.RoleMatchers{
+			&services.DatabaseUserMatcher{User: user},
+		}
+	case defaults.ProtocolClickHouse, defaults.ProtocolClickHouseHTTP:
+		// ClickHouse manages database permissions through its own grants
+		// so only check db_users.
+		return services.RoleMatchers{
+			&services.DatabaseUserMatcher{User: user},
+		}
+	case defaults.ProtocolSnowflake:
+		// Snowflake sessions can switch databases and warehouses freely
+		// so only check db_users.
+		return services.RoleMatchers{
+			&services.DatabaseUserMatcher{User: user},
+		}
+	case defaults.ProtocolRedis:
+		// Redis has no concept of databases in the SQL sense, the user
+		// maps to a Redis ACL user so only check db_users.
+		return services.RoleMatchers{
+			&services.DatabaseUserMatcher{User: user},
+		}
+	case defaults.ProtocolDynamoDB:
+		// DynamoDB table restrictions are enforced per-request by the
+		// engine which maps table names to database names, so only check
+		// db_users when establishing the connection.
+		return services.RoleMatchers{
+			&services.DatabaseUserMatcher{User: user},
+		}
 	case defaults.ProtocolCockroachDB:
 		// Cockroach uses the same wire protocol as Postgres but handling of
 		// databases is different and there's no way to prevent cross-database
@@ -46,6 +46,10 @@ type Session struct {
 	DatabaseName string
 	// StartupParameters define initial connection parameters such as date style.
 	StartupParameters map[string]string
+	// ConnectionAttributes are optional connection attributes reported by
+	// the client during the handshake, such as the client program name
+	// and driver version, captured by the protocol engine for auditing.
+	ConnectionAttributes map[string]string
 	// Log is the logger with session specific fields.
 	Log logrus.FieldLogger
 	// LockTargets is a list of lock targets applicable to this session.
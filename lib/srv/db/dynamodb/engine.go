@@ -0,0 +1,316 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dynamodb implements the AWS DynamoDB database access engine.
+//
+// The engine proxies the DynamoDB JSON/HTTP API: requests from the
+// client are authorized against the table names they reference, audited
+// per-operation and re-signed with SigV4 using the agent's IAM role
+// before being forwarded to the DynamoDB endpoint, so clients never hold
+// AWS credentials.
+package dynamodb
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	apiutils "github.com/gravitational/teleport/api/utils"
+	"github.com/gravitational/teleport/lib/defaults"
+	"github.com/gravitational/teleport/lib/services"
+	"github.com/gravitational/teleport/lib/srv/db/common"
+	"github.com/gravitational/teleport/lib/srv/db/common/role"
+	"github.com/gravitational/teleport/lib/utils"
+
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// awsSigningService is the SigV4 signing service name for DynamoDB.
+	awsSigningService = "dynamodb"
+	// amzTargetHeader carries the DynamoDB operation name, e.g.
+	// "DynamoDB_20120810.GetItem".
+	amzTargetHeader = "X-Amz-Target"
+	// maxRequestBodySize caps the accepted client request body size,
+	// DynamoDB items are limited to 400KB but batch requests can be
+	// larger.
+	maxRequestBodySize = 16 * 1024 * 1024
+)
+
+// Engine implements the DynamoDB database service that accepts client
+// connections coming over reverse tunnel from the proxy and proxies them
+// between the proxy and the DynamoDB API.
+//
+// Implements common.Engine.
+type Engine struct {
+	// Auth handles database access authentication.
+	Auth common.Auth
+	// Audit emits database access audit events.
+	Audit common.Audit
+	// Context is the database server close context.
+	Context context.Context
+	// Clock is the clock interface.
+	Clock clockwork.Clock
+	// Log is used for logging.
+	Log logrus.FieldLogger
+	// clientConn is an incoming client connection.
+	clientConn net.Conn
+	// mfaParams are the session MFA parameters used in per-request access
+	// checks.
+	mfaParams services.AccessMFAParams
+}
+
+// InitializeConnection initializes the client connection.
+func (e *Engine) InitializeConnection(clientConn net.Conn, _ *common.Session) error {
+	e.clientConn = clientConn
+	return nil
+}
+
+// SendError sends an error to the connected client in the JSON format
+// the DynamoDB API uses.
+func (e *Engine) SendError(err error) {
+	if err == nil || utils.IsOKNetworkError(err) {
+		return
+	}
+	if errSend := e.writeErrorResponse(err); errSend != nil {
+		e.Log.WithError(errSend).Debugf("Failed to send error %q to DynamoDB client.", err)
+	}
+}
+
+// writeErrorResponse writes an error response in the DynamoDB API format
+// to the client connection.
+func (e *Engine) writeErrorResponse(err error) error {
+	errorType := "com.amazon.coral.service#InternalFailure"
+	statusCode := http.StatusInternalServerError
+	if trace.IsAccessDenied(err) {
+		errorType = "com.amazon.coral.service#AccessDeniedException"
+		statusCode = http.StatusBadRequest
+	}
+	body, errMarshal := json.Marshal(map[string]interface{}{
+		"__type":  errorType,
+		"message": err.Error(),
+	})
+	if errMarshal != nil {
+		return trace.Wrap(errMarshal)
+	}
+	response := &http.Response{
+		StatusCode: statusCode,
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header: http.Header{
+			"Content-Type": []string{"application/x-amz-json-1.0"},
+		},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+	}
+	return trace.Wrap(response.Write(e.clientConn))
+}
+
+// HandleConnection processes the connection from DynamoDB proxy coming
+// over reverse tunnel.
+func (e *Engine) HandleConnection(ctx context.Context, sessionCtx *common.Session) error {
+	err := e.checkAccess(ctx, sessionCtx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	transport := &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: defaults.DatabaseConnectTimeout,
+		}).DialContext,
+	}
+	defer transport.CloseIdleConnections()
+	e.Audit.OnSessionStart(e.Context, sessionCtx, nil)
+	defer e.Audit.OnSessionEnd(e.Context, sessionCtx)
+	clientConnReader := bufio.NewReader(e.clientConn)
+	for {
+		request, err := http.ReadRequest(clientConnReader)
+		if err != nil {
+			if err == io.EOF || utils.IsOKNetworkError(err) {
+				return nil
+			}
+			return trace.Wrap(err)
+		}
+		err = e.process(ctx, sessionCtx, transport, request)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+}
+
+// process authorizes and audits a single client request and forwards it
+// to the DynamoDB API re-signed with the agent's IAM credentials.
+func (e *Engine) process(ctx context.Context, sessionCtx *common.Session, transport *http.Transport, request *http.Request) error {
+	body, err := io.ReadAll(io.LimitReader(request.Body, maxRequestBodySize))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	request.Body.Close()
+	operation := requestOperation(request)
+	tables, err := requestTables(body)
+	if err != nil {
+		e.Log.WithError(err).Warn("Failed to parse request tables.")
+	}
+	// Enforce RBAC on the referenced table names before forwarding.
+	err = e.checkTableAccess(sessionCtx, tables)
+	e.auditRequest(sessionCtx, operation, tables, err)
+	if err != nil {
+		return trace.Wrap(e.writeErrorResponse(err))
+	}
+	reqCopy := request.Clone(ctx)
+	reqCopy.RequestURI = ""
+	reqCopy.URL.Scheme = "https"
+	reqCopy.URL.Host = sessionCtx.Database.GetURI()
+	reqCopy.Host = sessionCtx.Database.GetURI()
+	reqCopy.Body = io.NopCloser(bytes.NewReader(body))
+	reqCopy.ContentLength = int64(len(body))
+	// Re-sign the request with the agent's IAM credentials, the client's
+	// signature is for dummy local credentials.
+	reqCopy.Header.Del("Authorization")
+	reqCopy.Header.Del("X-Amz-Security-Token")
+	credentials, err := e.Auth.GetAWSCredentials(sessionCtx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	signer := v4.NewSigner(credentials)
+	_, err = signer.Sign(reqCopy, bytes.NewReader(body), awsSigningService,
+		sessionCtx.Database.GetAWS().Region, e.Clock.Now())
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	response, err := transport.RoundTrip(reqCopy)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer response.Body.Close()
+	return trace.Wrap(response.Write(e.clientConn))
+}
+
+// auditRequest emits an audit event for the operation, one per
+// referenced table.
+func (e *Engine) auditRequest(sessionCtx *common.Session, operation string, tables []string, err error) {
+	if len(tables) == 0 {
+		e.Audit.OnQuery(e.Context, sessionCtx, common.Query{Query: operation, Error: err})
+		return
+	}
+	for _, table := range tables {
+		e.Audit.OnQuery(e.Context, sessionCtx, common.Query{
+			Query:    fmt.Sprintf("%v %v", operation, table),
+			Database: table,
+			Error:    err,
+		})
+	}
+}
+
+// checkAccess does authorization check for DynamoDB connection about to
+// be established.
+func (e *Engine) checkAccess(ctx context.Context, sessionCtx *common.Session) error {
+	ap, err := e.Auth.GetAuthPreference(ctx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	e.mfaParams = services.AccessMFAParams{
+		Verified:       sessionCtx.Identity.MFAVerified != "",
+		AlwaysRequired: ap.GetRequireSessionMFA(),
+	}
+	dbRoleMatchers := role.DatabaseRoleMatchers(
+		defaults.ProtocolDynamoDB,
+		sessionCtx.DatabaseUser,
+		sessionCtx.DatabaseName,
+		sessionCtx.Identity.ClientIP,
+	)
+	err = sessionCtx.Checker.CheckAccess(
+		sessionCtx.Database,
+		e.mfaParams,
+		dbRoleMatchers...,
+	)
+	if err != nil {
+		e.Audit.OnSessionStart(e.Context, sessionCtx, err)
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// checkTableAccess verifies the session is allowed to access the tables
+// referenced by the request, matching them against the roles' allowed
+// database names.
+func (e *Engine) checkTableAccess(sessionCtx *common.Session, tables []string) error {
+	for _, table := range tables {
+		err := sessionCtx.Checker.CheckAccess(
+			sessionCtx.Database,
+			e.mfaParams,
+			&services.DatabaseUserMatcher{User: sessionCtx.DatabaseUser},
+			&services.DatabaseNameMatcher{Name: table})
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// requestOperation returns the DynamoDB operation name from the request
+// target header.
+func requestOperation(request *http.Request) string {
+	target := request.Header.Get(amzTargetHeader)
+	if _, operation, found := strings.Cut(target, "."); found {
+		return operation
+	}
+	return target
+}
+
+// requestTables returns the table names referenced by the request body.
+func requestTables(body []byte) ([]string, error) {
+	if len(body) == 0 {
+		return nil, nil
+	}
+	// Most operations reference a single TableName, batch and transact
+	// operations reference multiple tables in RequestItems or nested
+	// per-item statements.
+	var parsed struct {
+		TableName     string                     `json:"TableName"`
+		RequestItems  map[string]json.RawMessage `json:"RequestItems"`
+		TransactItems []map[string]struct {
+			TableName string `json:"TableName"`
+		} `json:"TransactItems"`
+	}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var tables []string
+	if parsed.TableName != "" {
+		tables = append(tables, parsed.TableName)
+	}
+	for table := range parsed.RequestItems {
+		tables = append(tables, table)
+	}
+	for _, item := range parsed.TransactItems {
+		for _, action := range item {
+			if action.TableName != "" {
+				tables = append(tables, action.TableName)
+			}
+		}
+	}
+	return apiutils.Deduplicate(tables), nil
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamodb
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestRequestOperation verifies operation extraction from the target
+// header.
+func TestRequestOperation(t *testing.T) {
+	request := &http.Request{Header: http.Header{}}
+	request.Header.Set(amzTargetHeader, "DynamoDB_20120810.GetItem")
+	require.Equal(t, "GetItem", requestOperation(request))
+}
+
+// TestRequestTables verifies table name extraction from request bodies.
+func TestRequestTables(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		tables []string
+	}{
+		{
+			name:   "single table",
+			body:   `{"TableName": "users", "Key": {"id": {"S": "1"}}}`,
+			tables: []string{"users"},
+		},
+		{
+			name:   "batch request",
+			body:   `{"RequestItems": {"users": {"Keys": []}, "orders": {"Keys": []}}}`,
+			tables: []string{"users", "orders"},
+		},
+		{
+			name:   "transact request",
+			body:   `{"TransactItems": [{"Put": {"TableName": "users"}}, {"Delete": {"TableName": "users"}}]}`,
+			tables: []string{"users"},
+		},
+		{
+			name:   "no tables",
+			body:   `{"ExclusiveStartTableName": "users"}`,
+			tables: nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tables, err := requestTables([]byte(test.body))
+			require.NoError(t, err)
+			require.ElementsMatch(t, test.tables, tables)
+		})
+	}
+}
@@ -0,0 +1,274 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package elasticsearch implements the Elasticsearch and OpenSearch
+// database access engine.
+//
+// The engine proxies the Elasticsearch REST API: it reads HTTP requests
+// from the client connection, audits index-level operations including
+// search query bodies and forwards requests to the database over TLS.
+// Requests to AWS OpenSearch domains are signed with SigV4 using the
+// agent's IAM credentials, self-hosted clusters authenticate the agent's
+// client certificate where the certificate subject maps to the backend
+// role of the database user.
+package elasticsearch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gravitational/teleport/lib/defaults"
+	"github.com/gravitational/teleport/lib/services"
+	"github.com/gravitational/teleport/lib/srv/db/common"
+	"github.com/gravitational/teleport/lib/srv/db/common/role"
+	"github.com/gravitational/teleport/lib/utils"
+
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+	"github.com/sirupsen/logrus"
+)
+
+// awsSigningService is the signing service name for AWS OpenSearch
+// domains.
+const awsSigningService = "es"
+
+// maxAuditBodySize caps the request body size recorded in audit events.
+const maxAuditBodySize = 4096
+
+// maxRequestBodySize caps the accepted client request body size.
+const maxRequestBodySize = 256 * 1024 * 1024
+
+// Engine implements the Elasticsearch database service that accepts
+// client connections coming over reverse tunnel from the proxy and
+// proxies them between the proxy and the Elasticsearch cluster.
+//
+// Implements common.Engine.
+type Engine struct {
+	// Auth handles database access authentication.
+	Auth common.Auth
+	// Audit emits database access audit events.
+	Audit common.Audit
+	// Context is the database server close context.
+	Context context.Context
+	// Clock is the clock interface.
+	Clock clockwork.Clock
+	// Log is used for logging.
+	Log logrus.FieldLogger
+	// clientConn is an incoming client connection.
+	clientConn net.Conn
+}
+
+// InitializeConnection initializes the client connection.
+func (e *Engine) InitializeConnection(clientConn net.Conn, _ *common.Session) error {
+	e.clientConn = clientConn
+	return nil
+}
+
+// SendError sends an error to the connected client in the JSON error
+// format Elasticsearch clients expect.
+func (e *Engine) SendError(err error) {
+	if err == nil || utils.IsOKNetworkError(err) {
+		return
+	}
+	statusCode := http.StatusInternalServerError
+	if trace.IsAccessDenied(err) {
+		statusCode = http.StatusUnauthorized
+	}
+	body, errMarshal := json.Marshal(map[string]interface{}{
+		"error": map[string]interface{}{
+			"reason": err.Error(),
+			"type":   "security_exception",
+		},
+		"status": statusCode,
+	})
+	if errMarshal != nil {
+		e.Log.WithError(errMarshal).Debug("Failed to marshal error response.")
+		return
+	}
+	response := &http.Response{
+		StatusCode: statusCode,
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header: http.Header{
+			"Content-Type": []string{"application/json"},
+		},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+	}
+	if errSend := response.Write(e.clientConn); errSend != nil {
+		e.Log.WithError(errSend).Debugf("Failed to send error %q to Elasticsearch client.", err)
+	}
+}
+
+// HandleConnection processes the connection from Elasticsearch proxy
+// coming over reverse tunnel.
+//
+// It reads HTTP requests from the client, audits and authorizes them and
+// forwards them to the database, signing requests to AWS OpenSearch
+// domains with the agent's IAM credentials.
+func (e *Engine) HandleConnection(ctx context.Context, sessionCtx *common.Session) error {
+	err := e.checkAccess(ctx, sessionCtx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	tlsConfig, err := e.Auth.GetTLSConfig(ctx, sessionCtx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	transport := &http.Transport{
+		TLSClientConfig: tlsConfig,
+		DialContext: (&net.Dialer{
+			Timeout: defaults.DatabaseConnectTimeout,
+		}).DialContext,
+	}
+	defer transport.CloseIdleConnections()
+	e.Audit.OnSessionStart(e.Context, sessionCtx, nil)
+	defer e.Audit.OnSessionEnd(e.Context, sessionCtx)
+	clientConnReader := bufio.NewReader(e.clientConn)
+	for {
+		request, err := http.ReadRequest(clientConnReader)
+		if err != nil {
+			if err == io.EOF || utils.IsOKNetworkError(err) {
+				return nil
+			}
+			return trace.Wrap(err)
+		}
+		err = e.process(ctx, sessionCtx, transport, request)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+}
+
+// process audits a single client request and forwards it to the
+// database, relaying the response back to the client.
+func (e *Engine) process(ctx context.Context, sessionCtx *common.Session, transport *http.Transport, request *http.Request) error {
+	body, err := io.ReadAll(io.LimitReader(request.Body, maxRequestBodySize))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	request.Body.Close()
+	e.auditRequest(sessionCtx, request, body)
+	// Rewrite the request for the database and re-sign it for AWS
+	// OpenSearch domains.
+	reqCopy := request.Clone(ctx)
+	reqCopy.RequestURI = ""
+	reqCopy.URL.Scheme = "https"
+	reqCopy.URL.Host = sessionCtx.Database.GetURI()
+	reqCopy.Body = io.NopCloser(bytes.NewReader(body))
+	reqCopy.ContentLength = int64(len(body))
+	// Authentication is happening between the agent and the database so
+	// drop client credentials.
+	reqCopy.Header.Del("Authorization")
+	if sessionCtx.Database.GetAWS().Region != "" {
+		credentials, err := e.Auth.GetAWSCredentials(sessionCtx)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		signer := v4.NewSigner(credentials)
+		_, err = signer.Sign(reqCopy, bytes.NewReader(body), awsSigningService,
+			sessionCtx.Database.GetAWS().Region, e.Clock.Now())
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	response, err := transport.RoundTrip(reqCopy)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer response.Body.Close()
+	return trace.Wrap(response.Write(e.clientConn))
+}
+
+// auditRequest emits an audit event for the client request with the
+// accessed index and, for query endpoints, the request body.
+func (e *Engine) auditRequest(sessionCtx *common.Session, request *http.Request, body []byte) {
+	query := common.Query{
+		Query:    fmt.Sprintf("%v %v", request.Method, request.URL.Path),
+		Database: pathIndex(request.URL.Path),
+	}
+	if len(body) != 0 && isQueryEndpoint(request.URL.Path) {
+		if len(body) > maxAuditBodySize {
+			body = body[:maxAuditBodySize]
+		}
+		query.Parameters = []string{string(body)}
+	}
+	e.Audit.OnQuery(e.Context, sessionCtx, query)
+}
+
+// checkAccess does authorization check for Elasticsearch connection
+// about to be established.
+func (e *Engine) checkAccess(ctx context.Context, sessionCtx *common.Session) error {
+	ap, err := e.Auth.GetAuthPreference(ctx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	mfaParams := services.AccessMFAParams{
+		Verified:       sessionCtx.Identity.MFAVerified != "",
+		AlwaysRequired: ap.GetRequireSessionMFA(),
+	}
+	dbRoleMatchers := role.DatabaseRoleMatchers(
+		defaults.ProtocolElasticsearch,
+		sessionCtx.DatabaseUser,
+		sessionCtx.DatabaseName,
+		sessionCtx.Identity.ClientIP,
+	)
+	err = sessionCtx.Checker.CheckAccess(
+		sessionCtx.Database,
+		mfaParams,
+		dbRoleMatchers...,
+	)
+	if err != nil {
+		e.Audit.OnSessionStart(e.Context, sessionCtx, err)
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// pathIndex returns the index targeted by the request path, empty for
+// cluster-level APIs.
+func pathIndex(path string) string {
+	for _, segment := range strings.Split(strings.TrimPrefix(path, "/"), "/") {
+		if segment == "" || strings.HasPrefix(segment, "_") {
+			return ""
+		}
+		return segment
+	}
+	return ""
+}
+
+// isQueryEndpoint returns true for API endpoints whose request bodies
+// carry queries worth auditing.
+func isQueryEndpoint(path string) bool {
+	for _, endpoint := range []string{
+		"_search", "_msearch", "_count", "_sql", "_async_search",
+		"_eql", "_graph", "_delete_by_query", "_update_by_query",
+	} {
+		if strings.Contains(path, endpoint) {
+			return true
+		}
+	}
+	return false
+}
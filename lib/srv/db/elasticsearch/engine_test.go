@@ -0,0 +1,48 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticsearch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestPathIndex verifies index extraction from request paths.
+func TestPathIndex(t *testing.T) {
+	tests := []struct {
+		path  string
+		index string
+	}{
+		{path: "/logs-2021/_search", index: "logs-2021"},
+		{path: "/logs-2021/_doc/1", index: "logs-2021"},
+		{path: "/_cluster/health", index: ""},
+		{path: "/", index: ""},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			require.Equal(t, test.index, pathIndex(test.path))
+		})
+	}
+}
+
+// TestIsQueryEndpoint verifies detection of endpoints with query bodies.
+func TestIsQueryEndpoint(t *testing.T) {
+	require.True(t, isQueryEndpoint("/logs-2021/_search"))
+	require.True(t, isQueryEndpoint("/_sql"))
+	require.False(t, isQueryEndpoint("/logs-2021/_doc/1"))
+}
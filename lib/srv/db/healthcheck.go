@@ -0,0 +1,110 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package db
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/gravitational/teleport/api/types"
+)
+
+const (
+	// healthCheckInterval is how often the agent checks connectivity to
+	// each of its proxied databases.
+	healthCheckInterval = 30 * time.Second
+	// healthCheckTimeout is the dial timeout for a single connectivity
+	// check.
+	healthCheckTimeout = 5 * time.Second
+)
+
+// startHealthCheck starts a goroutine that periodically checks connectivity
+// from this agent to each proxied database and updates their health states
+// reported on heartbeats.
+func (s *Server) startHealthCheck(ctx context.Context) error {
+	go func() {
+		ticker := time.NewTicker(healthCheckInterval)
+		defer ticker.Stop()
+		defer s.log.Debug("Database health checker done.")
+		for {
+			select {
+			case <-ticker.C:
+				for _, database := range s.getProxiedDatabases() {
+					s.checkDatabaseHealth(database)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// checkDatabaseHealth dials the database endpoint and records the result,
+// logging the transition and forcing a heartbeat when the health state
+// changes so operators notice without waiting for the next announce cycle.
+func (s *Server) checkDatabaseHealth(database types.Database) {
+	// Some databases (e.g. MongoDB) can have a connection string URI that
+	// doesn't point to a single network endpoint the agent could dial.
+	if _, _, err := net.SplitHostPort(database.GetURI()); err != nil {
+		return
+	}
+	state := types.DatabaseHealthHealthy
+	conn, err := net.DialTimeout("tcp", database.GetURI(), healthCheckTimeout)
+	if err != nil {
+		state = types.DatabaseHealthUnhealthy
+	} else {
+		conn.Close()
+	}
+	if !s.setHealthState(database.GetName(), state) {
+		return // The state hasn't changed.
+	}
+	if err != nil {
+		s.log.Warnf("Database %q became unhealthy: %v.", database.GetName(), err)
+	} else {
+		s.log.Infof("Database %q became healthy.", database.GetName())
+	}
+	s.mu.RLock()
+	heartbeat, ok := s.heartbeats[database.GetName()]
+	s.mu.RUnlock()
+	if ok {
+		if err := heartbeat.ForceSend(healthCheckTimeout); err != nil {
+			s.log.WithError(err).Warnf("Failed to heartbeat database %q.", database.GetName())
+		}
+	}
+}
+
+// setHealthState records the health state for the database and returns true
+// if the state has changed since the last check.
+func (s *Server) setHealthState(name, state string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.healthStates[name] == state {
+		return false
+	}
+	s.healthStates[name] = state
+	return true
+}
+
+// getHealthState returns the recorded health state for the database, or an
+// empty string if the database hasn't been checked yet.
+func (s *Server) getHealthState(name string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.healthStates[name]
+}
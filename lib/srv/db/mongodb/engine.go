@@ -175,6 +175,7 @@ func (e *Engine) authorizeConnection(ctx context.Context, sessionCtx *common.Ses
 		sessionCtx.Database,
 		mfaParams,
 		&services.DatabaseUserMatcher{User: sessionCtx.DatabaseUser},
+		services.NewClientNetworkMatcher(sessionCtx.Identity.ClientIP),
 	)
 	if err != nil {
 		e.Audit.OnSessionStart(e.Context, sessionCtx, err)
@@ -203,6 +204,11 @@ func (e *Engine) authorizeClientMessage(sessionCtx *common.Session, message prot
 }
 
 func (e *Engine) checkClientMessage(sessionCtx *common.Session, message protocol.Message, database string) error {
+	// Clients that negotiated compression wrap messages in OP_COMPRESSED,
+	// check the decompressed message below.
+	if messageCompressed, ok := message.(*protocol.MessageOpCompressed); ok {
+		message = messageCompressed.GetOriginal()
+	}
 	// Legacy OP_KILL_CURSORS command doesn't contain database information.
 	if _, ok := message.(*protocol.MessageOpKillCursors); ok {
 		return sessionCtx.Checker.CheckAccess(sessionCtx.Database,
@@ -224,7 +230,8 @@ func (e *Engine) checkClientMessage(sessionCtx *common.Session, message protocol
 		role.DatabaseRoleMatchers(
 			defaults.ProtocolMongoDB,
 			sessionCtx.DatabaseUser,
-			database)...)
+			database,
+			sessionCtx.Identity.ClientIP)...)
 }
 
 func (e *Engine) replyError(clientConn net.Conn, replyTo protocol.Message, err error) {
@@ -303,7 +303,7 @@ func TestOpCompressed(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			// Wrap the message in OP_COMPRESSED.
-			compressedMessage := makeTestOpCompressed(t, test.message)
+			compressedMessage := makeTestOpCompressed(t, test.message, wiremessage.CompressorZLib)
 
 			// Read it back.
 			parsed, err := ReadMessage(bytes.NewReader(compressedMessage.bytes))
@@ -317,15 +317,73 @@ func TestOpCompressed(t *testing.T) {
 	}
 }
 
-func makeTestOpCompressed(t *testing.T, message Message) *MessageOpCompressed {
+// TestOpCompressedCompressors verifies all compressors drivers may negotiate
+// with the server are supported.
+func TestOpCompressedCompressors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		compressor wiremessage.CompressorID
+	}{
+		{
+			name:       "snappy",
+			compressor: wiremessage.CompressorSnappy,
+		},
+		{
+			name:       "zlib",
+			compressor: wiremessage.CompressorZLib,
+		},
+		{
+			name:       "zstd",
+			compressor: wiremessage.CompressorZstd,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			message := makeTestOpMsg(t)
+
+			// Wrap the message in OP_COMPRESSED.
+			compressedMessage := makeTestOpCompressed(t, message, test.compressor)
+
+			// Read it back and make sure it decompressed to the original.
+			parsed, err := ReadMessage(bytes.NewReader(compressedMessage.bytes))
+			require.NoError(t, err)
+			require.Equal(t, message, parsed.(*MessageOpCompressed).GetOriginal())
+		})
+	}
+}
+
+// TestOpReplyExhaust verifies exhaust cursor detection including the case
+// when the client request arrived wrapped in OP_COMPRESSED.
+func TestOpReplyExhaust(t *testing.T) {
+	t.Parallel()
+
+	exhaustQuery := makeTestOpQueryWithFlags(t, wiremessage.Exhaust)
+	reply := makeTestOpReply(t)
+
+	// Server will keep streaming replies for an exhaust cursor query while
+	// the cursor is open, compressed or not.
+	require.True(t, reply.MoreToCome(exhaustQuery))
+	require.True(t, reply.MoreToCome(makeTestOpCompressed(t, exhaustQuery, wiremessage.CompressorSnappy)))
+
+	// No more replies to come for a regular query or a closed cursor.
+	require.False(t, reply.MoreToCome(makeTestOpQuery(t)))
+	reply.CursorID = 0
+	require.False(t, reply.MoreToCome(exhaustQuery))
+}
+
+func makeTestOpCompressed(t *testing.T, message Message, compressor wiremessage.CompressorID) *MessageOpCompressed {
 	// Marshal the original message to wire representation.
 	bytes := message.ToWire(0)
 
 	// Compress the message payload, excluding header.
 	compressed, err := driver.CompressPayload(bytes[headerSizeBytes:],
 		driver.CompressionOpts{
-			Compressor: wiremessage.CompressorZLib,
+			Compressor: compressor,
 			ZlibLevel:  wiremessage.DefaultZlibLevel,
+			ZstdLevel:  wiremessage.DefaultZstdLevel,
 		})
 	require.NoError(t, err)
 
@@ -333,7 +391,7 @@ func makeTestOpCompressed(t *testing.T, message Message) *MessageOpCompressed {
 	msg := &MessageOpCompressed{
 		OriginalOpcode:    message.GetHeader().OpCode,
 		UncompressedSize:  int32(len(bytes) - headerSizeBytes),
-		CompressorID:      wiremessage.CompressorZLib,
+		CompressorID:      compressor,
 		CompressedMessage: compressed,
 		originalMessage:   message,
 	}
@@ -360,8 +418,12 @@ func makeTestOpMsgWithBody(t *testing.T, doc []byte) *MessageOpMsg {
 }
 
 func makeTestOpQuery(t *testing.T) *MessageOpQuery {
+	return makeTestOpQueryWithFlags(t, wiremessage.AwaitData)
+}
+
+func makeTestOpQueryWithFlags(t *testing.T, flags wiremessage.QueryFlag) *MessageOpQuery {
 	msg := &MessageOpQuery{
-		Flags:                wiremessage.AwaitData,
+		Flags:                flags,
 		FullCollectionName:   "test.collection",
 		NumberToSkip:         1,
 		NumberToReturn:       1,
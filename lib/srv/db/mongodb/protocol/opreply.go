@@ -88,6 +88,11 @@ func (m *MessageOpReply) String() string {
 
 // MoreToCome is whether sender will send another message right after this one.
 func (m *MessageOpReply) MoreToCome(msg Message) bool {
+	// The client request may have arrived wrapped in OP_COMPRESSED if the
+	// client negotiated compression, look at the original message flags then.
+	if msgCompressed, ok := msg.(*MessageOpCompressed); ok {
+		msg = msgCompressed.GetOriginal()
+	}
 	// Check if this is an exhaust cursor.
 	opQuery, ok := msg.(*MessageOpQuery)
 	return ok && opQuery.Flags&wiremessage.Exhaust == wiremessage.Exhaust && m.CursorID != 0
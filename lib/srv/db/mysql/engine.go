@@ -22,6 +22,7 @@ import (
 	"net"
 	"time"
 
+	"github.com/gravitational/teleport"
 	"github.com/gravitational/teleport/api/types"
 	"github.com/gravitational/teleport/lib/auth"
 	"github.com/gravitational/teleport/lib/defaults"
@@ -60,6 +61,15 @@ type Engine struct {
 	Log logrus.FieldLogger
 	// proxyConn is a client connection.
 	proxyConn server.Conn
+	// masker rewrites result sets to mask sensitive columns. It is nil
+	// when none of the user's roles flag columns for masking.
+	masker *resultSetMasker
+	// resultLimiter caps the amount of result data a single query may
+	// return. It is nil when none of the user's roles impose a limit.
+	resultLimiter *common.ResultLimiter
+	// queryFilter blocks queries based on the user's role-based allow and
+	// deny patterns. It is nil when none of the user's roles set any.
+	queryFilter *common.QueryFilter
 }
 
 // InitializeConnection initializes the engine with client connection.
@@ -88,6 +98,23 @@ func (e *Engine) HandleConnection(ctx context.Context, sessionCtx *common.Sessio
 	if err != nil {
 		return trace.Wrap(err)
 	}
+	// Setup result set masking if any of the user's roles flag columns
+	// as sensitive.
+	if patterns := sessionCtx.Checker.MaskedDatabaseColumns(); len(patterns) != 0 {
+		e.masker, err = newResultSetMasker(patterns, e.Log)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	// Setup result size limiting if any of the user's roles cap the amount
+	// of data a single query may return.
+	e.resultLimiter = common.NewResultLimiter(sessionCtx)
+	// Setup query filtering if any of the user's roles set query allow or
+	// deny patterns.
+	e.queryFilter, err = common.NewQueryFilter(sessionCtx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
 	// Establish connection to the MySQL server.
 	serverConn, err := e.connect(ctx, sessionCtx)
 	if err != nil {
@@ -110,6 +137,14 @@ func (e *Engine) HandleConnection(ctx context.Context, sessionCtx *common.Sessio
 	}
 	e.Audit.OnSessionStart(e.Context, sessionCtx, nil)
 	defer e.Audit.OnSessionEnd(e.Context, sessionCtx)
+	// Watch for updates to the user's roles for the duration of the session
+	// to be able to terminate it if access gets revoked mid-session. Cancel
+	// the watch context on return so the watcher goroutine doesn't outlive
+	// the session.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	accessErrCh := make(chan error, 1)
+	go e.watchAccess(ctx, sessionCtx, accessErrCh)
 	// Copy between the connections.
 	clientErrCh := make(chan error, 1)
 	serverErrCh := make(chan error, 1)
@@ -120,6 +155,10 @@ func (e *Engine) HandleConnection(ctx context.Context, sessionCtx *common.Sessio
 		e.Log.WithError(err).Debug("Client done.")
 	case err := <-serverErrCh:
 		e.Log.WithError(err).Debug("Server done.")
+	case err := <-accessErrCh:
+		e.Log.WithError(err).Info("Closing session, access revoked.")
+		e.SendError(err)
+		return trace.Wrap(err)
 	case <-ctx.Done():
 		e.Log.Debug("Context canceled.")
 	}
@@ -128,6 +167,25 @@ func (e *Engine) HandleConnection(ctx context.Context, sessionCtx *common.Sessio
 
 // checkAccess does authorization check for MySQL connection about to be established.
 func (e *Engine) checkAccess(ctx context.Context, sessionCtx *common.Session) error {
+	err := e.checkAccessToDatabaseName(ctx, sessionCtx, sessionCtx.DatabaseName)
+	if err != nil {
+		e.Audit.OnSessionStart(e.Context, sessionCtx, err)
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// checkAccessToDatabaseName verifies the user is allowed to use the provided
+// database name. It is called with the requested database when the connection
+// is being established and again with the new database name when the client
+// switches the session's default schema with COM_INIT_DB.
+func (e *Engine) checkAccessToDatabaseName(ctx context.Context, sessionCtx *common.Session, dbName string) error {
+	return trace.Wrap(e.checkAccessWithChecker(ctx, sessionCtx, sessionCtx.Checker, dbName))
+}
+
+// checkAccessWithChecker runs the database access check for the session
+// using the provided access checker.
+func (e *Engine) checkAccessWithChecker(ctx context.Context, sessionCtx *common.Session, checker services.AccessChecker, dbName string) error {
 	ap, err := e.Auth.GetAuthPreference(ctx)
 	if err != nil {
 		return trace.Wrap(err)
@@ -139,15 +197,90 @@ func (e *Engine) checkAccess(ctx context.Context, sessionCtx *common.Session) er
 	dbRoleMatchers := role.DatabaseRoleMatchers(
 		defaults.ProtocolMySQL,
 		sessionCtx.DatabaseUser,
-		sessionCtx.DatabaseName,
+		dbName,
+		sessionCtx.Identity.ClientIP,
 	)
-	err = sessionCtx.Checker.CheckAccess(
+	err = checker.CheckAccess(
 		sessionCtx.Database,
 		mfaParams,
 		dbRoleMatchers...,
 	)
+	return trace.Wrap(err)
+}
+
+// watchAccess watches for updates to the roles the session user holds and
+// re-evaluates database access when any of them changes. When access gets
+// revoked mid-session, the error is sent on accessErrCh to terminate the
+// session. Lock updates are already enforced at the connection level by
+// the connection monitor so only role updates need re-evaluation here.
+func (e *Engine) watchAccess(ctx context.Context, sessionCtx *common.Session, accessErrCh chan<- error) {
+	watcher, err := e.AuthClient.NewWatcher(ctx, types.Watch{
+		Name: teleport.ComponentDatabase,
+		Kinds: []types.WatchKind{
+			{Kind: types.KindRole},
+		},
+	})
+	if err != nil {
+		e.Log.WithError(err).Warn("Failed to start role watcher, role updates will not be enforced during this session.")
+		return
+	}
+	defer watcher.Close()
+	held := make(map[string]bool, len(sessionCtx.Identity.Groups))
+	for _, role := range sessionCtx.Identity.Groups {
+		held[role] = true
+	}
+	// retryC is set when a re-evaluation failed for a reason other than
+	// access being denied, to try again after a short delay.
+	var retryC <-chan time.Time
+	for {
+		select {
+		case event := <-watcher.Events():
+			if event.Type != types.OpPut && event.Type != types.OpDelete {
+				continue
+			}
+			if !held[event.Resource.GetName()] {
+				continue
+			}
+			e.Log.Debugf("Role %v was updated, re-evaluating access for session %v.", event.Resource.GetName(), sessionCtx)
+		case <-retryC:
+			e.Log.Debugf("Retrying access re-evaluation for session %v.", sessionCtx)
+		case <-watcher.Done():
+			e.Log.WithError(watcher.Error()).Warn("Role watcher closed, role updates will not be enforced during this session.")
+			return
+		case <-ctx.Done():
+			return
+		}
+		retryC = nil
+		switch err := e.reevaluateAccess(ctx, sessionCtx); {
+		case err == nil:
+		case trace.IsAccessDenied(err):
+			accessErrCh <- err
+			return
+		default:
+			// The check could not be completed, e.g. because the auth
+			// server is temporarily unreachable. That is no proof access
+			// was revoked, so keep the session and try again, consistent
+			// with the behavior when the watcher cannot be established.
+			e.Log.WithError(err).Warn("Failed to re-evaluate access, will retry.")
+			retryC = time.After(defaults.HighResPollingPeriod)
+		}
+	}
+}
+
+// reevaluateAccess fetches fresh copies of the user's roles from the auth
+// server and re-runs the database access check with them. An access denied
+// error means access has been revoked; any other error means the check
+// could not be completed.
+func (e *Engine) reevaluateAccess(ctx context.Context, sessionCtx *common.Session) error {
+	checker, err := services.FetchRoles(sessionCtx.Identity.Groups, e.AuthClient, sessionCtx.Identity.Traits)
 	if err != nil {
-		e.Audit.OnSessionStart(e.Context, sessionCtx, err)
+		return trace.Wrap(err)
+	}
+	err = e.checkAccessWithChecker(ctx, sessionCtx, checker, sessionCtx.DatabaseName)
+	if err != nil {
+		if trace.IsAccessDenied(err) {
+			return trace.AccessDenied("access to the database has been revoked by a role update")
+		}
 		return trace.Wrap(err)
 	}
 	return nil
@@ -251,7 +384,50 @@ func (e *Engine) receiveFromClient(clientConn, serverConn net.Conn, clientErrCh
 		}
 		switch pkt := packet.(type) {
 		case *protocol.Query:
-			e.Audit.OnQuery(e.Context, sessionCtx, common.Query{Query: pkt.Query()})
+			// A single COM_QUERY message can carry multiple statements
+			// (stacked queries) so audit each statement individually.
+			statements := splitStatements(pkt.Query())
+			if len(statements) > 1 && sessionCtx.Checker.RejectDatabaseMultiStatements() {
+				log.Warnf("Rejecting multi-statement query for session %v.", sessionCtx)
+				e.SendError(trace.AccessDenied("multi-statement queries are not allowed by your Teleport role"))
+				return
+			}
+			if e.queryFilter != nil {
+				if err := e.queryFilter.CheckQuery(pkt.Query()); err != nil {
+					log.Warnf("Rejecting query for session %v: %v.", sessionCtx, err)
+					e.Audit.OnQuery(e.Context, sessionCtx, common.Query{Query: pkt.Query(), Error: err})
+					e.SendError(err)
+					return
+				}
+			}
+			for _, statement := range statements {
+				e.Audit.OnQuery(e.Context, sessionCtx, common.Query{Query: statement})
+			}
+			if e.masker != nil {
+				e.masker.expectResultSet()
+			}
+			if e.resultLimiter != nil {
+				e.resultLimiter.Reset()
+			}
+		case *protocol.InitDB:
+			// COM_INIT_DB changes the session's default schema so make sure
+			// the user's roles allow the new database name before relaying.
+			e.Audit.OnQuery(e.Context, sessionCtx, common.Query{Query: fmt.Sprintf("USE %v", pkt.SchemaName())})
+			err := e.checkAccessToDatabaseName(e.Context, sessionCtx, pkt.SchemaName())
+			if err != nil {
+				log.Warnf("Rejecting switch to database %q for session %v: %v.", pkt.SchemaName(), sessionCtx, err)
+				e.SendError(err)
+				return
+			}
+			sessionCtx.DatabaseName = pkt.SchemaName()
+		case *protocol.FieldList:
+			// COM_FIELD_LIST is an old-style equivalent of "show columns"
+			// still sent by some clients so audit it as such.
+			query := fmt.Sprintf("SHOW FIELDS FROM %v", pkt.Table())
+			if pkt.Wildcard() != "" {
+				query = fmt.Sprintf("%v LIKE '%v'", query, pkt.Wildcard())
+			}
+			e.Audit.OnQuery(e.Context, sessionCtx, common.Query{Query: query})
 		case *protocol.ChangeUser:
 			// MySQL protocol includes COM_CHANGE_USER command that allows to
 			// re-authenticate connection as a different user. It is not
@@ -263,6 +439,20 @@ func (e *Engine) receiveFromClient(clientConn, serverConn net.Conn, clientErrCh
 			// attempt and close the client connection.
 			log.Warnf("Rejecting attempt to change user to %q for session %v.", pkt.User(), sessionCtx)
 			return
+		case *protocol.ResetConnection:
+			// ProxySQL does not implement COM_RESET_CONNECTION and replies
+			// with an error which confuses drivers that reset pooled
+			// connections before reuse, so acknowledge the command on the
+			// pooler's behalf instead of relaying it.
+			if sessionCtx.Database.IsConnectionPooler() {
+				e.proxyConn.Conn.Sequence = pkt.Bytes()[3] + 1
+				if err := e.proxyConn.WriteOK(nil); err != nil {
+					log.WithError(err).Error("Failed to acknowledge connection reset.")
+					clientErrCh <- err
+					return
+				}
+				continue
+			}
 		case *protocol.Quit:
 			return
 		}
@@ -298,6 +488,20 @@ func (e *Engine) receiveFromServer(serverConn, clientConn net.Conn, serverErrCh
 			serverErrCh <- err
 			return
 		}
+		if e.masker != nil {
+			packet = e.masker.process(packet)
+		}
+		// The MySQL engine relays raw packets without tracking result set
+		// state so the limiter counts bytes only; row caps are enforced
+		// by protocol-aware engines such as Postgres.
+		if e.resultLimiter != nil {
+			if err := e.resultLimiter.Add(0, int64(len(packet))); err != nil {
+				log.Infof("Closing session due to %v.", err)
+				e.SendError(err)
+				serverErrCh <- err
+				return
+			}
+		}
 		_, err = protocol.WritePacket(packet, clientConn)
 		if err != nil {
 			log.WithError(err).Error("Failed to write client packet.")
@@ -0,0 +1,253 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"regexp"
+	"sync"
+
+	"github.com/gravitational/teleport/lib/utils"
+
+	"github.com/siddontang/go-mysql/mysql"
+
+	"github.com/gravitational/trace"
+	"github.com/sirupsen/logrus"
+)
+
+// maskedValue is what clients see in place of the original value of a
+// masked column.
+const maskedValue = "******"
+
+// resultSetMasker rewrites text protocol result sets replacing values of
+// columns matching the configured name patterns with a mask before they
+// are relayed to the client.
+//
+// It is fed all packets flowing from the server to the client and tracks
+// enough protocol state to locate column definitions and row data packets
+// of a result set: a COM_QUERY response starts with a column count packet
+// followed by column definitions, then rows until an EOF (or OK) packet.
+//
+// https://dev.mysql.com/doc/internals/en/com-query-response.html
+type resultSetMasker struct {
+	// patterns are compiled column name patterns to mask.
+	patterns []*regexp.Regexp
+	// log is used for logging.
+	log logrus.FieldLogger
+	// mu guards the state below which is updated from both client and
+	// server receive goroutines.
+	mu sync.Mutex
+	// state is the current result set parser state.
+	state maskerState
+	// columnsLeft is the number of column definitions left to read.
+	columnsLeft uint64
+	// masked flags which columns of the current result set are masked.
+	masked []bool
+}
+
+// maskerState represents the position within a COM_QUERY response.
+type maskerState int
+
+const (
+	// stateIdle means no result set is expected, packets pass through.
+	stateIdle maskerState = iota
+	// stateColumnCount means the next server packet is a query response.
+	stateColumnCount
+	// stateColumns means column definition packets are being read.
+	stateColumns
+	// stateColumnsDone means all column definitions have been read and the
+	// next packet is either an intermediate EOF or the first row.
+	stateColumnsDone
+	// stateRows means row data packets are being read and masked.
+	stateRows
+)
+
+// newResultSetMasker returns a masker for the provided column name glob
+// patterns, e.g. "*_ssn" or "password".
+func newResultSetMasker(patterns []string, log logrus.FieldLogger) (*resultSetMasker, error) {
+	masker := &resultSetMasker{log: log}
+	for _, pattern := range patterns {
+		expr, err := regexp.Compile("(?i)^" + utils.GlobToRegexp(pattern) + "$")
+		if err != nil {
+			return nil, trace.BadParameter("invalid masked column pattern %q: %v", pattern, err)
+		}
+		masker.patterns = append(masker.patterns, expr)
+	}
+	return masker, nil
+}
+
+// expectResultSet lets the masker know a query was sent to the server so
+// the next server packet starts a COM_QUERY response.
+func (m *resultSetMasker) expectResultSet() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.state = stateColumnCount
+	m.masked = nil
+}
+
+// process inspects the next server packet (raw bytes including the 4-byte
+// header) and returns the packet to relay to the client, masking row values
+// when the current result set contains masked columns.
+func (m *resultSetMasker) process(pkt []byte) []byte {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(pkt) < 5 {
+		return pkt
+	}
+	payload := pkt[4:]
+	switch m.state {
+	case stateColumnCount:
+		switch payload[0] {
+		case mysql.OK_HEADER, mysql.ERR_HEADER, mysql.LocalInFile_HEADER:
+			// Not a result set (e.g. a DML statement or an error).
+			m.state = stateIdle
+			return pkt
+		}
+		count, isNull, n := mysql.LengthEncodedInt(payload)
+		if isNull || n == 0 || count == 0 {
+			m.state = stateIdle
+			return pkt
+		}
+		m.columnsLeft = count
+		m.state = stateColumns
+	case stateColumns:
+		name, err := parseColumnName(payload)
+		if err != nil {
+			m.log.WithError(err).Debug("Failed to parse column definition packet, disabling masking for this result set.")
+			m.state = stateIdle
+			return pkt
+		}
+		m.masked = append(m.masked, m.isMasked(name))
+		m.columnsLeft--
+		if m.columnsLeft == 0 {
+			m.state = stateColumnsDone
+		}
+	case stateColumnsDone:
+		m.state = stateRows
+		// Unless the client negotiated CLIENT_DEPRECATE_EOF, column
+		// definitions are separated from rows by an EOF packet.
+		if isEOFPacket(payload) {
+			return pkt
+		}
+		return m.maskRow(pkt)
+	case stateRows:
+		if payload[0] == mysql.ERR_HEADER {
+			m.state = stateIdle
+			return pkt
+		}
+		if isEOFPacket(payload) {
+			// If the server indicates more result sets follow (e.g. a
+			// stored procedure call), the next packet is another column
+			// count packet.
+			if len(payload) >= 5 && (uint16(payload[3])|uint16(payload[4])<<8)&mysql.SERVER_MORE_RESULTS_EXISTS != 0 {
+				m.state = stateColumnCount
+				m.masked = nil
+			} else {
+				m.state = stateIdle
+			}
+			return pkt
+		}
+		return m.maskRow(pkt)
+	}
+	return pkt
+}
+
+// isMasked returns true if the provided column name matches any of the
+// masked column patterns.
+func (m *resultSetMasker) isMasked(name string) bool {
+	for _, pattern := range m.patterns {
+		if pattern.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// maskRow rewrites a text protocol row data packet replacing values of
+// masked columns, keeping the original sequence number.
+//
+// https://dev.mysql.com/doc/internals/en/com-query-response.html#packet-ProtocolText::ResultsetRow
+func (m *resultSetMasker) maskRow(pkt []byte) []byte {
+	var mask bool
+	for _, masked := range m.masked {
+		mask = mask || masked
+	}
+	if !mask {
+		return pkt
+	}
+	payload := pkt[4:]
+	rewritten := make([]byte, 0, len(payload))
+	for i, pos := 0, 0; pos < len(payload); i++ {
+		if i >= len(m.masked) {
+			// More values than column definitions, shouldn't happen so
+			// pass the packet through unmodified to be safe.
+			return pkt
+		}
+		// NULL is sent as a single 0xfb byte instead of a string.
+		if payload[pos] == 0xfb {
+			rewritten = append(rewritten, payload[pos])
+			pos++
+			continue
+		}
+		value, _, n, err := mysql.LengthEncodedString(payload[pos:])
+		if err != nil {
+			m.log.WithError(err).Debug("Failed to parse row data packet, passing it through unmodified.")
+			return pkt
+		}
+		if m.masked[i] {
+			value = []byte(maskedValue)
+		}
+		rewritten = append(rewritten, mysql.PutLengthEncodedString(value)...)
+		pos += n
+	}
+	// Rebuild the packet header with the new payload length.
+	masked := make([]byte, 4, 4+len(rewritten))
+	masked[0] = byte(len(rewritten))
+	masked[1] = byte(len(rewritten) >> 8)
+	masked[2] = byte(len(rewritten) >> 16)
+	masked[3] = pkt[3] // Sequence number.
+	return append(masked, rewritten...)
+}
+
+// parseColumnName extracts the column name (or alias) from a column
+// definition packet payload.
+//
+// https://dev.mysql.com/doc/internals/en/com-query-response.html#packet-Protocol::ColumnDefinition41
+func parseColumnName(payload []byte) (string, error) {
+	pos := 0
+	// Skip catalog, schema, table and org_table fields preceding the
+	// column name.
+	for i := 0; i < 4; i++ {
+		n, err := mysql.SkipLengthEncodedString(payload[pos:])
+		if err != nil {
+			return "", trace.Wrap(err)
+		}
+		pos += n
+	}
+	name, _, _, err := mysql.LengthEncodedString(payload[pos:])
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	return string(name), nil
+}
+
+// isEOFPacket returns true if the payload is an EOF packet which, among
+// other things, indicates the end of a result set.
+//
+// https://dev.mysql.com/doc/internals/en/packet-EOF_Packet.html
+func isEOFPacket(payload []byte) bool {
+	return payload[0] == mysql.EOF_HEADER && len(payload) < 9
+}
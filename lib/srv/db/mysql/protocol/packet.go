@@ -89,6 +89,48 @@ type Quit struct {
 	packet
 }
 
+// InitDB represents the COM_INIT_DB command.
+//
+// https://dev.mysql.com/doc/internals/en/com-init-db.html
+type InitDB struct {
+	packet
+	// schemaName is the name of the schema the session switches to.
+	schemaName string
+}
+
+// SchemaName returns the name of the schema the session switches to.
+func (p *InitDB) SchemaName() string {
+	return p.schemaName
+}
+
+// FieldList represents the COM_FIELD_LIST command.
+//
+// https://dev.mysql.com/doc/internals/en/com-field-list.html
+type FieldList struct {
+	packet
+	// table is the name of the table to list columns for.
+	table string
+	// wildcard is the field wildcard, may be empty.
+	wildcard string
+}
+
+// Table returns the name of the table to list columns for.
+func (p *FieldList) Table() string {
+	return p.table
+}
+
+// Wildcard returns the field wildcard, may be empty.
+func (p *FieldList) Wildcard() string {
+	return p.wildcard
+}
+
+// ResetConnection represents the COM_RESET_CONNECTION command.
+//
+// https://dev.mysql.com/doc/internals/en/com-reset-connection.html
+type ResetConnection struct {
+	packet
+}
+
 // ChangeUser represents the COM_CHANGE_USER command.
 type ChangeUser struct {
 	packet
@@ -143,6 +185,34 @@ func ParsePacket(conn net.Conn) (Packet, error) {
 	case mysql.COM_QUIT:
 		return &Quit{packet: packet}, nil
 
+	case mysql.COM_INIT_DB:
+		// Be a bit paranoid and make sure the packet is not truncated.
+		if len(packetBytes) < 5 {
+			return nil, trace.BadParameter("failed to parse COM_INIT_DB packet: %v", packetBytes)
+		}
+		// 4-byte packet header + 1-byte payload header, then schema name.
+		return &InitDB{packet: packet, schemaName: string(packetBytes[5:])}, nil
+
+	case mysql.COM_FIELD_LIST:
+		if len(packetBytes) < 5 {
+			return nil, trace.BadParameter("failed to parse COM_FIELD_LIST packet: %v", packetBytes)
+		}
+		// Table is the first null-terminated string in the payload followed
+		// by an optional field wildcard:
+		// https://dev.mysql.com/doc/internals/en/com-field-list.html
+		idx := bytes.IndexByte(packetBytes[5:], 0x00)
+		if idx < 0 {
+			return nil, trace.BadParameter("failed to parse COM_FIELD_LIST packet: %v", packetBytes)
+		}
+		return &FieldList{
+			packet:   packet,
+			table:    string(packetBytes[5 : 5+idx]),
+			wildcard: string(packetBytes[5+idx+1:]),
+		}, nil
+
+	case mysql.COM_RESET_CONNECTION:
+		return &ResetConnection{packet: packet}, nil
+
 	case mysql.COM_CHANGE_USER:
 		if len(packetBytes) < 5 {
 			return nil, trace.BadParameter("failed to parse COM_CHANGE_USER packet: %s", packetBytes)
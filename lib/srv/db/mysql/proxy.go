@@ -70,7 +70,13 @@ func (p *Proxy) HandleConnection(ctx context.Context, clientConn net.Conn) (err
 			err = trace.BadParameter("failed to handle MySQL client connection")
 		}
 		if err != nil {
-			if writeErr := server.WriteError(err); writeErr != nil {
+			sendErr := err
+			// Use a proper MySQL error code for rate limit errors so client
+			// drivers can report them appropriately.
+			if trace.IsLimitExceeded(err) {
+				sendErr = mysql.NewError(mysql.ER_TOO_MANY_USER_CONNECTIONS, err.Error())
+			}
+			if writeErr := server.WriteError(sendErr); writeErr != nil {
 				p.Log.WithError(writeErr).Debugf("Failed to send error %q to MySQL client.", err)
 			}
 		}
@@ -185,7 +191,7 @@ func (p *Proxy) maybeReadProxyLine(conn *multiplexer.Conn) error {
 	if err != nil {
 		return trace.Wrap(err)
 	}
-	if proto != multiplexer.ProtoProxy {
+	if proto != multiplexer.ProtoProxy && proto != multiplexer.ProtoProxyV2 {
 		return nil
 	}
 	proxyLine, err := conn.ReadProxyLine()
@@ -0,0 +1,106 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"strings"
+)
+
+// splitStatements splits the text of a COM_QUERY command into individual
+// statements separated by semicolons.
+//
+// A single protocol message can carry multiple (stacked) statements when
+// the client enables CLIENT_MULTI_STATEMENTS so auditing the message as a
+// single query would hide all but the first statement. The splitter is
+// aware of string literals, quoted identifiers and comments so semicolons
+// inside them don't produce bogus splits.
+func splitStatements(query string) (statements []string) {
+	var sb strings.Builder
+	appendStatement := func() {
+		if statement := strings.TrimSpace(sb.String()); statement != "" {
+			statements = append(statements, statement)
+		}
+		sb.Reset()
+	}
+	var quote byte // Opening quote of the current literal, 0 when outside.
+	var lineComment, blockComment bool
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case lineComment:
+			if c == '\n' {
+				lineComment = false
+			}
+		case blockComment:
+			if c == '*' && i+1 < len(query) && query[i+1] == '/' {
+				blockComment = false
+				sb.WriteByte(c)
+				i++
+				c = query[i]
+			}
+		case quote != 0:
+			switch c {
+			case '\\':
+				// Backslash escapes the next character inside a string
+				// literal (but not inside a backtick-quoted identifier).
+				if quote != '`' && i+1 < len(query) {
+					sb.WriteByte(c)
+					i++
+					c = query[i]
+				}
+			case quote:
+				// A doubled quote character is an escaped quote, anything
+				// else closes the literal.
+				if i+1 < len(query) && query[i+1] == quote {
+					sb.WriteByte(c)
+					i++
+				} else {
+					quote = 0
+				}
+			}
+		default:
+			switch c {
+			case '\'', '"', '`':
+				quote = c
+			case '#':
+				lineComment = true
+			case '-':
+				// "--" starts a comment only when followed by whitespace
+				// (or end of query) per MySQL comment syntax.
+				if strings.HasPrefix(query[i:], "--") &&
+					(i+2 == len(query) || query[i+2] == ' ' || query[i+2] == '\t' || query[i+2] == '\n') {
+					lineComment = true
+				}
+			case '/':
+				// Consume the entire "/*" opener so the '*' is not
+				// mistaken for the start of the "*/" terminator.
+				if i+1 < len(query) && query[i+1] == '*' {
+					blockComment = true
+					sb.WriteByte(c)
+					i++
+					c = query[i]
+				}
+			case ';':
+				appendStatement()
+				continue
+			}
+		}
+		sb.WriteByte(c)
+	}
+	appendStatement()
+	return statements
+}
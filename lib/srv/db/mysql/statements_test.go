@@ -0,0 +1,89 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestSplitStatements verifies stacked queries are split into individual
+// statements without being confused by literals and comments.
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{
+			name:  "single statement",
+			query: "select 1",
+			want:  []string{"select 1"},
+		},
+		{
+			name:  "single statement with trailing semicolon",
+			query: "select 1;",
+			want:  []string{"select 1"},
+		},
+		{
+			name:  "multiple statements",
+			query: "select 1; drop table users; select 2",
+			want:  []string{"select 1", "drop table users", "select 2"},
+		},
+		{
+			name:  "semicolon inside string literal",
+			query: "select 'a;b'; select 2",
+			want:  []string{"select 'a;b'", "select 2"},
+		},
+		{
+			name:  "semicolon inside quoted identifier",
+			query: "select `a;b` from t",
+			want:  []string{"select `a;b` from t"},
+		},
+		{
+			name:  "escaped quote inside literal",
+			query: `select 'it\'s;fine'; select 2`,
+			want:  []string{`select 'it\'s;fine'`, "select 2"},
+		},
+		{
+			name:  "doubled quote inside literal",
+			query: "select 'it'';fine'; select 2",
+			want:  []string{"select 'it'';fine'", "select 2"},
+		},
+		{
+			name:  "semicolon inside line comment",
+			query: "select 1 -- drop;\n; select 2",
+			want:  []string{"select 1 -- drop;", "select 2"},
+		},
+		{
+			name:  "semicolon inside block comment",
+			query: "select /* a;b */ 1; select 2",
+			want:  []string{"select /* a;b */ 1", "select 2"},
+		},
+		{
+			name:  "empty statements are dropped",
+			query: ";;select 1;;",
+			want:  []string{"select 1"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.want, splitStatements(test.query))
+		})
+	}
+}
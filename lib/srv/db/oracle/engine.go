@@ -0,0 +1,265 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package oracle implements the Oracle database access engine.
+//
+// The engine terminates the TNS connection from the client, performs
+// authorization checks based on the service name and user from the
+// connect descriptor and relays packets to the database over a mutual
+// TLS connection. The Oracle server is expected to be configured with a
+// TCPS listener and a wallet trusting the Teleport database client CA so
+// the database authenticates users by their client certificate, no
+// passwords are involved.
+package oracle
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+
+	"github.com/gravitational/teleport/lib/defaults"
+	"github.com/gravitational/teleport/lib/services"
+	"github.com/gravitational/teleport/lib/srv/db/common"
+	"github.com/gravitational/teleport/lib/srv/db/common/role"
+	"github.com/gravitational/teleport/lib/srv/db/oracle/protocol"
+	"github.com/gravitational/teleport/lib/utils"
+
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+	"github.com/sirupsen/logrus"
+)
+
+// Engine implements the Oracle database service that accepts client
+// connections coming over reverse tunnel from the proxy and proxies them
+// between the proxy and the Oracle database instance.
+//
+// Implements common.Engine.
+type Engine struct {
+	// Auth handles database access authentication.
+	Auth common.Auth
+	// Audit emits database access audit events.
+	Audit common.Audit
+	// Context is the database server close context.
+	Context context.Context
+	// Clock is the clock interface.
+	Clock clockwork.Clock
+	// Log is used for logging.
+	Log logrus.FieldLogger
+	// clientConn is an incoming client connection.
+	clientConn net.Conn
+}
+
+// InitializeConnection initializes the client connection.
+func (e *Engine) InitializeConnection(clientConn net.Conn, _ *common.Session) error {
+	e.clientConn = clientConn
+	return nil
+}
+
+// SendError sends an error to the connected client in a TNS refuse packet
+// the way an Oracle listener would.
+func (e *Engine) SendError(err error) {
+	if err == nil || utils.IsOKNetworkError(err) {
+		return
+	}
+	if errSend := protocol.WriteRefuse(e.clientConn, err); errSend != nil {
+		e.Log.WithError(errSend).Debugf("Failed to send error %q to Oracle client.", err)
+	}
+}
+
+// HandleConnection processes the connection from Oracle proxy coming over
+// reverse tunnel.
+//
+// It reads the client connect packet, performs authorization checks and
+// relays the connection to the database over mutual TLS.
+func (e *Engine) HandleConnection(ctx context.Context, sessionCtx *common.Session) error {
+	connectPacket, descriptor, err := e.readClientConnect()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	e.Log.Debugf("Client connect descriptor: %v.", descriptor.Raw)
+	if sessionCtx.DatabaseName == "" && descriptor.ServiceName() != "" {
+		sessionCtx.DatabaseName = descriptor.ServiceName()
+	}
+	// Perform authorization checks.
+	err = e.checkAccess(ctx, sessionCtx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	serverConn, err := e.connect(ctx, sessionCtx, connectPacket)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer serverConn.Close()
+	e.Audit.OnSessionStart(e.Context, sessionCtx, nil)
+	defer e.Audit.OnSessionEnd(e.Context, sessionCtx)
+	// Copy between the connections.
+	clientErrCh := make(chan error, 1)
+	serverErrCh := make(chan error, 1)
+	go e.receiveFromClient(e.clientConn, serverConn, clientErrCh, sessionCtx)
+	go e.receiveFromServer(serverConn, e.clientConn, serverErrCh)
+	select {
+	case err := <-clientErrCh:
+		e.Log.WithError(err).Debug("Client done.")
+	case err := <-serverErrCh:
+		e.Log.WithError(err).Debug("Server done.")
+	case <-ctx.Done():
+		e.Log.Debug("Context canceled.")
+	}
+	return nil
+}
+
+// readClientConnect reads the client connect packet and its connect
+// descriptor which, when too long for the connect packet, follows in a
+// separate data packet.
+func (e *Engine) readClientConnect() ([]*protocol.Packet, *protocol.ConnectDescriptor, error) {
+	packet, err := protocol.ReadPacket(e.clientConn)
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	if packet.Type != protocol.PacketTypeConnect {
+		return nil, nil, trace.BadParameter("expected TNS connect packet, got %v", packet.Type)
+	}
+	descriptor, ok, err := protocol.ParseConnectPacket(packet)
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	if ok {
+		return []*protocol.Packet{packet}, descriptor, nil
+	}
+	// Long connect descriptors are sent in a follow-up data packet.
+	dataPacket, err := protocol.ReadPacket(e.clientConn)
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	if dataPacket.Type != protocol.PacketTypeData {
+		return nil, nil, trace.BadParameter("expected TNS data packet with connect descriptor, got %v", dataPacket.Type)
+	}
+	descriptor, err = protocol.ParseConnectDescriptor(string(dataPacket.Data))
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	return []*protocol.Packet{packet, dataPacket}, descriptor, nil
+}
+
+// connect establishes a mutual TLS connection to the Oracle database TCPS
+// listener and replays the client's connect packets over it.
+func (e *Engine) connect(ctx context.Context, sessionCtx *common.Session, connectPacket []*protocol.Packet) (net.Conn, error) {
+	tlsConfig, err := e.Auth.GetTLSConfig(ctx, sessionCtx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: defaults.DatabaseConnectTimeout},
+		Config:    tlsConfig,
+	}
+	serverConn, err := dialer.DialContext(ctx, "tcp", sessionCtx.Database.GetURI())
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	for _, packet := range connectPacket {
+		if err := protocol.WritePacket(serverConn, packet); err != nil {
+			serverConn.Close()
+			return nil, trace.Wrap(err)
+		}
+	}
+	return serverConn, nil
+}
+
+// checkAccess does authorization check for Oracle connection about to be
+// established.
+func (e *Engine) checkAccess(ctx context.Context, sessionCtx *common.Session) error {
+	ap, err := e.Auth.GetAuthPreference(ctx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	mfaParams := services.AccessMFAParams{
+		Verified:       sessionCtx.Identity.MFAVerified != "",
+		AlwaysRequired: ap.GetRequireSessionMFA(),
+	}
+	dbRoleMatchers := role.DatabaseRoleMatchers(
+		defaults.ProtocolOracle,
+		sessionCtx.DatabaseUser,
+		sessionCtx.DatabaseName,
+		sessionCtx.Identity.ClientIP,
+	)
+	err = sessionCtx.Checker.CheckAccess(
+		sessionCtx.Database,
+		mfaParams,
+		dbRoleMatchers...,
+	)
+	if err != nil {
+		e.Audit.OnSessionStart(e.Context, sessionCtx, err)
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// receiveFromClient relays TNS packets received from the Oracle client to
+// the database, auditing statements along the way.
+func (e *Engine) receiveFromClient(clientConn, serverConn net.Conn, clientErrCh chan<- error, sessionCtx *common.Session) {
+	log := e.Log.WithFields(logrus.Fields{
+		"from":   "client",
+		"client": clientConn.RemoteAddr(),
+		"server": serverConn.RemoteAddr(),
+	})
+	defer func() {
+		log.Debug("Stop receiving from client.")
+		close(clientErrCh)
+	}()
+	for {
+		packet, err := protocol.ReadPacket(clientConn)
+		if err != nil {
+			if utils.IsOKNetworkError(err) {
+				log.Debug("Client connection closed.")
+				return
+			}
+			log.WithError(err).Error("Failed to read client packet.")
+			clientErrCh <- err
+			return
+		}
+		if packet.Type == protocol.PacketTypeData {
+			if query := protocol.ExtractSQL(packet.Data); query != "" {
+				e.Audit.OnQuery(e.Context, sessionCtx, common.Query{Query: query})
+			}
+		}
+		err = protocol.WritePacket(serverConn, packet)
+		if err != nil {
+			log.WithError(err).Error("Failed to write server packet.")
+			clientErrCh <- err
+			return
+		}
+	}
+}
+
+// receiveFromServer relays TNS packets received from the database to the
+// Oracle client.
+func (e *Engine) receiveFromServer(serverConn, clientConn net.Conn, serverErrCh chan<- error) {
+	log := e.Log.WithFields(logrus.Fields{
+		"from":   "server",
+		"client": clientConn.RemoteAddr(),
+		"server": serverConn.RemoteAddr(),
+	})
+	defer func() {
+		log.Debug("Stop receiving from server.")
+		close(serverErrCh)
+	}()
+	// Server packets don't need to be interpreted so relay the raw stream.
+	_, err := io.Copy(clientConn, serverConn)
+	if err != nil && !utils.IsOKNetworkError(err) {
+		serverErrCh <- err
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protocol
+
+import (
+	"encoding/binary"
+	"strings"
+
+	"github.com/gravitational/trace"
+)
+
+// connectDataLengthOffset is the offset of the connect data length field
+// within the connect packet payload.
+const connectDataLengthOffset = 16
+
+// ConnectDescriptor is a parsed TNS connect descriptor, e.g.:
+//
+//	(DESCRIPTION=(ADDRESS=(PROTOCOL=tcp)(HOST=oracle)(PORT=1521))
+//	    (CONNECT_DATA=(SERVICE_NAME=XEPDB1)(CID=(USER=alice))))
+type ConnectDescriptor struct {
+	// Raw is the original descriptor string.
+	Raw string
+	// values maps upper-case keyword paths like "CONNECT_DATA.SERVICE_NAME"
+	// to their values.
+	values map[string]string
+}
+
+// ServiceName returns the requested database service name or SID.
+func (d *ConnectDescriptor) ServiceName() string {
+	if service, ok := d.values["DESCRIPTION.CONNECT_DATA.SERVICE_NAME"]; ok {
+		return service
+	}
+	return d.values["DESCRIPTION.CONNECT_DATA.SID"]
+}
+
+// User returns the client-reported user from the connect data, may be
+// empty.
+func (d *ConnectDescriptor) User() string {
+	return d.values["DESCRIPTION.CONNECT_DATA.CID.USER"]
+}
+
+// ParseConnectPacket extracts the connect descriptor from a connect
+// packet payload.
+//
+// When the descriptor is too long to fit in the connect packet itself its
+// length is set but the data is sent in a separate data packet, in which
+// case an empty descriptor and false are returned and the caller should
+// parse the follow-up packet with ParseConnectDescriptor.
+func ParseConnectPacket(packet *Packet) (*ConnectDescriptor, bool, error) {
+	if len(packet.Data) < connectDataLengthOffset+4 {
+		return nil, false, trace.BadParameter("truncated TNS connect packet")
+	}
+	length := int(binary.BigEndian.Uint16(packet.Data[connectDataLengthOffset:]))
+	// Offset is counted from the start of the packet including the header.
+	offset := int(binary.BigEndian.Uint16(packet.Data[connectDataLengthOffset+2:])) - packetHeaderSize
+	if length == 0 || offset < 0 {
+		return nil, false, nil
+	}
+	if offset+length > len(packet.Data) {
+		return nil, false, trace.BadParameter("invalid TNS connect data offset %v length %v", offset, length)
+	}
+	descriptor, err := ParseConnectDescriptor(string(packet.Data[offset : offset+length]))
+	if err != nil {
+		return nil, false, trace.Wrap(err)
+	}
+	return descriptor, true, nil
+}
+
+// ParseConnectDescriptor parses a TNS connect descriptor string.
+func ParseConnectDescriptor(raw string) (*ConnectDescriptor, error) {
+	descriptor := &ConnectDescriptor{
+		Raw:    raw,
+		values: make(map[string]string),
+	}
+	rest, err := parseKeyValue(strings.TrimSpace(raw), nil, descriptor.values)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if strings.TrimSpace(rest) != "" {
+		return nil, trace.BadParameter("trailing data in connect descriptor")
+	}
+	return descriptor, nil
+}
+
+// parseKeyValue parses a single "(KEY=value)" or "(KEY=(...)...)" element
+// at the start of the input, recording leaf values under their dot-joined
+// upper-case keyword path, and returns the unparsed remainder.
+func parseKeyValue(s string, path []string, values map[string]string) (string, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "(") {
+		return "", trace.BadParameter("expected '(' in connect descriptor")
+	}
+	key, rest, found := strings.Cut(s[1:], "=")
+	if !found {
+		return "", trace.BadParameter("expected '=' in connect descriptor")
+	}
+	path = append(path, strings.ToUpper(strings.TrimSpace(key)))
+	rest = strings.TrimSpace(rest)
+	if strings.HasPrefix(rest, "(") {
+		// Nested list of key-value pairs.
+		var err error
+		for strings.HasPrefix(strings.TrimSpace(rest), "(") {
+			rest, err = parseKeyValue(rest, path, values)
+			if err != nil {
+				return "", trace.Wrap(err)
+			}
+			rest = strings.TrimSpace(rest)
+		}
+		if !strings.HasPrefix(rest, ")") {
+			return "", trace.BadParameter("expected ')' in connect descriptor")
+		}
+		return rest[1:], nil
+	}
+	// Plain value terminated by the closing parenthesis.
+	value, rest, found := strings.Cut(rest, ")")
+	if !found {
+		return "", trace.BadParameter("expected ')' in connect descriptor")
+	}
+	values[strings.Join(path, ".")] = strings.TrimSpace(value)
+	return rest, nil
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protocol
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/gravitational/trace"
+)
+
+// refuseUserReason is the "user refused" reason code sent in refuse
+// packets.
+const refuseUserReason = 0x22
+
+// WriteRefuse sends a TNS refuse packet with the error to the client,
+// the way an Oracle listener reports connection errors.
+func WriteRefuse(w io.Writer, err error) error {
+	// ORA-12514 is the generic "listener does not currently know of
+	// service" error clients know how to display.
+	message := fmt.Sprintf("(DESCRIPTION=(ERR=12514)(ERROR_STACK=(ERROR=(CODE=12514)(EMFI=4))(TELEPORT_ERROR=%v)))", err)
+	data := make([]byte, 4, 4+len(message))
+	data[0] = refuseUserReason
+	binary.BigEndian.PutUint16(data[2:], uint16(len(message)))
+	data = append(data, message...)
+	return trace.Wrap(WritePacket(w, &Packet{
+		Type: PacketTypeRefuse,
+		Data: data,
+	}))
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package protocol implements parts of the Oracle TNS wire protocol the
+// Teleport Oracle database engine needs: packet framing, connect packet
+// parsing and refuse packets for error reporting.
+//
+// TNS is not publicly documented, the packet layouts follow the de-facto
+// descriptions used by open-source Oracle drivers.
+package protocol
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/gravitational/trace"
+)
+
+const (
+	// packetHeaderSize is the size of the TNS packet header.
+	packetHeaderSize = 8
+	// maxPacketSize is the maximum accepted TNS packet size, larger
+	// lengths indicate a corrupted stream.
+	maxPacketSize = 64 * 1024
+)
+
+// TNS packet types.
+const (
+	// PacketTypeConnect is the client connection request packet.
+	PacketTypeConnect = 1
+	// PacketTypeAccept is the server connection acceptance packet.
+	PacketTypeAccept = 2
+	// PacketTypeRefuse is the server connection refusal packet.
+	PacketTypeRefuse = 4
+	// PacketTypeRedirect is the server redirection packet.
+	PacketTypeRedirect = 5
+	// PacketTypeData is the data packet exchanged after session setup.
+	PacketTypeData = 6
+	// PacketTypeResend is the server request to resend the connect packet.
+	PacketTypeResend = 11
+	// PacketTypeMarker is the break/reset marker packet.
+	PacketTypeMarker = 12
+)
+
+// Packet represents a single TNS packet.
+type Packet struct {
+	// Type is the packet type, one of the PacketType constants.
+	Type byte
+	// Flags is the packet flags byte.
+	Flags byte
+	// Data is the packet payload without the header.
+	Data []byte
+}
+
+// ReadPacket reads a single TNS packet from the reader.
+func ReadPacket(r io.Reader) (*Packet, error) {
+	header := make([]byte, packetHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	// Packet length includes the header and is big-endian unlike most
+	// other protocols.
+	length := int(binary.BigEndian.Uint16(header))
+	if length < packetHeaderSize || length > maxPacketSize {
+		return nil, trace.BadParameter("invalid TNS packet length %v", length)
+	}
+	data := make([]byte, length-packetHeaderSize)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	return &Packet{
+		Type:  header[4],
+		Flags: header[5],
+		Data:  data,
+	}, nil
+}
+
+// WritePacket writes a single TNS packet to the writer.
+func WritePacket(w io.Writer, packet *Packet) error {
+	length := packetHeaderSize + len(packet.Data)
+	if length > maxPacketSize {
+		return trace.BadParameter("TNS packet length %v exceeds maximum", length)
+	}
+	header := make([]byte, packetHeaderSize)
+	binary.BigEndian.PutUint16(header, uint16(length))
+	header[4] = packet.Type
+	header[5] = packet.Flags
+	if _, err := w.Write(append(header, packet.Data...)); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protocol
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestPacketRoundtrip verifies TNS packets are serialized and parsed
+// back.
+func TestPacketRoundtrip(t *testing.T) {
+	packet := &Packet{
+		Type: PacketTypeData,
+		Data: []byte("test data"),
+	}
+	var buf bytes.Buffer
+	require.NoError(t, WritePacket(&buf, packet))
+
+	readPacket, err := ReadPacket(&buf)
+	require.NoError(t, err)
+	require.Equal(t, packet, readPacket)
+}
+
+// TestParseConnectPacket verifies connect descriptor extraction from
+// connect packets.
+func TestParseConnectPacket(t *testing.T) {
+	raw := "(DESCRIPTION=(ADDRESS=(PROTOCOL=tcp)(HOST=oracle.example.com)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=XEPDB1)(CID=(PROGRAM=sqlplus)(USER=alice))))"
+	data := make([]byte, 26)
+	binary.BigEndian.PutUint16(data[connectDataLengthOffset:], uint16(len(raw)))
+	binary.BigEndian.PutUint16(data[connectDataLengthOffset+2:], uint16(len(data)+packetHeaderSize))
+	data = append(data, raw...)
+
+	descriptor, ok, err := ParseConnectPacket(&Packet{Type: PacketTypeConnect, Data: data})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "XEPDB1", descriptor.ServiceName())
+	require.Equal(t, "alice", descriptor.User())
+}
+
+// TestExtractSQL verifies best-effort statement extraction from data
+// packets.
+func TestExtractSQL(t *testing.T) {
+	data := append([]byte{0x11, 0x69, 0x01, 0x01, 0x02},
+		[]byte("select * from dual")...)
+	data = append(data, 0x00, 0x01)
+
+	require.Equal(t, "select * from dual", ExtractSQL(data))
+	require.Empty(t, ExtractSQL([]byte{0x01, 0x02, 0x03}))
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protocol
+
+import (
+	"strings"
+)
+
+// sqlKeywords are the statement keywords SQL text extraction looks for at
+// the start of a candidate string.
+var sqlKeywords = []string{
+	"SELECT", "INSERT", "UPDATE", "DELETE", "MERGE", "CREATE", "ALTER",
+	"DROP", "TRUNCATE", "GRANT", "REVOKE", "BEGIN", "DECLARE", "CALL",
+	"COMMIT", "ROLLBACK", "WITH", "EXPLAIN", "LOCK", "ANALYZE", "AUDIT",
+	"COMMENT",
+}
+
+// ExtractSQL extracts SQL statement text from a TNS data packet payload
+// on a best-effort basis.
+//
+// The TTC layer carrying statements inside data packets is complex and
+// version-dependent so instead of a full parser the packet is scanned for
+// printable character runs starting with a SQL keyword, which is how the
+// statement text appears on the wire regardless of the surrounding
+// message format. Returns an empty string if no statement is found.
+func ExtractSQL(data []byte) string {
+	for start := 0; start < len(data); {
+		// Find the next printable run.
+		for start < len(data) && !isPrintable(data[start]) {
+			start++
+		}
+		end := start
+		for end < len(data) && isPrintable(data[end]) {
+			end++
+		}
+		if candidate := strings.TrimSpace(string(data[start:end])); isSQL(candidate) {
+			return candidate
+		}
+		start = end
+	}
+	return ""
+}
+
+// isSQL returns true if the string looks like a SQL statement.
+func isSQL(s string) bool {
+	keyword, _, _ := strings.Cut(s, " ")
+	for _, known := range sqlKeywords {
+		if strings.EqualFold(keyword, known) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPrintable returns true for characters that can appear in statement
+// text.
+func isPrintable(c byte) bool {
+	return c >= 0x20 && c < 0x7F || c == '\n' || c == '\t'
+}
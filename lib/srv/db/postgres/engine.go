@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"strings"
 
 	"github.com/gravitational/teleport/api/types"
 	"github.com/gravitational/teleport/lib/services"
@@ -29,6 +30,7 @@ import (
 	"github.com/gravitational/teleport/lib/utils"
 
 	"github.com/jackc/pgconn"
+	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgproto3/v2"
 
 	"github.com/gravitational/trace"
@@ -54,11 +56,31 @@ type Engine struct {
 	Log logrus.FieldLogger
 	// client is a client connection.
 	client *pgproto3.Backend
+	// queryFilter blocks queries based on the user's role-based allow and
+	// deny patterns. It is nil when none of the user's roles set any.
+	queryFilter *common.QueryFilter
+	// preparedStatements maps prepared statement names to their query text
+	// so Execute messages can be audited with the actual query.
+	preparedStatements map[string]string
+	// portals maps destination portal names to the bound prepared
+	// statement and parameter values.
+	portals map[string]portal
+}
+
+// portal is a prepared statement bound with parameter values, ready for
+// execution.
+type portal struct {
+	// statement is the name of the bound prepared statement.
+	statement string
+	// parameters are the formatted bound parameter values.
+	parameters []string
 }
 
 // InitializeConnection initializes the client connection.
 func (e *Engine) InitializeConnection(clientConn net.Conn, sessionCtx *common.Session) error {
 	e.client = pgproto3.NewBackend(pgproto3.NewChunkReader(clientConn), clientConn)
+	e.preparedStatements = make(map[string]string)
+	e.portals = make(map[string]portal)
 
 	// The proxy is supposed to pass a startup message it received from
 	// the psql client over to us, so wait for it and extract database
@@ -82,6 +104,15 @@ func (e *Engine) SendError(err error) {
 // error message response so the client such as psql can display it
 // appropriately.
 func toErrorResponse(err error) *pgproto3.ErrorResponse {
+	// Use the standard "too many connections" error code for rate limit
+	// errors so client drivers can report them appropriately.
+	if trace.IsLimitExceeded(err) {
+		return &pgproto3.ErrorResponse{
+			Severity: "FATAL",
+			Code:     pgerrcode.TooManyConnections,
+			Message:  err.Error(),
+		}
+	}
 	var pgErr *pgconn.PgError
 	if !errors.As(trace.Unwrap(err), &pgErr) {
 		return &pgproto3.ErrorResponse{
@@ -109,6 +140,12 @@ func (e *Engine) HandleConnection(ctx context.Context, sessionCtx *common.Sessio
 	if err != nil {
 		return trace.Wrap(err)
 	}
+	// Setup query filtering if any of the user's roles set query allow
+	// or deny patterns.
+	e.queryFilter, err = common.NewQueryFilter(sessionCtx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
 	// This is where we connect to the actual Postgres database.
 	server, hijackedConn, err := e.connect(ctx, sessionCtx)
 	if err != nil {
@@ -116,7 +153,7 @@ func (e *Engine) HandleConnection(ctx context.Context, sessionCtx *common.Sessio
 	}
 	// Upon successful connect, indicate to the Postgres client that startup
 	// has been completed, and it can start sending queries.
-	err = e.makeClientReady(e.client, hijackedConn)
+	err = e.makeClientReady(e.client, hijackedConn, sessionCtx)
 	if err != nil {
 		return trace.Wrap(err)
 	}
@@ -174,10 +211,23 @@ func (e *Engine) handleStartup(client *pgproto3.Backend, sessionCtx *common.Sess
 			sessionCtx.DatabaseName = value
 		case "user":
 			sessionCtx.DatabaseUser = value
+		case "replication":
+			// Replication clients such as pg_basebackup and logical
+			// decoding connectors request walsender mode through this
+			// parameter, pass it along to the server.
+			e.Log.Debugf("Client requested replication mode %q.", value)
+			sessionCtx.StartupParameters[key] = value
 		default:
 			sessionCtx.StartupParameters[key] = value
 		}
 	}
+	// Keep a copy of the client-reported startup parameters for the
+	// connection attributes audit event, this is where clients report
+	// the program name via application_name.
+	sessionCtx.ConnectionAttributes = make(map[string]string, len(sessionCtx.StartupParameters))
+	for key, value := range sessionCtx.StartupParameters {
+		sessionCtx.ConnectionAttributes[key] = value
+	}
 	return nil
 }
 
@@ -195,6 +245,7 @@ func (e *Engine) checkAccess(ctx context.Context, sessionCtx *common.Session) er
 		sessionCtx.Database.GetProtocol(),
 		sessionCtx.DatabaseUser,
 		sessionCtx.DatabaseName,
+		sessionCtx.Identity.ClientIP,
 	)
 	err = sessionCtx.Checker.CheckAccess(
 		sessionCtx.Database,
@@ -249,7 +300,17 @@ take a few minutes to propagate):
 
 // makeClientReady indicates to the Postgres client (such as psql) that the
 // server is ready to accept messages from it.
-func (e *Engine) makeClientReady(client *pgproto3.Backend, hijackedConn *pgconn.HijackedConn) error {
+func (e *Engine) makeClientReady(client *pgproto3.Backend, hijackedConn *pgconn.HijackedConn, sessionCtx *common.Session) error {
+	// Connection poolers only relay the parameter statuses they track so
+	// fill in defaults for the parameters drivers commonly rely on.
+	if sessionCtx.Database.IsConnectionPooler() {
+		for name, value := range defaultParameterStatuses {
+			if _, ok := hijackedConn.ParameterStatuses[name]; !ok {
+				e.Log.Debugf("Connection pooler did not report parameter %q, defaulting to %q.", name, value)
+				hijackedConn.ParameterStatuses[name] = value
+			}
+		}
+	}
 	// AuthenticationOk indicates that the authentication was successful.
 	e.Log.Debug("Sending AuthenticationOk.")
 	if err := client.Send(&pgproto3.AuthenticationOk{}); err != nil {
@@ -284,6 +345,7 @@ func (e *Engine) makeClientReady(client *pgproto3.Backend, hijackedConn *pgconn.
 func (e *Engine) receiveFromClient(client *pgproto3.Backend, server *pgproto3.Frontend, clientErrCh chan<- error, sessionCtx *common.Session) {
 	log := e.Log.WithField("from", "client")
 	defer log.Debug("Stop receiving from client.")
+	var copyRows, copyBytes int64
 	for {
 		message, err := client.Receive()
 		if err != nil {
@@ -294,8 +356,19 @@ func (e *Engine) receiveFromClient(client *pgproto3.Backend, server *pgproto3.Fr
 		log.Debugf("Received client message: %#v.", message)
 		switch msg := message.(type) {
 		case *pgproto3.Query:
+			if err := e.checkQuery(client, sessionCtx, msg.String); err != nil {
+				clientErrCh <- err
+				return
+			}
 			e.auditQueryMessage(sessionCtx, msg)
+			if cmd, ok := parseReplicationCommand(msg.String); ok {
+				e.auditReplicationCommand(sessionCtx, cmd)
+			}
 		case *pgproto3.Parse:
+			if err := e.checkQuery(client, sessionCtx, msg.Query); err != nil {
+				clientErrCh <- err
+				return
+			}
 			e.auditParseMessage(sessionCtx, msg)
 		case *pgproto3.Bind:
 			e.auditBindMessage(sessionCtx, msg)
@@ -305,6 +378,17 @@ func (e *Engine) receiveFromClient(client *pgproto3.Backend, server *pgproto3.Fr
 			e.auditCloseMessage(sessionCtx, msg)
 		case *pgproto3.FunctionCall:
 			e.auditFuncCallMessage(sessionCtx, msg)
+		case *pgproto3.CopyData:
+			// Client sends CopyData during COPY IN, one message per row.
+			copyRows++
+			copyBytes += int64(len(msg.Data))
+		case *pgproto3.CopyDone:
+			e.Audit.OnQuery(e.Context, sessionCtx, common.Query{
+				Query: fmt.Sprintf("COPY IN: %v rows, %v bytes", copyRows, copyBytes),
+			})
+			copyRows, copyBytes = 0, 0
+		case *pgproto3.CopyFail:
+			copyRows, copyBytes = 0, 0
 		case *pgproto3.Terminate:
 			clientErrCh <- nil
 			return
@@ -318,16 +402,91 @@ func (e *Engine) receiveFromClient(client *pgproto3.Backend, server *pgproto3.Fr
 	}
 }
 
+// checkQuery verifies the query against the user's role-based query filters.
+// A blocked query is audited and the error is sent to the client before the
+// session is terminated.
+func (e *Engine) checkQuery(client *pgproto3.Backend, sessionCtx *common.Session, query string) error {
+	if e.queryFilter == nil {
+		return nil
+	}
+	err := e.queryFilter.CheckQuery(query)
+	if err == nil {
+		return nil
+	}
+	e.Audit.OnQuery(e.Context, sessionCtx, common.Query{Query: query, Error: err})
+	if sendErr := client.Send(toErrorResponse(err)); sendErr != nil {
+		e.Log.WithError(sendErr).Error("Failed to send error to client.")
+	}
+	return err
+}
+
 // auditQueryMessage processes Query wire message which indicates that client
 // is executing a simple query.
 func (e *Engine) auditQueryMessage(session *common.Session, msg *pgproto3.Query) {
 	e.Audit.OnQuery(e.Context, session, common.Query{Query: msg.String})
 }
 
+// replicationCommand is a parsed streaming replication protocol command
+// such as START_REPLICATION or CREATE_REPLICATION_SLOT.
+type replicationCommand struct {
+	// command is the normalized command keyword.
+	command string
+	// slot is the replication slot name the command refers to, if any.
+	slot string
+}
+
+// parseReplicationCommand parses commands of the streaming replication
+// protocol which replication clients (e.g. pg_basebackup, Debezium) send
+// as regular simple queries over a replication connection:
+//
+// https://www.postgresql.org/docs/current/protocol-replication.html
+func parseReplicationCommand(query string) (replicationCommand, bool) {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return replicationCommand{}, false
+	}
+	command := strings.ToUpper(fields[0])
+	switch command {
+	case "IDENTIFY_SYSTEM", "TIMELINE_HISTORY", "BASE_BACKUP":
+		return replicationCommand{command: command}, true
+	case "CREATE_REPLICATION_SLOT", "DROP_REPLICATION_SLOT":
+		// Slot name immediately follows the command keyword.
+		cmd := replicationCommand{command: command}
+		if len(fields) > 1 {
+			cmd.slot = strings.Trim(fields[1], `"`)
+		}
+		return cmd, true
+	case "START_REPLICATION":
+		// Slot name follows the optional SLOT keyword:
+		// START_REPLICATION [SLOT slot_name] [PHYSICAL|LOGICAL] ...
+		cmd := replicationCommand{command: command}
+		for i := 1; i < len(fields)-1; i++ {
+			if strings.ToUpper(fields[i]) == "SLOT" {
+				cmd.slot = strings.Trim(fields[i+1], `"`)
+				break
+			}
+		}
+		return cmd, true
+	}
+	return replicationCommand{}, false
+}
+
+// auditReplicationCommand emits an audit event for a streaming replication
+// protocol command so replication slot usage can be searched in the audit
+// log separately from regular queries.
+func (e *Engine) auditReplicationCommand(session *common.Session, cmd replicationCommand) {
+	query := cmd.command
+	if cmd.slot != "" {
+		query = fmt.Sprintf("%v: slot %q", cmd.command, cmd.slot)
+	}
+	e.Audit.OnQuery(e.Context, session, common.Query{Query: query})
+}
+
 // handleParseMesssage processes Parse wire message which indicates start of the
 // extended query protocol (prepared statements):
 // https://www.postgresql.org/docs/10/protocol-flow.html#PROTOCOL-FLOW-EXT-QUERY
 func (e *Engine) auditParseMessage(session *common.Session, msg *pgproto3.Parse) {
+	e.preparedStatements[msg.Name] = msg.Query
 	e.Audit.EmitEvent(e.Context, makeParseEvent(session, msg.Name, msg.Query))
 }
 
@@ -335,15 +494,35 @@ func (e *Engine) auditParseMessage(session *common.Session, msg *pgproto3.Parse)
 // statement for execution into what Postgres calls a "destination portal",
 // optionally binding it with parameters (for parameterized queries).
 func (e *Engine) auditBindMessage(session *common.Session, msg *pgproto3.Bind) {
+	parameters := formatParameters(msg.Parameters, msg.ParameterFormatCodes)
+	e.portals[msg.DestinationPortal] = portal{
+		statement:  msg.PreparedStatement,
+		parameters: parameters,
+	}
 	e.Audit.EmitEvent(e.Context, makeBindEvent(session, msg.PreparedStatement,
-		msg.DestinationPortal, formatParameters(msg.Parameters,
-			msg.ParameterFormatCodes)))
+		msg.DestinationPortal, parameters))
 }
 
 // auditExecuteMessage processes Execute wire message which indicates that
 // client is executing the previously parsed and bound prepared statement.
+//
+// In addition to the Execute event, a regular query event with the portal's
+// query text and bound parameter values is emitted so extended protocol
+// clients (most ORMs) produce the same audit trail as simple protocol ones.
 func (e *Engine) auditExecuteMessage(session *common.Session, msg *pgproto3.Execute) {
 	e.Audit.EmitEvent(e.Context, makeExecuteEvent(session, msg.Portal))
+	boundPortal, ok := e.portals[msg.Portal]
+	if !ok {
+		return
+	}
+	query, ok := e.preparedStatements[boundPortal.statement]
+	if !ok {
+		return
+	}
+	e.Audit.OnQuery(e.Context, session, common.Query{
+		Query:      query,
+		Parameters: boundPortal.parameters,
+	})
 }
 
 // auditCloseMessage processes Close wire message which indicates that client
@@ -351,8 +530,10 @@ func (e *Engine) auditExecuteMessage(session *common.Session, msg *pgproto3.Exec
 func (e *Engine) auditCloseMessage(session *common.Session, msg *pgproto3.Close) {
 	switch msg.ObjectType {
 	case closeTypePreparedStatement:
+		delete(e.preparedStatements, msg.Name)
 		e.Audit.EmitEvent(e.Context, makeCloseEvent(session, msg.Name, ""))
 	case closeTypeDestinationPortal:
+		delete(e.portals, msg.Name)
 		e.Audit.EmitEvent(e.Context, makeCloseEvent(session, "", msg.Name))
 	}
 }
@@ -374,15 +555,42 @@ func (e *Engine) auditFuncCallMessage(session *common.Session, msg *pgproto3.Fun
 func (e *Engine) receiveFromServer(server *pgproto3.Frontend, client *pgproto3.Backend, serverConn *pgconn.PgConn, serverErrCh chan<- error, sessionCtx *common.Session) {
 	log := e.Log.WithField("from", "server")
 	defer log.Debug("Stop receiving from server.")
+	rejectCopy := sessionCtx.Checker.RejectDatabaseCopy()
+	// abort sends the error to the client and terminates the session.
+	abort := func(err error) {
+		log.Infof("Closing session due to %v.", err)
+		if sendErr := client.Send(toErrorResponse(err)); sendErr != nil {
+			log.WithError(sendErr).Error("Failed to send error to client.")
+		}
+		serverErrCh <- err
+	}
+	limiter := common.NewResultLimiter(sessionCtx)
+	var copyRows, copyBytes int64
+	var streaming bool
+	// flushStreamingSummary emits an audit summary of a finished replication
+	// stream. Streams typically end with the connection closing rather than
+	// a CopyDone message, so it is called from both paths.
+	flushStreamingSummary := func() {
+		if !streaming {
+			return
+		}
+		e.Audit.OnQuery(e.Context, sessionCtx, common.Query{
+			Query: fmt.Sprintf("REPLICATION: %v messages, %v bytes", copyRows, copyBytes),
+		})
+		copyRows, copyBytes = 0, 0
+		streaming = false
+	}
 	for {
 		message, err := server.Receive()
 		if err != nil {
 			if serverConn.IsClosed() {
 				log.Debug("Server connection closed.")
+				flushStreamingSummary()
 				serverErrCh <- nil
 				return
 			}
 			log.WithError(err).Errorf("Failed to receive message from server.")
+			flushStreamingSummary()
 			serverErrCh <- err
 			return
 		}
@@ -391,6 +599,58 @@ func (e *Engine) receiveFromServer(server *pgproto3.Frontend, client *pgproto3.B
 		// messages received from the Postgres server (i.e. emitting
 		// an audit event), but for now just pass them along back to
 		// the client.
+		switch msg := message.(type) {
+		case *pgproto3.DataRow:
+			if limiter != nil {
+				var size int64
+				for _, value := range msg.Values {
+					size += int64(len(value))
+				}
+				if err := limiter.Add(1, size); err != nil {
+					abort(err)
+					return
+				}
+			}
+		case *pgproto3.ReadyForQuery:
+			if limiter != nil {
+				limiter.Reset()
+			}
+		case *pgproto3.CopyInResponse, *pgproto3.CopyOutResponse:
+			if rejectCopy {
+				abort(trace.AccessDenied("COPY operations are not allowed by your Teleport role"))
+				return
+			}
+		case *pgproto3.CopyBothResponse:
+			// CopyBoth mode means the server accepted a START_REPLICATION
+			// command and is about to start streaming WAL data.
+			if rejectCopy {
+				abort(trace.AccessDenied("COPY operations are not allowed by your Teleport role"))
+				return
+			}
+			streaming = true
+		case *pgproto3.CopyData:
+			// Server sends CopyData during COPY OUT, one message per row,
+			// and continuously during replication streaming.
+			copyRows++
+			copyBytes += int64(len(msg.Data))
+			// Result limits don't apply to replication streams which are
+			// expected to run indefinitely.
+			if limiter != nil && !streaming {
+				if err := limiter.Add(1, int64(len(msg.Data))); err != nil {
+					abort(err)
+					return
+				}
+			}
+		case *pgproto3.CopyDone:
+			if streaming {
+				flushStreamingSummary()
+				break
+			}
+			e.Audit.OnQuery(e.Context, sessionCtx, common.Query{
+				Query: fmt.Sprintf("COPY OUT: %v rows, %v bytes", copyRows, copyBytes),
+			})
+			copyRows, copyBytes = 0, 0
+		}
 		err = client.Send(message)
 		if err != nil {
 			log.WithError(err).Error("Failed to send message to client.")
@@ -418,6 +678,11 @@ func (e *Engine) getConnectConfig(ctx context.Context, sessionCtx *common.Sessio
 	config.Fallbacks = nil
 	// Set startup parameters that the client sent us.
 	config.RuntimeParams = sessionCtx.StartupParameters
+	// Connection poolers such as PgBouncer reject connections that send
+	// startup parameters they don't track so drop unsupported ones.
+	if sessionCtx.Database.IsConnectionPooler() {
+		config.RuntimeParams = e.filterPoolerStartupParameters(sessionCtx.StartupParameters)
+	}
 	// AWS RDS/Aurora and GCP Cloud SQL use IAM authentication so request an
 	// auth token and use it as a password.
 	switch sessionCtx.Database.GetType() {
@@ -431,11 +696,21 @@ func (e *Engine) getConnectConfig(ctx context.Context, sessionCtx *common.Sessio
 		if err != nil {
 			return nil, trace.Wrap(err)
 		}
+	case types.DatabaseTypeRedshiftServerless:
+		config.User, config.Password, err = e.Auth.GetRedshiftServerlessAuthToken(sessionCtx)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
 	case types.DatabaseTypeCloudSQL:
 		config.Password, err = e.Auth.GetCloudSQLAuthToken(ctx, sessionCtx)
 		if err != nil {
 			return nil, trace.Wrap(err)
 		}
+	case types.DatabaseTypeAlloyDB:
+		config.Password, err = e.Auth.GetAlloyDBAuthToken(ctx, sessionCtx)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
 	case types.DatabaseTypeAzure:
 		config.Password, err = e.Auth.GetAzureAccessToken(ctx, sessionCtx)
 		if err != nil {
@@ -454,6 +729,39 @@ func (e *Engine) getConnectConfig(ctx context.Context, sessionCtx *common.Sessio
 	return config, nil
 }
 
+// filterPoolerStartupParameters returns a copy of the provided startup
+// parameters with the ones connection poolers don't support removed.
+func (e *Engine) filterPoolerStartupParameters(params map[string]string) map[string]string {
+	filtered := make(map[string]string, len(params))
+	for name, value := range params {
+		if _, ok := poolerStartupParameters[strings.ToLower(name)]; !ok {
+			e.Log.Debugf("Dropping startup parameter %q the connection pooler does not support.", name)
+			continue
+		}
+		filtered[name] = value
+	}
+	return filtered
+}
+
+// poolerStartupParameters are the startup parameters PgBouncer tracks by
+// default. Unless explicitly configured to ignore them, it rejects
+// connections that send any other parameters.
+var poolerStartupParameters = map[string]struct{}{
+	"client_encoding":             {},
+	"datestyle":                   {},
+	"timezone":                    {},
+	"standard_conforming_strings": {},
+	"application_name":            {},
+}
+
+// defaultParameterStatuses are parameter values reported to the client on
+// behalf of a connection pooler that does not relay them itself. Drivers
+// such as libpq and JDBC misbehave when these are missing.
+var defaultParameterStatuses = map[string]string{
+	"client_encoding":             "UTF8",
+	"standard_conforming_strings": "on",
+}
+
 // formatParameters converts parameters from the Postgres wire message into
 // their string representations for including in the audit log.
 func formatParameters(parameters [][]byte, formatCodes []int16) (formatted []string) {
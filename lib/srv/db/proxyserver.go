@@ -77,6 +77,9 @@ type ProxyServerConfig struct {
 	TLSConfig *tls.Config
 	// Limiter is the connection/rate limiter.
 	Limiter *limiter.Limiter
+	// IdentityLimiter rate limits new connections per identity and target
+	// database.
+	IdentityLimiter *limiter.Limiter
 	// Emitter is used to emit audit events.
 	Emitter events.Emitter
 	// Clock to override clock in tests.
@@ -133,9 +136,36 @@ func (c *ProxyServerConfig) CheckAndSetDefaults() error {
 
 		c.Limiter = connLimiter
 	}
+	if c.IdentityLimiter == nil {
+		identityLimiter, err := limiter.NewLimiter(limiter.Config{
+			Rates: []limiter.Rate{
+				{
+					Period:  defaultIdentityRatePeriod,
+					Average: defaultIdentityRateAverage,
+					Burst:   defaultIdentityRateBurst,
+				},
+			},
+		})
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		c.IdentityLimiter = identityLimiter
+	}
 	return nil
 }
 
+const (
+	// defaultIdentityRatePeriod is the refill period of the token bucket
+	// limiting new connections per identity and target database.
+	defaultIdentityRatePeriod = time.Second
+	// defaultIdentityRateAverage is the sustained number of new connections
+	// per second a single identity can open to a single database.
+	defaultIdentityRateAverage = 10
+	// defaultIdentityRateBurst is the number of new connections a single
+	// identity can open to a single database in a burst.
+	defaultIdentityRateBurst = 30
+)
+
 // NewProxyServer creates a new instance of the database proxy server.
 func NewProxyServer(ctx context.Context, config ProxyServerConfig) (*ProxyServer, error) {
 	if err := config.CheckAndSetDefaults(); err != nil {
@@ -385,19 +415,38 @@ func (s *ProxyServer) Proxy(ctx context.Context, authContext *auth.Context, clie
 		serviceConn.Close()
 		return trace.Wrap(err)
 	}
+	// Report transfer totals periodically while the connection is being
+	// proxied so unusually large data movements show up in the audit log
+	// before the session ends.
+	reporterCtx, cancelReporter := context.WithCancel(ctx)
+	defer cancelReporter()
+	reporter, err := srv.StartTrafficReporter(srv.TrafficReporterConfig{
+		Context:      reporterCtx,
+		Emitter:      s.cfg.Emitter,
+		Entry:        s.log,
+		Clock:        s.cfg.Clock,
+		ServerID:     s.cfg.ServerID,
+		TeleportUser: authContext.Identity.GetIdentity().Username,
+		RemoteAddr:   clientConn.RemoteAddr().String(),
+	})
+	if err != nil {
+		serviceConn.Close()
+		tc.Close()
+		return trace.Wrap(err)
+	}
 	errCh := make(chan error, 2)
 	go func() {
 		defer s.log.Debug("Stop proxying from client to service.")
 		defer serviceConn.Close()
 		defer tc.Close()
-		_, err := io.Copy(serviceConn, tc)
+		_, err := io.Copy(reporter.WrapTransmitted(serviceConn), tc)
 		errCh <- err
 	}()
 	go func() {
 		defer s.log.Debug("Stop proxying from service to client.")
 		defer serviceConn.Close()
 		defer tc.Close()
-		_, err := io.Copy(tc, serviceConn)
+		_, err := io.Copy(reporter.WrapReceived(tc), serviceConn)
 		errCh <- err
 	}()
 	var errs []error
@@ -510,6 +559,18 @@ func (s *ProxyServer) authorize(ctx context.Context, params common.ConnectParams
 	if params.ClientIP != "" {
 		identity.ClientIP = params.ClientIP
 	}
+	// Rate limit new connections per identity and target database so a
+	// runaway client can't exhaust database agent resources or burn cloud
+	// auth token quotas. Connection limits per client IP are enforced
+	// separately by the protocol listeners.
+	err = s.cfg.IdentityLimiter.RegisterRequest(fmt.Sprintf("%v@%v",
+		identity.Username, identity.RouteToDatabase.ServiceName))
+	if err != nil {
+		s.log.WithError(err).Debugf("Rate limiting user %q connecting to database %q.",
+			identity.Username, identity.RouteToDatabase.ServiceName)
+		return nil, trace.LimitExceeded("too many connections from user %q to database %q, try again later",
+			identity.Username, identity.RouteToDatabase.ServiceName)
+	}
 	cluster, servers, err := s.getDatabaseServers(ctx, identity)
 	if err != nil {
 		return nil, trace.Wrap(err)
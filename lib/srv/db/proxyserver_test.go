@@ -228,3 +228,60 @@ func TestProxyRateLimiting(t *testing.T) {
 		})
 	}
 }
+
+func TestProxyIdentityRateLimiting(t *testing.T) {
+	const (
+		user           = "bob"
+		role           = "admin"
+		postgresDbName = "postgres"
+		dbUser         = user
+		connAttempts   = 20 // Should be enough to hit the rate limit.
+	)
+
+	ctx := context.Background()
+	testCtx := setupTestContext(ctx, t, withSelfHostedPostgres("postgres"))
+
+	identityLimit, err := limiter.NewLimiter(limiter.Config{
+		// Set rates low, so we can easily hit them.
+		Rates: []limiter.Rate{
+			{
+				Period:  10 * time.Second,
+				Average: 3,
+				Burst:   3,
+			},
+		}})
+	require.NoError(t, err)
+
+	// Set per-identity rate limiter on the proxy.
+	testCtx.proxyServer.cfg.IdentityLimiter = identityLimit
+
+	go testCtx.startHandlingConnections()
+
+	// Create user/role with the requested permissions.
+	testCtx.createUserAndRole(ctx, t, user, role, []string{types.Wildcard}, []string{types.Wildcard})
+
+	// Keep close functions to all connections. Call and release all active connection at the end of test.
+	connsClosers := make([]func(context.Context) error, 0)
+	t.Cleanup(func() {
+		for _, connClose := range connsClosers {
+			err := connClose(ctx)
+			require.NoError(t, err)
+		}
+	})
+
+	for i := 0; i < connAttempts; i++ {
+		pgConn, err := testCtx.postgresClient(ctx, user, "postgres", dbUser, postgresDbName)
+		if err == nil {
+			connsClosers = append(connsClosers, pgConn.Close)
+
+			continue
+		}
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "too many connections")
+
+		return
+	}
+
+	require.FailNow(t, "we should hit the limit by now")
+}
@@ -0,0 +1,407 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"net"
+	"strings"
+
+	"github.com/gravitational/teleport/lib/defaults"
+	"github.com/gravitational/teleport/lib/services"
+	"github.com/gravitational/teleport/lib/srv/db/common"
+	"github.com/gravitational/teleport/lib/srv/db/common/role"
+	"github.com/gravitational/teleport/lib/utils"
+
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+	"github.com/sirupsen/logrus"
+)
+
+// pubSubAuditSampleRate determines how often relayed pub/sub messages are
+// audited: one event is emitted per this many messages per subscription
+// stream to keep high-volume channels from flooding the audit log.
+const pubSubAuditSampleRate = 100
+
+// Engine implements the Redis database service that accepts client
+// connections coming over reverse tunnel from the proxy and proxies them
+// between the proxy and the Redis database instance.
+//
+// Implements common.Engine.
+type Engine struct {
+	// Auth handles database access authentication.
+	Auth common.Auth
+	// Audit emits database access audit events.
+	Audit common.Audit
+	// Context is the database server close context.
+	Context context.Context
+	// Clock is the clock interface.
+	Clock clockwork.Clock
+	// Log is used for logging.
+	Log logrus.FieldLogger
+	// clientConn is an incoming client connection.
+	clientConn net.Conn
+	// sessionCtx is the current session context, kept for authenticating
+	// connections to other cluster nodes when following redirects.
+	sessionCtx *common.Session
+	// tlsConfig is the TLS config for connections to the database, reused
+	// when following cluster redirects to other nodes.
+	tlsConfig *tls.Config
+	// serverConn is the connection to the current database node.
+	serverConn net.Conn
+	// serverReader reads RESP values from the current database node.
+	serverReader *bufio.Reader
+}
+
+// InitializeConnection initializes the client connection.
+func (e *Engine) InitializeConnection(clientConn net.Conn, _ *common.Session) error {
+	e.clientConn = clientConn
+	return nil
+}
+
+// SendError sends an error to the connected client in the RESP error
+// format.
+func (e *Engine) SendError(err error) {
+	if err == nil || utils.IsOKNetworkError(err) {
+		return
+	}
+	if errSend := writeError(e.clientConn, err); errSend != nil {
+		e.Log.WithError(errSend).Debugf("Failed to send error %q to Redis client.", err)
+	}
+}
+
+// HandleConnection processes the connection from Redis proxy coming over
+// reverse tunnel.
+//
+// It performs authorization checks, connects to the database over mutual
+// TLS and relays RESP commands and replies between the client and the
+// database, following cluster MOVED/ASK redirects and relaying pub/sub
+// streams along the way.
+func (e *Engine) HandleConnection(ctx context.Context, sessionCtx *common.Session) error {
+	err := e.checkAccess(ctx, sessionCtx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	e.sessionCtx = sessionCtx
+	e.tlsConfig, err = e.Auth.GetTLSConfig(ctx, sessionCtx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	e.serverConn, err = e.connect(ctx, sessionCtx.Database.GetURI())
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer func() {
+		if err := e.serverConn.Close(); err != nil && !utils.IsOKNetworkError(err) {
+			e.Log.WithError(err).Error("Failed to close connection to Redis server.")
+		}
+	}()
+	e.serverReader = bufio.NewReader(e.serverConn)
+	if err := e.authenticate(ctx, e.serverConn, e.serverReader); err != nil {
+		return trace.Wrap(err)
+	}
+	e.Audit.OnSessionStart(e.Context, sessionCtx, nil)
+	defer e.Audit.OnSessionEnd(e.Context, sessionCtx)
+	// Close both connections when the session context expires so the
+	// relay loops below unblock.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		e.clientConn.Close()
+		e.serverConn.Close()
+	}()
+	clientReader := bufio.NewReader(e.clientConn)
+	for {
+		command, err := readValue(clientReader)
+		if err != nil {
+			if utils.IsOKNetworkError(err) || ctx.Err() != nil {
+				e.Log.Debug("Client connection closed.")
+				return nil
+			}
+			return trace.Wrap(err)
+		}
+		args := parseCommand(command)
+		e.auditCommand(sessionCtx, args)
+		switch commandName(args) {
+		case "AUTH":
+			// Authentication to the database is handled by the agent so
+			// don't pass client credentials along.
+			if _, err := e.clientConn.Write([]byte("+OK\r\n")); err != nil {
+				return trace.ConvertSystemError(err)
+			}
+		case "SUBSCRIBE", "PSUBSCRIBE", "SSUBSCRIBE":
+			// Subscriptions switch the connection into the push protocol
+			// where the server sends messages asynchronously, so hand the
+			// rest of the session off to the pub/sub relay.
+			if _, err := e.serverConn.Write(command); err != nil {
+				return trace.ConvertSystemError(err)
+			}
+			return trace.Wrap(e.relayPubSub(ctx, clientReader, sessionCtx))
+		default:
+			reply, err := e.sendCommand(ctx, command)
+			if err != nil {
+				return trace.Wrap(err)
+			}
+			if _, err := e.clientConn.Write(reply); err != nil {
+				return trace.ConvertSystemError(err)
+			}
+		}
+	}
+}
+
+// sendCommand relays a single command to the database and returns its
+// reply, following a cluster MOVED or ASK redirect when the database
+// replies with one.
+func (e *Engine) sendCommand(ctx context.Context, command []byte) ([]byte, error) {
+	if _, err := e.serverConn.Write(command); err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	reply, err := readValue(e.serverReader)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	addr, ask, ok := parseRedirect(reply)
+	if !ok {
+		return reply, nil
+	}
+	e.Log.Debugf("Following cluster redirect to %v.", addr)
+	if ask {
+		return e.resendAsk(ctx, addr, command)
+	}
+	return e.resendMoved(ctx, addr, command)
+}
+
+// resendMoved re-sends the command to the node that owns the slot after a
+// MOVED redirect and makes it the current node, since MOVED indicates the
+// slot has permanently migrated.
+func (e *Engine) resendMoved(ctx context.Context, addr string, command []byte) ([]byte, error) {
+	serverConn, err := e.connect(ctx, addr)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	serverReader := bufio.NewReader(serverConn)
+	if err := e.authenticate(ctx, serverConn, serverReader); err != nil {
+		serverConn.Close()
+		return nil, trace.Wrap(err)
+	}
+	e.serverConn.Close()
+	e.serverConn = serverConn
+	e.serverReader = serverReader
+	if _, err := e.serverConn.Write(command); err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	return readValue(e.serverReader)
+}
+
+// resendAsk re-sends the command to the node indicated by an ASK redirect
+// over a temporary connection, since ASK applies to a single command
+// during slot migration.
+func (e *Engine) resendAsk(ctx context.Context, addr string, command []byte) ([]byte, error) {
+	serverConn, err := e.connect(ctx, addr)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	defer serverConn.Close()
+	serverReader := bufio.NewReader(serverConn)
+	if err := e.authenticate(ctx, serverConn, serverReader); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	// The target node only accepts the redirected command after ASKING.
+	if _, err := serverConn.Write([]byte("*1\r\n$6\r\nASKING\r\n")); err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	if _, err := readValue(serverReader); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if _, err := serverConn.Write(command); err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	return readValue(serverReader)
+}
+
+// relayPubSub relays the pub/sub push protocol between the client and the
+// database for the rest of the session, auditing a sample of the relayed
+// messages.
+func (e *Engine) relayPubSub(ctx context.Context, clientReader *bufio.Reader, sessionCtx *common.Session) error {
+	clientErrCh := make(chan error, 1)
+	serverErrCh := make(chan error, 1)
+	// Relay subsequent client commands (additional subscriptions, PING,
+	// UNSUBSCRIBE) without waiting for replies since the server responds
+	// asynchronously in the push protocol.
+	go func() {
+		for {
+			command, err := readValue(clientReader)
+			if err != nil {
+				clientErrCh <- err
+				return
+			}
+			e.auditCommand(sessionCtx, parseCommand(command))
+			if _, err := e.serverConn.Write(command); err != nil {
+				clientErrCh <- trace.ConvertSystemError(err)
+				return
+			}
+		}
+	}()
+	// Relay server pushes to the client, auditing every Nth message.
+	go func() {
+		var messages int64
+		for {
+			push, err := readValue(e.serverReader)
+			if err != nil {
+				serverErrCh <- err
+				return
+			}
+			if messages%pubSubAuditSampleRate == 0 {
+				e.auditPubSubMessage(sessionCtx, push)
+			}
+			messages++
+			if _, err := e.clientConn.Write(push); err != nil {
+				serverErrCh <- trace.ConvertSystemError(err)
+				return
+			}
+		}
+	}()
+	select {
+	case err := <-clientErrCh:
+		e.Log.WithError(err).Debug("Client done.")
+	case err := <-serverErrCh:
+		e.Log.WithError(err).Debug("Server done.")
+	case <-ctx.Done():
+		e.Log.Debug("Context canceled.")
+	}
+	return nil
+}
+
+// auditCommand emits an audit event for the client command, redacting
+// credentials.
+func (e *Engine) auditCommand(sessionCtx *common.Session, args []string) {
+	if len(args) == 0 {
+		return
+	}
+	query := common.Query{Query: commandName(args)}
+	switch query.Query {
+	case "AUTH", "HELLO":
+		// AUTH arguments and HELLO's optional AUTH section contain
+		// credentials so audit the command name only.
+	default:
+		query.Parameters = args[1:]
+	}
+	e.Audit.OnQuery(e.Context, sessionCtx, query)
+}
+
+// auditPubSubMessage emits an audit event for a sampled pub/sub message
+// pushed by the server.
+func (e *Engine) auditPubSubMessage(sessionCtx *common.Session, push []byte) {
+	// Message pushes are arrays like ["message", channel, payload].
+	fields := parseCommand(push)
+	if len(fields) < 3 || (!strings.EqualFold(fields[0], "message") && !strings.EqualFold(fields[0], "pmessage")) {
+		return
+	}
+	// Audit the channel (and pattern for pmessage) but not the payload.
+	e.Audit.OnQuery(e.Context, sessionCtx, common.Query{
+		Query:      "PUBSUB MESSAGE",
+		Parameters: fields[1 : len(fields)-1],
+	})
+}
+
+// commandName returns the normalized name of the command.
+func commandName(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	return strings.ToUpper(args[0])
+}
+
+// connect establishes a mutual TLS connection to the Redis node at the
+// provided address.
+func (e *Engine) connect(ctx context.Context, addr string) (net.Conn, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	tlsConfig := e.tlsConfig.Clone()
+	tlsConfig.ServerName = host
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: defaults.DatabaseConnectTimeout},
+		Config:    tlsConfig,
+	}
+	serverConn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	return serverConn, nil
+}
+
+// authenticate logs into the database node over the provided connection
+// when the database requires it. For ElastiCache and MemoryDB with IAM
+// authentication enabled, an IAM auth token is generated and presented
+// with the AUTH command. A fresh token is generated for every connection
+// since tokens are short-lived and cluster redirects can happen at any
+// point in the session.
+func (e *Engine) authenticate(ctx context.Context, conn net.Conn, reader *bufio.Reader) error {
+	if !e.sessionCtx.Database.IsElastiCache() && !e.sessionCtx.Database.IsMemoryDB() {
+		return nil
+	}
+	token, err := e.Auth.GetElastiCacheRedisToken(ctx, e.sessionCtx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if err := writeCommand(conn, "AUTH", e.sessionCtx.DatabaseUser, token); err != nil {
+		return trace.Wrap(err)
+	}
+	reply, err := readValue(reader)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if len(reply) > 0 && reply[0] == '-' {
+		return trace.AccessDenied("failed to authenticate to the database: %s", bytes.TrimSpace(reply[1:]))
+	}
+	return nil
+}
+
+// checkAccess does authorization check for Redis connection about to be
+// established.
+func (e *Engine) checkAccess(ctx context.Context, sessionCtx *common.Session) error {
+	ap, err := e.Auth.GetAuthPreference(ctx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	mfaParams := services.AccessMFAParams{
+		Verified:       sessionCtx.Identity.MFAVerified != "",
+		AlwaysRequired: ap.GetRequireSessionMFA(),
+	}
+	dbRoleMatchers := role.DatabaseRoleMatchers(
+		defaults.ProtocolRedis,
+		sessionCtx.DatabaseUser,
+		sessionCtx.DatabaseName,
+		sessionCtx.Identity.ClientIP,
+	)
+	err = sessionCtx.Checker.CheckAccess(
+		sessionCtx.Database,
+		mfaParams,
+		dbRoleMatchers...,
+	)
+	if err != nil {
+		e.Audit.OnSessionStart(e.Context, sessionCtx, err)
+		return trace.Wrap(err)
+	}
+	return nil
+}
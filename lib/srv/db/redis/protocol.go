@@ -0,0 +1,194 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/gravitational/trace"
+)
+
+const (
+	// maxLineSize caps the accepted size of a single RESP protocol line.
+	maxLineSize = 64 * 1024
+	// maxBulkSize caps the accepted size of a single RESP bulk string,
+	// matching the Redis protocol limit.
+	maxBulkSize = 512 * 1024 * 1024
+)
+
+// readValue reads a single RESP value from the reader and returns its raw
+// wire bytes, including all elements of aggregate types.
+//
+// Both RESP2 and RESP3 wire types are supported so sessions negotiated
+// with "HELLO 3" relay correctly.
+func readValue(r *bufio.Reader) ([]byte, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	switch line[0] {
+	// Simple types fit on a single protocol line.
+	case '+', '-', ':', '#', ',', '(', '_':
+		return line, nil
+	// Bulk and verbatim strings carry the payload size in the header.
+	case '$', '=':
+		size, err := parseSize(line)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		if size < 0 { // Null bulk string.
+			return line, nil
+		}
+		if size > maxBulkSize {
+			return nil, trace.BadParameter("RESP bulk string size %v exceeds maximum", size)
+		}
+		payload := make([]byte, size+2) // Include trailing CRLF.
+		if _, err := io.ReadFull(r, payload); err != nil {
+			return nil, trace.ConvertSystemError(err)
+		}
+		return append(line, payload...), nil
+	// Aggregate types carry the number of elements in the header.
+	case '*', '~', '>', '%':
+		size, err := parseSize(line)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		if line[0] == '%' { // Maps have a key and a value per element.
+			size *= 2
+		}
+		value := line
+		for i := int64(0); i < size; i++ {
+			element, err := readValue(r)
+			if err != nil {
+				return nil, trace.Wrap(err)
+			}
+			value = append(value, element...)
+		}
+		return value, nil
+	}
+	return nil, trace.BadParameter("unsupported RESP type %q", line[0])
+}
+
+// readLine reads a single CRLF-terminated protocol line including the
+// terminator.
+func readLine(r *bufio.Reader) ([]byte, error) {
+	line, err := r.ReadBytes('\n')
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, trace.BadParameter("invalid RESP protocol line")
+	}
+	if len(line) > maxLineSize {
+		return nil, trace.BadParameter("RESP protocol line exceeds maximum size")
+	}
+	return line, nil
+}
+
+// parseSize parses the size from an aggregate or bulk string header line.
+func parseSize(line []byte) (int64, error) {
+	size, err := strconv.ParseInt(string(line[1:len(line)-2]), 10, 64)
+	if err != nil {
+		return 0, trace.BadParameter("invalid RESP size header %q", line)
+	}
+	return size, nil
+}
+
+// parseCommand extracts the command name and arguments from a raw client
+// RESP array of bulk strings, returning nil for other value types.
+func parseCommand(raw []byte) []string {
+	if len(raw) == 0 || raw[0] != '*' {
+		return nil
+	}
+	r := bufio.NewReader(bytes.NewReader(raw))
+	header, err := readLine(r)
+	if err != nil {
+		return nil
+	}
+	size, err := parseSize(header)
+	if err != nil || size <= 0 {
+		return nil
+	}
+	args := make([]string, 0, size)
+	for i := int64(0); i < size; i++ {
+		line, err := readLine(r)
+		if err != nil || line[0] != '$' {
+			return nil
+		}
+		argSize, err := parseSize(line)
+		if err != nil || argSize < 0 {
+			return nil
+		}
+		arg := make([]byte, argSize+2)
+		if _, err := io.ReadFull(r, arg); err != nil {
+			return nil
+		}
+		args = append(args, string(arg[:argSize]))
+	}
+	return args
+}
+
+// parseRedirect parses a MOVED or ASK cluster redirect error reply and
+// returns the address of the node the command should be retried against.
+func parseRedirect(reply []byte) (addr string, ask bool, ok bool) {
+	if len(reply) == 0 || reply[0] != '-' {
+		return "", false, false
+	}
+	fields := strings.Fields(string(reply[1 : len(reply)-2]))
+	if len(fields) != 3 {
+		return "", false, false
+	}
+	switch fields[0] {
+	case "MOVED":
+		ask = false
+	case "ASK":
+		ask = true
+	default:
+		return "", false, false
+	}
+	if _, _, err := net.SplitHostPort(fields[2]); err != nil {
+		return "", false, false
+	}
+	return fields[2], ask, true
+}
+
+// writeCommand writes a command with the provided arguments to the
+// connection in the RESP array format.
+func writeCommand(conn net.Conn, args ...string) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&sb, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	_, err := conn.Write([]byte(sb.String()))
+	return trace.ConvertSystemError(err)
+}
+
+// writeError writes an error to the connection in the RESP error format.
+func writeError(conn net.Conn, err error) error {
+	// Error replies must fit on a single protocol line.
+	message := strings.ReplaceAll(err.Error(), "\r", " ")
+	message = strings.ReplaceAll(message, "\n", " ")
+	_, errWrite := conn.Write([]byte("-ERR " + message + "\r\n"))
+	return trace.ConvertSystemError(errWrite)
+}
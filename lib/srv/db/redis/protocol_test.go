@@ -0,0 +1,264 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/gravitational/trace"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{
+			name:  "simple string",
+			input: "+OK\r\n",
+		},
+		{
+			name:  "error",
+			input: "-ERR unknown command\r\n",
+		},
+		{
+			name:  "integer",
+			input: ":1000\r\n",
+		},
+		{
+			name:  "RESP3 boolean",
+			input: "#t\r\n",
+		},
+		{
+			name:  "RESP3 double",
+			input: ",3.14\r\n",
+		},
+		{
+			name:  "RESP3 big number",
+			input: "(3492890328409238509324850943850943825024385\r\n",
+		},
+		{
+			name:  "RESP3 null",
+			input: "_\r\n",
+		},
+		{
+			name:  "bulk string",
+			input: "$5\r\nhello\r\n",
+		},
+		{
+			name:  "empty bulk string",
+			input: "$0\r\n\r\n",
+		},
+		{
+			name:  "null bulk string",
+			input: "$-1\r\n",
+		},
+		{
+			name:  "verbatim string",
+			input: "=15\r\ntxt:Some string\r\n",
+		},
+		{
+			name:  "array",
+			input: "*2\r\n$3\r\nfoo\r\n:42\r\n",
+		},
+		{
+			name:  "nested array",
+			input: "*2\r\n*2\r\n+a\r\n+b\r\n$1\r\nc\r\n",
+		},
+		{
+			name:  "empty array",
+			input: "*0\r\n",
+		},
+		{
+			name:  "map",
+			input: "%1\r\n+key\r\n+value\r\n",
+		},
+		{
+			name:  "set",
+			input: "~2\r\n:1\r\n:2\r\n",
+		},
+		{
+			name:  "push",
+			input: ">3\r\n$7\r\nmessage\r\n$2\r\nch\r\n$5\r\nhello\r\n",
+		},
+		{
+			name:    "unsupported type",
+			input:   "!5\r\nerror\r\n",
+			wantErr: true,
+		},
+		{
+			name:    "missing CR terminator",
+			input:   "+OK\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid size header",
+			input:   "$abc\r\n",
+			wantErr: true,
+		},
+		{
+			name:    "truncated bulk string",
+			input:   "$10\r\nabc",
+			wantErr: true,
+		},
+		{
+			name:    "truncated array",
+			input:   "*2\r\n+a\r\n",
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			value, err := readValue(bufio.NewReader(strings.NewReader(test.input)))
+			if test.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			// The raw wire bytes are relayed verbatim so the value must
+			// round-trip exactly.
+			require.Equal(t, test.input, string(value))
+		})
+	}
+}
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{
+			name:  "command with arguments",
+			input: "*3\r\n$3\r\nSET\r\n$3\r\nkey\r\n$5\r\nvalue\r\n",
+			want:  []string{"SET", "key", "value"},
+		},
+		{
+			name:  "command without arguments",
+			input: "*1\r\n$4\r\nPING\r\n",
+			want:  []string{"PING"},
+		},
+		{
+			name:  "not an array",
+			input: "+OK\r\n",
+			want:  nil,
+		},
+		{
+			name:  "empty array",
+			input: "*0\r\n",
+			want:  nil,
+		},
+		{
+			name:  "array of non-bulk strings",
+			input: "*1\r\n:1\r\n",
+			want:  nil,
+		},
+		{
+			name:  "truncated command",
+			input: "*2\r\n$3\r\nGET\r\n",
+			want:  nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.want, parseCommand([]byte(test.input)))
+		})
+	}
+}
+
+func TestParseRedirect(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantAddr string
+		wantAsk  bool
+		wantOK   bool
+	}{
+		{
+			name:     "MOVED redirect",
+			input:    "-MOVED 3999 127.0.0.1:6381\r\n",
+			wantAddr: "127.0.0.1:6381",
+			wantAsk:  false,
+			wantOK:   true,
+		},
+		{
+			name:     "ASK redirect",
+			input:    "-ASK 3999 10.0.0.1:6379\r\n",
+			wantAddr: "10.0.0.1:6379",
+			wantAsk:  true,
+			wantOK:   true,
+		},
+		{
+			name:   "regular error",
+			input:  "-ERR unknown command\r\n",
+			wantOK: false,
+		},
+		{
+			name:   "not an error reply",
+			input:  "+OK\r\n",
+			wantOK: false,
+		},
+		{
+			name:   "redirect with missing port",
+			input:  "-MOVED 3999 127.0.0.1\r\n",
+			wantOK: false,
+		},
+		{
+			name:   "redirect with missing address",
+			input:  "-MOVED 3999\r\n",
+			wantOK: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			addr, ask, ok := parseRedirect([]byte(test.input))
+			require.Equal(t, test.wantOK, ok)
+			require.Equal(t, test.wantAddr, addr)
+			require.Equal(t, test.wantAsk, ask)
+		})
+	}
+}
+
+func TestWriteCommand(t *testing.T) {
+	conn := &bufferConn{}
+	require.NoError(t, writeCommand(conn, "AUTH", "secret"))
+	require.Equal(t, "*2\r\n$4\r\nAUTH\r\n$6\r\nsecret\r\n", conn.buf.String())
+}
+
+func TestWriteError(t *testing.T) {
+	conn := &bufferConn{}
+	require.NoError(t, writeError(conn, trace.AccessDenied("access to db denied\r\nsecond line")))
+	// Error replies must fit on a single protocol line.
+	require.Equal(t, "-ERR access to db denied  second line\r\n", conn.buf.String())
+}
+
+// bufferConn is a net.Conn that records everything written to it.
+type bufferConn struct {
+	net.Conn
+	buf bytes.Buffer
+}
+
+func (c *bufferConn) Write(b []byte) (int, error) {
+	return c.buf.Write(b)
+}
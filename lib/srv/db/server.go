@@ -33,11 +33,19 @@ import (
 	"github.com/gravitational/teleport/lib/limiter"
 	"github.com/gravitational/teleport/lib/services"
 	"github.com/gravitational/teleport/lib/srv"
+	"github.com/gravitational/teleport/lib/srv/db/cassandra"
+	"github.com/gravitational/teleport/lib/srv/db/clickhouse"
 	"github.com/gravitational/teleport/lib/srv/db/cloud"
 	"github.com/gravitational/teleport/lib/srv/db/common"
+	"github.com/gravitational/teleport/lib/srv/db/dynamodb"
+	"github.com/gravitational/teleport/lib/srv/db/elasticsearch"
 	"github.com/gravitational/teleport/lib/srv/db/mongodb"
 	"github.com/gravitational/teleport/lib/srv/db/mysql"
+	"github.com/gravitational/teleport/lib/srv/db/oracle"
 	"github.com/gravitational/teleport/lib/srv/db/postgres"
+	"github.com/gravitational/teleport/lib/srv/db/redis"
+	"github.com/gravitational/teleport/lib/srv/db/snowflake"
+	"github.com/gravitational/teleport/lib/srv/db/sqlserver"
 	"github.com/gravitational/teleport/lib/utils"
 
 	"github.com/google/uuid"
@@ -60,6 +68,10 @@ type Config struct {
 	StreamEmitter events.StreamEmitter
 	// NewAudit allows to override audit logger in tests.
 	NewAudit NewAuditFn
+	// RedactedConnectionAttributes are names of client connection
+	// attributes whose values are redacted from audit events. When
+	// empty, a default list of common secret attribute names is used.
+	RedactedConnectionAttributes []string
 	// TLSConfig is the *tls.Config for this server.
 	TLSConfig *tls.Config
 	// Limiter limits the number of connections per client IP.
@@ -78,6 +90,10 @@ type Config struct {
 	ResourceMatchers []services.ResourceMatcher
 	// AWSMatchers is a list of AWS databases matchers.
 	AWSMatchers []services.AWSMatcher
+	// AzureMatchers is a list of Azure databases matchers.
+	AzureMatchers []services.AzureMatcher
+	// GCPMatchers is a list of GCP databases matchers.
+	GCPMatchers []services.GCPMatcher
 	// Databases is a list of proxied databases from static configuration.
 	Databases types.Databases
 	// OnHeartbeat is called after every heartbeat. Used to update process state.
@@ -205,6 +221,9 @@ type Server struct {
 	// monitoredDatabases contains all cluster databases the proxied databases
 	// are reconciled against.
 	monitoredDatabases monitoredDatabases
+	// healthStates contains results of the latest connectivity checks to
+	// the proxied databases.
+	healthStates map[string]string
 	// reconcileCh triggers reconciliation of proxied databases.
 	reconcileCh chan struct{}
 	// mu protects access to server infos and databases.
@@ -262,6 +281,7 @@ func New(ctx context.Context, config Config) (*Server, error) {
 		closeFunc:        cancel,
 		dynamicLabels:    make(map[string]*labels.Dynamic),
 		heartbeats:       make(map[string]*srv.Heartbeat),
+		healthStates:     make(map[string]string),
 		proxiedDatabases: config.Databases.ToMap(),
 		monitoredDatabases: monitoredDatabases{
 			static: config.Databases,
@@ -442,6 +462,7 @@ func (s *Server) stopProxyingDatabase(ctx context.Context, database types.Databa
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	delete(s.proxiedDatabases, database.GetName())
+	delete(s.healthStates, database.GetName())
 	return nil
 }
 
@@ -517,6 +538,16 @@ func (s *Server) getServerInfo(database types.Database) (types.Resource, error)
 	if labels != nil {
 		copy.SetDynamicLabels(labels.Get())
 	}
+	// Report the latest connectivity check result via a label so it is
+	// visible in "tsh db ls" and the web UI.
+	if state := s.getHealthState(copy.GetName()); state != "" {
+		staticLabels := copy.GetStaticLabels()
+		if staticLabels == nil {
+			staticLabels = make(map[string]string)
+		}
+		staticLabels[types.DatabaseHealthLabel] = state
+		copy.SetStaticLabels(staticLabels)
+	}
 	expires := s.cfg.Clock.Now().UTC().Add(apidefaults.ServerAnnounceTTL)
 	return types.NewDatabaseServerV3(types.Metadata{
 		Name:    copy.GetName(),
@@ -569,6 +600,12 @@ func (s *Server) Start(ctx context.Context) (err error) {
 		return trace.Wrap(err)
 	}
 
+	// Start checker that will be periodically verifying connectivity to
+	// the proxied databases.
+	if err := s.startHealthCheck(ctx); err != nil {
+		return trace.Wrap(err)
+	}
+
 	return nil
 }
 
@@ -680,11 +717,16 @@ func (s *Server) handleConnection(ctx context.Context, clientConn net.Conn) erro
 		return trace.Wrap(err)
 	}
 
+	// Cross-cutting behaviors are layered around the engine as middleware
+	// so individual protocol engines only deal with protocol handling.
+	engine = common.WrapEngine(engine,
+		common.WithRecovery(),
+		common.WithConnectionLimiter(s.cfg.Limiter),
+		common.WithMFASessionTTL(),
+		common.WithSessionMetrics(),
+	)
+
 	defer func() {
-		if r := recover(); r != nil {
-			s.log.Warnf("Recovered while handling DB connection from %v: %v.", clientConn.RemoteAddr(), r)
-			err = trace.BadParameter("failed to handle client connection")
-		}
 		if err != nil {
 			engine.SendError(err)
 		}
@@ -710,21 +752,6 @@ func (s *Server) handleConnection(ctx context.Context, clientConn net.Conn) erro
 		return trace.Wrap(err)
 	}
 
-	// TODO(jakule): ClientIP should be required starting from 10.0.
-	clientIP := sessionCtx.Identity.ClientIP
-	if clientIP != "" {
-		s.log.Debugf("Real client IP %s", clientIP)
-
-		var release func()
-		release, err = s.cfg.Limiter.RegisterRequestAndConnection(clientIP)
-		if err != nil {
-			return trace.Wrap(err)
-		}
-		defer release()
-	} else {
-		s.log.Debug("ClientIP is not set (Proxy Service has to be updated). Rate limiting is disabled.")
-	}
-
 	err = engine.HandleConnection(ctx, sessionCtx)
 	if err != nil {
 		return trace.Wrap(err)
@@ -735,7 +762,8 @@ func (s *Server) handleConnection(ctx context.Context, clientConn net.Conn) erro
 // dispatch creates and initializes an appropriate database engine for the session.
 func (s *Server) dispatch(sessionCtx *common.Session, streamWriter events.StreamWriter, clientConn net.Conn) (common.Engine, error) {
 	audit, err := s.cfg.NewAudit(common.AuditConfig{
-		Emitter: streamWriter,
+		Emitter:            streamWriter,
+		RedactedAttributes: s.cfg.RedactedConnectionAttributes,
 	})
 	if err != nil {
 		return nil, trace.Wrap(err)
@@ -781,6 +809,71 @@ func (s *Server) createEngine(sessionCtx *common.Session, audit common.Audit) (c
 			Clock:   s.cfg.Clock,
 			Log:     sessionCtx.Log,
 		}, nil
+	case defaults.ProtocolSQLServer:
+		return &sqlserver.Engine{
+			Auth:       s.cfg.Auth,
+			Audit:      audit,
+			AuthClient: s.cfg.AuthClient,
+			Context:    s.closeContext,
+			Clock:      s.cfg.Clock,
+			Log:        sessionCtx.Log,
+		}, nil
+	case defaults.ProtocolOracle:
+		return &oracle.Engine{
+			Auth:    s.cfg.Auth,
+			Audit:   audit,
+			Context: s.closeContext,
+			Clock:   s.cfg.Clock,
+			Log:     sessionCtx.Log,
+		}, nil
+	case defaults.ProtocolCassandra:
+		return &cassandra.Engine{
+			Auth:    s.cfg.Auth,
+			Audit:   audit,
+			Context: s.closeContext,
+			Clock:   s.cfg.Clock,
+			Log:     sessionCtx.Log,
+		}, nil
+	case defaults.ProtocolElasticsearch:
+		return &elasticsearch.Engine{
+			Auth:    s.cfg.Auth,
+			Audit:   audit,
+			Context: s.closeContext,
+			Clock:   s.cfg.Clock,
+			Log:     sessionCtx.Log,
+		}, nil
+	case defaults.ProtocolClickHouse, defaults.ProtocolClickHouseHTTP:
+		return &clickhouse.Engine{
+			Auth:    s.cfg.Auth,
+			Audit:   audit,
+			Context: s.closeContext,
+			Clock:   s.cfg.Clock,
+			Log:     sessionCtx.Log,
+		}, nil
+	case defaults.ProtocolDynamoDB:
+		return &dynamodb.Engine{
+			Auth:    s.cfg.Auth,
+			Audit:   audit,
+			Context: s.closeContext,
+			Clock:   s.cfg.Clock,
+			Log:     sessionCtx.Log,
+		}, nil
+	case defaults.ProtocolRedis:
+		return &redis.Engine{
+			Auth:    s.cfg.Auth,
+			Audit:   audit,
+			Context: s.closeContext,
+			Clock:   s.cfg.Clock,
+			Log:     sessionCtx.Log,
+		}, nil
+	case defaults.ProtocolSnowflake:
+		return &snowflake.Engine{
+			Auth:    s.cfg.Auth,
+			Audit:   audit,
+			Context: s.closeContext,
+			Clock:   s.cfg.Clock,
+			Log:     sessionCtx.Log,
+		}, nil
 	}
 
 	return nil, trace.BadParameter("unsupported database protocol %q",
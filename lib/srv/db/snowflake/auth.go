@@ -0,0 +1,135 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snowflake
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/teleport/lib/srv/db/common"
+
+	"gopkg.in/square/go-jose.v2"
+
+	"github.com/gravitational/trace"
+)
+
+// jwtTTL is the lifetime of generated login JWTs, Snowflake rejects
+// tokens valid for longer than an hour.
+const jwtTTL = time.Minute
+
+// generateJWT generates a key pair authentication JWT for the database
+// user.
+//
+// The RSA private key is configured on the database via the
+// teleport.dev/snowflake-key-file label and its public part must be
+// assigned to the Snowflake users with ALTER USER ... SET
+// RSA_PUBLIC_KEY.
+//
+// https://docs.snowflake.com/en/developer-guide/sql-api/authenticating.html#using-key-pair-authentication
+func (e *Engine) generateJWT(sessionCtx *common.Session) (string, error) {
+	keyFile := sessionCtx.Database.GetStaticLabels()[types.SnowflakeKeyFileLabel]
+	if keyFile == "" {
+		return "", trace.BadParameter("database %q does not have a Snowflake private key configured (%v label)",
+			sessionCtx.Database.GetName(), types.SnowflakeKeyFileLabel)
+	}
+	key, err := loadPrivateKey(keyFile)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	// The issuer includes the SHA-256 fingerprint of the public key the
+	// token is signed with.
+	publicKeyDER, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	fingerprint := sha256.Sum256(publicKeyDER)
+	// Account and user names must be upper-case in the token subject.
+	qualifiedUser := fmt.Sprintf("%v.%v",
+		strings.ToUpper(accountName(sessionCtx.Database.GetURI())),
+		strings.ToUpper(sessionCtx.DatabaseUser))
+	now := e.Clock.Now()
+	claims, err := json.Marshal(map[string]interface{}{
+		"iss": fmt.Sprintf("%v.SHA256:%v", qualifiedUser, base64.StdEncoding.EncodeToString(fingerprint[:])),
+		"sub": qualifiedUser,
+		"iat": now.Unix(),
+		"exp": now.Add(jwtTTL).Unix(),
+	})
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	signer, err := jose.NewSigner(jose.SigningKey{
+		Algorithm: jose.RS256,
+		Key:       key,
+	}, (&jose.SignerOptions{}).WithType("JWT"))
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	signed, err := signer.Sign(claims)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	token, err := signed.CompactSerialize()
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	return token, nil
+}
+
+// loadPrivateKey loads a PEM-encoded RSA private key from the file.
+func loadPrivateKey(path string) (*rsa.PrivateKey, error) {
+	keyPEM, err := os.ReadFile(path)
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, trace.BadParameter("failed to decode PEM private key in %v", path)
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, trace.BadParameter("expected RSA private key in %v, got %T", path, key)
+	}
+	return rsaKey, nil
+}
+
+// accountName returns the Snowflake account identifier derived from the
+// database URI, e.g. "myorg-myaccount.snowflakecomputing.com" yields
+// "myorg-myaccount".
+func accountName(uri string) string {
+	host, _, found := strings.Cut(uri, ":")
+	if !found {
+		host = uri
+	}
+	account, _, _ := strings.Cut(host, ".snowflakecomputing.com")
+	return account
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snowflake
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestAccountName verifies account identifier extraction from database
+// URIs.
+func TestAccountName(t *testing.T) {
+	tests := []struct {
+		uri     string
+		account string
+	}{
+		{uri: "myorg-myaccount.snowflakecomputing.com", account: "myorg-myaccount"},
+		{uri: "myorg-myaccount.snowflakecomputing.com:443", account: "myorg-myaccount"},
+		{uri: "ab12345.eu-west-1.snowflakecomputing.com", account: "ab12345.eu-west-1"},
+	}
+	for _, test := range tests {
+		t.Run(test.uri, func(t *testing.T) {
+			require.Equal(t, test.account, accountName(test.uri))
+		})
+	}
+}
@@ -0,0 +1,317 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package snowflake implements the Snowflake database access engine.
+//
+// The engine proxies the Snowflake REST API used by snowsql and the
+// Snowflake drivers. Login requests are rewritten to authenticate with a
+// key pair JWT for the Teleport-approved database user so clients never
+// hold Snowflake credentials, and SQL statements submitted through the
+// API are audited.
+package snowflake
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gravitational/teleport/lib/defaults"
+	"github.com/gravitational/teleport/lib/services"
+	"github.com/gravitational/teleport/lib/srv/db/common"
+	"github.com/gravitational/teleport/lib/srv/db/common/role"
+	"github.com/gravitational/teleport/lib/utils"
+
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// loginRequestPath is the REST API login endpoint.
+	loginRequestPath = "/session/v1/login-request"
+	// queryRequestPath is the REST API query submission endpoint.
+	queryRequestPath = "/queries/v1/query-request"
+	// maxRequestBodySize caps the accepted client request body size.
+	maxRequestBodySize = 256 * 1024 * 1024
+)
+
+// Engine implements the Snowflake database service that accepts client
+// connections coming over reverse tunnel from the proxy and proxies them
+// between the proxy and the Snowflake API.
+//
+// Implements common.Engine.
+type Engine struct {
+	// Auth handles database access authentication.
+	Auth common.Auth
+	// Audit emits database access audit events.
+	Audit common.Audit
+	// Context is the database server close context.
+	Context context.Context
+	// Clock is the clock interface.
+	Clock clockwork.Clock
+	// Log is used for logging.
+	Log logrus.FieldLogger
+	// clientConn is an incoming client connection.
+	clientConn net.Conn
+	// queryFilter blocks queries based on the user's role-based allow and
+	// deny patterns. It is nil when none of the user's roles set any.
+	queryFilter *common.QueryFilter
+}
+
+// InitializeConnection initializes the client connection.
+func (e *Engine) InitializeConnection(clientConn net.Conn, _ *common.Session) error {
+	e.clientConn = clientConn
+	return nil
+}
+
+// SendError sends an error to the connected client in the JSON format
+// Snowflake clients expect.
+func (e *Engine) SendError(err error) {
+	if err == nil || utils.IsOKNetworkError(err) {
+		return
+	}
+	body, errMarshal := json.Marshal(map[string]interface{}{
+		"success": false,
+		"message": err.Error(),
+	})
+	if errMarshal != nil {
+		e.Log.WithError(errMarshal).Debug("Failed to marshal error response.")
+		return
+	}
+	statusCode := http.StatusInternalServerError
+	if trace.IsAccessDenied(err) {
+		statusCode = http.StatusUnauthorized
+	}
+	response := &http.Response{
+		StatusCode: statusCode,
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header: http.Header{
+			"Content-Type": []string{"application/json"},
+		},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+	}
+	if errSend := response.Write(e.clientConn); errSend != nil {
+		e.Log.WithError(errSend).Debugf("Failed to send error %q to Snowflake client.", err)
+	}
+}
+
+// HandleConnection processes the connection from Snowflake proxy coming
+// over reverse tunnel.
+func (e *Engine) HandleConnection(ctx context.Context, sessionCtx *common.Session) error {
+	err := e.checkAccess(ctx, sessionCtx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	// Setup query filtering if any of the user's roles set query allow
+	// or deny patterns.
+	e.queryFilter, err = common.NewQueryFilter(sessionCtx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	tlsConfig, err := e.Auth.GetTLSConfig(ctx, sessionCtx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	transport := &http.Transport{
+		TLSClientConfig: tlsConfig,
+		DialContext: (&net.Dialer{
+			Timeout: defaults.DatabaseConnectTimeout,
+		}).DialContext,
+	}
+	defer transport.CloseIdleConnections()
+	e.Audit.OnSessionStart(e.Context, sessionCtx, nil)
+	defer e.Audit.OnSessionEnd(e.Context, sessionCtx)
+	clientConnReader := bufio.NewReader(e.clientConn)
+	for {
+		request, err := http.ReadRequest(clientConnReader)
+		if err != nil {
+			if err == io.EOF || utils.IsOKNetworkError(err) {
+				return nil
+			}
+			return trace.Wrap(err)
+		}
+		err = e.process(ctx, sessionCtx, transport, request)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+}
+
+// process inspects a single client request, rewriting login requests and
+// auditing queries, and forwards it to the Snowflake API relaying the
+// response back to the client.
+func (e *Engine) process(ctx context.Context, sessionCtx *common.Session, transport *http.Transport, request *http.Request) error {
+	body, err := readRequestBody(request)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	switch {
+	case strings.HasPrefix(request.URL.Path, loginRequestPath):
+		body, err = e.rewriteLoginRequest(sessionCtx, body)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	case strings.HasPrefix(request.URL.Path, queryRequestPath):
+		if err := e.auditQueryRequest(sessionCtx, body); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	reqCopy := request.Clone(ctx)
+	reqCopy.RequestURI = ""
+	reqCopy.URL.Scheme = "https"
+	reqCopy.URL.Host = sessionCtx.Database.GetURI()
+	if err := setRequestBody(reqCopy, body); err != nil {
+		return trace.Wrap(err)
+	}
+	response, err := transport.RoundTrip(reqCopy)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer response.Body.Close()
+	return trace.Wrap(response.Write(e.clientConn))
+}
+
+// rewriteLoginRequest replaces the authentication data in the login
+// request with a key pair JWT for the Teleport-approved database user.
+func (e *Engine) rewriteLoginRequest(sessionCtx *common.Session, body []byte) ([]byte, error) {
+	var loginRequest struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &loginRequest); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if loginRequest.Data == nil {
+		loginRequest.Data = make(map[string]interface{})
+	}
+	token, err := e.generateJWT(sessionCtx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	loginRequest.Data["LOGIN_NAME"] = sessionCtx.DatabaseUser
+	loginRequest.Data["AUTHENTICATOR"] = "SNOWFLAKE_JWT"
+	loginRequest.Data["TOKEN"] = token
+	delete(loginRequest.Data, "PASSWORD")
+	rewritten, err := json.Marshal(loginRequest)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return rewritten, nil
+}
+
+// auditQueryRequest emits an audit event with the SQL statement from a
+// query request and returns an error if the statement is blocked by the
+// user's role-based query filters.
+func (e *Engine) auditQueryRequest(sessionCtx *common.Session, body []byte) error {
+	var queryRequest struct {
+		SQLText  string            `json:"sqlText"`
+		Bindings map[string]struct {
+			Value string `json:"value"`
+		} `json:"bindings"`
+	}
+	if err := json.Unmarshal(body, &queryRequest); err != nil {
+		e.Log.WithError(err).Warn("Failed to parse query request.")
+		return nil
+	}
+	query := common.Query{Query: queryRequest.SQLText}
+	for _, binding := range queryRequest.Bindings {
+		query.Parameters = append(query.Parameters, binding.Value)
+	}
+	if e.queryFilter != nil {
+		if err := e.queryFilter.CheckQuery(query.Query); err != nil {
+			query.Error = err
+			e.Audit.OnQuery(e.Context, sessionCtx, query)
+			return trace.Wrap(err)
+		}
+	}
+	e.Audit.OnQuery(e.Context, sessionCtx, query)
+	return nil
+}
+
+// checkAccess does authorization check for Snowflake connection about to
+// be established.
+func (e *Engine) checkAccess(ctx context.Context, sessionCtx *common.Session) error {
+	ap, err := e.Auth.GetAuthPreference(ctx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	mfaParams := services.AccessMFAParams{
+		Verified:       sessionCtx.Identity.MFAVerified != "",
+		AlwaysRequired: ap.GetRequireSessionMFA(),
+	}
+	dbRoleMatchers := role.DatabaseRoleMatchers(
+		defaults.ProtocolSnowflake,
+		sessionCtx.DatabaseUser,
+		sessionCtx.DatabaseName,
+		sessionCtx.Identity.ClientIP,
+	)
+	err = sessionCtx.Checker.CheckAccess(
+		sessionCtx.Database,
+		mfaParams,
+		dbRoleMatchers...,
+	)
+	if err != nil {
+		e.Audit.OnSessionStart(e.Context, sessionCtx, err)
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// readRequestBody reads the request body, transparently decompressing
+// gzip-encoded bodies the Snowflake clients send.
+func readRequestBody(request *http.Request) ([]byte, error) {
+	defer request.Body.Close()
+	reader := io.Reader(request.Body)
+	if request.Header.Get("Content-Encoding") == "gzip" {
+		gzipReader, err := gzip.NewReader(request.Body)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+	body, err := io.ReadAll(io.LimitReader(reader, maxRequestBodySize))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return body, nil
+}
+
+// setRequestBody replaces the request body, re-compressing it when the
+// original body was gzip-encoded.
+func setRequestBody(request *http.Request, body []byte) error {
+	if request.Header.Get("Content-Encoding") == "gzip" {
+		var buf bytes.Buffer
+		gzipWriter := gzip.NewWriter(&buf)
+		if _, err := gzipWriter.Write(body); err != nil {
+			return trace.Wrap(err)
+		}
+		if err := gzipWriter.Close(); err != nil {
+			return trace.Wrap(err)
+		}
+		body = buf.Bytes()
+	}
+	request.Body = io.NopCloser(bytes.NewReader(body))
+	request.ContentLength = int64(len(body))
+	return nil
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlserver
+
+import (
+	"context"
+
+	"github.com/gravitational/teleport/lib/srv/db/common"
+	"github.com/gravitational/teleport/lib/srv/db/sqlserver/protocol"
+
+	"github.com/gravitational/trace"
+)
+
+// getAuth returns authentication material for the login with the database
+// server based on the database configuration: an Azure AD access token
+// for Azure SQL databases, a Kerberos SSPI token obtained with the
+// configured keytab otherwise.
+func (e *Engine) getAuth(ctx context.Context, sessionCtx *common.Session) (*protocol.Login7Auth, error) {
+	if sessionCtx.Database.IsAzure() {
+		token, err := e.Auth.GetAzureSQLAccessToken(ctx, sessionCtx)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return &protocol.Login7Auth{FedAuthToken: token}, nil
+	}
+	blob, err := e.getKerberosSSPIBlob(ctx, sessionCtx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &protocol.Login7Auth{SSPIBlob: blob}, nil
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlserver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/gravitational/teleport/lib/srv/db/common"
+
+	"github.com/gravitational/trace"
+)
+
+const (
+	// sqlBrowserPort is the UDP port the SQL Browser service listens on.
+	sqlBrowserPort = 1434
+	// sqlBrowserTimeout is the timeout for the SQL Browser exchange.
+	sqlBrowserTimeout = 5 * time.Second
+	// clntUcastInst is the CLNT_UCAST_INST request type asking the browser
+	// for a single instance.
+	clntUcastInst = 0x04
+	// svrResp is the SVR_RESP response type.
+	svrResp = 0x05
+)
+
+// resolveAddress returns the TCP address of the SQL Server instance the
+// database URI points to.
+//
+// Databases can be registered with a named instance URI such as
+// "sqlserver.example.com\SQLEXPRESS" in which case named instances
+// listen on dynamic ports and the actual port is resolved at connect
+// time by querying the SQL Browser service on UDP port 1434.
+//
+// https://docs.microsoft.com/en-us/openspecs/windows_protocols/mc-sqlr/6b537d8a-d4eb-4b30-b5b6-9d54feb26bed
+func (e *Engine) resolveAddress(ctx context.Context, sessionCtx *common.Session) (string, error) {
+	uri := sessionCtx.Database.GetURI()
+	host, instance, found := strings.Cut(uri, `\`)
+	if !found {
+		return uri, nil
+	}
+	// The host part may carry an explicit SQL Browser port override.
+	browserPort := fmt.Sprintf("%v", sqlBrowserPort)
+	if h, p, err := net.SplitHostPort(host); err == nil {
+		host, browserPort = h, p
+	}
+	port, err := e.queryBrowser(ctx, net.JoinHostPort(host, browserPort), instance)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	return fmt.Sprintf("%v:%v", host, port), nil
+}
+
+// queryBrowser asks the SQL Browser service at the provided address for
+// the TCP port of the named instance.
+func (e *Engine) queryBrowser(ctx context.Context, browserAddr, instance string) (string, error) {
+	dialer := &net.Dialer{Timeout: sqlBrowserTimeout}
+	conn, err := dialer.DialContext(ctx, "udp", browserAddr)
+	if err != nil {
+		return "", trace.ConvertSystemError(err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(sqlBrowserTimeout)); err != nil {
+		return "", trace.ConvertSystemError(err)
+	}
+	request := append([]byte{clntUcastInst}, instance...)
+	request = append(request, 0x00)
+	if _, err := conn.Write(request); err != nil {
+		return "", trace.ConvertSystemError(err)
+	}
+	// Response is the SVR_RESP type byte, a 2-byte length and a
+	// semicolon-separated list of instance properties.
+	response := make([]byte, 1024)
+	n, err := conn.Read(response)
+	if err != nil {
+		return "", trace.ConvertSystemError(err)
+	}
+	if n < 3 || response[0] != svrResp {
+		return "", trace.BadParameter("unexpected SQL Browser response for instance %q", instance)
+	}
+	port, err := parseBrowserResponse(string(response[3:n]), instance)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	return port, nil
+}
+
+// parseBrowserResponse extracts the TCP port of the instance from the SQL
+// Browser response data, e.g.:
+//
+//   ServerName;HOST;InstanceName;SQLEXPRESS;IsClustered;No;Version;15.0.2000.5;tcp;54321;;
+func parseBrowserResponse(response, instance string) (string, error) {
+	fields := strings.Split(response, ";")
+	for i := 0; i+1 < len(fields); i += 2 {
+		if strings.EqualFold(fields[i], "tcp") {
+			return fields[i+1], nil
+		}
+	}
+	return "", trace.NotFound("SQL Browser did not report a TCP port for instance %q, make sure TCP/IP is enabled for it", instance)
+}
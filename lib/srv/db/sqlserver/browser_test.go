@@ -0,0 +1,61 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlserver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseBrowserResponse verifies TCP port extraction from SQL Browser
+// responses.
+func TestParseBrowserResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		port     string
+		wantErr  bool
+	}{
+		{
+			name:     "tcp port reported",
+			response: "ServerName;HOST;InstanceName;SQLEXPRESS;IsClustered;No;Version;15.0.2000.5;tcp;54321;;",
+			port:     "54321",
+		},
+		{
+			name:     "named pipes only",
+			response: "ServerName;HOST;InstanceName;SQLEXPRESS;IsClustered;No;Version;15.0.2000.5;np;\\\\HOST\\pipe\\sql\\query;;",
+			wantErr:  true,
+		},
+		{
+			name:     "empty response",
+			response: "",
+			wantErr:  true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			port, err := parseBrowserResponse(test.response, "SQLEXPRESS")
+			if test.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, test.port, port)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlserver
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+
+	"github.com/gravitational/teleport/lib/defaults"
+	"github.com/gravitational/teleport/lib/srv/db/common"
+	"github.com/gravitational/teleport/lib/srv/db/sqlserver/protocol"
+	"github.com/gravitational/teleport/lib/utils"
+
+	"github.com/gravitational/trace"
+)
+
+// connect establishes a connection to the SQL Server instance, performs
+// the login sequence on the client's behalf and returns the connection
+// ready for message relay along with the server's login response to be
+// sent back to the client.
+func (e *Engine) connect(ctx context.Context, sessionCtx *common.Session, clientLogin *protocol.Login7) (net.Conn, []byte, error) {
+	auth, err := e.getAuth(ctx, sessionCtx)
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	// Resolve named instances through the SQL Browser service.
+	address, err := e.resolveAddress(ctx, sessionCtx)
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	dialer := &net.Dialer{Timeout: defaults.DatabaseConnectTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, nil, trace.ConvertSystemError(err)
+	}
+	serverConn, err := e.handshake(ctx, sessionCtx, conn, auth)
+	if err != nil {
+		conn.Close()
+		return nil, nil, trace.Wrap(err)
+	}
+	// Send the login message and verify the server accepted it before
+	// letting the client exchange messages.
+	addr, err := utils.ParseAddr(address)
+	if err != nil {
+		serverConn.Close()
+		return nil, nil, trace.Wrap(err)
+	}
+	err = protocol.WriteLogin7(serverConn, protocol.Login7{
+		Username: sessionCtx.DatabaseUser,
+		Database: sessionCtx.DatabaseName,
+		// Forward the column encryption (Always Encrypted) feature when
+		// the client requested it.
+		ColumnEncryption: clientLogin.ColumnEncryption,
+	}, addr.Host(), *auth)
+	if err != nil {
+		serverConn.Close()
+		return nil, nil, trace.Wrap(err)
+	}
+	packetType, loginResponse, err := protocol.ReadMessage(serverConn)
+	if err != nil {
+		serverConn.Close()
+		return nil, nil, trace.Wrap(err)
+	}
+	if packetType != protocol.PacketTypeResponse {
+		serverConn.Close()
+		return nil, nil, trace.BadParameter("expected login response packet, got %#x", packetType)
+	}
+	if err := protocol.CheckLoginResponse(loginResponse); err != nil {
+		serverConn.Close()
+		return nil, nil, trace.Wrap(err)
+	}
+	return serverConn, loginResponse, nil
+}
+
+// handshake performs the pre-login negotiation with the SQL Server
+// instance and upgrades the connection to TLS when the server supports
+// or requires encryption - always the case for Azure SQL.
+func (e *Engine) handshake(ctx context.Context, sessionCtx *common.Session, conn net.Conn, auth *protocol.Login7Auth) (net.Conn, error) {
+	options := protocol.PreLoginOptions{
+		protocol.PreLoginVersion:    make([]byte, 6),
+		protocol.PreLoginEncryption: {protocol.EncryptionOn},
+	}
+	if auth.FedAuthToken != "" {
+		options[protocol.PreLoginFedAuthRequired] = []byte{0x01}
+	}
+	err := protocol.WritePreLogin(conn, protocol.PacketTypePreLogin, options)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	response, err := protocol.ReadPreLogin(conn)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	// The server echoes the federated authentication option back when it
+	// supports it, in which case it must be echoed in the login message.
+	if option, ok := response[protocol.PreLoginFedAuthRequired]; ok && len(option) == 1 && option[0] == 0x01 {
+		auth.FedAuthEcho = true
+	}
+	switch response.Encryption() {
+	case protocol.EncryptionOn, protocol.EncryptionRequired:
+		tlsConfig, err := e.Auth.GetTLSConfig(ctx, sessionCtx)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		// During the TLS handshake records are framed in pre-login
+		// packets, afterwards they're exchanged directly.
+		handshakeConn := protocol.NewHandshakeConn(conn)
+		tlsConn := tls.Client(handshakeConn, tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return nil, trace.Wrap(err)
+		}
+		handshakeConn.TLSHandshakeDone()
+		return tlsConn, nil
+	case protocol.EncryptionNotSupported, protocol.EncryptionOff:
+		return conn, nil
+	}
+	return nil, trace.BadParameter("unexpected encryption option %#x in pre-login response", response.Encryption())
+}
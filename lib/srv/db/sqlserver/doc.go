@@ -0,0 +1,37 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sqlserver implements SQL Server protocol support for database
+// access.
+//
+// The engine middle-mans the TDS connection: it completes the pre-login
+// and login sequence with the connecting client (whose connection is
+// already authenticated and encrypted by Teleport so TDS-level encryption
+// is reported as not supported), performs its own login with the database
+// server on the client's behalf and then relays protocol messages back
+// and forth auditing queries along the way.
+//
+// Two authentication modes with the database server are supported:
+//
+//   - Azure Active Directory for Azure SQL Database and Azure SQL Managed
+//     Instance using access tokens obtained with the agent's Azure
+//     credentials and passed via the TDS federated authentication login
+//     extension. No Active Directory infrastructure is required.
+//
+//   - Active Directory (Kerberos) authentication for self-hosted servers
+//     using a keytab file, configured via the database's
+//     "teleport.dev/ad-*" labels.
+package sqlserver
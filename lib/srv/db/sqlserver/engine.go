@@ -0,0 +1,247 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlserver
+
+import (
+	"context"
+	"io"
+	"net"
+
+	"github.com/gravitational/teleport/lib/auth"
+	"github.com/gravitational/teleport/lib/defaults"
+	"github.com/gravitational/teleport/lib/services"
+	"github.com/gravitational/teleport/lib/srv/db/common"
+	"github.com/gravitational/teleport/lib/srv/db/common/role"
+	"github.com/gravitational/teleport/lib/srv/db/sqlserver/protocol"
+	"github.com/gravitational/teleport/lib/utils"
+
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+	"github.com/sirupsen/logrus"
+)
+
+// Engine implements the SQL Server database service that accepts client
+// connections coming over reverse tunnel from the proxy and proxies them
+// between the proxy and the SQL Server database instance.
+//
+// Implements common.Engine.
+type Engine struct {
+	// Auth handles database access authentication.
+	Auth common.Auth
+	// Audit emits database access audit events.
+	Audit common.Audit
+	// AuthClient is the cluster auth server client.
+	AuthClient *auth.Client
+	// Context is the database server close context.
+	Context context.Context
+	// Clock is the clock interface.
+	Clock clockwork.Clock
+	// Log is used for logging.
+	Log logrus.FieldLogger
+	// clientConn is an incoming client connection.
+	clientConn net.Conn
+}
+
+// InitializeConnection initializes the client connection.
+func (e *Engine) InitializeConnection(clientConn net.Conn, _ *common.Session) error {
+	e.clientConn = clientConn
+	return nil
+}
+
+// SendError sends an error to the connected client in a TDS understandable
+// format. The error token is wrapped in a response message the client
+// expects while logging in.
+func (e *Engine) SendError(err error) {
+	if err == nil || utils.IsOKNetworkError(err) {
+		return
+	}
+	if errSend := protocol.WriteErrorResponse(e.clientConn, err); errSend != nil {
+		e.Log.WithError(errSend).Debugf("Failed to send error %q to SQL Server client.", err)
+	}
+}
+
+// HandleConnection processes the connection from SQL Server proxy coming
+// over reverse tunnel.
+//
+// It completes the pre-login/login sequence with the client, logs into the
+// database server on the client's behalf and relays messages between them.
+func (e *Engine) HandleConnection(ctx context.Context, sessionCtx *common.Session) error {
+	// Complete the pre-login negotiation with the client. The connection
+	// between the client and Teleport is already encrypted so TDS-level
+	// encryption is reported as not supported.
+	err := e.handleClientPreLogin()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	// Read the client login message for the requested database and audit
+	// information, the authentication material in it is not used.
+	login, err := protocol.ReadLogin7(e.clientConn)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	e.Log.Debugf("Client login: username %q, database %q, application %q.", login.Username, login.Database, login.AppName)
+	if sessionCtx.DatabaseName == "" && login.Database != "" {
+		sessionCtx.DatabaseName = login.Database
+	}
+	if login.ColumnEncryption {
+		e.Log.Debug("Client requested Always Encrypted column encryption.")
+	}
+	// Perform authorization checks.
+	err = e.checkAccess(ctx, sessionCtx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	// Login to the database server on the client's behalf and relay its
+	// login response back to the client.
+	serverConn, loginResponse, err := e.connect(ctx, sessionCtx, login)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer serverConn.Close()
+	err = protocol.WritePacket(e.clientConn, protocol.PacketTypeResponse, loginResponse)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	e.Audit.OnSessionStart(e.Context, sessionCtx, nil)
+	defer e.Audit.OnSessionEnd(e.Context, sessionCtx)
+	// Copy between the connections.
+	clientErrCh := make(chan error, 1)
+	serverErrCh := make(chan error, 1)
+	go e.receiveFromClient(e.clientConn, serverConn, clientErrCh, sessionCtx)
+	go e.receiveFromServer(serverConn, e.clientConn, serverErrCh)
+	select {
+	case err := <-clientErrCh:
+		e.Log.WithError(err).Debug("Client done.")
+	case err := <-serverErrCh:
+		e.Log.WithError(err).Debug("Server done.")
+	case <-ctx.Done():
+		e.Log.Debug("Context canceled.")
+	}
+	return nil
+}
+
+// handleClientPreLogin responds to the client's pre-login negotiation.
+func (e *Engine) handleClientPreLogin() error {
+	_, err := protocol.ReadPreLogin(e.clientConn)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(protocol.WritePreLogin(e.clientConn, protocol.PacketTypeResponse, protocol.PreLoginOptions{
+		protocol.PreLoginVersion:    make([]byte, 6),
+		protocol.PreLoginEncryption: {protocol.EncryptionNotSupported},
+	}))
+}
+
+// checkAccess does authorization check for SQL Server connection about to
+// be established.
+func (e *Engine) checkAccess(ctx context.Context, sessionCtx *common.Session) error {
+	ap, err := e.Auth.GetAuthPreference(ctx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	mfaParams := services.AccessMFAParams{
+		Verified:       sessionCtx.Identity.MFAVerified != "",
+		AlwaysRequired: ap.GetRequireSessionMFA(),
+	}
+	dbRoleMatchers := role.DatabaseRoleMatchers(
+		defaults.ProtocolSQLServer,
+		sessionCtx.DatabaseUser,
+		sessionCtx.DatabaseName,
+		sessionCtx.Identity.ClientIP,
+	)
+	err = sessionCtx.Checker.CheckAccess(
+		sessionCtx.Database,
+		mfaParams,
+		dbRoleMatchers...,
+	)
+	if err != nil {
+		e.Audit.OnSessionStart(e.Context, sessionCtx, err)
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// receiveFromClient relays protocol messages received from SQL Server
+// client to the database, auditing queries along the way.
+func (e *Engine) receiveFromClient(clientConn, serverConn net.Conn, clientErrCh chan<- error, sessionCtx *common.Session) {
+	log := e.Log.WithFields(logrus.Fields{
+		"from":   "client",
+		"client": clientConn.RemoteAddr(),
+		"server": serverConn.RemoteAddr(),
+	})
+	defer func() {
+		log.Debug("Stop receiving from client.")
+		close(clientErrCh)
+	}()
+	for {
+		packetType, data, err := protocol.ReadMessage(clientConn)
+		if err != nil {
+			if utils.IsOKNetworkError(err) {
+				log.Debug("Client connection closed.")
+				return
+			}
+			log.WithError(err).Error("Failed to read client message.")
+			clientErrCh <- err
+			return
+		}
+		switch packetType {
+		case protocol.PacketTypeSQLBatch:
+			query, err := protocol.ParseSQLBatch(data)
+			if err != nil {
+				log.WithError(err).Warn("Failed to parse SQL batch message.")
+			} else {
+				e.Audit.OnQuery(e.Context, sessionCtx, common.Query{Query: query})
+			}
+		case protocol.PacketTypeRPC:
+			rpc, err := protocol.ParseRPCRequest(data)
+			if err != nil {
+				log.WithError(err).Warn("Failed to parse RPC request message.")
+			} else {
+				e.Audit.OnQuery(e.Context, sessionCtx, common.Query{
+					Query:      rpc.ProcName,
+					Parameters: rpc.Parameters,
+				})
+			}
+		}
+		err = protocol.WritePacket(serverConn, packetType, data)
+		if err != nil {
+			log.WithError(err).Error("Failed to write server message.")
+			clientErrCh <- err
+			return
+		}
+	}
+}
+
+// receiveFromServer relays protocol messages received from the database to
+// the SQL Server client.
+func (e *Engine) receiveFromServer(serverConn, clientConn net.Conn, serverErrCh chan<- error) {
+	log := e.Log.WithFields(logrus.Fields{
+		"from":   "server",
+		"client": clientConn.RemoteAddr(),
+		"server": serverConn.RemoteAddr(),
+	})
+	defer func() {
+		log.Debug("Stop receiving from server.")
+		close(serverErrCh)
+	}()
+	// Server messages don't need to be interpreted past the login sequence
+	// so relay the raw stream.
+	_, err := io.Copy(clientConn, serverConn)
+	if err != nil && !utils.IsOKNetworkError(err) {
+		serverErrCh <- err
+	}
+}
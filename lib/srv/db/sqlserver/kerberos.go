@@ -0,0 +1,142 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlserver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/teleport/lib/srv/db/common"
+	"github.com/gravitational/teleport/lib/utils"
+
+	"github.com/jcmturner/gokrb5/v8/client"
+	"github.com/jcmturner/gokrb5/v8/config"
+	"github.com/jcmturner/gokrb5/v8/gssapi"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+	"github.com/jcmturner/gokrb5/v8/spnego"
+
+	"github.com/gravitational/trace"
+)
+
+// getKerberosSSPIBlob logs into Active Directory and returns an SPNEGO
+// token with the service ticket for the SQL Server instance to be sent in
+// the login message.
+//
+// Active Directory settings are provided via the database labels, e.g.:
+//
+//   teleport.dev/ad-domain: EXAMPLE.COM
+//   teleport.dev/ad-keytab-file: /etc/teleport/sqlserver.keytab
+//   teleport.dev/ad-krb5-file: /etc/krb5.conf
+//   teleport.dev/ad-spn: MSSQLSvc/sqlserver.example.com:1433
+//
+// When no keytab file is configured, certificate-based PKINIT login is
+// performed instead using a short-lived Teleport-issued certificate, see
+// pkinit.go.
+func (e *Engine) getKerberosSSPIBlob(ctx context.Context, sessionCtx *common.Session) ([]byte, error) {
+	krbClient, err := e.getKerberosClient(ctx, sessionCtx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	defer krbClient.Destroy()
+	ticket, key, err := krbClient.GetServiceTicket(e.serviceSPN(sessionCtx))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	token, err := spnego.NewKRB5TokenAPREQ(krbClient, ticket, key,
+		[]int{gssapi.ContextFlagInteg, gssapi.ContextFlagConf, gssapi.ContextFlagMutual}, nil)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	blob, err := token.Marshal()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return blob, nil
+}
+
+// getKerberosClient returns a logged in Kerberos client for the database
+// user, using the keytab file when one is configured on the database and
+// falling back to certificate-based PKINIT login otherwise.
+func (e *Engine) getKerberosClient(ctx context.Context, sessionCtx *common.Session) (*client.Client, error) {
+	labels := sessionCtx.Database.GetStaticLabels()
+	domain := labels[types.ADDomainLabel]
+	if domain == "" {
+		return nil, trace.BadParameter("database %q does not have Active Directory domain configured (%v label)",
+			sessionCtx.Database.GetName(), types.ADDomainLabel)
+	}
+	if keytabFile := labels[types.ADKeytabFileLabel]; keytabFile != "" {
+		return e.keytabClient(sessionCtx, keytabFile, domain)
+	}
+	return e.pkinitClient(ctx, sessionCtx, domain)
+}
+
+// keytabClient logs into Active Directory with the configured keytab file.
+func (e *Engine) keytabClient(sessionCtx *common.Session, keytabFile, domain string) (*client.Client, error) {
+	kt, err := keytab.Load(keytabFile)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	krb5Conf, err := config.Load(e.krb5FilePath(sessionCtx))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	krbClient := client.NewWithKeytab(sessionCtx.DatabaseUser, domain, kt, krb5Conf,
+		// Active Directory does not commonly support FAST negotiation.
+		client.DisablePAFXFAST(true))
+	err = krbClient.Login()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return krbClient, nil
+}
+
+// krb5FilePath returns the path to the Kerberos configuration file for the
+// database.
+func (e *Engine) krb5FilePath(sessionCtx *common.Session) string {
+	if krb5File := sessionCtx.Database.GetStaticLabels()[types.ADKrb5FileLabel]; krb5File != "" {
+		return krb5File
+	}
+	return defaultKrb5FilePath
+}
+
+// serviceSPN returns the service principal name for the SQL Server
+// instance, either configured explicitly on the database or derived from
+// its URI.
+func (e *Engine) serviceSPN(sessionCtx *common.Session) string {
+	if spn := sessionCtx.Database.GetStaticLabels()[types.ADSPNLabel]; spn != "" {
+		return spn
+	}
+	// Named instances register SPNs with the instance name in place of the
+	// port e.g. MSSQLSvc/sqlserver.example.com:SQLEXPRESS.
+	if host, instance, found := strings.Cut(sessionCtx.Database.GetURI(), `\`); found {
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		return fmt.Sprintf("MSSQLSvc/%v:%v", host, instance)
+	}
+	addr, err := utils.ParseAddr(sessionCtx.Database.GetURI())
+	if err != nil {
+		return fmt.Sprintf("MSSQLSvc/%v", sessionCtx.Database.GetURI())
+	}
+	return fmt.Sprintf("MSSQLSvc/%v:%v", addr.Host(), addr.Port(1433))
+}
+
+// defaultKrb5FilePath is the default Kerberos configuration file location.
+const defaultKrb5FilePath = "/etc/krb5.conf"
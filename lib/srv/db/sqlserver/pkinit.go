@@ -0,0 +1,253 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlserver
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gravitational/teleport/api/client/proto"
+	"github.com/gravitational/teleport/api/constants"
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/teleport/lib/srv/db/common"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/jcmturner/gokrb5/v8/client"
+	"github.com/jcmturner/gokrb5/v8/config"
+	"github.com/jcmturner/gokrb5/v8/credentials"
+
+	"github.com/gravitational/trace"
+)
+
+// pkinitCertTTL is the TTL of certificates issued for PKINIT login, they
+// are only used to obtain the initial Kerberos ticket.
+const pkinitCertTTL = 5 * time.Minute
+
+// pkinitClient logs the database user into Active Directory using
+// certificate-based PKINIT authentication and returns a Kerberos client
+// with the obtained credentials.
+//
+// Instead of requiring a keytab file on every database agent, a
+// short-lived smartcard-logon style certificate is issued by the Teleport
+// database client CA - the same way Desktop Access authenticates to AD -
+// and exchanged for an initial ticket with "kinit" which implements the
+// PKINIT protocol. The Active Directory domain must be configured to
+// trust the Teleport CA.
+func (e *Engine) pkinitClient(ctx context.Context, sessionCtx *common.Session, domain string) (*client.Client, error) {
+	if e.AuthClient == nil {
+		return nil, trace.BadParameter("PKINIT login requires the auth client, configure a keytab file instead")
+	}
+	upn, err := e.lookupUPN(sessionCtx, domain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	certPEM, keyPEM, err := e.generatePKINITCredentials(ctx, upn)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	// Write the credentials out for kinit and load the resulting
+	// credentials cache back.
+	dir, err := os.MkdirTemp("", "sqlserver-pkinit-*")
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	defer os.RemoveAll(dir)
+	certPath := filepath.Join(dir, "cert.pem")
+	keyPath := filepath.Join(dir, "key.pem")
+	cachePath := filepath.Join(dir, "ccache")
+	anchorsPath := filepath.Join(dir, "anchors.pem")
+	for path, data := range map[string][]byte{
+		certPath:    certPEM,
+		keyPath:     keyPEM,
+		anchorsPath: []byte(sessionCtx.Database.GetCA()),
+	} {
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			return nil, trace.ConvertSystemError(err)
+		}
+	}
+	cmd := exec.CommandContext(ctx, "kinit",
+		"-c", cachePath,
+		"-X", fmt.Sprintf("X509_user_identity=FILE:%v,%v", certPath, keyPath),
+		"-X", fmt.Sprintf("X509_anchors=FILE:%v", anchorsPath),
+		upn)
+	cmd.Env = append(os.Environ(), "KRB5_CONFIG="+e.krb5FilePath(sessionCtx))
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, trace.AccessDenied("PKINIT login for %q failed: %v: %s", upn, err, output)
+	}
+	cache, err := credentials.LoadCCache(cachePath)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	krb5Conf, err := config.Load(e.krb5FilePath(sessionCtx))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	krbClient, err := client.NewFromCCache(cache, krb5Conf,
+		// Active Directory does not commonly support FAST negotiation.
+		client.DisablePAFXFAST(true))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return krbClient, nil
+}
+
+// lookupUPN returns the user principal name the certificate is issued
+// for. When an LDAP address is configured on the database the user is
+// looked up in the directory, both verifying it exists and picking up a
+// UPN that may differ from "user@domain".
+func (e *Engine) lookupUPN(sessionCtx *common.Session, domain string) (string, error) {
+	ldapAddr := sessionCtx.Database.GetStaticLabels()[types.ADLDAPAddrLabel]
+	if ldapAddr == "" {
+		return fmt.Sprintf("%s@%s", sessionCtx.DatabaseUser, domain), nil
+	}
+	tlsConfig := &tls.Config{ServerName: strings.Split(ldapAddr, ":")[0]}
+	if ca := sessionCtx.Database.GetCA(); ca != "" {
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM([]byte(ca)) {
+			return "", trace.BadParameter("invalid database CA certificate")
+		}
+	}
+	conn, err := ldap.DialURL("ldaps://"+ldapAddr, ldap.DialWithTLSConfig(tlsConfig))
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	defer conn.Close()
+	// Search base is the domain converted to a distinguished name e.g.
+	// EXAMPLE.COM becomes DC=EXAMPLE,DC=COM.
+	baseDN := "DC=" + strings.ReplaceAll(domain, ".", ",DC=")
+	result, err := conn.Search(ldap.NewSearchRequest(
+		baseDN,
+		ldap.ScopeWholeSubtree,
+		ldap.NeverDerefAliases,
+		0,
+		0,
+		false,
+		fmt.Sprintf("(&(objectClass=user)(sAMAccountName=%s))", ldap.EscapeFilter(sessionCtx.DatabaseUser)),
+		[]string{"userPrincipalName"},
+		nil,
+	))
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	if len(result.Entries) == 0 {
+		return "", trace.NotFound("user %q not found in Active Directory domain %q",
+			sessionCtx.DatabaseUser, domain)
+	}
+	if upn := result.Entries[0].GetAttributeValue("userPrincipalName"); upn != "" {
+		return upn, nil
+	}
+	return fmt.Sprintf("%s@%s", sessionCtx.DatabaseUser, domain), nil
+}
+
+// generatePKINITCredentials generates a private key and requests a
+// smartcard-logon style certificate for the user principal from the auth
+// server.
+//
+// The certificate requirements are the same as for Desktop Access, see:
+// https://docs.microsoft.com/en-us/troubleshoot/windows-server/windows-security/enabling-smart-card-logon-third-party-certification-authorities
+func (e *Engine) generatePKINITCredentials(ctx context.Context, upn string) (certPEM, keyPEM []byte, err error) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, constants.RSAKeySize)
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	san, err := asn1.Marshal(subjectAltName{
+		OtherName: otherName{
+			OID:   upnOtherNameOID,
+			Value: upnValue{Value: upn},
+		},
+	})
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	eku, err := asn1.Marshal([]asn1.ObjectIdentifier{
+		clientAuthenticationOID,
+		smartcardLogonOID,
+	})
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	csrBytes, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: strings.Split(upn, "@")[0]},
+		// Smartcard logon extended key usage and the otherName SAN with
+		// the UPN aren't supported by crypto/x509 so pass raw extensions.
+		ExtraExtensions: []pkix.Extension{
+			{Id: enhancedKeyUsageExtensionOID, Value: eku},
+			{Id: subjectAltNameExtensionOID, Value: san},
+		},
+	}, rsaKey)
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	resp, err := e.AuthClient.GenerateWindowsDesktopCert(ctx, &proto.WindowsDesktopCertRequest{
+		CSR: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrBytes}),
+		TTL: proto.Duration(pkinitCertTTL),
+	})
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	keyPEM = pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(rsaKey),
+	})
+	return resp.Cert, keyPEM, nil
+}
+
+// Object identifiers used in smartcard logon certificates.
+var (
+	// enhancedKeyUsageExtensionOID identifies the enhanced key usage
+	// extension.
+	enhancedKeyUsageExtensionOID = asn1.ObjectIdentifier{2, 5, 29, 37}
+	// subjectAltNameExtensionOID identifies the subject alternative name
+	// extension.
+	subjectAltNameExtensionOID = asn1.ObjectIdentifier{2, 5, 29, 17}
+	// clientAuthenticationOID identifies the client authentication
+	// extended key usage.
+	clientAuthenticationOID = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 3, 2}
+	// smartcardLogonOID identifies the smartcard logon extended key usage.
+	smartcardLogonOID = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 20, 2, 2}
+	// upnOtherNameOID identifies the user principal name otherName SAN
+	// field.
+	upnOtherNameOID = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 20, 2, 3}
+)
+
+// Types for ASN.1 serialization of the otherName SAN.
+
+type subjectAltName struct {
+	OtherName otherName `asn1:"tag:0"`
+}
+
+type otherName struct {
+	OID   asn1.ObjectIdentifier
+	Value upnValue `asn1:"tag:0"`
+}
+
+type upnValue struct {
+	Value string `asn1:"utf8"`
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protocol
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/gravitational/trace"
+)
+
+const (
+	// errorNumber is the error number reported to clients for errors
+	// raised by Teleport, from the range reserved for user-defined errors.
+	errorNumber = 50000
+	// errorClass is the severity level reported with the error, 16
+	// indicates a general error that can be corrected by the user.
+	errorClass = 16
+	// doneStatusError indicates an error in the done token.
+	doneStatusError = 0x2
+)
+
+// WriteErrorResponse sends a response message with an error token
+// carrying the provided error's message to the client.
+func WriteErrorResponse(w io.Writer, err error) error {
+	message := stringToUCS2(trace.UserMessage(err))
+	// Error token data: number, state, class, message, server name,
+	// procedure name and line number.
+	token := make([]byte, 0, len(message)+14)
+	token = binary.LittleEndian.AppendUint32(token, errorNumber)
+	token = append(token, 1, errorClass)
+	token = binary.LittleEndian.AppendUint16(token, uint16(len(message)/2))
+	token = append(token, message...)
+	token = append(token, 0, 0) // Empty server and procedure names.
+	token = binary.LittleEndian.AppendUint32(token, 0)
+	data := []byte{tokenError}
+	data = binary.LittleEndian.AppendUint16(data, uint16(len(token)))
+	data = append(data, token...)
+	// Finish the response message with a done token indicating the error.
+	data = append(data, tokenDone)
+	data = binary.LittleEndian.AppendUint16(data, doneStatusError)
+	data = binary.LittleEndian.AppendUint16(data, 0)
+	data = append(data, make([]byte, 8)...)
+	return trace.Wrap(WritePacket(w, PacketTypeResponse, data))
+}
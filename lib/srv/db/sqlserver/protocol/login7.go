@@ -0,0 +1,275 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protocol
+
+import (
+	"encoding/binary"
+	"io"
+	"unicode/utf16"
+
+	"github.com/gravitational/trace"
+)
+
+const (
+	// login7FixedSize is the size of the fixed portion of a LOGIN7 message
+	// including the offset/length table and the Length field itself.
+	login7FixedSize = 94
+	// login7TDSVersion is the TDS protocol version sent in login packets,
+	// 7.4 is the current version used by SQL Server 2012 and later.
+	login7TDSVersion = 0x74000004
+	// login7PacketSize is the packet size requested from the server.
+	login7PacketSize = 4096
+	// optionFlags2IntSecurity requests integrated (SSPI) authentication.
+	optionFlags2IntSecurity = 0x80
+	// optionFlags2ODBC indicates ODBC driver defaults.
+	optionFlags2ODBC = 0x02
+	// optionFlags3Extension indicates the login carries a feature
+	// extension block.
+	optionFlags3Extension = 0x10
+	// featureExtFedAuth is the federated authentication feature id.
+	featureExtFedAuth = 0x02
+	// featureExtColumnEncryption is the column encryption (Always
+	// Encrypted) feature id.
+	featureExtColumnEncryption = 0x04
+	// featureExtTerminator ends the feature extension list.
+	featureExtTerminator = 0xff
+	// fedAuthLibrarySecurityToken identifies the security token federated
+	// authentication library in the feature extension options.
+	fedAuthLibrarySecurityToken = 0x01
+)
+
+// Login7 represents a parsed client LOGIN7 message.
+//
+// https://docs.microsoft.com/en-us/openspecs/windows_protocols/ms-tds/773a62b6-ee89-4c02-9e5e-344882630aac
+type Login7 struct {
+	// Username is the requested database user.
+	Username string
+	// Database is the requested database name.
+	Database string
+	// AppName is the connecting application name.
+	AppName string
+	// ColumnEncryption is set when the client requested the column
+	// encryption (Always Encrypted) feature extension.
+	ColumnEncryption bool
+}
+
+// ReadLogin7 reads and parses a client LOGIN7 message from the reader.
+func ReadLogin7(r io.Reader) (*Login7, error) {
+	packetType, data, err := ReadMessage(r)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if packetType != PacketTypeLogin7 {
+		return nil, trace.BadParameter("expected LOGIN7 packet, got %#x", packetType)
+	}
+	if len(data) < login7FixedSize {
+		return nil, trace.BadParameter("truncated LOGIN7 packet")
+	}
+	username, err := readLogin7Field(data, 40)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	appName, err := readLogin7Field(data, 48)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	database, err := readLogin7Field(data, 68)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	login := &Login7{
+		Username: username,
+		Database: database,
+		AppName:  appName,
+	}
+	if data[27]&optionFlags3Extension != 0 {
+		features, err := readLogin7FeatureExt(data)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		_, login.ColumnEncryption = features[featureExtColumnEncryption]
+	}
+	return login, nil
+}
+
+// readLogin7FeatureExt parses the feature extension block of a LOGIN7
+// message into a map of feature id to feature data.
+func readLogin7FeatureExt(data []byte) (map[byte][]byte, error) {
+	// With the extension flag set the field at offset 56 holds the offset
+	// of a DWORD pointing at the feature extension block.
+	pointer := int(binary.LittleEndian.Uint16(data[56:]))
+	if pointer+4 > len(data) {
+		return nil, trace.BadParameter("truncated LOGIN7 extension pointer")
+	}
+	offset := int(binary.LittleEndian.Uint32(data[pointer:]))
+	features := make(map[byte][]byte)
+	for {
+		if offset >= len(data) {
+			return nil, trace.BadParameter("truncated LOGIN7 feature extension block")
+		}
+		featureID := data[offset]
+		if featureID == featureExtTerminator {
+			return features, nil
+		}
+		if offset+5 > len(data) {
+			return nil, trace.BadParameter("truncated LOGIN7 feature extension block")
+		}
+		length := int(binary.LittleEndian.Uint32(data[offset+1:]))
+		offset += 5
+		if offset+length > len(data) {
+			return nil, trace.BadParameter("truncated LOGIN7 feature extension data")
+		}
+		features[featureID] = data[offset : offset+length]
+		offset += length
+	}
+}
+
+// readLogin7Field reads a string field from the LOGIN7 variable data
+// located by the offset/length pair at the specified position.
+func readLogin7Field(data []byte, pos int) (string, error) {
+	offset := int(binary.LittleEndian.Uint16(data[pos:]))
+	// Lengths are specified in UCS-2 characters.
+	length := int(binary.LittleEndian.Uint16(data[pos+2:])) * 2
+	if offset+length > len(data) {
+		return "", trace.BadParameter("truncated LOGIN7 field at %v", pos)
+	}
+	return ucs2ToString(data[offset : offset+length])
+}
+
+// Login7Auth contains authentication material for the login performed by
+// the database service with the database server on the user's behalf.
+type Login7Auth struct {
+	// FedAuthToken is an access token for federated (Azure AD)
+	// authentication.
+	FedAuthToken string
+	// SSPIBlob is an initial Kerberos SSPI token for integrated
+	// authentication.
+	SSPIBlob []byte
+	// FedAuthEcho is set when the server's pre-login response included the
+	// federated authentication required option, it must be echoed back.
+	FedAuthEcho bool
+}
+
+// WriteLogin7 writes a LOGIN7 message authenticating as the login's user
+// to the login's database using the provided authentication material.
+//
+// Feature extensions requested by the client, such as column encryption,
+// are forwarded in the login.
+func WriteLogin7(w io.Writer, login Login7, serverName string, auth Login7Auth) error {
+	fixed := make([]byte, login7FixedSize)
+	binary.LittleEndian.PutUint32(fixed[4:], login7TDSVersion)
+	binary.LittleEndian.PutUint32(fixed[8:], login7PacketSize)
+	fixed[25] = optionFlags2ODBC
+	if len(auth.SSPIBlob) != 0 {
+		fixed[25] |= optionFlags2IntSecurity
+	}
+	var features []byte
+	if auth.FedAuthToken != "" {
+		features = append(features, fedAuthFeature(auth)...)
+	}
+	if login.ColumnEncryption {
+		// Version 1 of the column encryption feature.
+		features = append(features, featureExtColumnEncryption, 1, 0, 0, 0, 0x01)
+	}
+	var featureExt []byte
+	if features != nil {
+		fixed[27] |= optionFlags3Extension
+		featureExt = append(features, featureExtTerminator)
+	}
+	// Variable-length fields follow the fixed portion in order, each
+	// located by an offset/length pair within the fixed portion.
+	data := fixed
+	appendField := func(pos int, value string) {
+		encoded := stringToUCS2(value)
+		binary.LittleEndian.PutUint16(data[pos:], uint16(len(data)))
+		// Lengths are specified in UCS-2 characters.
+		binary.LittleEndian.PutUint16(data[pos+2:], uint16(len(encoded)/2))
+		data = append(data, encoded...)
+	}
+	appendField(36, "")             // Hostname.
+	appendField(40, login.Username) // Username.
+	appendField(44, "")             // Password.
+	appendField(48, "Teleport")     // Application name.
+	appendField(52, serverName)     // Server name.
+	if featureExt != nil {
+		// With the extension flag set this field holds the offset of the
+		// feature extension block which is appended after all other
+		// variable-length fields.
+		binary.LittleEndian.PutUint16(data[56:], uint16(len(data)))
+		binary.LittleEndian.PutUint16(data[58:], 4)
+		data = append(data, make([]byte, 4)...)
+	}
+	appendField(60, "Teleport")     // Client interface name.
+	appendField(64, "")             // Language.
+	appendField(68, login.Database) // Database.
+	appendField(78, "")         // SSPI placeholder, filled below.
+	appendField(82, "")         // Attach database file.
+	appendField(86, "")         // Change password.
+	if len(auth.SSPIBlob) != 0 {
+		binary.LittleEndian.PutUint16(data[78:], uint16(len(data)))
+		binary.LittleEndian.PutUint16(data[80:], uint16(len(auth.SSPIBlob)))
+		data = append(data, auth.SSPIBlob...)
+	}
+	if featureExt != nil {
+		offset := int(binary.LittleEndian.Uint16(data[56:]))
+		binary.LittleEndian.PutUint32(data[offset:], uint32(len(data)))
+		data = append(data, featureExt...)
+	}
+	binary.LittleEndian.PutUint32(data, uint32(len(data)))
+	return trace.Wrap(WritePacket(w, PacketTypeLogin7, data))
+}
+
+// fedAuthFeature builds a feature extension entry with the federated
+// authentication security token.
+//
+// https://docs.microsoft.com/en-us/openspecs/windows_protocols/ms-tds/773a62b6-ee89-4c02-9e5e-344882630aac
+func fedAuthFeature(auth Login7Auth) []byte {
+	options := byte(fedAuthLibrarySecurityToken << 1)
+	if auth.FedAuthEcho {
+		options |= 0x01
+	}
+	token := stringToUCS2(auth.FedAuthToken)
+	data := make([]byte, 0, len(token)+5)
+	data = append(data, options)
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(token)))
+	data = append(data, token...)
+	feature := []byte{featureExtFedAuth}
+	feature = binary.LittleEndian.AppendUint32(feature, uint32(len(data)))
+	return append(feature, data...)
+}
+
+// ucs2ToString decodes a UCS-2 (UTF-16LE) encoded byte sequence.
+func ucs2ToString(data []byte) (string, error) {
+	if len(data)%2 != 0 {
+		return "", trace.BadParameter("odd UCS-2 data length %v", len(data))
+	}
+	chars := make([]uint16, len(data)/2)
+	for i := range chars {
+		chars[i] = binary.LittleEndian.Uint16(data[i*2:])
+	}
+	return string(utf16.Decode(chars)), nil
+}
+
+// stringToUCS2 encodes a string as a UCS-2 (UTF-16LE) byte sequence.
+func stringToUCS2(s string) []byte {
+	chars := utf16.Encode([]rune(s))
+	data := make([]byte, len(chars)*2)
+	for i, char := range chars {
+		binary.LittleEndian.PutUint16(data[i*2:], char)
+	}
+	return data
+}
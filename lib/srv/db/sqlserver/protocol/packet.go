@@ -0,0 +1,133 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protocol
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/gravitational/trace"
+)
+
+const (
+	// PacketTypeSQLBatch is the SQL batch request packet type.
+	PacketTypeSQLBatch = 0x01
+	// PacketTypeRPC is the remote procedure call request packet type.
+	PacketTypeRPC = 0x03
+	// PacketTypeResponse is the server response packet type.
+	PacketTypeResponse = 0x04
+	// PacketTypeLogin7 is the login request packet type.
+	PacketTypeLogin7 = 0x10
+	// PacketTypeSSPI is the SSPI authentication message packet type.
+	PacketTypeSSPI = 0x11
+	// PacketTypePreLogin is the pre-login negotiation packet type.
+	PacketTypePreLogin = 0x12
+)
+
+const (
+	// packetStatusEOM is set on the last packet of a message.
+	packetStatusEOM = 0x01
+	// packetHeaderSize is the size of the TDS packet header.
+	packetHeaderSize = 8
+	// maxPacketDataSize is the maximum amount of data a single packet
+	// written by WritePacket can carry.
+	maxPacketDataSize = 4096 - packetHeaderSize
+)
+
+// Packet represents a single TDS protocol packet.
+//
+// https://docs.microsoft.com/en-us/openspecs/windows_protocols/ms-tds/7af53667-1b72-4703-8258-7984e838f746
+type Packet struct {
+	// Type is the packet type e.g. pre-login, login, SQL batch.
+	Type byte
+	// Status is the packet status bitmask, e.g. end-of-message.
+	Status byte
+	// Data is the packet data without the 8-byte header.
+	Data []byte
+}
+
+// Last returns true if this is the last packet of a message.
+func (p *Packet) Last() bool {
+	return p.Status&packetStatusEOM != 0
+}
+
+// Bytes returns the raw packet bytes including the header.
+func (p *Packet) Bytes() []byte {
+	bytes := make([]byte, packetHeaderSize+len(p.Data))
+	bytes[0] = p.Type
+	bytes[1] = p.Status
+	binary.BigEndian.PutUint16(bytes[2:], uint16(len(bytes)))
+	copy(bytes[packetHeaderSize:], p.Data)
+	return bytes
+}
+
+// ReadPacket reads a single protocol packet from the reader.
+func ReadPacket(r io.Reader) (*Packet, error) {
+	var header [packetHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	length := binary.BigEndian.Uint16(header[2:])
+	if length < packetHeaderSize {
+		return nil, trace.BadParameter("invalid TDS packet length %v", length)
+	}
+	data := make([]byte, length-packetHeaderSize)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	return &Packet{
+		Type:   header[0],
+		Status: header[1],
+		Data:   data,
+	}, nil
+}
+
+// ReadMessage reads a complete message - one or more packets up to one
+// with the end-of-message status - and returns the reassembled data.
+func ReadMessage(r io.Reader) (packetType byte, data []byte, err error) {
+	for {
+		packet, err := ReadPacket(r)
+		if err != nil {
+			return 0, nil, trace.Wrap(err)
+		}
+		packetType = packet.Type
+		data = append(data, packet.Data...)
+		if packet.Last() {
+			return packetType, data, nil
+		}
+	}
+}
+
+// WritePacket writes the provided message data to the writer splitting it
+// into protocol packets as needed.
+func WritePacket(w io.Writer, packetType byte, data []byte) error {
+	for {
+		chunk := data
+		var status byte = packetStatusEOM
+		if len(chunk) > maxPacketDataSize {
+			chunk, status = data[:maxPacketDataSize], 0
+		}
+		packet := Packet{Type: packetType, Status: status, Data: chunk}
+		if _, err := w.Write(packet.Bytes()); err != nil {
+			return trace.ConvertSystemError(err)
+		}
+		if status == packetStatusEOM {
+			return nil
+		}
+		data = data[maxPacketDataSize:]
+	}
+}
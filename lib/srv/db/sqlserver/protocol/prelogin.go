@@ -0,0 +1,127 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protocol
+
+import (
+	"encoding/binary"
+	"io"
+	"sort"
+
+	"github.com/gravitational/trace"
+)
+
+// Pre-login option tokens.
+//
+// https://docs.microsoft.com/en-us/openspecs/windows_protocols/ms-tds/60f56408-0188-4cd5-8b90-25c6f2423868
+const (
+	// PreLoginVersion is the version pre-login option.
+	PreLoginVersion = 0x00
+	// PreLoginEncryption is the encryption pre-login option.
+	PreLoginEncryption = 0x01
+	// PreLoginFedAuthRequired is the federated authentication pre-login option.
+	PreLoginFedAuthRequired = 0x06
+	// preLoginTerminator ends the pre-login option list.
+	preLoginTerminator = 0xff
+)
+
+// Encryption negotiation values exchanged in the pre-login packets.
+const (
+	// EncryptionOff means encryption is available but off.
+	EncryptionOff = 0x00
+	// EncryptionOn means encryption is available and on.
+	EncryptionOn = 0x01
+	// EncryptionNotSupported means encryption is not available.
+	EncryptionNotSupported = 0x02
+	// EncryptionRequired means encryption is required.
+	EncryptionRequired = 0x03
+)
+
+// PreLoginOptions is a set of pre-login negotiation options keyed by the
+// option token.
+type PreLoginOptions map[byte][]byte
+
+// Encryption returns the negotiated encryption option value.
+func (o PreLoginOptions) Encryption() byte {
+	if option, ok := o[PreLoginEncryption]; ok && len(option) == 1 {
+		return option[0]
+	}
+	return EncryptionNotSupported
+}
+
+// ReadPreLogin reads a pre-login message (either a client request or a
+// server response) and returns its options.
+func ReadPreLogin(r io.Reader) (PreLoginOptions, error) {
+	packetType, data, err := ReadMessage(r)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	// Server pre-login responses come in a "response" typed packet.
+	if packetType != PacketTypePreLogin && packetType != PacketTypeResponse {
+		return nil, trace.BadParameter("expected pre-login packet, got %#x", packetType)
+	}
+	return parsePreLoginOptions(data)
+}
+
+// WritePreLogin writes a pre-login message with the provided options as a
+// packet of the specified type - pre-login for client requests, response
+// for server responses.
+func WritePreLogin(w io.Writer, packetType byte, options PreLoginOptions) error {
+	// Options must be serialized in the token order.
+	tokens := make([]int, 0, len(options))
+	for token := range options {
+		tokens = append(tokens, int(token))
+	}
+	sort.Ints(tokens)
+	// Option headers are followed by the terminator and the option data.
+	dataOffset := len(options)*5 + 1
+	headers := make([]byte, 0, dataOffset)
+	var payload []byte
+	for _, token := range tokens {
+		option := options[byte(token)]
+		header := make([]byte, 5)
+		header[0] = byte(token)
+		binary.BigEndian.PutUint16(header[1:], uint16(dataOffset+len(payload)))
+		binary.BigEndian.PutUint16(header[3:], uint16(len(option)))
+		headers = append(headers, header...)
+		payload = append(payload, option...)
+	}
+	headers = append(headers, preLoginTerminator)
+	return trace.Wrap(WritePacket(w, packetType, append(headers, payload...)))
+}
+
+// parsePreLoginOptions parses pre-login message option headers and data.
+func parsePreLoginOptions(data []byte) (PreLoginOptions, error) {
+	options := make(PreLoginOptions)
+	for pos := 0; ; pos += 5 {
+		if pos >= len(data) {
+			return nil, trace.BadParameter("missing pre-login terminator")
+		}
+		token := data[pos]
+		if token == preLoginTerminator {
+			return options, nil
+		}
+		if pos+5 > len(data) {
+			return nil, trace.BadParameter("truncated pre-login option header")
+		}
+		offset := binary.BigEndian.Uint16(data[pos+1:])
+		length := binary.BigEndian.Uint16(data[pos+3:])
+		if int(offset)+int(length) > len(data) {
+			return nil, trace.BadParameter("truncated pre-login option data")
+		}
+		options[token] = data[offset : offset+length]
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protocol
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestPacketRoundtrip verifies messages are split into packets and
+// reassembled back.
+func TestPacketRoundtrip(t *testing.T) {
+	// Larger than a single packet to exercise splitting.
+	data := bytes.Repeat([]byte{0x42}, maxPacketDataSize*2+100)
+	var buf bytes.Buffer
+	require.NoError(t, WritePacket(&buf, PacketTypeSQLBatch, data))
+
+	packetType, readData, err := ReadMessage(&buf)
+	require.NoError(t, err)
+	require.Equal(t, byte(PacketTypeSQLBatch), packetType)
+	require.Equal(t, data, readData)
+}
+
+// TestPreLoginRoundtrip verifies pre-login messages are serialized and
+// parsed back.
+func TestPreLoginRoundtrip(t *testing.T) {
+	options := PreLoginOptions{
+		PreLoginVersion:         make([]byte, 6),
+		PreLoginEncryption:      {EncryptionOn},
+		PreLoginFedAuthRequired: {0x01},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, WritePreLogin(&buf, PacketTypePreLogin, options))
+
+	readOptions, err := ReadPreLogin(&buf)
+	require.NoError(t, err)
+	require.Equal(t, options, readOptions)
+	require.Equal(t, byte(EncryptionOn), readOptions.Encryption())
+}
+
+// TestLogin7Roundtrip verifies client login packets built by WriteLogin7
+// are parsed back by ReadLogin7.
+func TestLogin7Roundtrip(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteLogin7(&buf, Login7{
+		Username:         "alice",
+		Database:         "master",
+		ColumnEncryption: true,
+	}, "sqlserver.example.com", Login7Auth{
+		FedAuthToken: "test-token",
+	})
+	require.NoError(t, err)
+
+	login, err := ReadLogin7(&buf)
+	require.NoError(t, err)
+	require.Equal(t, "alice", login.Username)
+	require.Equal(t, "master", login.Database)
+	require.Equal(t, "Teleport", login.AppName)
+	require.True(t, login.ColumnEncryption)
+}
+
+// TestParseSQLBatch verifies SQL text extraction from batch messages.
+func TestParseSQLBatch(t *testing.T) {
+	headers := make([]byte, 22)
+	headers[0] = 22 // Total headers length.
+	data := append(headers, stringToUCS2("select 1")...)
+
+	query, err := ParseSQLBatch(data)
+	require.NoError(t, err)
+	require.Equal(t, "select 1", query)
+}
+
+// TestParseRPCRequest verifies procedure name and parameter extraction
+// from RPC request messages.
+func TestParseRPCRequest(t *testing.T) {
+	nvarcharParam := func(value string) []byte {
+		encoded := stringToUCS2(value)
+		param := []byte{
+			0,            // No parameter name.
+			0,            // Status flags.
+			typeNVarChar, // TYPE_INFO.
+			0xFF, 0xFF,   // Maximum length.
+			0, 0, 0, 0, 0, // Collation.
+		}
+		param = append(param, byte(len(encoded)), byte(len(encoded)>>8))
+		return append(param, encoded...)
+	}
+
+	t.Run("procedure by name", func(t *testing.T) {
+		headers := make([]byte, 22)
+		headers[0] = 22 // Total headers length.
+		name := stringToUCS2("dbo.usp_account")
+		data := append(headers, byte(len(name)/2), 0)
+		data = append(data, name...)
+		data = append(data, 0, 0) // Option flags.
+
+		rpc, err := ParseRPCRequest(data)
+		require.NoError(t, err)
+		require.Equal(t, "dbo.usp_account", rpc.ProcName)
+		require.Empty(t, rpc.Parameters)
+	})
+
+	t.Run("procedure by ID with parameters", func(t *testing.T) {
+		headers := make([]byte, 22)
+		headers[0] = 22            // Total headers length.
+		data := append(headers,
+			0xFF, 0xFF, // Procedure ID switch.
+			10, 0, // sp_executesql.
+			0, 0) // Option flags.
+		data = append(data, nvarcharParam("select * from accounts where id = @id")...)
+		data = append(data, nvarcharParam("@id int")...)
+
+		rpc, err := ParseRPCRequest(data)
+		require.NoError(t, err)
+		require.Equal(t, "sp_executesql", rpc.ProcName)
+		require.Equal(t, []string{"select * from accounts where id = @id", "@id int"}, rpc.Parameters)
+	})
+}
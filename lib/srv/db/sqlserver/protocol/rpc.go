@@ -0,0 +1,173 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protocol
+
+import (
+	"encoding/binary"
+
+	"github.com/gravitational/trace"
+)
+
+// RPCRequest represents a parsed RPC request message.
+type RPCRequest struct {
+	// ProcName is the called procedure name, either taken from the message
+	// directly or resolved from a well-known procedure ID.
+	ProcName string
+	// Parameters contains string representations of the call parameters
+	// that could be decoded.
+	Parameters []string
+}
+
+// ParseRPCRequest extracts the procedure name and parameters from an RPC
+// request message data.
+//
+// https://docs.microsoft.com/en-us/openspecs/windows_protocols/ms-tds/619c43b6-9495-4a58-9e49-a4950db245b3
+func ParseRPCRequest(data []byte) (*RPCRequest, error) {
+	if len(data) < 4 {
+		return nil, trace.BadParameter("truncated RPC request message")
+	}
+	// The request is preceded by the ALL_HEADERS rule which starts with
+	// its total length.
+	headersLen := int(binary.LittleEndian.Uint32(data))
+	if headersLen > len(data) {
+		return nil, trace.BadParameter("invalid RPC request headers length %v", headersLen)
+	}
+	data = data[headersLen:]
+	// Procedure is identified either by name or, when the name length is
+	// 0xFFFF, by a well-known procedure ID.
+	if len(data) < 2 {
+		return nil, trace.BadParameter("truncated RPC request message")
+	}
+	request := &RPCRequest{}
+	nameLen := binary.LittleEndian.Uint16(data)
+	data = data[2:]
+	if nameLen == procIDSwitch {
+		if len(data) < 2 {
+			return nil, trace.BadParameter("truncated RPC request message")
+		}
+		request.ProcName = procIDToName(binary.LittleEndian.Uint16(data))
+		data = data[2:]
+	} else {
+		if len(data) < int(nameLen)*2 {
+			return nil, trace.BadParameter("truncated RPC request message")
+		}
+		name, err := ucs2ToString(data[:nameLen*2])
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		request.ProcName = name
+		data = data[nameLen*2:]
+	}
+	// Skip the option flags.
+	if len(data) < 2 {
+		return nil, trace.BadParameter("truncated RPC request message")
+	}
+	data = data[2:]
+	// Decode parameters on a best-effort basis: full TYPE_INFO parsing
+	// covers dozens of data types so only the textual ones sp_executesql
+	// and friends use for the statement and its parameter declarations are
+	// decoded, parsing stops at the first parameter of another type.
+	request.Parameters = parseRPCParameters(data)
+	return request, nil
+}
+
+// parseRPCParameters decodes textual RPC call parameters, stopping at the
+// first parameter that isn't a national character type.
+func parseRPCParameters(data []byte) (parameters []string) {
+	for len(data) > 0 {
+		// B_VARCHAR parameter name.
+		nameLen := int(data[0])
+		data = data[1:]
+		if len(data) < nameLen*2+1 {
+			return parameters
+		}
+		data = data[nameLen*2:]
+		// Status flags.
+		data = data[1:]
+		// TYPE_INFO, only national character types are decoded.
+		if len(data) < 1 {
+			return parameters
+		}
+		typeID := data[0]
+		if typeID != typeNVarChar && typeID != typeNChar {
+			return parameters
+		}
+		// Maximum length and collation.
+		if len(data) < 10 {
+			return parameters
+		}
+		data = data[8:]
+		// Actual value length in bytes, 0xFFFF indicates NULL.
+		valueLen := binary.LittleEndian.Uint16(data)
+		data = data[2:]
+		if valueLen == charNull {
+			parameters = append(parameters, "NULL")
+			continue
+		}
+		if len(data) < int(valueLen) {
+			return parameters
+		}
+		value, err := ucs2ToString(data[:valueLen])
+		if err != nil {
+			return parameters
+		}
+		parameters = append(parameters, value)
+		data = data[valueLen:]
+	}
+	return parameters
+}
+
+// procIDToName returns the name of a well-known system stored procedure.
+func procIDToName(id uint16) string {
+	if int(id) < len(procIDNames) && procIDNames[id] != "" {
+		return procIDNames[id]
+	}
+	return "unknown"
+}
+
+const (
+	// procIDSwitch is the procedure name length indicating that the
+	// procedure is identified by ID instead.
+	procIDSwitch = 0xFFFF
+	// typeNVarChar is the NVARCHARTYPE TDS data type token.
+	typeNVarChar = 0xE7
+	// typeNChar is the NCHARTYPE TDS data type token.
+	typeNChar = 0xEF
+	// charNull is the character type value length indicating NULL.
+	charNull = 0xFFFF
+)
+
+// procIDNames maps well-known procedure IDs to their names.
+//
+// https://docs.microsoft.com/en-us/openspecs/windows_protocols/ms-tds/619c43b6-9495-4a58-9e49-a4950db245b3
+var procIDNames = []string{
+	1:  "sp_cursor",
+	2:  "sp_cursoropen",
+	3:  "sp_cursorprepare",
+	4:  "sp_cursorexecute",
+	5:  "sp_cursorprepexec",
+	6:  "sp_cursorunprepare",
+	7:  "sp_cursorfetch",
+	8:  "sp_cursoroption",
+	9:  "sp_cursorclose",
+	10: "sp_executesql",
+	11: "sp_prepare",
+	12: "sp_execute",
+	13: "sp_prepexec",
+	14: "sp_prepexecrpc",
+	15: "sp_unprepare",
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protocol
+
+import (
+	"encoding/binary"
+
+	"github.com/gravitational/trace"
+)
+
+// ParseSQLBatch extracts the SQL text from an SQL batch message data.
+//
+// https://docs.microsoft.com/en-us/openspecs/windows_protocols/ms-tds/f2026cd3-9a46-4a3f-9a08-f63140bcbbe3
+func ParseSQLBatch(data []byte) (string, error) {
+	if len(data) < 4 {
+		return "", trace.BadParameter("truncated SQL batch message")
+	}
+	// The batch text is preceded by the ALL_HEADERS rule which starts with
+	// its total length.
+	headersLen := int(binary.LittleEndian.Uint32(data))
+	if headersLen > len(data) {
+		return "", trace.BadParameter("invalid SQL batch headers length %v", headersLen)
+	}
+	query, err := ucs2ToString(data[headersLen:])
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	return query, nil
+}
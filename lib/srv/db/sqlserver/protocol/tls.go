@@ -0,0 +1,79 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protocol
+
+import (
+	"net"
+)
+
+// HandshakeConn allows performing a TLS handshake with a SQL Server
+// instance.
+//
+// During the handshake the TLS records must be wrapped inside TDS pre-login
+// packets, after the handshake completes TLS records are exchanged directly
+// on the wire. The connection starts in the handshake mode and must be
+// switched over with TLSHandshakeDone once the handshake is finished.
+type HandshakeConn struct {
+	net.Conn
+	// handshakeDone indicates the TLS handshake has completed and packet
+	// framing is no longer applied.
+	handshakeDone bool
+	// readBuf buffers unconsumed data of the last read packet.
+	readBuf []byte
+}
+
+// NewHandshakeConn returns a server connection wrapper for performing the
+// TLS handshake.
+func NewHandshakeConn(conn net.Conn) *HandshakeConn {
+	return &HandshakeConn{Conn: conn}
+}
+
+// TLSHandshakeDone switches the connection from TDS packet framing to the
+// raw exchange used after the TLS handshake completes.
+func (c *HandshakeConn) TLSHandshakeDone() {
+	c.handshakeDone = true
+}
+
+// Read reads from the connection stripping TDS packet framing while the
+// handshake is in progress.
+func (c *HandshakeConn) Read(b []byte) (int, error) {
+	if c.handshakeDone {
+		return c.Conn.Read(b)
+	}
+	if len(c.readBuf) == 0 {
+		packet, err := ReadPacket(c.Conn)
+		if err != nil {
+			return 0, err
+		}
+		c.readBuf = packet.Data
+	}
+	n := copy(b, c.readBuf)
+	c.readBuf = c.readBuf[n:]
+	return n, nil
+}
+
+// Write writes to the connection wrapping data in TDS pre-login packets
+// while the handshake is in progress.
+func (c *HandshakeConn) Write(b []byte) (int, error) {
+	if c.handshakeDone {
+		return c.Conn.Write(b)
+	}
+	if err := WritePacket(c.Conn, PacketTypePreLogin, b); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
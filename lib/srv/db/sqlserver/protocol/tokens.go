@@ -0,0 +1,132 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protocol
+
+import (
+	"encoding/binary"
+
+	"github.com/gravitational/trace"
+)
+
+// Data stream token types appearing in login responses.
+//
+// https://docs.microsoft.com/en-us/openspecs/windows_protocols/ms-tds/67b6113c-d722-42d1-902c-3f6e8de09173
+const (
+	// tokenError is the error token.
+	tokenError = 0xaa
+	// tokenInfo is the info message token.
+	tokenInfo = 0xab
+	// tokenLoginAck is the login acknowledgement token.
+	tokenLoginAck = 0xad
+	// tokenFeatureExtAck is the feature extension acknowledgement token.
+	tokenFeatureExtAck = 0xae
+	// tokenSSPI is the SSPI authentication data token.
+	tokenSSPI = 0xed
+	// tokenEnvChange is the environment change token.
+	tokenEnvChange = 0xe3
+	// tokenDone is the done token.
+	tokenDone = 0xfd
+	// tokenDoneProc is the stored procedure done token.
+	tokenDoneProc = 0xfe
+	// tokenDoneInProc is the statement-within-procedure done token.
+	tokenDoneInProc = 0xff
+)
+
+// CheckLoginResponse scans a login response token stream and returns an
+// error if the login was rejected or not acknowledged by the server.
+func CheckLoginResponse(data []byte) error {
+	var loginAck bool
+	pos := 0
+	for pos < len(data) {
+		token := data[pos]
+		pos++
+		switch token {
+		case tokenLoginAck:
+			loginAck = true
+			fallthrough
+		case tokenInfo, tokenEnvChange, tokenSSPI:
+			if pos+2 > len(data) {
+				return trace.BadParameter("truncated login response token %#x", token)
+			}
+			pos += 2 + int(binary.LittleEndian.Uint16(data[pos:]))
+		case tokenError:
+			message, err := parseErrorToken(data[pos:])
+			if err != nil {
+				return trace.Wrap(err)
+			}
+			return trace.AccessDenied("database login failed: %v", message)
+		case tokenFeatureExtAck:
+			length, err := featureExtAckLength(data[pos:])
+			if err != nil {
+				return trace.Wrap(err)
+			}
+			pos += length
+		case tokenDone, tokenDoneProc, tokenDoneInProc:
+			pos += 12
+		default:
+			// Unrecognized token, can't parse further.
+			if loginAck {
+				return nil
+			}
+			return trace.BadParameter("unexpected login response token %#x", token)
+		}
+	}
+	if !loginAck {
+		return trace.AccessDenied("database server did not acknowledge login")
+	}
+	return nil
+}
+
+// parseErrorToken extracts the error message from an error token following
+// its length prefix.
+//
+// https://docs.microsoft.com/en-us/openspecs/windows_protocols/ms-tds/9805e9fa-1f8b-4cf8-8f78-8d2602228635
+func parseErrorToken(data []byte) (string, error) {
+	// Length(2), number(4), state(1), class(1), then the message length in
+	// characters followed by the UCS-2 encoded message.
+	if len(data) < 10 {
+		return "", trace.BadParameter("truncated error token")
+	}
+	msgLen := int(binary.LittleEndian.Uint16(data[8:])) * 2
+	if 10+msgLen > len(data) {
+		return "", trace.BadParameter("truncated error token message")
+	}
+	message, err := ucs2ToString(data[10 : 10+msgLen])
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	return message, nil
+}
+
+// featureExtAckLength returns the length of a feature extension
+// acknowledgement token data which, unlike most tokens, is not
+// length-prefixed.
+func featureExtAckLength(data []byte) (int, error) {
+	pos := 0
+	for {
+		if pos >= len(data) {
+			return 0, trace.BadParameter("truncated feature ack token")
+		}
+		if data[pos] == featureExtTerminator {
+			return pos + 1, nil
+		}
+		if pos+5 > len(data) {
+			return 0, trace.BadParameter("truncated feature ack entry")
+		}
+		pos += 5 + int(binary.LittleEndian.Uint32(data[pos+1:]))
+	}
+}
@@ -103,8 +103,10 @@ func (s *Server) startResourceWatcher(ctx context.Context) (*services.DatabaseWa
 // selectors and register/unregister them appropriately.
 func (s *Server) startCloudWatcher(ctx context.Context) error {
 	watcher, err := watchers.NewWatcher(ctx, watchers.WatcherConfig{
-		AWSMatchers: s.cfg.AWSMatchers,
-		Clients:     s.cfg.CloudClients,
+		AWSMatchers:   s.cfg.AWSMatchers,
+		AzureMatchers: s.cfg.AzureMatchers,
+		GCPMatchers:   s.cfg.GCPMatchers,
+		Clients:       s.cfg.CloudClients,
 	})
 	if err != nil {
 		if trace.IsNotFound(err) {
@@ -174,7 +176,7 @@ func (s *Server) matcher(resource types.ResourceWithLabels) bool {
 	if !ok {
 		return false
 	}
-	if database.IsRDS() || database.IsRedshift() {
+	if database.IsCloudHosted() {
 		return true // Cloud fetchers return only matching databases.
 	}
 	return services.MatchResourceLabels(s.cfg.ResourceMatchers, database)
@@ -18,6 +18,7 @@ package desktop
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/gravitational/teleport/api/types"
 	"github.com/gravitational/teleport/api/types/events"
@@ -87,6 +88,179 @@ func (s *WindowsService) onSessionEnd(ctx context.Context, id *tlsca.Identity, w
 	s.emit(ctx, event)
 }
 
+// onClipboard emits an audit event for clipboard data crossing a desktop
+// connection. eventType is either libevents.DesktopClipboardSendEvent or
+// libevents.DesktopClipboardReceiveEvent. Clipboard events reuse the
+// desktop session start wire message, the format and size of the data are
+// recorded in the status user message since the message has no dedicated
+// fields for them.
+func (s *WindowsService) onClipboard(ctx context.Context, id *tlsca.Identity, windowsUser, sessionID string, desktop types.WindowsDesktop, eventType, format string, size int) {
+	code := libevents.DesktopClipboardSendCode
+	if eventType == libevents.DesktopClipboardReceiveEvent {
+		code = libevents.DesktopClipboardReceiveCode
+	}
+	event := &events.WindowsDesktopSessionStart{
+		Metadata: events.Metadata{
+			Type:        eventType,
+			Code:        code,
+			ClusterName: s.clusterName,
+		},
+		UserMetadata: events.UserMetadata{
+			User:         id.Username,
+			Impersonator: id.Impersonator,
+		},
+		SessionMetadata: events.SessionMetadata{
+			SessionID: sessionID,
+			WithMFA:   id.MFAVerified,
+		},
+		ConnectionMetadata: events.ConnectionMetadata{
+			LocalAddr:  id.ClientIP,
+			RemoteAddr: desktop.GetAddr(),
+			Protocol:   libevents.EventProtocolTDP,
+		},
+		Status: events.Status{
+			Success:     true,
+			UserMessage: fmt.Sprintf("%v clipboard data, %v bytes", format, size),
+		},
+		WindowsDesktopService: s.cfg.Heartbeat.HostUUID,
+		DesktopAddr:           desktop.GetAddr(),
+		Domain:                desktop.GetDomain(),
+		WindowsUser:           windowsUser,
+		DesktopLabels:         desktop.GetAllLabels(),
+	}
+	s.emit(ctx, event)
+}
+
+// onSharedDirectory emits an audit event for shared directory activity on
+// a desktop connection. eventType is one of the
+// libevents.DesktopDirectory* event types. Like the clipboard events, the
+// directory sharing events reuse the desktop session start wire message
+// with the details of the transfer recorded in the status user message.
+func (s *WindowsService) onSharedDirectory(ctx context.Context, id *tlsca.Identity, windowsUser, sessionID string, desktop types.WindowsDesktop, eventType, path string, size int) {
+	code := libevents.DesktopDirectoryShareCode
+	message := fmt.Sprintf("shared directory %q", path)
+	switch eventType {
+	case libevents.DesktopDirectoryReadEvent:
+		code = libevents.DesktopDirectoryReadCode
+		message = fmt.Sprintf("read %v, %v bytes", path, size)
+	case libevents.DesktopDirectoryWriteEvent:
+		code = libevents.DesktopDirectoryWriteCode
+		message = fmt.Sprintf("wrote %v, %v bytes", path, size)
+	}
+	event := &events.WindowsDesktopSessionStart{
+		Metadata: events.Metadata{
+			Type:        eventType,
+			Code:        code,
+			ClusterName: s.clusterName,
+		},
+		UserMetadata: events.UserMetadata{
+			User:         id.Username,
+			Impersonator: id.Impersonator,
+		},
+		SessionMetadata: events.SessionMetadata{
+			SessionID: sessionID,
+			WithMFA:   id.MFAVerified,
+		},
+		ConnectionMetadata: events.ConnectionMetadata{
+			LocalAddr:  id.ClientIP,
+			RemoteAddr: desktop.GetAddr(),
+			Protocol:   libevents.EventProtocolTDP,
+		},
+		Status: events.Status{
+			Success:     true,
+			UserMessage: message,
+		},
+		WindowsDesktopService: s.cfg.Heartbeat.HostUUID,
+		DesktopAddr:           desktop.GetAddr(),
+		Domain:                desktop.GetDomain(),
+		WindowsUser:           windowsUser,
+		DesktopLabels:         desktop.GetAllLabels(),
+	}
+	s.emit(ctx, event)
+}
+
+// onSmartcardAuth emits an audit event for an in-session certificate
+// request answered (or refused) by the virtual smart card, for example a
+// UAC prompt or a "runas" invocation inside the session. Like the other
+// in-session desktop events, it reuses the desktop session start wire
+// message with the details recorded in the status.
+func (s *WindowsService) onSmartcardAuth(ctx context.Context, id *tlsca.Identity, windowsUser, sessionID string, desktop types.WindowsDesktop, allowed bool) {
+	code := libevents.DesktopSmartcardAuthCode
+	message := "in-session certificate request answered by the virtual smart card"
+	if !allowed {
+		code = libevents.DesktopSmartcardAuthFailureCode
+		message = "in-session certificate request denied by role options"
+	}
+	event := &events.WindowsDesktopSessionStart{
+		Metadata: events.Metadata{
+			Type:        libevents.DesktopSmartcardAuthEvent,
+			Code:        code,
+			ClusterName: s.clusterName,
+		},
+		UserMetadata: events.UserMetadata{
+			User:         id.Username,
+			Impersonator: id.Impersonator,
+		},
+		SessionMetadata: events.SessionMetadata{
+			SessionID: sessionID,
+			WithMFA:   id.MFAVerified,
+		},
+		ConnectionMetadata: events.ConnectionMetadata{
+			LocalAddr:  id.ClientIP,
+			RemoteAddr: desktop.GetAddr(),
+			Protocol:   libevents.EventProtocolTDP,
+		},
+		Status: events.Status{
+			Success:     allowed,
+			UserMessage: message,
+		},
+		WindowsDesktopService: s.cfg.Heartbeat.HostUUID,
+		DesktopAddr:           desktop.GetAddr(),
+		Domain:                desktop.GetDomain(),
+		WindowsUser:           windowsUser,
+		DesktopLabels:         desktop.GetAllLabels(),
+	}
+	s.emit(ctx, event)
+}
+
+// onInputThrottled emits an audit event when a client sends keyboard or
+// mouse input faster than the per-session rate limit and the excess input
+// is dropped. Like the other in-session desktop events, it reuses the
+// desktop session start wire message with the details recorded in the
+// status.
+func (s *WindowsService) onInputThrottled(ctx context.Context, id *tlsca.Identity, windowsUser, sessionID string, desktop types.WindowsDesktop) {
+	event := &events.WindowsDesktopSessionStart{
+		Metadata: events.Metadata{
+			Type:        libevents.DesktopInputThrottledEvent,
+			Code:        libevents.DesktopInputThrottledCode,
+			ClusterName: s.clusterName,
+		},
+		UserMetadata: events.UserMetadata{
+			User:         id.Username,
+			Impersonator: id.Impersonator,
+		},
+		SessionMetadata: events.SessionMetadata{
+			SessionID: sessionID,
+			WithMFA:   id.MFAVerified,
+		},
+		ConnectionMetadata: events.ConnectionMetadata{
+			LocalAddr:  id.ClientIP,
+			RemoteAddr: desktop.GetAddr(),
+			Protocol:   libevents.EventProtocolTDP,
+		},
+		Status: events.Status{
+			Success:     false,
+			UserMessage: "keyboard and mouse input exceeded the per-session rate limit, excess input dropped",
+		},
+		WindowsDesktopService: s.cfg.Heartbeat.HostUUID,
+		DesktopAddr:           desktop.GetAddr(),
+		Domain:                desktop.GetDomain(),
+		WindowsUser:           windowsUser,
+		DesktopLabels:         desktop.GetAllLabels(),
+	}
+	s.emit(ctx, event)
+}
+
 func (s *WindowsService) emit(ctx context.Context, event events.AuditEvent) {
 	if err := s.cfg.Emitter.EmitAuditEvent(ctx, event); err != nil {
 		s.cfg.Log.WithError(err).Errorf("Failed to emit audit event %v", event)
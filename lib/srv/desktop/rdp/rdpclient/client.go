@@ -68,6 +68,7 @@ import (
 	"errors"
 	"fmt"
 	"image"
+	"image/draw"
 	"os"
 	"runtime/cgo"
 	"sync"
@@ -114,6 +115,12 @@ type Client struct {
 	// Parameters read from the TDP stream.
 	clientWidth, clientHeight uint16
 	username                  string
+	// encodings is the set of frame encodings the client advertised in a
+	// ClientCapabilities message. Clients that sent none get PNG only.
+	encodings tdp.Encoding
+	// keyboardLayout is the Windows keyboard layout identifier announced
+	// by the client, defaulting to US English.
+	keyboardLayout uint32
 
 	// RDP client on the Rust side.
 	rustClient *C.Client
@@ -127,6 +134,28 @@ type Client struct {
 	// goroutines to complete
 	wg        sync.WaitGroup
 	closeOnce sync.Once
+	// done is closed when the client is closed, to stop the background
+	// latency measurement goroutine.
+	done chan struct{}
+
+	// outputMu serializes writes to the TDP connection, which are produced
+	// by several goroutines (frames, latency probes, pong echoes).
+	outputMu sync.Mutex
+
+	// pingMu guards the in-flight latency probes.
+	pingMu   sync.Mutex
+	pingID   uint32
+	pingSent map[uint32]time.Time
+
+	// screenMu guards the server-side copy of the current framebuffer,
+	// kept for screenshot captures. The buffer is allocated at connection
+	// setup with the negotiated screen size, so the frame handling
+	// goroutine never reads the client size fields above.
+	screenMu sync.Mutex
+	screen   *image.NRGBA
+	// screenDrawn is set once the first frame has been applied, to avoid
+	// capturing screenshots of a blank screen.
+	screenDrawn bool
 
 	clientActivityMu sync.RWMutex
 	clientLastActive time.Time
@@ -138,8 +167,12 @@ func New(ctx context.Context, cfg Config) (*Client, error) {
 		return nil, err
 	}
 	c := &Client{
-		cfg:           cfg,
-		readyForInput: 0,
+		cfg:            cfg,
+		readyForInput:  0,
+		encodings:      tdp.EncodingPNG,
+		done:           make(chan struct{}),
+		pingSent:       make(map[uint32]time.Time),
+		keyboardLayout: keyboardLayoutUSEnglish,
 	}
 
 	if err := c.readClientUsername(); err != nil {
@@ -151,6 +184,9 @@ func New(ctx context.Context, cfg Config) (*Client, error) {
 	if err := c.readClientSize(); err != nil {
 		return nil, trace.Wrap(err)
 	}
+	// The screen size cannot change for the duration of the connection, so
+	// the framebuffer copy is allocated once up front.
+	c.screen = image.NewNRGBA(image.Rect(0, 0, int(c.clientWidth), int(c.clientHeight)))
 	if err := c.connect(ctx); err != nil {
 		return nil, trace.Wrap(err)
 	}
@@ -158,20 +194,44 @@ func New(ctx context.Context, cfg Config) (*Client, error) {
 	return c, nil
 }
 
+// supportedEncodings is the set of frame encodings the server can produce.
+const supportedEncodings = tdp.EncodingPNG | tdp.EncodingRLE
+
+// supportedCapabilities returns the optional TDP features the server
+// supports for this session, accounting for role restrictions.
+func (c *Client) supportedCapabilities() tdp.Capability {
+	capabilities := tdp.CapabilityDirectorySharing | tdp.CapabilityPing
+	if c.cfg.AllowClipboard {
+		capabilities |= tdp.CapabilityClipboard
+	}
+	return capabilities
+}
+
 func (c *Client) readClientUsername() error {
 	for {
 		msg, err := c.cfg.Conn.InputMessage()
 		if err != nil {
 			return trace.Wrap(err)
 		}
-		u, ok := msg.(tdp.ClientUsername)
-		if !ok {
+		switch m := msg.(type) {
+		case tdp.ClientHello:
+			// Clients that support version negotiation send the hello
+			// before anything else. The negotiated encodings supersede a
+			// later ClientCapabilities message.
+			hello := m.Negotiate(c.supportedCapabilities(), supportedEncodings)
+			c.cfg.Log.Debugf("Negotiated TDP version %d, capabilities %b, encodings %b",
+				hello.Version, hello.Capabilities, hello.Encodings)
+			if err := c.cfg.Conn.OutputMessage(hello); err != nil {
+				return trace.Wrap(err)
+			}
+			c.encodings = hello.Encodings
+		case tdp.ClientUsername:
+			c.cfg.Log.Debugf("Got RDP username %q", m.Username)
+			c.username = m.Username
+			return nil
+		default:
 			c.cfg.Log.Debugf("Expected ClientUsername message, got %T", msg)
-			continue
 		}
-		c.cfg.Log.Debugf("Got RDP username %q", u.Username)
-		c.username = u.Username
-		return nil
 	}
 }
 
@@ -181,15 +241,23 @@ func (c *Client) readClientSize() error {
 		if err != nil {
 			return trace.Wrap(err)
 		}
-		s, ok := msg.(tdp.ClientScreenSpec)
-		if !ok {
+		switch m := msg.(type) {
+		case tdp.ClientCapabilities:
+			// Capabilities are optional and sent before the screen spec.
+			c.cfg.Log.Debugf("Got client frame encodings %b", m.Encodings)
+			c.encodings = m.Encodings | tdp.EncodingPNG
+		case tdp.ClientKeyboardLayout:
+			// The layout is optional and sent before the screen spec.
+			c.cfg.Log.Debugf("Got client keyboard layout 0x%x", m.KeyboardLayout)
+			c.keyboardLayout = m.KeyboardLayout
+		case tdp.ClientScreenSpec:
+			c.cfg.Log.Debugf("Got RDP screen size %dx%d", m.Width, m.Height)
+			c.clientWidth = uint16(m.Width)
+			c.clientHeight = uint16(m.Height)
+			return nil
+		default:
 			c.cfg.Log.Debugf("Expected ClientScreenSpec message, got %T", msg)
-			continue
 		}
-		c.cfg.Log.Debugf("Got RDP screen size %dx%d", s.Width, s.Height)
-		c.clientWidth = uint16(s.Width)
-		c.clientHeight = uint16(s.Height)
-		return nil
 	}
 }
 
@@ -199,6 +267,11 @@ func (c *Client) connect(ctx context.Context) error {
 		return trace.Wrap(err)
 	}
 
+	// TODO: announce c.keyboardLayout in the RDP client core data once the
+	// underlying RDP library accepts a keyboard layout; until then the RDP
+	// server applies its default layout, which produces wrong characters
+	// for many keys on non-US layouts.
+
 	// Addr and username strings only need to be valid for the duration of
 	// C.connect_rdp. They are copied on the Rust side and can be freed here.
 	addr := C.CString(c.cfg.Addr)
@@ -246,6 +319,44 @@ func (c *Client) start() {
 		}
 	}()
 
+	// Periodic screenshot worker goroutine.
+	if c.cfg.ScreenshotInterval > 0 && c.cfg.OnScreenshot != nil {
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			t := time.NewTicker(c.cfg.ScreenshotInterval)
+			defer t.Stop()
+			for {
+				select {
+				case <-c.done:
+					return
+				case <-t.C:
+				}
+				c.captureScreenshot()
+			}
+		}()
+	}
+
+	// Latency measurement worker goroutine, periodically pings the client
+	// and records the round-trip time of the pong.
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		t := time.NewTicker(pingInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-c.done:
+				return
+			case <-t.C:
+			}
+			if err := c.sendPing(); err != nil {
+				c.cfg.Log.Debugf("Failed sending latency probe: %v", err)
+				return
+			}
+		}
+	}()
+
 	// User input streaming worker goroutine.
 	c.wg.Add(1)
 	go func() {
@@ -254,6 +365,7 @@ func (c *Client) start() {
 		defer c.cfg.Log.Info("RDP input streaming finished")
 		// Remember mouse coordinates to send them with all CGOPointer events.
 		var mouseX, mouseY uint32
+		limiter := newInputLimiter(c.cfg.Log, c.cfg.OnInputThrottled)
 		for {
 			msg, err := c.cfg.Conn.InputMessage()
 			if err != nil {
@@ -268,6 +380,15 @@ func (c *Client) start() {
 
 			c.UpdateClientActivity()
 
+			// Rate-limit keyboard and mouse input to protect the RDP
+			// server from malicious or buggy clients.
+			switch msg.(type) {
+			case tdp.MouseMove, tdp.MouseButton, tdp.MouseWheel, tdp.KeyboardButton:
+				if !limiter.Allow(time.Now()) {
+					continue
+				}
+			}
+
 			switch m := msg.(type) {
 			case tdp.MouseMove:
 				mouseX, mouseY = m.X, m.Y
@@ -284,6 +405,10 @@ func (c *Client) start() {
 					return
 				}
 			case tdp.MouseButton:
+				if !validMouseButton(m.Button) {
+					c.cfg.Log.Debugf("Dropping mouse button event with unknown button %v", m.Button)
+					continue
+				}
 				// Map the button to a C enum value.
 				var button C.CGOPointerButton
 				switch m.Button {
@@ -293,8 +418,6 @@ func (c *Client) start() {
 					button = C.PointerButtonRight
 				case tdp.MiddleMouseButton:
 					button = C.PointerButtonMiddle
-				default:
-					button = C.PointerButtonNone
 				}
 				if err := cgoError(C.write_rdp_pointer(
 					c.rustClient,
@@ -339,6 +462,10 @@ func (c *Client) start() {
 					return
 				}
 			case tdp.KeyboardButton:
+				if !validKeyCode(m.KeyCode) {
+					c.cfg.Log.Debugf("Dropping keyboard event with impossible scancode %#x", m.KeyCode)
+					continue
+				}
 				if err := cgoError(C.write_rdp_keyboard(
 					c.rustClient,
 					C.CGOKeyboardEvent{
@@ -349,6 +476,37 @@ func (c *Client) start() {
 					c.cfg.Log.Warningf("Failed forwarding RDP input message: %v", err)
 					return
 				}
+			case tdp.ClipboardData:
+				c.handleClipboard("text", m.Data)
+			case tdp.ClipboardImage:
+				c.handleClipboard("image", m.Data)
+			case tdp.SharedDirectoryAnnounce:
+				c.cfg.Log.Infof("Client shared directory %q (id %v)", m.Name, m.DirectoryID)
+				if c.cfg.OnSharedDirectory != nil {
+					c.cfg.OnSharedDirectory(DirectoryShare, m.Name, 0)
+				}
+				// TODO: announce the directory as a drive over the rdpdr
+				// virtual channel once the underlying RDP library exposes it.
+			case tdp.SharedDirectoryReadResponse:
+				if m.ErrCode == tdp.SharedDirectoryErrNil && c.cfg.OnSharedDirectory != nil {
+					c.cfg.OnSharedDirectory(DirectoryRead, m.Path, len(m.Data))
+				}
+			case tdp.SharedDirectoryWriteResponse:
+				if m.ErrCode == tdp.SharedDirectoryErrNil && c.cfg.OnSharedDirectory != nil {
+					c.cfg.OnSharedDirectory(DirectoryWrite, m.Path, int(m.BytesWritten))
+				}
+			case tdp.Ping:
+				// Echo the probe back so the client can measure its own
+				// round-trip latency for its connection quality indicator.
+				if err := c.output(tdp.Pong{ID: m.ID}); err != nil {
+					c.cfg.Log.Warningf("Failed responding to latency probe: %v", err)
+					return
+				}
+			case tdp.Pong:
+				c.handlePong(m.ID)
+			case tdp.SharedDirectoryListResponse:
+				// Directory listings are relayed to the server but not
+				// audited per-entry.
 			default:
 				c.cfg.Log.Warningf("Skipping unimplemented desktop protocol message type %T", msg)
 			}
@@ -356,6 +514,104 @@ func (c *Client) start() {
 	}()
 }
 
+// updateScreen applies a frame to the server-side copy of the current
+// framebuffer.
+func (c *Client) updateScreen(img *image.NRGBA) {
+	c.screenMu.Lock()
+	defer c.screenMu.Unlock()
+	draw.Draw(c.screen, img.Rect, img, img.Rect.Min, draw.Src)
+	c.screenDrawn = true
+}
+
+// captureScreenshot encodes a still PNG of the current framebuffer and
+// hands it to the OnScreenshot callback to be attached to the session
+// recording.
+func (c *Client) captureScreenshot() {
+	if c.cfg.OnScreenshot == nil {
+		return
+	}
+	c.screenMu.Lock()
+	defer c.screenMu.Unlock()
+	if !c.screenDrawn {
+		return
+	}
+	// Encode with a throwaway encoder, the shared one is not safe for
+	// concurrent use with the frame encoding.
+	frame, err := tdp.NewPNG(c.screen, nil).Encode()
+	if err != nil {
+		c.cfg.Log.Warningf("Failed capturing session screenshot: %v", err)
+		return
+	}
+	c.cfg.OnScreenshot(frame)
+}
+
+// output sends a TDP message to the client, serializing concurrent
+// senders.
+func (c *Client) output(m tdp.Message) error {
+	c.outputMu.Lock()
+	defer c.outputMu.Unlock()
+	return c.cfg.Conn.OutputMessage(m)
+}
+
+// sendPing sends a latency probe to the client and records its send time.
+func (c *Client) sendPing() error {
+	c.pingMu.Lock()
+	c.pingID++
+	id := c.pingID
+	c.pingSent[id] = time.Now()
+	c.pingMu.Unlock()
+	return c.output(tdp.Ping{ID: id})
+}
+
+// handlePong records the round-trip time of an answered latency probe.
+func (c *Client) handlePong(id uint32) {
+	c.pingMu.Lock()
+	sent, ok := c.pingSent[id]
+	delete(c.pingSent, id)
+	c.pingMu.Unlock()
+	if !ok {
+		// Not a probe we sent, or already answered.
+		return
+	}
+	sessionRTT.Observe(time.Since(sent).Seconds())
+}
+
+// handleClipboard processes clipboard data sent by the client. The data is
+// audited via the OnClipboard callback before being forwarded to the RDP
+// server.
+func (c *Client) handleClipboard(format string, data []byte) {
+	if !c.cfg.AllowClipboard {
+		c.cfg.Log.Debug("Clipboard sharing is not allowed by the user's roles, dropping clipboard data")
+		return
+	}
+	if c.cfg.OnClipboard != nil {
+		c.cfg.OnClipboard(ClipboardSend, format, len(data))
+	}
+	// Capture what was on screen when the clipboard was used.
+	c.captureScreenshot()
+	// TODO: forward the data over the cliprdr virtual channel once the
+	// underlying RDP library exposes it.
+	c.cfg.Log.Debugf("Dropping %v bytes of clipboard %v data, the RDP library does not expose the clipboard channel yet", len(data), format)
+}
+
+// handleSmartcardAuth decides whether an in-session certificate request
+// from the remote desktop may be answered by the virtual smart card used
+// for the RDP login, and audits the request via the OnSmartcardAuth
+// callback.
+//
+// TODO: invoke this from the smart card emulation once the underlying RDP
+// library distinguishes in-session certificate requests from the login.
+func (c *Client) handleSmartcardAuth() bool {
+	allowed := c.cfg.AllowSmartcardAuth
+	if c.cfg.OnSmartcardAuth != nil {
+		c.cfg.OnSmartcardAuth(allowed)
+	}
+	if !allowed {
+		c.cfg.Log.Debug("In-session smart card authentication is not allowed by the user's roles, refusing certificate request")
+	}
+	return allowed
+}
+
 //export handle_bitmap
 func handle_bitmap(handle C.uintptr_t, cb C.CGOBitmap) C.CGOError {
 	return cgo.Handle(handle).Value().(*Client).handleBitmap(cb)
@@ -365,7 +621,7 @@ func (c *Client) handleBitmap(cb C.CGOBitmap) C.CGOError {
 	// Notify the input forwarding goroutine that we're ready for input.
 	// Input can only be sent after connection was established, which we infer
 	// from the fact that a bitmap was sent.
-	atomic.StoreUint32(&c.readyForInput, 1)
+	sessionStarted := atomic.CompareAndSwapUint32(&c.readyForInput, 0, 1)
 
 	data := C.GoBytes(unsafe.Pointer(cb.data_ptr), C.int(cb.data_len))
 	// Convert BGRA to RGBA. It's likely due to Windows using uint32 values for
@@ -383,9 +639,24 @@ func (c *Client) handleBitmap(cb C.CGOBitmap) C.CGOError {
 	})
 	copy(img.Pix, data)
 
-	if err := c.cfg.Conn.OutputMessage(tdp.NewPNG(img, c.cfg.Encoder)); err != nil {
-		return C.CString(fmt.Sprintf("failed to send PNG frame %v: %v", img.Rect, err))
+	c.updateScreen(img)
+	if sessionStarted {
+		// Capture the initial screen contents for the session recording.
+		c.captureScreenshot()
+	}
+
+	// Prefer the run-length encoding when the client supports it: it is much
+	// cheaper to produce than a PNG. WebP frames are not emitted until a
+	// WebP encoder is available.
+	var frame tdp.Message = tdp.NewPNG(img, c.cfg.Encoder)
+	if c.encodings&tdp.EncodingRLE != 0 {
+		frame = tdp.NewRLE(img)
+	}
+	if err := c.output(frame); err != nil {
+		framesDropped.Inc()
+		return C.CString(fmt.Sprintf("failed to send frame %v: %v", img.Rect, err))
 	}
+	framesSent.Inc()
 	return nil
 }
 
@@ -402,6 +673,7 @@ func (c *Client) Wait() error {
 // Calls other than the first one are no-ops.
 func (c *Client) Close() {
 	c.closeOnce.Do(func() {
+		close(c.done)
 		if err := cgoError(C.close_rdp(c.rustClient)); err != nil {
 			c.cfg.Log.Warningf("Error closing RDP connection: %v", err)
 		}
@@ -20,6 +20,7 @@ package rdpclient
 import (
 	"context"
 	"image/png"
+	"time"
 
 	"github.com/gravitational/teleport/lib/srv/desktop/tdp"
 	"github.com/gravitational/trace"
@@ -43,10 +44,140 @@ type Config struct {
 	// Encoder is an optional override for PNG encoding.
 	Encoder *png.Encoder
 
+	// AllowClipboard enables forwarding of clipboard data between the
+	// client and the remote desktop. When disabled, clipboard messages
+	// are dropped.
+	AllowClipboard bool
+
+	// ScreenshotInterval, if set, makes the client periodically capture a
+	// screenshot of the session in addition to the event-driven captures.
+	ScreenshotInterval time.Duration
+
+	// OnScreenshot, if set, is called with a TDP-encoded full-screen PNG
+	// frame whenever a screenshot of the session is captured, so it can be
+	// attached to the session recording.
+	OnScreenshot func(frame []byte)
+
+	// AllowSmartcardAuth enables the virtual smart card used for the RDP
+	// login to answer in-session certificate requests, such as UAC
+	// prompts or "runas" invocations. When disabled, only the initial
+	// login is answered.
+	AllowSmartcardAuth bool
+
+	// OnSmartcardAuth, if set, is called with whether the request was
+	// allowed whenever the remote desktop requests an in-session
+	// certificate from the virtual smart card.
+	OnSmartcardAuth func(allowed bool)
+
+	// OnClipboard, if set, is called with the direction (ClipboardSend or
+	// ClipboardReceive), format ("text" or "image") and size in bytes of
+	// every clipboard payload crossing the connection.
+	OnClipboard func(direction, format string, size int)
+
+	// OnSharedDirectory, if set, is called with the operation
+	// (DirectoryShare, DirectoryRead or DirectoryWrite), path and size in
+	// bytes of every shared directory access crossing the connection.
+	OnSharedDirectory func(op, path string, size int)
+
+	// OnInputThrottled, if set, is called whenever throttling of the
+	// client's input event stream engages.
+	OnInputThrottled func()
+
 	// Log is the logger for status messages.
 	Log logrus.FieldLogger
 }
 
+// Directions of clipboard data reported to Config.OnClipboard.
+const (
+	// ClipboardSend is clipboard data heading to the remote desktop.
+	ClipboardSend = "send"
+	// ClipboardReceive is clipboard data heading to the client.
+	ClipboardReceive = "receive"
+)
+
+// Operations of shared directory activity reported to
+// Config.OnSharedDirectory.
+const (
+	// DirectoryShare is the client announcing a shared directory.
+	DirectoryShare = "share"
+	// DirectoryRead is a file read from a shared directory.
+	DirectoryRead = "read"
+	// DirectoryWrite is a file written to in a shared directory.
+	DirectoryWrite = "write"
+)
+
+// keyboardLayoutUSEnglish is the Windows keyboard layout identifier (KLID)
+// for US English, assumed for clients that do not announce a layout.
+const keyboardLayoutUSEnglish = 0x409
+
+// maxInputEventsPerSecond caps the rate of keyboard and mouse messages
+// forwarded to the RDP server. The cap is far above anything a human can
+// produce and only engages for malicious or buggy clients.
+const maxInputEventsPerSecond = 500
+
+// throttleReportInterval is the minimum time between input throttling
+// reports, to avoid flooding the audit log under a sustained burst.
+const throttleReportInterval = 30 * time.Second
+
+// inputLimiter throttles the stream of input events forwarded to the RDP
+// server and reports when throttling engages. It is only used from the
+// input streaming goroutine and needs no locking.
+type inputLimiter struct {
+	log logrus.FieldLogger
+	// onThrottle is called when throttling engages, at most once per
+	// throttleReportInterval.
+	onThrottle func()
+	// window is the start of the current one-second counting window.
+	window time.Time
+	// count is the number of input events seen in the current window.
+	count int
+	// lastReport is the time throttling was last reported.
+	lastReport time.Time
+}
+
+func newInputLimiter(log logrus.FieldLogger, onThrottle func()) *inputLimiter {
+	return &inputLimiter{log: log, onThrottle: onThrottle}
+}
+
+// Allow returns true if an input event observed at the provided time is
+// within the rate limit.
+func (l *inputLimiter) Allow(now time.Time) bool {
+	if now.Sub(l.window) >= time.Second {
+		l.window = now
+		l.count = 0
+	}
+	l.count++
+	if l.count <= maxInputEventsPerSecond {
+		return true
+	}
+	if now.Sub(l.lastReport) >= throttleReportInterval {
+		l.lastReport = now
+		l.log.Warnf("Throttling client input: more than %v events per second.", maxInputEventsPerSecond)
+		if l.onThrottle != nil {
+			l.onThrottle()
+		}
+	}
+	return false
+}
+
+// validKeyCode returns true if the provided keyboard scancode is possible
+// on a physical keyboard: a base scancode set 1 code or an extended
+// (0xE0-prefixed) one. Anything else is dropped before reaching the RDP
+// server.
+func validKeyCode(code uint32) bool {
+	return code <= 0xFF || (code >= 0xE000 && code <= 0xE0FF)
+}
+
+// validMouseButton returns true if the provided mouse button is one the
+// protocol defines.
+func validMouseButton(button tdp.MouseButtonType) bool {
+	switch button {
+	case tdp.LeftMouseButton, tdp.MiddleMouseButton, tdp.RightMouseButton:
+		return true
+	}
+	return false
+}
+
 // GenerateUserCertFn generates user certificates for RDP authentication.
 type GenerateUserCertFn func(ctx context.Context, username string) (certDER, keyDER []byte, err error)
 
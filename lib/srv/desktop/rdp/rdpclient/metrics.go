@@ -0,0 +1,55 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rdpclient
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// pingInterval is how often the service probes the round-trip latency of
+// each desktop session.
+const pingInterval = 5 * time.Second
+
+var (
+	sessionRTT = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "windows_desktop_session_rtt_seconds",
+			Help:    "Round-trip latency between the Windows desktop service and desktop clients.",
+			Buckets: prometheus.ExponentialBuckets(0.001, 2, 12),
+		},
+	)
+	framesSent = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "windows_desktop_frames_sent_total",
+			Help: "Number of screen frames sent to desktop clients.",
+		},
+	)
+	framesDropped = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "windows_desktop_frames_dropped_total",
+			Help: "Number of screen frames that could not be delivered to desktop clients.",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(sessionRTT)
+	prometheus.MustRegister(framesSent)
+	prometheus.MustRegister(framesDropped)
+}
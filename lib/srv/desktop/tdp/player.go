@@ -0,0 +1,125 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tdp
+
+import (
+	"bufio"
+	"errors"
+	"image"
+	"image/draw"
+	"io"
+
+	"github.com/gravitational/trace"
+)
+
+// Player decodes a recorded stream of TDP messages and reconstructs the
+// remote desktop screen from the frames in it, for session playback.
+//
+// A recorded desktop session is the byte stream of both directions of the
+// TDP connection, so the decoded messages include client input as well as
+// screen updates; only the latter change the screen.
+type Player struct {
+	cr     *countingReader
+	bufr   *bufio.Reader
+	screen *image.NRGBA
+}
+
+// NewPlayer creates a Player reading a recorded TDP stream from r.
+func NewPlayer(r io.Reader) *Player {
+	cr := &countingReader{r: r}
+	return &Player{
+		cr:   cr,
+		bufr: bufio.NewReader(cr),
+	}
+}
+
+// NextMessage returns the next message in the stream and the stream offset
+// of the first byte after it. The offset lets callers correlate messages
+// with the timing metadata recorded alongside the stream. io.EOF is
+// returned unwrapped when the stream ends.
+func (p *Player) NextMessage() (Message, int64, error) {
+	m, err := decode(p.bufr)
+	if err != nil {
+		if errors.Is(trace.Unwrap(err), io.EOF) {
+			return nil, p.offset(), io.EOF
+		}
+		return nil, p.offset(), trace.Wrap(err)
+	}
+	return m, p.offset(), nil
+}
+
+// offset is the number of stream bytes consumed by decoded messages,
+// excluding any bytes read ahead into the buffer.
+func (p *Player) offset() int64 {
+	return p.cr.n - int64(p.bufr.Buffered())
+}
+
+// Apply updates the screen with m and returns true if the message changed
+// the screen contents. Input messages are valid but have no effect.
+func (p *Player) Apply(m Message) bool {
+	switch m := m.(type) {
+	case ClientScreenSpec:
+		p.resize(int(m.Width), int(m.Height))
+		return true
+	case PNGFrame:
+		p.drawFrame(m.Img)
+		return true
+	case RLEFrame:
+		img, err := m.ToImage()
+		if err != nil {
+			return false
+		}
+		p.drawFrame(img)
+		return true
+	}
+	return false
+}
+
+// Screen returns the current screen contents. It returns nil until the
+// first message affecting the screen has been applied.
+func (p *Player) Screen() *image.NRGBA {
+	return p.screen
+}
+
+func (p *Player) resize(width, height int) {
+	resized := image.NewNRGBA(image.Rect(0, 0, width, height))
+	if p.screen != nil {
+		draw.Draw(resized, p.screen.Rect, p.screen, p.screen.Rect.Min, draw.Src)
+	}
+	p.screen = resized
+}
+
+func (p *Player) drawFrame(img image.Image) {
+	if p.screen == nil {
+		// Dimensions weren't announced before the first frame, size the
+		// screen to fit it.
+		p.resize(img.Bounds().Max.X, img.Bounds().Max.Y)
+	}
+	draw.Draw(p.screen, img.Bounds(), img, img.Bounds().Min, draw.Src)
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
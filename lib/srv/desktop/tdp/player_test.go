@@ -0,0 +1,67 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tdp
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlayer(t *testing.T) {
+	frame := image.NewNRGBA(image.Rect(10, 10, 20, 20))
+	for x := frame.Rect.Min.X; x < frame.Rect.Max.X; x++ {
+		for y := frame.Rect.Min.Y; y < frame.Rect.Max.Y; y++ {
+			frame.Set(x, y, color.NRGBA{255, 0, 0, 255})
+		}
+	}
+
+	// A recorded stream mixes input messages with screen updates.
+	var stream bytes.Buffer
+	for _, m := range []Message{
+		ClientScreenSpec{Width: 100, Height: 100},
+		MouseMove{X: 1, Y: 2},
+		NewRLE(frame),
+	} {
+		buf, err := m.Encode()
+		require.NoError(t, err)
+		stream.Write(buf)
+	}
+
+	p := NewPlayer(&stream)
+
+	var prevOffset int64
+	for _, wantScreenChange := range []bool{true, false, true} {
+		m, offset, err := p.NextMessage()
+		require.NoError(t, err)
+		require.Greater(t, offset, prevOffset)
+		prevOffset = offset
+		require.Equal(t, wantScreenChange, p.Apply(m))
+	}
+
+	_, _, err := p.NextMessage()
+	require.ErrorIs(t, err, io.EOF)
+
+	screen := p.Screen()
+	require.Equal(t, image.Rect(0, 0, 100, 100), screen.Rect)
+	require.Equal(t, color.NRGBA{255, 0, 0, 255}, screen.NRGBAAt(15, 15))
+	require.Equal(t, color.NRGBA{0, 0, 0, 0}, screen.NRGBAAt(50, 50))
+}
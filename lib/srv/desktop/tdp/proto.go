@@ -38,15 +38,31 @@ type MessageType byte
 // For descriptions of each message type see:
 // https://github.com/gravitational/teleport/blob/master/rfd/0037-desktop-access-protocol.md#message-types
 const (
-	TypeClientScreenSpec = MessageType(1)
-	TypePNGFrame         = MessageType(2)
-	TypeMouseMove        = MessageType(3)
-	TypeMouseButton      = MessageType(4)
-	TypeKeyboardButton   = MessageType(5)
-	TypeClipboardData    = MessageType(6)
-	TypeClientUsername   = MessageType(7)
-	TypeMouseWheel       = MessageType(8)
-	TypeError            = MessageType(9)
+	TypeClientScreenSpec             = MessageType(1)
+	TypePNGFrame                     = MessageType(2)
+	TypeMouseMove                    = MessageType(3)
+	TypeMouseButton                  = MessageType(4)
+	TypeKeyboardButton               = MessageType(5)
+	TypeClipboardData                = MessageType(6)
+	TypeClientUsername               = MessageType(7)
+	TypeMouseWheel                   = MessageType(8)
+	TypeError                        = MessageType(9)
+	TypeClipboardImage               = MessageType(10)
+	TypeSharedDirectoryAnnounce      = MessageType(11)
+	TypeSharedDirectoryReadRequest   = MessageType(12)
+	TypeSharedDirectoryReadResponse  = MessageType(13)
+	TypeSharedDirectoryWriteRequest  = MessageType(14)
+	TypeSharedDirectoryWriteResponse = MessageType(15)
+	TypeSharedDirectoryListRequest   = MessageType(16)
+	TypeSharedDirectoryListResponse  = MessageType(17)
+	TypeClientCapabilities           = MessageType(18)
+	TypeRLEFrame                     = MessageType(19)
+	TypeWebPFrame                    = MessageType(20)
+	TypePing                         = MessageType(21)
+	TypePong                         = MessageType(22)
+	TypeClientKeyboardLayout         = MessageType(23)
+	TypeClientHello                  = MessageType(24)
+	TypeServerHello                  = MessageType(25)
 )
 
 // Message is a Go representation of a desktop protocol message.
@@ -93,6 +109,40 @@ func decode(in peekReader) (Message, error) {
 		return decodeKeyboardButton(in)
 	case TypeClientUsername:
 		return decodeClientUsername(in)
+	case TypeClipboardData:
+		return decodeClipboardData(in)
+	case TypeClipboardImage:
+		return decodeClipboardImage(in)
+	case TypeSharedDirectoryAnnounce:
+		return decodeSharedDirectoryAnnounce(in)
+	case TypeSharedDirectoryReadRequest:
+		return decodeSharedDirectoryReadRequest(in)
+	case TypeSharedDirectoryReadResponse:
+		return decodeSharedDirectoryReadResponse(in)
+	case TypeSharedDirectoryWriteRequest:
+		return decodeSharedDirectoryWriteRequest(in)
+	case TypeSharedDirectoryWriteResponse:
+		return decodeSharedDirectoryWriteResponse(in)
+	case TypeSharedDirectoryListRequest:
+		return decodeSharedDirectoryListRequest(in)
+	case TypeSharedDirectoryListResponse:
+		return decodeSharedDirectoryListResponse(in)
+	case TypeClientCapabilities:
+		return decodeClientCapabilities(in)
+	case TypeRLEFrame:
+		return decodeRLEFrame(in)
+	case TypeWebPFrame:
+		return decodeWebPFrame(in)
+	case TypePing:
+		return decodePing(in)
+	case TypePong:
+		return decodePong(in)
+	case TypeClientKeyboardLayout:
+		return decodeClientKeyboardLayout(in)
+	case TypeClientHello:
+		return decodeClientHello(in)
+	case TypeServerHello:
+		return decodeServerHello(in)
 	case TypeError:
 		return decodeError(in)
 	default:
@@ -299,6 +349,367 @@ func decodeClientScreenSpec(in peekReader) (ClientScreenSpec, error) {
 	return s, trace.Wrap(err)
 }
 
+// Encoding is a bitmask of frame encodings supported by a client.
+type Encoding uint32
+
+const (
+	// EncodingPNG is the PNG frame encoding every client supports.
+	EncodingPNG = Encoding(1 << 0)
+	// EncodingRLE is the lossless run-length frame encoding.
+	EncodingRLE = Encoding(1 << 1)
+	// EncodingWebP is the lossy WebP frame encoding.
+	EncodingWebP = Encoding(1 << 2)
+)
+
+// ClientCapabilities is sent by the client before the ClientScreenSpec to
+// advertise the frame encodings it supports in addition to PNG. Clients
+// that never send it are served PNG frames only.
+type ClientCapabilities struct {
+	Encodings Encoding
+}
+
+func (c ClientCapabilities) Encode() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteByte(byte(TypeClientCapabilities))
+	if err := binary.Write(buf, binary.BigEndian, c); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeClientCapabilities(in peekReader) (ClientCapabilities, error) {
+	t, err := in.ReadByte()
+	if err != nil {
+		return ClientCapabilities{}, trace.Wrap(err)
+	}
+	if t != byte(TypeClientCapabilities) {
+		return ClientCapabilities{}, trace.BadParameter("got message type %v, expected TypeClientCapabilities(%v)", t, TypeClientCapabilities)
+	}
+	var c ClientCapabilities
+	err = binary.Read(in, binary.BigEndian, &c)
+	return c, trace.Wrap(err)
+}
+
+// ProtocolVersion is the TDP protocol version implemented by this release.
+// It is advertised in the hello exchange and bumped whenever the protocol
+// changes in a way peers need to know about.
+const ProtocolVersion = uint32(1)
+
+// Capability is a bitmask of optional TDP features a peer supports.
+type Capability uint32
+
+const (
+	// CapabilityClipboard indicates support for the clipboard sharing
+	// messages (ClipboardData, ClipboardImage).
+	CapabilityClipboard = Capability(1 << 0)
+	// CapabilityDirectorySharing indicates support for the shared
+	// directory messages.
+	CapabilityDirectorySharing = Capability(1 << 1)
+	// CapabilityPing indicates support for the ping/pong latency messages.
+	CapabilityPing = Capability(1 << 2)
+)
+
+// ClientHello is the first message sent by clients that support version
+// negotiation. It advertises the client's protocol version along with the
+// optional features and frame encodings it supports. Clients that don't
+// send it are assumed to speak version 0 where feature support is implied
+// by the release both sides run.
+type ClientHello struct {
+	Version      uint32
+	Capabilities Capability
+	Encodings    Encoding
+}
+
+func (h ClientHello) Encode() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteByte(byte(TypeClientHello))
+	if err := binary.Write(buf, binary.BigEndian, h); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeClientHello(in peekReader) (ClientHello, error) {
+	t, err := in.ReadByte()
+	if err != nil {
+		return ClientHello{}, trace.Wrap(err)
+	}
+	if t != byte(TypeClientHello) {
+		return ClientHello{}, trace.BadParameter("got message type %v, expected TypeClientHello(%v)", t, TypeClientHello)
+	}
+	var h ClientHello
+	err = binary.Read(in, binary.BigEndian, &h)
+	return h, trace.Wrap(err)
+}
+
+// ServerHello is sent by the server in response to ClientHello. It carries
+// the negotiated protocol version and feature set: the lower of the two
+// versions and the intersection of the advertised capabilities and
+// encodings. Both sides must only use the features confirmed here.
+type ServerHello struct {
+	Version      uint32
+	Capabilities Capability
+	Encodings    Encoding
+}
+
+func (h ServerHello) Encode() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteByte(byte(TypeServerHello))
+	if err := binary.Write(buf, binary.BigEndian, h); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeServerHello(in peekReader) (ServerHello, error) {
+	t, err := in.ReadByte()
+	if err != nil {
+		return ServerHello{}, trace.Wrap(err)
+	}
+	if t != byte(TypeServerHello) {
+		return ServerHello{}, trace.BadParameter("got message type %v, expected TypeServerHello(%v)", t, TypeServerHello)
+	}
+	var h ServerHello
+	err = binary.Read(in, binary.BigEndian, &h)
+	return h, trace.Wrap(err)
+}
+
+// Negotiate returns the hello the server should respond with given the
+// features it supports itself: the lower of the two protocol versions and
+// the intersection of the capabilities and encodings. PNG is the baseline
+// frame encoding and is always included.
+func (h ClientHello) Negotiate(capabilities Capability, encodings Encoding) ServerHello {
+	version := ProtocolVersion
+	if h.Version < version {
+		version = h.Version
+	}
+	return ServerHello{
+		Version:      version,
+		Capabilities: h.Capabilities & capabilities,
+		Encodings:    (h.Encodings | EncodingPNG) & (encodings | EncodingPNG),
+	}
+}
+
+// frameHeader is the region header shared by all frame encodings.
+type frameHeader struct {
+	Left, Top     uint32
+	Right, Bottom uint32
+}
+
+func encodeFrame(buf *bytes.Buffer, messageType MessageType, header frameHeader, data []byte) ([]byte, error) {
+	buf.WriteByte(byte(messageType))
+	if err := binary.Write(buf, binary.BigEndian, header); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if err := encodeBytes(buf, data); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeFrame(in peekReader, messageType MessageType) (frameHeader, []byte, error) {
+	t, err := in.ReadByte()
+	if err != nil {
+		return frameHeader{}, nil, trace.Wrap(err)
+	}
+	if t != byte(messageType) {
+		return frameHeader{}, nil, trace.BadParameter("got message type %v, expected %v", t, messageType)
+	}
+	var header frameHeader
+	if err := binary.Read(in, binary.BigEndian, &header); err != nil {
+		return frameHeader{}, nil, trace.Wrap(err)
+	}
+	data, err := decodeBytes(in)
+	if err != nil {
+		return frameHeader{}, nil, trace.Wrap(err)
+	}
+	return header, data, nil
+}
+
+// RLEFrame is a losslessly compressed frame message. It carries the same
+// region header as a PNG frame with the raw RGBA pixel data compressed by
+// the run-length scheme implemented in rle.go, which is much cheaper to
+// produce than a PNG.
+type RLEFrame struct {
+	Left, Top     uint32
+	Right, Bottom uint32
+
+	// Data is the run-length encoded RGBA pixel data of the region.
+	Data []byte
+}
+
+// NewRLE run-length encodes the raw RGBA pixels of img into an RLEFrame.
+func NewRLE(img *image.NRGBA) RLEFrame {
+	return RLEFrame{
+		Left:   uint32(img.Rect.Min.X),
+		Top:    uint32(img.Rect.Min.Y),
+		Right:  uint32(img.Rect.Max.X),
+		Bottom: uint32(img.Rect.Max.Y),
+		Data:   runLengthEncode(img.Pix),
+	}
+}
+
+// ToImage decompresses the frame back into an image.
+func (f RLEFrame) ToImage() (*image.NRGBA, error) {
+	pix, err := runLengthDecode(f.Data)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	img := image.NewNRGBA(image.Rect(int(f.Left), int(f.Top), int(f.Right), int(f.Bottom)))
+	if len(pix) != len(img.Pix) {
+		return nil, trace.BadParameter("got %v bytes of pixel data, expected %v for a %vx%v frame",
+			len(pix), len(img.Pix), f.Right-f.Left, f.Bottom-f.Top)
+	}
+	copy(img.Pix, pix)
+	return img, nil
+}
+
+func (f RLEFrame) Encode() ([]byte, error) {
+	return encodeFrame(new(bytes.Buffer), TypeRLEFrame, frameHeader{
+		Left:   f.Left,
+		Top:    f.Top,
+		Right:  f.Right,
+		Bottom: f.Bottom,
+	}, f.Data)
+}
+
+func decodeRLEFrame(in peekReader) (RLEFrame, error) {
+	header, data, err := decodeFrame(in, TypeRLEFrame)
+	if err != nil {
+		return RLEFrame{}, trace.Wrap(err)
+	}
+	return RLEFrame{
+		Left:   header.Left,
+		Top:    header.Top,
+		Right:  header.Right,
+		Bottom: header.Bottom,
+		Data:   data,
+	}, nil
+}
+
+// WebPFrame is a lossily compressed frame message carrying a WebP
+// bitstream with the same region header as a PNG frame. There is no WebP
+// encoder in the Go standard library, so the service does not emit WebP
+// frames yet; the message is defined so the protocol and clients do not
+// have to change again when an encoder is added.
+type WebPFrame struct {
+	Left, Top     uint32
+	Right, Bottom uint32
+
+	// Data is the WebP bitstream of the region.
+	Data []byte
+}
+
+func (f WebPFrame) Encode() ([]byte, error) {
+	return encodeFrame(new(bytes.Buffer), TypeWebPFrame, frameHeader{
+		Left:   f.Left,
+		Top:    f.Top,
+		Right:  f.Right,
+		Bottom: f.Bottom,
+	}, f.Data)
+}
+
+func decodeWebPFrame(in peekReader) (WebPFrame, error) {
+	header, data, err := decodeFrame(in, TypeWebPFrame)
+	if err != nil {
+		return WebPFrame{}, trace.Wrap(err)
+	}
+	return WebPFrame{
+		Left:   header.Left,
+		Top:    header.Top,
+		Right:  header.Right,
+		Bottom: header.Bottom,
+		Data:   data,
+	}, nil
+}
+
+// Ping is a latency measurement probe. Either side of the connection may
+// send one at any time and the peer echoes it back as a Pong with the same
+// ID, letting the sender measure the round-trip latency.
+type Ping struct {
+	ID uint32
+}
+
+func (p Ping) Encode() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteByte(byte(TypePing))
+	if err := binary.Write(buf, binary.BigEndian, p); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return buf.Bytes(), nil
+}
+
+func decodePing(in peekReader) (Ping, error) {
+	t, err := in.ReadByte()
+	if err != nil {
+		return Ping{}, trace.Wrap(err)
+	}
+	if t != byte(TypePing) {
+		return Ping{}, trace.BadParameter("got message type %v, expected TypePing(%v)", t, TypePing)
+	}
+	var p Ping
+	err = binary.Read(in, binary.BigEndian, &p)
+	return p, trace.Wrap(err)
+}
+
+// Pong is the response to a Ping, carrying the ID of the Ping it answers.
+type Pong struct {
+	ID uint32
+}
+
+func (p Pong) Encode() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteByte(byte(TypePong))
+	if err := binary.Write(buf, binary.BigEndian, p); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return buf.Bytes(), nil
+}
+
+func decodePong(in peekReader) (Pong, error) {
+	t, err := in.ReadByte()
+	if err != nil {
+		return Pong{}, trace.Wrap(err)
+	}
+	if t != byte(TypePong) {
+		return Pong{}, trace.BadParameter("got message type %v, expected TypePong(%v)", t, TypePong)
+	}
+	var p Pong
+	err = binary.Read(in, binary.BigEndian, &p)
+	return p, trace.Wrap(err)
+}
+
+// ClientKeyboardLayout announces the keyboard layout of the client, as a
+// Windows keyboard layout identifier (KLID, for example 0x409 for US
+// English). It is sent before the ClientScreenSpec so the layout can be
+// used when establishing the RDP connection; the layout of clients that
+// never send it is assumed to be US English.
+type ClientKeyboardLayout struct {
+	KeyboardLayout uint32
+}
+
+func (k ClientKeyboardLayout) Encode() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteByte(byte(TypeClientKeyboardLayout))
+	if err := binary.Write(buf, binary.BigEndian, k); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeClientKeyboardLayout(in peekReader) (ClientKeyboardLayout, error) {
+	t, err := in.ReadByte()
+	if err != nil {
+		return ClientKeyboardLayout{}, trace.Wrap(err)
+	}
+	if t != byte(TypeClientKeyboardLayout) {
+		return ClientKeyboardLayout{}, trace.BadParameter("got message type %v, expected TypeClientKeyboardLayout(%v)", t, TypeClientKeyboardLayout)
+	}
+	var k ClientKeyboardLayout
+	err = binary.Read(in, binary.BigEndian, &k)
+	return k, trace.Wrap(err)
+}
+
 // ClientUsername is the client username.
 // https://github.com/gravitational/teleport/blob/master/rfd/0037-desktop-access-protocol.md#7---client-username
 type ClientUsername struct {
@@ -357,6 +768,502 @@ func decodeError(in peekReader) (Error, error) {
 	return Error{Message: message}, nil
 }
 
+// ClipboardData is the clipboard data message carrying UTF-8 text copied
+// on either end of the connection.
+// https://github.com/gravitational/teleport/blob/master/rfd/0037-desktop-access-protocol.md#6---clipboard-data
+type ClipboardData struct {
+	Data []byte
+}
+
+func (c ClipboardData) Encode() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteByte(byte(TypeClipboardData))
+	if err := encodeBytes(buf, c.Data); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeClipboardData(in peekReader) (ClipboardData, error) {
+	t, err := in.ReadByte()
+	if err != nil {
+		return ClipboardData{}, trace.Wrap(err)
+	}
+	if t != byte(TypeClipboardData) {
+		return ClipboardData{}, trace.BadParameter("got message type %v, expected TypeClipboardData(%v)", t, TypeClipboardData)
+	}
+	data, err := decodeBytes(in)
+	if err != nil {
+		return ClipboardData{}, trace.Wrap(err)
+	}
+	return ClipboardData{Data: data}, nil
+}
+
+// ClipboardImage is the clipboard image message carrying a PNG-encoded
+// image copied on either end of the connection. The image is kept in its
+// encoded form so relaying it does not require a decode/encode round trip.
+type ClipboardImage struct {
+	Data []byte
+}
+
+func (c ClipboardImage) Encode() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteByte(byte(TypeClipboardImage))
+	if err := encodeBytes(buf, c.Data); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeClipboardImage(in peekReader) (ClipboardImage, error) {
+	t, err := in.ReadByte()
+	if err != nil {
+		return ClipboardImage{}, trace.Wrap(err)
+	}
+	if t != byte(TypeClipboardImage) {
+		return ClipboardImage{}, trace.BadParameter("got message type %v, expected TypeClipboardImage(%v)", t, TypeClipboardImage)
+	}
+	data, err := decodeBytes(in)
+	if err != nil {
+		return ClipboardImage{}, trace.Wrap(err)
+	}
+	return ClipboardImage{Data: data}, nil
+}
+
+// SharedDirectoryErrCode is the result code of a shared directory
+// operation.
+type SharedDirectoryErrCode uint32
+
+const (
+	SharedDirectoryErrNil          = SharedDirectoryErrCode(0)
+	SharedDirectoryErrFailed       = SharedDirectoryErrCode(1)
+	SharedDirectoryErrDoesNotExist = SharedDirectoryErrCode(2)
+)
+
+// FileType distinguishes files from directories in shared directory
+// listings.
+type FileType uint32
+
+const (
+	FileTypeFile = FileType(0)
+	FileTypeDir  = FileType(1)
+)
+
+// SharedDirectoryAnnounce is sent by the client to announce a local
+// directory shared with the remote desktop.
+type SharedDirectoryAnnounce struct {
+	DirectoryID uint32
+	Name        string
+}
+
+func (s SharedDirectoryAnnounce) Encode() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteByte(byte(TypeSharedDirectoryAnnounce))
+	if err := binary.Write(buf, binary.BigEndian, s.DirectoryID); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if err := encodeString(buf, s.Name); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeSharedDirectoryAnnounce(in peekReader) (SharedDirectoryAnnounce, error) {
+	t, err := in.ReadByte()
+	if err != nil {
+		return SharedDirectoryAnnounce{}, trace.Wrap(err)
+	}
+	if t != byte(TypeSharedDirectoryAnnounce) {
+		return SharedDirectoryAnnounce{}, trace.BadParameter("got message type %v, expected TypeSharedDirectoryAnnounce(%v)", t, TypeSharedDirectoryAnnounce)
+	}
+	var s SharedDirectoryAnnounce
+	if err := binary.Read(in, binary.BigEndian, &s.DirectoryID); err != nil {
+		return SharedDirectoryAnnounce{}, trace.Wrap(err)
+	}
+	s.Name, err = decodeString(in)
+	if err != nil {
+		return SharedDirectoryAnnounce{}, trace.Wrap(err)
+	}
+	return s, nil
+}
+
+// SharedDirectoryReadRequest is sent by the server to read a chunk of a
+// file in a shared directory.
+type SharedDirectoryReadRequest struct {
+	CompletionID uint32
+	DirectoryID  uint32
+	Path         string
+	Offset       uint64
+	Length       uint32
+}
+
+func (s SharedDirectoryReadRequest) Encode() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteByte(byte(TypeSharedDirectoryReadRequest))
+	if err := binary.Write(buf, binary.BigEndian, s.CompletionID); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if err := binary.Write(buf, binary.BigEndian, s.DirectoryID); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if err := encodeString(buf, s.Path); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if err := binary.Write(buf, binary.BigEndian, s.Offset); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if err := binary.Write(buf, binary.BigEndian, s.Length); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeSharedDirectoryReadRequest(in peekReader) (SharedDirectoryReadRequest, error) {
+	t, err := in.ReadByte()
+	if err != nil {
+		return SharedDirectoryReadRequest{}, trace.Wrap(err)
+	}
+	if t != byte(TypeSharedDirectoryReadRequest) {
+		return SharedDirectoryReadRequest{}, trace.BadParameter("got message type %v, expected TypeSharedDirectoryReadRequest(%v)", t, TypeSharedDirectoryReadRequest)
+	}
+	var s SharedDirectoryReadRequest
+	if err := binary.Read(in, binary.BigEndian, &s.CompletionID); err != nil {
+		return SharedDirectoryReadRequest{}, trace.Wrap(err)
+	}
+	if err := binary.Read(in, binary.BigEndian, &s.DirectoryID); err != nil {
+		return SharedDirectoryReadRequest{}, trace.Wrap(err)
+	}
+	s.Path, err = decodeString(in)
+	if err != nil {
+		return SharedDirectoryReadRequest{}, trace.Wrap(err)
+	}
+	if err := binary.Read(in, binary.BigEndian, &s.Offset); err != nil {
+		return SharedDirectoryReadRequest{}, trace.Wrap(err)
+	}
+	if err := binary.Read(in, binary.BigEndian, &s.Length); err != nil {
+		return SharedDirectoryReadRequest{}, trace.Wrap(err)
+	}
+	return s, nil
+}
+
+// SharedDirectoryReadResponse is the client's response to a read request.
+// The path of the read file is echoed back so it can be audited without
+// tracking outstanding completion IDs.
+type SharedDirectoryReadResponse struct {
+	CompletionID uint32
+	ErrCode      SharedDirectoryErrCode
+	Path         string
+	Offset       uint64
+	Data         []byte
+}
+
+func (s SharedDirectoryReadResponse) Encode() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteByte(byte(TypeSharedDirectoryReadResponse))
+	if err := binary.Write(buf, binary.BigEndian, s.CompletionID); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if err := binary.Write(buf, binary.BigEndian, s.ErrCode); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if err := encodeString(buf, s.Path); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if err := binary.Write(buf, binary.BigEndian, s.Offset); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if err := encodeBytes(buf, s.Data); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeSharedDirectoryReadResponse(in peekReader) (SharedDirectoryReadResponse, error) {
+	t, err := in.ReadByte()
+	if err != nil {
+		return SharedDirectoryReadResponse{}, trace.Wrap(err)
+	}
+	if t != byte(TypeSharedDirectoryReadResponse) {
+		return SharedDirectoryReadResponse{}, trace.BadParameter("got message type %v, expected TypeSharedDirectoryReadResponse(%v)", t, TypeSharedDirectoryReadResponse)
+	}
+	var s SharedDirectoryReadResponse
+	if err := binary.Read(in, binary.BigEndian, &s.CompletionID); err != nil {
+		return SharedDirectoryReadResponse{}, trace.Wrap(err)
+	}
+	if err := binary.Read(in, binary.BigEndian, &s.ErrCode); err != nil {
+		return SharedDirectoryReadResponse{}, trace.Wrap(err)
+	}
+	s.Path, err = decodeString(in)
+	if err != nil {
+		return SharedDirectoryReadResponse{}, trace.Wrap(err)
+	}
+	if err := binary.Read(in, binary.BigEndian, &s.Offset); err != nil {
+		return SharedDirectoryReadResponse{}, trace.Wrap(err)
+	}
+	s.Data, err = decodeBytes(in)
+	if err != nil {
+		return SharedDirectoryReadResponse{}, trace.Wrap(err)
+	}
+	return s, nil
+}
+
+// SharedDirectoryWriteRequest is sent by the server to write a chunk of a
+// file in a shared directory.
+type SharedDirectoryWriteRequest struct {
+	CompletionID uint32
+	DirectoryID  uint32
+	Path         string
+	Offset       uint64
+	Data         []byte
+}
+
+func (s SharedDirectoryWriteRequest) Encode() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteByte(byte(TypeSharedDirectoryWriteRequest))
+	if err := binary.Write(buf, binary.BigEndian, s.CompletionID); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if err := binary.Write(buf, binary.BigEndian, s.DirectoryID); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if err := encodeString(buf, s.Path); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if err := binary.Write(buf, binary.BigEndian, s.Offset); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if err := encodeBytes(buf, s.Data); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeSharedDirectoryWriteRequest(in peekReader) (SharedDirectoryWriteRequest, error) {
+	t, err := in.ReadByte()
+	if err != nil {
+		return SharedDirectoryWriteRequest{}, trace.Wrap(err)
+	}
+	if t != byte(TypeSharedDirectoryWriteRequest) {
+		return SharedDirectoryWriteRequest{}, trace.BadParameter("got message type %v, expected TypeSharedDirectoryWriteRequest(%v)", t, TypeSharedDirectoryWriteRequest)
+	}
+	var s SharedDirectoryWriteRequest
+	if err := binary.Read(in, binary.BigEndian, &s.CompletionID); err != nil {
+		return SharedDirectoryWriteRequest{}, trace.Wrap(err)
+	}
+	if err := binary.Read(in, binary.BigEndian, &s.DirectoryID); err != nil {
+		return SharedDirectoryWriteRequest{}, trace.Wrap(err)
+	}
+	s.Path, err = decodeString(in)
+	if err != nil {
+		return SharedDirectoryWriteRequest{}, trace.Wrap(err)
+	}
+	if err := binary.Read(in, binary.BigEndian, &s.Offset); err != nil {
+		return SharedDirectoryWriteRequest{}, trace.Wrap(err)
+	}
+	s.Data, err = decodeBytes(in)
+	if err != nil {
+		return SharedDirectoryWriteRequest{}, trace.Wrap(err)
+	}
+	return s, nil
+}
+
+// SharedDirectoryWriteResponse is the client's response to a write request.
+// Like the read response it echoes the path back for auditing.
+type SharedDirectoryWriteResponse struct {
+	CompletionID uint32
+	ErrCode      SharedDirectoryErrCode
+	Path         string
+	BytesWritten uint32
+}
+
+func (s SharedDirectoryWriteResponse) Encode() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteByte(byte(TypeSharedDirectoryWriteResponse))
+	if err := binary.Write(buf, binary.BigEndian, s.CompletionID); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if err := binary.Write(buf, binary.BigEndian, s.ErrCode); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if err := encodeString(buf, s.Path); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if err := binary.Write(buf, binary.BigEndian, s.BytesWritten); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeSharedDirectoryWriteResponse(in peekReader) (SharedDirectoryWriteResponse, error) {
+	t, err := in.ReadByte()
+	if err != nil {
+		return SharedDirectoryWriteResponse{}, trace.Wrap(err)
+	}
+	if t != byte(TypeSharedDirectoryWriteResponse) {
+		return SharedDirectoryWriteResponse{}, trace.BadParameter("got message type %v, expected TypeSharedDirectoryWriteResponse(%v)", t, TypeSharedDirectoryWriteResponse)
+	}
+	var s SharedDirectoryWriteResponse
+	if err := binary.Read(in, binary.BigEndian, &s.CompletionID); err != nil {
+		return SharedDirectoryWriteResponse{}, trace.Wrap(err)
+	}
+	if err := binary.Read(in, binary.BigEndian, &s.ErrCode); err != nil {
+		return SharedDirectoryWriteResponse{}, trace.Wrap(err)
+	}
+	s.Path, err = decodeString(in)
+	if err != nil {
+		return SharedDirectoryWriteResponse{}, trace.Wrap(err)
+	}
+	if err := binary.Read(in, binary.BigEndian, &s.BytesWritten); err != nil {
+		return SharedDirectoryWriteResponse{}, trace.Wrap(err)
+	}
+	return s, nil
+}
+
+// SharedDirectoryListRequest is sent by the server to list the contents of
+// a directory in a shared directory.
+type SharedDirectoryListRequest struct {
+	CompletionID uint32
+	DirectoryID  uint32
+	Path         string
+}
+
+func (s SharedDirectoryListRequest) Encode() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteByte(byte(TypeSharedDirectoryListRequest))
+	if err := binary.Write(buf, binary.BigEndian, s.CompletionID); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if err := binary.Write(buf, binary.BigEndian, s.DirectoryID); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if err := encodeString(buf, s.Path); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeSharedDirectoryListRequest(in peekReader) (SharedDirectoryListRequest, error) {
+	t, err := in.ReadByte()
+	if err != nil {
+		return SharedDirectoryListRequest{}, trace.Wrap(err)
+	}
+	if t != byte(TypeSharedDirectoryListRequest) {
+		return SharedDirectoryListRequest{}, trace.BadParameter("got message type %v, expected TypeSharedDirectoryListRequest(%v)", t, TypeSharedDirectoryListRequest)
+	}
+	var s SharedDirectoryListRequest
+	if err := binary.Read(in, binary.BigEndian, &s.CompletionID); err != nil {
+		return SharedDirectoryListRequest{}, trace.Wrap(err)
+	}
+	if err := binary.Read(in, binary.BigEndian, &s.DirectoryID); err != nil {
+		return SharedDirectoryListRequest{}, trace.Wrap(err)
+	}
+	s.Path, err = decodeString(in)
+	if err != nil {
+		return SharedDirectoryListRequest{}, trace.Wrap(err)
+	}
+	return s, nil
+}
+
+// FileSystemObject describes a single file or directory in a shared
+// directory listing.
+type FileSystemObject struct {
+	LastModified uint64
+	Size         uint64
+	FileType     FileType
+	Path         string
+}
+
+func encodeFileSystemObject(w io.Writer, fso FileSystemObject) error {
+	if err := binary.Write(w, binary.BigEndian, fso.LastModified); err != nil {
+		return trace.Wrap(err)
+	}
+	if err := binary.Write(w, binary.BigEndian, fso.Size); err != nil {
+		return trace.Wrap(err)
+	}
+	if err := binary.Write(w, binary.BigEndian, fso.FileType); err != nil {
+		return trace.Wrap(err)
+	}
+	if err := encodeString(w, fso.Path); err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+func decodeFileSystemObject(r io.Reader) (FileSystemObject, error) {
+	var fso FileSystemObject
+	if err := binary.Read(r, binary.BigEndian, &fso.LastModified); err != nil {
+		return FileSystemObject{}, trace.Wrap(err)
+	}
+	if err := binary.Read(r, binary.BigEndian, &fso.Size); err != nil {
+		return FileSystemObject{}, trace.Wrap(err)
+	}
+	if err := binary.Read(r, binary.BigEndian, &fso.FileType); err != nil {
+		return FileSystemObject{}, trace.Wrap(err)
+	}
+	path, err := decodeString(r)
+	if err != nil {
+		return FileSystemObject{}, trace.Wrap(err)
+	}
+	fso.Path = path
+	return fso, nil
+}
+
+// SharedDirectoryListResponse is the client's response to a list request.
+type SharedDirectoryListResponse struct {
+	CompletionID uint32
+	ErrCode      SharedDirectoryErrCode
+	FsoList      []FileSystemObject
+}
+
+func (s SharedDirectoryListResponse) Encode() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteByte(byte(TypeSharedDirectoryListResponse))
+	if err := binary.Write(buf, binary.BigEndian, s.CompletionID); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if err := binary.Write(buf, binary.BigEndian, s.ErrCode); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if err := binary.Write(buf, binary.BigEndian, uint32(len(s.FsoList))); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, fso := range s.FsoList {
+		if err := encodeFileSystemObject(buf, fso); err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeSharedDirectoryListResponse(in peekReader) (SharedDirectoryListResponse, error) {
+	t, err := in.ReadByte()
+	if err != nil {
+		return SharedDirectoryListResponse{}, trace.Wrap(err)
+	}
+	if t != byte(TypeSharedDirectoryListResponse) {
+		return SharedDirectoryListResponse{}, trace.BadParameter("got message type %v, expected TypeSharedDirectoryListResponse(%v)", t, TypeSharedDirectoryListResponse)
+	}
+	var s SharedDirectoryListResponse
+	if err := binary.Read(in, binary.BigEndian, &s.CompletionID); err != nil {
+		return SharedDirectoryListResponse{}, trace.Wrap(err)
+	}
+	if err := binary.Read(in, binary.BigEndian, &s.ErrCode); err != nil {
+		return SharedDirectoryListResponse{}, trace.Wrap(err)
+	}
+	var count uint32
+	if err := binary.Read(in, binary.BigEndian, &count); err != nil {
+		return SharedDirectoryListResponse{}, trace.Wrap(err)
+	}
+	for i := uint32(0); i < count; i++ {
+		fso, err := decodeFileSystemObject(in)
+		if err != nil {
+			return SharedDirectoryListResponse{}, trace.Wrap(err)
+		}
+		s.FsoList = append(s.FsoList, fso)
+	}
+	return s, nil
+}
+
 // MouseWheelAxis identifies a scroll axis on the mouse wheel.
 type MouseWheelAxis byte
 
@@ -418,3 +1325,27 @@ func decodeString(r io.Reader) (string, error) {
 	}
 	return string(s), nil
 }
+
+// encodeBytes encodes a raw byte blob with a 32-bit length prefix (in
+// bytes), the same framing used for strings.
+func encodeBytes(w io.Writer, b []byte) error {
+	if err := binary.Write(w, binary.BigEndian, uint32(len(b))); err != nil {
+		return trace.Wrap(err)
+	}
+	if _, err := w.Write(b); err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+func decodeBytes(r io.Reader) ([]byte, error) {
+	var length uint32
+	if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	b := make([]byte, int(length))
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return b, nil
+}
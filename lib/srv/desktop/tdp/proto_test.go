@@ -45,8 +45,28 @@ func TestEncodeDecode(t *testing.T) {
 			return PNGFrame{Img: img}
 		}(),
 		ClientScreenSpec{Width: 123, Height: 456},
+		ClientCapabilities{Encodings: EncodingPNG | EncodingRLE},
+		RLEFrame{Left: 1, Top: 2, Right: 3, Bottom: 4, Data: []byte{1, 10, 20, 30, 40}},
+		WebPFrame{Left: 5, Top: 6, Right: 7, Bottom: 8, Data: []byte("RIFF")},
+		ClientKeyboardLayout{KeyboardLayout: 0x409},
+		Ping{ID: 42},
+		Pong{ID: 42},
 		ClientUsername{Username: "admin"},
+		ClipboardData{Data: []byte("copied text")},
+		ClipboardImage{Data: []byte{0x89, 'P', 'N', 'G'}},
+		SharedDirectoryAnnounce{DirectoryID: 1, Name: "home"},
+		SharedDirectoryReadRequest{CompletionID: 2, DirectoryID: 1, Path: "docs/report.txt", Offset: 1024, Length: 4096},
+		SharedDirectoryReadResponse{CompletionID: 2, ErrCode: SharedDirectoryErrNil, Path: "docs/report.txt", Offset: 1024, Data: []byte("file contents")},
+		SharedDirectoryWriteRequest{CompletionID: 3, DirectoryID: 1, Path: "upload.bin", Offset: 0, Data: []byte{1, 2, 3}},
+		SharedDirectoryWriteResponse{CompletionID: 3, ErrCode: SharedDirectoryErrFailed, Path: "upload.bin", BytesWritten: 0},
+		SharedDirectoryListRequest{CompletionID: 4, DirectoryID: 1, Path: "docs"},
+		SharedDirectoryListResponse{CompletionID: 4, ErrCode: SharedDirectoryErrNil, FsoList: []FileSystemObject{
+			{LastModified: 123, Size: 456, FileType: FileTypeFile, Path: "docs/report.txt"},
+			{LastModified: 789, Size: 0, FileType: FileTypeDir, Path: "docs/archive"},
+		}},
 		MouseWheel{Axis: HorizontalWheelAxis, Delta: -123},
+		ClientHello{Version: ProtocolVersion, Capabilities: CapabilityClipboard | CapabilityPing, Encodings: EncodingPNG | EncodingRLE},
+		ServerHello{Version: ProtocolVersion, Capabilities: CapabilityClipboard, Encodings: EncodingPNG},
 		Error{Message: "An error occurred"},
 	} {
 
@@ -60,6 +80,27 @@ func TestEncodeDecode(t *testing.T) {
 	}
 }
 
+func TestNegotiate(t *testing.T) {
+	hello := ClientHello{
+		// Client from the future with features this release doesn't know.
+		Version:      ProtocolVersion + 1,
+		Capabilities: CapabilityClipboard | Capability(1<<30) | Capability(1<<31),
+		Encodings:    EncodingRLE,
+	}
+	negotiated := hello.Negotiate(CapabilityClipboard|CapabilityPing, EncodingPNG|EncodingRLE|EncodingWebP)
+	require.Equal(t, ServerHello{
+		Version:      ProtocolVersion,
+		Capabilities: CapabilityClipboard,
+		Encodings:    EncodingPNG | EncodingRLE,
+	}, negotiated)
+
+	// Legacy client that never sent a hello.
+	require.Equal(t, ServerHello{
+		Version:   0,
+		Encodings: EncodingPNG,
+	}, ClientHello{}.Negotiate(CapabilityClipboard, EncodingPNG|EncodingRLE))
+}
+
 func TestBadDecode(t *testing.T) {
 	// 254 is an unknown message type.
 	_, err := Decode([]byte{254})
@@ -0,0 +1,77 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tdp
+
+import (
+	"bytes"
+
+	"github.com/gravitational/trace"
+)
+
+// Run-length codec for RLEFrame payloads.
+//
+// The input is raw RGBA pixel data (4 bytes per pixel). The output is a
+// sequence of runs, each a count byte (1-255) followed by the 4-byte pixel
+// repeated that many times. Desktop frames are dominated by long runs of
+// identical pixels, so this compresses well while being far cheaper to
+// produce than a PNG.
+
+// pixelSize is the size of one RGBA pixel in bytes.
+const pixelSize = 4
+
+// runLengthEncode compresses raw RGBA pixel data. It panics if the input
+// is not a whole number of pixels, which can only happen through a
+// programming error.
+func runLengthEncode(pix []byte) []byte {
+	if len(pix)%pixelSize != 0 {
+		panic("tdp: pixel data is not a whole number of RGBA pixels")
+	}
+	// Worst case (no two adjacent pixels equal) adds a count byte per pixel.
+	out := make([]byte, 0, len(pix)+len(pix)/pixelSize)
+	for i := 0; i < len(pix); {
+		run := pix[i : i+pixelSize]
+		n := 1
+		for n < 255 && i+n*pixelSize < len(pix) && bytes.Equal(run, pix[i+n*pixelSize:i+(n+1)*pixelSize]) {
+			n++
+		}
+		out = append(out, byte(n))
+		out = append(out, run...)
+		i += n * pixelSize
+	}
+	return out
+}
+
+// runLengthDecode expands run-length encoded data back into raw RGBA
+// pixel data.
+func runLengthDecode(data []byte) ([]byte, error) {
+	out := make([]byte, 0, len(data))
+	for i := 0; i < len(data); {
+		if i+1+pixelSize > len(data) {
+			return nil, trace.BadParameter("truncated run-length encoded data")
+		}
+		n := int(data[i])
+		if n == 0 {
+			return nil, trace.BadParameter("zero-length run in run-length encoded data")
+		}
+		run := data[i+1 : i+1+pixelSize]
+		for j := 0; j < n; j++ {
+			out = append(out, run...)
+		}
+		i += 1 + pixelSize
+	}
+	return out, nil
+}
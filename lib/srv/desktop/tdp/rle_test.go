@@ -0,0 +1,90 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tdp
+
+import (
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunLengthRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		desc string
+		pix  []byte
+	}{
+		{desc: "empty", pix: []byte{}},
+		{desc: "single pixel", pix: []byte{1, 2, 3, 4}},
+		{desc: "uniform", pix: repeatPixel([]byte{10, 20, 30, 255}, 1000)},
+		{desc: "no runs", pix: randomPixels(1000)},
+		{desc: "run longer than 255", pix: repeatPixel([]byte{0, 0, 0, 255}, 300)},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			encoded := runLengthEncode(tc.pix)
+			decoded, err := runLengthDecode(encoded)
+			require.NoError(t, err)
+			require.Equal(t, tc.pix, decoded)
+		})
+	}
+}
+
+func TestRunLengthDecodeErrors(t *testing.T) {
+	// Truncated run.
+	_, err := runLengthDecode([]byte{2, 1, 2, 3})
+	require.Error(t, err)
+
+	// Zero-length run.
+	_, err = runLengthDecode([]byte{0, 1, 2, 3, 4})
+	require.Error(t, err)
+}
+
+func TestRLEFrameToImage(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(5, 5, 69, 69))
+	for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+		for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+			img.Set(x, y, color.NRGBA{byte(x), byte(y), 3, 255})
+		}
+	}
+
+	out, err := NewRLE(img).ToImage()
+	require.NoError(t, err)
+	require.Equal(t, img, out)
+
+	// A frame whose pixel data doesn't match its bounds is rejected.
+	frame := NewRLE(img)
+	frame.Right++
+	_, err = frame.ToImage()
+	require.Error(t, err)
+}
+
+func repeatPixel(pixel []byte, n int) []byte {
+	out := make([]byte, 0, len(pixel)*n)
+	for i := 0; i < n; i++ {
+		out = append(out, pixel...)
+	}
+	return out
+}
+
+func randomPixels(n int) []byte {
+	r := rand.New(rand.NewSource(42))
+	out := make([]byte, n*pixelSize)
+	r.Read(out)
+	return out
+}
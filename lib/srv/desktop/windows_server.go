@@ -27,7 +27,9 @@ import (
 	"encoding/asn1"
 	"encoding/pem"
 	"fmt"
+	"io"
 	"net"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -44,7 +46,10 @@ import (
 	"github.com/gravitational/teleport/api/types/events"
 	"github.com/gravitational/teleport/lib/auth"
 	"github.com/gravitational/teleport/lib/defaults"
+	libevents "github.com/gravitational/teleport/lib/events"
+	"github.com/gravitational/teleport/lib/events/filesessions"
 	"github.com/gravitational/teleport/lib/limiter"
+	"github.com/gravitational/teleport/lib/multiplexer"
 	"github.com/gravitational/teleport/lib/services"
 	"github.com/gravitational/teleport/lib/session"
 	"github.com/gravitational/teleport/lib/srv"
@@ -112,6 +117,13 @@ type WindowsServiceConfig struct {
 	AccessPoint auth.WindowsDesktopAccessPoint
 	// AuthClient is the Auth API client (without caching).
 	AuthClient auth.ClientI
+	// DataDir is the base directory used to buffer session recordings
+	// on disk before they are uploaded.
+	DataDir string
+	// ScreenshotInterval is how often to capture a still PNG of each
+	// desktop session and attach it to the session recording. Zero
+	// disables periodic screenshots.
+	ScreenshotInterval time.Duration
 	// ConnLimiter limits the number of active connections per client IP.
 	ConnLimiter *limiter.ConnectionsLimiter
 	// Heartbeat contains configuration for service heartbeats.
@@ -188,6 +200,10 @@ type HeartbeatConfig struct {
 	OnHeartbeat func(error)
 	// StaticHosts is an optional list of static Windows hosts to register.
 	StaticHosts []utils.NetAddr
+	// NonADHosts is an optional list of standalone Windows hosts without
+	// Active Directory to register. Logins on these hosts use local
+	// accounts with locally provisioned certificates.
+	NonADHosts []utils.NetAddr
 }
 
 func (cfg *WindowsServiceConfig) checkAndSetDiscoveryDefaults() error {
@@ -234,14 +250,32 @@ func (cfg *WindowsServiceConfig) CheckAndSetDefaults() error {
 	if cfg.AuthClient == nil {
 		return trace.BadParameter("WindowsServiceConfig is missing AuthClient")
 	}
+	if cfg.DataDir == "" {
+		return trace.BadParameter("WindowsServiceConfig is missing DataDir")
+	}
 	if cfg.ConnLimiter == nil {
 		return trace.BadParameter("WindowsServiceConfig is missing ConnLimiter")
 	}
 	if err := cfg.Heartbeat.CheckAndSetDefaults(); err != nil {
 		return trace.Wrap(err)
 	}
-	if err := cfg.LDAPConfig.check(); err != nil {
-		return trace.Wrap(err)
+	// LDAP is only required for Active Directory hosts. Standalone hosts
+	// authenticate local accounts with locally provisioned certificates
+	// and need no domain controller.
+	if cfg.LDAPConfig.Addr != "" {
+		if err := cfg.LDAPConfig.check(); err != nil {
+			return trace.Wrap(err)
+		}
+	} else {
+		if len(cfg.Heartbeat.StaticHosts) > 0 {
+			return trace.BadParameter("WindowsServiceConfig specifies static hosts but no LDAP configuration; list hosts without Active Directory under non_ad_hosts instead")
+		}
+		if len(cfg.DiscoveryBaseDN) > 0 {
+			return trace.BadParameter("WindowsServiceConfig enables desktop discovery but has no LDAP configuration")
+		}
+		if len(cfg.Heartbeat.NonADHosts) == 0 {
+			return trace.BadParameter("WindowsServiceConfig has neither an LDAP configuration nor non_ad_hosts")
+		}
 	}
 	if err := cfg.checkAndSetDiscoveryDefaults(); err != nil {
 		return trace.Wrap(err)
@@ -277,19 +311,35 @@ func NewWindowsService(cfg WindowsServiceConfig) (*WindowsService, error) {
 		return nil, trace.Wrap(err, "fetching cluster name: %v", err)
 	}
 
-	lc, err := newLDAPClient(cfg.LDAPConfig)
-	if err != nil {
-		return nil, trace.Wrap(err, "connecting to LDAP server: %v", err)
-	}
+	// LDAP (and with it CA publishing and host discovery) is only used for
+	// Active Directory hosts; in standalone mode the service serves the
+	// non-AD hosts without a domain controller.
+	var lc *ldapClient
+	var dnsResolver *net.Resolver
+	if cfg.LDAPConfig.Addr != "" {
+		lc, err = newLDAPClient(cfg.LDAPConfig)
+		if err != nil {
+			return nil, trace.Wrap(err, "connecting to LDAP server: %v", err)
+		}
 
-	// Here we assume the LDAP server is an Active Directory Domain Controller,
-	// which means it should also be a DNS server that can resolve Windows hosts.
-	dnsServer, _, err := net.SplitHostPort(cfg.LDAPConfig.Addr)
-	if err != nil {
-		return nil, trace.Wrap(err)
+		// Here we assume the LDAP server is an Active Directory Domain Controller,
+		// which means it should also be a DNS server that can resolve Windows hosts.
+		dnsServer, _, err := net.SplitHostPort(cfg.LDAPConfig.Addr)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		dnsAddr := net.JoinHostPort(dnsServer, "53")
+		cfg.Log.Debugln("DNS lookups will be performed against", dnsAddr)
+		dnsResolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+				// Ignore the address provided, and always explicitly dial
+				// the domain controller.
+				d := net.Dialer{Timeout: dnsDialTimeout}
+				return d.DialContext(ctx, network, dnsAddr)
+			},
+		}
 	}
-	dnsAddr := net.JoinHostPort(dnsServer, "53")
-	cfg.Log.Debugln("DNS lookups will be performed against", dnsAddr)
 
 	ctx, close := context.WithCancel(context.Background())
 	s := &WindowsService{
@@ -298,15 +348,7 @@ func NewWindowsService(cfg WindowsServiceConfig) (*WindowsService, error) {
 			AccessPoint:   cfg.AccessPoint,
 			AcceptedUsage: []string{teleport.UsageWindowsDesktopOnly},
 		},
-		dnsResolver: &net.Resolver{
-			PreferGo: true,
-			Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
-				// Ignore the address provided, and always explicitly dial
-				// the domain controller.
-				d := net.Dialer{Timeout: dnsDialTimeout}
-				return d.DialContext(ctx, network, dnsAddr)
-			},
-		},
+		dnsResolver: dnsResolver,
 		lc:          lc,
 		clusterName: clusterName.GetClusterName(),
 		closeCtx:    ctx,
@@ -325,8 +367,10 @@ func NewWindowsService(cfg WindowsServiceConfig) (*WindowsService, error) {
 	// but I'm leaving this for later.
 	//
 	// TODO(zmb3): do these periodically
-	if err := s.updateCA(ctx); err != nil {
-		return nil, trace.Wrap(err)
+	if s.lc != nil {
+		if err := s.updateCA(ctx); err != nil {
+			return nil, trace.Wrap(err)
+		}
 	}
 
 	// TODO(zmb3): session recording.
@@ -387,13 +431,25 @@ func (s *WindowsService) startServiceHeartbeat() error {
 // should eventually get cleaned up. But they should exist as long as the
 // service itself is running.
 func (s *WindowsService) startStaticHostHeartbeats() error {
-	for _, host := range s.cfg.Heartbeat.StaticHosts {
+	type staticHost struct {
+		addr utils.NetAddr
+		// domain is empty for standalone hosts without Active Directory.
+		domain string
+	}
+	var hosts []staticHost
+	for _, addr := range s.cfg.Heartbeat.StaticHosts {
+		hosts = append(hosts, staticHost{addr: addr, domain: s.cfg.Domain})
+	}
+	for _, addr := range s.cfg.Heartbeat.NonADHosts {
+		hosts = append(hosts, staticHost{addr: addr})
+	}
+	for _, host := range hosts {
 		heartbeat, err := srv.NewHeartbeat(srv.HeartbeatConfig{
 			Context:         s.closeCtx,
 			Component:       teleport.ComponentWindowsDesktop,
 			Mode:            srv.HeartbeatModeWindowsDesktop,
 			Announcer:       s.cfg.AccessPoint,
-			GetServerInfo:   s.staticHostHeartbeatInfo(host, s.cfg.HostLabelsFn),
+			GetServerInfo:   s.staticHostHeartbeatInfo(host.addr, host.domain, s.cfg.HostLabelsFn),
 			KeepAlivePeriod: apidefaults.ServerKeepAliveTTL(),
 			AnnouncePeriod:  apidefaults.ServerAnnounceTTL/2 + utils.RandomDuration(apidefaults.ServerAnnounceTTL/10),
 			CheckPeriod:     defaults.HeartbeatCheckPeriod,
@@ -416,15 +472,16 @@ func (s *WindowsService) startStaticHostHeartbeats() error {
 // established ones. Close does not wait for the connections to be finished.
 func (s *WindowsService) Close() error {
 	s.close()
-	s.lc.close()
+	if s.lc != nil {
+		s.lc.close()
+	}
 	return nil
 }
 
 // Serve starts serving TLS connections for plainLis. plainLis should be a TCP
 // listener and Serve will handle TLS internally.
 func (s *WindowsService) Serve(plainLis net.Listener) error {
-	lis := tls.NewListener(plainLis, s.cfg.TLS)
-	defer lis.Close()
+	defer plainLis.Close()
 	for {
 		select {
 		case <-s.closeCtx.Done():
@@ -432,7 +489,7 @@ func (s *WindowsService) Serve(plainLis net.Listener) error {
 		default:
 		}
 
-		conn, err := lis.Accept()
+		conn, err := plainLis.Accept()
 		if err != nil {
 			if utils.IsOKNetworkError(err) || trace.IsConnectionProblem(err) {
 				return nil
@@ -440,13 +497,40 @@ func (s *WindowsService) Serve(plainLis net.Listener) error {
 			return trace.Wrap(err)
 
 		}
-		proxyConn, ok := conn.(*tls.Conn)
-		if !ok {
-			return trace.ConnectionProblem(nil, "Got %T from TLS listener, expected *tls.Conn", conn)
-		}
 
-		go s.handleConnection(proxyConn)
+		go func() {
+			// The proxy sends a PROXY protocol header with the real client
+			// address before the TLS handshake so it survives load balancer
+			// fronting; strip it off and record the address if present.
+			muxConn := multiplexer.NewConn(conn)
+			if err := s.maybeReadProxyLine(muxConn); err != nil {
+				s.cfg.Log.WithError(err).Warning("Failed to read proxy line, closing connection")
+				muxConn.Close()
+				return
+			}
+			s.handleConnection(tls.Server(muxConn, s.cfg.TLS))
+		}()
+	}
+}
+
+// maybeReadProxyLine peeks into the connection to see if it starts with a
+// PROXY protocol header carrying the real client address and consumes it
+// if so. The header is sent by the Teleport proxy and by load balancers
+// with proxy protocol enabled.
+func (s *WindowsService) maybeReadProxyLine(conn *multiplexer.Conn) error {
+	proto, err := conn.Detect()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if proto != multiplexer.ProtoProxy && proto != multiplexer.ProtoProxyV2 {
+		return nil
+	}
+	proxyLine, err := conn.ReadProxyLine()
+	if err != nil {
+		return trace.Wrap(err)
 	}
+	s.cfg.Log.Debugf("Desktop service listener proxy-line: %v.", proxyLine)
+	return nil
 }
 
 // handleConnection handles TLS connections from a Teleport proxy.
@@ -530,26 +614,99 @@ func (s *WindowsService) connectRDP(ctx context.Context, log logrus.FieldLogger,
 		return trace.Wrap(err)
 	}
 
+	recConfig, err := s.cfg.AccessPoint.GetSessionRecordingConfig(ctx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
 	sessionID := session.NewID()
 
+	// When recording is enabled, tee both directions of the TDP stream into
+	// a session recorder. Each recorded chunk carries the delay since the
+	// session start, which is enough to replay the session with its
+	// original timing.
+	var rw io.ReadWriter = proxyConn
+	var recorder libevents.StreamWriter
+	if recConfig.GetMode() != types.RecordOff {
+		recorder, err = s.newSessionRecorder(recConfig, string(sessionID))
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		defer func() {
+			if err := recorder.Close(s.closeCtx); err != nil {
+				log.WithError(err).Warning("Failed to close session recorder")
+			}
+		}()
+		rw = struct {
+			io.Reader
+			io.Writer
+		}{
+			Reader: io.TeeReader(proxyConn, recorder),
+			Writer: io.MultiWriter(proxyConn, recorder),
+		}
+	}
+
 	var windowsUser string
 	authorize := func(login string) error {
 		windowsUser = login // capture attempted login user
 		return authCtx.Checker.CheckAccess(
 			desktop,
 			services.AccessMFAParams{Verified: true},
-			services.NewWindowsLoginMatcher(login))
+			services.NewWindowsLoginMatcher(login),
+			services.NewClientNetworkMatcher(identity.ClientIP))
 	}
 
-	tdpConn := tdp.NewConn(proxyConn)
+	// Screenshots are written straight to the session recorder so they end
+	// up in the recording without also being sent to the client.
+	var onScreenshot func(frame []byte)
+	if recorder != nil {
+		onScreenshot = func(frame []byte) {
+			if _, err := recorder.Write(frame); err != nil {
+				log.WithError(err).Warning("Failed to record session screenshot")
+			}
+		}
+	}
+
+	tdpConn := tdp.NewConn(rw)
 	rdpc, err := rdpclient.New(ctx, rdpclient.Config{
 		Log: log,
 		GenerateUserCert: func(ctx context.Context, username string) (certDER, keyDER []byte, err error) {
 			return s.generateCredentials(ctx, username, desktop.GetDomain())
 		},
-		Addr:        desktop.GetAddr(),
-		Conn:        tdpConn,
-		AuthorizeFn: authorize,
+		Addr:               desktop.GetAddr(),
+		Conn:               tdpConn,
+		AuthorizeFn:        authorize,
+		AllowClipboard:     !authCtx.Checker.DenyDesktopClipboard(),
+		AllowSmartcardAuth: !authCtx.Checker.DenyDesktopSmartcardAuth(),
+		ScreenshotInterval: s.cfg.ScreenshotInterval,
+		OnScreenshot:       onScreenshot,
+		OnSmartcardAuth: func(allowed bool) {
+			s.onSmartcardAuth(ctx, &identity, windowsUser, string(sessionID), desktop, allowed)
+		},
+		OnClipboard: func(direction, format string, size int) {
+			eventType := libevents.DesktopClipboardSendEvent
+			if direction == rdpclient.ClipboardReceive {
+				eventType = libevents.DesktopClipboardReceiveEvent
+			}
+			s.onClipboard(ctx, &identity, windowsUser, string(sessionID), desktop, eventType, format, size)
+		},
+		OnSharedDirectory: func(op, path string, size int) {
+			var eventType string
+			switch op {
+			case rdpclient.DirectoryShare:
+				eventType = libevents.DesktopDirectoryShareEvent
+			case rdpclient.DirectoryRead:
+				eventType = libevents.DesktopDirectoryReadEvent
+			case rdpclient.DirectoryWrite:
+				eventType = libevents.DesktopDirectoryWriteEvent
+			default:
+				return
+			}
+			s.onSharedDirectory(ctx, &identity, windowsUser, string(sessionID), desktop, eventType, path, size)
+		},
+		OnInputThrottled: func() {
+			s.onInputThrottled(ctx, &identity, windowsUser, string(sessionID), desktop)
+		},
 	})
 	if err != nil {
 		s.onSessionStart(ctx, &identity, windowsUser, string(sessionID), desktop, err)
@@ -591,6 +748,38 @@ func (s *WindowsService) connectRDP(ctx context.Context, log logrus.FieldLogger,
 	return trace.Wrap(err)
 }
 
+// newSessionRecorder creates a recorder for a desktop session. In sync
+// recording mode events are streamed directly to the auth server, otherwise
+// they are buffered on disk and uploaded when the session ends.
+func (s *WindowsService) newSessionRecorder(recConfig types.SessionRecordingConfig, sessionID string) (libevents.StreamWriter, error) {
+	var streamer libevents.Streamer = s.cfg.AuthClient
+	if !services.IsRecordSync(recConfig.GetMode()) {
+		uploadDir := filepath.Join(
+			s.cfg.DataDir, teleport.LogsDir, teleport.ComponentUpload,
+			libevents.StreamingLogsDir, apidefaults.Namespace,
+		)
+		fileStreamer, err := filesessions.NewStreamer(uploadDir)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		streamer = fileStreamer
+	}
+	return libevents.NewAuditWriter(libevents.AuditWriterConfig{
+		// Audit stream is using the service context, not the session
+		// context, to make sure that the session is uploaded even after it
+		// is closed.
+		Context:      s.closeCtx,
+		Streamer:     streamer,
+		Clock:        s.cfg.Clock,
+		SessionID:    session.ID(sessionID),
+		ServerID:     s.cfg.Heartbeat.HostUUID,
+		Namespace:    apidefaults.Namespace,
+		RecordOutput: recConfig.GetMode() != types.RecordOff,
+		Component:    teleport.Component(teleport.ComponentSession, teleport.ComponentWindowsDesktop),
+		ClusterName:  s.clusterName,
+	})
+}
+
 func (s *WindowsService) getServiceHeartbeatInfo() (types.Resource, error) {
 	srv, err := types.NewWindowsDesktopServiceV3(
 		s.cfg.Heartbeat.HostUUID,
@@ -607,7 +796,7 @@ func (s *WindowsService) getServiceHeartbeatInfo() (types.Resource, error) {
 
 // staticHostHeartbeatInfo generates the Windows Desktop resource
 // for heartbeating statically defined hosts
-func (s *WindowsService) staticHostHeartbeatInfo(netAddr utils.NetAddr,
+func (s *WindowsService) staticHostHeartbeatInfo(netAddr utils.NetAddr, domain string,
 	getHostLabels func(string) map[string]string) func() (types.Resource, error) {
 	return func() (types.Resource, error) {
 		addr := netAddr.String()
@@ -624,7 +813,7 @@ func (s *WindowsService) staticHostHeartbeatInfo(netAddr utils.NetAddr,
 			labels,
 			types.WindowsDesktopSpecV3{
 				Addr:   addr,
-				Domain: s.cfg.Domain,
+				Domain: domain,
 			})
 		if err != nil {
 			return nil, trace.Wrap(err)
@@ -811,8 +1000,8 @@ func (s *WindowsService) crlContainerDN() string {
 
 // generateCredentials generates a private key / certificate pair for the given
 // Windows username. The certificate has certain special fields different from
-// the regular Teleport user certificate, to meet the requirements of Active
-// Directory. See:
+// the regular Teleport user certificate, to meet the requirements of smart
+// card logon. domain is empty for standalone hosts without Active Directory. See:
 // https://docs.microsoft.com/en-us/windows/security/identity-protection/smart-cards/smart-card-certificate-requirements-and-enumeration
 func (s *WindowsService) generateCredentials(ctx context.Context, username, domain string) (certDER, keyDER []byte, err error) {
 	// Important: rdpclient currently only supports 2048-bit RSA keys.
@@ -850,17 +1039,18 @@ func (s *WindowsService) generateCredentials(ctx context.Context, username, doma
 		return nil, nil, trace.Wrap(err)
 	}
 	csrPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrBytes})
-	// Note: this CRL DN may or may not be the same DN published in updateCRL.
-	//
-	// There can be multiple AD domains connected to Teleport. Each
-	// windows_desktop_service is connected to a single AD domain and publishes
-	// CRLs in it. Each service can also handle RDP connections for a different
-	// domain, with the assumption that some other windows_desktop_service
-	// published a CRL there.
-	crlDN := s.crlDN()
-	genResp, err := s.cfg.AuthClient.GenerateWindowsDesktopCert(ctx, &proto.WindowsDesktopCertRequest{
-		CSR: csrPEM,
-		// LDAP URI pointing at the CRL created with updateCRL.
+	// Standalone hosts have no domain controller to fetch a CRL from, so the
+	// certificate carries no CRL endpoint; the Teleport user CA is imported
+	// into the host's certificate stores instead.
+	var crlEndpoint string
+	if domain != "" {
+		// Note: this CRL DN may or may not be the same DN published in updateCRL.
+		//
+		// There can be multiple AD domains connected to Teleport. Each
+		// windows_desktop_service is connected to a single AD domain and publishes
+		// CRLs in it. Each service can also handle RDP connections for a different
+		// domain, with the assumption that some other windows_desktop_service
+		// published a CRL there.
 		//
 		// The full format is:
 		// ldap://domain_controller_addr/distinguished_name_and_parameters.
@@ -868,7 +1058,12 @@ func (s *WindowsService) generateCredentials(ctx context.Context, username, doma
 		// Using ldap:///distinguished_name_and_parameters (with empty
 		// domain_controller_addr) will cause Windows to fetch the CRL from any
 		// of its current domain controllers.
-		CRLEndpoint: fmt.Sprintf("ldap:///%s?certificateRevocationList?base?objectClass=cRLDistributionPoint", crlDN),
+		crlEndpoint = fmt.Sprintf("ldap:///%s?certificateRevocationList?base?objectClass=cRLDistributionPoint", s.crlDN())
+	}
+	genResp, err := s.cfg.AuthClient.GenerateWindowsDesktopCert(ctx, &proto.WindowsDesktopCertRequest{
+		CSR: csrPEM,
+		// LDAP URI pointing at the CRL created with updateCRL.
+		CRLEndpoint: crlEndpoint,
 		TTL:         proto.Duration(windowsDesktopCertTTL),
 	})
 	if err != nil {
@@ -927,13 +1122,19 @@ func subjectAltNameExtension(user, domain string) (pkix.Extension, error) {
 	// othernName SAN is needed to pass the UPN of the user, per
 	// https://docs.microsoft.com/en-us/troubleshoot/windows-server/windows-security/enabling-smart-card-logon-third-party-certification-authorities
 	ext := pkix.Extension{Id: subjectAltNameExtensionOID}
+	// Standalone hosts without Active Directory match the certificate against
+	// a bare username, so the UPN carries no domain suffix.
+	upnValue := user
+	if domain != "" {
+		upnValue = fmt.Sprintf("%s@%s", user, domain) // TODO(zmb3): sanitize username to avoid domain spoofing
+	}
 	var err error
 	ext.Value, err = asn1.Marshal(
 		subjectAltName{
 			OtherName: otherName{
 				OID: upnOtherNameOID,
 				Value: upn{
-					Value: fmt.Sprintf("%s@%s", user, domain), // TODO(zmb3): sanitize username to avoid domain spoofing
+					Value: upnValue,
 				},
 			},
 		},
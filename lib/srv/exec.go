@@ -241,6 +241,12 @@ func (e *localExec) transformSecureCopy() error {
 		return nil
 	}
 
+	// File transfers can be denied by role independently of shell access.
+	if e.Ctx.Identity.RoleSet.DenyFileTransfers() {
+		return trace.AccessDenied("file transfers are not allowed by role for user %q",
+			e.Ctx.Identity.TeleportUser)
+	}
+
 	// for scp requests update the command to execute to launch teleport with
 	// scp parameters just like openssh does.
 	teleportBin, err := os.Executable()
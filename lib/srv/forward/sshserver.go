@@ -22,6 +22,7 @@ import (
 	"io"
 	"net"
 	"sync"
+	"time"
 
 	"golang.org/x/crypto/ssh"
 	"golang.org/x/crypto/ssh/agent"
@@ -732,26 +733,31 @@ func (s *Server) handleDirectTCPIPRequest(ctx context.Context, ch ssh.Channel, r
 	}
 	defer conn.Close()
 
-	if err := s.EmitAuditEvent(s.closeContext, &apievents.PortForward{
-		Metadata: apievents.Metadata{
-			Type: events.PortForwardEvent,
-			Code: events.PortForwardCode,
-		},
-		UserMetadata: apievents.UserMetadata{
-			Login:        s.identityContext.Login,
-			User:         s.identityContext.TeleportUser,
-			Impersonator: s.identityContext.Impersonator,
-		},
-		ConnectionMetadata: apievents.ConnectionMetadata{
-			LocalAddr:  s.sconn.LocalAddr().String(),
-			RemoteAddr: s.sconn.RemoteAddr().String(),
-		},
-		Addr: scx.DstAddr,
-		Status: apievents.Status{
-			Success: true,
-		},
-	}); err != nil {
-		scx.WithError(err).Warn("Failed to emit port forward event.")
+	// Emit a port forwarding start event now that the connection to the
+	// target has been made.
+	start := time.Now()
+	s.emitPortForwardEvent(scx, events.PortForwardStartEvent, events.PortForwardStartCode, "")
+
+	// Start a reporter that periodically emits transfer summaries while the
+	// channel stays open, so bulk data movement over port forwarding is
+	// visible in the audit log before the channel closes.
+	reporterCtx, cancelReporter := context.WithCancel(ctx)
+	defer cancelReporter()
+	reporter, err := srv.StartTrafficReporter(srv.TrafficReporterConfig{
+		Context:      reporterCtx,
+		Emitter:      s,
+		Entry:        scx.Entry,
+		SessionID:    string(scx.SessionID()),
+		ServerID:     s.HostUUID(),
+		Namespace:    s.GetNamespace(),
+		Login:        s.identityContext.Login,
+		TeleportUser: s.identityContext.TeleportUser,
+		RemoteAddr:   s.sconn.RemoteAddr().String(),
+		LocalAddr:    s.sconn.LocalAddr().String(),
+	})
+	if err != nil {
+		s.stderrWrite(ch, err.Error())
+		return
 	}
 
 	var wg sync.WaitGroup
@@ -759,7 +765,8 @@ func (s *Server) handleDirectTCPIPRequest(ctx context.Context, ch ssh.Channel, r
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		if _, err := io.Copy(ch, conn); err != nil {
+		_, err := io.Copy(reporter.WrapReceived(ch), conn)
+		if err != nil {
 			scx.Warningf("failed proxying data for port forwarding connection: %v", err)
 		}
 		ch.Close()
@@ -767,7 +774,8 @@ func (s *Server) handleDirectTCPIPRequest(ctx context.Context, ch ssh.Channel, r
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		if _, err := io.Copy(conn, ch); err != nil {
+		_, err := io.Copy(reporter.WrapTransmitted(conn), ch)
+		if err != nil {
 			scx.Warningf("failed proxying data for port forwarding connection: %v", err)
 		}
 		conn.Close()
@@ -782,6 +790,41 @@ func (s *Server) handleDirectTCPIPRequest(ctx context.Context, ch ssh.Channel, r
 	case <-wch:
 	case <-ctx.Done():
 	}
+
+	// Emit a port forwarding stop event with the duration of the channel
+	// and the bytes transferred in each direction.
+	bytesSent, bytesReceived := reporter.Stat()
+	s.emitPortForwardEvent(scx, events.PortForwardStopEvent, events.PortForwardStopCode,
+		fmt.Sprintf("%v bytes sent, %v bytes received in %v",
+			bytesSent, bytesReceived,
+			time.Since(start).Round(time.Millisecond)))
+}
+
+// emitPortForwardEvent emits a port forwarding audit event of the given type
+// and code for the channel of the server context.
+func (s *Server) emitPortForwardEvent(scx *srv.ServerContext, eventType, eventCode, message string) {
+	if err := s.EmitAuditEvent(s.closeContext, &apievents.PortForward{
+		Metadata: apievents.Metadata{
+			Type: eventType,
+			Code: eventCode,
+		},
+		UserMetadata: apievents.UserMetadata{
+			Login:        s.identityContext.Login,
+			User:         s.identityContext.TeleportUser,
+			Impersonator: s.identityContext.Impersonator,
+		},
+		ConnectionMetadata: apievents.ConnectionMetadata{
+			LocalAddr:  s.sconn.LocalAddr().String(),
+			RemoteAddr: s.sconn.RemoteAddr().String(),
+		},
+		Addr: scx.DstAddr,
+		Status: apievents.Status{
+			Success:     true,
+			UserMessage: message,
+		},
+	}); err != nil {
+		scx.WithError(err).Warn("Failed to emit port forward event.")
+	}
 }
 
 // handleSessionChannel handles accepting and forwarding a session channel from the client to
@@ -905,6 +948,10 @@ func (s *Server) dispatch(ctx context.Context, ch ssh.Channel, req *ssh.Request,
 		return s.termHandlers.HandleShell(ch, req, scx)
 	case sshutils.WindowChangeRequest:
 		return s.termHandlers.HandleWinChange(ch, req, scx)
+	case sshutils.PauseRequest:
+		return s.termHandlers.HandlePause(ch, req, scx)
+	case sshutils.ResumeRequest:
+		return s.termHandlers.HandleResume(ch, req, scx)
 	case sshutils.EnvRequest:
 		return s.handleEnv(ch, req, scx)
 	case sshutils.SubsystemRequest:
@@ -0,0 +1,150 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package srv
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strings"
+
+	"github.com/gravitational/trace"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// hostUserSudoersDir is the directory sudoers entries for provisioned host
+// users are installed in.
+const hostUserSudoersDir = "/etc/sudoers.d"
+
+// ProvisionHostUser creates the login user of the session if the roles of
+// the Teleport user enable automatic host user provisioning and the user
+// does not exist on the host yet. Groups configured on the roles are
+// created and the user is added to them, and configured sudoers entries are
+// installed. If the roles also enable cleanup, the user is deleted again
+// when the session context closes.
+//
+// Users that already exist on the host are never touched, provisioning only
+// manages users it created itself.
+func ProvisionHostUser(ctx *ServerContext) error {
+	create, cleanup := ctx.Identity.RoleSet.CreateHostUser()
+	if !create {
+		return nil
+	}
+
+	login := ctx.Identity.Login
+	if _, err := user.Lookup(login); err == nil {
+		return nil
+	} else if _, ok := err.(user.UnknownUserError); !ok {
+		return trace.Wrap(err)
+	}
+
+	groups := ctx.Identity.RoleSet.HostUserGroups()
+	for _, group := range groups {
+		// The -f flag makes groupadd succeed if the group already exists.
+		if err := runHostUsersCommand("groupadd", "-f", group); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+
+	args := []string{"-m"}
+	if len(groups) != 0 {
+		args = append(args, "-G", strings.Join(groups, ","))
+	}
+	args = append(args, login)
+	if err := runHostUsersCommand("useradd", args...); err != nil {
+		return trace.Wrap(err)
+	}
+	ctx.Infof("Created host user %q with groups %v.", login, groups)
+
+	var sudoersFile string
+	if sudoers := ctx.Identity.RoleSet.HostUserSudoers(); len(sudoers) != 0 {
+		sudoersFile = filepath.Join(hostUserSudoersDir, "teleport-"+login)
+		if err := writeSudoersFile(sudoersFile, login, sudoers); err != nil {
+			return trace.Wrap(err)
+		}
+		ctx.Infof("Installed sudoers entries for host user %q.", login)
+	}
+
+	if cleanup {
+		ctx.AddCloser(&hostUserCloser{
+			login:       login,
+			sudoersFile: sudoersFile,
+		})
+	}
+	return nil
+}
+
+// writeSudoersFile installs the sudoers entries for the login user and
+// validates them with visudo, removing the file again if it does not pass.
+func writeSudoersFile(path, login string, sudoers []string) error {
+	var b bytes.Buffer
+	for _, entry := range sudoers {
+		fmt.Fprintf(&b, "%v %v\n", login, entry)
+	}
+	if err := ioutil.WriteFile(path, b.Bytes(), 0440); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	// A broken file in sudoers.d can lock sudo up entirely, never install
+	// entries that do not validate.
+	if err := runHostUsersCommand("visudo", "-c", "-f", path); err != nil {
+		os.Remove(path)
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// hostUserCloser deletes a provisioned host user when the session context
+// closes.
+type hostUserCloser struct {
+	login       string
+	sudoersFile string
+}
+
+// Close removes the host user, its home directory and its sudoers entries.
+func (c *hostUserCloser) Close() error {
+	var errors []error
+	if c.sudoersFile != "" {
+		if err := os.Remove(c.sudoersFile); err != nil && !os.IsNotExist(err) {
+			errors = append(errors, trace.ConvertSystemError(err))
+		}
+	}
+	if err := runHostUsersCommand("userdel", "-r", c.login); err != nil {
+		errors = append(errors, err)
+	} else {
+		log.Infof("Deleted host user %q.", c.login)
+	}
+	return trace.NewAggregate(errors...)
+}
+
+// runHostUsersCommand runs one of the host user management commands,
+// returning its output as part of the error if it fails.
+func runHostUsersCommand(name string, args ...string) error {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return trace.NotFound("cannot provision host user: %v not found on the host", name)
+	}
+	out, err := exec.Command(path, args...).CombinedOutput()
+	if err != nil {
+		return trace.Wrap(err, "%v failed: %v", name, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -37,6 +37,7 @@ import (
 	"github.com/gravitational/teleport/lib/shell"
 	"github.com/gravitational/teleport/lib/srv/uacc"
 	"github.com/gravitational/teleport/lib/sshutils"
+	"github.com/gravitational/teleport/lib/sshutils/sftp"
 	"github.com/gravitational/teleport/lib/utils"
 
 	log "github.com/sirupsen/logrus"
@@ -339,6 +340,159 @@ func RunForward() (io.Writer, int, error) {
 	return ioutil.Discard, teleport.RemoteCommandSuccess, nil
 }
 
+// RunSFTP reads in the command to run from the parent process (over a pipe),
+// changes the process credentials to those of the login user, and serves the
+// SFTP subsystem on std{in,out}. Completed file transfers are reported back
+// to the parent over the audit pipe so they can be emitted as audit events.
+func RunSFTP() (io.Writer, int, error) {
+	// errorWriter is used to return any error message back to the client.
+	// Use stderr so that it's not interpreted as protocol data.
+	errorWriter := os.Stderr
+
+	// Parent sends the command payload in the third file descriptor.
+	cmdfd := os.NewFile(uintptr(3), "/proc/self/fd/3")
+	if cmdfd == nil {
+		return errorWriter, teleport.RemoteCommandFailure, trace.BadParameter("command pipe not found")
+	}
+	contfd := os.NewFile(uintptr(4), "/proc/self/fd/4")
+	if contfd == nil {
+		return errorWriter, teleport.RemoteCommandFailure, trace.BadParameter("continue pipe not found")
+	}
+	// The parent reads per-file transfer records from the audit pipe.
+	auditfd := os.NewFile(uintptr(5), "/proc/self/fd/5")
+	if auditfd == nil {
+		return errorWriter, teleport.RemoteCommandFailure, trace.BadParameter("audit pipe not found")
+	}
+	defer auditfd.Close()
+
+	// Read in the command payload.
+	var b bytes.Buffer
+	_, err := b.ReadFrom(cmdfd)
+	if err != nil {
+		return errorWriter, teleport.RemoteCommandFailure, trace.Wrap(err)
+	}
+	var c ExecCommand
+	err = json.Unmarshal(b.Bytes(), &c)
+	if err != nil {
+		return errorWriter, teleport.RemoteCommandFailure, trace.Wrap(err)
+	}
+
+	// If PAM is enabled, open a PAM context. This has to be done before anything
+	// else because PAM is sometimes used to create the local user used to
+	// launch the shell under.
+	if c.PAMConfig != nil {
+		// Open the PAM context.
+		pamContext, err := pam.Open(&pam.Config{
+			ServiceName: c.PAMConfig.ServiceName,
+			Login:       c.Login,
+			Stdin:       os.Stdin,
+			Stdout:      ioutil.Discard,
+			Stderr:      ioutil.Discard,
+			// Set Teleport specific environment variables that PAM modules
+			// like pam_script.so can pick up to potentially customize the
+			// account/session.
+			Env: c.PAMConfig.Environment,
+		})
+		if err != nil {
+			return errorWriter, teleport.RemoteCommandFailure, trace.Wrap(err)
+		}
+		defer pamContext.Close()
+	}
+
+	localUser, err := user.Lookup(c.Login)
+	if err != nil {
+		return errorWriter, teleport.RemoteCommandFailure, trace.Wrap(err)
+	}
+
+	// Wait until the continue signal is received from Teleport signaling that
+	// the child process has been placed in a cgroup.
+	err = waitForContinue(contfd)
+	if err != nil {
+		return errorWriter, teleport.RemoteCommandFailure, trace.Wrap(err)
+	}
+
+	// Unlike exec requests which launch a separate process with the login
+	// user's credentials, the SFTP subsystem is served in-process, so the
+	// credentials of this process are changed directly. The operating system
+	// then enforces file permissions on every request.
+	if err := switchUser(localUser); err != nil {
+		return errorWriter, teleport.RemoteCommandFailure, trace.Wrap(err)
+	}
+
+	// Start in the home directory like OpenSSH does, clients resolve
+	// relative paths against it.
+	if utils.IsDir(localUser.HomeDir) {
+		if err := os.Chdir(localUser.HomeDir); err != nil {
+			return errorWriter, teleport.RemoteCommandFailure, trace.Wrap(err)
+		}
+	}
+
+	server := sftp.NewServer(os.Stdin, os.Stdout, func(transfer sftp.FileTransfer) {
+		// Transfer records are reported to the parent as JSON lines. This is
+		// best-effort, a broken audit pipe should not fail the transfer
+		// itself, the parent logs the error on its side.
+		data, err := json.Marshal(transfer)
+		if err != nil {
+			return
+		}
+		auditfd.Write(append(data, '\n'))
+	})
+	if err := server.Serve(); err != nil {
+		return errorWriter, teleport.RemoteCommandFailure, trace.Wrap(err)
+	}
+
+	return ioutil.Discard, teleport.RemoteCommandSuccess, nil
+}
+
+// switchUser changes the credentials of the current process to those of the
+// login user. It's a no-op if the credentials already match, which allows
+// running Teleport as a regular user for testing.
+func switchUser(localUser *user.User) error {
+	if strconv.Itoa(os.Getuid()) == localUser.Uid && strconv.Itoa(os.Getgid()) == localUser.Gid {
+		return nil
+	}
+
+	uid, err := strconv.Atoi(localUser.Uid)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	gid, err := strconv.Atoi(localUser.Gid)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	// Lookup supplementary groups for the user.
+	userGroups, err := localUser.GroupIds()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	groups := make([]int, 0)
+	for _, sgid := range userGroups {
+		igid, err := strconv.Atoi(sgid)
+		if err != nil {
+			log.Warnf("Cannot interpret user group: '%v'", sgid)
+		} else {
+			groups = append(groups, igid)
+		}
+	}
+	if len(groups) == 0 {
+		groups = append(groups, gid)
+	}
+
+	// The group list has to be set while still privileged, and the GID has
+	// to be set before the UID drops the permission to do so.
+	if err := syscall.Setgroups(groups); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	if err := syscall.Setgid(gid); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	if err := syscall.Setuid(uid); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	return nil
+}
+
 // RunAndExit will run the requested command and then exit. This wrapper
 // allows Run{Command,Forward} to use defers and makes sure error messages
 // are consistent across both.
@@ -352,6 +506,8 @@ func RunAndExit(commandType string) {
 		w, code, err = RunCommand()
 	case teleport.ForwardSubCommand:
 		w, code, err = RunForward()
+	case teleport.SFTPSubCommand:
+		w, code, err = RunSFTP()
 	default:
 		w, code, err = os.Stderr, teleport.RemoteCommandFailure, fmt.Errorf("unknown command type: %v", commandType)
 	}
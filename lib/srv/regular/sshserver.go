@@ -30,6 +30,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"golang.org/x/crypto/ssh"
 
@@ -163,6 +164,10 @@ type Server struct {
 	// configuration.
 	fips bool
 
+	// breakGlass configures the emergency access mode for pre-authorized
+	// identities when the auth service is unreachable.
+	breakGlass srv.BreakGlassConfig
+
 	// ebpf is the service used for enhanced session recording.
 	ebpf bpf.BPF
 
@@ -447,6 +452,15 @@ func SetEmitter(emitter events.StreamEmitter) ServerOption {
 	}
 }
 
+// SetBreakGlass configures the emergency access mode that lets pinned
+// identities log in while the auth service is unreachable.
+func SetBreakGlass(breakGlass srv.BreakGlassConfig) ServerOption {
+	return func(s *Server) error {
+		s.breakGlass = breakGlass
+		return nil
+	}
+}
+
 // SetUUID sets server unique ID
 func SetUUID(uuid string) ServerOption {
 	return func(s *Server) error {
@@ -640,6 +654,7 @@ func New(addr utils.NetAddr,
 		FIPS:        s.fips,
 		Emitter:     s.StreamEmitter,
 		Clock:       s.clock,
+		BreakGlass:  s.breakGlass,
 	}
 
 	s.authHandlers, err = srv.NewAuthHandlers(&authHandlerConfig)
@@ -1231,15 +1246,42 @@ func (s *Server) handleDirectTCPIPRequest(ctx context.Context, ccx *sshutils.Con
 		return
 	}
 
+	// Emit a port forwarding start event now that the channel is open and
+	// the connection to the target is being made.
+	start := time.Now()
+	s.emitPortForwardEvent(scx, events.PortForwardStartEvent, events.PortForwardStartCode, "")
+
+	// Start a reporter that periodically emits transfer summaries while the
+	// channel stays open, so bulk data movement over port forwarding is
+	// visible in the audit log before the channel closes.
+	reporterCtx, cancelReporter := context.WithCancel(ctx)
+	defer cancelReporter()
+	reporter, err := srv.StartTrafficReporter(srv.TrafficReporterConfig{
+		Context:      reporterCtx,
+		Emitter:      s,
+		Entry:        scx.Entry,
+		SessionID:    string(scx.SessionID()),
+		ServerID:     s.HostUUID(),
+		Namespace:    s.GetNamespace(),
+		Login:        scx.Identity.Login,
+		TeleportUser: scx.Identity.TeleportUser,
+		RemoteAddr:   scx.ServerConn.RemoteAddr().String(),
+		LocalAddr:    scx.ServerConn.LocalAddr().String(),
+	})
+	if err != nil {
+		writeStderr(channel, err.Error())
+		return
+	}
+
 	// Start copy routines that copy from channel to stdin pipe and from stdout
-	// pipe to channel.
+	// pipe to channel, counting the bytes transferred in each direction.
 	errorCh := make(chan error, 2)
 	go func() {
 		defer channel.Close()
 		defer pw.Close()
 		defer pr.Close()
 
-		_, err := io.Copy(pw, channel)
+		_, err := io.Copy(reporter.WrapTransmitted(pw), channel)
 		errorCh <- err
 	}()
 	go func() {
@@ -1247,7 +1289,7 @@ func (s *Server) handleDirectTCPIPRequest(ctx context.Context, ccx *sshutils.Con
 		defer pw.Close()
 		defer pr.Close()
 
-		_, err := io.Copy(channel, pr)
+		_, err := io.Copy(reporter.WrapReceived(channel), pr)
 		errorCh <- err
 	}()
 
@@ -1265,17 +1307,29 @@ Loop:
 			break Loop
 		}
 	}
+
+	// Emit a port forwarding stop event with the duration of the channel
+	// and the bytes transferred in each direction.
+	bytesSent, bytesReceived := reporter.Stat()
+	s.emitPortForwardEvent(scx, events.PortForwardStopEvent, events.PortForwardStopCode,
+		fmt.Sprintf("%v bytes sent, %v bytes received in %v",
+			bytesSent, bytesReceived,
+			time.Since(start).Round(time.Millisecond)))
+
 	err = cmd.Wait()
 	if err != nil {
 		writeStderr(channel, err.Error())
 		return
 	}
+}
 
-	// Emit a port forwarding event.
+// emitPortForwardEvent emits a port forwarding audit event of the given type
+// and code for the channel of the server context.
+func (s *Server) emitPortForwardEvent(scx *srv.ServerContext, eventType, eventCode, message string) {
 	if err := s.EmitAuditEvent(s.ctx, &apievents.PortForward{
 		Metadata: apievents.Metadata{
-			Type: events.PortForwardEvent,
-			Code: events.PortForwardCode,
+			Type: eventType,
+			Code: eventCode,
 		},
 		UserMetadata: apievents.UserMetadata{
 			Login:        scx.Identity.Login,
@@ -1288,7 +1342,8 @@ Loop:
 		},
 		Addr: scx.DstAddr,
 		Status: apievents.Status{
-			Success: true,
+			Success:     true,
+			UserMessage: message,
 		},
 	}); err != nil {
 		log.WithError(err).Warn("Failed to emit port forward event.")
@@ -1327,6 +1382,16 @@ func (s *Server) handleSessionRequests(ctx context.Context, ccx *sshutils.Connec
 
 	ch = scx.TrackActivity(ch)
 
+	// Create the login user on the host if the user's roles enable automatic
+	// host user provisioning and the user does not exist yet.
+	if !s.proxyMode {
+		if err := srv.ProvisionHostUser(scx); err != nil {
+			log.WithError(err).Error("Unable to provision host user.")
+			writeStderr(ch, "Unable to provision host user.")
+			return
+		}
+	}
+
 	// The keep-alive loop will keep pinging the remote server and after it has
 	// missed a certain number of keep-alive requests it will cancel the
 	// closeContext which signals the server to shutdown.
@@ -1433,12 +1498,18 @@ func (s *Server) dispatch(ch ssh.Channel, req *ssh.Request, ctx *srv.ServerConte
 		return s.termHandlers.HandleShell(ch, req, ctx)
 	case sshutils.WindowChangeRequest:
 		return s.termHandlers.HandleWinChange(ch, req, ctx)
+	case sshutils.PauseRequest:
+		return s.termHandlers.HandlePause(ch, req, ctx)
+	case sshutils.ResumeRequest:
+		return s.termHandlers.HandleResume(ch, req, ctx)
 	case sshutils.EnvRequest:
 		return s.handleEnv(ch, req, ctx)
 	case sshutils.SubsystemRequest:
 		// subsystems are SSH subsystems defined in http://tools.ietf.org/html/rfc4254 6.6
 		// they are in essence SSH session extensions, allowing to implement new SSH commands
 		return s.handleSubsystem(ch, req, ctx)
+	case sshutils.X11ForwardRequest:
+		return s.handleX11ForwardNode(ch, req, ctx)
 	case sshutils.AgentForwardRequest:
 		// This happens when SSH client has agent forwarding enabled, in this case
 		// client sends a special request, in return SSH server opens new channel
@@ -1483,6 +1554,74 @@ func (s *Server) handleAgentForwardNode(req *ssh.Request, ctx *srv.ServerContext
 	return nil
 }
 
+// handleX11ForwardNode sets up X11 forwarding for the session: a proxy X
+// display is bound on the node and inbound connections on it are carried
+// back to the client over "x11" channels.
+func (s *Server) handleX11ForwardNode(ch ssh.Channel, req *ssh.Request, ctx *srv.ServerContext) error {
+	event := &apievents.X11Forward{
+		Metadata: apievents.Metadata{
+			Type: events.X11ForwardEvent,
+			Code: events.X11ForwardCode,
+		},
+		UserMetadata: apievents.UserMetadata{
+			Login:        ctx.Identity.Login,
+			User:         ctx.Identity.TeleportUser,
+			Impersonator: ctx.Identity.Impersonator,
+		},
+		ConnectionMetadata: apievents.ConnectionMetadata{
+			LocalAddr:  ctx.ServerConn.LocalAddr().String(),
+			RemoteAddr: ctx.ServerConn.RemoteAddr().String(),
+		},
+		Status: apievents.Status{
+			Success: true,
+		},
+	}
+
+	err := s.setupX11Forwarding(req, ctx)
+	if err != nil {
+		event.Metadata.Code = events.X11ForwardFailureCode
+		event.Status.Success = false
+		event.Status.Error = err.Error()
+	}
+	if emitErr := s.EmitAuditEvent(s.ctx, event); emitErr != nil {
+		log.WithError(emitErr).Warn("Failed to emit X11 forward event.")
+	}
+	return trace.Wrap(err)
+}
+
+// setupX11Forwarding binds the proxy X display, installs the Xauthority
+// file for the session and points DISPLAY at the listener.
+func (s *Server) setupX11Forwarding(req *ssh.Request, ctx *srv.ServerContext) error {
+	// Check if the user's RBAC role allows X11 forwarding.
+	if err := s.authHandlers.CheckX11Forward(ctx); err != nil {
+		return trace.Wrap(err)
+	}
+
+	var x11Req srv.X11ForwardRequestPayload
+	if err := ssh.Unmarshal(req.Payload, &x11Req); err != nil {
+		return trace.BadParameter("failed to parse X11 forwarding request: %v", err)
+	}
+
+	forwarder, err := srv.NewX11Forwarder(ctx, x11Req)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	authFile, err := forwarder.WriteXAuthority(ctx.Identity.Login)
+	if err != nil {
+		forwarder.Close()
+		return trace.Wrap(err)
+	}
+
+	ctx.SetEnv(teleport.X11Display, forwarder.Display())
+	ctx.SetEnv(teleport.X11Authority, authFile)
+	ctx.AddCloser(forwarder)
+	ctx.Debugf("Starting X11 forwarding for Teleport user %v on display %v.",
+		ctx.Identity.TeleportUser, forwarder.Display())
+	go forwarder.Serve()
+
+	return nil
+}
+
 // handleAgentForwardProxy will forward the clients agent to the proxy (when
 // the proxy is running in recording mode). When running in normal mode, this
 // request will do nothing. To maintain interoperability, agent forwarding
@@ -1733,6 +1872,12 @@ func (s *Server) parseSubsystemRequest(req *ssh.Request, ctx *srv.ServerContext)
 	if s.proxyMode && strings.HasPrefix(r.Name, "proxysites") {
 		return parseProxySitesSubsys(r.Name, s)
 	}
+	if !s.proxyMode && r.Name == teleport.SFTPSubsystem {
+		return srv.NewSFTPSubsystem(), nil
+	}
+	if !s.proxyMode && strings.HasPrefix(r.Name, teleport.WatchSubsystemPrefix) {
+		return srv.NewWatchSubsystem(r.Name, s.reg)
+	}
 	return nil, trace.BadParameter("unrecognized subsystem: %v", r.Name)
 }
 
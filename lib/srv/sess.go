@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"io"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -196,6 +197,12 @@ func (s *SessionRegistry) OpenSession(ch ssh.Channel, req *ssh.Request, ctx *Ser
 
 		return nil
 	}
+	// Enforce the maximum number of concurrent interactive sessions the
+	// user may have on this node before creating a new one.
+	if err := s.acquireSessionSemaphore(ctx); err != nil {
+		return trace.Wrap(err)
+	}
+
 	// session not found? need to create one. start by getting/generating an ID for it
 	sid, found := ctx.GetEnv(sshutils.SessionEnvVar)
 	if !found {
@@ -221,6 +228,78 @@ func (s *SessionRegistry) OpenSession(ch ssh.Channel, req *ssh.Request, ctx *Ser
 	return nil
 }
 
+// acquireSessionSemaphore enforces the maximum number of concurrent
+// interactive sessions the user may have on this node. The semaphore lease
+// is kept alive until the session context is closed. If the limit has been
+// reached, a session reject event is emitted and an access denied error is
+// returned to the client.
+func (s *SessionRegistry) acquireSessionSemaphore(ctx *ServerContext) error {
+	// Concurrent session control only applies to nodes.
+	if s.srv.Component() != teleport.ComponentNode {
+		return nil
+	}
+	maxSessions := ctx.Identity.RoleSet.MaxConcurrentSessions()
+	if maxSessions == 0 {
+		return nil
+	}
+
+	netConfig, err := s.srv.GetAccessPoint().GetClusterNetworkingConfig(ctx.CancelContext())
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	semLock, err := services.AcquireSemaphoreLock(ctx.CancelContext(), services.SemaphoreLockConfig{
+		Service: s.srv.GetAccessPoint(),
+		Expiry:  netConfig.GetSessionControlTimeout(),
+		Params: types.AcquireSemaphoreRequest{
+			SemaphoreKind: types.SemaphoreKindSession,
+			SemaphoreName: fmt.Sprintf("%v@%v", ctx.Identity.TeleportUser, s.srv.HostUUID()),
+			MaxLeases:     maxSessions,
+			Holder:        s.srv.HostUUID(),
+		},
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), teleport.MaxLeases) {
+			// The user has exceeded their maximum concurrent sessions on
+			// this node.
+			if err := s.srv.EmitAuditEvent(s.srv.Context(), &apievents.SessionReject{
+				Metadata: apievents.Metadata{
+					Type: events.SessionRejectedEvent,
+					Code: events.SessionRejectedCode,
+				},
+				UserMetadata: apievents.UserMetadata{
+					Login:        ctx.Identity.Login,
+					User:         ctx.Identity.TeleportUser,
+					Impersonator: ctx.Identity.Impersonator,
+				},
+				ConnectionMetadata: apievents.ConnectionMetadata{
+					Protocol:   events.EventProtocolSSH,
+					LocalAddr:  ctx.ServerConn.LocalAddr().String(),
+					RemoteAddr: ctx.ServerConn.RemoteAddr().String(),
+				},
+				ServerMetadata: apievents.ServerMetadata{
+					ServerID:        s.srv.HostUUID(),
+					ServerNamespace: s.srv.GetNamespace(),
+				},
+				Reason:  events.SessionRejectedEvent,
+				Maximum: maxSessions,
+			}); err != nil {
+				s.log.WithError(err).Warn("Failed to emit session reject event.")
+			}
+			err = trace.AccessDenied("too many concurrent sessions for user %q on this node (max=%d)",
+				ctx.Identity.TeleportUser,
+				maxSessions,
+			)
+		}
+		return trace.Wrap(err)
+	}
+
+	// Keep the lease alive for the lifetime of the session context; closing
+	// the context releases the semaphore.
+	go semLock.KeepAlive(ctx.CancelContext())
+	return nil
+}
+
 // OpenExecSession opens an non-interactive exec session.
 func (s *SessionRegistry) OpenExecSession(channel ssh.Channel, req *ssh.Request, ctx *ServerContext) error {
 	// Create a new session ID. These sessions can not be joined so no point in
@@ -465,6 +544,268 @@ func (s *SessionRegistry) NotifyWinChange(params rsession.TerminalParams, ctx *S
 	return nil
 }
 
+// PauseSession pauses the session in the given context: terminal output and
+// the input of all parties are held until ResumeSession is called. Any
+// participant of the session can pause it.
+func (s *SessionRegistry) PauseSession(ctx *ServerContext) error {
+	session := ctx.getSession()
+	if session == nil {
+		s.log.Debug("Unable to pause session, no session found in context.")
+		return nil
+	}
+
+	if err := session.Pause(ctx); err != nil {
+		return trace.Wrap(err)
+	}
+
+	// Emit session pause event to both the Audit Log as well as over the
+	// "x-teleport-event" channel in the SSH connection.
+	s.emitPauseResumeEvent(ctx, session, events.SessionPauseEvent, events.SessionPauseCode)
+	return nil
+}
+
+// ResumeSession resumes a previously paused session, releasing terminal
+// output buffered while the session was paused and the input of all parties.
+func (s *SessionRegistry) ResumeSession(ctx *ServerContext) error {
+	session := ctx.getSession()
+	if session == nil {
+		s.log.Debug("Unable to resume session, no session found in context.")
+		return nil
+	}
+
+	if err := session.Resume(ctx); err != nil {
+		return trace.Wrap(err)
+	}
+
+	// Emit session resume event to both the Audit Log as well as over the
+	// "x-teleport-event" channel in the SSH connection.
+	s.emitPauseResumeEvent(ctx, session, events.SessionResumeEvent, events.SessionResumeCode)
+	return nil
+}
+
+// KickParticipant forcibly removes all parties of the given Teleport user
+// from the session in the given context, tearing down their session reader
+// and writer immediately. Only the session initiator can remove participants.
+func (s *SessionRegistry) KickParticipant(ctx *ServerContext, user string) error {
+	session := ctx.getSession()
+	if session == nil {
+		return trace.NotFound("no session found in context")
+	}
+
+	if ctx.Identity.TeleportUser != session.initiator {
+		return trace.AccessDenied("only the session initiator %v can remove participants", session.initiator)
+	}
+	if user == session.initiator {
+		return trace.BadParameter("the session initiator can not be removed, terminate the session instead")
+	}
+
+	var kicked []*party
+	for _, p := range session.getParties() {
+		if p.user == user {
+			kicked = append(kicked, p)
+		}
+	}
+	if len(kicked) == 0 {
+		return trace.NotFound("user %v is not a participant of session %v", user, session.id)
+	}
+
+	message := fmt.Sprintf("\r\nParticipant %v was removed from the session by %v.\r\n", user, ctx.Identity.TeleportUser)
+	if _, err := session.switchWriter.WriteUnconditional([]byte(message)); err != nil {
+		s.log.Warnf("Failed to broadcast kick message: %v.", err)
+	}
+
+	for _, p := range kicked {
+		// Emit session kick event to both the Audit Log as well as over the
+		// "x-teleport-event" channel in the SSH connection.
+		s.emitKickTerminateEvent(ctx, session, events.SessionKickEvent, events.SessionKickCode, apievents.UserMetadata{
+			User:  p.user,
+			Login: p.login,
+		})
+
+		// Closing the party detaches its reader and writer from the session,
+		// closing the channel disconnects the removed client.
+		if err := p.Close(); err != nil {
+			s.log.WithError(err).Warnf("Failed to close kicked party %v.", p.id)
+		}
+		if err := p.ch.Close(); err != nil {
+			s.log.WithError(err).Warnf("Failed to close channel of kicked party %v.", p.id)
+		}
+	}
+	return nil
+}
+
+// TerminateSession forcibly ends the session in the given context,
+// disconnecting all parties. Only the session initiator can terminate the
+// session.
+func (s *SessionRegistry) TerminateSession(ctx *ServerContext) error {
+	session := ctx.getSession()
+	if session == nil {
+		return trace.NotFound("no session found in context")
+	}
+
+	if ctx.Identity.TeleportUser != session.initiator {
+		return trace.AccessDenied("only the session initiator %v can terminate the session", session.initiator)
+	}
+
+	message := fmt.Sprintf("\r\nSession terminated by %v.\r\n", ctx.Identity.TeleportUser)
+	if _, err := session.switchWriter.WriteUnconditional([]byte(message)); err != nil {
+		s.log.Warnf("Failed to broadcast terminate message: %v.", err)
+	}
+
+	// Emit session terminate event to both the Audit Log as well as over the
+	// "x-teleport-event" channel in the SSH connection.
+	s.emitKickTerminateEvent(ctx, session, events.SessionTerminateEvent, events.SessionTerminateCode, apievents.UserMetadata{
+		User:         ctx.Identity.TeleportUser,
+		Login:        ctx.Identity.Login,
+		Impersonator: ctx.Identity.Impersonator,
+	})
+
+	// Closing the session kills the shell which disconnects all parties.
+	return trace.Wrap(session.Close())
+}
+
+// emitKickTerminateEvent emits a session kick or terminate event to both the
+// Audit Log as well as sending a "x-teleport-event" global request on the SSH
+// connection. The events reuse the session leave wire message, they carry the
+// same server, session and user metadata and no extra fields.
+func (s *SessionRegistry) emitKickTerminateEvent(ctx *ServerContext, session *session, eventType, eventCode string, user apievents.UserMetadata) {
+	event := &apievents.SessionLeave{
+		Metadata: apievents.Metadata{
+			Type:        eventType,
+			Code:        eventCode,
+			ClusterName: ctx.ClusterName,
+		},
+		ServerMetadata: apievents.ServerMetadata{
+			ServerID:        ctx.srv.HostUUID(),
+			ServerLabels:    ctx.srv.GetInfo().GetAllLabels(),
+			ServerNamespace: s.srv.GetNamespace(),
+			ServerHostname:  s.srv.GetInfo().GetHostname(),
+			ServerAddr:      ctx.ServerConn.LocalAddr().String(),
+		},
+		SessionMetadata: apievents.SessionMetadata{
+			SessionID: session.ID(),
+		},
+		UserMetadata: user,
+	}
+
+	// Emit the event to the Audit Log.
+	if err := session.recorder.EmitAuditEvent(ctx.srv.Context(), event); err != nil {
+		s.log.WithError(err).Warnf("Failed to emit %v event.", eventType)
+	}
+
+	// Notify all members of the party about the forced removal over the
+	// "x-teleport-event" channel.
+	for _, p := range session.getParties() {
+		eventPayload, err := json.Marshal(event)
+		if err != nil {
+			s.log.Warnf("Unable to marshal %v for %v: %v.", eventType, p.sconn.RemoteAddr(), err)
+			continue
+		}
+		_, _, err = p.sconn.SendRequest(teleport.SessionEvent, false, eventPayload)
+		if err != nil {
+			s.log.Warnf("Unable to send %v to %v: %v.", eventType, p.sconn.RemoteAddr(), err)
+			continue
+		}
+		s.log.Debugf("Sent %v to %v.", eventType, p.sconn.RemoteAddr())
+	}
+}
+
+// emitPauseResumeEvent emits a session pause or resume event to both the
+// Audit Log as well as sending a "x-teleport-event" global request on the SSH
+// connection. The events reuse the session join wire message, they carry the
+// same server, session and user metadata and no extra fields.
+func (s *SessionRegistry) emitPauseResumeEvent(ctx *ServerContext, session *session, eventType, eventCode string) {
+	event := &apievents.SessionJoin{
+		Metadata: apievents.Metadata{
+			Type:        eventType,
+			Code:        eventCode,
+			ClusterName: ctx.ClusterName,
+		},
+		ServerMetadata: apievents.ServerMetadata{
+			ServerID:        ctx.srv.HostUUID(),
+			ServerLabels:    ctx.srv.GetInfo().GetAllLabels(),
+			ServerNamespace: s.srv.GetNamespace(),
+			ServerHostname:  s.srv.GetInfo().GetHostname(),
+			ServerAddr:      ctx.ServerConn.LocalAddr().String(),
+		},
+		SessionMetadata: apievents.SessionMetadata{
+			SessionID: session.ID(),
+		},
+		UserMetadata: apievents.UserMetadata{
+			User:         ctx.Identity.TeleportUser,
+			Login:        ctx.Identity.Login,
+			Impersonator: ctx.Identity.Impersonator,
+		},
+		ConnectionMetadata: apievents.ConnectionMetadata{
+			RemoteAddr: ctx.ServerConn.RemoteAddr().String(),
+		},
+	}
+	// Local address only makes sense for non-tunnel nodes.
+	if !ctx.srv.UseTunnel() {
+		event.ConnectionMetadata.LocalAddr = ctx.ServerConn.LocalAddr().String()
+	}
+
+	// Emit the event to the Audit Log.
+	if err := session.recorder.EmitAuditEvent(ctx.srv.Context(), event); err != nil {
+		s.log.WithError(err).Warnf("Failed to emit %v event.", eventType)
+	}
+
+	// Notify all members of the party that the session was paused or resumed
+	// over the "x-teleport-event" channel.
+	for _, p := range session.getParties() {
+		eventPayload, err := json.Marshal(event)
+		if err != nil {
+			s.log.Warnf("Unable to marshal %v for %v: %v.", eventType, p.sconn.RemoteAddr(), err)
+			continue
+		}
+		_, _, err = p.sconn.SendRequest(teleport.SessionEvent, false, eventPayload)
+		if err != nil {
+			s.log.Warnf("Unable to send %v to %v: %v.", eventType, p.sconn.RemoteAddr(), err)
+			continue
+		}
+		s.log.Debugf("Sent %v to %v.", eventType, p.sconn.RemoteAddr())
+	}
+}
+
+// WatchSession attaches w to an active session as a read-only observer: the
+// recent terminal output is replayed to it and all subsequent output is
+// mirrored to it through the same multi-writer that serves session parties.
+// The returned release function detaches the observer, done is closed when
+// the watched session ends. The observer never blocks or terminates the
+// session, write errors only detach it.
+func (s *SessionRegistry) WatchSession(id rsession.ID, login string, w io.WriteCloser) (release func(), done <-chan bool, err error) {
+	s.mu.Lock()
+	sess, found := s.findSessionLocked(id)
+	s.mu.Unlock()
+	if !found {
+		return nil, nil, trace.NotFound("session %v not found", id)
+	}
+
+	// The same restriction session parties are under: the observer has to be
+	// logged in as the user running the session.
+	if login != sess.login {
+		return nil, nil, trace.AccessDenied(
+			"can't watch session %v running as %v while logged in as %v",
+			id, sess.login, login)
+	}
+
+	// Write the last chunk first so the observer won't stare at a blank
+	// screen, the same way newly joined parties are caught up.
+	if _, err := w.Write(sess.writer.getRecentWrites()); err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+
+	watcherID := fmt.Sprintf("watcher-%v", rsession.NewID())
+	sess.writer.addWriter(watcherID, w, false)
+	s.log.Infof("Attached observer %v to session %v.", watcherID, id)
+
+	release = func() {
+		sess.writer.deleteWriter(watcherID)
+		s.log.Infof("Detached observer %v from session %v.", watcherID, id)
+	}
+	return release, sess.closeC, nil
+}
+
 func (s *SessionRegistry) broadcastResult(sid rsession.ID, r ExecResult) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -494,6 +835,26 @@ type session struct {
 	// this writer is used to broadcast terminal I/O to different clients
 	writer *multiWriter
 
+	// switchWriter wraps writer and allows terminal output of the session to
+	// be paused and resumed, buffering output produced while it is paused.
+	switchWriter *SwitchWriter
+
+	// breakReaders hold the input readers of connected parties keyed by the
+	// party ID so terminal input can be paused together with the output.
+	breakReaders map[rsession.ID]*BreakReader
+
+	// throttleIn throttles the terminal input of all parties through a
+	// shared budget when the roles limit the session bandwidth.
+	throttleIn io.Writer
+
+	// bandwidthLimit is the maximum number of bytes per second of terminal
+	// data transferred in each direction of the session, zero meaning the
+	// bandwidth is not limited.
+	bandwidthLimit int64
+
+	// paused indicates the session is currently paused.
+	paused bool
+
 	// parties is the set of current connected clients/users. This map may grow
 	// and shrink as members join and leave the session.
 	parties map[rsession.ID]*party
@@ -521,6 +882,11 @@ type session struct {
 	// login stores the login of the initial session creator
 	login string
 
+	// initiator stores the Teleport user that created the session. The
+	// initiator acts as the session moderator and can forcibly remove
+	// participants or terminate the session.
+	initiator string
+
 	closeOnce sync.Once
 
 	recorder events.StreamWriter
@@ -589,6 +955,8 @@ func newSession(id rsession.ID, r *SessionRegistry, ctx *ServerContext) (*sessio
 		r.log.Errorf("Failed to create new session: %v.", err)
 	}
 
+	bandwidthLimit := ctx.Identity.RoleSet.MaxSessionBandwidth()
+
 	sess := &session{
 		log: logrus.WithFields(logrus.Fields{
 			trace.Component: teleport.Component(teleport.ComponentSession, r.srv.Component()),
@@ -598,11 +966,19 @@ func newSession(id rsession.ID, r *SessionRegistry, ctx *ServerContext) (*sessio
 		parties:      make(map[rsession.ID]*party),
 		participants: make(map[rsession.ID]*party),
 		writer:       newMultiWriter(),
+		breakReaders: make(map[rsession.ID]*BreakReader),
 		login:        ctx.Identity.Login,
+		initiator:    ctx.Identity.TeleportUser,
 		closeC:       make(chan bool),
 		lingerTTL:    defaults.SessionIdlePeriod,
 		startTime:    startTime,
 		serverCtx:    ctx.srv.Context(),
+
+		bandwidthLimit: bandwidthLimit,
+	}
+	sess.switchWriter, err = NewSwitchWriter(sess.writer, 0, SwitchWriterModeBackpressure)
+	if err != nil {
+		return nil, trace.Wrap(err)
 	}
 	return sess, nil
 }
@@ -638,6 +1014,11 @@ func (s *session) Close() error {
 		// because of closeOnce
 		go func() {
 			s.log.Infof("Closing session %v.", s.id)
+			// Flush any output buffered while the session was paused so the
+			// PTY copy goroutine is not left blocked on a full buffer.
+			if err := s.switchWriter.On(); err != nil {
+				s.log.Debugf("Failed to flush paused session output: %v", err)
+			}
 			if s.term != nil {
 				s.term.Close()
 			}
@@ -718,16 +1099,19 @@ func (s *session) startInteractive(ch ssh.Channel, ctx *ServerContext) error {
 
 	// Open a BPF recording session. If BPF was not configured, not available,
 	// or running in a recording proxy, OpenSession is a NOP.
+	commandAllow, commandDeny := ctx.Identity.RoleSet.CommandFilters()
 	sessionContext := &bpf.SessionContext{
-		Context:   ctx.srv.Context(),
-		PID:       s.term.PID(),
-		Emitter:   s.recorder,
-		Namespace: ctx.srv.GetNamespace(),
-		SessionID: s.id.String(),
-		ServerID:  ctx.srv.HostUUID(),
-		Login:     ctx.Identity.Login,
-		User:      ctx.Identity.TeleportUser,
-		Events:    ctx.Identity.RoleSet.EnhancedRecordingSet(),
+		Context:      ctx.srv.Context(),
+		PID:          s.term.PID(),
+		Emitter:      s.recorder,
+		Namespace:    ctx.srv.GetNamespace(),
+		SessionID:    s.id.String(),
+		ServerID:     ctx.srv.HostUUID(),
+		Login:        ctx.Identity.Login,
+		User:         ctx.Identity.TeleportUser,
+		Events:       ctx.Identity.RoleSet.EnhancedRecordingSet(),
+		CommandAllow: commandAllow,
+		CommandDeny:  commandDeny,
 	}
 	cgroupID, err := ctx.srv.GetBPF().OpenSession(sessionContext)
 	if err != nil {
@@ -793,11 +1177,18 @@ func (s *session) startInteractive(ch ssh.Channel, ctx *ServerContext) error {
 	// and output of the session (because input is echoed to stdout in the pty).
 	// the writer contains multiple writers: the session logger and a direct
 	// connection to members of the "party" (other people in the session).
+	// The output is throttled if the roles limit the session bandwidth.
+	// Warnings broadcast by the server itself go directly through the
+	// switch writer and are not throttled.
+	throttleOut := NewThrottledWriter(s.switchWriter, s.bandwidthLimit)
+
 	s.term.AddParty(1)
 	go func() {
 		defer s.term.AddParty(-1)
 
-		_, err := io.Copy(s.writer, s.term.PTY())
+		// The copy goes through the switch writer so terminal output can be
+		// paused and resumed while the underlying writers stay untouched.
+		_, err := io.Copy(throttleOut, s.term.PTY())
 		s.log.Debugf("Copying from PTY to writer completed with error %v.", err)
 
 		// once everything has been copied, notify the goroutine below. if this code
@@ -860,9 +1251,74 @@ func (s *session) startInteractive(ch ssh.Channel, ctx *ServerContext) error {
 			s.log.Debugf("Failed killing the shell: %v", err)
 		}
 	}()
+
+	// terminate the session once the wall-clock limit from the user's
+	// roles is reached.
+	limit, warning := ctx.Identity.RoleSet.MaxSessionDuration()
+	go s.enforceSessionDuration(ctx, limit, warning)
+
 	return nil
 }
 
+// sessionDurationWarning is how long before the maximum session duration a
+// warning is written to the terminal if the roles do not configure it.
+const sessionDurationWarning = time.Minute
+
+// enforceSessionDuration terminates the session once the wall-clock limit
+// from the user's roles is reached. A warning is written to the terminal
+// before the limit so the parties have a chance to wrap up.
+func (s *session) enforceSessionDuration(ctx *ServerContext, limit, warning time.Duration) {
+	if limit <= 0 {
+		return
+	}
+	if warning <= 0 || warning >= limit {
+		warning = sessionDurationWarning
+		if warning >= limit {
+			warning = limit / 2
+		}
+	}
+
+	warnTimer := time.NewTimer(limit - warning)
+	defer warnTimer.Stop()
+	select {
+	case <-warnTimer.C:
+	case <-s.closeC:
+		return
+	}
+
+	message := fmt.Sprintf("\r\nSession will be terminated in %v: the maximum session duration of %v is about to be reached.\r\n",
+		warning, limit)
+	if _, err := s.switchWriter.WriteUnconditional([]byte(message)); err != nil {
+		s.log.Warnf("Failed to broadcast session duration warning: %v.", err)
+	}
+
+	termTimer := time.NewTimer(warning)
+	defer termTimer.Stop()
+	select {
+	case <-termTimer.C:
+	case <-s.closeC:
+		return
+	}
+
+	message = fmt.Sprintf("\r\nSession terminated: the maximum session duration of %v has been reached.\r\n", limit)
+	if _, err := s.switchWriter.WriteUnconditional([]byte(message)); err != nil {
+		s.log.Warnf("Failed to broadcast session termination message: %v.", err)
+	}
+
+	// Emit a session terminate event with the duration limit code to both
+	// the Audit Log as well as over the "x-teleport-event" channel in the
+	// SSH connection.
+	s.registry.emitKickTerminateEvent(ctx, s, events.SessionTerminateEvent, events.SessionDurationLimitCode, apievents.UserMetadata{
+		User:  s.initiator,
+		Login: ctx.Identity.Login,
+	})
+
+	// Closing the session kills the shell which disconnects all parties.
+	if err := s.Close(); err != nil {
+		s.log.WithError(err).Warn("Failed to close session after reaching its maximum duration.")
+	}
+}
+
 func (s *session) startExec(channel ssh.Channel, ctx *ServerContext) error {
 	var err error
 
@@ -944,16 +1400,19 @@ func (s *session) startExec(channel ssh.Channel, ctx *ServerContext) error {
 
 	// Open a BPF recording session. If BPF was not configured, not available,
 	// or running in a recording proxy, OpenSession is a NOP.
+	commandAllow, commandDeny := ctx.Identity.RoleSet.CommandFilters()
 	sessionContext := &bpf.SessionContext{
-		Context:   ctx.srv.Context(),
-		PID:       ctx.ExecRequest.PID(),
-		Emitter:   s.recorder,
-		Namespace: ctx.srv.GetNamespace(),
-		SessionID: string(s.id),
-		ServerID:  ctx.srv.HostUUID(),
-		Login:     ctx.Identity.Login,
-		User:      ctx.Identity.TeleportUser,
-		Events:    ctx.Identity.RoleSet.EnhancedRecordingSet(),
+		Context:      ctx.srv.Context(),
+		PID:          ctx.ExecRequest.PID(),
+		Emitter:      s.recorder,
+		Namespace:    ctx.srv.GetNamespace(),
+		SessionID:    string(s.id),
+		ServerID:     ctx.srv.HostUUID(),
+		Login:        ctx.Identity.Login,
+		User:         ctx.Identity.TeleportUser,
+		Events:       ctx.Identity.RoleSet.EnhancedRecordingSet(),
+		CommandAllow: commandAllow,
+		CommandDeny:  commandDeny,
 	}
 	cgroupID, err := ctx.srv.GetBPF().OpenSession(sessionContext)
 	if err != nil {
@@ -1111,6 +1570,7 @@ func (s *session) removeParty(p *party) error {
 	s.removePartyMember(p)
 
 	s.writer.deleteWriter(string(p.id))
+	s.removeBreakReader(p.id)
 
 	return nil
 }
@@ -1243,10 +1703,15 @@ func (s *session) addParty(p *party) error {
 
 	s.log.Infof("New party %v joined session: %v", p.String(), s.id)
 
+	// Wrap the party's input in a break reader so it can be paused and
+	// resumed together with the rest of the session.
+	reader := NewBreakReader(p)
+	s.addBreakReader(p.id, reader)
+
 	// This goroutine keeps pumping party's input into the session.
 	go func() {
 		defer s.term.AddParty(-1)
-		_, err := io.Copy(s.term.PTY(), p)
+		_, err := io.Copy(s.inputWriter(), reader)
 		if err != nil {
 			s.log.Errorf("Party member %v left session %v due an error: %v", p.id, s.id, err)
 		}
@@ -1255,6 +1720,97 @@ func (s *session) addParty(p *party) error {
 	return nil
 }
 
+// inputWriter returns the writer pumping party input into the terminal,
+// creating it on first use so the input of all parties is throttled
+// through a shared budget if the roles limit the session bandwidth.
+func (s *session) inputWriter() io.Writer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.throttleIn == nil {
+		s.throttleIn = NewThrottledWriter(s.term.PTY(), s.bandwidthLimit)
+	}
+	return s.throttleIn
+}
+
+// addBreakReader registers the break reader pumping a party's input so it can
+// be paused together with the session. Occurs under a lock.
+func (s *session) addBreakReader(id rsession.ID, reader *BreakReader) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.breakReaders[id] = reader
+	if s.paused {
+		reader.Pause()
+	}
+}
+
+// removeBreakReader closes and unregisters the break reader of the party with
+// the given ID. Occurs under a lock.
+func (s *session) removeBreakReader(id rsession.ID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if reader, ok := s.breakReaders[id]; ok {
+		reader.Close()
+		delete(s.breakReaders, id)
+	}
+}
+
+// Pause suspends the session: terminal output is held by the switch writer
+// and input of all parties is held by their break readers until Resume is
+// called. All participants are notified with a message broadcast into the
+// session which also records it in the session recording.
+func (s *session) Pause(ctx *ServerContext) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.paused {
+		return trace.BadParameter("session %v is already paused", s.id)
+	}
+
+	s.switchWriter.Off()
+	for _, reader := range s.breakReaders {
+		reader.Pause()
+	}
+	s.paused = true
+
+	s.log.Debugf("Session %v was paused by %v.", s.id, ctx.Identity.TeleportUser)
+	message := fmt.Sprintf("\r\nSession paused by %v. Terminal input and output are suspended until the session is resumed.\r\n", ctx.Identity.TeleportUser)
+	if _, err := s.switchWriter.WriteUnconditional([]byte(message)); err != nil {
+		s.log.Warnf("Failed to broadcast pause message: %v.", err)
+	}
+	return nil
+}
+
+// Resume resumes a previously paused session, flushing terminal output
+// buffered while the session was paused and releasing the input of all
+// parties. If the flush fails the session stays paused so Resume can be
+// retried.
+func (s *session) Resume(ctx *ServerContext) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.paused {
+		return trace.BadParameter("session %v is not paused", s.id)
+	}
+
+	message := fmt.Sprintf("\r\nSession resumed by %v.\r\n", ctx.Identity.TeleportUser)
+	if _, err := s.switchWriter.WriteUnconditional([]byte(message)); err != nil {
+		s.log.Warnf("Failed to broadcast resume message: %v.", err)
+	}
+
+	if err := s.switchWriter.On(); err != nil {
+		return trace.Wrap(err)
+	}
+	for _, reader := range s.breakReaders {
+		reader.Resume()
+	}
+	s.paused = false
+
+	s.log.Debugf("Session %v was resumed by %v.", s.id, ctx.Identity.TeleportUser)
+	return nil
+}
+
 func (s *session) join(ch ssh.Channel, req *ssh.Request, ctx *ServerContext) (*party, error) {
 	p := newParty(s, ch, ctx)
 	if err := s.addParty(p); err != nil {
@@ -0,0 +1,189 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package srv
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+
+	"github.com/gravitational/trace"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/gravitational/teleport"
+	apievents "github.com/gravitational/teleport/api/types/events"
+	"github.com/gravitational/teleport/lib/events"
+	"github.com/gravitational/teleport/lib/sshutils/sftp"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// SFTPSubsystem serves the SFTP subsystem by re-executing Teleport with the
+// "sftp" sub-command as the login user, the same way exec requests are
+// handled. The child reports every completed file transfer back over a pipe
+// so it can be emitted as an audit event with the path, size, direction and
+// checksum of the transfer.
+type SFTPSubsystem struct {
+	cmd   *exec.Cmd
+	errCh chan error
+}
+
+// NewSFTPSubsystem returns a new SFTP subsystem.
+func NewSFTPSubsystem() *SFTPSubsystem {
+	return &SFTPSubsystem{
+		errCh: make(chan error, 1),
+	}
+}
+
+// Start launches the re-executed child serving the SFTP protocol on the
+// channel.
+func (s *SFTPSubsystem) Start(sconn *ssh.ServerConn, ch ssh.Channel, req *ssh.Request, ctx *ServerContext) error {
+	// File transfers can be denied by role independently of shell access.
+	if ctx.Identity.RoleSet.DenyFileTransfers() {
+		return trace.AccessDenied("file transfers are not allowed by role for user %q",
+			ctx.Identity.TeleportUser)
+	}
+
+	// The audit pipe carries per-file transfer records from the child.
+	auditr, auditw, err := os.Pipe()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	cmd, err := ConfigureCommand(ctx)
+	if err != nil {
+		auditr.Close()
+		auditw.Close()
+		return trace.Wrap(err)
+	}
+	// ConfigureCommand prepares an exec re-execution by default, switch the
+	// sub-command and hand the child the audit pipe in addition to the
+	// command and continue pipes.
+	cmd.Args = []string{cmd.Path, teleport.SFTPSubCommand}
+	cmd.ExtraFiles = append(cmd.ExtraFiles, auditw)
+
+	// Connect the channel to std{in,out,err} of the child, the SFTP protocol
+	// itself is spoken on std{in,out}.
+	cmd.Stdout = ch
+	cmd.Stderr = ch.Stderr()
+	inputWriter, err := cmd.StdinPipe()
+	if err != nil {
+		auditr.Close()
+		auditw.Close()
+		return trace.Wrap(err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		auditr.Close()
+		auditw.Close()
+		return trace.Wrap(err)
+	}
+	s.cmd = cmd
+
+	// Close the parent's copy of the child end of the audit pipe so reads
+	// terminate once the child exits.
+	auditw.Close()
+
+	// Signal the child to continue, the SFTP subsystem is not placed in a
+	// cgroup for enhanced session recording the way exec requests are.
+	if ctx.contw != nil {
+		ctx.contw.Close()
+		ctx.contw = nil
+	}
+
+	go func() {
+		if _, err := io.Copy(inputWriter, ch); err != nil {
+			log.Warnf("Failed to forward data from SSH channel to SFTP child: %v.", err)
+		}
+		inputWriter.Close()
+	}()
+	go s.emitAuditEvents(ctx, auditr)
+	go func() {
+		s.errCh <- cmd.Wait()
+	}()
+
+	return nil
+}
+
+// Wait blocks until the child serving the subsystem exits.
+func (s *SFTPSubsystem) Wait() error {
+	return trace.Wrap(<-s.errCh)
+}
+
+// emitAuditEvents reads transfer records reported by the child over the
+// audit pipe and emits them to the audit log.
+func (s *SFTPSubsystem) emitAuditEvents(ctx *ServerContext, auditr *os.File) {
+	defer auditr.Close()
+
+	scanner := bufio.NewScanner(auditr)
+	for scanner.Scan() {
+		var transfer sftp.FileTransfer
+		if err := json.Unmarshal(scanner.Bytes(), &transfer); err != nil {
+			log.Warnf("Failed to decode SFTP transfer record: %v.", err)
+			continue
+		}
+
+		// SFTP transfers reuse the scp wire message. It has no dedicated
+		// fields for the transfer size and checksum, so they are recorded
+		// in the command field alongside the dedicated path and action.
+		event := &apievents.SCP{
+			Metadata: apievents.Metadata{
+				Type:        events.SFTPEvent,
+				ClusterName: ctx.ClusterName,
+			},
+			ServerMetadata: apievents.ServerMetadata{
+				ServerID:        ctx.srv.HostUUID(),
+				ServerNamespace: ctx.srv.GetNamespace(),
+			},
+			SessionMetadata: apievents.SessionMetadata{
+				SessionID: string(ctx.SessionID()),
+				WithMFA:   ctx.Identity.Certificate.Extensions[teleport.CertExtensionMFAVerified],
+			},
+			UserMetadata: apievents.UserMetadata{
+				User:         ctx.Identity.TeleportUser,
+				Login:        ctx.Identity.Login,
+				Impersonator: ctx.Identity.Impersonator,
+			},
+			ConnectionMetadata: apievents.ConnectionMetadata{
+				RemoteAddr: ctx.ServerConn.RemoteAddr().String(),
+				LocalAddr:  ctx.ServerConn.LocalAddr().String(),
+			},
+			CommandMetadata: apievents.CommandMetadata{
+				Command: fmt.Sprintf("sftp %v %v (%v bytes, sha256 %v)",
+					transfer.Direction, transfer.Path, transfer.Bytes, transfer.SHA256),
+			},
+			Path:   transfer.Path,
+			Action: transfer.Direction,
+		}
+		switch transfer.Direction {
+		case sftp.DirectionUpload:
+			event.Code = events.SFTPUploadCode
+		case sftp.DirectionDownload:
+			event.Code = events.SFTPDownloadCode
+		}
+
+		if err := ctx.srv.EmitAuditEvent(ctx.srv.Context(), event); err != nil {
+			log.WithError(err).Warn("Failed to emit sftp event.")
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		log.Warnf("Failed to read SFTP transfer records: %v.", err)
+	}
+}
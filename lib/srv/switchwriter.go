@@ -0,0 +1,198 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package srv
+
+import (
+	"io"
+	"sync"
+
+	"github.com/gravitational/teleport"
+	"github.com/gravitational/teleport/lib/utils"
+
+	"github.com/gravitational/trace"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	switchWriterBufferedBytes = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: teleport.MetricSwitchWriterBufferedBytes,
+			Help: "Number of bytes currently buffered by switched off session writers",
+		},
+	)
+
+	switchWriterDroppedBytes = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: teleport.MetricSwitchWriterDroppedBytes,
+			Help: "Number of bytes dropped by switched off session writers",
+		},
+	)
+
+	switchWriterCollectors = []prometheus.Collector{switchWriterBufferedBytes, switchWriterDroppedBytes}
+)
+
+// SwitchWriterMode determines what happens to writes when a switched off
+// writer's internal buffer is full.
+type SwitchWriterMode int
+
+const (
+	// SwitchWriterModeDropOldest drops the oldest buffered data to make
+	// room for new writes, keeping the most recent output.
+	SwitchWriterModeDropOldest SwitchWriterMode = iota
+	// SwitchWriterModeBackpressure blocks writes until buffer space frees
+	// up or the writer is switched back on.
+	SwitchWriterModeBackpressure
+)
+
+// switchWriterMaxBuffer is the default maximum amount of data buffered
+// while the writer is switched off.
+const switchWriterMaxBuffer = 256 * 1024
+
+// SwitchWriter is an io.Writer that can be switched off, buffering writes
+// up to a configurable limit until it is switched back on, used to pause
+// terminal output of a live session.
+//
+// The buffer is bounded: depending on the configured mode a full buffer
+// either drops the oldest buffered data or blocks writers, so a session
+// that stays paused cannot consume unbounded memory.
+type SwitchWriter struct {
+	// writer is the underlying writer.
+	writer io.Writer
+	// cond guards the fields below and signals buffer space and switch
+	// state changes to blocked writes.
+	cond *sync.Cond
+	// buffer holds data written while the writer is switched off, capped
+	// at maxBuffer.
+	buffer []byte
+	// maxBuffer is the internal buffer size limit.
+	maxBuffer int
+	// mode determines the behavior when the buffer is full.
+	mode SwitchWriterMode
+	// on indicates whether writes currently pass through.
+	on bool
+}
+
+// NewSwitchWriter returns a new switched on SwitchWriter wrapping the
+// provided writer. Passing zero maxBuffer uses the default buffer limit.
+func NewSwitchWriter(writer io.Writer, maxBuffer int, mode SwitchWriterMode) (*SwitchWriter, error) {
+	err := utils.RegisterPrometheusCollectors(switchWriterCollectors...)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if maxBuffer < 0 {
+		return nil, trace.BadParameter("maxBuffer must not be negative")
+	}
+	if maxBuffer == 0 {
+		maxBuffer = switchWriterMaxBuffer
+	}
+	return &SwitchWriter{
+		writer:    writer,
+		cond:      sync.NewCond(&sync.Mutex{}),
+		maxBuffer: maxBuffer,
+		mode:      mode,
+		on:        true,
+	}, nil
+}
+
+// Write passes the data to the underlying writer if the writer is on,
+// otherwise buffers it according to the configured mode.
+func (w *SwitchWriter) Write(p []byte) (int, error) {
+	w.cond.L.Lock()
+	defer w.cond.L.Unlock()
+	written := 0
+	for written < len(p) {
+		if w.on {
+			n, err := w.writer.Write(p[written:])
+			return written + n, trace.Wrap(err)
+		}
+		space := w.maxBuffer - len(w.buffer)
+		if space == 0 {
+			if w.mode == SwitchWriterModeBackpressure {
+				w.cond.Wait()
+				continue
+			}
+			// Drop the oldest buffered data to make room for this write.
+			drop := len(p) - written
+			if drop > w.maxBuffer {
+				drop = w.maxBuffer
+			}
+			w.buffer = w.buffer[:copy(w.buffer, w.buffer[drop:])]
+			switchWriterDroppedBytes.Add(float64(drop))
+			switchWriterBufferedBytes.Sub(float64(drop))
+			continue
+		}
+		n := len(p) - written
+		if n > space {
+			n = space
+		}
+		w.buffer = append(w.buffer, p[written:written+n]...)
+		switchWriterBufferedBytes.Add(float64(n))
+		written += n
+	}
+	return written, nil
+}
+
+// WriteUnconditional writes directly to the underlying writer regardless of
+// the switch state, bypassing the internal buffer. It is used to deliver out
+// of band notifications, such as pause and resume notices, while regular
+// output is switched off.
+func (w *SwitchWriter) WriteUnconditional(p []byte) (int, error) {
+	w.cond.L.Lock()
+	defer w.cond.L.Unlock()
+	n, err := w.writer.Write(p)
+	return n, trace.Wrap(err)
+}
+
+// On switches the writer on after flushing data buffered while it was off.
+//
+// If the flush fails, the data that was not flushed remains buffered, the
+// writer stays off and the error is returned so the caller can retry.
+func (w *SwitchWriter) On() error {
+	w.cond.L.Lock()
+	defer w.cond.L.Unlock()
+	if len(w.buffer) > 0 {
+		n, err := w.writer.Write(w.buffer)
+		if n > 0 {
+			w.buffer = append(w.buffer[:0], w.buffer[n:]...)
+			switchWriterBufferedBytes.Sub(float64(n))
+			// Wake writes blocked on a full buffer.
+			w.cond.Broadcast()
+		}
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		w.buffer = nil
+	}
+	w.on = true
+	w.cond.Broadcast()
+	return nil
+}
+
+// Off switches the writer off, buffering subsequent writes until it is
+// switched back on.
+func (w *SwitchWriter) Off() {
+	w.cond.L.Lock()
+	defer w.cond.L.Unlock()
+	w.on = false
+}
+
+// BufferedBytes returns the amount of data currently buffered.
+func (w *SwitchWriter) BufferedBytes() int {
+	w.cond.L.Lock()
+	defer w.cond.L.Unlock()
+	return len(w.buffer)
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package srv
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/gravitational/trace"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestSwitchWriterPassthrough verifies writes pass through a switched on writer.
+func TestSwitchWriterPassthrough(t *testing.T) {
+	var buffer bytes.Buffer
+	writer, err := NewSwitchWriter(&buffer, 0, SwitchWriterModeDropOldest)
+	require.NoError(t, err)
+
+	n, err := writer.Write([]byte("hello"))
+	require.NoError(t, err)
+	require.Equal(t, 5, n)
+	require.Equal(t, "hello", buffer.String())
+	require.Equal(t, 0, writer.BufferedBytes())
+}
+
+// TestSwitchWriterBuffering verifies writes are buffered while the writer is
+// off and flushed in order when it is switched back on.
+func TestSwitchWriterBuffering(t *testing.T) {
+	var buffer bytes.Buffer
+	writer, err := NewSwitchWriter(&buffer, 0, SwitchWriterModeDropOldest)
+	require.NoError(t, err)
+
+	writer.Off()
+	_, err = writer.Write([]byte("hello, "))
+	require.NoError(t, err)
+	_, err = writer.Write([]byte("world"))
+	require.NoError(t, err)
+	require.Empty(t, buffer.String())
+	require.Equal(t, 12, writer.BufferedBytes())
+
+	require.NoError(t, writer.On())
+	require.Equal(t, "hello, world", buffer.String())
+	require.Equal(t, 0, writer.BufferedBytes())
+}
+
+// TestSwitchWriterDropOldest verifies the oldest buffered data is dropped
+// when the buffer limit is reached.
+func TestSwitchWriterDropOldest(t *testing.T) {
+	var buffer bytes.Buffer
+	writer, err := NewSwitchWriter(&buffer, 5, SwitchWriterModeDropOldest)
+	require.NoError(t, err)
+
+	writer.Off()
+	_, err = writer.Write([]byte("hello"))
+	require.NoError(t, err)
+	_, err = writer.Write([]byte("world"))
+	require.NoError(t, err)
+	require.Equal(t, 5, writer.BufferedBytes())
+
+	require.NoError(t, writer.On())
+	require.Equal(t, "world", buffer.String())
+}
+
+// TestSwitchWriterBackpressure verifies writes block on a full buffer in
+// backpressure mode until the writer is switched back on.
+func TestSwitchWriterBackpressure(t *testing.T) {
+	var buffer bytes.Buffer
+	writer, err := NewSwitchWriter(&buffer, 5, SwitchWriterModeBackpressure)
+	require.NoError(t, err)
+
+	writer.Off()
+	_, err = writer.Write([]byte("hello"))
+	require.NoError(t, err)
+
+	doneCh := make(chan error, 1)
+	go func() {
+		_, err := writer.Write([]byte("world"))
+		doneCh <- err
+	}()
+	select {
+	case <-doneCh:
+		t.Fatal("Write returned while the buffer was full.")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	require.NoError(t, writer.On())
+	select {
+	case err := <-doneCh:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for blocked write to complete.")
+	}
+	require.Equal(t, "helloworld", buffer.String())
+}
+
+// TestSwitchWriterWriteUnconditional verifies unconditional writes bypass
+// the buffer of a switched off writer.
+func TestSwitchWriterWriteUnconditional(t *testing.T) {
+	var buffer bytes.Buffer
+	writer, err := NewSwitchWriter(&buffer, 0, SwitchWriterModeDropOldest)
+	require.NoError(t, err)
+
+	writer.Off()
+	_, err = writer.Write([]byte("hello"))
+	require.NoError(t, err)
+
+	_, err = writer.WriteUnconditional([]byte("notice"))
+	require.NoError(t, err)
+	require.Equal(t, "notice", buffer.String())
+
+	require.NoError(t, writer.On())
+	require.Equal(t, "noticehello", buffer.String())
+}
+
+// TestSwitchWriterPartialFlush verifies data that failed to flush on On()
+// remains buffered and is flushed by a subsequent On().
+func TestSwitchWriterPartialFlush(t *testing.T) {
+	failing := &failingWriter{failAfter: 5}
+	writer, err := NewSwitchWriter(failing, 0, SwitchWriterModeDropOldest)
+	require.NoError(t, err)
+
+	writer.Off()
+	_, err = writer.Write([]byte("hello, world"))
+	require.NoError(t, err)
+
+	// First flush fails partway, the remainder should stay buffered.
+	require.Error(t, writer.On())
+	require.Equal(t, 7, writer.BufferedBytes())
+
+	// Second flush delivers the rest.
+	failing.failAfter = -1
+	require.NoError(t, writer.On())
+	require.Equal(t, 0, writer.BufferedBytes())
+	require.Equal(t, "hello, world", failing.buffer.String())
+}
+
+// failingWriter writes failAfter bytes and then starts returning errors,
+// negative failAfter disables failures.
+type failingWriter struct {
+	buffer    bytes.Buffer
+	failAfter int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.failAfter < 0 {
+		return w.buffer.Write(p)
+	}
+	if len(p) > w.failAfter {
+		p = p[:w.failAfter]
+		n, _ := w.buffer.Write(p)
+		w.failAfter -= n
+		return n, trace.ConnectionProblem(nil, "write failed")
+	}
+	w.failAfter -= len(p)
+	return w.buffer.Write(p)
+}
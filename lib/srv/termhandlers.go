@@ -136,6 +136,19 @@ func (t *TermHandlers) HandleWinChange(ch ssh.Channel, req *ssh.Request, ctx *Se
 	return nil
 }
 
+// HandlePause handles requests of type "pause@goteleport.com" which suspend
+// terminal input and output of an interactive session until a resume request
+// is received.
+func (t *TermHandlers) HandlePause(ch ssh.Channel, req *ssh.Request, ctx *ServerContext) error {
+	return trace.Wrap(t.SessionRegistry.PauseSession(ctx))
+}
+
+// HandleResume handles requests of type "resume@goteleport.com" which resume
+// a previously paused session.
+func (t *TermHandlers) HandleResume(ch ssh.Channel, req *ssh.Request, ctx *ServerContext) error {
+	return trace.Wrap(t.SessionRegistry.ResumeSession(ctx))
+}
+
 func parseExecRequest(req *ssh.Request, ctx *ServerContext) (Exec, error) {
 	var err error
 
@@ -0,0 +1,115 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package srv
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// throttle is a token bucket limiting the number of bytes per second that
+// may pass through it. The bucket holds up to one second worth of tokens so
+// short bursts are not delayed.
+type throttle struct {
+	mu        sync.Mutex
+	rate      int64
+	allowance float64
+	last      time.Time
+}
+
+func newThrottle(bytesPerSecond int64) *throttle {
+	return &throttle{
+		rate:      bytesPerSecond,
+		allowance: float64(bytesPerSecond),
+		last:      time.Now(),
+	}
+}
+
+// wait blocks until n bytes may pass the throttle.
+func (t *throttle) wait(n int) {
+	t.mu.Lock()
+	now := time.Now()
+	t.allowance += now.Sub(t.last).Seconds() * float64(t.rate)
+	t.last = now
+	if t.allowance > float64(t.rate) {
+		t.allowance = float64(t.rate)
+	}
+	t.allowance -= float64(n)
+	var sleep time.Duration
+	if t.allowance < 0 {
+		sleep = time.Duration(-t.allowance / float64(t.rate) * float64(time.Second))
+	}
+	t.mu.Unlock()
+
+	if sleep > 0 {
+		time.Sleep(sleep)
+	}
+}
+
+// ThrottledWriter wraps an io.Writer and limits the rate at which bytes are
+// written through it.
+type ThrottledWriter struct {
+	w        io.Writer
+	throttle *throttle
+}
+
+// NewThrottledWriter returns a writer that writes to w at no more than
+// bytesPerSecond. If bytesPerSecond is zero or negative, w is returned
+// unchanged.
+func NewThrottledWriter(w io.Writer, bytesPerSecond int64) io.Writer {
+	if bytesPerSecond <= 0 {
+		return w
+	}
+	return &ThrottledWriter{
+		w:        w,
+		throttle: newThrottle(bytesPerSecond),
+	}
+}
+
+func (w *ThrottledWriter) Write(b []byte) (int, error) {
+	w.throttle.wait(len(b))
+	return w.w.Write(b)
+}
+
+// ThrottledReader wraps an io.Reader and limits the rate at which bytes are
+// read through it.
+type ThrottledReader struct {
+	r        io.Reader
+	throttle *throttle
+}
+
+// NewThrottledReader returns a reader that reads from r at no more than
+// bytesPerSecond. If bytesPerSecond is zero or negative, r is returned
+// unchanged.
+func NewThrottledReader(r io.Reader, bytesPerSecond int64) io.Reader {
+	if bytesPerSecond <= 0 {
+		return r
+	}
+	return &ThrottledReader{
+		r:        r,
+		throttle: newThrottle(bytesPerSecond),
+	}
+}
+
+func (r *ThrottledReader) Read(b []byte) (int, error) {
+	n, err := r.r.Read(b)
+	if n > 0 {
+		r.throttle.wait(n)
+	}
+	return n, err
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package srv
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestThrottledWriter verifies writes are delayed once the one second burst
+// allowance has been used up.
+func TestThrottledWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewThrottledWriter(&buf, 100*1024)
+
+	// Twice the burst allowance has to take at least half a second.
+	start := time.Now()
+	data := make([]byte, 100*1024)
+	for i := 0; i < 2; i++ {
+		n, err := w.Write(data)
+		require.NoError(t, err)
+		require.Equal(t, len(data), n)
+	}
+	require.GreaterOrEqual(t, time.Since(start), 500*time.Millisecond)
+	require.Equal(t, 2*len(data), buf.Len())
+}
+
+// TestThrottledReader verifies reads are delayed once the one second burst
+// allowance has been used up.
+func TestThrottledReader(t *testing.T) {
+	data := make([]byte, 200*1024)
+	r := NewThrottledReader(bytes.NewReader(data), 100*1024)
+
+	start := time.Now()
+	out, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	require.Len(t, out, len(data))
+	require.GreaterOrEqual(t, time.Since(start), 500*time.Millisecond)
+}
+
+// TestThrottleDisabled verifies a zero limit leaves the reader and writer
+// unchanged.
+func TestThrottleDisabled(t *testing.T) {
+	var buf bytes.Buffer
+	require.Equal(t, &buf, NewThrottledWriter(&buf, 0))
+	reader := bytes.NewReader(nil)
+	require.Equal(t, reader, NewThrottledReader(reader, 0))
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package srv
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+	"time"
+
+	apievents "github.com/gravitational/teleport/api/types/events"
+	"github.com/gravitational/teleport/lib/events"
+
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+	log "github.com/sirupsen/logrus"
+)
+
+// defaultTrafficReportInterval is how often in-flight session data events
+// are emitted when no interval is configured explicitly.
+const defaultTrafficReportInterval = 5 * time.Minute
+
+// TrafficReporterConfig configures a traffic reporter.
+type TrafficReporterConfig struct {
+	// Context is an external context to stop the reporter
+	Context context.Context
+	// Emitter is events emitter
+	Emitter apievents.Emitter
+	// Entry is a logging entry
+	Entry log.FieldLogger
+	// Clock is a clock, realtime or fixed in tests
+	Clock clockwork.Clock
+	// Interval is how often a summary event is emitted
+	Interval time.Duration
+	// SessionID is an optional session ID to correlate the events with
+	SessionID string
+	// ServerID is a session server ID
+	ServerID string
+	// Namespace is the server namespace
+	Namespace string
+	// Login is linux box login
+	Login string
+	// TeleportUser is a teleport user name
+	TeleportUser string
+	// RemoteAddr is the client remote address
+	RemoteAddr string
+	// LocalAddr is an optional server local address
+	LocalAddr string
+}
+
+// CheckAndSetDefaults checks values and sets defaults
+func (c *TrafficReporterConfig) CheckAndSetDefaults() error {
+	if c.Context == nil {
+		return trace.BadParameter("missing parameter Context")
+	}
+	if c.Emitter == nil {
+		return trace.BadParameter("missing parameter Emitter")
+	}
+	if c.Entry == nil {
+		return trace.BadParameter("missing parameter Entry")
+	}
+	if c.Clock == nil {
+		c.Clock = clockwork.NewRealClock()
+	}
+	if c.Interval == 0 {
+		c.Interval = defaultTrafficReportInterval
+	}
+	return nil
+}
+
+// StartTrafficReporter starts a reporter that periodically emits
+// "session.data" events with the bytes and writes relayed so far in each
+// direction, so anomalously large transfers can be alerted on while the
+// session is still in progress. The final totals are still reported by
+// the caller when the connection closes.
+func StartTrafficReporter(cfg TrafficReporterConfig) (*TrafficReporter, error) {
+	if err := cfg.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	r := &TrafficReporter{
+		cfg:   cfg,
+		start: cfg.Clock.Now(),
+	}
+	go r.run()
+	return r, nil
+}
+
+// TrafficReporter counts the traffic relayed in both directions of a
+// proxied connection and emits periodic summary events.
+type TrafficReporter struct {
+	cfg   TrafficReporterConfig
+	start time.Time
+
+	txBytes   uint64
+	rxBytes   uint64
+	txPackets uint64
+	rxPackets uint64
+}
+
+// WrapTransmitted wraps a writer carrying data transmitted by the client
+// towards the target, counting the bytes and writes that pass through it.
+// Like the final "session.data" report, directions are named from the
+// perspective of the client, not the server.
+func (r *TrafficReporter) WrapTransmitted(w io.Writer) io.Writer {
+	return &countingWriter{w: w, bytes: &r.txBytes, packets: &r.txPackets}
+}
+
+// WrapReceived wraps a writer carrying data received by the client from
+// the target, counting the bytes and writes that pass through it.
+func (r *TrafficReporter) WrapReceived(w io.Writer) io.Writer {
+	return &countingWriter{w: w, bytes: &r.rxBytes, packets: &r.rxPackets}
+}
+
+// Stat returns the number of bytes transmitted and received so far.
+func (r *TrafficReporter) Stat() (txBytes, rxBytes uint64) {
+	return atomic.LoadUint64(&r.txBytes), atomic.LoadUint64(&r.rxBytes)
+}
+
+// run emits a summary event every interval until the context is closed.
+// Intervals during which no data moved are skipped to keep idle sessions
+// from flooding the audit log.
+func (r *TrafficReporter) run() {
+	ticker := r.cfg.Clock.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+	var lastTX, lastRX uint64
+	for {
+		select {
+		case <-ticker.Chan():
+			tx, rx := r.Stat()
+			if tx == lastTX && rx == lastRX {
+				continue
+			}
+			lastTX, lastRX = tx, rx
+			r.report(tx, rx)
+		case <-r.cfg.Context.Done():
+			return
+		}
+	}
+}
+
+// report emits a single in-flight session data event.
+func (r *TrafficReporter) report(txBytes, rxBytes uint64) {
+	event := &apievents.SessionData{
+		Metadata: apievents.Metadata{
+			Type: events.SessionDataEvent,
+			Code: events.SessionDataPeriodicCode,
+		},
+		ServerMetadata: apievents.ServerMetadata{
+			ServerID:        r.cfg.ServerID,
+			ServerNamespace: r.cfg.Namespace,
+		},
+		SessionMetadata: apievents.SessionMetadata{
+			SessionID: r.cfg.SessionID,
+		},
+		UserMetadata: apievents.UserMetadata{
+			User:  r.cfg.TeleportUser,
+			Login: r.cfg.Login,
+		},
+		ConnectionMetadata: apievents.ConnectionMetadata{
+			RemoteAddr: r.cfg.RemoteAddr,
+			LocalAddr:  r.cfg.LocalAddr,
+		},
+		BytesTransmitted:     txBytes,
+		BytesReceived:        rxBytes,
+		PacketsTransmitted:   atomic.LoadUint64(&r.txPackets),
+		PacketsReceived:      atomic.LoadUint64(&r.rxPackets),
+		DurationMilliseconds: r.cfg.Clock.Since(r.start).Milliseconds(),
+	}
+	if err := r.cfg.Emitter.EmitAuditEvent(r.cfg.Context, event); err != nil {
+		r.cfg.Entry.WithError(err).Warn("Failed to emit session data event.")
+	}
+}
+
+// countingWriter counts the bytes and writes passing through a writer.
+type countingWriter struct {
+	w       io.Writer
+	bytes   *uint64
+	packets *uint64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	atomic.AddUint64(c.bytes, uint64(n))
+	atomic.AddUint64(c.packets, 1)
+	return n, err
+}
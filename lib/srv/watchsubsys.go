@@ -0,0 +1,90 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package srv
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/gravitational/trace"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/gravitational/teleport"
+	rsession "github.com/gravitational/teleport/lib/session"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// WatchSubsystem streams the live terminal output of an active session to
+// the requesting channel without joining the session. It is requested as
+// "watch:<session-id>" and is strictly read-only: any input sent by the
+// observer is discarded.
+type WatchSubsystem struct {
+	sessionID rsession.ID
+	registry  *SessionRegistry
+	errCh     chan error
+}
+
+// NewWatchSubsystem parses a "watch:<session-id>" subsystem request.
+func NewWatchSubsystem(name string, registry *SessionRegistry) (*WatchSubsystem, error) {
+	sid, err := rsession.ParseID(strings.TrimPrefix(name, teleport.WatchSubsystemPrefix))
+	if err != nil {
+		return nil, trace.BadParameter("invalid session id in subsystem request %q", name)
+	}
+	return &WatchSubsystem{
+		sessionID: *sid,
+		registry:  registry,
+		errCh:     make(chan error, 1),
+	}, nil
+}
+
+// Start attaches the channel to the watched session as an observer.
+func (w *WatchSubsystem) Start(sconn *ssh.ServerConn, ch ssh.Channel, req *ssh.Request, ctx *ServerContext) error {
+	release, done, err := w.registry.WatchSession(w.sessionID, ctx.Identity.Login, ch)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	// Watching is read-only: drain and discard everything the observer
+	// sends. The copy unblocking doubles as hangup detection.
+	hangupC := make(chan struct{})
+	go func() {
+		defer close(hangupC)
+		if _, err := io.Copy(ioutil.Discard, ch); err != nil {
+			log.Debugf("Session %v observer read loop ended: %v.", w.sessionID, err)
+		}
+	}()
+
+	// Detach the observer once the watched session ends or the observer
+	// hangs up, whichever comes first.
+	go func() {
+		select {
+		case <-done:
+		case <-hangupC:
+		}
+		release()
+		w.errCh <- nil
+	}()
+
+	return nil
+}
+
+// Wait blocks until the observer is detached from the watched session.
+func (w *WatchSubsystem) Wait() error {
+	return trace.Wrap(<-w.errCh)
+}
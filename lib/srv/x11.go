@@ -0,0 +1,361 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package srv
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/gravitational/trace"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/gravitational/teleport/lib/sshutils"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// x11BasePort is the TCP port of X display number zero.
+	x11BasePort = 6000
+
+	// x11DisplayOffset is the first display number used for forwarded X11
+	// displays, same default as OpenSSH. Lower numbers are left for real
+	// displays attached to the node.
+	x11DisplayOffset = 10
+
+	// x11MaxDisplays is the number of display numbers probed for a free
+	// port when setting up forwarding.
+	x11MaxDisplays = 100
+
+	// x11CookieSize is the size in bytes of an X11 authentication cookie.
+	x11CookieSize = 16
+
+	// x11AuthProtocol is the only X11 authentication protocol supported,
+	// same as OpenSSH.
+	x11AuthProtocol = "MIT-MAGIC-COOKIE-1"
+)
+
+// X11ForwardRequestPayload is the payload of an "x11-req" session request,
+// defined in RFC 4254 section 6.3.1.
+type X11ForwardRequestPayload struct {
+	// SingleConnection requests that only a single X11 connection is
+	// forwarded.
+	SingleConnection bool
+	// AuthProtocol is the X11 authentication protocol in use. For trusted
+	// forwarding the client sends the cookie of its real display, for
+	// untrusted forwarding a restricted cookie generated for the session.
+	// The server treats both the same, the distinction is enforced by the
+	// client's X server.
+	AuthProtocol string
+	// AuthCookie is the hex encoded X11 authentication cookie.
+	AuthCookie string
+	// ScreenNumber is the screen number of the client display.
+	ScreenNumber uint32
+}
+
+// X11ChannelRequestPayload is the payload sent when opening an "x11"
+// channel to the client for an inbound X11 connection, defined in RFC 4254
+// section 6.3.2.
+type X11ChannelRequestPayload struct {
+	// OriginatorAddress is the address of the X client that connected.
+	OriginatorAddress string
+	// OriginatorPort is the port of the X client that connected.
+	OriginatorPort uint32
+}
+
+// X11Forwarder serves a proxy X display for a single session. X clients
+// started within the session connect to it, their connections are carried
+// back to the client over "x11" channels.
+//
+// The cookie the client sent in the forwarding request is never written to
+// the node. Instead a fresh fake cookie is installed in the session's
+// Xauthority file, verified on every inbound connection and then replaced
+// with the real cookie before the connection setup is forwarded. This way a
+// cookie read from disk on the node is useless on the client's display.
+type X11Forwarder struct {
+	mu sync.Mutex
+
+	// listener accepts connections from X clients within the session.
+	listener net.Listener
+	// display is the X display address of the listener, e.g. "localhost:10.0".
+	display string
+	// displayNumber is the display number of the listener.
+	displayNumber int
+	// singleConnection indicates only the first connection should be
+	// forwarded.
+	singleConnection bool
+	// served is set once a connection has been forwarded, used to enforce
+	// singleConnection.
+	served bool
+
+	// realCookie is the authentication cookie sent by the client.
+	realCookie []byte
+	// fakeCookie is the authentication cookie installed in the session's
+	// Xauthority file.
+	fakeCookie []byte
+
+	// sconn is the SSH connection "x11" channels are opened on.
+	sconn *ssh.ServerConn
+}
+
+// NewX11Forwarder sets up X11 forwarding for a session: it binds a proxy
+// display on the loopback interface and generates the fake cookie handed to
+// X clients on the node.
+func NewX11Forwarder(ctx *ServerContext, x11Req X11ForwardRequestPayload) (*X11Forwarder, error) {
+	if x11Req.AuthProtocol != x11AuthProtocol {
+		return nil, trace.BadParameter("unsupported X11 authentication protocol %q", x11Req.AuthProtocol)
+	}
+	realCookie, err := hex.DecodeString(x11Req.AuthCookie)
+	if err != nil {
+		return nil, trace.BadParameter("invalid X11 authentication cookie")
+	}
+	fakeCookie := make([]byte, x11CookieSize)
+	if _, err := rand.Read(fakeCookie); err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	// Find a free display number, same probing approach as OpenSSH.
+	var listener net.Listener
+	var displayNumber int
+	for n := x11DisplayOffset; n < x11DisplayOffset+x11MaxDisplays; n++ {
+		listener, err = net.Listen("tcp", fmt.Sprintf("127.0.0.1:%v", x11BasePort+n))
+		if err == nil {
+			displayNumber = n
+			break
+		}
+	}
+	if listener == nil {
+		return nil, trace.LimitExceeded("failed to find a free X11 display number")
+	}
+
+	forwarder := &X11Forwarder{
+		listener:         listener,
+		display:          fmt.Sprintf("localhost:%v.%v", displayNumber, x11Req.ScreenNumber),
+		displayNumber:    displayNumber,
+		singleConnection: x11Req.SingleConnection,
+		realCookie:       realCookie,
+		fakeCookie:       fakeCookie,
+		sconn:            ctx.ServerConn,
+	}
+	return forwarder, nil
+}
+
+// writeXAuthority writes an Xauthority file containing the fake cookie,
+// owned by the login user, and returns its path. A wildcard entry is used
+// so it matches the proxy display regardless of how the X client resolves
+// the display address.
+func (f *X11Forwarder) WriteXAuthority(login string) (string, error) {
+	systemUser, err := user.Lookup(login)
+	if err != nil {
+		return "", trace.ConvertSystemError(err)
+	}
+	uid, err := strconv.Atoi(systemUser.Uid)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	gid, err := strconv.Atoi(systemUser.Gid)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+
+	authDir, err := ioutil.TempDir(os.TempDir(), "teleport-x11-")
+	if err != nil {
+		return "", trace.ConvertSystemError(err)
+	}
+	if err := os.Chown(authDir, uid, gid); err != nil {
+		os.RemoveAll(authDir)
+		return "", trace.ConvertSystemError(err)
+	}
+
+	// An Xauthority entry is family, address, display number, protocol name
+	// and cookie, each length-prefixed with a big-endian uint16. The
+	// wildcard family (0xffff) matches any address.
+	var entry []byte
+	entry = appendXAuthUint16(entry, 0xffff)
+	entry = appendXAuthString(entry, "")
+	entry = appendXAuthString(entry, strconv.Itoa(f.displayNumber))
+	entry = appendXAuthString(entry, x11AuthProtocol)
+	entry = appendXAuthUint16(entry, uint16(len(f.fakeCookie)))
+	entry = append(entry, f.fakeCookie...)
+
+	authFile := filepath.Join(authDir, "xauthority")
+	if err := ioutil.WriteFile(authFile, entry, 0600); err != nil {
+		os.RemoveAll(authDir)
+		return "", trace.ConvertSystemError(err)
+	}
+	if err := os.Chown(authFile, uid, gid); err != nil {
+		os.RemoveAll(authDir)
+		return "", trace.ConvertSystemError(err)
+	}
+	return authFile, nil
+}
+
+func appendXAuthUint16(data []byte, value uint16) []byte {
+	return append(data, byte(value>>8), byte(value))
+}
+
+func appendXAuthString(data []byte, value string) []byte {
+	return append(appendXAuthUint16(data, uint16(len(value))), value...)
+}
+
+// serve accepts connections from X clients until the listener is closed.
+func (f *X11Forwarder) Serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		f.mu.Lock()
+		if f.singleConnection && f.served {
+			f.mu.Unlock()
+			conn.Close()
+			continue
+		}
+		f.served = true
+		f.mu.Unlock()
+
+		go func() {
+			if err := f.handleConnection(conn); err != nil {
+				log.Warnf("Failed to forward X11 connection: %v.", err)
+			}
+		}()
+
+		if f.singleConnection {
+			f.listener.Close()
+		}
+	}
+}
+
+// handleConnection verifies the authentication cookie of an inbound X11
+// connection, rewrites it with the real cookie and splices the connection
+// with an "x11" channel opened to the client.
+func (f *X11Forwarder) handleConnection(conn net.Conn) error {
+	defer conn.Close()
+
+	setup, err := f.rewriteConnectionSetup(conn)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	// Open an "x11" channel back to the client.
+	remoteHost, remotePortString, _ := net.SplitHostPort(conn.RemoteAddr().String())
+	remotePort, _ := strconv.Atoi(remotePortString)
+	payload := X11ChannelRequestPayload{
+		OriginatorAddress: remoteHost,
+		OriginatorPort:    uint32(remotePort),
+	}
+	channel, in, err := f.sconn.OpenChannel(sshutils.X11ChannelRequest, ssh.Marshal(payload))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer channel.Close()
+	go ssh.DiscardRequests(in)
+
+	if _, err := channel.Write(setup); err != nil {
+		return trace.Wrap(err)
+	}
+
+	// Splice the remaining data in both directions.
+	doneCh := make(chan struct{}, 2)
+	go func() {
+		io.Copy(channel, conn)
+		channel.CloseWrite()
+		doneCh <- struct{}{}
+	}()
+	go func() {
+		io.Copy(conn, channel)
+		doneCh <- struct{}{}
+	}()
+	<-doneCh
+	<-doneCh
+	return nil
+}
+
+// rewriteConnectionSetup reads the X11 connection setup message from the
+// connection, verifies the fake cookie and returns the message rewritten
+// with the real cookie.
+//
+// The setup message is a 12 byte header followed by the padded
+// authentication protocol name and cookie, see the X Window System protocol
+// specification, section "Connection Setup".
+func (f *X11Forwarder) rewriteConnectionSetup(conn net.Conn) ([]byte, error) {
+	header := make([]byte, 12)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	var order binary.ByteOrder
+	switch header[0] {
+	case 'B':
+		order = binary.BigEndian
+	case 'l':
+		order = binary.LittleEndian
+	default:
+		return nil, trace.BadParameter("invalid X11 connection setup byte order %q", header[0])
+	}
+	nameLen := int(order.Uint16(header[6:8]))
+	dataLen := int(order.Uint16(header[8:10]))
+
+	trailer := make([]byte, xauthPad(nameLen)+xauthPad(dataLen))
+	if _, err := io.ReadFull(conn, trailer); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	name := string(trailer[:nameLen])
+	data := trailer[xauthPad(nameLen) : xauthPad(nameLen)+dataLen]
+
+	if name != x11AuthProtocol || subtle.ConstantTimeCompare(data, f.fakeCookie) != 1 {
+		return nil, trace.AccessDenied("X11 connection rejected because of wrong authentication")
+	}
+
+	// Rebuild the setup message with the real cookie.
+	setup := make([]byte, 0, len(header)+xauthPad(nameLen)+xauthPad(len(f.realCookie)))
+	setup = append(setup, header...)
+	order.PutUint16(setup[8:10], uint16(len(f.realCookie)))
+	setup = append(setup, trailer[:xauthPad(nameLen)]...)
+	setup = append(setup, f.realCookie...)
+	setup = append(setup, make([]byte, xauthPad(len(f.realCookie))-len(f.realCookie))...)
+	return setup, nil
+}
+
+// Display returns the X display address of the proxy display, suitable for
+// the DISPLAY environment variable.
+func (f *X11Forwarder) Display() string {
+	return f.display
+}
+
+// xauthPad rounds the length up to the four byte padding used in the X11
+// connection setup message.
+func xauthPad(length int) int {
+	return (length + 3) &^ 3
+}
+
+// Close stops accepting X11 connections for the session.
+func (f *X11Forwarder) Close() error {
+	return f.listener.Close()
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package srv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"testing"
+
+	"github.com/gravitational/trace"
+	"github.com/stretchr/testify/require"
+)
+
+// buildConnectionSetup builds an X11 connection setup message carrying the
+// given authentication cookie.
+func buildConnectionSetup(order binary.ByteOrder, orderByte byte, cookie []byte) []byte {
+	header := make([]byte, 12)
+	header[0] = orderByte
+	order.PutUint16(header[2:4], 11) // protocol major version
+	order.PutUint16(header[6:8], uint16(len(x11AuthProtocol)))
+	order.PutUint16(header[8:10], uint16(len(cookie)))
+
+	setup := append([]byte{}, header...)
+	setup = append(setup, x11AuthProtocol...)
+	setup = append(setup, make([]byte, xauthPad(len(x11AuthProtocol))-len(x11AuthProtocol))...)
+	setup = append(setup, cookie...)
+	setup = append(setup, make([]byte, xauthPad(len(cookie))-len(cookie))...)
+	return setup
+}
+
+func TestX11RewriteConnectionSetup(t *testing.T) {
+	fakeCookie := bytes.Repeat([]byte{1}, x11CookieSize)
+	realCookie := bytes.Repeat([]byte{2}, x11CookieSize)
+	forwarder := &X11Forwarder{
+		fakeCookie: fakeCookie,
+		realCookie: realCookie,
+	}
+
+	tests := []struct {
+		name      string
+		order     binary.ByteOrder
+		orderByte byte
+	}{
+		{name: "big endian", order: binary.BigEndian, orderByte: 'B'},
+		{name: "little endian", order: binary.LittleEndian, orderByte: 'l'},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := net.Pipe()
+			defer client.Close()
+			defer server.Close()
+
+			go func() {
+				client.Write(buildConnectionSetup(tt.order, tt.orderByte, fakeCookie))
+			}()
+
+			setup, err := forwarder.rewriteConnectionSetup(server)
+			require.NoError(t, err)
+			require.Equal(t, buildConnectionSetup(tt.order, tt.orderByte, realCookie), setup)
+		})
+	}
+}
+
+func TestX11RewriteConnectionSetupWrongCookie(t *testing.T) {
+	forwarder := &X11Forwarder{
+		fakeCookie: bytes.Repeat([]byte{1}, x11CookieSize),
+		realCookie: bytes.Repeat([]byte{2}, x11CookieSize),
+	}
+
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	go func() {
+		client.Write(buildConnectionSetup(binary.BigEndian, 'B', bytes.Repeat([]byte{3}, x11CookieSize)))
+	}()
+
+	_, err := forwarder.rewriteConnectionSetup(server)
+	require.True(t, trace.IsAccessDenied(err))
+}
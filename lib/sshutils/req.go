@@ -164,6 +164,13 @@ const (
 	// X11ForwardRequest is a request to initiate X11 forwarding.
 	X11ForwardRequest = "x11-req"
 
+	// PauseRequest is a request to pause delivery of terminal input and
+	// output of an interactive session.
+	PauseRequest = "pause@goteleport.com"
+
+	// ResumeRequest is a request to resume a previously paused session.
+	ResumeRequest = "resume@goteleport.com"
+
 	// X11ChannelRequest is the type of an X11 forwarding channel.
 	X11ChannelRequest = "x11"
 )
@@ -0,0 +1,635 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sftp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/gravitational/trace"
+)
+
+// Transfer directions reported in FileTransfer.
+const (
+	// DirectionUpload means the file was written by the client.
+	DirectionUpload = "upload"
+	// DirectionDownload means the file was read by the client.
+	DirectionDownload = "download"
+)
+
+// FileTransfer describes a single completed file transfer, reported when
+// the client closes a file handle.
+type FileTransfer struct {
+	// Path is the path of the transferred file on the server.
+	Path string `json:"path"`
+	// Direction is either "upload" or "download".
+	Direction string `json:"direction"`
+	// Bytes is the number of bytes transferred.
+	Bytes int64 `json:"bytes"`
+	// SHA256 is the hex encoded SHA256 sum of the transferred data, in the
+	// order it was transferred.
+	SHA256 string `json:"sha256"`
+}
+
+// Server implements the server side of the SFTP protocol on a pair of
+// streams, typically the stdin and stdout of a re-executed child process
+// running as the login user. File access is performed directly with the
+// ambient credentials of the process, the operating system enforces
+// permissions.
+//
+// Requests are processed sequentially in the order they are received which
+// is a valid, if not maximally concurrent, implementation of the protocol.
+type Server struct {
+	in  io.Reader
+	out io.Writer
+
+	// onTransfer, if set, is called for every completed file transfer.
+	onTransfer func(FileTransfer)
+
+	files      map[string]*fileHandle
+	dirs       map[string]*dirHandle
+	nextHandle uint64
+}
+
+// fileHandle tracks an open file along with per-direction transfer
+// accounting used to report FileTransfer on close.
+type fileHandle struct {
+	file           *os.File
+	path           string
+	openedForWrite bool
+	appendMode     bool
+	readBytes      int64
+	writeBytes     int64
+	readHash       hash.Hash
+	writeHash      hash.Hash
+}
+
+// dirHandle tracks an open directory being listed.
+type dirHandle struct {
+	dir  *os.File
+	path string
+}
+
+// NewServer returns a new SFTP server reading requests from in and writing
+// responses to out. onTransfer may be nil.
+func NewServer(in io.Reader, out io.Writer, onTransfer func(FileTransfer)) *Server {
+	return &Server{
+		in:         in,
+		out:        out,
+		onTransfer: onTransfer,
+		files:      make(map[string]*fileHandle),
+		dirs:       make(map[string]*dirHandle),
+	}
+}
+
+// Serve processes requests until the client disconnects. A clean disconnect
+// returns nil.
+func (s *Server) Serve() error {
+	defer s.closeAllHandles()
+	for {
+		payload, err := readPacket(s.in)
+		if err != nil {
+			if origErr := trace.Unwrap(err); origErr == io.EOF || origErr == io.ErrUnexpectedEOF {
+				return nil
+			}
+			return trace.Wrap(err)
+		}
+		b := &buffer{data: payload}
+		packetType, err := b.readByte()
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if packetType == packetTypeInit {
+			// Version negotiation: respond with the version this server
+			// speaks, clients fall back to it.
+			if err := writePacket(s.out, appendUint32([]byte{packetTypeVersion}, ProtocolVersion)); err != nil {
+				return trace.Wrap(err)
+			}
+			continue
+		}
+		requestID, err := b.readUint32()
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if err := s.handleRequest(packetType, requestID, b); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+}
+
+// handleRequest dispatches a single request and sends the response. Request
+// level failures are reported to the client as status responses, a returned
+// error means the transport is broken.
+func (s *Server) handleRequest(packetType byte, requestID uint32, b *buffer) error {
+	switch packetType {
+	case packetTypeOpen:
+		return s.handleOpen(requestID, b)
+	case packetTypeClose:
+		return s.handleClose(requestID, b)
+	case packetTypeRead:
+		return s.handleRead(requestID, b)
+	case packetTypeWrite:
+		return s.handleWrite(requestID, b)
+	case packetTypeLstat:
+		return s.handleStat(requestID, b, os.Lstat)
+	case packetTypeStat:
+		return s.handleStat(requestID, b, os.Stat)
+	case packetTypeFstat:
+		return s.handleFstat(requestID, b)
+	case packetTypeSetstat:
+		return s.handleSetstat(requestID, b)
+	case packetTypeFsetstat:
+		return s.handleFsetstat(requestID, b)
+	case packetTypeOpendir:
+		return s.handleOpendir(requestID, b)
+	case packetTypeReaddir:
+		return s.handleReaddir(requestID, b)
+	case packetTypeRemove:
+		return s.handlePathRequest(requestID, b, os.Remove)
+	case packetTypeMkdir:
+		return s.handleMkdir(requestID, b)
+	case packetTypeRmdir:
+		return s.handlePathRequest(requestID, b, os.Remove)
+	case packetTypeRealpath:
+		return s.handleRealpath(requestID, b)
+	case packetTypeRename:
+		return s.handleRename(requestID, b)
+	case packetTypeReadlink:
+		return s.handleReadlink(requestID, b)
+	case packetTypeSymlink:
+		return s.handleSymlink(requestID, b)
+	default:
+		return s.sendStatus(requestID, statusOpUnsupported, fmt.Sprintf("unsupported request type %v", packetType))
+	}
+}
+
+func (s *Server) handleOpen(requestID uint32, b *buffer) error {
+	path, err := b.readString()
+	if err != nil {
+		return s.sendStatus(requestID, statusBadMessage, err.Error())
+	}
+	pflags, err := b.readUint32()
+	if err != nil {
+		return s.sendStatus(requestID, statusBadMessage, err.Error())
+	}
+	attrs, err := b.readAttrs()
+	if err != nil {
+		return s.sendStatus(requestID, statusBadMessage, err.Error())
+	}
+
+	osFlags := 0
+	switch {
+	case pflags&openFlagRead != 0 && pflags&openFlagWrite != 0:
+		osFlags = os.O_RDWR
+	case pflags&openFlagWrite != 0:
+		osFlags = os.O_WRONLY
+	default:
+		osFlags = os.O_RDONLY
+	}
+	if pflags&openFlagAppend != 0 {
+		osFlags |= os.O_APPEND
+	}
+	if pflags&openFlagCreate != 0 {
+		osFlags |= os.O_CREATE
+	}
+	if pflags&openFlagTrunc != 0 {
+		osFlags |= os.O_TRUNC
+	}
+	if pflags&openFlagExcl != 0 {
+		osFlags |= os.O_EXCL
+	}
+
+	permissions := os.FileMode(0644)
+	if attrs.flags&attrFlagPermissions != 0 {
+		permissions = os.FileMode(attrs.permissions).Perm()
+	}
+
+	file, err := os.OpenFile(path, osFlags, permissions)
+	if err != nil {
+		return s.sendError(requestID, err)
+	}
+	handle := strconv.FormatUint(s.nextHandle, 10)
+	s.nextHandle++
+	s.files[handle] = &fileHandle{
+		file:           file,
+		path:           path,
+		openedForWrite: pflags&openFlagWrite != 0,
+		appendMode:     pflags&openFlagAppend != 0,
+		readHash:       sha256.New(),
+		writeHash:      sha256.New(),
+	}
+	return s.sendHandle(requestID, handle)
+}
+
+func (s *Server) handleClose(requestID uint32, b *buffer) error {
+	handle, err := b.readString()
+	if err != nil {
+		return s.sendStatus(requestID, statusBadMessage, err.Error())
+	}
+	if f, ok := s.files[handle]; ok {
+		delete(s.files, handle)
+		err := f.file.Close()
+		s.reportTransfer(f)
+		return s.sendError(requestID, err)
+	}
+	if d, ok := s.dirs[handle]; ok {
+		delete(s.dirs, handle)
+		return s.sendError(requestID, d.dir.Close())
+	}
+	return s.sendStatus(requestID, statusFailure, "invalid handle")
+}
+
+func (s *Server) handleRead(requestID uint32, b *buffer) error {
+	handle, err := b.readString()
+	if err != nil {
+		return s.sendStatus(requestID, statusBadMessage, err.Error())
+	}
+	offset, err := b.readUint64()
+	if err != nil {
+		return s.sendStatus(requestID, statusBadMessage, err.Error())
+	}
+	length, err := b.readUint32()
+	if err != nil {
+		return s.sendStatus(requestID, statusBadMessage, err.Error())
+	}
+	f, ok := s.files[handle]
+	if !ok {
+		return s.sendStatus(requestID, statusFailure, "invalid handle")
+	}
+	if length > maxPacketSize/2 {
+		length = maxPacketSize / 2
+	}
+	data := make([]byte, length)
+	n, err := f.file.ReadAt(data, int64(offset))
+	if n > 0 {
+		f.readBytes += int64(n)
+		f.readHash.Write(data[:n])
+		return s.sendData(requestID, data[:n])
+	}
+	return s.sendError(requestID, err)
+}
+
+func (s *Server) handleWrite(requestID uint32, b *buffer) error {
+	handle, err := b.readString()
+	if err != nil {
+		return s.sendStatus(requestID, statusBadMessage, err.Error())
+	}
+	offset, err := b.readUint64()
+	if err != nil {
+		return s.sendStatus(requestID, statusBadMessage, err.Error())
+	}
+	data, err := b.readString()
+	if err != nil {
+		return s.sendStatus(requestID, statusBadMessage, err.Error())
+	}
+	f, ok := s.files[handle]
+	if !ok {
+		return s.sendStatus(requestID, statusFailure, "invalid handle")
+	}
+	var n int
+	if f.appendMode {
+		// Positioned writes are rejected on O_APPEND files, the offset is
+		// implied.
+		n, err = f.file.Write([]byte(data))
+	} else {
+		n, err = f.file.WriteAt([]byte(data), int64(offset))
+	}
+	if n > 0 {
+		f.writeBytes += int64(n)
+		f.writeHash.Write([]byte(data[:n]))
+	}
+	return s.sendError(requestID, err)
+}
+
+func (s *Server) handleStat(requestID uint32, b *buffer, stat func(string) (os.FileInfo, error)) error {
+	path, err := b.readString()
+	if err != nil {
+		return s.sendStatus(requestID, statusBadMessage, err.Error())
+	}
+	info, err := stat(path)
+	if err != nil {
+		return s.sendError(requestID, err)
+	}
+	return s.sendAttrs(requestID, attrsFromFileInfo(info))
+}
+
+func (s *Server) handleFstat(requestID uint32, b *buffer) error {
+	handle, err := b.readString()
+	if err != nil {
+		return s.sendStatus(requestID, statusBadMessage, err.Error())
+	}
+	f, ok := s.files[handle]
+	if !ok {
+		return s.sendStatus(requestID, statusFailure, "invalid handle")
+	}
+	info, err := f.file.Stat()
+	if err != nil {
+		return s.sendError(requestID, err)
+	}
+	return s.sendAttrs(requestID, attrsFromFileInfo(info))
+}
+
+func (s *Server) handleSetstat(requestID uint32, b *buffer) error {
+	path, err := b.readString()
+	if err != nil {
+		return s.sendStatus(requestID, statusBadMessage, err.Error())
+	}
+	attrs, err := b.readAttrs()
+	if err != nil {
+		return s.sendStatus(requestID, statusBadMessage, err.Error())
+	}
+	return s.sendError(requestID, applySetstat(path, attrs))
+}
+
+func (s *Server) handleFsetstat(requestID uint32, b *buffer) error {
+	handle, err := b.readString()
+	if err != nil {
+		return s.sendStatus(requestID, statusBadMessage, err.Error())
+	}
+	attrs, err := b.readAttrs()
+	if err != nil {
+		return s.sendStatus(requestID, statusBadMessage, err.Error())
+	}
+	f, ok := s.files[handle]
+	if !ok {
+		return s.sendStatus(requestID, statusFailure, "invalid handle")
+	}
+	return s.sendError(requestID, applySetstat(f.path, attrs))
+}
+
+// applySetstat applies the attributes present in a setstat request.
+func applySetstat(path string, attrs *fileAttrs) error {
+	if attrs.flags&attrFlagSize != 0 {
+		if err := os.Truncate(path, int64(attrs.size)); err != nil {
+			return trace.ConvertSystemError(err)
+		}
+	}
+	if attrs.flags&attrFlagPermissions != 0 {
+		if err := os.Chmod(path, os.FileMode(attrs.permissions).Perm()); err != nil {
+			return trace.ConvertSystemError(err)
+		}
+	}
+	if attrs.flags&attrFlagACModTime != 0 {
+		if err := os.Chtimes(path, attrs.accessTime(), attrs.modTime()); err != nil {
+			return trace.ConvertSystemError(err)
+		}
+	}
+	if attrs.flags&attrFlagUIDGID != 0 {
+		if err := os.Chown(path, int(attrs.uid), int(attrs.gid)); err != nil {
+			return trace.ConvertSystemError(err)
+		}
+	}
+	return nil
+}
+
+func (s *Server) handleOpendir(requestID uint32, b *buffer) error {
+	path, err := b.readString()
+	if err != nil {
+		return s.sendStatus(requestID, statusBadMessage, err.Error())
+	}
+	dir, err := os.Open(path)
+	if err != nil {
+		return s.sendError(requestID, err)
+	}
+	info, err := dir.Stat()
+	if err != nil {
+		dir.Close()
+		return s.sendError(requestID, err)
+	}
+	if !info.IsDir() {
+		dir.Close()
+		return s.sendStatus(requestID, statusFailure, fmt.Sprintf("%v is not a directory", path))
+	}
+	handle := strconv.FormatUint(s.nextHandle, 10)
+	s.nextHandle++
+	s.dirs[handle] = &dirHandle{dir: dir, path: path}
+	return s.sendHandle(requestID, handle)
+}
+
+func (s *Server) handleReaddir(requestID uint32, b *buffer) error {
+	handle, err := b.readString()
+	if err != nil {
+		return s.sendStatus(requestID, statusBadMessage, err.Error())
+	}
+	d, ok := s.dirs[handle]
+	if !ok {
+		return s.sendStatus(requestID, statusFailure, "invalid handle")
+	}
+	infos, err := d.dir.Readdir(64)
+	if len(infos) == 0 {
+		if err == nil || err == io.EOF {
+			return s.sendStatus(requestID, statusEOF, "end of directory")
+		}
+		return s.sendError(requestID, err)
+	}
+	// Build the NAME response: count followed by filename, longname and
+	// attributes for every entry.
+	payload := appendUint32([]byte{packetTypeName}, requestID)
+	payload = appendUint32(payload, uint32(len(infos)))
+	for _, info := range infos {
+		payload = appendString(payload, info.Name())
+		payload = appendString(payload, longname(info))
+		payload = appendAttrs(payload, attrsFromFileInfo(info))
+	}
+	return writePacket(s.out, payload)
+}
+
+func (s *Server) handleMkdir(requestID uint32, b *buffer) error {
+	path, err := b.readString()
+	if err != nil {
+		return s.sendStatus(requestID, statusBadMessage, err.Error())
+	}
+	attrs, err := b.readAttrs()
+	if err != nil {
+		return s.sendStatus(requestID, statusBadMessage, err.Error())
+	}
+	permissions := os.FileMode(0755)
+	if attrs.flags&attrFlagPermissions != 0 {
+		permissions = os.FileMode(attrs.permissions).Perm()
+	}
+	return s.sendError(requestID, os.Mkdir(path, permissions))
+}
+
+func (s *Server) handleRealpath(requestID uint32, b *buffer) error {
+	path, err := b.readString()
+	if err != nil {
+		return s.sendStatus(requestID, statusBadMessage, err.Error())
+	}
+	resolved, err := filepath.Abs(path)
+	if err != nil {
+		return s.sendError(requestID, err)
+	}
+	payload := appendUint32([]byte{packetTypeName}, requestID)
+	payload = appendUint32(payload, 1)
+	payload = appendString(payload, resolved)
+	payload = appendString(payload, resolved)
+	payload = appendAttrs(payload, &fileAttrs{})
+	return writePacket(s.out, payload)
+}
+
+func (s *Server) handleRename(requestID uint32, b *buffer) error {
+	oldPath, err := b.readString()
+	if err != nil {
+		return s.sendStatus(requestID, statusBadMessage, err.Error())
+	}
+	newPath, err := b.readString()
+	if err != nil {
+		return s.sendStatus(requestID, statusBadMessage, err.Error())
+	}
+	return s.sendError(requestID, os.Rename(oldPath, newPath))
+}
+
+func (s *Server) handleReadlink(requestID uint32, b *buffer) error {
+	path, err := b.readString()
+	if err != nil {
+		return s.sendStatus(requestID, statusBadMessage, err.Error())
+	}
+	target, err := os.Readlink(path)
+	if err != nil {
+		return s.sendError(requestID, err)
+	}
+	payload := appendUint32([]byte{packetTypeName}, requestID)
+	payload = appendUint32(payload, 1)
+	payload = appendString(payload, target)
+	payload = appendString(payload, target)
+	payload = appendAttrs(payload, &fileAttrs{})
+	return writePacket(s.out, payload)
+}
+
+func (s *Server) handleSymlink(requestID uint32, b *buffer) error {
+	// Note: OpenSSH sends the arguments to SSH_FXP_SYMLINK in reverse order
+	// from the specification, target first, and servers follow suit.
+	target, err := b.readString()
+	if err != nil {
+		return s.sendStatus(requestID, statusBadMessage, err.Error())
+	}
+	linkPath, err := b.readString()
+	if err != nil {
+		return s.sendStatus(requestID, statusBadMessage, err.Error())
+	}
+	return s.sendError(requestID, os.Symlink(target, linkPath))
+}
+
+// handlePathRequest handles requests that take a single path and return a
+// status, such as remove and rmdir.
+func (s *Server) handlePathRequest(requestID uint32, b *buffer, op func(string) error) error {
+	path, err := b.readString()
+	if err != nil {
+		return s.sendStatus(requestID, statusBadMessage, err.Error())
+	}
+	return s.sendError(requestID, op(path))
+}
+
+// reportTransfer reports the completed transfer of a closed file handle.
+// Handles opened for writing are reported as uploads, handles that were
+// only read from are reported as downloads. Handles with no data movement,
+// such as stat-only opens, are not reported.
+func (s *Server) reportTransfer(f *fileHandle) {
+	if s.onTransfer == nil {
+		return
+	}
+	switch {
+	case f.openedForWrite:
+		s.onTransfer(FileTransfer{
+			Path:      f.path,
+			Direction: DirectionUpload,
+			Bytes:     f.writeBytes,
+			SHA256:    hex.EncodeToString(f.writeHash.Sum(nil)),
+		})
+	case f.readBytes > 0:
+		s.onTransfer(FileTransfer{
+			Path:      f.path,
+			Direction: DirectionDownload,
+			Bytes:     f.readBytes,
+			SHA256:    hex.EncodeToString(f.readHash.Sum(nil)),
+		})
+	}
+}
+
+// closeAllHandles closes handles left open by the client, reporting any
+// pending transfers.
+func (s *Server) closeAllHandles() {
+	for handle, f := range s.files {
+		delete(s.files, handle)
+		f.file.Close()
+		s.reportTransfer(f)
+	}
+	for handle, d := range s.dirs {
+		delete(s.dirs, handle)
+		d.dir.Close()
+	}
+}
+
+func (s *Server) sendStatus(requestID uint32, code uint32, message string) error {
+	payload := appendUint32([]byte{packetTypeStatus}, requestID)
+	payload = appendUint32(payload, code)
+	payload = appendString(payload, message)
+	payload = appendString(payload, "en")
+	return writePacket(s.out, payload)
+}
+
+// sendError sends the status response corresponding to the given error, a
+// nil error produces an OK status.
+func (s *Server) sendError(requestID uint32, err error) error {
+	if err == nil {
+		return s.sendStatus(requestID, statusOK, "success")
+	}
+	err = trace.ConvertSystemError(trace.Wrap(err))
+	switch {
+	case trace.IsNotFound(err):
+		return s.sendStatus(requestID, statusNoSuchFile, err.Error())
+	case trace.IsAccessDenied(err):
+		return s.sendStatus(requestID, statusPermissionDenied, err.Error())
+	case trace.Unwrap(err) == io.EOF:
+		return s.sendStatus(requestID, statusEOF, "end of file")
+	default:
+		return s.sendStatus(requestID, statusFailure, err.Error())
+	}
+}
+
+func (s *Server) sendHandle(requestID uint32, handle string) error {
+	payload := appendUint32([]byte{packetTypeHandle}, requestID)
+	payload = appendString(payload, handle)
+	return writePacket(s.out, payload)
+}
+
+func (s *Server) sendData(requestID uint32, data []byte) error {
+	payload := appendUint32([]byte{packetTypeData}, requestID)
+	payload = appendUint32(payload, uint32(len(data)))
+	payload = append(payload, data...)
+	return writePacket(s.out, payload)
+}
+
+func (s *Server) sendAttrs(requestID uint32, attrs *fileAttrs) error {
+	payload := appendUint32([]byte{packetTypeAttrs}, requestID)
+	payload = appendAttrs(payload, attrs)
+	return writePacket(s.out, payload)
+}
+
+// longname formats a directory entry like "ls -l" output, which is what
+// clients display for verbose listings.
+func longname(info os.FileInfo) string {
+	return fmt.Sprintf("%s %3d %-8s %-8s %8d %s %s",
+		info.Mode().String(), 1, "-", "-", info.Size(),
+		info.ModTime().Format("Jan _2 15:04"), info.Name())
+}
@@ -0,0 +1,264 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package sftp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testClient speaks the client side of the protocol over pipes connected to
+// a Server running in a separate goroutine.
+type testClient struct {
+	t         *testing.T
+	in        io.WriteCloser
+	out       io.Reader
+	requestID uint32
+	done      chan error
+	transfers []FileTransfer
+}
+
+func newTestClient(t *testing.T) *testClient {
+	clientIn, serverOut := io.Pipe()
+	serverIn, clientOut := io.Pipe()
+	c := &testClient{
+		t:    t,
+		in:   clientOut,
+		out:  clientIn,
+		done: make(chan error, 1),
+	}
+	server := NewServer(serverIn, serverOut, func(transfer FileTransfer) {
+		c.transfers = append(c.transfers, transfer)
+	})
+	go func() {
+		c.done <- server.Serve()
+		serverOut.Close()
+	}()
+
+	// Version negotiation.
+	c.send(appendUint32([]byte{packetTypeInit}, ProtocolVersion))
+	response := c.recv()
+	require.Equal(t, byte(packetTypeVersion), response[0])
+	return c
+}
+
+// close disconnects the client and waits for the server to exit cleanly.
+func (c *testClient) close() {
+	c.in.Close()
+	require.NoError(c.t, <-c.done)
+}
+
+func (c *testClient) send(payload []byte) {
+	require.NoError(c.t, writePacket(c.in, payload))
+}
+
+func (c *testClient) recv() []byte {
+	payload, err := readPacket(c.out)
+	require.NoError(c.t, err)
+	return payload
+}
+
+// request sends a request of the given type and returns the response type
+// and a buffer positioned after the response request id.
+func (c *testClient) request(packetType byte, fields []byte) (byte, *buffer) {
+	c.requestID++
+	payload := appendUint32([]byte{packetType}, c.requestID)
+	payload = append(payload, fields...)
+	c.send(payload)
+
+	response := c.recv()
+	b := &buffer{data: response[1:]}
+	requestID, err := b.readUint32()
+	require.NoError(c.t, err)
+	require.Equal(c.t, c.requestID, requestID)
+	return response[0], b
+}
+
+// expectStatus sends a request and requires a status response with the
+// given code.
+func (c *testClient) expectStatus(packetType byte, fields []byte, code uint32) {
+	responseType, b := c.request(packetType, fields)
+	require.Equal(c.t, byte(packetTypeStatus), responseType)
+	gotCode, err := b.readUint32()
+	require.NoError(c.t, err)
+	message, err := b.readString()
+	require.NoError(c.t, err)
+	require.Equal(c.t, code, gotCode, "unexpected status: %v", message)
+}
+
+// open opens a path with the given flags and returns the handle.
+func (c *testClient) open(path string, pflags uint32) string {
+	fields := appendString(nil, path)
+	fields = appendUint32(fields, pflags)
+	fields = appendUint32(fields, 0) // no attributes
+	responseType, b := c.request(packetTypeOpen, fields)
+	require.Equal(c.t, byte(packetTypeHandle), responseType)
+	handle, err := b.readString()
+	require.NoError(c.t, err)
+	return handle
+}
+
+func TestUpload(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "upload.txt")
+	payload := []byte("sftp upload test payload")
+
+	c := newTestClient(t)
+	handle := c.open(path, openFlagWrite|openFlagCreate|openFlagTrunc)
+
+	fields := appendString(nil, handle)
+	fields = appendUint64(fields, 0)
+	fields = appendString(fields, string(payload))
+	c.expectStatus(packetTypeWrite, fields, statusOK)
+	c.expectStatus(packetTypeClose, appendString(nil, handle), statusOK)
+	c.close()
+
+	written, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, payload, written)
+
+	expectedSum := sha256.Sum256(payload)
+	require.Equal(t, []FileTransfer{{
+		Path:      path,
+		Direction: DirectionUpload,
+		Bytes:     int64(len(payload)),
+		SHA256:    hex.EncodeToString(expectedSum[:]),
+	}}, c.transfers)
+}
+
+func TestDownload(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "download.txt")
+	payload := []byte("sftp download test payload")
+	require.NoError(t, ioutil.WriteFile(path, payload, 0644))
+
+	c := newTestClient(t)
+	handle := c.open(path, openFlagRead)
+
+	fields := appendString(nil, handle)
+	fields = appendUint64(fields, 0)
+	fields = appendUint32(fields, 1024)
+	responseType, b := c.request(packetTypeRead, fields)
+	require.Equal(t, byte(packetTypeData), responseType)
+	data, err := b.readString()
+	require.NoError(t, err)
+	require.Equal(t, payload, []byte(data))
+
+	// The next read is past the end of the file.
+	fields = appendString(nil, handle)
+	fields = appendUint64(fields, uint64(len(payload)))
+	fields = appendUint32(fields, 1024)
+	c.expectStatus(packetTypeRead, fields, statusEOF)
+
+	c.expectStatus(packetTypeClose, appendString(nil, handle), statusOK)
+	c.close()
+
+	expectedSum := sha256.Sum256(payload)
+	require.Equal(t, []FileTransfer{{
+		Path:      path,
+		Direction: DirectionDownload,
+		Bytes:     int64(len(payload)),
+		SHA256:    hex.EncodeToString(expectedSum[:]),
+	}}, c.transfers)
+}
+
+func TestStat(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "stat.txt")
+	require.NoError(t, ioutil.WriteFile(path, []byte("12345"), 0640))
+
+	c := newTestClient(t)
+	defer c.close()
+
+	responseType, b := c.request(packetTypeStat, appendString(nil, path))
+	require.Equal(t, byte(packetTypeAttrs), responseType)
+	attrs, err := b.readAttrs()
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), attrs.size)
+	require.Equal(t, os.FileMode(0640), os.FileMode(attrs.permissions).Perm())
+
+	c.expectStatus(packetTypeStat, appendString(nil, filepath.Join(dir, "missing")), statusNoSuchFile)
+}
+
+func TestReaddir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "b.txt"), []byte("b"), 0644))
+
+	c := newTestClient(t)
+	defer c.close()
+
+	responseType, b := c.request(packetTypeOpendir, appendString(nil, dir))
+	require.Equal(t, byte(packetTypeHandle), responseType)
+	handle, err := b.readString()
+	require.NoError(t, err)
+
+	responseType, b = c.request(packetTypeReaddir, appendString(nil, handle))
+	require.Equal(t, byte(packetTypeName), responseType)
+	count, err := b.readUint32()
+	require.NoError(t, err)
+	names := make([]string, 0, count)
+	for i := uint32(0); i < count; i++ {
+		name, err := b.readString()
+		require.NoError(t, err)
+		_, err = b.readString() // longname
+		require.NoError(t, err)
+		_, err = b.readAttrs()
+		require.NoError(t, err)
+		names = append(names, name)
+	}
+	require.ElementsMatch(t, []string{"a.txt", "b.txt"}, names)
+
+	c.expectStatus(packetTypeReaddir, appendString(nil, handle), statusEOF)
+	c.expectStatus(packetTypeClose, appendString(nil, handle), statusOK)
+}
+
+func TestRemoveAndMkdir(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "remove.txt")
+	require.NoError(t, ioutil.WriteFile(path, []byte("x"), 0644))
+
+	c := newTestClient(t)
+	defer c.close()
+
+	c.expectStatus(packetTypeRemove, appendString(nil, path), statusOK)
+	_, err := os.Stat(path)
+	require.True(t, os.IsNotExist(err))
+
+	subdir := filepath.Join(dir, "subdir")
+	fields := appendString(nil, subdir)
+	fields = appendUint32(fields, 0) // no attributes
+	c.expectStatus(packetTypeMkdir, fields, statusOK)
+	info, err := os.Stat(subdir)
+	require.NoError(t, err)
+	require.True(t, info.IsDir())
+
+	c.expectStatus(packetTypeRmdir, appendString(nil, subdir), statusOK)
+}
+
+func TestUnsupportedRequest(t *testing.T) {
+	c := newTestClient(t)
+	defer c.close()
+
+	c.expectStatus(200, nil, statusOpUnsupported)
+}
@@ -133,6 +133,12 @@ type Identity struct {
 	// deny any attempts to reissue new certificates while authenticated with
 	// this certificate.
 	DisallowReissue bool
+	// BoundKeyFingerprint is the SSH SHA256 fingerprint of the
+	// hardware-attested public key this certificate was issued for, set
+	// after a successful device key attestation at login. Since TLS and
+	// SSH both prove possession of the certified key during the handshake,
+	// the certificate is unusable without the attested device key.
+	BoundKeyFingerprint string
 }
 
 // RouteToApp holds routing information for applications.
@@ -297,6 +303,11 @@ var (
 	// requests to generate new certificates using this certificate should be
 	// denied.
 	DisallowReissueASN1ExtensionOID = asn1.ObjectIdentifier{1, 3, 9999, 2, 9}
+
+	// BoundKeyFingerprintASN1ExtensionOID is an extension OID used when
+	// encoding/decoding the fingerprint of the device-held key the
+	// certificate is bound to.
+	BoundKeyFingerprintASN1ExtensionOID = asn1.ObjectIdentifier{1, 3, 9999, 2, 10}
 )
 
 // Subject converts identity to X.509 subject name
@@ -485,6 +496,14 @@ func (id *Identity) Subject() (pkix.Name, error) {
 		)
 	}
 
+	if id.BoundKeyFingerprint != "" {
+		subject.ExtraNames = append(subject.ExtraNames,
+			pkix.AttributeTypeAndValue{
+				Type:  BoundKeyFingerprintASN1ExtensionOID,
+				Value: id.BoundKeyFingerprint,
+			})
+	}
+
 	return subject, nil
 }
 
@@ -614,6 +633,11 @@ func FromSubject(subject pkix.Name, expires time.Time) (*Identity, error) {
 			if ok {
 				id.DisallowReissue = val == types.True
 			}
+		case attr.Type.Equal(BoundKeyFingerprintASN1ExtensionOID):
+			val, ok := attr.Value.(string)
+			if ok {
+				id.BoundKeyFingerprint = val
+			}
 		}
 	}
 
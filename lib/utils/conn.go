@@ -75,6 +75,43 @@ func (c *CloserConn) Wait() {
 	<-c.ctx.Done()
 }
 
+// ProxyConn launches a double-copy loop that proxies traffic between the
+// provided client and server connections. It returns when one of the copies
+// finishes, or when the context gets canceled, and closes both connections.
+func ProxyConn(ctx context.Context, client, server io.ReadWriteCloser) error {
+	errCh := make(chan error, 2)
+
+	go func() {
+		defer client.Close()
+		defer server.Close()
+		_, err := io.Copy(server, client)
+		errCh <- err
+	}()
+
+	go func() {
+		defer client.Close()
+		defer server.Close()
+		_, err := io.Copy(client, server)
+		errCh <- err
+	}()
+
+	var errs []error
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-errCh:
+			if err != nil && !IsOKNetworkError(err) {
+				errs = append(errs, err)
+			}
+		case <-ctx.Done():
+			client.Close()
+			server.Close()
+			return trace.ConnectionProblem(ctx.Err(), "context is closing")
+		}
+	}
+
+	return trace.NewAggregate(errs...)
+}
+
 // Roundtrip is a single connection simplistic HTTP client
 // that allows us to bypass a connection pool to test load balancing
 // used in tests, as it only supports GET request on /
@@ -0,0 +1,84 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProxyConn(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clientLeft, clientRight := net.Pipe()
+	serverLeft, serverRight := net.Pipe()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- ProxyConn(ctx, clientRight, serverRight)
+	}()
+
+	// Data written by the client should arrive at the server and vice versa.
+	go clientLeft.Write([]byte("ping"))
+	buf := make([]byte, 4)
+	_, err := serverLeft.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, "ping", string(buf))
+
+	go serverLeft.Write([]byte("pong"))
+	_, err = clientLeft.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, "pong", string(buf))
+
+	// Closing one of the connections should unblock the proxy and close the
+	// other side as well. The error value is not asserted since net.Pipe
+	// reports closure differently than TCP connections do.
+	require.NoError(t, clientLeft.Close())
+	select {
+	case <-errCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("proxy loop did not exit after connection close")
+	}
+	_, err = serverLeft.Read(buf)
+	require.Error(t, err)
+}
+
+func TestProxyConnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, clientRight := net.Pipe()
+	_, serverRight := net.Pipe()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- ProxyConn(ctx, clientRight, serverRight)
+	}()
+
+	cancel()
+	select {
+	case err := <-errCh:
+		require.Error(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("proxy loop did not exit after context cancellation")
+	}
+}
@@ -0,0 +1,274 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gravitational/trace"
+
+	"golang.org/x/crypto/md4"
+)
+
+// NTLM over HTTP CONNECT per [MS-NLMP]. Only the NTLMv2 response is
+// computed, NTLMv1 and the session security extensions are not needed for
+// proxy authentication.
+
+// ntlmSignature prefixes every NTLM message.
+const ntlmSignature = "NTLMSSP\x00"
+
+// ntlmNegotiateFlags are the flags sent in the NEGOTIATE_MESSAGE.
+const ntlmNegotiateFlags = 0x00000001 | // NTLMSSP_NEGOTIATE_UNICODE
+	0x00000004 | // NTLMSSP_REQUEST_TARGET
+	0x00000200 | // NTLMSSP_NEGOTIATE_NTLM
+	0x00008000 | // NTLMSSP_NEGOTIATE_ALWAYS_SIGN
+	0x00080000 // NTLMSSP_NEGOTIATE_EXTENDED_SESSIONSECURITY
+
+// windowsEpochOffset is the number of 100-nanosecond intervals between
+// the Windows epoch (1601-01-01) and the Unix epoch (1970-01-01).
+const windowsEpochOffset = 116444736000000000
+
+// connectNTLM issues an HTTP CONNECT request for the target address,
+// performing the 3-leg NTLM challenge-response handshake with the proxy
+// on the same connection.
+func (d proxyDial) connectNTLM(conn net.Conn, addr string) (net.Conn, error) {
+	br := bufio.NewReader(conn)
+
+	// First leg: send the negotiate message and expect to be challenged.
+	resp, err := d.sendConnect(conn, br, addr, ntlmNegotiateMessage())
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if resp.StatusCode == http.StatusOK {
+		// The proxy let the request through without completing the
+		// handshake.
+		return &bufferedConn{Conn: conn, reader: br}, nil
+	}
+	if resp.StatusCode != http.StatusProxyAuthRequired {
+		return nil, trace.BadParameter("unable to proxy connection: %v", resp.Status)
+	}
+	challenge, err := parseNTLMChallenge(resp.Header.Values("Proxy-Authenticate"))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if resp.Close {
+		return nil, trace.BadParameter("proxy closed the connection during the NTLM handshake")
+	}
+
+	// Second leg: answer the challenge with the authenticate message.
+	var clientChallenge [8]byte
+	if _, err := rand.Read(clientChallenge[:]); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	authenticate := ntlmAuthenticateMessage(d.username, d.password, challenge, clientChallenge, time.Now())
+	resp, err = d.sendConnect(conn, br, addr, authenticate)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, trace.AccessDenied("proxy rejected NTLM credentials: %v", resp.Status)
+	}
+	return &bufferedConn{Conn: conn, reader: br}, nil
+}
+
+// sendConnect sends a CONNECT request carrying the NTLM message and reads
+// the response, draining its body so the connection can be reused for the
+// next handshake leg.
+func (d proxyDial) sendConnect(conn net.Conn, br *bufio.Reader, addr string, message []byte) (*http.Response, error) {
+	connectReq := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	connectReq.Header.Set("Proxy-Authorization", "NTLM "+base64.StdEncoding.EncodeToString(message))
+	connectReq.Header.Set("Proxy-Connection", "Keep-Alive")
+	if err := connectReq.Write(conn); err != nil {
+		log.Warnf("Unable to write to proxy: %v.", err)
+		return nil, trace.Wrap(err)
+	}
+	resp, err := http.ReadResponse(br, connectReq)
+	if err != nil {
+		log.Warnf("Unable to read response: %v.", err)
+		return nil, trace.Wrap(err)
+	}
+	// A successful CONNECT has no body, the connection is handed off to
+	// the caller as is.
+	if resp.StatusCode == http.StatusOK {
+		return resp, nil
+	}
+	if _, err := io.Copy(ioutil.Discard, resp.Body); err != nil {
+		resp.Body.Close()
+		return nil, trace.Wrap(err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return resp, nil
+}
+
+// ntlmNegotiateMessage returns the NEGOTIATE_MESSAGE with empty domain
+// and workstation fields.
+func ntlmNegotiateMessage() []byte {
+	b := make([]byte, 32)
+	copy(b, ntlmSignature)
+	binary.LittleEndian.PutUint32(b[8:], 1)
+	binary.LittleEndian.PutUint32(b[12:], ntlmNegotiateFlags)
+	return b
+}
+
+// ntlmChallenge holds the parts of the CHALLENGE_MESSAGE needed to
+// compute the NTLMv2 response.
+type ntlmChallenge struct {
+	serverChallenge [8]byte
+	targetInfo      []byte
+}
+
+// parseNTLMChallenge extracts the NTLM challenge from the
+// Proxy-Authenticate response headers.
+func parseNTLMChallenge(headers []string) (*ntlmChallenge, error) {
+	var encoded string
+	for _, header := range headers {
+		if strings.HasPrefix(header, "NTLM ") {
+			encoded = strings.TrimPrefix(header, "NTLM ")
+			break
+		}
+	}
+	if encoded == "" {
+		return nil, trace.BadParameter("proxy did not send an NTLM challenge")
+	}
+	message, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, trace.BadParameter("invalid NTLM challenge encoding: %v", err)
+	}
+	return decodeNTLMChallenge(message)
+}
+
+// decodeNTLMChallenge decodes the CHALLENGE_MESSAGE.
+func decodeNTLMChallenge(message []byte) (*ntlmChallenge, error) {
+	if len(message) < 48 || !bytes.HasPrefix(message, []byte(ntlmSignature)) {
+		return nil, trace.BadParameter("malformed NTLM challenge message")
+	}
+	if messageType := binary.LittleEndian.Uint32(message[8:]); messageType != 2 {
+		return nil, trace.BadParameter("unexpected NTLM message type %v, expected a challenge", messageType)
+	}
+	var challenge ntlmChallenge
+	copy(challenge.serverChallenge[:], message[24:32])
+	infoLen := int(binary.LittleEndian.Uint16(message[40:]))
+	infoOffset := int(binary.LittleEndian.Uint32(message[44:]))
+	if infoOffset+infoLen > len(message) {
+		return nil, trace.BadParameter("malformed NTLM challenge message")
+	}
+	challenge.targetInfo = message[infoOffset : infoOffset+infoLen]
+	return &challenge, nil
+}
+
+// ntlmAuthenticateMessage returns the AUTHENTICATE_MESSAGE carrying the
+// NTLMv2 and LMv2 responses. The username may optionally be qualified
+// with a domain as DOMAIN\user.
+func ntlmAuthenticateMessage(username, password string, challenge *ntlmChallenge, clientChallenge [8]byte, now time.Time) []byte {
+	var domain string
+	user := username
+	if i := strings.Index(username, `\`); i >= 0 {
+		domain, user = username[:i], username[i+1:]
+	}
+
+	v2Hash := ntowfv2(password, user, domain)
+	timestamp := uint64(now.UnixNano()/100) + windowsEpochOffset
+	ntResponse := ntlmV2Response(v2Hash, challenge.serverChallenge, clientChallenge, timestamp, challenge.targetInfo)
+	lmResponse := append(hmacMD5(v2Hash, challenge.serverChallenge[:], clientChallenge[:]), clientChallenge[:]...)
+
+	buf := make([]byte, 64)
+	copy(buf, ntlmSignature)
+	binary.LittleEndian.PutUint32(buf[8:], 3)
+	binary.LittleEndian.PutUint32(buf[60:], ntlmNegotiateFlags)
+	setField := func(fieldOffset int, data []byte) {
+		binary.LittleEndian.PutUint16(buf[fieldOffset:], uint16(len(data)))
+		binary.LittleEndian.PutUint16(buf[fieldOffset+2:], uint16(len(data)))
+		binary.LittleEndian.PutUint32(buf[fieldOffset+4:], uint32(len(buf)))
+		buf = append(buf, data...)
+	}
+	// Payload order per [MS-NLMP]: domain, user, workstation, then the LM
+	// and NT responses. The session key field stays empty.
+	setField(28, utf16leBytes(domain))
+	setField(36, utf16leBytes(user))
+	setField(44, nil)
+	setField(12, lmResponse)
+	setField(20, ntResponse)
+	setField(52, nil)
+	return buf
+}
+
+// ntowfv2 computes the NTLMv2 password hash.
+func ntowfv2(password, user, domain string) []byte {
+	ntHash := md4.New()
+	ntHash.Write(utf16leBytes(password))
+	return hmacMD5(ntHash.Sum(nil), utf16leBytes(strings.ToUpper(user)+domain))
+}
+
+// ntlmV2Response computes the NTLMv2 response: the proof over the
+// server and client challenges followed by the temp blob it was computed
+// from.
+func ntlmV2Response(v2Hash []byte, serverChallenge, clientChallenge [8]byte, timestamp uint64, targetInfo []byte) []byte {
+	temp := make([]byte, 28, 28+len(targetInfo)+4)
+	temp[0] = 1 // response version
+	temp[1] = 1 // highest response version
+	binary.LittleEndian.PutUint64(temp[8:], timestamp)
+	copy(temp[16:], clientChallenge[:])
+	temp = append(temp, targetInfo...)
+	temp = append(temp, 0, 0, 0, 0)
+
+	proof := hmacMD5(v2Hash, serverChallenge[:], temp)
+	return append(proof, temp...)
+}
+
+// hmacMD5 computes HMAC-MD5 of the concatenated data.
+func hmacMD5(key []byte, data ...[]byte) []byte {
+	mac := hmac.New(md5.New, key)
+	for _, d := range data {
+		mac.Write(d)
+	}
+	return mac.Sum(nil)
+}
+
+// utf16leBytes encodes the string as UTF-16 little-endian.
+func utf16leBytes(s string) []byte {
+	b := &bytes.Buffer{}
+	for _, r := range s {
+		if r > 0xffff {
+			r1, r2 := (r-0x10000)>>10+0xd800, (r-0x10000)&0x3ff+0xdc00
+			binary.Write(b, binary.LittleEndian, uint16(r1))
+			binary.Write(b, binary.LittleEndian, uint16(r2))
+			continue
+		}
+		binary.Write(b, binary.LittleEndian, uint16(r))
+	}
+	return b.Bytes()
+}
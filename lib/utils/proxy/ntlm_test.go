@@ -0,0 +1,144 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test vectors from [MS-NLMP] section 4.2: user "User" in domain
+// "Domain" with password "Password", server challenge 0123456789abcdef,
+// client challenge aaaaaaaaaaaaaaaa and zero timestamp.
+var (
+	ntlmTestServerChallenge = [8]byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef}
+	ntlmTestClientChallenge = [8]byte{0xaa, 0xaa, 0xaa, 0xaa, 0xaa, 0xaa, 0xaa, 0xaa}
+	ntlmTestTargetInfo      = mustHex("02000c0044006f006d00610069006e0001000c00530065007200760065007200" + "00000000")
+)
+
+func TestNTOWFv2(t *testing.T) {
+	require.Equal(t,
+		mustHex("0c868a403bfd7a93a3001ef22ef02e3f"),
+		ntowfv2("Password", "User", "Domain"))
+}
+
+func TestNTLMv2Response(t *testing.T) {
+	v2Hash := ntowfv2("Password", "User", "Domain")
+	response := ntlmV2Response(v2Hash, ntlmTestServerChallenge, ntlmTestClientChallenge, 0, ntlmTestTargetInfo)
+	require.Equal(t,
+		mustHex("68cd0ab851e51c96aabc927bebef6a1c"),
+		response[:16])
+
+	lmResponse := append(hmacMD5(v2Hash, ntlmTestServerChallenge[:], ntlmTestClientChallenge[:]), ntlmTestClientChallenge[:]...)
+	require.Equal(t,
+		mustHex("86c35097ac9cec102554764a57cccc19aaaaaaaaaaaaaaaa"),
+		lmResponse)
+}
+
+func TestDecodeNTLMChallenge(t *testing.T) {
+	// Assemble a CHALLENGE_MESSAGE carrying the test server challenge and
+	// target info.
+	message := make([]byte, 48)
+	copy(message, ntlmSignature)
+	message[8] = 2
+	copy(message[24:], ntlmTestServerChallenge[:])
+	message[40] = byte(len(ntlmTestTargetInfo))
+	message[44] = 48
+	message = append(message, ntlmTestTargetInfo...)
+
+	challenge, err := decodeNTLMChallenge(message)
+	require.NoError(t, err)
+	require.Equal(t, ntlmTestServerChallenge, challenge.serverChallenge)
+	require.Equal(t, ntlmTestTargetInfo, challenge.targetInfo)
+
+	// Truncated and non-challenge messages are rejected.
+	_, err = decodeNTLMChallenge(message[:20])
+	require.Error(t, err)
+	_, err = decodeNTLMChallenge(ntlmNegotiateMessage())
+	require.Error(t, err)
+}
+
+func TestOutboundProxyConfigCheckAndSetDefaults(t *testing.T) {
+	tests := []struct {
+		desc      string
+		config    OutboundProxyConfig
+		outMethod string
+		wantErr   bool
+	}{
+		{
+			desc:      "http proxy with default auth",
+			config:    OutboundProxyConfig{URL: "http://proxy:3128", Username: "alice", Password: "secret"},
+			outMethod: AuthMethodBasic,
+		},
+		{
+			desc:      "socks5 proxy",
+			config:    OutboundProxyConfig{URL: "socks5://proxy:1080"},
+			outMethod: AuthMethodBasic,
+		},
+		{
+			desc:      "ntlm over http",
+			config:    OutboundProxyConfig{URL: "http://proxy:3128", Username: `DOMAIN\alice`, Password: "secret", AuthMethod: AuthMethodNTLM},
+			outMethod: AuthMethodNTLM,
+		},
+		{
+			desc:    "missing URL",
+			config:  OutboundProxyConfig{},
+			wantErr: true,
+		},
+		{
+			desc:    "unsupported scheme",
+			config:  OutboundProxyConfig{URL: "ftp://proxy:21"},
+			wantErr: true,
+		},
+		{
+			desc:    "ntlm over socks5",
+			config:  OutboundProxyConfig{URL: "socks5://proxy:1080", Username: "alice", AuthMethod: AuthMethodNTLM},
+			wantErr: true,
+		},
+		{
+			desc:    "ntlm without username",
+			config:  OutboundProxyConfig{URL: "http://proxy:3128", AuthMethod: AuthMethodNTLM},
+			wantErr: true,
+		},
+		{
+			desc:    "unsupported auth method",
+			config:  OutboundProxyConfig{URL: "http://proxy:3128", AuthMethod: "digest"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := tt.config.CheckAndSetDefaults()
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.outMethod, tt.config.AuthMethod)
+		})
+	}
+}
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
@@ -19,6 +19,7 @@ import (
 	"bufio"
 	"context"
 	"crypto/tls"
+	"encoding/base64"
 	"net"
 	"net/http"
 	"net/url"
@@ -132,6 +133,11 @@ func (d directDial) DialTimeout(network, address string, timeout time.Duration)
 type proxyDial struct {
 	proxyHost string
 	useTLS    bool
+	// username and password are optional credentials presented to the
+	// proxy, using the authMethod scheme.
+	username   string
+	password   string
+	authMethod string
 }
 
 // DialTimeout acts like Dial but takes a timeout.
@@ -143,7 +149,7 @@ func (d proxyDial) DialTimeout(network, address string, timeout time.Duration) (
 		defer cancel()
 		ctx = timeoutCtx
 	}
-	conn, err := dialProxy(ctx, d.proxyHost, address)
+	conn, err := d.dialProxy(ctx, address)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
@@ -165,7 +171,7 @@ func (d proxyDial) DialTimeout(network, address string, timeout time.Duration) (
 // SSH connection.
 func (d proxyDial) Dial(network string, addr string, config *ssh.ClientConfig) (*ssh.Client, error) {
 	// Build a proxy connection first.
-	pconn, err := dialProxy(context.Background(), d.proxyHost, addr)
+	pconn, err := d.dialProxy(context.Background(), addr)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
@@ -232,15 +238,119 @@ func DialerFromEnvironment(addr string, opts ...DialerOptionFunc) Dialer {
 	return proxyDial{proxyHost: proxyAddr, useTLS: options.dialTLS}
 }
 
+const (
+	// AuthMethodBasic authenticates to an HTTP CONNECT proxy with the
+	// basic scheme.
+	AuthMethodBasic = "basic"
+	// AuthMethodNTLM authenticates to an HTTP CONNECT proxy with the NTLM
+	// challenge-response scheme.
+	AuthMethodNTLM = "ntlm"
+)
+
+// OutboundProxyConfig specifies an explicitly configured outbound proxy
+// that reverse tunnel connections are established through, for networks
+// that don't allow direct egress. Unlike proxies picked up from the
+// environment, it supports SOCKS5 and authentication.
+type OutboundProxyConfig struct {
+	// URL is the proxy URL, e.g. http://proxy.example.com:3128 or
+	// socks5://proxy.example.com:1080.
+	URL string
+	// Username is an optional username to authenticate to the proxy with.
+	Username string
+	// Password is an optional password to authenticate to the proxy with.
+	Password string
+	// AuthMethod is how credentials are presented to HTTP CONNECT
+	// proxies, either AuthMethodBasic (default) or AuthMethodNTLM. SOCKS5
+	// proxies always use the username/password method from RFC 1929.
+	AuthMethod string
+}
+
+// CheckAndSetDefaults checks and sets default values.
+func (c *OutboundProxyConfig) CheckAndSetDefaults() error {
+	if c.URL == "" {
+		return trace.BadParameter("missing outbound proxy URL")
+	}
+	proxyurl, err := url.Parse(c.URL)
+	if err != nil {
+		return trace.BadParameter("invalid outbound proxy URL %q: %v", c.URL, err)
+	}
+	switch proxyurl.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return trace.BadParameter("unsupported outbound proxy scheme %q, supported are: http, https, socks5", proxyurl.Scheme)
+	}
+	if c.AuthMethod == "" {
+		c.AuthMethod = AuthMethodBasic
+	}
+	switch c.AuthMethod {
+	case AuthMethodBasic:
+	case AuthMethodNTLM:
+		if proxyurl.Scheme == "socks5" {
+			return trace.BadParameter("NTLM authentication is only supported for HTTP CONNECT proxies")
+		}
+		if c.Username == "" {
+			return trace.BadParameter("NTLM authentication requires a username")
+		}
+	default:
+		return trace.BadParameter("unsupported outbound proxy auth method %q, supported are: %v, %v", c.AuthMethod, AuthMethodBasic, AuthMethodNTLM)
+	}
+	return nil
+}
+
+// DialerFromConfig returns a dialer that establishes connections through
+// the explicitly configured outbound proxy, ignoring any proxy settings
+// from the environment.
+func DialerFromConfig(config OutboundProxyConfig, opts ...DialerOptionFunc) (Dialer, error) {
+	if err := config.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	proxyurl, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, trace.BadParameter("invalid outbound proxy URL %q: %v", config.URL, err)
+	}
+
+	var options dialerOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if proxyurl.Scheme == "socks5" {
+		return socksDial{
+			proxyHost: proxyurl.Host,
+			useTLS:    options.dialTLS,
+			username:  config.Username,
+			password:  config.Password,
+		}, nil
+	}
+	return proxyDial{
+		proxyHost:  proxyurl.Host,
+		useTLS:     options.dialTLS,
+		username:   config.Username,
+		password:   config.Password,
+		authMethod: config.AuthMethod,
+	}, nil
+}
+
 type DirectDialerOptFunc func(dial *directDial)
 
-func dialProxy(ctx context.Context, proxyAddr string, addr string) (net.Conn, error) {
-	var d net.Dialer
-	conn, err := d.DialContext(ctx, "tcp", proxyAddr)
+// dialProxy connects to the proxy and issues an HTTP CONNECT request for
+// the target address, authenticating to the proxy if credentials are
+// configured.
+func (d proxyDial) dialProxy(ctx context.Context, addr string) (net.Conn, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", d.proxyHost)
 	if err != nil {
-		log.Warnf("Unable to dial to proxy: %v: %v.", proxyAddr, err)
+		log.Warnf("Unable to dial to proxy: %v: %v.", d.proxyHost, err)
 		return nil, trace.ConvertSystemError(err)
 	}
+	if d.authMethod == AuthMethodNTLM {
+		pconn, err := d.connectNTLM(conn, addr)
+		if err != nil {
+			conn.Close()
+			return nil, trace.Wrap(err)
+		}
+		return pconn, nil
+	}
 
 	connectReq := &http.Request{
 		Method: http.MethodConnect,
@@ -248,6 +358,10 @@ func dialProxy(ctx context.Context, proxyAddr string, addr string) (net.Conn, er
 		Host:   addr,
 		Header: make(http.Header),
 	}
+	if d.username != "" {
+		connectReq.Header.Set("Proxy-Authorization", "Basic "+
+			base64.StdEncoding.EncodeToString([]byte(d.username+":"+d.password)))
+	}
 	err = connectReq.Write(conn)
 	if err != nil {
 		log.Warnf("Unable to write to proxy: %v.", err)
@@ -0,0 +1,123 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"time"
+
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/teleport/api/utils/sshutils"
+	"github.com/gravitational/teleport/lib"
+	alpncommon "github.com/gravitational/teleport/lib/srv/alpnproxy/common"
+	"github.com/gravitational/teleport/lib/utils"
+
+	"golang.org/x/crypto/ssh"
+	xproxy "golang.org/x/net/proxy"
+)
+
+// socksDial connects to the target through a SOCKS5 proxy, optionally
+// authenticating with the username/password method from RFC 1929.
+type socksDial struct {
+	proxyHost string
+	useTLS    bool
+	username  string
+	password  string
+}
+
+// dialProxy connects to the target address through the SOCKS5 proxy.
+func (d socksDial) dialProxy(ctx context.Context, addr string) (net.Conn, error) {
+	var auth *xproxy.Auth
+	if d.username != "" {
+		auth = &xproxy.Auth{
+			User:     d.username,
+			Password: d.password,
+		}
+	}
+	dialer, err := xproxy.SOCKS5("tcp", d.proxyHost, auth, nil)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	// The SOCKS5 dialer from x/net always implements ContextDialer, the
+	// type assertion guards against future regressions.
+	contextDialer, ok := dialer.(xproxy.ContextDialer)
+	if !ok {
+		return nil, trace.BadParameter("expected SOCKS5 dialer to implement ContextDialer, got %T", dialer)
+	}
+	conn, err := contextDialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		log.Warnf("Unable to dial through SOCKS5 proxy %v: %v.", d.proxyHost, err)
+		return nil, trace.ConvertSystemError(err)
+	}
+	return conn, nil
+}
+
+// DialTimeout acts like Dial but takes a timeout.
+func (d socksDial) DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	ctx := context.Background()
+	if timeout > 0 {
+		timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		ctx = timeoutCtx
+	}
+	conn, err := d.dialProxy(ctx, address)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if d.useTLS {
+		addr, err := utils.ParseAddr(address)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		conn = tls.Client(conn, &tls.Config{
+			NextProtos:         []string{string(alpncommon.ProtocolReverseTunnel)},
+			InsecureSkipVerify: lib.IsInsecureDevMode(),
+			ServerName:         addr.Host(),
+		})
+	}
+	return conn, nil
+}
+
+// Dial first connects through the SOCKS5 proxy, then uses the connection
+// to establish a new SSH connection.
+func (d socksDial) Dial(network string, addr string, config *ssh.ClientConfig) (*ssh.Client, error) {
+	ctx := context.Background()
+	if config.Timeout > 0 {
+		timeoutCtx, cancel := context.WithTimeout(ctx, config.Timeout)
+		defer cancel()
+		ctx = timeoutCtx
+	}
+	pconn, err := d.dialProxy(ctx, addr)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if d.useTLS {
+		address, err := utils.ParseAddr(addr)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		pconn = tls.Client(pconn, &tls.Config{
+			NextProtos:         []string{string(alpncommon.ProtocolReverseTunnel)},
+			InsecureSkipVerify: lib.IsInsecureDevMode(),
+			ServerName:         address.Host(),
+		})
+	}
+	return sshutils.NewClientConnWithDeadline(pconn, addr, config)
+}
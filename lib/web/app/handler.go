@@ -20,6 +20,7 @@ package app
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net"
 	"net/http"
@@ -135,6 +136,49 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	h.router.ServeHTTP(w, r)
 }
 
+// HandleConnection handles connections from plain TCP applications. The
+// caller is authenticated based on the client certificate presented during
+// the TLS handshake and the connection is proxied to the application
+// service as a raw byte stream.
+func (h *Handler) HandleConnection(ctx context.Context, clientConn net.Conn) error {
+	tlsConn, ok := clientConn.(*tls.Conn)
+	if !ok {
+		return trace.BadParameter("expected *tls.Conn, got: %T", clientConn)
+	}
+
+	certs := tlsConn.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return trace.AccessDenied("missing client certificate")
+	}
+	identity, err := tlsca.FromSubject(certs[0].Subject, certs[0].NotAfter)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	// Check that the session exists in the backend cache. This allows the
+	// user to logout and invalidate their application session immediately.
+	ws, err := h.c.AccessPoint.GetAppSession(ctx, types.GetAppSessionRequest{
+		SessionID: identity.RouteToApp.SessionID,
+	})
+	if err != nil {
+		return trace.AccessDenied("invalid session")
+	}
+
+	session, err := h.getSession(ctx, ws)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	serverConn, err := session.tr.DialContext(ctx, "", "")
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	// App access connections over reverse tunnel use mutual TLS.
+	serverConn = tls.Client(serverConn, session.tr.clientTLSConfig)
+
+	return trace.Wrap(utils.ProxyConn(ctx, clientConn, serverConn))
+}
+
 // handleForward forwards the request to the application service.
 func (h *Handler) handleForward(w http.ResponseWriter, r *http.Request, session *session) error {
 	session.fwd.ServeHTTP(w, r)
@@ -39,6 +39,9 @@ type session struct {
 	fwd *forward.Forwarder
 	// ws represents the services.WebSession this requests belongs to.
 	ws types.WebSession
+	// tr is the transport used by the forwarder, it can also dial the
+	// application service directly for TCP applications.
+	tr *transport
 }
 
 // newSession creates a new session.
@@ -108,6 +111,7 @@ func (h *Handler) newSession(ctx context.Context, ws types.WebSession) (*session
 	return &session{
 		fwd: fwd,
 		ws:  ws,
+		tr:  transport,
 	}, nil
 }
 
@@ -31,6 +31,7 @@ import (
 	"github.com/gravitational/trace"
 
 	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/teleport/lib/multiplexer"
 	"github.com/gravitational/teleport/lib/reversetunnel"
 	"github.com/gravitational/teleport/lib/srv/desktop"
 	"github.com/gravitational/teleport/lib/srv/desktop/tdp"
@@ -116,6 +117,14 @@ func createDesktopConnection(
 		return trace.WrapWithMessage(err, "failed to connect to windows_desktop_service at %q: %v", service.GetAddr(), err)
 	}
 	defer serviceCon.Close()
+
+	// Send the real client address to the desktop service using a PROXY
+	// protocol v2 header before starting the TLS handshake, so it survives
+	// the reverse tunnel and shows up correctly in audit events.
+	if err := sendProxyLine(serviceCon, r.RemoteAddr); err != nil {
+		return trace.Wrap(err)
+	}
+
 	tlsConfig := ctx.clt.Config()
 	// Pass target desktop UUID via SNI.
 	tlsConfig.ServerName = desktopName + desktop.SNISuffix
@@ -140,6 +149,32 @@ func createDesktopConnection(
 	return nil
 }
 
+// sendProxyLine writes a PROXY protocol v2 header carrying the client's
+// real address to the connection with the Windows desktop service.
+func sendProxyLine(con net.Conn, clientAddr string) error {
+	source, err := net.ResolveTCPAddr("tcp", clientAddr)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	destination, ok := con.RemoteAddr().(*net.TCPAddr)
+	if !ok {
+		// Tunnel connections don't have a real TCP address on the other
+		// end, the destination is informational in that case.
+		destination = &net.TCPAddr{IP: net.IPv4zero}
+	}
+	proxyLine := multiplexer.ProxyLine{
+		Protocol:    multiplexer.TCP4,
+		Source:      *source,
+		Destination: *destination,
+	}
+	header, err := proxyLine.Bytes()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	_, err = con.Write(header)
+	return trace.Wrap(err)
+}
+
 func proxyWebsocketConn(ws *websocket.Conn, con net.Conn) error {
 	// Ensure we send binary frames to the browser.
 	ws.PayloadType = websocket.BinaryFrame
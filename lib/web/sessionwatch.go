@@ -0,0 +1,136 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package web
+
+import (
+	"net/http"
+
+	"github.com/gravitational/trace"
+	"github.com/julienschmidt/httprouter"
+	"golang.org/x/net/websocket"
+
+	apidefaults "github.com/gravitational/teleport/api/defaults"
+	"github.com/gravitational/teleport/lib/client"
+	"github.com/gravitational/teleport/lib/reversetunnel"
+	"github.com/gravitational/teleport/lib/session"
+
+	"github.com/sirupsen/logrus"
+)
+
+// siteSessionWatch streams the live terminal output of an active session
+// to the web client, read-only.
+//
+// GET /webapi/sites/:site/sessions/:sid/watch?access_token=bearer_token
+//
+// Successful response is a websocket stream carrying the raw terminal
+// output of the watched session.
+func (h *Handler) siteSessionWatch(w http.ResponseWriter, r *http.Request, p httprouter.Params, sctx *SessionContext, site reversetunnel.RemoteSite) (interface{}, error) {
+	sessionID, err := session.ParseID(p.ByName("sid"))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	clt, err := sctx.GetUserClient(site)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	sess, err := clt.GetSession(apidefaults.Namespace, *sessionID)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	watcher := &sessionWatcher{
+		log:           h.log.WithField("sid", sessionID),
+		ctx:           sctx,
+		session:       sess,
+		proxyHostPort: h.ProxyHostPort(),
+		cluster:       site.GetName(),
+	}
+	watcher.Serve(w, r)
+	return nil, nil
+}
+
+// sessionWatcher pipes the live terminal output of an active session into a
+// websocket. Unlike TerminalHandler it never joins the session: the stream
+// is read-only and carries no envelopes, just the raw output.
+type sessionWatcher struct {
+	// log holds the structured logger.
+	log *logrus.Entry
+
+	// ctx is a web session context for the currently logged in user.
+	ctx *SessionContext
+
+	// session is the active session to watch.
+	session *session.Session
+
+	// proxyHostPort is the address of the proxy to connect through.
+	proxyHostPort string
+
+	// cluster is the name of the cluster the session is running in.
+	cluster string
+}
+
+// Serve upgrades the request to a websocket and streams the watched session
+// into it until the session ends or the web client hangs up.
+func (s *sessionWatcher) Serve(w http.ResponseWriter, r *http.Request) {
+	// An empty origin checker is set for the same reason as in
+	// TerminalHandler.Serve: the bearer token required in the URL already
+	// rules out requests issued by malicious Javascript.
+	ws := &websocket.Server{Handler: s.handler}
+	ws.ServeHTTP(w, r)
+}
+
+func (s *sessionWatcher) handler(ws *websocket.Conn) {
+	defer ws.Close()
+
+	tc, err := s.makeClient(ws)
+	if err != nil {
+		s.log.WithError(err).Warn("Failed creating a client to watch session.")
+		return
+	}
+
+	s.log.Debugf("Streaming session %v output to web client.", s.session.ID)
+	if err := tc.WatchSession(ws.Request().Context(), s.session.Namespace, s.session.ID); err != nil {
+		s.log.WithError(err).Warn("Unable to stream session to web client.")
+	}
+}
+
+// makeClient builds a *client.TeleportClient for the connection to the node
+// hosting the watched session.
+func (s *sessionWatcher) makeClient(ws *websocket.Conn) (*client.TeleportClient, error) {
+	clientConfig, err := makeTeleportClientConfig(ws.Request().Context(), s.ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	clientConfig.HostLogin = s.session.Login
+	clientConfig.Namespace = s.session.Namespace
+	clientConfig.SiteName = s.cluster
+	// The watched session output goes straight into the websocket.
+	clientConfig.Stdout = ws
+	if err := clientConfig.ParseProxyHost(s.proxyHostPort); err != nil {
+		return nil, trace.BadParameter("failed to parse proxy address: %v", err)
+	}
+	clientConfig.ClientAddr = ws.Request().RemoteAddr
+
+	tc, err := client.NewClient(clientConfig)
+	if err != nil {
+		return nil, trace.BadParameter("failed to create client: %v", err)
+	}
+
+	return tc, nil
+}
@@ -58,6 +58,12 @@ const (
 	// MetricProxyConnectionLimitHit counts the number of times the proxy connection limit was exceeded
 	MetricProxyConnectionLimitHit = "proxy_connection_limit_exceeded_total"
 
+	// MetricSwitchWriterBufferedBytes measures bytes buffered by switched off session writers
+	MetricSwitchWriterBufferedBytes = "switch_writer_buffered_bytes"
+
+	// MetricSwitchWriterDroppedBytes counts bytes dropped by switched off session writers
+	MetricSwitchWriterDroppedBytes = "switch_writer_dropped_bytes_total"
+
 	// MetricUserLoginCount counts user logins
 	MetricUserLoginCount = "user_login_total"
 
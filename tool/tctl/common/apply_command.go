@@ -0,0 +1,353 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/teleport/lib/auth"
+	"github.com/gravitational/teleport/lib/defaults"
+	"github.com/gravitational/teleport/lib/service"
+	"github.com/gravitational/teleport/lib/services"
+	"github.com/gravitational/teleport/lib/utils"
+
+	"github.com/gravitational/kingpin"
+	"github.com/gravitational/trace"
+	"github.com/kylelemons/godebug/diff"
+	kyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// ApplyCommand implements `tctl resources apply`, it reconciles a directory
+// of YAML resource definitions with the cluster so cluster configuration can
+// be kept in version control.
+type ApplyCommand struct {
+	config *service.Config
+	dir    string
+	dryRun bool
+
+	applyCmd *kingpin.CmdClause
+
+	// stdout allows to switch the standard output source. Used in tests.
+	stdout io.Writer
+}
+
+// Initialize allows ApplyCommand to plug itself into the CLI parser
+func (c *ApplyCommand) Initialize(app *kingpin.Application, config *service.Config) {
+	c.config = config
+	resources := app.Command("resources", "Operate on sets of cluster resources")
+	c.applyCmd = resources.Command("apply", "Apply a directory of YAML resource definitions to the cluster")
+	c.applyCmd.Flag("filename", "Directory with resource definition files").Short('f').Required().StringVar(&c.dir)
+	c.applyCmd.Flag("dry-run", "Print the changes that would be made without applying them").BoolVar(&c.dryRun)
+	if c.stdout == nil {
+		c.stdout = os.Stdout
+	}
+}
+
+// TryRun takes the CLI command as an argument and executes it or returns
+// match=false if the command does not belong to it
+func (c *ApplyCommand) TryRun(cmd string, client auth.ClientI) (match bool, err error) {
+	switch cmd {
+	case c.applyCmd.FullCommand():
+		err = c.Apply(client)
+	default:
+		return false, nil
+	}
+	return true, trace.Wrap(err)
+}
+
+// Actions applying a resource can take on the cluster.
+const (
+	applyActionCreate    = "create"
+	applyActionUpdate    = "update"
+	applyActionUnchanged = "unchanged"
+)
+
+// applyItem is a single planned change of an apply operation.
+type applyItem struct {
+	// description identifies the resource in the command output.
+	description string
+	// action is the action applying the resource takes on the cluster.
+	action string
+	// diff is a line diff between the stored and desired resource, set for
+	// updates.
+	diff string
+	// apply writes the desired resource to the cluster.
+	apply func(ctx context.Context) error
+}
+
+// Apply reconciles the resource definitions in the directory with the
+// cluster. The whole directory is parsed, validated and diffed against the
+// cluster before anything is written, so a broken definition aborts the
+// apply without partial changes.
+func (c *ApplyCommand) Apply(client auth.ClientI) error {
+	// TODO: pass the context from CLI to terminate requests on Ctrl-C
+	ctx := context.TODO()
+	resources, err := readResourceDir(c.dir)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	plan := make([]applyItem, 0, len(resources))
+	for _, raw := range resources {
+		item, err := c.planResource(ctx, client, raw)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		plan = append(plan, item)
+	}
+	for _, item := range plan {
+		switch {
+		case item.action == applyActionUnchanged:
+			fmt.Fprintf(c.stdout, "%v is unchanged\n", item.description)
+		case c.dryRun:
+			fmt.Fprintf(c.stdout, "%v would be %vd\n", item.description, item.action)
+			if item.diff != "" {
+				fmt.Fprintln(c.stdout, item.diff)
+			}
+		default:
+			if err := item.apply(ctx); err != nil {
+				return trace.Wrap(err)
+			}
+			fmt.Fprintf(c.stdout, "%v has been %vd\n", item.description, item.action)
+		}
+	}
+	return nil
+}
+
+// readResourceDir reads all YAML resource definitions from the directory,
+// in lexical file name order.
+func readResourceDir(dir string) ([]services.UnknownResource, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	var files []string
+	for _, info := range infos {
+		if info.IsDir() {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(info.Name())) {
+		case ".yaml", ".yml":
+			files = append(files, filepath.Join(dir, info.Name()))
+		}
+	}
+	if len(files) == 0 {
+		return nil, trace.NotFound("no YAML resource files found in %v", dir)
+	}
+	sort.Strings(files)
+	var resources []services.UnknownResource
+	for _, file := range files {
+		fileResources, err := readResourceFile(file)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		resources = append(resources, fileResources...)
+	}
+	return resources, nil
+}
+
+// readResourceFile reads all resource definitions from a single file.
+func readResourceFile(file string) ([]services.UnknownResource, error) {
+	f, err := utils.OpenFile(file)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	defer f.Close()
+	var resources []services.UnknownResource
+	decoder := kyaml.NewYAMLOrJSONDecoder(f, defaults.LookaheadBufSize)
+	for {
+		var raw services.UnknownResource
+		if err := decoder.Decode(&raw); err != nil {
+			if err == io.EOF {
+				return resources, nil
+			}
+			return nil, trace.BadParameter("failed to parse %v: %v", file, err)
+		}
+		resources = append(resources, raw)
+	}
+}
+
+// planResource validates a single resource definition and plans the change
+// applying it would make.
+func (c *ApplyCommand) planResource(ctx context.Context, client auth.ClientI, raw services.UnknownResource) (applyItem, error) {
+	switch raw.Kind {
+	case types.KindRole:
+		return planRole(ctx, client, raw)
+	case types.KindDatabase:
+		return planDatabase(ctx, client, raw)
+	case types.KindApp:
+		return planApp(ctx, client, raw)
+	case types.KindClusterAuthPreference:
+		return planAuthPreference(ctx, client, raw)
+	}
+	return applyItem{}, trace.BadParameter("applying resources of type %q is not supported", raw.Kind)
+}
+
+// planRole plans the change for a role definition.
+func planRole(ctx context.Context, client auth.ClientI, raw services.UnknownResource) (applyItem, error) {
+	role, err := services.UnmarshalRole(raw.Raw)
+	if err != nil {
+		return applyItem{}, trace.Wrap(err)
+	}
+	if err := role.CheckAndSetDefaults(); err != nil {
+		return applyItem{}, trace.Wrap(err)
+	}
+	if err := services.ValidateAccessPredicates(role); err != nil {
+		// check for syntax errors in predicates
+		return applyItem{}, trace.Wrap(err)
+	}
+	item := applyItem{
+		description: fmt.Sprintf("role %q", role.GetName()),
+		action:      applyActionCreate,
+		apply: func(ctx context.Context) error {
+			return trace.Wrap(client.UpsertRole(ctx, role))
+		},
+	}
+	stored, err := client.GetRole(ctx, role.GetName())
+	if err != nil && !trace.IsNotFound(err) {
+		return applyItem{}, trace.Wrap(err)
+	}
+	if err == nil {
+		if item.action, item.diff, err = resourceDiff(stored, role); err != nil {
+			return applyItem{}, trace.Wrap(err)
+		}
+	}
+	return item, nil
+}
+
+// planDatabase plans the change for a database definition.
+func planDatabase(ctx context.Context, client auth.ClientI, raw services.UnknownResource) (applyItem, error) {
+	database, err := services.UnmarshalDatabase(raw.Raw)
+	if err != nil {
+		return applyItem{}, trace.Wrap(err)
+	}
+	item := applyItem{
+		description: fmt.Sprintf("database %q", database.GetName()),
+	}
+	stored, err := client.GetDatabase(ctx, database.GetName())
+	if err != nil && !trace.IsNotFound(err) {
+		return applyItem{}, trace.Wrap(err)
+	}
+	if trace.IsNotFound(err) {
+		item.action = applyActionCreate
+		item.apply = func(ctx context.Context) error {
+			return trace.Wrap(client.CreateDatabase(ctx, database))
+		}
+		return item, nil
+	}
+	if item.action, item.diff, err = resourceDiff(stored, database); err != nil {
+		return applyItem{}, trace.Wrap(err)
+	}
+	item.apply = func(ctx context.Context) error {
+		return trace.Wrap(client.UpdateDatabase(ctx, database))
+	}
+	return item, nil
+}
+
+// planApp plans the change for an application definition.
+func planApp(ctx context.Context, client auth.ClientI, raw services.UnknownResource) (applyItem, error) {
+	app, err := services.UnmarshalApp(raw.Raw)
+	if err != nil {
+		return applyItem{}, trace.Wrap(err)
+	}
+	item := applyItem{
+		description: fmt.Sprintf("application %q", app.GetName()),
+	}
+	stored, err := client.GetApp(ctx, app.GetName())
+	if err != nil && !trace.IsNotFound(err) {
+		return applyItem{}, trace.Wrap(err)
+	}
+	if trace.IsNotFound(err) {
+		item.action = applyActionCreate
+		item.apply = func(ctx context.Context) error {
+			return trace.Wrap(client.CreateApp(ctx, app))
+		}
+		return item, nil
+	}
+	if item.action, item.diff, err = resourceDiff(stored, app); err != nil {
+		return applyItem{}, trace.Wrap(err)
+	}
+	item.apply = func(ctx context.Context) error {
+		return trace.Wrap(client.UpdateApp(ctx, app))
+	}
+	return item, nil
+}
+
+// planAuthPreference plans the change for the cluster auth preference
+// singleton.
+func planAuthPreference(ctx context.Context, client auth.ClientI, raw services.UnknownResource) (applyItem, error) {
+	newAuthPref, err := services.UnmarshalAuthPreference(raw.Raw)
+	if err != nil {
+		return applyItem{}, trace.Wrap(err)
+	}
+	stored, err := client.GetAuthPreference(ctx)
+	if err != nil {
+		return applyItem{}, trace.Wrap(err)
+	}
+	if stored.Origin() == types.OriginConfigFile {
+		return applyItem{}, trace.BadParameter("cluster auth preference is managed by static configuration, remove it from teleport.yaml to manage it with 'tctl resources apply'")
+	}
+	item := applyItem{
+		description: "cluster auth preference",
+		apply: func(ctx context.Context) error {
+			return trace.Wrap(client.SetAuthPreference(ctx, newAuthPref))
+		},
+	}
+	if item.action, item.diff, err = resourceDiff(stored, newAuthPref); err != nil {
+		return applyItem{}, trace.Wrap(err)
+	}
+	return item, nil
+}
+
+// resourceDiff compares the stored and desired versions of a resource by
+// their YAML representations and returns the action applying the desired
+// version would take, together with a line diff for updates.
+func resourceDiff(stored, desired types.Resource) (string, string, error) {
+	// Resource IDs change on every write and are not part of the desired
+	// state, leave them out of the comparison.
+	stored.SetResourceID(0)
+	desired.SetResourceID(0)
+	storedYAML, err := resourceYAML(stored)
+	if err != nil {
+		return "", "", trace.Wrap(err)
+	}
+	desiredYAML, err := resourceYAML(desired)
+	if err != nil {
+		return "", "", trace.Wrap(err)
+	}
+	if storedYAML == desiredYAML {
+		return applyActionUnchanged, "", nil
+	}
+	return applyActionUpdate, diff.Diff(storedYAML, desiredYAML), nil
+}
+
+// resourceYAML returns the YAML representation of a resource.
+func resourceYAML(resource types.Resource) (string, error) {
+	var buf bytes.Buffer
+	if err := utils.WriteYAML(&buf, resource); err != nil {
+		return "", trace.Wrap(err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gravitational/teleport/api/types"
+
+	"github.com/gravitational/trace"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadResourceDir(t *testing.T) {
+	dir := t.TempDir()
+	// Files are read in lexical order, multiple documents per file are
+	// supported and non-YAML files are skipped.
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "02-roles.yaml"), []byte(`kind: role
+version: v4
+metadata:
+  name: admin
+spec: {}
+---
+kind: role
+version: v4
+metadata:
+  name: dev
+spec: {}
+`), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "01-app.yml"), []byte(`kind: app
+version: v3
+metadata:
+  name: grafana
+spec:
+  uri: http://localhost:3000
+`), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "README.md"), []byte("not a resource"), 0644))
+
+	resources, err := readResourceDir(dir)
+	require.NoError(t, err)
+	require.Len(t, resources, 3)
+	require.Equal(t, types.KindApp, resources[0].Kind)
+	require.Equal(t, types.KindRole, resources[1].Kind)
+	require.Equal(t, types.KindRole, resources[2].Kind)
+
+	// A directory without YAML files is reported as not found.
+	empty := t.TempDir()
+	_, err = readResourceDir(empty)
+	require.True(t, trace.IsNotFound(err))
+
+	// A broken definition fails the whole read.
+	require.NoError(t, os.Remove(filepath.Join(dir, "README.md")))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "03-broken.yaml"), []byte(`{kind: [`), 0644))
+	_, err = readResourceDir(dir)
+	require.Error(t, err)
+}
+
+func TestResourceDiff(t *testing.T) {
+	stored, err := types.NewRole("admin", types.RoleSpecV4{})
+	require.NoError(t, err)
+	desired, err := types.NewRole("admin", types.RoleSpecV4{})
+	require.NoError(t, err)
+
+	// Differing resource IDs alone don't count as a change.
+	stored.SetResourceID(42)
+	action, diff, err := resourceDiff(stored, desired)
+	require.NoError(t, err)
+	require.Equal(t, applyActionUnchanged, action)
+	require.Empty(t, diff)
+
+	desired.SetLogins(types.Allow, []string{"root"})
+	action, diff, err = resourceDiff(stored, desired)
+	require.NoError(t, err)
+	require.Equal(t, applyActionUpdate, action)
+	require.Contains(t, diff, "root")
+}
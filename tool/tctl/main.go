@@ -27,6 +27,7 @@ func main() {
 		&common.TokenCommand{},
 		&common.AuthCommand{},
 		&common.ResourceCommand{},
+		&common.ApplyCommand{},
 		&common.StatusCommand{},
 		&common.TopCommand{},
 		&common.AccessRequestCommand{},
@@ -0,0 +1,188 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/gravitational/teleport/api/types"
+	awslib "github.com/gravitational/teleport/lib/cloud/aws"
+	"github.com/gravitational/teleport/lib/config"
+	"github.com/gravitational/teleport/lib/service"
+	"github.com/gravitational/teleport/lib/srv/db/cloud"
+	"github.com/gravitational/teleport/lib/srv/db/common"
+
+	"github.com/gravitational/trace"
+)
+
+// dbConfigureAWSFlags contains CLI flags for the "db configure aws" commands.
+type dbConfigureAWSFlags struct {
+	// ConfigPath is the path to the Teleport configuration file.
+	ConfigPath string
+	// PolicyName is the name of the IAM policy to create.
+	PolicyName string
+	// AttachToRole is the IAM role name or ARN to attach the policy to.
+	AttachToRole string
+	// AttachToUser is the IAM user name or ARN to attach the policy to.
+	AttachToUser string
+}
+
+// onDatabaseConfigureAWSPrint is the handler for "db configure aws print-iam".
+//
+// It prints the IAM policy and permissions boundary the database agent
+// requires based on the databases in the configuration file.
+func onDatabaseConfigureAWSPrint(flags dbConfigureAWSFlags) error {
+	bootstrapConfig, err := readBootstrapConfig(flags.ConfigPath)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	policy, err := cloud.BuildBootstrapPolicy(*bootstrapConfig)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	boundary, err := cloud.BuildBootstrapBoundary(*bootstrapConfig)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	policyJSON, err := json.MarshalIndent(policy, "", "    ")
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	boundaryJSON, err := json.MarshalIndent(boundary, "", "    ")
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	fmt.Printf("Attach the following policy to the database agent's IAM identity:\n\n%v\n\n", string(policyJSON))
+	fmt.Printf("Use the following permissions boundary for the database agent's IAM identity:\n\n%v\n", string(boundaryJSON))
+	return nil
+}
+
+// onDatabaseConfigureAWSCreate is the handler for "db configure aws create-iam".
+//
+// It generates the IAM policy the database agent requires and attaches it
+// to the agent's IAM identity via the IAM API.
+func onDatabaseConfigureAWSCreate(flags dbConfigureAWSFlags) error {
+	ctx := context.TODO()
+	bootstrapConfig, err := readBootstrapConfig(flags.ConfigPath)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	region, err := awsRegionFromConfig(bootstrapConfig)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	clients := common.NewCloudClients()
+	defer clients.Close()
+	identity, err := awsIdentity(ctx, clients, region, flags)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	bootstrapConfig.Identity = identity
+	policy, err := cloud.BuildBootstrapPolicy(*bootstrapConfig)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	iamClient, err := clients.GetAWSIAMClient(region)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	err = cloud.AttachBootstrapPolicy(ctx, iamClient, identity, flags.PolicyName, policy)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	fmt.Printf("Attached policy %q to %v.\n", flags.PolicyName, identity)
+	return nil
+}
+
+// readBootstrapConfig parses the Teleport configuration file and returns
+// the databases and matchers relevant to generating IAM policies.
+func readBootstrapConfig(configPath string) (*cloud.BootstrapConfig, error) {
+	conf := service.MakeDefaultConfig()
+	if err := config.Configure(&config.CommandLineFlags{
+		ConfigFile: configPath,
+	}, conf); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var databases types.Databases
+	for _, db := range conf.Databases.Databases {
+		database, err := types.NewDatabaseV3(types.Metadata{
+			Name:   db.Name,
+			Labels: db.StaticLabels,
+		}, types.DatabaseSpecV3{
+			Protocol: db.Protocol,
+			URI:      db.URI,
+			AWS: types.AWS{
+				Region: db.AWS.Region,
+				Redshift: types.Redshift{
+					ClusterID: db.AWS.Redshift.ClusterID,
+				},
+				RDS: types.RDS{
+					InstanceID: db.AWS.RDS.InstanceID,
+					ClusterID:  db.AWS.RDS.ClusterID,
+				},
+			},
+		})
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		databases = append(databases, database)
+	}
+	return &cloud.BootstrapConfig{
+		Databases:   databases,
+		AWSMatchers: conf.Databases.AWSMatchers,
+	}, nil
+}
+
+// awsRegionFromConfig returns an AWS region the IAM clients should use.
+func awsRegionFromConfig(config *cloud.BootstrapConfig) (string, error) {
+	for _, database := range config.Databases {
+		if database.GetAWS().Region != "" {
+			return database.GetAWS().Region, nil
+		}
+	}
+	for _, matcher := range config.AWSMatchers {
+		if len(matcher.Regions) != 0 {
+			return matcher.Regions[0], nil
+		}
+	}
+	return "", trace.NotFound("configuration contains no AWS databases or AWS matchers")
+}
+
+// awsIdentity determines the IAM identity to attach the policy to, either
+// from the --attach-to-role/--attach-to-user flags or from the credentials
+// the command is running with.
+func awsIdentity(ctx context.Context, clients common.CloudClients, region string, flags dbConfigureAWSFlags) (awslib.Identity, error) {
+	stsClient, err := clients.GetAWSSTSClient(region)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	identity, err := awslib.GetIdentityWithClient(ctx, stsClient)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	switch {
+	case flags.AttachToRole != "":
+		return awslib.IdentityFromArn(fmt.Sprintf("arn:%v:iam::%v:role/%v",
+			identity.GetPartition(), identity.GetAccountID(), flags.AttachToRole))
+	case flags.AttachToUser != "":
+		return awslib.IdentityFromArn(fmt.Sprintf("arn:%v:iam::%v:user/%v",
+			identity.GetPartition(), identity.GetAccountID(), flags.AttachToUser))
+	}
+	return identity, nil
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/gravitational/teleport/api/types"
+	awslib "github.com/gravitational/teleport/lib/cloud/aws"
+	"github.com/gravitational/teleport/lib/srv/db/common"
+
+	"github.com/aws/aws-sdk-go/service/rds/rdsutils"
+
+	"github.com/gravitational/trace"
+)
+
+// dbDiagFlags contains CLI flags for the "db diag" command.
+type dbDiagFlags struct {
+	// ConfigPath is the path to the Teleport configuration file.
+	ConfigPath string
+	// Database is the name of the database to diagnose, empty for all.
+	Database string
+}
+
+// dbDiagTimeout is the timeout for a single diagnostic step.
+const dbDiagTimeout = 10 * time.Second
+
+// onDatabaseDiag is the handler for "teleport db diag".
+//
+// It walks the connection path from the agent to each configured database -
+// network connectivity, TLS handshake, cloud IAM credentials and auth token
+// generation - and reports which step failed with a remediation hint, so
+// operators don't have to guess from generic access denied errors.
+func onDatabaseDiag(flags dbDiagFlags) error {
+	ctx := context.TODO()
+	bootstrapConfig, err := readBootstrapConfig(flags.ConfigPath)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	clients := common.NewCloudClients()
+	defer clients.Close()
+	var found bool
+	for _, database := range bootstrapConfig.Databases {
+		if flags.Database != "" && database.GetName() != flags.Database {
+			continue
+		}
+		found = true
+		diagnoseDatabase(ctx, clients, database)
+	}
+	if !found {
+		if flags.Database != "" {
+			return trace.NotFound("database %q is not present in the configuration file", flags.Database)
+		}
+		return trace.NotFound("configuration file contains no databases")
+	}
+	return nil
+}
+
+// diagnoseDatabase runs the diagnostic steps for a single database and
+// prints the results.
+func diagnoseDatabase(ctx context.Context, clients common.CloudClients, database types.Database) {
+	fmt.Printf("Diagnostics for database %q (%v %v):\n",
+		database.GetName(), database.GetProtocol(), database.GetURI())
+	diagReport("network connectivity", diagDial(database),
+		"make sure the database address is reachable from the agent and firewall/security group rules allow the connection")
+	diagReport("TLS handshake", diagTLS(database),
+		"make sure the database presents a certificate issued by a CA the agent trusts, or set ca_cert_file in the database configuration")
+	if aws := database.GetAWS(); aws.Region != "" {
+		diagReport("AWS credentials", diagAWSCredentials(ctx, clients, database),
+			"make sure the agent has AWS credentials available, e.g. via an attached IAM role or environment variables")
+		if database.IsRDS() {
+			diagReport("RDS IAM auth token", diagRDSAuthToken(ctx, clients, database),
+				`make sure the agent's IAM policy allows "rds-db:connect", see "teleport db configure aws print-iam"`)
+		}
+	}
+	fmt.Println()
+}
+
+// diagReport prints the result of a single diagnostic step, including the
+// remediation hint when the step failed.
+func diagReport(step string, err error, remediation string) {
+	if err != nil {
+		fmt.Printf("  ✗ %v: %v\n    %v\n", step, err, remediation)
+		return
+	}
+	fmt.Printf("  ✓ %v\n", step)
+}
+
+// diagDial verifies the database endpoint accepts TCP connections from
+// the agent.
+func diagDial(database types.Database) error {
+	if _, _, err := net.SplitHostPort(database.GetURI()); err != nil {
+		return trace.BadParameter("database URI %q is not a host:port address", database.GetURI())
+	}
+	conn, err := net.DialTimeout("tcp", database.GetURI(), dbDiagTimeout)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	return conn.Close()
+}
+
+// diagTLS verifies the agent can complete a TLS handshake with the
+// database endpoint.
+//
+// Databases that negotiate TLS in-protocol after a plaintext handshake
+// (e.g. Postgres SSLRequest, MySQL STARTTLS) will report a failure here
+// even when they're configured correctly, so the result is informational
+// for those protocols.
+func diagTLS(database types.Database) error {
+	host, _, err := net.SplitHostPort(database.GetURI())
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: dbDiagTimeout}, "tcp",
+		database.GetURI(), &tls.Config{ServerName: host})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return conn.Close()
+}
+
+// diagAWSCredentials verifies the agent has AWS credentials by determining
+// its IAM identity.
+func diagAWSCredentials(ctx context.Context, clients common.CloudClients, database types.Database) error {
+	stsClient, err := clients.GetAWSSTSClient(database.GetAWS().Region)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	identity, err := awslib.GetIdentityWithClient(ctx, stsClient)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	fmt.Printf("    agent's AWS identity: %v\n", identity)
+	return nil
+}
+
+// diagRDSAuthToken verifies an RDS IAM authentication token can be
+// generated with the agent's credentials.
+func diagRDSAuthToken(ctx context.Context, clients common.CloudClients, database types.Database) error {
+	awsSession, err := clients.GetAWSSession(database.GetAWS().Region)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	_, err = rdsutils.BuildAuthToken(
+		database.GetURI(),
+		database.GetAWS().Region,
+		"teleport-diag",
+		awsSession.Config.Credentials)
+	return trace.Wrap(err)
+}
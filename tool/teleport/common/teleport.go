@@ -67,6 +67,8 @@ func Run(options Options) (app *kingpin.Application, executedCommand string, con
 	var ccf config.CommandLineFlags
 	var scpFlags scp.Flags
 	var dumpFlags dumpFlags
+	var dbConfigureAWS dbConfigureAWSFlags
+	var dbDiag dbDiagFlags
 
 	// define commands:
 	start := app.Command("start", "Starts the Teleport service.")
@@ -76,6 +78,7 @@ func Run(options Options) (app *kingpin.Application, executedCommand string, con
 	scpc := app.Command("scp", "Server-side implementation of SCP.").Hidden()
 	exec := app.Command("exec", "Used internally by Teleport to re-exec itself to run a command.").Hidden()
 	forward := app.Command("forward", "Used internally by Teleport to re-exec itself to port forward.").Hidden()
+	sftp := app.Command("sftp", "Used internally by Teleport to re-exec itself to serve the SFTP subsystem.").Hidden()
 	app.HelpFlag.Short('h')
 
 	// define start flags:
@@ -206,6 +209,20 @@ func Run(options Options) (app *kingpin.Application, executedCommand string, con
 	dbStartCmd.Flag("insecure", "Insecure mode disables certificate validation").BoolVar(&ccf.InsecureMode)
 	dbStartCmd.Alias(dbUsageExamples) // We're using "alias" section to display usage examples.
 
+	dbConfigureCmd := dbCmd.Command("configure", "Bootstrap cloud provider configuration for the database service.")
+	dbConfigureAWSCmd := dbConfigureCmd.Command("aws", "Manage IAM policies for AWS hosted databases.")
+	dbConfigureAWSPrintCmd := dbConfigureAWSCmd.Command("print-iam", "Print the IAM policy and permissions boundary the database agent requires.")
+	dbConfigureAWSPrintCmd.Flag("config", fmt.Sprintf("Path to a configuration file [%v].", defaults.ConfigFilePath)).Short('c').Default(defaults.ConfigFilePath).StringVar(&dbConfigureAWS.ConfigPath)
+	dbConfigureAWSCreateCmd := dbConfigureAWSCmd.Command("create-iam", "Generate the required IAM policy and attach it to the agent's IAM identity.")
+	dbConfigureAWSCreateCmd.Flag("config", fmt.Sprintf("Path to a configuration file [%v].", defaults.ConfigFilePath)).Short('c').Default(defaults.ConfigFilePath).StringVar(&dbConfigureAWS.ConfigPath)
+	dbConfigureAWSCreateCmd.Flag("name", "Name of the IAM policy to create.").Default("teleport-db-access").StringVar(&dbConfigureAWS.PolicyName)
+	dbConfigureAWSCreateCmd.Flag("attach-to-role", "IAM role name to attach the policy to. Defaults to the current identity.").StringVar(&dbConfigureAWS.AttachToRole)
+	dbConfigureAWSCreateCmd.Flag("attach-to-user", "IAM user name to attach the policy to. Defaults to the current identity.").StringVar(&dbConfigureAWS.AttachToUser)
+
+	dbDiagCmd := dbCmd.Command("diag", "Diagnose connectivity from the database agent to its configured databases.")
+	dbDiagCmd.Flag("config", fmt.Sprintf("Path to a configuration file [%v].", defaults.ConfigFilePath)).Short('c').Default(defaults.ConfigFilePath).StringVar(&dbDiag.ConfigPath)
+	dbDiagCmd.Flag("name", "Name of the database to diagnose. Defaults to all configured databases.").StringVar(&dbDiag.Database)
+
 	// define a hidden 'scp' command (it implements server-side implementation of handling
 	// 'scp' requests)
 	scpc.Flag("t", "sink mode (data consumer)").Short('t').Default("false").BoolVar(&scpFlags.Sink)
@@ -275,10 +292,18 @@ func Run(options Options) (app *kingpin.Application, executedCommand string, con
 		err = onStatus()
 	case dump.FullCommand():
 		err = onConfigDump(dumpFlags)
+	case dbConfigureAWSPrintCmd.FullCommand():
+		err = onDatabaseConfigureAWSPrint(dbConfigureAWS)
+	case dbConfigureAWSCreateCmd.FullCommand():
+		err = onDatabaseConfigureAWSCreate(dbConfigureAWS)
+	case dbDiagCmd.FullCommand():
+		err = onDatabaseDiag(dbDiag)
 	case exec.FullCommand():
 		err = onExec()
 	case forward.FullCommand():
 		err = onForward()
+	case sftp.FullCommand():
+		err = onSFTP()
 	case ver.FullCommand():
 		utils.PrintVersion()
 	}
@@ -490,6 +515,13 @@ func onForward() error {
 	return nil
 }
 
+// onSFTP is a subcommand used to re-execute Teleport to serve the SFTP
+// subsystem as the login user on Teleport nodes.
+func onSFTP() error {
+	srv.RunAndExit(teleport.SFTPSubCommand)
+	return nil
+}
+
 type StdReadWriter struct {
 }
 
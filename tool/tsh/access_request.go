@@ -269,6 +269,51 @@ func onRequestReview(cf *CLIConf) error {
 	return nil
 }
 
+func onRequestAssume(cf *CLIConf) error {
+	tc, err := makeClient(cf, false)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	if cf.Username == "" {
+		cf.Username = tc.Username
+	}
+
+	var req types.AccessRequest
+	err = tc.WithRootClusterClient(cf.Context, func(clt auth.ClientI) error {
+		req, err = services.GetAccessRequest(cf.Context, clt, cf.RequestID)
+		return trace.Wrap(err)
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	if req.GetUser() != cf.Username {
+		return trace.BadParameter(`invalid access request %q`, cf.RequestID)
+	}
+	if !req.GetState().IsApproved() {
+		return trace.BadParameter("access request %q is %s, only approved requests can be assumed", cf.RequestID, req.GetState())
+	}
+
+	// Make sure a requested window fits inside the request's own access
+	// expiry; the auth server caps the certificates either way, but a clear
+	// client-side error beats a silently shorter elevation.
+	if cf.AssumeTTL > 0 {
+		if expiry := req.GetAccessExpiry(); time.Now().Add(cf.AssumeTTL).After(expiry) {
+			return trace.BadParameter("requested TTL exceeds the request's access expiry (%v)", expiry.Format(time.RFC822))
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Assuming request %s, getting updated certificates...\n", req.GetName())
+
+	if err := reissueWithRequests(cf, tc, req.GetName()); err != nil {
+		return trace.Wrap(err)
+	}
+
+	onStatus(cf)
+	return nil
+}
+
 func showRequestTable(reqs []types.AccessRequest) error {
 	sort.Slice(reqs, func(i, j int) bool {
 		return reqs[i].GetCreationTime().After(reqs[j].GetCreationTime())
@@ -87,6 +87,11 @@ func onAppLogin(cf *CLIConf) error {
 			"awsCmd":     "s3 ls",
 		})
 	}
+	if app.IsTCP() {
+		return appLoginTCPTpl.Execute(os.Stdout, map[string]string{
+			"appName": app.GetName(),
+		})
+	}
 	return appLoginTpl.Execute(os.Stdout, map[string]string{
 		"appName": app.GetName(),
 		"curlCmd": formatAppConfig(tc, profile, app.GetName(), app.GetPublicAddr(), appFormatCURL),
@@ -100,6 +105,16 @@ var appLoginTpl = template.Must(template.New("").Parse(
 {{.curlCmd}}
 `))
 
+// appLoginTCPTpl is the message that gets printed to a user upon successful
+// login into a TCP application.
+var appLoginTCPTpl = template.Must(template.New("").Parse(
+	`Logged into TCP app {{.appName}}. Start the local TCP proxy for it:
+
+  tsh proxy app {{.appName}}
+
+Then connect to the application through this proxy.
+`))
+
 // awsCliTpl is the message that gets printed to a user upon successful aws app login.
 var awsCliTpl = template.Must(template.New("").Parse(
 	`Logged into AWS app {{.awsAppName}}. Example AWS cli command:
@@ -0,0 +1,154 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+
+	"github.com/gravitational/teleport/lib/events"
+	"github.com/gravitational/teleport/lib/srv/desktop/tdp"
+	"github.com/gravitational/trace"
+)
+
+// exportFrameRate is the frame rate of exported MP4 videos.
+const exportFrameRate = 30
+
+// onExportVideo exports a recorded desktop session to an MP4 file by
+// replaying the TDP stream and piping the reconstructed screen frames to
+// ffmpeg, which must be installed on the machine running tsh.
+func onExportVideo(cf *CLIConf) error {
+	tc, err := makeClient(cf, true)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	// The print events carry the timing metadata for the raw stream.
+	sessionEvents, err := tc.GetSessionEvents(cf.Context, cf.Namespace, cf.SessionID)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	stream, err := tc.GetSessionStream(cf.Context, cf.Namespace, cf.SessionID)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	// Each print event covers a contiguous chunk of the stream and records
+	// the delay since the session start at which it was captured.
+	type chunk struct {
+		end, ms int64
+	}
+	var chunks []chunk
+	for _, e := range sessionEvents {
+		if e.GetType() != events.SessionPrintEvent {
+			continue
+		}
+		chunks = append(chunks, chunk{
+			end: int64(e.GetInt(events.SessionByteOffset) + e.GetInt(events.SessionPrintEventBytes)),
+			ms:  int64(e.GetInt(events.SessionEventTimestamp)),
+		})
+	}
+
+	// Decode the stream into messages, each stamped with the capture time
+	// of the chunk it ends in.
+	type timedMessage struct {
+		m  tdp.Message
+		ms int64
+	}
+	var messages []timedMessage
+	var width, height int
+	player := tdp.NewPlayer(bytes.NewReader(stream))
+	ci := 0
+	for {
+		m, offset, err := player.NextMessage()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		for ci < len(chunks)-1 && offset > chunks[ci].end {
+			ci++
+		}
+		var ms int64
+		if ci < len(chunks) {
+			ms = chunks[ci].ms
+		}
+		messages = append(messages, timedMessage{m: m, ms: ms})
+		if s, ok := m.(tdp.ClientScreenSpec); ok && width == 0 {
+			width, height = int(s.Width), int(s.Height)
+		}
+	}
+	if width == 0 {
+		return trace.NotFound("the recording of session %v contains no desktop screen data; only desktop sessions can be exported to MP4", cf.SessionID)
+	}
+
+	ffmpeg, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return trace.NotFound("MP4 export requires the ffmpeg binary in PATH")
+	}
+	out := cf.SessionID + ".mp4"
+	cmd := exec.CommandContext(cf.Context, ffmpeg,
+		"-y", "-loglevel", "error",
+		"-f", "rawvideo",
+		"-pixel_format", "rgba",
+		"-video_size", fmt.Sprintf("%dx%d", width, height),
+		"-framerate", strconv.Itoa(exportFrameRate),
+		"-i", "-",
+		// yuv420p requires even dimensions, pad by a pixel if needed.
+		"-vf", "pad=ceil(iw/2)*2:ceil(ih/2)*2",
+		"-pix_fmt", "yuv420p",
+		out,
+	)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if err := cmd.Start(); err != nil {
+		return trace.Wrap(err)
+	}
+
+	// Replay the session at a fixed frame rate onto a fixed-size canvas;
+	// mid-session resizes are clipped or padded to the initial size.
+	canvas := image.NewNRGBA(image.Rect(0, 0, width, height))
+	const msPerFrame = 1000 / exportFrameRate
+	i := 0
+	for t := int64(0); i < len(messages); t += msPerFrame {
+		for i < len(messages) && messages[i].ms <= t {
+			player.Apply(messages[i].m)
+			i++
+		}
+		if screen := player.Screen(); screen != nil {
+			draw.Draw(canvas, canvas.Rect, screen, image.Point{}, draw.Src)
+		}
+		if _, err := stdin.Write(canvas.Pix); err != nil {
+			break
+		}
+	}
+	stdin.Close()
+	if err := cmd.Wait(); err != nil {
+		return trace.Wrap(err, "ffmpeg failed to export the session")
+	}
+	fmt.Printf("Exported session %v to %v\n", cf.SessionID, out)
+	return nil
+}
@@ -193,6 +193,74 @@ func onProxyCommandDB(cf *CLIConf) error {
 	return nil
 }
 
+// onProxyCommandApp creates a local proxy for TCP application connections.
+//
+// The created proxy can be used to connect to the application through the
+// Teleport Proxy with any TCP client, the user identity is passed in the
+// application certificate obtained with "tsh app login".
+func onProxyCommandApp(cf *CLIConf) error {
+	tc, err := makeClient(cf, false)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	app, err := pickActiveApp(cf)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	profile, err := libclient.StatusCurrent(cf.HomePath, cf.Proxy)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	cert, err := tls.LoadX509KeyPair(profile.AppCertPath(app.Name), profile.KeyPath())
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	address, err := utils.ParseAddr(tc.WebProxyAddr)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	addr := "localhost:0"
+	if cf.LocalProxyPort != "" {
+		addr = fmt.Sprintf("127.0.0.1:%s", cf.LocalProxyPort)
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	lp, err := alpnproxy.NewLocalProxy(alpnproxy.LocalProxyConfig{
+		RemoteProxyAddr:    tc.WebProxyAddr,
+		Protocol:           alpncommon.ProtocolTCP,
+		InsecureSkipVerify: cf.InsecureSkipVerify,
+		Listener:           listener,
+		ParentContext:      cf.Context,
+		SNI:                address.Host(),
+		Certs:              []tls.Certificate{cert},
+	})
+	if err != nil {
+		if cerr := listener.Close(); cerr != nil {
+			return trace.NewAggregate(err, cerr)
+		}
+		return trace.Wrap(err)
+	}
+
+	fmt.Printf("Proxying connections to %s on %v\n", app.Name, lp.GetAddr())
+
+	go func() {
+		<-cf.Context.Done()
+		lp.Close()
+	}()
+
+	defer lp.Close()
+	if err = lp.Start(cf.Context); err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
 func mkLocalProxy(cf *CLIConf, remoteProxyAddr string, protocol string, listener net.Listener) (*alpnproxy.LocalProxy, error) {
 	alpnProtocol, err := toALPNProtocol(protocol)
 	if err != nil {
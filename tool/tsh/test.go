@@ -0,0 +1,279 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/gravitational/teleport/api/client/proto"
+	"github.com/gravitational/teleport/api/types"
+	apiutils "github.com/gravitational/teleport/api/utils"
+	"github.com/gravitational/teleport/lib/client"
+	kubeutils "github.com/gravitational/teleport/lib/kube/utils"
+
+	"github.com/gravitational/trace"
+)
+
+// Resource kinds accepted by "tsh test access".
+const (
+	testKindNode = "node"
+	testKindDB   = "db"
+	testKindKube = "kube"
+	testKindApp  = "app"
+)
+
+// onTestAccess implements "tsh test access". It performs a dry-run
+// connection to the requested resource using the user's current
+// certificates and prints each step as it goes, so access problems can
+// be narrowed down to a specific hop without opening a live session.
+func onTestAccess(cf *CLIConf) error {
+	tc, err := makeClient(cf, false)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	t := accessTest{out: os.Stdout}
+	fmt.Fprintf(t.out, "Testing access to %v %q:\n", cf.TestResourceKind, cf.TestResourceName)
+	err = client.RetryWithRelogin(cf.Context, tc, func() error {
+		switch cf.TestResourceKind {
+		case testKindNode:
+			return trace.Wrap(t.testNode(cf, tc))
+		case testKindDB:
+			return trace.Wrap(t.testDatabase(cf, tc))
+		case testKindKube:
+			return trace.Wrap(t.testKube(cf, tc))
+		case testKindApp:
+			return trace.Wrap(t.testApp(cf, tc))
+		}
+		return trace.BadParameter("unsupported resource kind %q", cf.TestResourceKind)
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	fmt.Fprintf(t.out, "Access to %v %q verified.\n", cf.TestResourceKind, cf.TestResourceName)
+	return nil
+}
+
+// accessTest prints the step-by-step trace of a dry-run connection.
+type accessTest struct {
+	out io.Writer
+}
+
+// step runs fn, printing name before and the outcome after. A failed
+// step aborts the test, so the caller should return the error as-is.
+func (t accessTest) step(name string, fn func() error) error {
+	fmt.Fprintf(t.out, "  %v... ", name)
+	if err := fn(); err != nil {
+		fmt.Fprintln(t.out, "FAILED")
+		return trace.Wrap(err)
+	}
+	fmt.Fprintln(t.out, "OK")
+	return nil
+}
+
+// testNode checks access to an SSH node by performing the full SSH
+// handshake (proxy subsystem, node dial, certificate authentication)
+// without starting a session.
+func (t accessTest) testNode(cf *CLIConf, tc *client.TeleportClient) error {
+	var proxyClient *client.ProxyClient
+	err := t.step(fmt.Sprintf("connecting to proxy %v", tc.Config.SSHProxyAddr), func() error {
+		var err error
+		proxyClient, err = tc.ConnectToProxy(cf.Context)
+		return trace.Wrap(err)
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer proxyClient.Close()
+
+	// The node listing returned by the auth server is already filtered by
+	// the user's roles, so a successful lookup doubles as the RBAC check.
+	var nodeAddr string
+	err = t.step(fmt.Sprintf("looking up node %q in cluster %q (checks RBAC)", cf.TestResourceName, tc.SiteName), func() error {
+		nodes, err := proxyClient.FindServersByLabels(cf.Context, tc.Namespace, nil)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		for _, node := range nodes {
+			if node.GetHostname() != cf.TestResourceName {
+				continue
+			}
+			nodeAddr = node.GetAddr()
+			if nodeAddr == "" {
+				// Address is empty for tunnel nodes, dial by UUID instead.
+				nodeAddr = fmt.Sprintf("%s:0", node.GetName())
+			}
+			return nil
+		}
+		return trace.NotFound("node %q is not registered or not allowed by your roles", cf.TestResourceName)
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	err = t.step(fmt.Sprintf("establishing SSH connection to %v as %q", nodeAddr, tc.Config.HostLogin), func() error {
+		nodeClient, err := proxyClient.ConnectToNode(cf.Context, client.NodeAddr{
+			Addr:      nodeAddr,
+			Namespace: tc.Namespace,
+			Cluster:   tc.SiteName,
+		}, tc.Config.HostLogin, true)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		return trace.Wrap(nodeClient.Close())
+	})
+	return trace.Wrap(err)
+}
+
+// testDatabase checks access to a database by looking the database up
+// and requesting a database access certificate for it, which is where
+// the auth server enforces the database-related role options.
+func (t accessTest) testDatabase(cf *CLIConf, tc *client.TeleportClient) error {
+	var proxyClient *client.ProxyClient
+	err := t.step(fmt.Sprintf("connecting to proxy %v", tc.Config.SSHProxyAddr), func() error {
+		var err error
+		proxyClient, err = tc.ConnectToProxy(cf.Context)
+		return trace.Wrap(err)
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer proxyClient.Close()
+
+	var database types.Database
+	err = t.step(fmt.Sprintf("looking up database %q in cluster %q (checks RBAC)", cf.TestResourceName, tc.SiteName), func() error {
+		servers, err := proxyClient.GetDatabaseServers(cf.Context, tc.Namespace)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		for _, server := range servers {
+			if server.GetDatabase().GetName() == cf.TestResourceName {
+				database = server.GetDatabase()
+				return nil
+			}
+		}
+		return trace.NotFound("database %q is not registered or not allowed by your roles", cf.TestResourceName)
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	err = t.step(fmt.Sprintf("requesting database certificate for %q", database.GetName()), func() error {
+		_, err := tc.IssueUserCertsWithMFA(cf.Context, client.ReissueParams{
+			RouteToCluster: tc.SiteName,
+			RouteToDatabase: proto.RouteToDatabase{
+				ServiceName: database.GetName(),
+				Protocol:    database.GetProtocol(),
+				Username:    cf.DatabaseUser,
+				Database:    cf.DatabaseName,
+			},
+		})
+		return trace.Wrap(err)
+	})
+	return trace.Wrap(err)
+}
+
+// testKube checks access to a Kubernetes cluster by verifying it is
+// visible to the user and requesting a certificate routed to it.
+func (t accessTest) testKube(cf *CLIConf, tc *client.TeleportClient) error {
+	var proxyClient *client.ProxyClient
+	err := t.step(fmt.Sprintf("connecting to proxy %v", tc.Config.SSHProxyAddr), func() error {
+		var err error
+		proxyClient, err = tc.ConnectToProxy(cf.Context)
+		return trace.Wrap(err)
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer proxyClient.Close()
+
+	err = t.step(fmt.Sprintf("looking up Kubernetes cluster %q in cluster %q", cf.TestResourceName, tc.SiteName), func() error {
+		ac, err := proxyClient.ConnectToCurrentCluster(cf.Context, true)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		defer ac.Close()
+		kubeClusters, err := kubeutils.KubeClusterNames(cf.Context, ac)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if !apiutils.SliceContainsStr(kubeClusters, cf.TestResourceName) {
+			return trace.NotFound("Kubernetes cluster %q is not registered in this Teleport cluster", cf.TestResourceName)
+		}
+		return nil
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	err = t.step(fmt.Sprintf("requesting Kubernetes certificate for %q (checks RBAC)", cf.TestResourceName), func() error {
+		_, err := tc.IssueUserCertsWithMFA(cf.Context, client.ReissueParams{
+			RouteToCluster:    tc.SiteName,
+			KubernetesCluster: cf.TestResourceName,
+		})
+		return trace.Wrap(err)
+	})
+	return trace.Wrap(err)
+}
+
+// testApp checks access to an application by looking it up and creating
+// (then immediately deleting) an app session for it.
+func (t accessTest) testApp(cf *CLIConf, tc *client.TeleportClient) error {
+	var proxyClient *client.ProxyClient
+	err := t.step(fmt.Sprintf("connecting to proxy %v", tc.Config.SSHProxyAddr), func() error {
+		var err error
+		proxyClient, err = tc.ConnectToProxy(cf.Context)
+		return trace.Wrap(err)
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer proxyClient.Close()
+
+	var app types.Application
+	err = t.step(fmt.Sprintf("looking up application %q in cluster %q (checks RBAC)", cf.TestResourceName, tc.SiteName), func() error {
+		servers, err := proxyClient.GetAppServers(cf.Context, tc.Namespace)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		for _, server := range servers {
+			if server.GetApp().GetName() == cf.TestResourceName {
+				app = server.GetApp()
+				return nil
+			}
+		}
+		return trace.NotFound("application %q is not registered or not allowed by your roles", cf.TestResourceName)
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	err = t.step(fmt.Sprintf("creating app session for %q", app.GetName()), func() error {
+		ws, err := proxyClient.CreateAppSession(cf.Context, types.CreateAppSessionRequest{
+			Username:    tc.Username,
+			PublicAddr:  app.GetPublicAddr(),
+			ClusterName: tc.SiteName,
+		})
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		// The session was only created to prove it can be; clean it up.
+		return trace.Wrap(proxyClient.DeleteAppSession(cf.Context, ws.GetName()))
+	})
+	return trace.Wrap(err)
+}
@@ -86,6 +86,9 @@ type CLIConf struct {
 	NoWait bool
 	// RequestID is an access request ID
 	RequestID string
+	// AssumeTTL caps how long the roles of an assumed access request stay
+	// active before the elevation is dropped.
+	AssumeTTL time.Duration
 	// ReviewReason indicates the reason for an access review.
 	ReviewReason string
 	// ReviewableRequests indicates that only requests which can be reviewed should
@@ -141,6 +144,11 @@ type CLIConf struct {
 	DatabaseName string
 	// AppName specifies proxied application name.
 	AppName string
+	// TestResourceKind is the kind of resource "tsh test access" dry-runs
+	// a connection to: node, db, kube or app.
+	TestResourceKind string
+	// TestResourceName is the name of the resource for "tsh test access".
+	TestResourceName string
 	// Interactive, when set to true, launches remote command with the terminal attached
 	Interactive bool
 	// Quiet mode, -q command (disables progress printing)
@@ -412,6 +420,9 @@ func Run(args []string, opts ...cliOption) error {
 	proxyDB := proxy.Command("db", "Start local TLS proxy for database connections when using Teleport in single-port mode")
 	proxyDB.Arg("db", "The name of the database to start local proxy for").Required().StringVar(&cf.DatabaseService)
 	proxyDB.Flag("port", " Specifies the source port used by proxy db listener").Short('p').StringVar(&cf.LocalProxyPort)
+	proxyApp := proxy.Command("app", "Start local TLS proxy for app connection when using Teleport in single-port mode")
+	proxyApp.Arg("app", "The name of the application to start local proxy for").Required().StringVar(&cf.AppName)
+	proxyApp.Flag("port", "Specifies the source port used by the proxy app listener").Short('p').StringVar(&cf.LocalProxyPort)
 
 	// Databases.
 	db := app.Command("db", "View and control proxied databases.")
@@ -445,7 +456,7 @@ func Run(args []string, opts ...cliOption) error {
 	// play
 	play := app.Command("play", "Replay the recorded SSH session")
 	play.Flag("cluster", clusterHelp).StringVar(&cf.SiteName)
-	play.Flag("format", "Format output (json, pty)").Short('f').Default(teleport.PTY).StringVar(&cf.Format)
+	play.Flag("format", "Format output (json, pty, mp4, asciicast, raw)").Short('f').Default(teleport.PTY).StringVar(&cf.Format)
 	play.Arg("session-id", "ID of the session to play").Required().StringVar(&cf.SessionID)
 
 	// scp
@@ -540,6 +551,10 @@ func Run(args []string, opts ...cliOption) error {
 	reqReview.Flag("deny", "Review proposes denial").BoolVar(&cf.Deny)
 	reqReview.Flag("reason", "Review reason message").StringVar(&cf.ReviewReason)
 
+	reqAssume := req.Command("assume", "Assume an approved access request")
+	reqAssume.Arg("request-id", "ID of the target request").Required().StringVar(&cf.RequestID)
+	reqAssume.Flag("ttl", "Drop the elevated roles after this duration, rather than when the request's access expires").DurationVar(&cf.AssumeTTL)
+
 	// Kubernetes subcommands.
 	kube := newKubeCommand(app)
 	// MFA subcommands.
@@ -547,6 +562,12 @@ func Run(args []string, opts ...cliOption) error {
 
 	config := app.Command("config", "Print OpenSSH configuration details")
 
+	test := app.Command("test", "Test connectivity and access to resources")
+	testAccess := test.Command("access", "Perform a dry-run connection to a resource and print a step-by-step trace")
+	testAccess.Arg("kind", "Resource kind: node, db, kube or app").Required().EnumVar(&cf.TestResourceKind, "node", "db", "kube", "app")
+	testAccess.Arg("name", "Node hostname, database service, Kubernetes cluster or application name").Required().StringVar(&cf.TestResourceName)
+	testAccess.Flag("cluster", clusterHelp).StringVar(&cf.SiteName)
+
 	// config-proxy is a wrapper to ensure Windows clients can properly use
 	// `tsh config`. As it's not intended to run by users directly and may
 	// not have a stable CLI interface, hide it.
@@ -657,6 +678,8 @@ func Run(args []string, opts ...cliOption) error {
 		err = onProxyCommandSSH(&cf)
 	case proxyDB.FullCommand():
 		err = onProxyCommandDB(&cf)
+	case proxyApp.FullCommand():
+		err = onProxyCommandApp(&cf)
 
 	case dbList.FullCommand():
 		err = onListDatabases(&cf)
@@ -686,8 +709,12 @@ func Run(args []string, opts ...cliOption) error {
 		err = onRequestCreate(&cf)
 	case reqReview.FullCommand():
 		err = onRequestReview(&cf)
+	case reqAssume.FullCommand():
+		err = onRequestAssume(&cf)
 	case config.FullCommand():
 		err = onConfig(&cf)
+	case testAccess.FullCommand():
+		err = onTestAccess(&cf)
 	case configProxy.FullCommand():
 		err = onConfigProxy(&cf)
 	case aws.FullCommand():
@@ -728,6 +755,10 @@ func onPlay(cf *CLIConf) error {
 				return trace.Wrap(err)
 			}
 		}
+	case teleport.MP4:
+		if err := onExportVideo(cf); err != nil {
+			return trace.Wrap(err)
+		}
 	default:
 		switch {
 		case path.Ext(cf.SessionID) == ".tar":
@@ -740,11 +771,18 @@ func onPlay(cf *CLIConf) error {
 			if err != nil {
 				return trace.Wrap(err)
 			}
-			events, err := tc.GetSessionEvents(context.TODO(), cf.Namespace, cf.SessionID)
+			sessionEvents, err := tc.GetSessionEvents(context.TODO(), cf.Namespace, cf.SessionID)
 			if err != nil {
 				return trace.Wrap(err)
 			}
-			for _, event := range events {
+			if cf.Format == teleport.Asciicast || cf.Format == teleport.Raw {
+				stream, err := tc.GetSessionStream(cf.Context, cf.Namespace, cf.SessionID)
+				if err != nil {
+					return trace.Wrap(err)
+				}
+				return trace.Wrap(events.ExportEventFields(sessionEvents, stream, os.Stdout, cf.Format))
+			}
+			for _, event := range sessionEvents {
 				// when playing from a file, id is not included, this
 				// makes the outputs otherwise identical
 				delete(event, "id")
@@ -2333,6 +2371,11 @@ func reissueWithRequests(cf *CLIConf, tc *client.TeleportClient, reqIDs ...strin
 		AccessRequests: reqIDs,
 		RouteToCluster: cf.SiteName,
 	}
+	// Cap the validity of the elevated certificates when a bounded
+	// assumption window was requested.
+	if cf.AssumeTTL > 0 {
+		params.Expires = time.Now().UTC().Add(cf.AssumeTTL)
+	}
 	// if the certificate already had active requests, add them to our inputs parameters.
 	if len(profile.ActiveRequests.AccessRequests) > 0 {
 		params.AccessRequests = append(params.AccessRequests, profile.ActiveRequests.AccessRequests...)